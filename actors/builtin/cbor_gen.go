@@ -119,3 +119,402 @@ func (t *MinerAddrs) UnmarshalCBOR(r io.Reader) error {
 
 	return nil
 }
+
+var lengthBufMinerBalanceBreakdown = []byte{134}
+
+func (t *MinerBalanceBreakdown) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufMinerBalanceBreakdown); err != nil {
+		return err
+	}
+
+	// t.Balance (big.Int) (struct)
+	if err := t.Balance.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.Available (big.Int) (struct)
+	if err := t.Available.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.PreCommitDeposits (big.Int) (struct)
+	if err := t.PreCommitDeposits.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.InitialPledge (big.Int) (struct)
+	if err := t.InitialPledge.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.LockedFunds (big.Int) (struct)
+	if err := t.LockedFunds.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.FeeDebt (big.Int) (struct)
+	if err := t.FeeDebt.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *MinerBalanceBreakdown) UnmarshalCBOR(r io.Reader) error {
+	*t = MinerBalanceBreakdown{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 6 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Balance (big.Int) (struct)
+
+	{
+
+		if err := t.Balance.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.Balance: %w", err)
+		}
+
+	}
+	// t.Available (big.Int) (struct)
+
+	{
+
+		if err := t.Available.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.Available: %w", err)
+		}
+
+	}
+	// t.PreCommitDeposits (big.Int) (struct)
+
+	{
+
+		if err := t.PreCommitDeposits.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.PreCommitDeposits: %w", err)
+		}
+
+	}
+	// t.InitialPledge (big.Int) (struct)
+
+	{
+
+		if err := t.InitialPledge.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.InitialPledge: %w", err)
+		}
+
+	}
+	// t.LockedFunds (big.Int) (struct)
+
+	{
+
+		if err := t.LockedFunds.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.LockedFunds: %w", err)
+		}
+
+	}
+	// t.FeeDebt (big.Int) (struct)
+
+	{
+
+		if err := t.FeeDebt.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.FeeDebt: %w", err)
+		}
+
+	}
+	return nil
+}
+
+var lengthBufPageParams = []byte{130}
+
+func (t *PageParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufPageParams); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Limit (uint64) (uint64)
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.Limit)); err != nil {
+		return err
+	}
+
+	// t.Cursor ([]uint8) (slice)
+	if len(t.Cursor) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.Cursor was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajByteString, uint64(len(t.Cursor))); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(t.Cursor[:]); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *PageParams) UnmarshalCBOR(r io.Reader) error {
+	*t = PageParams{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 2 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Limit (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.Limit = uint64(extra)
+
+	}
+	// t.Cursor ([]uint8) (slice)
+
+	maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.ByteArrayMaxLen {
+		return fmt.Errorf("t.Cursor: byte array too large (%d)", extra)
+	}
+	if maj != cbg.MajByteString {
+		return fmt.Errorf("expected byte array")
+	}
+
+	if extra > 0 {
+		t.Cursor = make([]uint8, extra)
+	}
+
+	if _, err := io.ReadFull(br, t.Cursor[:]); err != nil {
+		return err
+	}
+	return nil
+}
+
+var lengthBufPageReturn = []byte{130}
+
+func (t *PageReturn) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufPageReturn); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.NextCursor ([]uint8) (slice)
+	if len(t.NextCursor) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.NextCursor was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajByteString, uint64(len(t.NextCursor))); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(t.NextCursor[:]); err != nil {
+		return err
+	}
+
+	// t.HasMore (bool) (bool)
+	if err := cbg.WriteBool(w, t.HasMore); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *PageReturn) UnmarshalCBOR(r io.Reader) error {
+	*t = PageReturn{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 2 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.NextCursor ([]uint8) (slice)
+
+	maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.ByteArrayMaxLen {
+		return fmt.Errorf("t.NextCursor: byte array too large (%d)", extra)
+	}
+	if maj != cbg.MajByteString {
+		return fmt.Errorf("expected byte array")
+	}
+
+	if extra > 0 {
+		t.NextCursor = make([]uint8, extra)
+	}
+
+	if _, err := io.ReadFull(br, t.NextCursor[:]); err != nil {
+		return err
+	}
+	// t.HasMore (bool) (bool)
+
+	maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajOther {
+		return fmt.Errorf("booleans must be major type 7")
+	}
+	switch extra {
+	case 20:
+		t.HasMore = false
+	case 21:
+		t.HasMore = true
+	default:
+		return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+	}
+	return nil
+}
+
+var lengthBufFundsTransferredEvent = []byte{132}
+
+func (t *FundsTransferredEvent) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufFundsTransferredEvent); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.From (address.Address) (struct)
+	if err := t.From.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.To (address.Address) (struct)
+	if err := t.To.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.Amount (big.Int) (struct)
+	if err := t.Amount.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.Purpose (builtin.TransferPurpose) (uint64)
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.Purpose)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (t *FundsTransferredEvent) UnmarshalCBOR(r io.Reader) error {
+	*t = FundsTransferredEvent{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 4 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.From (address.Address) (struct)
+
+	{
+
+		if err := t.From.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.From: %w", err)
+		}
+
+	}
+	// t.To (address.Address) (struct)
+
+	{
+
+		if err := t.To.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.To: %w", err)
+		}
+
+	}
+	// t.Amount (big.Int) (struct)
+
+	{
+
+		if err := t.Amount.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.Amount: %w", err)
+		}
+
+	}
+	// t.Purpose (builtin.TransferPurpose) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.Purpose = TransferPurpose(extra)
+
+	}
+	return nil
+}
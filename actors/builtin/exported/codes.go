@@ -0,0 +1,193 @@
+package exported
+
+import (
+	"sort"
+
+	"github.com/ipfs/go-cid"
+
+	builtin0 "github.com/filecoin-project/specs-actors/actors/builtin"
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+	builtin3 "github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	builtin4 "github.com/filecoin-project/specs-actors/v4/actors/builtin"
+	builtin5 "github.com/filecoin-project/specs-actors/v5/actors/builtin"
+)
+
+// ActorVersion identifies a release of the builtin actors, corresponding to the major version of this module.
+// Code CIDs for the same kind of actor differ between versions.
+type ActorVersion int
+
+const (
+	Version0 ActorVersion = 0
+	Version2 ActorVersion = 2
+	Version3 ActorVersion = 3
+	Version4 ActorVersion = 4
+	Version5 ActorVersion = 5
+)
+
+// All actor versions with builtin code CIDs, in ascending order.
+// There was never a version 1 of the actors; version 0 was superseded directly by version 2.
+var ActorVersions = []ActorVersion{Version0, Version2, Version3, Version4, Version5}
+
+// The version of the actors defined in this module.
+const LatestActorVersion = Version5
+
+// ActorKind names a builtin actor independently of actor version, e.g. "storageminer".
+type ActorKind string
+
+const (
+	SystemActorKind           ActorKind = "system"
+	InitActorKind             ActorKind = "init"
+	CronActorKind             ActorKind = "cron"
+	AccountActorKind          ActorKind = "account"
+	StoragePowerActorKind     ActorKind = "storagepower"
+	StorageMinerActorKind     ActorKind = "storageminer"
+	StorageMarketActorKind    ActorKind = "storagemarket"
+	PaymentChannelActorKind   ActorKind = "paymentchannel"
+	MultisigActorKind         ActorKind = "multisig"
+	RewardActorKind           ActorKind = "reward"
+	VerifiedRegistryActorKind ActorKind = "verifiedregistry"
+)
+
+// All kinds of builtin actor, in a stable order.
+var ActorKinds = []ActorKind{
+	SystemActorKind,
+	InitActorKind,
+	CronActorKind,
+	AccountActorKind,
+	StoragePowerActorKind,
+	StorageMinerActorKind,
+	StorageMarketActorKind,
+	PaymentChannelActorKind,
+	MultisigActorKind,
+	RewardActorKind,
+	VerifiedRegistryActorKind,
+}
+
+// BuiltinActorCode describes the code CID of one kind of builtin actor at one actor version.
+type BuiltinActorCode struct {
+	Version ActorVersion
+	Kind    ActorKind
+	Code    cid.Cid
+}
+
+var builtinCodes []BuiltinActorCode
+var builtinCodesByCID map[cid.Cid]BuiltinActorCode
+
+func init() {
+	type codeSet struct {
+		version ActorVersion
+		codes   [11]cid.Cid // Ordered as ActorKinds
+	}
+	for _, set := range []codeSet{
+		{Version0, [11]cid.Cid{
+			builtin0.SystemActorCodeID, builtin0.InitActorCodeID, builtin0.CronActorCodeID, builtin0.AccountActorCodeID,
+			builtin0.StoragePowerActorCodeID, builtin0.StorageMinerActorCodeID, builtin0.StorageMarketActorCodeID,
+			builtin0.PaymentChannelActorCodeID, builtin0.MultisigActorCodeID, builtin0.RewardActorCodeID,
+			builtin0.VerifiedRegistryActorCodeID,
+		}},
+		{Version2, [11]cid.Cid{
+			builtin2.SystemActorCodeID, builtin2.InitActorCodeID, builtin2.CronActorCodeID, builtin2.AccountActorCodeID,
+			builtin2.StoragePowerActorCodeID, builtin2.StorageMinerActorCodeID, builtin2.StorageMarketActorCodeID,
+			builtin2.PaymentChannelActorCodeID, builtin2.MultisigActorCodeID, builtin2.RewardActorCodeID,
+			builtin2.VerifiedRegistryActorCodeID,
+		}},
+		{Version3, [11]cid.Cid{
+			builtin3.SystemActorCodeID, builtin3.InitActorCodeID, builtin3.CronActorCodeID, builtin3.AccountActorCodeID,
+			builtin3.StoragePowerActorCodeID, builtin3.StorageMinerActorCodeID, builtin3.StorageMarketActorCodeID,
+			builtin3.PaymentChannelActorCodeID, builtin3.MultisigActorCodeID, builtin3.RewardActorCodeID,
+			builtin3.VerifiedRegistryActorCodeID,
+		}},
+		{Version4, [11]cid.Cid{
+			builtin4.SystemActorCodeID, builtin4.InitActorCodeID, builtin4.CronActorCodeID, builtin4.AccountActorCodeID,
+			builtin4.StoragePowerActorCodeID, builtin4.StorageMinerActorCodeID, builtin4.StorageMarketActorCodeID,
+			builtin4.PaymentChannelActorCodeID, builtin4.MultisigActorCodeID, builtin4.RewardActorCodeID,
+			builtin4.VerifiedRegistryActorCodeID,
+		}},
+		{Version5, [11]cid.Cid{
+			builtin5.SystemActorCodeID, builtin5.InitActorCodeID, builtin5.CronActorCodeID, builtin5.AccountActorCodeID,
+			builtin5.StoragePowerActorCodeID, builtin5.StorageMinerActorCodeID, builtin5.StorageMarketActorCodeID,
+			builtin5.PaymentChannelActorCodeID, builtin5.MultisigActorCodeID, builtin5.RewardActorCodeID,
+			builtin5.VerifiedRegistryActorCodeID,
+		}},
+	} {
+		for i, code := range set.codes {
+			builtinCodes = append(builtinCodes, BuiltinActorCode{Version: set.version, Kind: ActorKinds[i], Code: code})
+		}
+	}
+
+	builtinCodesByCID = make(map[cid.Cid]BuiltinActorCode, len(builtinCodes))
+	for _, bc := range builtinCodes {
+		if _, found := builtinCodesByCID[bc.Code]; found {
+			panic("duplicate builtin actor code " + bc.Code.String())
+		}
+		builtinCodesByCID[bc.Code] = bc
+	}
+}
+
+// AllBuiltinActorCodes returns the code CIDs of every builtin actor at every actor version,
+// ordered by version and then by kind (as ActorKinds).
+func AllBuiltinActorCodes() []BuiltinActorCode {
+	ret := make([]BuiltinActorCode, len(builtinCodes))
+	copy(ret, builtinCodes)
+	return ret
+}
+
+// BuiltinActorCodes returns the code CIDs of the builtin actors at a single version, ordered as ActorKinds.
+// Returns nil for an unknown version.
+func BuiltinActorCodes(version ActorVersion) []BuiltinActorCode {
+	var ret []BuiltinActorCode
+	for _, bc := range builtinCodes {
+		if bc.Version == version {
+			ret = append(ret, bc)
+		}
+	}
+	return ret
+}
+
+// IsBuiltinActor returns true if the code belongs to a builtin actor of any actor version.
+// Compare builtin.IsBuiltinActor, which recognises only the actors defined in this module.
+func IsBuiltinActor(code cid.Cid) bool {
+	_, ok := builtinCodesByCID[code]
+	return ok
+}
+
+// ClassifyActorCode returns the version and kind of a builtin actor code CID.
+// The second return value is false if the code does not belong to any builtin actor.
+func ClassifyActorCode(code cid.Cid) (BuiltinActorCode, bool) {
+	bc, ok := builtinCodesByCID[code]
+	return bc, ok
+}
+
+// BuiltinActorCodeFor returns the code CID for a kind of actor at some actor version.
+// The second return value is false if the version or kind are unknown.
+func BuiltinActorCodeFor(version ActorVersion, kind ActorKind) (cid.Cid, bool) {
+	for _, bc := range builtinCodes {
+		if bc.Version == version && bc.Kind == kind {
+			return bc.Code, true
+		}
+	}
+	return cid.Undef, false
+}
+
+// TranslateActorCode returns the code CID for the same kind of actor as code, at another actor version.
+// The second return value is false if code is not a builtin actor code or the target version is unknown.
+func TranslateActorCode(code cid.Cid, to ActorVersion) (cid.Cid, bool) {
+	bc, ok := builtinCodesByCID[code]
+	if !ok {
+		return cid.Undef, false
+	}
+	return BuiltinActorCodeFor(to, bc.Kind)
+}
+
+// SortedBuiltinActorCodeCIDs returns the code CIDs of all builtin actors at all versions, sorted by CID bytes.
+// This is convenient for building sets of CIDs to skip when walking state, e.g. when exporting CARs.
+func SortedBuiltinActorCodeCIDs() []cid.Cid {
+	ret := make([]cid.Cid, 0, len(builtinCodes))
+	for _, bc := range builtinCodes {
+		ret = append(ret, bc.Code)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].KeyString() < ret[j].KeyString()
+	})
+	return ret
+}
@@ -0,0 +1,67 @@
+package exported
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	builtin4 "github.com/filecoin-project/specs-actors/v4/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	tutil "github.com/filecoin-project/specs-actors/v5/support/testing"
+)
+
+func TestBuiltinActorCodes(t *testing.T) {
+	t.Run("every version has every kind", func(t *testing.T) {
+		all := AllBuiltinActorCodes()
+		assert.Equal(t, len(ActorVersions)*len(ActorKinds), len(all))
+		for _, v := range ActorVersions {
+			codes := BuiltinActorCodes(v)
+			require.Equal(t, len(ActorKinds), len(codes))
+			for i, bc := range codes {
+				assert.Equal(t, v, bc.Version)
+				assert.Equal(t, ActorKinds[i], bc.Kind)
+				assert.True(t, IsBuiltinActor(bc.Code))
+			}
+		}
+		assert.Nil(t, BuiltinActorCodes(ActorVersion(1)))
+	})
+
+	t.Run("latest version matches builtin package", func(t *testing.T) {
+		for _, bc := range BuiltinActorCodes(LatestActorVersion) {
+			assert.True(t, builtin.IsBuiltinActor(bc.Code))
+			assert.Equal(t, "fil/5/"+string(bc.Kind), builtin.ActorNameByCode(bc.Code))
+		}
+	})
+
+	t.Run("classify and translate", func(t *testing.T) {
+		bc, ok := ClassifyActorCode(builtin4.StorageMinerActorCodeID)
+		require.True(t, ok)
+		assert.Equal(t, Version4, bc.Version)
+		assert.Equal(t, StorageMinerActorKind, bc.Kind)
+
+		translated, ok := TranslateActorCode(builtin4.StorageMinerActorCodeID, Version5)
+		require.True(t, ok)
+		assert.Equal(t, builtin.StorageMinerActorCodeID, translated)
+
+		_, ok = TranslateActorCode(builtin4.StorageMinerActorCodeID, ActorVersion(1))
+		assert.False(t, ok)
+	})
+
+	t.Run("unknown code", func(t *testing.T) {
+		code := tutil.MakeCID("not an actor", nil)
+		assert.False(t, IsBuiltinActor(code))
+		_, ok := ClassifyActorCode(code)
+		assert.False(t, ok)
+		_, ok = TranslateActorCode(code, Version5)
+		assert.False(t, ok)
+	})
+
+	t.Run("sorted codes", func(t *testing.T) {
+		sorted := SortedBuiltinActorCodeCIDs()
+		require.Equal(t, len(AllBuiltinActorCodes()), len(sorted))
+		for i := 1; i < len(sorted); i++ {
+			assert.True(t, sorted[i-1].KeyString() < sorted[i].KeyString())
+		}
+	})
+}
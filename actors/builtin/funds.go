@@ -0,0 +1,76 @@
+package builtin
+
+import (
+	"fmt"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/runtime"
+)
+
+// The reason for a transfer of funds out of an actor, recorded with the transfer so that every token
+// movement has an attributable reason.
+type TransferPurpose uint64
+
+const (
+	TransferPenalty           = TransferPurpose(iota) // Penalty burnt or paid to the treasury
+	TransferFee                                       // Network fee, burnt
+	TransferReporterReward                            // Reward to a reporter of a fault, or a caller doing work for an actor
+	TransferWithdrawal                                // Withdrawal of an actor's balance to its owner or beneficiary
+	TransferPayment                                   // Payment to a counterparty, such as a payment channel's recipient
+	TransferUndeliveredReward                         // Block reward that could not be delivered to a miner, burnt
+)
+
+var transferPurposeNames = map[TransferPurpose]string{
+	TransferPenalty:           "penalty",
+	TransferFee:               "fee",
+	TransferReporterReward:    "reporter reward",
+	TransferWithdrawal:        "withdrawal",
+	TransferPayment:           "payment",
+	TransferUndeliveredReward: "undelivered reward",
+}
+
+func (p TransferPurpose) String() string {
+	if name, ok := transferPurposeNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("purpose(%d)", uint64(p))
+}
+
+// Type of the event emitted for each successful transfer made through TransferFunds.
+// The payload is a FundsTransferredEvent.
+const EventFundsTransferred = "funds-transferred"
+
+type FundsTransferredEvent struct {
+	From    addr.Address
+	To      addr.Address
+	Amount  abi.TokenAmount
+	Purpose TransferPurpose
+}
+
+// Sends funds from the executing actor to an address, emitting an event recording the transfer and its purpose
+// on success. Returns the exit code of the send, for the caller to decide whether a failure aborts.
+func TransferFunds(rt runtime.Runtime, to addr.Address, amount abi.TokenAmount, purpose TransferPurpose) exitcode.ExitCode {
+	code := rt.Send(to, MethodSend, nil, amount, &Discard{})
+	if code.IsSuccess() {
+		rt.EmitEvent(EventFundsTransferred, &FundsTransferredEvent{
+			From:    rt.Receiver(),
+			To:      to,
+			Amount:  amount,
+			Purpose: purpose,
+		})
+	}
+	return code
+}
+
+// Burns a positive amount of funds, aborting if the send fails.
+func BurnFunds(rt runtime.Runtime, amount abi.TokenAmount, purpose TransferPurpose) {
+	if !amount.GreaterThan(big.Zero()) {
+		return
+	}
+	code := TransferFunds(rt, BurntFundsActorAddr, amount, purpose)
+	RequireSuccess(rt, code, "failed to burn funds")
+}
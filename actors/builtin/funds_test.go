@@ -0,0 +1,80 @@
+package builtin_test
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v5/actors/runtime"
+	"github.com/filecoin-project/specs-actors/v5/support/mock"
+	tutil "github.com/filecoin-project/specs-actors/v5/support/testing"
+)
+
+func TestTransferFunds(t *testing.T) {
+	receiver := tutil.NewIDAddr(t, 100)
+	recipient := tutil.NewIDAddr(t, 101)
+	builder := mock.NewBuilder(receiver).WithBalance(abi.NewTokenAmount(1000), big.Zero())
+
+	// Invokes a function as if it were an actor method, so that it may send.
+	call := func(rt *mock.Runtime, f func(rt runtime.Runtime)) {
+		rt.Call(func(rt runtime.Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
+			f(rt)
+			return nil
+		}, nil)
+		rt.Verify()
+	}
+
+	t.Run("records purpose of successful transfer", func(t *testing.T) {
+		rt := builder.Build(t)
+		rt.ExpectSend(recipient, builtin.MethodSend, nil, abi.NewTokenAmount(100), nil, exitcode.Ok)
+		call(rt, func(rt runtime.Runtime) {
+			code := builtin.TransferFunds(rt, recipient, abi.NewTokenAmount(100), builtin.TransferWithdrawal)
+			assert.Equal(t, exitcode.Ok, code)
+		})
+		rt.ExpectEmitted(builtin.EventFundsTransferred, &builtin.FundsTransferredEvent{
+			From:    receiver,
+			To:      recipient,
+			Amount:  abi.NewTokenAmount(100),
+			Purpose: builtin.TransferWithdrawal,
+		})
+	})
+
+	t.Run("returns code of failed transfer", func(t *testing.T) {
+		rt := builder.Build(t)
+		rt.ExpectSend(recipient, builtin.MethodSend, nil, abi.NewTokenAmount(100), nil, exitcode.ErrForbidden)
+		call(rt, func(rt runtime.Runtime) {
+			code := builtin.TransferFunds(rt, recipient, abi.NewTokenAmount(100), builtin.TransferPayment)
+			assert.Equal(t, exitcode.ErrForbidden, code)
+		})
+		rt.ExpectNotEmitted(builtin.EventFundsTransferred)
+	})
+
+	t.Run("burns positive amounts only", func(t *testing.T) {
+		rt := builder.Build(t)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, abi.NewTokenAmount(10), nil, exitcode.Ok)
+		call(rt, func(rt runtime.Runtime) {
+			builtin.BurnFunds(rt, abi.NewTokenAmount(10), builtin.TransferFee)
+			builtin.BurnFunds(rt, big.Zero(), builtin.TransferFee)
+		})
+		rt.ExpectEmitted(builtin.EventFundsTransferred, &builtin.FundsTransferredEvent{
+			From:    receiver,
+			To:      builtin.BurntFundsActorAddr,
+			Amount:  abi.NewTokenAmount(10),
+			Purpose: builtin.TransferFee,
+		})
+	})
+
+	t.Run("aborts if burn fails", func(t *testing.T) {
+		rt := builder.Build(t)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, abi.NewTokenAmount(10), nil, exitcode.ErrIllegalState)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalState, "failed to burn funds", func() {
+			call(rt, func(rt runtime.Runtime) {
+				builtin.BurnFunds(rt, abi.NewTokenAmount(10), builtin.TransferPenalty)
+			})
+		})
+	})
+}
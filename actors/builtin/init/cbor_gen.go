@@ -108,3 +108,285 @@ func (t *State) UnmarshalCBOR(r io.Reader) error {
 	}
 	return nil
 }
+
+var lengthBufPaymentChannelSpec = []byte{130}
+
+func (t *PaymentChannelSpec) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufPaymentChannelSpec); err != nil {
+		return err
+	}
+
+	// t.To (address.Address) (struct)
+	if err := t.To.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.Value (big.Int) (struct)
+	if err := t.Value.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *PaymentChannelSpec) UnmarshalCBOR(r io.Reader) error {
+	*t = PaymentChannelSpec{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 2 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.To (address.Address) (struct)
+
+	{
+
+		if err := t.To.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.To: %w", err)
+		}
+
+	}
+	// t.Value (big.Int) (struct)
+
+	{
+
+		if err := t.Value.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.Value: %w", err)
+		}
+
+	}
+	return nil
+}
+
+var lengthBufCreatePaymentChannelsParams = []byte{129}
+
+func (t *CreatePaymentChannelsParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufCreatePaymentChannelsParams); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Channels ([]init.PaymentChannelSpec) (slice)
+	if len(t.Channels) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Channels was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajArray, uint64(len(t.Channels))); err != nil {
+		return err
+	}
+	for _, v := range t.Channels {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *CreatePaymentChannelsParams) UnmarshalCBOR(r io.Reader) error {
+	*t = CreatePaymentChannelsParams{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Channels ([]init.PaymentChannelSpec) (slice)
+
+	maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Channels: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.Channels = make([]PaymentChannelSpec, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		var v PaymentChannelSpec
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.Channels[i] = v
+	}
+
+	return nil
+}
+
+var lengthBufCreatedChannel = []byte{130}
+
+func (t *CreatedChannel) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufCreatedChannel); err != nil {
+		return err
+	}
+
+	// t.IDAddress (address.Address) (struct)
+	if err := t.IDAddress.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.RobustAddress (address.Address) (struct)
+	if err := t.RobustAddress.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *CreatedChannel) UnmarshalCBOR(r io.Reader) error {
+	*t = CreatedChannel{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 2 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.IDAddress (address.Address) (struct)
+
+	{
+
+		if err := t.IDAddress.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.IDAddress: %w", err)
+		}
+
+	}
+	// t.RobustAddress (address.Address) (struct)
+
+	{
+
+		if err := t.RobustAddress.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.RobustAddress: %w", err)
+		}
+
+	}
+	return nil
+}
+
+var lengthBufCreatePaymentChannelsReturn = []byte{129}
+
+func (t *CreatePaymentChannelsReturn) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufCreatePaymentChannelsReturn); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Channels ([]init.CreatedChannel) (slice)
+	if len(t.Channels) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Channels was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajArray, uint64(len(t.Channels))); err != nil {
+		return err
+	}
+	for _, v := range t.Channels {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *CreatePaymentChannelsReturn) UnmarshalCBOR(r io.Reader) error {
+	*t = CreatePaymentChannelsReturn{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Channels ([]init.CreatedChannel) (slice)
+
+	maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Channels: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.Channels = make([]CreatedChannel, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		var v CreatedChannel
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.Channels[i] = v
+	}
+
+	return nil
+}
@@ -1,14 +1,18 @@
 package init
 
 import (
+	"bytes"
+
 	addr "github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/cbor"
 	"github.com/filecoin-project/go-state-types/exitcode"
 	init0 "github.com/filecoin-project/specs-actors/actors/builtin/init"
 	cid "github.com/ipfs/go-cid"
 
 	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/paych"
 	"github.com/filecoin-project/specs-actors/v5/actors/runtime"
 	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
 )
@@ -21,6 +25,7 @@ func (a Actor) Exports() []interface{} {
 	return []interface{}{
 		builtin.MethodConstructor: a.Constructor,
 		2:                         a.Exec,
+		3:                         a.CreatePaymentChannels,
 	}
 }
 
@@ -36,9 +41,9 @@ func (a Actor) State() cbor.Er { return new(State) }
 
 var _ runtime.VMActor = Actor{}
 
-//type ConstructorParams struct {
-//	NetworkName string
-//}
+//	type ConstructorParams struct {
+//		NetworkName string
+//	}
 type ConstructorParams = init0.ConstructorParams
 
 func (a Actor) Constructor(rt runtime.Runtime, params *ConstructorParams) *abi.EmptyValue {
@@ -49,16 +54,16 @@ func (a Actor) Constructor(rt runtime.Runtime, params *ConstructorParams) *abi.E
 	return nil
 }
 
-//type ExecParams struct {
-//	CodeCID           cid.Cid `checked:"true"` // invalid CIDs won't get committed to the state tree
-//	ConstructorParams []byte
-//}
+//	type ExecParams struct {
+//		CodeCID           cid.Cid `checked:"true"` // invalid CIDs won't get committed to the state tree
+//		ConstructorParams []byte
+//	}
 type ExecParams = init0.ExecParams
 
-//type ExecReturn struct {
-//	IDAddress     addr.Address // The canonical ID-based address for the actor.
-//	RobustAddress addr.Address // A more expensive but re-org-safe address for the newly created actor.
-//}
+//	type ExecReturn struct {
+//		IDAddress     addr.Address // The canonical ID-based address for the actor.
+//		RobustAddress addr.Address // A more expensive but re-org-safe address for the newly created actor.
+//	}
 type ExecReturn = init0.ExecReturn
 
 func (a Actor) Exec(rt runtime.Runtime, params *ExecParams) *ExecReturn {
@@ -69,6 +74,67 @@ func (a Actor) Exec(rt runtime.Runtime, params *ExecParams) *ExecReturn {
 		rt.Abortf(exitcode.ErrForbidden, "caller type %v cannot exec actor type %v", callerCodeCID, params.CodeCID)
 	}
 
+	return execActor(rt, params.CodeCID, params.ConstructorParams, rt.ValueReceived())
+}
+
+type PaymentChannelSpec struct {
+	To    addr.Address    // The recipient of the channel.
+	Value abi.TokenAmount // The initial balance of the channel.
+}
+
+type CreatePaymentChannelsParams struct {
+	Channels []PaymentChannelSpec
+}
+
+type CreatedChannel struct {
+	IDAddress     addr.Address // The canonical ID-based address for the channel.
+	RobustAddress addr.Address // A more expensive but re-org-safe address for the channel.
+}
+
+type CreatePaymentChannelsReturn struct {
+	Channels []CreatedChannel // Addresses of the new channels, in the order requested.
+}
+
+// Creates a payment channel from the caller to each of the requested recipients, funding each
+// with its requested value. The value sent with the message must equal the total of those values.
+func (a Actor) CreatePaymentChannels(rt runtime.Runtime, params *CreatePaymentChannelsParams) *CreatePaymentChannelsReturn {
+	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
+	if len(params.Channels) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no payment channels to create")
+	}
+
+	totalValue := big.Zero()
+	for i, spec := range params.Channels {
+		if spec.Value.LessThan(big.Zero()) {
+			rt.Abortf(exitcode.ErrIllegalArgument, "negative value %v for channel %d", spec.Value, i)
+		}
+		totalValue = big.Add(totalValue, spec.Value)
+	}
+	if !totalValue.Equals(rt.ValueReceived()) {
+		rt.Abortf(exitcode.ErrIllegalArgument, "value received %v does not match total channel value %v", rt.ValueReceived(), totalValue)
+	}
+
+	ret := &CreatePaymentChannelsReturn{Channels: make([]CreatedChannel, 0, len(params.Channels))}
+	for _, spec := range params.Channels {
+		ctorParams := paych.ConstructorParams{
+			From: rt.Caller(),
+			To:   spec.To,
+		}
+		ctorParamBuf := new(bytes.Buffer)
+		err := ctorParams.MarshalCBOR(ctorParamBuf)
+		builtin.RequireNoErr(rt, err, exitcode.ErrSerialization, "failed to serialize payment channel constructor params %v", ctorParams)
+
+		execRet := execActor(rt, builtin.PaymentChannelActorCodeID, ctorParamBuf.Bytes(), spec.Value)
+		ret.Channels = append(ret.Channels, CreatedChannel{
+			IDAddress:     execRet.IDAddress,
+			RobustAddress: execRet.RobustAddress,
+		})
+	}
+	return ret
+}
+
+// Allocates addresses for a new actor, creates it and invokes its constructor with the given value.
+func execActor(rt runtime.Runtime, codeCID cid.Cid, constructorParams []byte, value abi.TokenAmount) *ExecReturn {
 	// Compute a re-org-stable address.
 	// This address exists for use by messages coming from outside the system, in order to
 	// stably address the newly created actor even if a chain re-org causes it to end up with
@@ -86,10 +152,10 @@ func (a Actor) Exec(rt runtime.Runtime, params *ExecParams) *ExecReturn {
 	})
 
 	// Create an empty actor.
-	rt.CreateActor(params.CodeCID, idAddr)
+	rt.CreateActor(codeCID, idAddr)
 
 	// Invoke constructor.
-	code := rt.Send(idAddr, builtin.MethodConstructor, builtin.CBORBytes(params.ConstructorParams), rt.ValueReceived(), &builtin.Discard{})
+	code := rt.Send(idAddr, builtin.MethodConstructor, builtin.CBORBytes(constructorParams), value, &builtin.Discard{})
 	builtin.RequireSuccess(rt, code, "constructor failed")
 
 	return &ExecReturn{IDAddress: idAddr, RobustAddress: uniqueAddress}
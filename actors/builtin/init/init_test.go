@@ -1,6 +1,7 @@
 package init_test
 
 import (
+	"bytes"
 	"strings"
 	"testing"
 
@@ -10,9 +11,11 @@ import (
 	"github.com/filecoin-project/go-state-types/exitcode"
 	cid "github.com/ipfs/go-cid"
 	assert "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
 	init_ "github.com/filecoin-project/specs-actors/v5/actors/builtin/init"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/paych"
 	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
 	"github.com/filecoin-project/specs-actors/v5/support/mock"
 	tutil "github.com/filecoin-project/specs-actors/v5/support/testing"
@@ -212,6 +215,90 @@ func TestExec(t *testing.T) {
 	})
 }
 
+func TestCreatePaymentChannels(t *testing.T) {
+	actor := initHarness{init_.Actor{}, t}
+
+	receiver := tutil.NewIDAddr(t, 1000)
+	anne := tutil.NewIDAddr(t, 1001)
+	bob := tutil.NewIDAddr(t, 1002)
+	builder := mock.NewBuilder(receiver).WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID)
+	value := abi.NewTokenAmount(100)
+
+	t.Run("creates and funds a channel from the caller", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		rt.SetCaller(anne, builtin.AccountActorCodeID)
+		rt.SetBalance(value)
+		rt.SetReceived(value)
+
+		uniqueAddr := tutil.NewActorAddr(t, "paych")
+		rt.SetNewActorAddress(uniqueAddr)
+		expectedIdAddr := tutil.NewIDAddr(t, 100)
+		rt.ExpectCreateActor(builtin.PaymentChannelActorCodeID, expectedIdAddr)
+
+		ctorParams := paych.ConstructorParams{From: anne, To: bob}
+		ctorParamBuf := new(bytes.Buffer)
+		require.NoError(t, ctorParams.MarshalCBOR(ctorParamBuf))
+		rt.ExpectSend(expectedIdAddr, builtin.MethodConstructor, builtin.CBORBytes(ctorParamBuf.Bytes()), value, nil, exitcode.Ok)
+
+		ret := actor.createPaymentChannels(rt, init_.PaymentChannelSpec{To: bob, Value: value})
+		require.Len(t, ret.Channels, 1)
+		assert.Equal(t, uniqueAddr, ret.Channels[0].RobustAddress)
+		assert.Equal(t, expectedIdAddr, ret.Channels[0].IDAddress)
+		actor.checkState(rt)
+	})
+
+	t.Run("value received must match channel values", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		rt.SetCaller(anne, builtin.AccountActorCodeID)
+		rt.SetBalance(value)
+		rt.SetReceived(value)
+
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.createPaymentChannels(rt,
+				init_.PaymentChannelSpec{To: bob, Value: value},
+				init_.PaymentChannelSpec{To: bob, Value: abi.NewTokenAmount(1)},
+			)
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects negative channel values", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		rt.SetCaller(anne, builtin.AccountActorCodeID)
+
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.createPaymentChannels(rt,
+				init_.PaymentChannelSpec{To: bob, Value: abi.NewTokenAmount(-1)},
+				init_.PaymentChannelSpec{To: bob, Value: abi.NewTokenAmount(1)},
+			)
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects an empty batch", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		rt.SetCaller(anne, builtin.AccountActorCodeID)
+
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.createPaymentChannels(rt)
+		})
+	})
+
+	t.Run("caller must be signable", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		rt.SetCaller(builtin.StoragePowerActorAddr, builtin.StoragePowerActorCodeID)
+
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			actor.createPaymentChannels(rt, init_.PaymentChannelSpec{To: bob, Value: big.Zero()})
+		})
+	})
+}
+
 type initHarness struct {
 	init_.Actor
 	t testing.TB
@@ -253,3 +340,10 @@ func (h *initHarness) execAndVerify(rt *mock.Runtime, codeID cid.Cid, constructo
 	rt.Verify()
 	return ret
 }
+
+func (h *initHarness) createPaymentChannels(rt *mock.Runtime, specs ...init_.PaymentChannelSpec) *init_.CreatePaymentChannelsReturn {
+	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+	ret := rt.Call(h.CreatePaymentChannels, &init_.CreatePaymentChannelsParams{Channels: specs}).(*init_.CreatePaymentChannelsReturn)
+	rt.Verify()
+	return ret
+}
@@ -1,7 +1,14 @@
 package market
 
 import (
+	"bytes"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	acrypto "github.com/filecoin-project/go-state-types/crypto"
 	market0 "github.com/filecoin-project/specs-actors/actors/builtin/market"
+	"github.com/ipfs/go-cid"
 )
 
 //var PieceCIDPrefix = cid.Prefix{
@@ -20,33 +27,104 @@ var PieceCIDPrefix = market0.PieceCIDPrefix
 // minimal deals that last for a long time.
 // Note: ClientCollateralPerEpoch may not be needed and removed pending future confirmation.
 // There will be a Minimum value for both client and provider deal collateral.
-//type DealProposal struct {
-//	PieceCID     cid.Cid `checked:"true"` // Checked in validateDeal, CommP
-//	PieceSize    abi.PaddedPieceSize
-//	VerifiedDeal bool
-//	Client       addr.Address
-//	Provider     addr.Address
-//
-//	// Label is an arbitrary client chosen label to apply to the deal
-//	// TODO: Limit the size of this: https://github.com/filecoin-project/specs-actors/issues/897
-//	Label string
-//
-//	// Nominal start epoch. Deal payment is linear between StartEpoch and EndEpoch,
-//	// with total amount StoragePricePerEpoch * (EndEpoch - StartEpoch).
-//	// Storage deal must appear in a sealed (proven) sector no later than StartEpoch,
-//	// otherwise it is invalid.
-//	StartEpoch           abi.ChainEpoch
-//	EndEpoch             abi.ChainEpoch
-//	StoragePricePerEpoch abi.TokenAmount
-//
-//	ProviderCollateral abi.TokenAmount
-//	ClientCollateral   abi.TokenAmount
-//}
-type DealProposal = market0.DealProposal
+type DealProposal struct {
+	PieceCID     cid.Cid `checked:"true"` // Checked in validateDeal, CommP
+	PieceSize    abi.PaddedPieceSize
+	VerifiedDeal bool
+	Client       addr.Address
+	Provider     addr.Address
+
+	// Label is an arbitrary client chosen label to apply to the deal, of at most DealMaxLabelSize bytes
+	Label DealLabel
+
+	// Nominal start epoch. Deal payment is linear between StartEpoch and EndEpoch,
+	// with total amount StoragePricePerEpoch * (EndEpoch - StartEpoch).
+	// A negative price is paid by the provider to the client.
+	// Storage deal must appear in a sealed (proven) sector no later than StartEpoch,
+	// otherwise it is invalid.
+	StartEpoch           abi.ChainEpoch
+	EndEpoch             abi.ChainEpoch
+	StoragePricePerEpoch abi.TokenAmount
+
+	ProviderCollateral abi.TokenAmount
+	ClientCollateral   abi.TokenAmount
+
+	// Optional term by which the deal is extended at EndEpoch, unless either party declines the renewal
+	// beforehand. Nil if the deal does not renew.
+	Renewal *DealRenewal
+
+	// Optional number of epochs from StartEpoch during which termination of the deal's sector, such as after
+	// its faults persist, ends the deal without slashing the provider's collateral. The market is not told
+	// why a sector terminates, so this applies to any termination. Zero if every termination slashes the deal.
+	SlashingGracePeriod abi.ChainEpoch
+}
+
+// The term of a renewable deal's extension past its end epoch.
+// Payment continues at the renewal price, and both parties' collateral remains locked, until the extension ends.
+// The renewal price may not be paid by a different party than the price of the deal's term.
+type DealRenewal struct {
+	Duration             abi.ChainEpoch
+	StoragePricePerEpoch abi.TokenAmount
+}
 
 // ClientDealProposal is a DealProposal signed by a client
-//type ClientDealProposal struct {
-//	Proposal        DealProposal
-//	ClientSignature crypto.Signature
-//}
-type ClientDealProposal = market0.ClientDealProposal
+type ClientDealProposal struct {
+	Proposal        DealProposal
+	ClientSignature acrypto.Signature
+}
+
+func (p *DealProposal) Duration() abi.ChainEpoch {
+	return p.EndEpoch - p.StartEpoch
+}
+
+// The epoch at which the deal ends if it is renewed, or its end epoch if it is not renewable.
+func (p *DealProposal) MaxEndEpoch() abi.ChainEpoch {
+	if p.Renewal == nil {
+		return p.EndEpoch
+	}
+	return p.EndEpoch + p.Renewal.Duration
+}
+
+// Whether termination of the deal's sector at an epoch falls within the deal's slashing grace period,
+// which includes any epoch before the deal starts if the deal has a grace period.
+func (p *DealProposal) InSlashingGracePeriod(epoch abi.ChainEpoch) bool {
+	return p.SlashingGracePeriod > 0 && epoch < p.StartEpoch+p.SlashingGracePeriod
+}
+
+// The storage fee for the deal's renewal term, zero if it is not renewable.
+func (p *DealProposal) RenewalStorageFee() abi.TokenAmount {
+	if p.Renewal == nil {
+		return big.Zero()
+	}
+	return big.Mul(p.Renewal.StoragePricePerEpoch, big.NewInt(int64(p.Renewal.Duration)))
+}
+
+// The storage fee for the deal's term and its renewal term, if any, which is locked when the deal is published.
+// The fee is negative if the provider pays the client.
+func (p *DealProposal) TotalStorageFee() abi.TokenAmount {
+	termFee := big.Mul(p.StoragePricePerEpoch, big.NewInt(int64(p.Duration())))
+	return big.Add(termFee, p.RenewalStorageFee())
+}
+
+// Whether the provider pays the client for the deal, rather than the client paying the provider.
+func (p *DealProposal) ProviderPaysStorageFee() bool {
+	return p.TotalStorageFee().LessThan(big.Zero())
+}
+
+// The client's collateral and the storage fee, if paid by the client.
+func (p *DealProposal) ClientBalanceRequirement() abi.TokenAmount {
+	return big.Add(p.ClientCollateral, big.Max(p.TotalStorageFee(), big.Zero()))
+}
+
+// The provider's collateral and the storage fee, if paid by the provider.
+func (p *DealProposal) ProviderBalanceRequirement() abi.TokenAmount {
+	return big.Add(p.ProviderCollateral, big.Max(p.TotalStorageFee().Neg(), big.Zero()))
+}
+
+func (p *DealProposal) Cid() (cid.Cid, error) {
+	buf := new(bytes.Buffer)
+	if err := p.MarshalCBOR(buf); err != nil {
+		return cid.Undef, err
+	}
+	return abi.CidBuilder.Sum(buf.Bytes())
+}
@@ -0,0 +1,132 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+)
+
+// A DealLabel is a client chosen label for a deal, which is either a valid UTF-8 string or raw bytes.
+// It serializes to a CBOR text string or byte string respectively, and a text string that is not valid UTF-8
+// fails to deserialize.
+// The zero value is the empty string label.
+type DealLabel struct {
+	// The label's content, which is valid UTF-8 unless the label is bytes.
+	data    string
+	isBytes bool
+}
+
+// The empty string label.
+var EmptyDealLabel = DealLabel{}
+
+// Constructs a string label, which must be valid UTF-8.
+func NewLabelFromString(s string) (DealLabel, error) {
+	if !utf8.ValidString(s) {
+		return EmptyDealLabel, xerrors.Errorf("deal label string is not valid UTF-8")
+	}
+	return DealLabel{data: s}, nil
+}
+
+// Constructs a bytes label.
+func NewLabelFromBytes(b []byte) DealLabel {
+	return DealLabel{data: string(b), isBytes: true}
+}
+
+func (l DealLabel) IsString() bool {
+	return !l.isBytes
+}
+
+func (l DealLabel) IsBytes() bool {
+	return l.isBytes
+}
+
+// Returns the content of a string label.
+func (l DealLabel) ToString() (string, error) {
+	if l.isBytes {
+		return "", xerrors.Errorf("deal label is bytes, not a string")
+	}
+	return l.data, nil
+}
+
+// Returns the content of the label as bytes, whether it is a string or bytes label.
+func (l DealLabel) ToBytes() []byte {
+	return []byte(l.data)
+}
+
+// The length of the label's content in bytes.
+func (l DealLabel) Length() int {
+	return len(l.data)
+}
+
+func (l DealLabel) String() string {
+	if l.isBytes {
+		return fmt.Sprintf("0x%x", l.data)
+	}
+	return l.data
+}
+
+func (l *DealLabel) MarshalCBOR(w io.Writer) error {
+	var majorType byte = cbg.MajTextString
+	if l.isBytes {
+		majorType = cbg.MajByteString
+	}
+	if err := cbg.WriteMajorTypeHeader(w, majorType, uint64(len(l.data))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, l.data)
+	return err
+}
+
+func (l *DealLabel) UnmarshalCBOR(r io.Reader) error {
+	majorType, length, err := cbg.CborReadHeader(r)
+	if err != nil {
+		return err
+	}
+	if majorType != cbg.MajTextString && majorType != cbg.MajByteString {
+		return xerrors.Errorf("deal label must be a text or byte string, got major type %d", majorType)
+	}
+	if length > DealMaxLabelSize {
+		return xerrors.Errorf("deal label can be at most %d bytes, is %d", DealMaxLabelSize, length)
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return err
+	}
+
+	if majorType == cbg.MajByteString {
+		*l = NewLabelFromBytes(buf)
+		return nil
+	}
+	*l, err = NewLabelFromString(string(buf))
+	return err
+}
+
+// The JSON form of a bytes label, distinguishing it from a string label, which is a JSON string.
+type dealLabelBytesJSON struct {
+	Bytes []byte
+}
+
+func (l DealLabel) MarshalJSON() ([]byte, error) {
+	if l.isBytes {
+		return json.Marshal(dealLabelBytesJSON{Bytes: l.ToBytes()})
+	}
+	return json.Marshal(l.data)
+}
+
+func (l *DealLabel) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*l, err = NewLabelFromString(s)
+		return err
+	}
+	var bs dealLabelBytesJSON
+	if err := json.Unmarshal(b, &bs); err != nil {
+		return xerrors.Errorf("deal label must be a JSON string or bytes object: %w", err)
+	}
+	*l = NewLabelFromBytes(bs.Bytes)
+	return nil
+}
@@ -0,0 +1,118 @@
+package market
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+)
+
+// The changes to deals between two market states, each list in deal ID order.
+type DealsDiff struct {
+	// Deals published.
+	Added []abi.DealID
+	// Deals activated in a sector.
+	Activated []abi.DealID
+	// Deals terminated early. A deal is reported when its slash epoch is recorded, or, if it is also removed
+	// before the later state, when it is removed before its end epoch could have been reached.
+	Slashed []abi.DealID
+	// Activated deals removed after reaching their end epoch without being slashed.
+	// A deal both terminated and removed after its end epoch, all between the two states, is indistinguishable
+	// from an expired one, and is reported as expired.
+	Expired []abi.DealID
+	// All deals removed, whether expired, slashed, timed out or cancelled.
+	Removed []abi.DealID
+}
+
+// Computes the changes to deals between two market states, given their roots.
+// Only the parts of the deal proposal and state collections that differ are traversed.
+func DiffDeals(store adt.Store, prevRoot, curRoot cid.Cid) (*DealsDiff, error) {
+	var prev, cur State
+	if err := store.Get(store.Context(), prevRoot, &prev); err != nil {
+		return nil, xerrors.Errorf("failed to load previous market state %v: %w", prevRoot, err)
+	}
+	if err := store.Get(store.Context(), curRoot, &cur); err != nil {
+		return nil, xerrors.Errorf("failed to load current market state %v: %w", curRoot, err)
+	}
+
+	proposalChanges, err := adt.DiffArrays(store, prev.Proposals, cur.Proposals, ProposalsAmtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to diff deal proposals: %w", err)
+	}
+	stateChanges, err := adt.DiffArrays(store, prev.States, cur.States, StatesAmtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to diff deal states: %w", err)
+	}
+
+	diff := &DealsDiff{}
+	removedStates := make(map[abi.DealID]*DealState)
+	for _, change := range stateChanges {
+		dealID := abi.DealID(change.Index)
+		before, err := decodeDealState(change.Before)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to decode previous state of deal %d: %w", dealID, err)
+		}
+		after, err := decodeDealState(change.After)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to decode current state of deal %d: %w", dealID, err)
+		}
+
+		if after == nil {
+			removedStates[dealID] = before
+			continue
+		}
+		if after.SectorStartEpoch != epochUndefined && (before == nil || before.SectorStartEpoch == epochUndefined) {
+			diff.Activated = append(diff.Activated, dealID)
+		}
+		if after.SlashEpoch != epochUndefined && (before == nil || before.SlashEpoch == epochUndefined) {
+			diff.Slashed = append(diff.Slashed, dealID)
+		}
+	}
+
+	for _, change := range proposalChanges {
+		dealID := abi.DealID(change.Index)
+		if change.Before == nil {
+			diff.Added = append(diff.Added, dealID)
+			continue
+		}
+		if change.After != nil {
+			continue
+		}
+		diff.Removed = append(diff.Removed, dealID)
+
+		// A deal that was active and not yet slashed in the previous state either expired or was slashed.
+		state, ok := removedStates[dealID]
+		if !ok || state.SlashEpoch != epochUndefined {
+			continue
+		}
+		var proposal DealProposal
+		if err := proposal.UnmarshalCBOR(bytes.NewReader(change.Before.Raw)); err != nil {
+			return nil, xerrors.Errorf("failed to decode proposal of deal %d: %w", dealID, err)
+		}
+		// A deal that isn't slashed is removed by cron no earlier than its end epoch.
+		if proposal.EndEpoch > cur.LastCron {
+			diff.Slashed = append(diff.Slashed, dealID)
+		} else {
+			diff.Expired = append(diff.Expired, dealID)
+		}
+	}
+
+	sort.Slice(diff.Slashed, func(i, j int) bool { return diff.Slashed[i] < diff.Slashed[j] })
+	return diff, nil
+}
+
+func decodeDealState(raw *cbg.Deferred) (*DealState, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var state DealState
+	if err := state.UnmarshalCBOR(bytes.NewReader(raw.Raw)); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
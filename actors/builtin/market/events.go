@@ -0,0 +1,70 @@
+package market
+
+import (
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+// Types of the events emitted over the lifecycle of a deal.
+const (
+	// A deal is published, or its offer accepted. The payload is a DealEvent.
+	EventDealPublished = "deal-published"
+	// A deal is activated in a sector. The payload is a DealEvent.
+	EventDealActivated = "deal-activated"
+	// A deal is removed before its end epoch, having been terminated or not activated in time.
+	// The payload is a DealSlashedEvent. A terminated deal is reported when cron next processes it.
+	EventDealSlashed = "deal-slashed"
+	// A deal is removed after its end epoch. The payload is a DealEvent.
+	EventDealExpired = "deal-expired"
+	// A deal's client pays its provider. The payload is a DealPaymentEvent.
+	EventDealPaymentSettled = "deal-payment-settled"
+)
+
+type DealEvent struct {
+	DealID   abi.DealID
+	Client   addr.Address
+	Provider addr.Address
+}
+
+type DealSlashedEvent struct {
+	DealID   abi.DealID
+	Client   addr.Address
+	Provider addr.Address
+	// The epoch at which the deal's sector terminated, or at which the deal timed out before activation.
+	SlashEpoch abi.ChainEpoch
+	// The provider collateral burnt, which is zero for a deal terminated within its slashing grace period.
+	Penalty abi.TokenAmount
+}
+
+type DealPaymentEvent struct {
+	DealID   abi.DealID
+	Client   addr.Address
+	Provider addr.Address
+	Amount   abi.TokenAmount
+	// The epoch up to which the deal has been paid.
+	Epoch abi.ChainEpoch
+}
+
+func emitDealEvent(rt Runtime, eventType string, dealID abi.DealID, deal *DealProposal) {
+	rt.EmitEvent(eventType, &DealEvent{DealID: dealID, Client: deal.Client, Provider: deal.Provider})
+}
+
+func emitDealSlashed(rt Runtime, dealID abi.DealID, deal *DealProposal, slashEpoch abi.ChainEpoch, penalty abi.TokenAmount) {
+	rt.EmitEvent(EventDealSlashed, &DealSlashedEvent{
+		DealID:     dealID,
+		Client:     deal.Client,
+		Provider:   deal.Provider,
+		SlashEpoch: slashEpoch,
+		Penalty:    penalty,
+	})
+}
+
+func emitDealPayment(rt Runtime, dealID abi.DealID, deal *DealProposal, amount abi.TokenAmount, epoch abi.ChainEpoch) {
+	rt.EmitEvent(EventDealPaymentSettled, &DealPaymentEvent{
+		DealID:   dealID,
+		Client:   deal.Client,
+		Provider: deal.Provider,
+		Amount:   amount,
+		Epoch:    epoch,
+	})
+}
@@ -1,9 +1,12 @@
 package market
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
 	"sort"
 
 	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/cbor"
@@ -37,6 +40,25 @@ func (a Actor) Exports() []interface{} {
 		7:                         a.OnMinerSectorsTerminate,
 		8:                         a.ComputeDataCommitment,
 		9:                         a.CronTick,
+		10:                        a.PublishStorageDealsWithPaymentMode,
+		11:                        a.OfferStorageDeals,
+		12:                        a.AcceptStorageDealOffers,
+		13:                        a.PublishStorageDealsWithDerivedIDs,
+		14:                        a.DealEndEpochs,
+		15:                        a.DealSettlements,
+		16:                        a.ProviderDeals,
+		17:                        a.PieceDeals,
+		18:                        a.CancelStorageDeals,
+		19:                        a.DeclineRenewal,
+		20:                        a.WithdrawBalanceTo,
+		21:                        a.AddBalanceFor,
+		22:                        a.GetDealStatus,
+		23:                        a.GetLockedFunds,
+		24:                        a.AuthorizeDealProposal,
+		25:                        a.ContinueTermination,
+		26:                        a.RequestActivationExtension,
+		27:                        a.GrantActivationExtension,
+		28:                        a.GetPendingProposalCount,
 	}
 }
 
@@ -85,30 +107,41 @@ func (a Actor) WithdrawBalance(rt Runtime, params *WithdrawBalanceParams) *abi.E
 	// for clients -> only the client i.e the recipient can withdraw
 	rt.ValidateImmediateCallerIs(approvedCallers...)
 
-	amountExtracted := abi.NewTokenAmount(0)
-	var st State
-	rt.StateTransaction(&st, func() {
-		msm, err := st.mutator(adt.AsStore(rt)).withEscrowTable(WritePermission).
-			withLockedTable(WritePermission).build()
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
-
-		// The withdrawable amount might be slightly less than nominal
-		// depending on whether or not all relevant entries have been processed
-		// by cron
-		minBalance, err := msm.lockedTable.Get(nominal)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get locked balance")
+	amountExtracted := withdrawEscrow(rt, nominal, params.Amount)
+	code := builtin.TransferFunds(rt, recipient, amountExtracted, builtin.TransferWithdrawal)
+	builtin.RequireSuccess(rt, code, "failed to send funds")
+	return nil
+}
 
-		ex, err := msm.escrowTable.SubtractWithMinimum(nominal, params.Amount, minBalance)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to subtract from escrow table")
+type WithdrawBalanceToParams struct {
+	ProviderOrClientAddress addr.Address
+	Amount                  abi.TokenAmount
+	Recipient               addr.Address
+}
 
-		err = msm.commitState()
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
+// Attempt to withdraw the specified amount from the balance held in escrow, sending it to a designated recipient
+// rather than the implied one (a provider's owner, or the client itself).
+// If less than the specified amount is available, yields the entire available balance.
+// A provider's worker may withdraw only to the owner; any other recipient must be designated by the owner.
+func (a Actor) WithdrawBalanceTo(rt Runtime, params *WithdrawBalanceToParams) *abi.EmptyValue {
+	if params.Amount.LessThan(big.Zero()) {
+		rt.Abortf(exitcode.ErrIllegalArgument, "negative amount %v", params.Amount)
+	}
+	if params.Recipient.Empty() {
+		rt.Abortf(exitcode.ErrIllegalArgument, "recipient address must be specified")
+	}
 
-		amountExtracted = ex
-	})
+	nominal, implied, approvedCallers := escrowAddress(rt, params.ProviderOrClientAddress)
+	if params.Recipient == implied {
+		rt.ValidateImmediateCallerIs(approvedCallers...)
+	} else {
+		// Only the party to which funds would otherwise be sent may redirect them.
+		rt.ValidateImmediateCallerIs(implied)
+	}
 
-	code := rt.Send(recipient, builtin.MethodSend, nil, amountExtracted, &builtin.Discard{})
-	builtin.RequireSuccess(rt, code, "failed to send funds")
+	amountExtracted := withdrawEscrow(rt, nominal, params.Amount)
+	code := builtin.TransferFunds(rt, params.Recipient, amountExtracted, builtin.TransferWithdrawal)
+	builtin.RequireSuccess(rt, code, "failed to send funds to %v", params.Recipient)
 	return nil
 }
 
@@ -120,42 +153,75 @@ func (a Actor) AddBalance(rt Runtime, providerOrClientAddress *addr.Address) *ab
 	// only signing parties can add balance for client AND provider.
 	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
 
-	nominal, _, _ := escrowAddress(rt, *providerOrClientAddress)
+	addEscrowBalance(rt, *providerOrClientAddress, msgValue)
+	return nil
+}
 
-	var st State
-	rt.StateTransaction(&st, func() {
-		msm, err := st.mutator(adt.AsStore(rt)).withEscrowTable(WritePermission).
-			withLockedTable(WritePermission).build()
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+// Deposits the received value into the balance held in escrow for a named client or provider, sponsoring that party.
+// Unlike AddBalance, any actor may call this method.
+// The value is credited to the named party, and only that party may withdraw it.
+func (a Actor) AddBalanceFor(rt Runtime, providerOrClientAddress *addr.Address) *abi.EmptyValue {
+	msgValue := rt.ValueReceived()
+	builtin.RequireParam(rt, msgValue.GreaterThan(big.Zero()), "balance to add must be greater than zero")
 
-		err = msm.escrowTable.Add(nominal, msgValue)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to add balance to escrow table")
+	rt.ValidateImmediateCallerAcceptAny()
 
-		err = msm.commitState()
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
-	})
+	addEscrowBalance(rt, *providerOrClientAddress, msgValue)
 	return nil
 }
 
-//type PublishStorageDealsParams struct {
-//	Deals []ClientDealProposal
-//}
-type PublishStorageDealsParams = market0.PublishStorageDealsParams
+type PublishStorageDealsParams struct {
+	Deals []ClientDealProposal
+}
 
-//type PublishStorageDealsReturn struct {
-//	IDs []abi.DealID
-//}
-type PublishStorageDealsReturn = market0.PublishStorageDealsReturn
+type PublishStorageDealsReturn struct {
+	IDs        []abi.DealID        // IDs of the published deals, in order of their proposals
+	ValidDeals bitfield.BitField   // Indices of the proposals that were published
+	FailCodes  []exitcode.ExitCode // Exit code for each proposal, Ok for those published
+}
 
 // Publish a new set of storage deals (not yet included in a sector).
+// Each proposal is published only if it is valid, and the return value identifies the proposals that failed and
+// why. The call aborts only if the batch as a whole is invalid, or if every proposal fails (with the first failure).
+// The client storage fee for these deals is paid per epoch.
 func (a Actor) PublishStorageDeals(rt Runtime, params *PublishStorageDealsParams) *PublishStorageDealsReturn {
+	withModeParams := &PublishStorageDealsWithPaymentModeParams{
+		Deals:       params.Deals,
+		PaymentMode: DealPaymentPerEpoch,
+	}
+	return a.PublishStorageDealsWithPaymentMode(rt, withModeParams)
+}
+
+type PublishStorageDealsWithPaymentModeParams struct {
+	Deals       []ClientDealProposal
+	PaymentMode DealPaymentMode
+}
+
+// Publish a new set of storage deals (not yet included in a sector), all with the same payment mode.
+// Deals paid upfront have the client storage fee released to the provider only when the deal completes,
+// or pro-rated between provider and client if the deal is terminated early.
+func (a Actor) PublishStorageDealsWithPaymentMode(rt Runtime, params *PublishStorageDealsWithPaymentModeParams) *PublishStorageDealsReturn {
+	return publishStorageDeals(rt, params, false)
+}
+
+// Publish a new set of storage deals as PublishStorageDealsWithPaymentMode, with each deal's ID derived from
+// its proposal CID (see DerivedDealID) rather than generated sequentially.
+// The ID of a deal is thus known before it is published, and may be used to look up the deal's proposal and state.
+// Publishing a deal that has already been published with a derived ID has no effect, and returns the same ID.
+func (a Actor) PublishStorageDealsWithDerivedIDs(rt Runtime, params *PublishStorageDealsWithPaymentModeParams) *PublishStorageDealsReturn {
+	return publishStorageDeals(rt, params, true)
+}
 
+func publishStorageDeals(rt Runtime, params *PublishStorageDealsWithPaymentModeParams, deriveIDs bool) *PublishStorageDealsReturn {
 	// Deal message must have a From field identical to the provider of all the deals.
 	// This allows us to retain and verify only the client's signature in each deal proposal itself.
 	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
 	if len(params.Deals) == 0 {
 		rt.Abortf(exitcode.ErrIllegalArgument, "empty deals parameter")
 	}
+	if params.PaymentMode != DealPaymentPerEpoch && params.PaymentMode != DealPaymentUpfront {
+		rt.Abortf(exitcode.ErrIllegalArgument, "invalid deal payment mode %d", params.PaymentMode)
+	}
 
 	// All deals should have the same provider so get worker once
 	providerRaw := params.Deals[0].Proposal.Provider
@@ -170,20 +236,290 @@ func (a Actor) PublishStorageDeals(rt Runtime, params *PublishStorageDealsParams
 		rt.Abortf(exitcode.ErrIllegalArgument, "deal provider is not a StorageMinerActor")
 	}
 
-	caller := rt.Caller()
-	_, worker, controllers := builtin.RequestMinerControlAddrs(rt, provider)
-	callerOk := caller == worker
-	for _, controller := range controllers {
-		if callerOk {
-			break
+	validateCallerIsProviderControl(rt, provider)
+
+	baselinePower := requestCurrentBaselinePower(rt)
+	networkRawPower, networkQAPower := requestCurrentNetworkPower(rt)
+
+	// Each proposal is validated independently against the state and the proposals preceding it, and only those
+	// that are valid are published. Validation completes before any state changes, including the use of a verified
+	// client's data cap, so that a proposal found invalid leaves no trace.
+	var st State
+	rt.StateReadonly(&st)
+	msm, err := st.mutator(adt.AsStore(rt)).withPendingProposals(ReadOnlyPermission).
+		withDealProposals(ReadOnlyPermission).withEscrowTable(ReadOnlyPermission).
+		withLockedTable(ReadOnlyPermission).withAuthorizedProposals(ReadOnlyPermission).build()
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+	batch := publishBatch{
+		proposalCids:   make(map[cid.Cid]int, len(params.Deals)),
+		lockedInBatch:  make(map[addr.Address]abi.TokenAmount),
+		derivedIDs:     make(map[int]abi.DealID),
+		alreadyPresent: make(map[int]bool),
+		authorizations: make(map[int]cid.Cid),
+	}
+	failCodes := make([]exitcode.ExitCode, len(params.Deals))
+	var validIdxs []uint64
+	var validDeals []ClientDealProposal
+	var firstErr error
+	for di := range params.Deals {
+		deal := params.Deals[di]
+		if err := validatePublishableDeal(rt, msm, &batch, di, &deal, provider, providerRaw, deriveIDs, params.PaymentMode,
+			networkRawPower, networkQAPower, baselinePower); err != nil {
+			failCodes[di] = exitcode.Unwrap(err, exitcode.ErrIllegalArgument)
+			if firstErr == nil {
+				firstErr = xerrors.Errorf("proposal %d: %w", di, err)
+			}
+			rt.Log(rtt.INFO, "invalid deal proposal %d: %s", di, err)
+			continue
 		}
-		callerOk = caller == controller
+		validIdxs = append(validIdxs, uint64(di))
+		validDeals = append(validDeals, deal)
 	}
-	if !callerOk {
-		rt.Abortf(exitcode.ErrForbidden, "caller %v is not worker or control address of provider %v", caller, provider)
+	if len(validDeals) == 0 {
+		builtin.RequireNoErr(rt, firstErr, exitcode.ErrIllegalArgument, "all deal proposals failed validation")
+	}
+
+	newDealIds := make([]abi.DealID, 0, len(validDeals))
+	rt.StateTransaction(&st, func() {
+		msm, err := st.mutator(adt.AsStore(rt)).withPendingProposals(WritePermission).
+			withDealProposals(WritePermission).withDealsByEpoch(WritePermission).withEscrowTable(WritePermission).
+			withLockedTable(WritePermission).withUpfrontPaymentDeals(WritePermission).
+			withDealsByProvider(WritePermission).withDealsByPiece(WritePermission).
+			withAuthorizedProposals(WritePermission).build()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+		for i, deal := range validDeals {
+			di := int(validIdxs[i])
+			if batch.alreadyPresent[di] {
+				newDealIds = append(newDealIds, batch.derivedIDs[di])
+				continue
+			}
+
+			var id abi.DealID
+			if deriveIDs {
+				id = batch.derivedIDs[di]
+			} else {
+				id = msm.generateStorageDealID()
+			}
+
+			err = msm.lockClientAndProviderBalances(&deal.Proposal, params.PaymentMode)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to lock balance")
+
+			pcid, err := deal.Proposal.Cid()
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to take cid of proposal %d", di)
+			err = msm.addPendingProposal(pcid, &deal.Proposal)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set pending deal")
+
+			err = msm.dealProposals.Set(id, &deal.Proposal)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set deal")
+			err = msm.indexDeal(id, &deal.Proposal)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to index deal %d", id)
+			emitDealEvent(rt, EventDealPublished, id, &deal.Proposal)
+
+			if params.PaymentMode == DealPaymentUpfront {
+				err = msm.upfrontDeals.Put(abi.UIntKey(uint64(id)))
+				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set upfront payment deal")
+			}
+
+			if authCid, ok := batch.authorizations[di]; ok {
+				err = msm.authorizedProposals.Delete(abi.CidKey(authCid))
+				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to consume authorization of proposal %d", di)
+			}
+
+			// We should randomize the first epoch for when the deal will be processed so an attacker isn't able to
+			// schedule too many deals for the same tick.
+			processEpoch := GenRandNextEpoch(deal.Proposal.StartEpoch, id)
+
+			err = msm.dealsByEpoch.Put(processEpoch, id)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set deal ops by epoch")
+
+			newDealIds = append(newDealIds, id)
+		}
+
+		err = msm.commitState()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
+	})
+
+	return &PublishStorageDealsReturn{
+		IDs:        newDealIds,
+		ValidDeals: bitfield.NewFromSet(validIdxs),
+		FailCodes:  failCodes,
+	}
+}
+
+// Tracks the proposals of a batch being validated for publication.
+type publishBatch struct {
+	proposalCids   map[cid.Cid]int                  // Index of the first valid proposal with each CID
+	lockedInBatch  map[addr.Address]abi.TokenAmount // Balances to be locked for valid proposals
+	derivedIDs     map[int]abi.DealID               // Derived IDs of valid proposals, by index
+	alreadyPresent map[int]bool                     // Valid proposals already published (with derived IDs)
+	authorizations map[int]cid.Cid                  // CIDs of the authorizations of valid proposals that have no signature, by index
+}
+
+// Validates a proposal for publication, normalising its provider and client addresses.
+// Balances are checked against the state with the balances locked for the valid proposals preceding it, and the
+// data cap of a verified deal's client is used, so a valid proposal is one that can be published.
+func validatePublishableDeal(rt Runtime, msm *marketStateMutation, batch *publishBatch, di int, deal *ClientDealProposal,
+	provider, providerRaw addr.Address, deriveIDs bool, paymentMode DealPaymentMode, networkRawPower, networkQAPower,
+	baselinePower abi.StoragePower) error {
+	// A client that cannot sign, such as a multisig, authorizes its proposal on chain instead.
+	authCid, err := deal.Proposal.Cid()
+	if err != nil {
+		return exitcode.ErrIllegalArgument.Wrapf("failed to take cid of proposal: %w", err)
+	}
+	authorized, err := msm.authorizedProposals.Has(abi.CidKey(authCid))
+	if err != nil {
+		return xerrors.Errorf("failed to check for authorization of proposal: %w", err)
+	}
+	if err := validateDeal(rt, *deal, authorized, networkRawPower, networkQAPower, baselinePower); err != nil {
+		return err
+	}
+	if deal.Proposal.Renewal != nil && paymentMode == DealPaymentUpfront {
+		return exitcode.ErrIllegalArgument.Wrapf("renewable deal cannot be paid upfront")
+	}
+	if deal.Proposal.ProviderPaysStorageFee() && paymentMode == DealPaymentUpfront {
+		return exitcode.ErrIllegalArgument.Wrapf("deal paid by the provider cannot be paid upfront")
+	}
+
+	if deal.Proposal.Provider != provider && deal.Proposal.Provider != providerRaw {
+		return exitcode.ErrIllegalArgument.Wrapf("cannot publish deals from different providers at the same time")
+	}
+
+	client, ok := rt.ResolveAddress(deal.Proposal.Client)
+	if !ok {
+		return exitcode.ErrNotFound.Wrapf("failed to resolve client address %v", deal.Proposal.Client)
+	}
+	// Normalise provider and client addresses in the proposal stored on chain (after signature verification).
+	unresolvedClient := deal.Proposal.Client
+	deal.Proposal.Provider = provider
+	deal.Proposal.Client = client
+
+	pcid, err := deal.Proposal.Cid()
+	if err != nil {
+		return exitcode.ErrIllegalArgument.Wrapf("failed to take cid of proposal: %w", err)
+	}
+
+	if deriveIDs {
+		id, err := DerivedDealID(pcid)
+		if err != nil {
+			return exitcode.ErrIllegalArgument.Wrapf("failed to derive id of proposal: %w", err)
+		}
+		// A proposal repeated in the batch, or already published, is published (again) with the same ID.
+		if prev, ok := batch.proposalCids[pcid]; ok {
+			batch.derivedIDs[di] = batch.derivedIDs[prev]
+			batch.alreadyPresent[di] = true
+			return nil
+		}
+		existing, found, err := msm.dealProposals.Get(id)
+		if err != nil {
+			return xerrors.Errorf("failed to load deal %d: %w", id, err)
+		}
+		if found {
+			existingCid, err := existing.Cid()
+			if err != nil {
+				return xerrors.Errorf("failed to take cid of deal %d: %w", id, err)
+			}
+			if !existingCid.Equals(pcid) {
+				return exitcode.ErrIllegalArgument.Wrapf("derived id %d of proposal is taken by another deal", id)
+			}
+			batch.derivedIDs[di] = id
+			batch.alreadyPresent[di] = true
+			return nil
+		}
+		batch.derivedIDs[di] = id
+	}
+
+	if _, ok := batch.proposalCids[pcid]; ok {
+		return exitcode.ErrIllegalArgument.Wrapf("cannot publish duplicate deals")
+	}
+	if has, err := msm.pendingDeals.Has(abi.CidKey(pcid)); err != nil {
+		return xerrors.Errorf("failed to check for existence of deal proposal: %w", err)
+	} else if has {
+		return exitcode.ErrIllegalArgument.Wrapf("cannot publish duplicate deals")
+	}
+
+	clientLock := deal.Proposal.ClientBalanceRequirement()
+	if err := batch.checkBalance(msm, client, clientLock); err != nil {
+		return xerrors.Errorf("failed to lock client funds: %w", err)
+	}
+	providerLock := deal.Proposal.ProviderBalanceRequirement()
+	if err := batch.checkBalance(msm, provider, providerLock); err != nil {
+		return xerrors.Errorf("failed to lock provider funds: %w", err)
+	}
+
+	// Check VerifiedClient allowed cap and deduct PieceSize from cap.
+	// Either the DealSize is within the available DataCap of the VerifiedClient
+	// or this deal will fail. We do not allow a deal that is partially verified.
+	if deal.Proposal.VerifiedDeal {
+		code := rt.Send(
+			builtin.VerifiedRegistryActorAddr,
+			builtin.MethodsVerifiedRegistry.UseBytes,
+			&verifreg.UseBytesParams{
+				Address:  client,
+				DealSize: big.NewIntUnsigned(uint64(deal.Proposal.PieceSize)),
+			},
+			abi.NewTokenAmount(0),
+			&builtin.Discard{},
+		)
+		if !code.IsSuccess() {
+			return code.Wrapf("failed to add verified deal for client: %v", unresolvedClient)
+		}
+	}
+
+	batch.proposalCids[pcid] = di
+	if authorized {
+		batch.authorizations[di] = authCid
+	}
+	batch.lock(client, clientLock)
+	batch.lock(provider, providerLock)
+	return nil
+}
+
+func (b *publishBatch) lock(a addr.Address, amount abi.TokenAmount) {
+	if prev, ok := b.lockedInBatch[a]; ok {
+		amount = big.Add(prev, amount)
+	}
+	b.lockedInBatch[a] = amount
+}
+
+// Checks that an address's escrow balance covers its locked balance, the balance to be locked for the batch's
+// valid proposals, and an additional amount.
+func (b *publishBatch) checkBalance(msm *marketStateMutation, a addr.Address, amount abi.TokenAmount) error {
+	locked, err := msm.lockedTable.Get(a)
+	if err != nil {
+		return xerrors.Errorf("failed to get locked balance: %w", err)
+	}
+	escrow, err := msm.escrowTable.Get(a)
+	if err != nil {
+		return xerrors.Errorf("failed to get escrow balance: %w", err)
+	}
+	if batchLocked, ok := b.lockedInBatch[a]; ok {
+		locked = big.Add(locked, batchLocked)
+	}
+	if big.Add(locked, amount).GreaterThan(escrow) {
+		return exitcode.ErrInsufficientFunds.Wrapf("insufficient balance for addr %s: escrow balance %s < locked %s + required %s",
+			a, escrow, locked, amount)
+	}
+	return nil
+}
+
+type OfferStorageDealsParams struct {
+	Deals []DealProposal
+}
+
+// Publish a set of storage deal offers from the calling client, to be accepted on-chain by each deal's provider.
+// The client's collateral and storage fee are locked when a deal is offered, so an offer carries no client signature.
+// The provider's collateral is locked when the provider accepts the offer with AcceptStorageDealOffers.
+// Offers that have not been accepted by their start epoch are removed and the client's funds unlocked.
+// The client storage fee for offered deals is paid per epoch.
+func (a Actor) OfferStorageDeals(rt Runtime, params *OfferStorageDealsParams) *PublishStorageDealsReturn {
+	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
+	if len(params.Deals) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "empty deals parameter")
 	}
+	client := rt.Caller()
 
-	resolvedAddrs := make(map[addr.Address]addr.Address, len(params.Deals))
 	baselinePower := requestCurrentBaselinePower(rt)
 	networkRawPower, networkQAPower := requestCurrentNetworkPower(rt)
 
@@ -191,33 +527,42 @@ func (a Actor) PublishStorageDeals(rt Runtime, params *PublishStorageDealsParams
 	var st State
 	rt.StateTransaction(&st, func() {
 		msm, err := st.mutator(adt.AsStore(rt)).withPendingProposals(WritePermission).
-			withDealProposals(WritePermission).withDealsByEpoch(WritePermission).withEscrowTable(WritePermission).
+			withDealOffers(WritePermission).withDealsByEpoch(WritePermission).withEscrowTable(WritePermission).
 			withLockedTable(WritePermission).build()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
 
-		// All storage dealProposals will be added in an atomic transaction; this operation will be unrolled if any of them fails.
 		for di, deal := range params.Deals {
-			validateDeal(rt, deal, networkRawPower, networkQAPower, baselinePower)
+			err := validateDealProposal(rt, deal, networkRawPower, networkQAPower, baselinePower)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid deal offer %d", di)
 
-			if deal.Proposal.Provider != provider && deal.Proposal.Provider != providerRaw {
-				rt.Abortf(exitcode.ErrIllegalArgument, "cannot publish deals from different providers at the same time")
+			dealClient, ok := rt.ResolveAddress(deal.Client)
+			if !ok {
+				rt.Abortf(exitcode.ErrNotFound, "failed to resolve client address %v", deal.Client)
+			}
+			if dealClient != client {
+				rt.Abortf(exitcode.ErrForbidden, "caller %v is not the client %v of deal %d", client, deal.Client, di)
 			}
 
-			client, ok := rt.ResolveAddress(deal.Proposal.Client)
+			provider, ok := rt.ResolveAddress(deal.Provider)
 			if !ok {
-				rt.Abortf(exitcode.ErrNotFound, "failed to resolve client address %v", deal.Proposal.Client)
+				rt.Abortf(exitcode.ErrNotFound, "failed to resolve provider address %v", deal.Provider)
 			}
-			// Normalise provider and client addresses in the proposal stored on chain (after signature verification).
-			deal.Proposal.Provider = provider
-			resolvedAddrs[deal.Proposal.Client] = client
-			deal.Proposal.Client = client
+			codeID, ok := rt.GetActorCodeCID(provider)
+			builtin.RequireParam(rt, ok, "no codeId for address %v", provider)
+			if !codeID.Equals(builtin.StorageMinerActorCodeID) {
+				rt.Abortf(exitcode.ErrIllegalArgument, "deal provider is not a StorageMinerActor")
+			}
+
+			// Normalise provider and client addresses in the offer stored on chain.
+			deal.Client = client
+			deal.Provider = provider
 
-			err := msm.lockClientAndProviderBalances(&deal.Proposal)
+			err = msm.lockClientBalance(&deal)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to lock balance")
 
 			id := msm.generateStorageDealID()
 
-			pcid, err := deal.Proposal.Cid()
+			pcid, err := deal.Cid()
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to take cid of proposal %d", di)
 
 			has, err := msm.pendingDeals.Has(abi.CidKey(pcid))
@@ -226,17 +571,15 @@ func (a Actor) PublishStorageDeals(rt Runtime, params *PublishStorageDealsParams
 				rt.Abortf(exitcode.ErrIllegalArgument, "cannot publish duplicate deals")
 			}
 
-			err = msm.pendingDeals.Put(abi.CidKey(pcid))
+			err = msm.addPendingProposal(pcid, &deal)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set pending deal")
 
-			err = msm.dealProposals.Set(id, &deal.Proposal)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set deal")
-
-			// We should randomize the first epoch for when the deal will be processed so an attacker isn't able to
-			// schedule too many deals for the same tick.
-			processEpoch := GenRandNextEpoch(deal.Proposal.StartEpoch, id)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to generate random process epoch")
+			err = msm.dealOffers.Set(id, &deal)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set deal offer")
 
+			// The offer is processed at the same epoch as the deal would be if accepted,
+			// at which point an unaccepted offer is refunded.
+			processEpoch := GenRandNextEpoch(deal.StartEpoch, id)
 			err = msm.dealsByEpoch.Put(processEpoch, id)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set deal ops by epoch")
 
@@ -248,28 +591,223 @@ func (a Actor) PublishStorageDeals(rt Runtime, params *PublishStorageDealsParams
 	})
 
 	for _, deal := range params.Deals {
-		// Check VerifiedClient allowed cap and deduct PieceSize from cap.
-		// Either the DealSize is within the available DataCap of the VerifiedClient
-		// or this message will fail. We do not allow a deal that is partially verified.
-		if deal.Proposal.VerifiedDeal {
-			resolvedClient, ok := resolvedAddrs[deal.Proposal.Client]
-			builtin.RequireParam(rt, ok, "could not get resolvedClient client address")
-
+		if deal.VerifiedDeal {
 			code := rt.Send(
 				builtin.VerifiedRegistryActorAddr,
 				builtin.MethodsVerifiedRegistry.UseBytes,
 				&verifreg.UseBytesParams{
-					Address:  resolvedClient,
-					DealSize: big.NewIntUnsigned(uint64(deal.Proposal.PieceSize)),
+					Address:  client,
+					DealSize: big.NewIntUnsigned(uint64(deal.PieceSize)),
 				},
 				abi.NewTokenAmount(0),
 				&builtin.Discard{},
 			)
-			builtin.RequireSuccess(rt, code, "failed to add verified deal for client: %v", deal.Proposal.Client)
+			builtin.RequireSuccess(rt, code, "failed to add verified deal for client: %v", deal.Client)
+		}
+	}
+
+	// Offers are all published or none are.
+	validIdxs := make([]uint64, len(params.Deals))
+	for i := range validIdxs {
+		validIdxs[i] = uint64(i)
+	}
+	return &PublishStorageDealsReturn{
+		IDs:        newDealIds,
+		ValidDeals: bitfield.NewFromSet(validIdxs),
+		FailCodes:  make([]exitcode.ExitCode, len(params.Deals)),
+	}
+}
+
+type AcceptStorageDealOffersParams struct {
+	DealIDs []abi.DealID
+}
+
+// Accept a set of deals offered by clients, locking the provider collateral for each.
+// All the deals must have the same provider, and the caller must be the provider's worker or a control address.
+// An accepted offer becomes a published deal with the same deal ID, which must be activated by its start epoch.
+func (a Actor) AcceptStorageDealOffers(rt Runtime, params *AcceptStorageDealOffersParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
+	if len(params.DealIDs) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "empty deal IDs parameter")
+	}
+
+	// All deals should have the same provider so check the caller once.
+	var st State
+	rt.StateReadonly(&st)
+	offers, err := AsDealProposalArray(adt.AsStore(rt), st.DealOffers)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal offers")
+	firstOffer, found, err := offers.Get(params.DealIDs[0])
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal offer %d", params.DealIDs[0])
+	if !found {
+		rt.Abortf(exitcode.ErrNotFound, "no offer for deal %d", params.DealIDs[0])
+	}
+	provider := firstOffer.Provider
+	validateCallerIsProviderControl(rt, provider)
+
+	rt.StateTransaction(&st, func() {
+		msm, err := st.mutator(adt.AsStore(rt)).withDealOffers(WritePermission).
+			withDealProposals(WritePermission).withEscrowTable(ReadOnlyPermission).
+			withLockedTable(WritePermission).withDealsByProvider(WritePermission).withDealsByPiece(WritePermission).build()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+		for _, dealID := range params.DealIDs {
+			offer, found, err := msm.dealOffers.Get(dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal offer %d", dealID)
+			if !found {
+				rt.Abortf(exitcode.ErrNotFound, "no offer for deal %d", dealID)
+			}
+			if offer.Provider != provider {
+				rt.Abortf(exitcode.ErrIllegalArgument, "cannot accept deals from different providers at the same time")
+			}
+			if rt.CurrEpoch() > offer.StartEpoch {
+				rt.Abortf(exitcode.ErrIllegalArgument, "deal %d start epoch %d has already elapsed", dealID, offer.StartEpoch)
+			}
+
+			err = msm.lockProviderBalance(offer)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to lock balance")
+
+			// The deal remains pending, and was scheduled for processing when offered.
+			err = msm.dealOffers.Delete(dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete deal offer %d", dealID)
+			err = msm.dealProposals.Set(dealID, offer)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set deal %d", dealID)
+			err = msm.indexDeal(dealID, offer)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to index deal %d", dealID)
+			emitDealEvent(rt, EventDealPublished, dealID, offer)
+		}
+
+		err = msm.commitState()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
+	})
+	return nil
+}
+
+type CancelStorageDealsParams struct {
+	DealIDs []abi.DealID
+}
+
+// Cancels deals that have been published but not yet activated, before their start epoch.
+// Either the client or the provider (through its worker or a control address) may cancel a deal.
+// Both parties' locked funds are unlocked in full, with no penalty, and a verified client's data cap is restored.
+func (a Actor) CancelStorageDeals(rt Runtime, params *CancelStorageDealsParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
+	if len(params.DealIDs) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "empty deal IDs parameter")
+	}
+
+	// Check the caller may cancel every deal before changing any state, since checking the control
+	// addresses of a provider requires a send.
+	var st State
+	rt.StateReadonly(&st)
+	validateCallerIsDealParty(rt, &st, params.DealIDs)
+
+	var cancelledVerifiedDeals []*DealProposal
+	rt.StateTransaction(&st, func() {
+		msm, err := st.mutator(adt.AsStore(rt)).withDealProposals(WritePermission).
+			withDealStates(ReadOnlyPermission).withPendingProposals(WritePermission).
+			withDealsByEpoch(WritePermission).withLockedTable(WritePermission).
+			withUpfrontPaymentDeals(WritePermission).withDealsByProvider(WritePermission).
+			withDealsByPiece(WritePermission).withActivationExtensions(WritePermission).build()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+		for _, dealID := range params.DealIDs {
+			deal, err := getDealProposal(msm.dealProposals, dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal %d", dealID)
+			_, activated, err := msm.dealStates.Get(dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get state for deal %d", dealID)
+			if activated {
+				rt.Abortf(exitcode.ErrIllegalArgument, "deal %d has already been activated", dealID)
+			}
+			if rt.CurrEpoch() >= deal.StartEpoch {
+				rt.Abortf(exitcode.ErrIllegalArgument, "deal %d start epoch %d has already been reached", dealID, deal.StartEpoch)
+			}
+
+			paymentMode, err := msm.popUpfrontPaymentDeal(dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check payment mode for deal %d", dealID)
+			msm.processDealCancelled(rt, deal, paymentMode == DealPaymentUpfront)
+			if deal.VerifiedDeal {
+				cancelledVerifiedDeals = append(cancelledVerifiedDeals, deal)
+			}
+			err = msm.removeActivationExtension(dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to remove activation extension")
+
+			// The deal's first processing epoch is no earlier than its start epoch, so it is still scheduled there.
+			processEpoch := GenRandNextEpoch(deal.StartEpoch, dealID)
+			err = msm.dealsByEpoch.RemoveKey(abi.UIntKey(uint64(processEpoch)), dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to unschedule deal %d", dealID)
+
+			dcid, err := deal.Cid()
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to calculate CID for proposal %v", dealID)
+			err = msm.removePendingProposal(dcid)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete pending proposal %d (%v)", dealID, dcid)
+
+			err = msm.dealProposals.Delete(dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete deal proposal %d", dealID)
+			err = msm.unindexDeal(dealID, deal)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to remove deal %d from indexes", dealID)
 		}
+
+		err = msm.commitState()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
+	})
+
+	restoreVerifiedDealBytes(rt, cancelledVerifiedDeals, "cancelled")
+	return nil
+}
+
+type DeclineRenewalParams struct {
+	DealIDs []abi.DealID
+}
+
+// Declines the renewal of active renewable deals, which then end at their end epoch.
+// Either the client or the provider (through its worker or a control address) may decline a deal's renewal,
+// up to the deal's end epoch. The client's storage fee for the renewal term is unlocked.
+func (a Actor) DeclineRenewal(rt Runtime, params *DeclineRenewalParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
+	if len(params.DealIDs) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "empty deal IDs parameter")
 	}
 
-	return &PublishStorageDealsReturn{IDs: newDealIds}
+	var st State
+	rt.StateReadonly(&st)
+	validateCallerIsDealParty(rt, &st, params.DealIDs)
+
+	rt.StateTransaction(&st, func() {
+		msm, err := st.mutator(adt.AsStore(rt)).withDealProposals(ReadOnlyPermission).
+			withDealStates(WritePermission).withLockedTable(WritePermission).build()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+		for _, dealID := range params.DealIDs {
+			deal, err := getDealProposal(msm.dealProposals, dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal %d", dealID)
+			if deal.Renewal == nil {
+				rt.Abortf(exitcode.ErrIllegalArgument, "deal %d is not renewable", dealID)
+			}
+			if rt.CurrEpoch() >= deal.EndEpoch {
+				rt.Abortf(exitcode.ErrIllegalArgument, "deal %d end epoch %d has already been reached", dealID, deal.EndEpoch)
+			}
+
+			state, activated, err := msm.dealStates.Get(dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get state for deal %d", dealID)
+			if !activated {
+				rt.Abortf(exitcode.ErrIllegalArgument, "deal %d has not been activated", dealID)
+			}
+			if state.SlashEpoch != epochUndefined {
+				rt.Abortf(exitcode.ErrIllegalArgument, "deal %d has been terminated", dealID)
+			}
+			if state.RenewalDeclined {
+				rt.Abortf(exitcode.ErrIllegalArgument, "renewal of deal %d has already been declined", dealID)
+			}
+
+			msm.processDealRenewalDeclined(rt, deal, state)
+			err = msm.dealStates.Set(dealID, state)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set state for deal %d", dealID)
+		}
+
+		err = msm.commitState()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
+	})
+	return nil
 }
 
 // Changed since v2:
@@ -313,13 +851,15 @@ func (a Actor) VerifyDealsForActivation(rt Runtime, params *VerifyDealsForActiva
 
 	proposals, err := AsDealProposalArray(store, st.Proposals)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal proposals")
+	extensions, err := adt.AsMap(store, st.ActivationExtensions, builtin.DefaultHamtBitwidth)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load activation extensions")
 
 	weights := make([]SectorWeights, len(params.Sectors))
 	for i, sector := range params.Sectors {
 		// Pass the current epoch as the activation epoch for validation.
 		// The sector activation epoch isn't yet known, but it's still more helpful to fail now if the deal
 		// is so late that a sector activating now couldn't include it.
-		dealWeight, verifiedWeight, dealSpace, err := validateAndComputeDealWeight(proposals, sector.DealIDs, minerAddr, sector.SectorExpiry, currEpoch)
+		dealWeight, verifiedWeight, dealSpace, err := validateAndComputeDealWeight(proposals, extensions, sector.DealIDs, minerAddr, sector.SectorExpiry, currEpoch)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to validate deal proposals for activation")
 
 		weights[i] = SectorWeights{
@@ -329,112 +869,493 @@ func (a Actor) VerifyDealsForActivation(rt Runtime, params *VerifyDealsForActiva
 		}
 	}
 
-	return &VerifyDealsForActivationReturn{
-		Sectors: weights,
-	}
+	return &VerifyDealsForActivationReturn{
+		Sectors: weights,
+	}
+}
+
+//type ActivateDealsParams struct {
+//	DealIDs      []abi.DealID
+//	SectorExpiry abi.ChainEpoch
+//}
+type ActivateDealsParams = market0.ActivateDealsParams
+
+// Verify that a given set of storage deals is valid for a sector currently being ProveCommitted,
+// update the market's internal state accordingly.
+func (a Actor) ActivateDeals(rt Runtime, params *ActivateDealsParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
+	minerAddr := rt.Caller()
+	currEpoch := rt.CurrEpoch()
+
+	var st State
+	store := adt.AsStore(rt)
+
+	// Update deal dealStates.
+	rt.StateTransaction(&st, func() {
+		_, _, _, err := ValidateDealsForActivation(&st, store, params.DealIDs, minerAddr, params.SectorExpiry, currEpoch)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to validate dealProposals for activation")
+
+		msm, err := st.mutator(adt.AsStore(rt)).withDealStates(WritePermission).
+			withPendingProposals(ReadOnlyPermission).withDealProposals(ReadOnlyPermission).
+			withUpfrontPaymentDeals(WritePermission).withActivationExtensions(WritePermission).build()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+		for _, dealID := range params.DealIDs {
+			// This construction could be replaced with a single "update deal state" state method, possibly batched
+			// over all deal ids at once.
+			_, found, err := msm.dealStates.Get(dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get state for dealId %d", dealID)
+			if found {
+				rt.Abortf(exitcode.ErrIllegalArgument, "deal %d already included in another sector", dealID)
+			}
+
+			proposal, err := getDealProposal(msm.dealProposals, dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get dealId %d", dealID)
+
+			propc, err := proposal.Cid()
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to calculate proposal CID")
+
+			has, err := msm.pendingDeals.Has(abi.CidKey(propc))
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get pending proposal %v", propc)
+
+			if !has {
+				rt.Abortf(exitcode.ErrIllegalState, "tried to activate deal that was not in the pending set (%s)", propc)
+			}
+
+			// The payment mode is recorded with the deal state from activation.
+			paymentMode, err := msm.popUpfrontPaymentDeal(dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check payment mode for deal %d", dealID)
+			err = msm.removeActivationExtension(dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to remove activation extension")
+
+			err = msm.dealStates.Set(dealID, &DealState{
+				SectorStartEpoch: currEpoch,
+				LastUpdatedEpoch: epochUndefined,
+				SlashEpoch:       epochUndefined,
+				PaymentMode:      paymentMode,
+			})
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set deal state %d", dealID)
+			emitDealEvent(rt, EventDealActivated, dealID, proposal)
+		}
+
+		err = msm.commitState()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
+	})
+
+	return nil
+}
+
+type SectorDataSpec struct {
+	DealIDs    []abi.DealID
+	SectorType abi.RegisteredSealProof
+}
+
+type ComputeDataCommitmentParams struct {
+	Inputs []*SectorDataSpec
+}
+
+type ComputeDataCommitmentReturn struct {
+	CommDs []cbg.CborCid
+}
+
+// Computes the unsealed sector CIDs of a batch of sectors from their deals, returned in the order of the inputs.
+func (a Actor) ComputeDataCommitment(rt Runtime, params *ComputeDataCommitmentParams) *ComputeDataCommitmentReturn {
+	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
+	if len(params.Inputs) > ComputeDataCommitmentMaxInputs {
+		rt.Abortf(exitcode.ErrIllegalArgument, "batch of %d too large, max %d", len(params.Inputs), ComputeDataCommitmentMaxInputs)
+	}
+
+	var st State
+	rt.StateReadonly(&st)
+	proposals, err := AsDealProposalArray(adt.AsStore(rt), st.Proposals)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal dealProposals")
+	commDs := make([]cbg.CborCid, len(params.Inputs))
+	for i, commInput := range params.Inputs {
+		pieces := make([]abi.PieceInfo, 0)
+		for _, dealID := range commInput.DealIDs {
+			deal, err := getDealProposal(proposals, dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get dealId %d", dealID)
+
+			pieces = append(pieces, abi.PieceInfo{
+				PieceCID: deal.PieceCID,
+				Size:     deal.PieceSize,
+			})
+		}
+		commD, err := rt.ComputeUnsealedSectorCID(commInput.SectorType, pieces)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to compute unsealed sectorCID: %s", err)
+		commDs[i] = (cbg.CborCid)(commD)
+	}
+	return &ComputeDataCommitmentReturn{
+		CommDs: commDs,
+	}
+}
+
+type DealEndEpochsParams struct {
+	DealIDs []abi.DealID
+}
+
+type DealEndEpoch struct {
+	DealID   abi.DealID
+	EndEpoch abi.ChainEpoch
+}
+
+type DealEndEpochsReturn struct {
+	Deals []DealEndEpoch
+}
+
+// Returns the end epochs of published deals, in the order requested.
+// The end epoch of a renewable deal is that of its renewal, which the sector holding the deal must cover.
+// This allows a miner to check that a sector's expiration covers its deals before pre-committing it.
+func (a Actor) DealEndEpochs(rt Runtime, params *DealEndEpochsParams) *DealEndEpochsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+
+	var st State
+	rt.StateReadonly(&st)
+	proposals, err := AsDealProposalArray(adt.AsStore(rt), st.Proposals)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal proposals")
+
+	deals := make([]DealEndEpoch, len(params.DealIDs))
+	for i, dealID := range params.DealIDs {
+		proposal, err := getDealProposal(proposals, dealID)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal %d", dealID)
+		deals[i] = DealEndEpoch{
+			DealID:   dealID,
+			EndEpoch: proposal.MaxEndEpoch(),
+		}
+	}
+	return &DealEndEpochsReturn{
+		Deals: deals,
+	}
+}
+
+type DealSettlementsParams struct {
+	DealIDs []abi.DealID
+}
+
+// A statement of the payments settled for a deal.
+type DealSettlement struct {
+	DealID           abi.DealID
+	PaymentMode      DealPaymentMode
+	LastSettledEpoch abi.ChainEpoch  // Epoch up to which payment has been settled, or -1 if none has been
+	AmountSettled    abi.TokenAmount // Cumulative payment transferred from the client to the provider
+}
+
+type DealSettlementsReturn struct {
+	Deals []DealSettlement
+}
+
+// Returns statements of the payments settled for published deals, in the order requested, so that clients
+// and providers can reconcile payments without inferring them from changes in escrow balances.
+// Upfront payments are settled only when a deal completes or is terminated, after which it no longer exists.
+func (a Actor) DealSettlements(rt Runtime, params *DealSettlementsParams) *DealSettlementsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+
+	var st State
+	rt.StateReadonly(&st)
+	store := adt.AsStore(rt)
+	proposals, err := AsDealProposalArray(store, st.Proposals)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal proposals")
+	states, err := AsDealStateArray(store, st.States)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal states")
+
+	deals := make([]DealSettlement, len(params.DealIDs))
+	for i, dealID := range params.DealIDs {
+		proposal, err := getDealProposal(proposals, dealID)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal %d", dealID)
+		state, _, err := states.Get(dealID)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get state for deal %d", dealID)
+		deals[i] = DealSettlement{
+			DealID:           dealID,
+			PaymentMode:      state.PaymentMode,
+			LastSettledEpoch: state.LastUpdatedEpoch,
+			AmountSettled:    dealAmountSettled(proposal, state),
+		}
+	}
+	return &DealSettlementsReturn{
+		Deals: deals,
+	}
+}
+
+type GetDealStatusParams struct {
+	DealID abi.DealID
+}
+
+type GetDealStatusReturn struct {
+	SectorStartEpoch abi.ChainEpoch  // Epoch at which the deal was activated, or -1 if it has not been
+	LastUpdatedEpoch abi.ChainEpoch  // Epoch at which the deal was last processed by cron, or -1 if it has not been
+	SlashEpoch       abi.ChainEpoch  // Epoch at which the deal was terminated, or -1 if it has not been
+	AmountPaid       abi.TokenAmount // Cumulative payment transferred from the client to the provider, negative if the provider pays
+	ClientObligation abi.TokenAmount // Payment the client's escrow remains committed to for the rest of the deal, negative if the provider pays
+}
+
+// Returns the status of a published deal and its payments, so that clients need not replicate the payment schedule.
+// The client's remaining obligation runs to the deal's slash epoch if it has been terminated, otherwise to its end
+// (including any renewal that has not been declined).
+func (a Actor) GetDealStatus(rt Runtime, params *GetDealStatusParams) *GetDealStatusReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+
+	var st State
+	rt.StateReadonly(&st)
+	store := adt.AsStore(rt)
+	proposals, err := AsDealProposalArray(store, st.Proposals)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal proposals")
+	states, err := AsDealStateArray(store, st.States)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal states")
+
+	proposal, err := getDealProposal(proposals, params.DealID)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal %d", params.DealID)
+	state, _, err := states.Get(params.DealID)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get state for deal %d", params.DealID)
+
+	obligationStart := proposal.StartEpoch
+	if state.PaymentMode != DealPaymentUpfront && state.LastUpdatedEpoch > obligationStart {
+		obligationStart = state.LastUpdatedEpoch
+	}
+	obligationEnd := dealEndEpoch(proposal, state)
+	if state.SlashEpoch != epochUndefined && state.SlashEpoch < obligationEnd {
+		obligationEnd = state.SlashEpoch
+	}
+
+	return &GetDealStatusReturn{
+		SectorStartEpoch: state.SectorStartEpoch,
+		LastUpdatedEpoch: state.LastUpdatedEpoch,
+		SlashEpoch:       state.SlashEpoch,
+		AmountPaid:       dealAmountSettled(proposal, state),
+		ClientObligation: dealPaymentBetween(proposal, obligationStart, obligationEnd),
+	}
+}
+
+type GetLockedFundsReturn struct {
+	Collateral        abi.TokenAmount // Collateral locked for the party's deals, as client or provider
+	PaymentObligation abi.TokenAmount // Storage fees locked to pay for the remaining terms of the party's deals
+}
+
+// Returns the funds a client or provider has committed to its deals, which are locked in its escrow balance.
+func (a Actor) GetLockedFunds(rt Runtime, providerOrClientAddress *addr.Address) *GetLockedFundsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+
+	nominal, ok := rt.ResolveAddress(*providerOrClientAddress)
+	if !ok {
+		rt.Abortf(exitcode.ErrIllegalArgument, "failed to resolve address %v", *providerOrClientAddress)
+	}
+
+	var st State
+	rt.StateReadonly(&st)
+	collateral, paymentObligation, err := st.LockedFunds(adt.AsStore(rt), nominal)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get locked funds for %v", nominal)
+
+	return &GetLockedFundsReturn{
+		Collateral:        collateral,
+		PaymentObligation: paymentObligation,
+	}
+}
+
+type GetPendingProposalCountReturn struct {
+	Count uint64
+}
+
+// Returns the number of deal proposals and offers in the pending set, which guards against their publication twice.
+func (a Actor) GetPendingProposalCount(rt Runtime, _ *abi.EmptyValue) *GetPendingProposalCountReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+
+	var st State
+	rt.StateReadonly(&st)
+	return &GetPendingProposalCountReturn{Count: st.PendingProposalCount}
+}
+
+type AuthorizeDealProposalParams struct {
+	Proposal DealProposal
 }
 
-//type ActivateDealsParams struct {
-//	DealIDs      []abi.DealID
-//	SectorExpiry abi.ChainEpoch
-//}
-type ActivateDealsParams = market0.ActivateDealsParams
+// Authorizes a deal proposal for publication in lieu of a signature, for a client that cannot sign proposals,
+// such as a multisig. The caller must be the proposal's client.
+// The provider may then publish the proposal with an empty client signature. The authorization is consumed when
+// the proposal is published, and is for exactly the proposal given, with its addresses as given.
+func (a Actor) AuthorizeDealProposal(rt Runtime, params *AuthorizeDealProposalParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerAcceptAny()
+	proposal := params.Proposal
 
-// Verify that a given set of storage deals is valid for a sector currently being ProveCommitted,
-// update the market's internal state accordingly.
-func (a Actor) ActivateDeals(rt Runtime, params *ActivateDealsParams) *abi.EmptyValue {
-	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
-	minerAddr := rt.Caller()
-	currEpoch := rt.CurrEpoch()
+	client, ok := rt.ResolveAddress(proposal.Client)
+	if !ok {
+		rt.Abortf(exitcode.ErrNotFound, "failed to resolve client address %v", proposal.Client)
+	}
+	if client != rt.Caller() {
+		rt.Abortf(exitcode.ErrForbidden, "caller %v is not the client %v of the proposal", rt.Caller(), proposal.Client)
+	}
+	builtin.RequireParam(rt, rt.CurrEpoch() <= proposal.StartEpoch, "proposal start epoch %d has already elapsed", proposal.StartEpoch)
 
-	var st State
-	store := adt.AsStore(rt)
+	pcid, err := proposal.Cid()
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to take cid of proposal")
 
-	// Update deal dealStates.
+	var st State
 	rt.StateTransaction(&st, func() {
-		_, _, _, err := ValidateDealsForActivation(&st, store, params.DealIDs, minerAddr, params.SectorExpiry, currEpoch)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to validate dealProposals for activation")
-
-		msm, err := st.mutator(adt.AsStore(rt)).withDealStates(WritePermission).
-			withPendingProposals(ReadOnlyPermission).withDealProposals(ReadOnlyPermission).build()
+		msm, err := st.mutator(adt.AsStore(rt)).withAuthorizedProposals(WritePermission).build()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
 
-		for _, dealID := range params.DealIDs {
-			// This construction could be replaced with a single "update deal state" state method, possibly batched
-			// over all deal ids at once.
-			_, found, err := msm.dealStates.Get(dealID)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get state for dealId %d", dealID)
-			if found {
-				rt.Abortf(exitcode.ErrIllegalArgument, "deal %d already included in another sector", dealID)
-			}
+		err = msm.authorizedProposals.Put(abi.CidKey(pcid))
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to authorize proposal %v", pcid)
 
-			proposal, err := getDealProposal(msm.dealProposals, dealID)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get dealId %d", dealID)
+		err = msm.commitState()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
+	})
+	return nil
+}
 
-			propc, err := proposal.Cid()
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to calculate proposal CID")
+type ActivationExtensionParams struct {
+	DealID   abi.DealID
+	Deadline abi.ChainEpoch
+}
 
-			has, err := msm.pendingDeals.Has(abi.CidKey(propc))
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get pending proposal %v", propc)
+// Requests that the client of a published deal allow it to be activated as late as a new deadline,
+// past its start epoch. The request takes effect only once the client grants it.
+// Must be called by the worker or a control address of the deal's provider, no later than the deal's current
+// activation deadline. A new request replaces any request not yet granted.
+func (a Actor) RequestActivationExtension(rt Runtime, params *ActivationExtensionParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
+	proposal := loadExtensibleDealProposal(rt, params.DealID)
+	validateCallerIsProviderControl(rt, proposal.Provider)
+	builtin.RequireParam(rt, params.Deadline < proposal.EndEpoch, "deadline %d must be before deal end epoch %d",
+		params.Deadline, proposal.EndEpoch)
+
+	updateActivationExtension(rt, params.DealID, func(ext *ActivationExtension, deadline abi.ChainEpoch) {
+		builtin.RequireParam(rt, params.Deadline > deadline, "deadline %d must be after current deadline %d",
+			params.Deadline, deadline)
+		ext.Requested = params.Deadline
+	})
+	return nil
+}
 
-			if !has {
-				rt.Abortf(exitcode.ErrIllegalState, "tried to activate deal that was not in the pending set (%s)", propc)
-			}
+// Grants a provider's pending request to activate a published deal as late as the requested deadline.
+// The deal is then not timed out until after that deadline. Payment for the deal still accrues from its start epoch.
+// Must be called by the deal's client, with the deadline that was requested.
+func (a Actor) GrantActivationExtension(rt Runtime, params *ActivationExtensionParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerAcceptAny()
+	proposal := loadExtensibleDealProposal(rt, params.DealID)
+	client, ok := rt.ResolveAddress(proposal.Client)
+	if !ok {
+		rt.Abortf(exitcode.ErrNotFound, "failed to resolve client address %v", proposal.Client)
+	}
+	if client != rt.Caller() {
+		rt.Abortf(exitcode.ErrForbidden, "caller %v is not the client %v of deal %d", rt.Caller(), proposal.Client, params.DealID)
+	}
 
-			err = msm.dealStates.Set(dealID, &DealState{
-				SectorStartEpoch: currEpoch,
-				LastUpdatedEpoch: epochUndefined,
-				SlashEpoch:       epochUndefined,
-			})
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set deal state %d", dealID)
+	updateActivationExtension(rt, params.DealID, func(ext *ActivationExtension, _ abi.ChainEpoch) {
+		builtin.RequireParam(rt, ext.Requested != epochUndefined && params.Deadline == ext.Requested,
+			"deadline %d was not requested for deal %d", params.Deadline, params.DealID)
+		ext.Granted = ext.Requested
+		ext.Requested = epochUndefined
+	})
+	return nil
+}
+
+// Loads the proposal of a deal that is published but not yet activated.
+func loadExtensibleDealProposal(rt Runtime, dealID abi.DealID) *DealProposal {
+	var st State
+	rt.StateReadonly(&st)
+	msm, err := st.mutator(adt.AsStore(rt)).withDealProposals(ReadOnlyPermission).
+		withDealStates(ReadOnlyPermission).build()
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+	proposal, found, err := msm.dealProposals.Get(dealID)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal proposal %d", dealID)
+	if !found {
+		rt.Abortf(exitcode.ErrNotFound, "no such deal %d", dealID)
+	}
+	_, activated, err := msm.dealStates.Get(dealID)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal state %d", dealID)
+	if activated {
+		rt.Abortf(exitcode.ErrIllegalArgument, "deal %d is already activated", dealID)
+	}
+	return proposal
+}
+
+// Updates the activation extension of a published deal, which must not have passed its activation deadline.
+// The update is passed the deal's extension, or an empty one, and the current deadline.
+func updateActivationExtension(rt Runtime, dealID abi.DealID, update func(ext *ActivationExtension, deadline abi.ChainEpoch)) {
+	var st State
+	rt.StateTransaction(&st, func() {
+		msm, err := st.mutator(adt.AsStore(rt)).withDealProposals(ReadOnlyPermission).
+			withActivationExtensions(WritePermission).build()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+		proposal, err := getDealProposal(msm.dealProposals, dealID)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal proposal %d", dealID)
+		deadline, err := activationDeadline(msm.activationExtensions, dealID, proposal)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get activation deadline for deal %d", dealID)
+		if rt.CurrEpoch() > deadline {
+			rt.Abortf(exitcode.ErrForbidden, "deal %d activation deadline %d has already elapsed", dealID, deadline)
 		}
 
+		ext := ActivationExtension{Granted: epochUndefined, Requested: epochUndefined}
+		_, err = msm.activationExtensions.Get(abi.UIntKey(uint64(dealID)), &ext)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get activation extension for deal %d", dealID)
+		update(&ext, deadline)
+		err = msm.activationExtensions.Put(abi.UIntKey(uint64(dealID)), &ext)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to put activation extension for deal %d", dealID)
+
 		err = msm.commitState()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
 	})
+}
 
-	return nil
+type ProviderDealsParams struct {
+	Provider addr.Address
+	Page     builtin.PageParams
 }
 
-type SectorDataSpec struct {
-	DealIDs    []abi.DealID
-	SectorType abi.RegisteredSealProof
+type ProviderDealsReturn struct {
+	Deals []abi.DealID // Ascending
+	Page  builtin.PageReturn
 }
 
-type ComputeDataCommitmentParams struct {
-	Inputs []*SectorDataSpec
+// Returns the IDs of a provider's published deals, including those not yet activated.
+// Deals are removed once they expire, are slashed, or time out before activation.
+func (a Actor) ProviderDeals(rt Runtime, params *ProviderDealsParams) *ProviderDealsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+
+	provider, ok := rt.ResolveAddress(params.Provider)
+	if !ok {
+		rt.Abortf(exitcode.ErrNotFound, "failed to resolve provider address %v", params.Provider)
+	}
+
+	var st State
+	rt.StateReadonly(&st)
+	dealIDs, err := st.ProviderDealIDs(adt.AsStore(rt), provider)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deals of provider %v", provider)
+
+	start, end, page, err := builtin.Paginate(uint64(len(dealIDs)), &params.Page)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid page")
+	return &ProviderDealsReturn{Deals: dealIDs[start:end], Page: page}
 }
 
-type ComputeDataCommitmentReturn struct {
-	CommDs []cbg.CborCid
+type PieceDealsParams struct {
+	PieceCID cid.Cid `checked:"true"`
+	Page     builtin.PageParams
 }
 
-func (a Actor) ComputeDataCommitment(rt Runtime, params *ComputeDataCommitmentParams) *ComputeDataCommitmentReturn {
-	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
+type PieceDealsReturn struct {
+	Deals []abi.DealID // Ascending
+	Page  builtin.PageReturn
+}
+
+// Returns the IDs of the published deals for a piece, including those not yet activated, so that retrieval
+// and indexing services can find the providers storing a piece.
+// Deals are removed once they expire, are slashed, or time out before activation.
+func (a Actor) PieceDeals(rt Runtime, params *PieceDealsParams) *PieceDealsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+
+	if !params.PieceCID.Defined() || params.PieceCID.Prefix() != PieceCIDPrefix {
+		rt.Abortf(exitcode.ErrIllegalArgument, "invalid piece CID %v", params.PieceCID)
+	}
 
 	var st State
 	rt.StateReadonly(&st)
-	proposals, err := AsDealProposalArray(adt.AsStore(rt), st.Proposals)
-	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal dealProposals")
-	commDs := make([]cbg.CborCid, len(params.Inputs))
-	for i, commInput := range params.Inputs {
-		pieces := make([]abi.PieceInfo, 0)
-		for _, dealID := range commInput.DealIDs {
-			deal, err := getDealProposal(proposals, dealID)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get dealId %d", dealID)
+	dealIDs, err := st.PieceDealIDs(adt.AsStore(rt), params.PieceCID)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deals of piece %v", params.PieceCID)
 
-			pieces = append(pieces, abi.PieceInfo{
-				PieceCID: deal.PieceCID,
-				Size:     deal.PieceSize,
-			})
-		}
-		commD, err := rt.ComputeUnsealedSectorCID(commInput.SectorType, pieces)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to compute unsealed sectorCID: %s", err)
-		commDs[i] = (cbg.CborCid)(commD)
-	}
-	return &ComputeDataCommitmentReturn{
-		CommDs: commDs,
-	}
+	start, end, page, err := builtin.Paginate(uint64(len(dealIDs)), &params.Page)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid page")
+	return &PieceDealsReturn{Deals: dealIDs[start:end], Page: page}
 }
 
 //type OnMinerSectorsTerminateParams struct {
@@ -444,8 +1365,10 @@ func (a Actor) ComputeDataCommitment(rt Runtime, params *ComputeDataCommitmentPa
 type OnMinerSectorsTerminateParams = market0.OnMinerSectorsTerminateParams
 
 // Terminate a set of deals in response to their containing sector being terminated.
-// Slash provider collateral, refund client collateral, and refund partial unpaid escrow
-// amount to client.
+// Slash provider collateral, unless terminated within the deal's slashing grace period, refund client collateral,
+// and refund partial unpaid escrow amount to client.
+// At most DealTerminationsMax deals are terminated immediately. The remainder are validated and queued,
+// to be terminated by ContinueTermination.
 func (a Actor) OnMinerSectorsTerminate(rt Runtime, params *OnMinerSectorsTerminateParams) *abi.EmptyValue {
 	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
 	minerAddr := rt.Caller()
@@ -453,50 +1376,56 @@ func (a Actor) OnMinerSectorsTerminate(rt Runtime, params *OnMinerSectorsTermina
 	var st State
 	rt.StateTransaction(&st, func() {
 		msm, err := st.mutator(adt.AsStore(rt)).withDealStates(WritePermission).
-			withDealProposals(ReadOnlyPermission).build()
+			withDealProposals(ReadOnlyPermission).withPendingTerminations(WritePermission).build()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal state")
 
-		for _, dealID := range params.DealIDs {
-			deal, found, err := msm.dealProposals.Get(dealID)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal proposal %v", dealID)
-			// The deal may have expired and been deleted before the sector is terminated.
-			// Nothing to do, but continue execution for the other deals.
-			if !found {
-				continue
+		for i, dealID := range params.DealIDs {
+			if i < DealTerminationsMax {
+				msm.terminateDeal(rt, minerAddr, dealID, params.Epoch)
+			} else {
+				msm.queueDealTermination(rt, minerAddr, dealID, params.Epoch)
 			}
-			builtin.RequireState(rt, deal.Provider == minerAddr, "caller %v is not the provider %v of deal %v",
-				minerAddr, deal.Provider, dealID)
+		}
 
-			// do not slash expired deals
-			if deal.EndEpoch <= params.Epoch {
-				continue
-			}
+		err = msm.commitState()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
+	})
+	return nil
+}
 
-			state, found, err := msm.dealStates.Get(dealID)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal state %v", dealID)
-			if !found {
-				// A deal with a proposal but no state is not activated, but then it should not be
-				// part of a sector that is terminating.
-				rt.Abortf(exitcode.ErrIllegalArgument, "no state for deal %v", dealID)
-			}
+type ContinueTerminationReturn struct {
+	// The number of deal terminations that remain queued.
+	Remaining uint64
+}
 
-			// if a deal is already slashed, we don't need to do anything here.
-			if state.SlashEpoch != epochUndefined {
-				continue
-			}
+// Terminates up to DealTerminationsMax of the deals queued by OnMinerSectorsTerminate, in order of notification.
+// Anyone may call this method, so that the settlement of a large termination always completes.
+func (a Actor) ContinueTermination(rt Runtime, _ *abi.EmptyValue) *ContinueTerminationReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+
+	var remaining uint64
+	var st State
+	rt.StateTransaction(&st, func() {
+		msm, err := st.mutator(adt.AsStore(rt)).withDealStates(WritePermission).
+			withDealProposals(ReadOnlyPermission).withPendingTerminations(WritePermission).build()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal state")
 
-			// mark the deal for slashing here.
-			// actual releasing of locked funds for the client and slashing of provider collateral happens in CronTick.
-			state.SlashEpoch = params.Epoch
+		for processed := 0; processed < DealTerminationsMax && msm.pendingTerminations.Length() > 0; processed++ {
+			var pending PendingDealTermination
+			index := msm.nextPendingTermination
+			found, err := msm.pendingTerminations.Pop(index, &pending)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to pop pending termination %d", index)
+			builtin.RequireState(rt, found, "pending termination %d not found", index)
+			msm.nextPendingTermination++
 
-			err = msm.dealStates.Set(dealID, state)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set deal state %v", dealID)
+			msm.terminateDeal(rt, pending.Provider, pending.DealID, pending.Epoch)
 		}
+		remaining = msm.pendingTerminations.Length()
 
 		err = msm.commitState()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
 	})
-	return nil
+	return &ContinueTerminationReturn{Remaining: remaining}
 }
 
 func (a Actor) CronTick(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
@@ -504,6 +1433,7 @@ func (a Actor) CronTick(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 	amountSlashed := big.Zero()
 
 	var timedOutVerifiedDeals []*DealProposal
+	var dataCapRefunds []dataCapRefund
 
 	var st State
 	rt.StateTransaction(&st, func() {
@@ -511,11 +1441,38 @@ func (a Actor) CronTick(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 
 		msm, err := st.mutator(adt.AsStore(rt)).withDealStates(WritePermission).
 			withLockedTable(WritePermission).withEscrowTable(WritePermission).withDealsByEpoch(WritePermission).
-			withDealProposals(WritePermission).withPendingProposals(WritePermission).build()
+			withDealProposals(WritePermission).withPendingProposals(WritePermission).
+			withUpfrontPaymentDeals(WritePermission).withDealOffers(WritePermission).
+			withDealsByProvider(WritePermission).withDealsByPiece(WritePermission).
+			withActivationExtensions(WritePermission).build()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
 
 		for i := st.LastCron + 1; i <= rt.CurrEpoch(); i++ {
 			err = msm.dealsByEpoch.ForEach(i, func(dealID abi.DealID) error {
+				offer, offered, err := msm.dealOffers.Get(dealID)
+				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal offer %d", dealID)
+
+				// deal has been offered but not accepted by the provider -> refund the client
+				if offered {
+					builtin.RequireState(rt, rt.CurrEpoch() >= offer.StartEpoch, "deal offer %d processed before start epoch %d",
+						dealID, offer.StartEpoch)
+
+					msm.processDealOfferExpired(rt, offer)
+					if offer.VerifiedDeal {
+						timedOutVerifiedDeals = append(timedOutVerifiedDeals, offer)
+					}
+
+					ocid, err := offer.Cid()
+					builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to calculate CID for offer %v", dealID)
+
+					err = msm.dealOffers.Delete(dealID)
+					builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete deal offer %d", dealID)
+
+					err = msm.removePendingProposal(ocid)
+					builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete pending offer %d (%v)", dealID, ocid)
+					return nil
+				}
+
 				deal, err := getDealProposal(msm.dealProposals, dealID)
 				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get dealId %d", dealID)
 
@@ -531,10 +1488,25 @@ func (a Actor) CronTick(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 					builtin.RequireState(rt, rt.CurrEpoch() >= deal.StartEpoch, "deal %d processed before start epoch %d",
 						dealID, deal.StartEpoch)
 
-					slashed := msm.processDealInitTimedOut(rt, deal)
+					// A deal whose client granted more time to activate it is processed again at its deadline.
+					deadline, err := activationDeadline(msm.activationExtensions, dealID, deal)
+					builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get activation deadline for deal %d", dealID)
+					if rt.CurrEpoch() < deadline {
+						nextEpoch := GenRandNextEpoch(deadline, dealID)
+						updatesNeeded[nextEpoch] = append(updatesNeeded[nextEpoch], dealID)
+						return nil
+					}
+					err = msm.removeActivationExtension(dealID)
+					builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to remove activation extension")
+
+					paymentMode, err := msm.popUpfrontPaymentDeal(dealID)
+					builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check payment mode for deal %d", dealID)
+
+					slashed := msm.processDealInitTimedOut(rt, deal, paymentMode == DealPaymentUpfront)
 					if !slashed.IsZero() {
 						amountSlashed = big.Add(amountSlashed, slashed)
 					}
+					emitDealSlashed(rt, dealID, deal, rt.CurrEpoch(), slashed)
 					if deal.VerifiedDeal {
 						timedOutVerifiedDeals = append(timedOutVerifiedDeals, deal)
 					}
@@ -542,30 +1514,40 @@ func (a Actor) CronTick(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 					// Delete the proposal (but not state, which doesn't exist).
 					err = msm.dealProposals.Delete(dealID)
 					builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete deal proposal %d", dealID)
+					err = msm.unindexDeal(dealID, deal)
+					builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to remove deal %d from indexes", dealID)
 
-					err = msm.pendingDeals.Delete(abi.CidKey(dcid))
+					err = msm.removePendingProposal(dcid)
 					builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete pending proposal %d (%v)", dealID, dcid)
 					return nil
 				}
 
 				// if this is the first cron tick for the deal, it should be in the pending state.
 				if state.LastUpdatedEpoch == epochUndefined {
-					pdErr := msm.pendingDeals.Delete(abi.CidKey(dcid))
+					pdErr := msm.removePendingProposal(dcid)
 					builtin.RequireNoErr(rt, pdErr, exitcode.ErrIllegalState, "failed to delete pending proposal %v", dcid)
 				}
 
-				slashAmount, nextEpoch, removeDeal := msm.updatePendingDealState(rt, state, deal, rt.CurrEpoch())
+				slashAmount, nextEpoch, removeDeal := msm.updatePendingDealState(rt, dealID, state, deal, rt.CurrEpoch())
 				builtin.RequireState(rt, slashAmount.GreaterThanEqual(big.Zero()), "computed negative slash amount %v for deal %d", slashAmount, dealID)
 
 				if removeDeal {
 					builtin.RequireState(rt, nextEpoch == epochUndefined, "removed deal %d should have no scheduled epoch (got %d)", dealID, nextEpoch)
 					amountSlashed = big.Add(amountSlashed, slashAmount)
 
+					if state.SlashEpoch != epochUndefined {
+						if refund := VerifiedDealDataCapRefund(deal, state.SlashEpoch); refund.GreaterThan(big.Zero()) {
+							dataCapRefunds = append(dataCapRefunds, dataCapRefund{deal: deal, amount: refund})
+						}
+					}
+
 					// Delete proposal and state simultaneously.
 					err = msm.dealStates.Delete(dealID)
 					builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete deal state %d", dealID)
 					err = msm.dealProposals.Delete(dealID)
 					builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete deal proposal %d", dealID)
+					err = msm.unindexDeal(dealID, deal)
+					builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to remove deal %d from indexes", dealID)
 				} else {
 					builtin.RequireState(rt, nextEpoch > rt.CurrEpoch(), "continuing deal %d next epoch %d should be in future", dealID, nextEpoch)
 					builtin.RequireState(rt, slashAmount.IsZero(), "continuing deal %d should not be slashed", dealID)
@@ -584,6 +1566,12 @@ func (a Actor) CronTick(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 
 			err = msm.dealsByEpoch.RemoveAll(i)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete deal ops for epoch %v", i)
+
+			expired, err := msm.expirePendingProposals(i)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to expire pending proposals")
+			if expired > 0 {
+				rt.Log(rtt.INFO, "removed %d pending proposals left behind at epoch %d", expired, i)
+			}
 		}
 
 		// Iterate changes in sorted order to ensure that loads/stores
@@ -607,7 +1595,18 @@ func (a Actor) CronTick(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
 	})
 
-	for _, d := range timedOutVerifiedDeals {
+	restoreVerifiedDealBytes(rt, timedOutVerifiedDeals, "timed-out")
+	refundVerifiedDealBytes(rt, dataCapRefunds)
+
+	builtin.BurnPenalty(rt, amountSlashed)
+
+	return nil
+}
+
+// Restores the data cap used by verified deals that were never activated to their clients.
+// A failure to restore is logged rather than aborting, so it cannot block the deals' removal.
+func restoreVerifiedDealBytes(rt Runtime, deals []*DealProposal, reason string) {
+	for _, d := range deals {
 		code := rt.Send(
 			builtin.VerifiedRegistryActorAddr,
 			builtin.MethodsVerifiedRegistry.RestoreBytes,
@@ -620,28 +1619,53 @@ func (a Actor) CronTick(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 		)
 
 		if !code.IsSuccess() {
-			rt.Log(rtt.ERROR, "failed to send RestoreBytes call to the VerifReg actor for timed-out verified deal, client: %s, dealSize: %v, "+
-				"provider: %v, got code %v", d.Client, d.PieceSize, d.Provider, code)
+			rt.Log(rtt.ERROR, "failed to send RestoreBytes call to the VerifReg actor for %s verified deal, client: %s, dealSize: %v, "+
+				"provider: %v, got code %v", reason, d.Client, d.PieceSize, d.Provider, code)
 		}
 	}
+}
+
+// A share of the data cap used by a verified deal terminated early, to be refunded to its client.
+type dataCapRefund struct {
+	deal   *DealProposal
+	amount abi.StoragePower
+}
 
-	if !amountSlashed.IsZero() {
-		e := rt.Send(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, amountSlashed, &builtin.Discard{})
-		builtin.RequireSuccess(rt, e, "expected send to burnt funds actor to succeed")
+// Refunds to their clients shares of the data cap used by verified deals terminated early in their terms.
+// A failure to refund is logged rather than aborting, so it cannot block the deals' removal.
+func refundVerifiedDealBytes(rt Runtime, refunds []dataCapRefund) {
+	for _, r := range refunds {
+		code := rt.Send(
+			builtin.VerifiedRegistryActorAddr,
+			builtin.MethodsVerifiedRegistry.RefundBytes,
+			&verifreg.RefundBytesParams{
+				Address: r.deal.Client,
+				Amount:  r.amount,
+			},
+			abi.NewTokenAmount(0),
+			&builtin.Discard{},
+		)
+
+		if !code.IsSuccess() {
+			rt.Log(rtt.ERROR, "failed to send RefundBytes call to the VerifReg actor for terminated verified deal, client: %s, "+
+				"amount: %v, provider: %v, got code %v", r.deal.Client, r.amount, r.deal.Provider, code)
+		}
 	}
+}
 
-	return nil
+// Returns the first epoch at or after an epoch at which cron processes a deal.
+// Each deal is processed once per DealUpdatesInterval, at an offset within the interval derived from a hash
+// of its ID, so that deals published together or starting at the same epoch are spread evenly across the interval.
+func GenRandNextEpoch(epoch abi.ChainEpoch, dealID abi.DealID) abi.ChainEpoch {
+	return dealUpdateQuantSpec(dealID).QuantizeUp(epoch)
 }
 
-func GenRandNextEpoch(startEpoch abi.ChainEpoch, dealID abi.DealID) abi.ChainEpoch {
-	offset := abi.ChainEpoch(uint64(dealID) % uint64(DealUpdatesInterval))
-	q := builtin.NewQuantSpec(DealUpdatesInterval, 0)
-	prevDay := q.QuantizeDown(startEpoch)
-	if prevDay+offset >= startEpoch {
-		return prevDay + offset
-	}
-	nextDay := q.QuantizeUp(startEpoch)
-	return nextDay + offset
+func dealUpdateQuantSpec(dealID abi.DealID) builtin.QuantSpec {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(dealID))
+	digest := sha256.Sum256(buf[:])
+	offset := binary.BigEndian.Uint64(digest[:8]) % uint64(DealUpdatesInterval)
+	return builtin.NewQuantSpec(DealUpdatesInterval, abi.ChainEpoch(offset))
 }
 
 //
@@ -657,15 +1681,19 @@ func ValidateDealsForActivation(
 	if err != nil {
 		return big.Int{}, big.Int{}, 0, xerrors.Errorf("failed to load dealProposals: %w", err)
 	}
+	extensions, err := adt.AsMap(store, st.ActivationExtensions, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return big.Int{}, big.Int{}, 0, xerrors.Errorf("failed to load activation extensions: %w", err)
+	}
 
-	return validateAndComputeDealWeight(proposals, dealIDs, minerAddr, sectorExpiry, currEpoch)
+	return validateAndComputeDealWeight(proposals, extensions, dealIDs, minerAddr, sectorExpiry, currEpoch)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 // Checks
 ////////////////////////////////////////////////////////////////////////////////
 
-func validateAndComputeDealWeight(proposals *DealArray, dealIDs []abi.DealID, minerAddr addr.Address,
+func validateAndComputeDealWeight(proposals *DealArray, extensions *adt.Map, dealIDs []abi.DealID, minerAddr addr.Address,
 	sectorExpiry abi.ChainEpoch, sectorActivation abi.ChainEpoch) (big.Int, big.Int, uint64, error) {
 
 	seenDealIDs := make(map[abi.DealID]struct{}, len(dealIDs))
@@ -686,7 +1714,11 @@ func validateAndComputeDealWeight(proposals *DealArray, dealIDs []abi.DealID, mi
 		if !found {
 			return big.Int{}, big.Int{}, 0, exitcode.ErrNotFound.Wrapf("no such deal %d", dealID)
 		}
-		if err = validateDealCanActivate(proposal, minerAddr, sectorExpiry, sectorActivation); err != nil {
+		deadline, err := activationDeadline(extensions, dealID, proposal)
+		if err != nil {
+			return big.Int{}, big.Int{}, 0, err
+		}
+		if err = validateDealCanActivate(proposal, deadline, minerAddr, sectorExpiry, sectorActivation); err != nil {
 			return big.Int{}, big.Int{}, 0, xerrors.Errorf("cannot activate deal %d: %w", dealID, err)
 		}
 
@@ -702,70 +1734,99 @@ func validateAndComputeDealWeight(proposals *DealArray, dealIDs []abi.DealID, mi
 	return totalDealSpaceTime, totalVerifiedSpaceTime, totalDealSpace, nil
 }
 
-func validateDealCanActivate(proposal *DealProposal, minerAddr addr.Address, sectorExpiration, sectorActivation abi.ChainEpoch) error {
+// A deal may be activated up to its start epoch, or a later deadline granted by its client.
+func validateDealCanActivate(proposal *DealProposal, deadline abi.ChainEpoch, minerAddr addr.Address, sectorExpiration, sectorActivation abi.ChainEpoch) error {
 	if proposal.Provider != minerAddr {
 		return exitcode.ErrForbidden.Wrapf("proposal has provider %v, must be %v", proposal.Provider, minerAddr)
 	}
-	if sectorActivation > proposal.StartEpoch {
-		return exitcode.ErrIllegalArgument.Wrapf("proposal start epoch %d has already elapsed at %d", proposal.StartEpoch, sectorActivation)
+	if sectorActivation > deadline {
+		return exitcode.ErrIllegalArgument.Wrapf("proposal activation deadline %d has already elapsed at %d", deadline, sectorActivation)
 	}
-	if proposal.EndEpoch > sectorExpiration {
-		return exitcode.ErrIllegalArgument.Wrapf("proposal expiration %d exceeds sector expiration %d", proposal.EndEpoch, sectorExpiration)
+	// The sector must hold a renewable deal until the end of its renewal, which either party may decline later.
+	if proposal.MaxEndEpoch() > sectorExpiration {
+		return exitcode.ErrIllegalArgument.Wrapf("proposal expiration %d exceeds sector expiration %d", proposal.MaxEndEpoch(), sectorExpiration)
 	}
 	return nil
 }
 
-func validateDeal(rt Runtime, deal ClientDealProposal, networkRawPower, networkQAPower, baselinePower abi.StoragePower) {
-	if err := dealProposalIsInternallyValid(rt, deal); err != nil {
-		rt.Abortf(exitcode.ErrIllegalArgument, "Invalid deal proposal: %s", err)
+// The client signature is verified unless the client has authorized the proposal on chain.
+func validateDeal(rt Runtime, deal ClientDealProposal, authorized bool, networkRawPower, networkQAPower, baselinePower abi.StoragePower) error {
+	if !authorized {
+		if err := dealProposalIsInternallyValid(rt, deal); err != nil {
+			return exitcode.ErrIllegalArgument.Wrapf("Invalid deal proposal: %s", err)
+		}
 	}
 
-	proposal := deal.Proposal
+	return validateDealProposal(rt, deal.Proposal, networkRawPower, networkQAPower, baselinePower)
+}
+
+func validateDealProposal(rt Runtime, proposal DealProposal, networkRawPower, networkQAPower, baselinePower abi.StoragePower) error {
 
-	if len(proposal.Label) > DealMaxLabelSize {
-		rt.Abortf(exitcode.ErrIllegalArgument, "deal label can be at most %d bytes, is %d", DealMaxLabelSize, len(proposal.Label))
+	if proposal.Label.Length() > DealMaxLabelSize {
+		return exitcode.ErrIllegalArgument.Wrapf("deal label can be at most %d bytes, is %d", DealMaxLabelSize, proposal.Label.Length())
 	}
 
 	if err := proposal.PieceSize.Validate(); err != nil {
-		rt.Abortf(exitcode.ErrIllegalArgument, "proposal piece size is invalid: %v", err)
+		return exitcode.ErrIllegalArgument.Wrapf("proposal piece size is invalid: %v", err)
 	}
 
 	if !proposal.PieceCID.Defined() {
-		rt.Abortf(exitcode.ErrIllegalArgument, "proposal PieceCID undefined")
+		return exitcode.ErrIllegalArgument.Wrapf("proposal PieceCID undefined")
 	}
 
 	if proposal.PieceCID.Prefix() != PieceCIDPrefix {
-		rt.Abortf(exitcode.ErrIllegalArgument, "proposal PieceCID had wrong prefix")
+		return exitcode.ErrIllegalArgument.Wrapf("proposal PieceCID had wrong prefix")
 	}
 
 	if proposal.EndEpoch <= proposal.StartEpoch {
-		rt.Abortf(exitcode.ErrIllegalArgument, "proposal end before proposal start")
+		return exitcode.ErrIllegalArgument.Wrapf("proposal end before proposal start")
 	}
 
 	if rt.CurrEpoch() > proposal.StartEpoch {
-		rt.Abortf(exitcode.ErrIllegalArgument, "Deal start epoch has already elapsed.")
+		return exitcode.ErrIllegalArgument.Wrapf("Deal start epoch has already elapsed.")
 	}
 
 	minDuration, maxDuration := DealDurationBounds(proposal.PieceSize)
 	if proposal.Duration() < minDuration || proposal.Duration() > maxDuration {
-		rt.Abortf(exitcode.ErrIllegalArgument, "Deal duration out of bounds.")
+		return exitcode.ErrIllegalArgument.Wrapf("Deal duration out of bounds.")
 	}
 
 	minPrice, maxPrice := DealPricePerEpochBounds(proposal.PieceSize, proposal.Duration())
 	if proposal.StoragePricePerEpoch.LessThan(minPrice) || proposal.StoragePricePerEpoch.GreaterThan(maxPrice) {
-		rt.Abortf(exitcode.ErrIllegalArgument, "Storage price out of bounds.")
+		return exitcode.ErrIllegalArgument.Wrapf("Storage price out of bounds.")
 	}
 
 	minProviderCollateral, maxProviderCollateral := DealProviderCollateralBounds(proposal.PieceSize, proposal.VerifiedDeal,
 		networkRawPower, networkQAPower, baselinePower, rt.TotalFilCircSupply())
 	if proposal.ProviderCollateral.LessThan(minProviderCollateral) || proposal.ProviderCollateral.GreaterThan(maxProviderCollateral) {
-		rt.Abortf(exitcode.ErrIllegalArgument, "Provider collateral out of bounds.")
+		return exitcode.ErrIllegalArgument.Wrapf("Provider collateral out of bounds.")
 	}
 
 	minClientCollateral, maxClientCollateral := DealClientCollateralBounds(proposal.PieceSize, proposal.Duration())
 	if proposal.ClientCollateral.LessThan(minClientCollateral) || proposal.ClientCollateral.GreaterThan(maxClientCollateral) {
-		rt.Abortf(exitcode.ErrIllegalArgument, "Client collateral out of bounds.")
+		return exitcode.ErrIllegalArgument.Wrapf("Client collateral out of bounds.")
+	}
+
+	if renewal := proposal.Renewal; renewal != nil {
+		if renewal.Duration <= 0 {
+			return exitcode.ErrIllegalArgument.Wrapf("Renewal duration %d not positive.", renewal.Duration)
+		}
+		if proposal.Duration()+renewal.Duration > maxDuration {
+			return exitcode.ErrIllegalArgument.Wrapf("Deal duration including renewal out of bounds.")
+		}
+		minPrice, maxPrice := DealPricePerEpochBounds(proposal.PieceSize, renewal.Duration)
+		if renewal.StoragePricePerEpoch.LessThan(minPrice) || renewal.StoragePricePerEpoch.GreaterThan(maxPrice) {
+			return exitcode.ErrIllegalArgument.Wrapf("Renewal storage price out of bounds.")
+		}
+		if big.Mul(proposal.StoragePricePerEpoch, renewal.StoragePricePerEpoch).LessThan(big.Zero()) {
+			return exitcode.ErrIllegalArgument.Wrapf("Renewal storage price paid by a different party than the storage price.")
+		}
+	}
+
+	if proposal.SlashingGracePeriod < 0 || proposal.SlashingGracePeriod > proposal.Duration() {
+		return exitcode.ErrIllegalArgument.Wrapf("Slashing grace period %d out of bounds.", proposal.SlashingGracePeriod)
 	}
+	return nil
 }
 
 //
@@ -795,6 +1856,91 @@ func escrowAddress(rt Runtime, address addr.Address) (nominal addr.Address, reci
 	return nominal, nominal, []addr.Address{nominal}
 }
 
+// Adds an amount to the escrow balance of a client or provider.
+func addEscrowBalance(rt Runtime, providerOrClientAddress addr.Address, amount abi.TokenAmount) {
+	nominal, _, _ := escrowAddress(rt, providerOrClientAddress)
+
+	var st State
+	rt.StateTransaction(&st, func() {
+		msm, err := st.mutator(adt.AsStore(rt)).withEscrowTable(WritePermission).
+			withLockedTable(WritePermission).build()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+		err = msm.escrowTable.Add(nominal, amount)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to add balance to escrow table")
+
+		err = msm.commitState()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
+	})
+}
+
+// Subtracts up to the specified amount from an escrow balance, leaving at least the balance locked for deals.
+// Returns the amount subtracted.
+func withdrawEscrow(rt Runtime, nominal addr.Address, amount abi.TokenAmount) abi.TokenAmount {
+	amountExtracted := abi.NewTokenAmount(0)
+	var st State
+	rt.StateTransaction(&st, func() {
+		msm, err := st.mutator(adt.AsStore(rt)).withEscrowTable(WritePermission).
+			withLockedTable(WritePermission).build()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+		// The withdrawable amount might be slightly less than nominal
+		// depending on whether or not all relevant entries have been processed
+		// by cron
+		minBalance, err := msm.lockedTable.Get(nominal)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get locked balance")
+
+		ex, err := msm.escrowTable.SubtractWithMinimum(nominal, amount, minBalance)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to subtract from escrow table")
+
+		err = msm.commitState()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
+
+		amountExtracted = ex
+	})
+	return amountExtracted
+}
+
+// Aborts unless the caller is the worker or a control address of a storage provider.
+func validateCallerIsProviderControl(rt Runtime, provider addr.Address) {
+	caller := rt.Caller()
+	_, worker, controllers := builtin.RequestMinerControlAddrs(rt, provider)
+	callerOk := caller == worker
+	for _, controller := range controllers {
+		if callerOk {
+			break
+		}
+		callerOk = caller == controller
+	}
+	if !callerOk {
+		rt.Abortf(exitcode.ErrForbidden, "caller %v is not worker or control address of provider %v", caller, provider)
+	}
+}
+
+// Aborts unless the caller is, for each of a set of distinct published deals, either the deal's client or
+// the worker or a control address of its provider. The control addresses of each provider are requested once.
+func validateCallerIsDealParty(rt Runtime, st *State, dealIDs []abi.DealID) {
+	proposals, err := AsDealProposalArray(adt.AsStore(rt), st.Proposals)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal proposals")
+
+	seen := make(map[abi.DealID]bool, len(dealIDs))
+	providersChecked := make(map[addr.Address]bool)
+	for _, dealID := range dealIDs {
+		if seen[dealID] {
+			rt.Abortf(exitcode.ErrIllegalArgument, "duplicate deal %d", dealID)
+		}
+		seen[dealID] = true
+
+		deal, err := getDealProposal(proposals, dealID)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal %d", dealID)
+		if rt.Caller() == deal.Client || providersChecked[deal.Provider] {
+			continue
+		}
+		validateCallerIsProviderControl(rt, deal.Provider)
+		providersChecked[deal.Provider] = true
+	}
+}
+
 func getDealProposal(proposals *DealArray, dealID abi.DealID) (*DealProposal, error) {
 	proposal, found, err := proposals.Get(dealID)
 	if err != nil {
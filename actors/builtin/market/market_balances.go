@@ -8,17 +8,62 @@ import (
 	"golang.org/x/xerrors"
 )
 
-func (m *marketStateMutation) lockClientAndProviderBalances(proposal *DealProposal) error {
+func (m *marketStateMutation) lockClientAndProviderBalances(proposal *DealProposal, paymentMode DealPaymentMode) error {
 	if err := m.maybeLockBalance(proposal.Client, proposal.ClientBalanceRequirement()); err != nil {
 		return xerrors.Errorf("failed to lock client funds: %w", err)
 	}
-	if err := m.maybeLockBalance(proposal.Provider, proposal.ProviderCollateral); err != nil {
+	if err := m.maybeLockBalance(proposal.Provider, proposal.ProviderBalanceRequirement()); err != nil {
 		return xerrors.Errorf("failed to lock provider funds: %w", err)
 	}
+	if err := m.lockedCollateralTable.Add(proposal.Client, proposal.ClientCollateral); err != nil {
+		return xerrors.Errorf("failed to add client locked collateral: %w", err)
+	}
+	if err := m.lockedCollateralTable.Add(proposal.Provider, proposal.ProviderCollateral); err != nil {
+		return xerrors.Errorf("failed to add provider locked collateral: %w", err)
+	}
 
 	m.totalClientLockedCollateral = big.Add(m.totalClientLockedCollateral, proposal.ClientCollateral)
-	m.totalClientStorageFee = big.Add(m.totalClientStorageFee, proposal.TotalStorageFee())
+	if proposal.ProviderPaysStorageFee() {
+		m.totalProviderStorageFee = big.Sub(m.totalProviderStorageFee, proposal.TotalStorageFee())
+	} else if paymentMode == DealPaymentUpfront {
+		m.totalClientUpfrontPayments = big.Add(m.totalClientUpfrontPayments, proposal.TotalStorageFee())
+	} else {
+		m.totalClientStorageFee = big.Add(m.totalClientStorageFee, proposal.TotalStorageFee())
+	}
+	m.totalProviderLockedCollateral = big.Add(m.totalProviderLockedCollateral, proposal.ProviderCollateral)
+	return nil
+}
+
+// Locks the client's funds for a deal offered by the client, to be paid per epoch.
+// A storage fee paid by the provider is locked only when the provider accepts the offer.
+func (m *marketStateMutation) lockClientBalance(proposal *DealProposal) error {
+	if err := m.maybeLockBalance(proposal.Client, proposal.ClientBalanceRequirement()); err != nil {
+		return xerrors.Errorf("failed to lock client funds: %w", err)
+	}
+	if err := m.lockedCollateralTable.Add(proposal.Client, proposal.ClientCollateral); err != nil {
+		return xerrors.Errorf("failed to add client locked collateral: %w", err)
+	}
+
+	m.totalClientLockedCollateral = big.Add(m.totalClientLockedCollateral, proposal.ClientCollateral)
+	if !proposal.ProviderPaysStorageFee() {
+		m.totalClientStorageFee = big.Add(m.totalClientStorageFee, proposal.TotalStorageFee())
+	}
+	return nil
+}
+
+// Locks the provider's collateral, and the storage fee if paid by the provider, for a deal offer accepted by the provider.
+func (m *marketStateMutation) lockProviderBalance(proposal *DealProposal) error {
+	if err := m.maybeLockBalance(proposal.Provider, proposal.ProviderBalanceRequirement()); err != nil {
+		return xerrors.Errorf("failed to lock provider funds: %w", err)
+	}
+	if err := m.lockedCollateralTable.Add(proposal.Provider, proposal.ProviderCollateral); err != nil {
+		return xerrors.Errorf("failed to add provider locked collateral: %w", err)
+	}
+
 	m.totalProviderLockedCollateral = big.Add(m.totalProviderLockedCollateral, proposal.ProviderCollateral)
+	if proposal.ProviderPaysStorageFee() {
+		m.totalProviderStorageFee = big.Sub(m.totalProviderStorageFee, proposal.TotalStorageFee())
+	}
 	return nil
 }
 
@@ -31,6 +76,11 @@ func (m *marketStateMutation) unlockBalance(addr addr.Address, amount abi.TokenA
 	if err != nil {
 		return xerrors.Errorf("subtracting from locked balance: %w", err)
 	}
+	if lockReason == ClientCollateral || lockReason == ProviderCollateral {
+		if err := m.lockedCollateralTable.MustSubtract(addr, amount); err != nil {
+			return xerrors.Errorf("subtracting from locked collateral: %w", err)
+		}
+	}
 
 	switch lockReason {
 	case ClientCollateral:
@@ -39,20 +89,49 @@ func (m *marketStateMutation) unlockBalance(addr addr.Address, amount abi.TokenA
 		m.totalClientStorageFee = big.Sub(m.totalClientStorageFee, amount)
 	case ProviderCollateral:
 		m.totalProviderLockedCollateral = big.Sub(m.totalProviderLockedCollateral, amount)
+	case ClientUpfrontPayment:
+		m.totalClientUpfrontPayments = big.Sub(m.totalClientUpfrontPayments, amount)
+	case ProviderStorageFee:
+		m.totalProviderStorageFee = big.Sub(m.totalProviderStorageFee, amount)
 	}
 
 	return nil
 }
 
-// move funds from locked in client to available in provider
-func (m *marketStateMutation) transferBalance(fromAddr addr.Address, toAddr addr.Address, amount abi.TokenAmount) error {
+// Unlocks an amount of a deal's storage fee for the party paying it.
+// The amount is negative if the provider pays the client.
+func (m *marketStateMutation) unlockStorageFee(deal *DealProposal, amount abi.TokenAmount, upfront bool) error {
+	if amount.LessThan(big.Zero()) {
+		return m.unlockBalance(deal.Provider, amount.Neg(), ProviderStorageFee)
+	}
+	return m.unlockBalance(deal.Client, amount, clientStorageFeeLockReason(upfront))
+}
+
+// Pays an amount of a deal's locked storage fee from the party paying it to the other party.
+// The amount is negative if the provider pays the client.
+func (m *marketStateMutation) transferStorageFee(deal *DealProposal, amount abi.TokenAmount, upfront bool) error {
+	if amount.LessThan(big.Zero()) {
+		return m.transferBalance(deal.Provider, deal.Client, amount.Neg(), ProviderStorageFee)
+	}
+	return m.transferBalance(deal.Client, deal.Provider, amount, clientStorageFeeLockReason(upfront))
+}
+
+func clientStorageFeeLockReason(upfront bool) BalanceLockingReason {
+	if upfront {
+		return ClientUpfrontPayment
+	}
+	return ClientStorageFee
+}
+
+// move funds from locked in one party to available in the other
+func (m *marketStateMutation) transferBalance(fromAddr addr.Address, toAddr addr.Address, amount abi.TokenAmount, lockReason BalanceLockingReason) error {
 	if amount.LessThan(big.Zero()) {
 		return xerrors.Errorf("transfer negative amount %v", amount)
 	}
 	if err := m.escrowTable.MustSubtract(fromAddr, amount); err != nil {
 		return xerrors.Errorf("subtract from escrow: %w", err)
 	}
-	if err := m.unlockBalance(fromAddr, amount, ClientStorageFee); err != nil {
+	if err := m.unlockBalance(fromAddr, amount, lockReason); err != nil {
 		return xerrors.Errorf("subtract from locked: %w", err)
 	}
 	if err := m.escrowTable.Add(toAddr, amount); err != nil {
@@ -2,11 +2,16 @@ package market
 
 import (
 	"bytes"
+	"encoding/binary"
+	"sort"
 
+	addr "github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/exitcode"
 	"github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+	cbg "github.com/whyrusleeping/cbor-gen"
 	xerrors "golang.org/x/xerrors"
 
 	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
@@ -22,11 +27,27 @@ const (
 	ClientCollateral BalanceLockingReason = iota
 	ClientStorageFee
 	ProviderCollateral
+	ClientUpfrontPayment
+	ProviderStorageFee
+)
+
+// DealPaymentMode determines how a client's storage fee for a deal is paid to the provider.
+type DealPaymentMode uint64
+
+const (
+	// The storage fee is locked at publication and paid to the provider periodically as the deal progresses.
+	DealPaymentPerEpoch DealPaymentMode = iota
+	// The storage fee is locked at publication and paid to the provider in a single sum when the deal completes.
+	// If the deal is terminated early, the provider receives the fee for the epochs elapsed and the client
+	// is refunded the remainder.
+	DealPaymentUpfront
 )
 
 // Bitwidth of AMTs determined empirically from mutation patterns and projections of mainnet data.
 const ProposalsAmtBitwidth = 5
 const StatesAmtBitwidth = 6
+const PendingTerminationsAmtBitwidth = 5
+const PendingProposalExpirationsAmtBitwidth = 5
 
 type State struct {
 	// Proposals are deals that have been proposed and not yet cleaned up after expiry or termination.
@@ -36,9 +57,15 @@ type State struct {
 	// Invariant: keys(States) ⊆ keys(Proposals).
 	States cid.Cid // AMT[DealID]DealState
 
-	// PendingProposals tracks dealProposals that have not yet reached their deal start date.
+	// PendingProposals tracks dealProposals and deal offers that have not yet reached their deal start date.
 	// We track them here to ensure that miners can't publish the same deal proposal twice
 	PendingProposals cid.Cid // Set[DealCid]
+	// PendingProposalExpirations schedules the removal of each proposal from PendingProposals, should cron not
+	// have removed it when processing the deal, PendingProposalExpiry epochs after the deal's maximum end epoch.
+	// Invariant: PendingProposals ⊆ values(PendingProposalExpirations).
+	PendingProposalExpirations cid.Cid // Multimap, HAMT[epoch]AMT[DealCid]
+	// PendingProposalCount is the number of entries in PendingProposals.
+	PendingProposalCount uint64
 
 	// Total amount held in escrow, indexed by actor address (including both locked and unlocked amounts).
 	EscrowTable cid.Cid // BalanceTable
@@ -48,6 +75,11 @@ type State struct {
 	// only the _portion_ of the total escrow amount that is locked.
 	LockedTable cid.Cid // BalanceTable
 
+	// Collateral locked for deals, indexed by actor address.
+	// Each entry is the portion of the address's amount in LockedTable that is collateral, the remainder being
+	// storage fees the address is committed to pay.
+	LockedCollateralTable cid.Cid // BalanceTable
+
 	NextID abi.DealID
 
 	// Metadata cached for efficient iteration over deals.
@@ -60,6 +92,60 @@ type State struct {
 	TotalProviderLockedCollateral abi.TokenAmount
 	// Total storage fee that is locked in escrow -> unlocked when payments are made
 	TotalClientStorageFee abi.TokenAmount
+
+	// UpfrontPaymentDeals tracks the published but not yet activated deals, by ID, whose storage fee is paid
+	// in full on completion rather than per epoch. The payment mode moves to the deal state on activation.
+	// Invariant: UpfrontPaymentDeals ⊆ keys(Proposals) \ keys(States).
+	UpfrontPaymentDeals cid.Cid // Set[DealID]
+	// Total storage fee for upfront payment deals that is locked in escrow -> unlocked when the deal completes or terminates
+	TotalClientUpfrontPayments abi.TokenAmount
+	// Total storage fee paid by providers to clients that is locked in escrow -> unlocked when payments are made
+	TotalProviderStorageFee abi.TokenAmount
+
+	// DealOffers are deal proposals published and funded by a client that are awaiting acceptance by the provider.
+	// An accepted offer moves to Proposals, keeping its deal ID. Offers not accepted by their start epoch
+	// are removed and the client's funds unlocked.
+	// Invariant: keys(DealOffers) ∩ keys(Proposals) = ∅.
+	DealOffers cid.Cid // AMT[DealID]DealProposal
+
+	// DealsByProvider indexes the IDs of deals in Proposals by their provider's (ID) address.
+	// Invariant: the union of the sets is keys(Proposals), each deal being in the set of its provider.
+	DealsByProvider cid.Cid // SetMultimap, HAMT[address]Set[DealID]
+
+	// DealsByPiece indexes the IDs of deals in Proposals by their piece CID.
+	// Invariant: the union of the sets is keys(Proposals), each deal being in the set of its piece.
+	DealsByPiece cid.Cid // SetMultimap, HAMT[PieceCID]Set[DealID]
+
+	// AuthorizedProposals are the CIDs of deal proposals authorized on chain by their clients, in lieu of a
+	// client signature, for clients such as multisigs that cannot sign. An authorization is consumed when its
+	// proposal is published.
+	AuthorizedProposals cid.Cid // Set[ProposalCID]
+
+	// PendingTerminations queues the deal terminations notified by miners beyond those processed immediately,
+	// indexed in order of notification from NextPendingTermination.
+	// Invariant: keys(PendingTerminations) = [NextPendingTermination, NextPendingTermination + len(PendingTerminations)).
+	PendingTerminations    cid.Cid // AMT[uint64]PendingDealTermination
+	NextPendingTermination uint64
+
+	// ActivationExtensions tracks extensions, past their start epochs, of the deadlines by which published deals
+	// must be activated, as requested by their providers and granted by their clients.
+	// Invariant: keys(ActivationExtensions) ⊆ keys(Proposals) \ keys(States).
+	ActivationExtensions cid.Cid // HAMT[DealID]ActivationExtension
+}
+
+// The extension of the deadline by which a published deal must be activated.
+type ActivationExtension struct {
+	// The last epoch at which the deal may be activated, as granted by the client, or -1 if none has been granted.
+	Granted abi.ChainEpoch
+	// The deadline requested by the provider and not yet granted, or -1 if there is no pending request.
+	Requested abi.ChainEpoch
+}
+
+// The termination of a deal whose sector has terminated, awaiting processing.
+type PendingDealTermination struct {
+	Provider addr.Address
+	DealID   abi.DealID
+	Epoch    abi.ChainEpoch
 }
 
 func ConstructState(store adt.Store) (*State, error) {
@@ -84,6 +170,18 @@ func ConstructState(store adt.Store) (*State, error) {
 	if err != nil {
 		return nil, xerrors.Errorf("failed to create empty balance table: %w", err)
 	}
+	emptyUpfrontDealsMapCid, err := adt.StoreEmptyMap(store, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create empty map: %w", err)
+	}
+	emptyPendingTerminationsArrayCid, err := adt.StoreEmptyArray(store, PendingTerminationsAmtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create empty pending terminations array: %w", err)
+	}
+	emptyPendingExpirationsCid, err := adt.StoreEmptyMultimap(store, builtin.DefaultHamtBitwidth, PendingProposalExpirationsAmtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create empty pending proposal expirations: %w", err)
+	}
 
 	return &State{
 		Proposals:        emptyProposalsArrayCid,
@@ -95,18 +193,97 @@ func ConstructState(store adt.Store) (*State, error) {
 		DealOpsByEpoch:   emptyDealOpsHamtCid,
 		LastCron:         abi.ChainEpoch(-1),
 
+		LockedCollateralTable: emptyBalanceTableCid,
+
 		TotalClientLockedCollateral:   abi.NewTokenAmount(0),
 		TotalProviderLockedCollateral: abi.NewTokenAmount(0),
 		TotalClientStorageFee:         abi.NewTokenAmount(0),
+
+		UpfrontPaymentDeals:        emptyUpfrontDealsMapCid,
+		TotalClientUpfrontPayments: abi.NewTokenAmount(0),
+		TotalProviderStorageFee:    abi.NewTokenAmount(0),
+
+		DealOffers:      emptyProposalsArrayCid,
+		DealsByProvider: emptyDealOpsHamtCid,
+		DealsByPiece:    emptyDealOpsHamtCid,
+
+		AuthorizedProposals: emptyPendingProposalsMapCid,
+
+		PendingTerminations:    emptyPendingTerminationsArrayCid,
+		NextPendingTermination: 0,
+
+		ActivationExtensions: emptyPendingProposalsMapCid,
+
+		PendingProposalExpirations: emptyPendingExpirationsCid,
+		PendingProposalCount:       0,
 	}, nil
 }
 
+// Returns the collateral an address has locked for deals, as client or provider, and the storage fees it has locked
+// to pay for its deals' remaining terms.
+// The address must be an ID address.
+func (s *State) LockedFunds(store adt.Store, a addr.Address) (collateral, paymentObligation abi.TokenAmount, err error) {
+	lockedTable, err := adt.AsBalanceTable(store, s.LockedTable)
+	if err != nil {
+		return big.Zero(), big.Zero(), xerrors.Errorf("failed to load locked table: %w", err)
+	}
+	collateralTable, err := adt.AsBalanceTable(store, s.LockedCollateralTable)
+	if err != nil {
+		return big.Zero(), big.Zero(), xerrors.Errorf("failed to load locked collateral table: %w", err)
+	}
+	locked, err := lockedTable.Get(a)
+	if err != nil {
+		return big.Zero(), big.Zero(), xerrors.Errorf("failed to get locked balance for %v: %w", a, err)
+	}
+	collateral, err = collateralTable.Get(a)
+	if err != nil {
+		return big.Zero(), big.Zero(), xerrors.Errorf("failed to get locked collateral for %v: %w", a, err)
+	}
+	return collateral, big.Sub(locked, collateral), nil
+}
+
+// Returns the IDs of the published deals (those in Proposals) with a provider, in ascending order.
+// The provider must be given by its ID address.
+func (s *State) ProviderDealIDs(store adt.Store, provider addr.Address) ([]abi.DealID, error) {
+	dealsByProvider, err := AsSetMultimap(store, s.DealsByProvider, builtin.DefaultHamtBitwidth, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load deals by provider: %w", err)
+	}
+	var dealIDs []abi.DealID
+	if err = dealsByProvider.ForEachKey(abi.AddrKey(provider), func(id abi.DealID) error {
+		dealIDs = append(dealIDs, id)
+		return nil
+	}); err != nil {
+		return nil, xerrors.Errorf("failed to load deals of provider %v: %w", provider, err)
+	}
+	sort.Slice(dealIDs, func(i, j int) bool { return dealIDs[i] < dealIDs[j] })
+	return dealIDs, nil
+}
+
+// Returns the IDs of the published deals (those in Proposals) for a piece, in ascending order.
+func (s *State) PieceDealIDs(store adt.Store, pieceCID cid.Cid) ([]abi.DealID, error) {
+	dealsByPiece, err := AsSetMultimap(store, s.DealsByPiece, builtin.DefaultHamtBitwidth, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load deals by piece: %w", err)
+	}
+	var dealIDs []abi.DealID
+	if err = dealsByPiece.ForEachKey(abi.CidKey(pieceCID), func(id abi.DealID) error {
+		dealIDs = append(dealIDs, id)
+		return nil
+	}); err != nil {
+		return nil, xerrors.Errorf("failed to load deals of piece %v: %w", pieceCID, err)
+	}
+	sort.Slice(dealIDs, func(i, j int) bool { return dealIDs[i] < dealIDs[j] })
+	return dealIDs, nil
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Deal state operations
 ////////////////////////////////////////////////////////////////////////////////
 
-func (m *marketStateMutation) updatePendingDealState(rt Runtime, state *DealState, deal *DealProposal, epoch abi.ChainEpoch) (amountSlashed abi.TokenAmount, nextEpoch abi.ChainEpoch, removeDeal bool) {
+func (m *marketStateMutation) updatePendingDealState(rt Runtime, dealID abi.DealID, state *DealState, deal *DealProposal, epoch abi.ChainEpoch) (amountSlashed abi.TokenAmount, nextEpoch abi.ChainEpoch, removeDeal bool) {
 	amountSlashed = abi.NewTokenAmount(0)
+	upfront := state.PaymentMode == DealPaymentUpfront
 
 	everUpdated := state.LastUpdatedEpoch != epochUndefined
 	everSlashed := state.SlashEpoch != epochUndefined
@@ -119,10 +296,11 @@ func (m *marketStateMutation) updatePendingDealState(rt Runtime, state *DealStat
 		return amountSlashed, epochUndefined, false
 	}
 
-	paymentEndEpoch := deal.EndEpoch
+	endEpoch := dealEndEpoch(deal, state)
+	paymentEndEpoch := endEpoch
 	if everSlashed {
 		builtin.RequireState(rt, epoch >= state.SlashEpoch, "current epoch less than deal slash epoch %d", state.SlashEpoch)
-		builtin.RequireState(rt, state.SlashEpoch <= deal.EndEpoch, "deal slash epoch %d after deal end %d", state.SlashEpoch, deal.EndEpoch)
+		builtin.RequireState(rt, state.SlashEpoch <= endEpoch, "deal slash epoch %d after deal end %d", state.SlashEpoch, endEpoch)
 		paymentEndEpoch = state.SlashEpoch
 	} else if epoch < paymentEndEpoch {
 		paymentEndEpoch = epoch
@@ -133,65 +311,161 @@ func (m *marketStateMutation) updatePendingDealState(rt Runtime, state *DealStat
 		paymentStartEpoch = state.LastUpdatedEpoch
 	}
 
-	numEpochsElapsed := paymentEndEpoch - paymentStartEpoch
-
-	{
+	// Upfront payments are settled only when the deal is removed.
+	if !upfront {
 		// Process deal payment for the elapsed epochs.
-		totalPayment := big.Mul(big.NewInt(int64(numEpochsElapsed)), deal.StoragePricePerEpoch)
+		totalPayment := dealPaymentBetween(deal, paymentStartEpoch, paymentEndEpoch)
 
-		// the transfer amount can be less than or equal to zero if a deal is slashed before or at the deal's start epoch.
-		if totalPayment.GreaterThan(big.Zero()) {
-			err := m.transferBalance(deal.Client, deal.Provider, totalPayment)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to transfer %v from %v to %v",
+		// the transfer amount can be zero if a deal is slashed before or at the deal's start epoch.
+		if !totalPayment.IsZero() {
+			err := m.transferStorageFee(deal, totalPayment, false)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to transfer storage fee %v between %v and %v",
 				totalPayment, deal.Client, deal.Provider)
+			emitDealPayment(rt, dealID, deal, totalPayment, paymentEndEpoch)
 		}
 	}
 
 	if everSlashed {
 		// unlock client collateral and locked storage fee
-		paymentRemaining, err := dealGetPaymentRemaining(deal, state.SlashEpoch)
+		paymentRemaining, err := dealGetPaymentRemaining(deal, state, state.SlashEpoch)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to compute remaining payment")
 
+		if upfront {
+			// pay the provider for the epochs up to the slash epoch, the remainder is refunded to the client
+			paymentEarned := big.Sub(deal.TotalStorageFee(), paymentRemaining)
+			if !paymentEarned.IsZero() {
+				err = m.transferStorageFee(deal, paymentEarned, true)
+				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to transfer storage fee %v between %v and %v",
+					paymentEarned, deal.Client, deal.Provider)
+				emitDealPayment(rt, dealID, deal, paymentEarned, state.SlashEpoch)
+			}
+		}
+
 		// unlock remaining storage fee
-		err = m.unlockBalance(deal.Client, paymentRemaining, ClientStorageFee)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to unlock remaining client storage fee")
+		err = m.unlockStorageFee(deal, paymentRemaining, upfront)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to unlock remaining storage fee")
 
 		// unlock client collateral
 		err = m.unlockBalance(deal.Client, deal.ClientCollateral, ClientCollateral)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to unlock client collateral")
 
-		// slash provider collateral
+		// slash provider collateral, or unlock it if the deal terminated within its grace period
+		if deal.InSlashingGracePeriod(state.SlashEpoch) {
+			err = m.unlockBalance(deal.Provider, deal.ProviderCollateral, ProviderCollateral)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to unlock provider collateral")
+			emitDealSlashed(rt, dealID, deal, state.SlashEpoch, amountSlashed)
+			return amountSlashed, epochUndefined, true
+		}
 		amountSlashed = deal.ProviderCollateral
 		err = m.slashBalance(deal.Provider, amountSlashed, ProviderCollateral)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "slashing balance")
+		emitDealSlashed(rt, dealID, deal, state.SlashEpoch, amountSlashed)
 		return amountSlashed, epochUndefined, true
 	}
 
-	if epoch >= deal.EndEpoch {
+	if epoch >= endEpoch {
+		if upfront {
+			// release the full upfront payment to the provider on completion
+			totalPayment := deal.TotalStorageFee()
+			if !totalPayment.IsZero() {
+				err := m.transferStorageFee(deal, totalPayment, true)
+				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to transfer storage fee %v between %v and %v",
+					totalPayment, deal.Client, deal.Provider)
+				emitDealPayment(rt, dealID, deal, totalPayment, endEpoch)
+			}
+		}
 		m.processDealExpired(rt, deal, state)
+		emitDealEvent(rt, EventDealExpired, dealID, deal)
 		return amountSlashed, epochUndefined, true
 	}
 
 	// We're explicitly not inspecting the end epoch and may process a deal's expiration late, in order to prevent an outsider
 	// from loading a cron tick by activating too many deals with the same end epoch.
-	nextEpoch = epoch + DealUpdatesInterval
+	// The deal remains in its update bucket even if this tick was delayed, rather than joining the deals of later epochs.
+	nextEpoch = GenRandNextEpoch(epoch+1, dealID)
 
 	return amountSlashed, nextEpoch, false
 }
 
+// Loads the state of a provider's deal whose sector terminated at an epoch, checking that the deal is activated.
+// Returns false if there is nothing to terminate, the deal having been removed, ended or already slashed.
+func (m *marketStateMutation) loadTerminatingDeal(rt Runtime, provider addr.Address, dealID abi.DealID, epoch abi.ChainEpoch) (*DealState, bool) {
+	deal, found, err := m.dealProposals.Get(dealID)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal proposal %v", dealID)
+	// The deal may have expired and been deleted before the sector is terminated.
+	// Nothing to do, but continue execution for the other deals.
+	if !found {
+		return nil, false
+	}
+	builtin.RequireState(rt, deal.Provider == provider, "caller %v is not the provider %v of deal %v",
+		provider, deal.Provider, dealID)
+
+	// do not slash expired deals
+	if deal.MaxEndEpoch() <= epoch {
+		return nil, false
+	}
+
+	state, found, err := m.dealStates.Get(dealID)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal state %v", dealID)
+	if !found {
+		// A deal with a proposal but no state is not activated, but then it should not be
+		// part of a sector that is terminating.
+		rt.Abortf(exitcode.ErrIllegalArgument, "no state for deal %v", dealID)
+	}
+
+	// nor a deal whose renewal was declined, once its end epoch has passed
+	if dealEndEpoch(deal, state) <= epoch {
+		return nil, false
+	}
+
+	// if a deal is already slashed, we don't need to do anything here.
+	if state.SlashEpoch != epochUndefined {
+		return nil, false
+	}
+	return state, true
+}
+
+// Marks a deal for slashing at the epoch its sector terminated.
+// The actual releasing of locked funds for the client and slashing of provider collateral happens in CronTick.
+func (m *marketStateMutation) terminateDeal(rt Runtime, provider addr.Address, dealID abi.DealID, epoch abi.ChainEpoch) {
+	state, found := m.loadTerminatingDeal(rt, provider, dealID, epoch)
+	if !found {
+		return
+	}
+
+	// A termination processed after the deal's payment was settled beyond its epoch takes effect from the settlement.
+	state.SlashEpoch = epoch
+	if state.LastUpdatedEpoch > epoch {
+		state.SlashEpoch = state.LastUpdatedEpoch
+	}
+
+	err := m.dealStates.Set(dealID, state)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set deal state %v", dealID)
+}
+
+// Validates the termination of a deal and queues it, to be processed by ContinueTermination.
+func (m *marketStateMutation) queueDealTermination(rt Runtime, provider addr.Address, dealID abi.DealID, epoch abi.ChainEpoch) {
+	if _, found := m.loadTerminatingDeal(rt, provider, dealID, epoch); !found {
+		return
+	}
+	index := m.nextPendingTermination + m.pendingTerminations.Length()
+	err := m.pendingTerminations.Set(index, &PendingDealTermination{Provider: provider, DealID: dealID, Epoch: epoch})
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to queue termination of deal %v", dealID)
+}
+
 // Deal start deadline elapsed without appearing in a proven sector.
 // Slash a portion of provider's collateral, and unlock remaining collaterals
 // for both provider and client.
-func (m *marketStateMutation) processDealInitTimedOut(rt Runtime, deal *DealProposal) abi.TokenAmount {
-	if err := m.unlockBalance(deal.Client, deal.TotalStorageFee(), ClientStorageFee); err != nil {
-		rt.Abortf(exitcode.ErrIllegalState, "failure unlocking client storage fee: %s", err)
+func (m *marketStateMutation) processDealInitTimedOut(rt Runtime, deal *DealProposal, upfront bool) abi.TokenAmount {
+	if err := m.unlockStorageFee(deal, deal.TotalStorageFee(), upfront); err != nil {
+		rt.Abortf(exitcode.ErrIllegalState, "failure unlocking storage fee: %s", err)
 	}
 	if err := m.unlockBalance(deal.Client, deal.ClientCollateral, ClientCollateral); err != nil {
 		rt.Abortf(exitcode.ErrIllegalState, "failure unlocking client collateral: %s", err)
 	}
 
 	amountSlashed := CollateralPenaltyForDealActivationMissed(deal.ProviderCollateral)
-	amountRemaining := big.Sub(deal.ProviderBalanceRequirement(), amountSlashed)
+	amountRemaining := big.Sub(deal.ProviderCollateral, amountSlashed)
 
 	if err := m.slashBalance(deal.Provider, amountSlashed, ProviderCollateral); err != nil {
 		rt.Abortf(exitcode.ErrIllegalState, "failed to slash balance: %s", err)
@@ -204,6 +478,40 @@ func (m *marketStateMutation) processDealInitTimedOut(rt Runtime, deal *DealProp
 	return amountSlashed
 }
 
+// Deal offer start epoch elapsed without acceptance by the provider.
+// Unlock the client's storage fee (if the client pays) and collateral. The provider has locked nothing.
+func (m *marketStateMutation) processDealOfferExpired(rt Runtime, offer *DealProposal) {
+	if !offer.ProviderPaysStorageFee() {
+		err := m.unlockStorageFee(offer, offer.TotalStorageFee(), false)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to unlock client storage fee")
+	}
+
+	err := m.unlockBalance(offer.Client, offer.ClientCollateral, ClientCollateral)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to unlock client collateral")
+}
+
+// Deal cancelled by either party before its start epoch.
+// Unlock the storage fee and the client's and provider's collateral, without penalty.
+func (m *marketStateMutation) processDealCancelled(rt Runtime, deal *DealProposal, upfront bool) {
+	err := m.unlockStorageFee(deal, deal.TotalStorageFee(), upfront)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to unlock storage fee")
+
+	err = m.unlockBalance(deal.Client, deal.ClientCollateral, ClientCollateral)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to unlock client collateral")
+
+	err = m.unlockBalance(deal.Provider, deal.ProviderCollateral, ProviderCollateral)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to unlock provider collateral")
+}
+
+// Renewal of a deal declined by either party before its end epoch. Unlock the storage fee for the renewal term.
+func (m *marketStateMutation) processDealRenewalDeclined(rt Runtime, deal *DealProposal, state *DealState) {
+	builtin.RequireState(rt, !state.RenewalDeclined, "deal renewal already declined")
+	state.RenewalDeclined = true
+
+	err := m.unlockStorageFee(deal, deal.RenewalStorageFee(), false)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to unlock renewal storage fee")
+}
+
 // Normal expiration. Unlock collaterals for both provider and client.
 func (m *marketStateMutation) processDealExpired(rt Runtime, deal *DealProposal, state *DealState) {
 	builtin.RequireState(rt, state.SectorStartEpoch != epochUndefined, "sector start epoch undefined")
@@ -216,12 +524,143 @@ func (m *marketStateMutation) processDealExpired(rt Runtime, deal *DealProposal,
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed unlocking deal client balance")
 }
 
+// Returns the last epoch at which a published deal may be activated: its start epoch, unless its client has granted
+// a later deadline.
+func activationDeadline(extensions *adt.Map, dealID abi.DealID, proposal *DealProposal) (abi.ChainEpoch, error) {
+	var ext ActivationExtension
+	found, err := extensions.Get(abi.UIntKey(uint64(dealID)), &ext)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to get activation extension for deal %d: %w", dealID, err)
+	}
+	if !found || ext.Granted == epochUndefined {
+		return proposal.StartEpoch, nil
+	}
+	return ext.Granted, nil
+}
+
+// Removes any activation extension of a deal that is activated or removed.
+func (m *marketStateMutation) removeActivationExtension(dealID abi.DealID) error {
+	if _, err := m.activationExtensions.TryDelete(abi.UIntKey(uint64(dealID))); err != nil {
+		return xerrors.Errorf("failed to delete activation extension for deal %d: %w", dealID, err)
+	}
+	return nil
+}
+
+// Adds a proposal to the pending set, scheduling its removal in case cron does not remove it first.
+func (m *marketStateMutation) addPendingProposal(pcid cid.Cid, proposal *DealProposal) error {
+	if err := m.pendingDeals.Put(abi.CidKey(pcid)); err != nil {
+		return xerrors.Errorf("failed to add pending proposal %v: %w", pcid, err)
+	}
+	expiry := proposal.MaxEndEpoch() + PendingProposalExpiry
+	value := cbg.CborCid(pcid)
+	if err := m.pendingExpirations.Add(abi.UIntKey(uint64(expiry)), &value); err != nil {
+		return xerrors.Errorf("failed to schedule expiry of pending proposal %v: %w", pcid, err)
+	}
+	m.pendingCount++
+	return nil
+}
+
+// Removes a proposal from the pending set. Its scheduled expiry remains, and has no effect.
+func (m *marketStateMutation) removePendingProposal(pcid cid.Cid) error {
+	if err := m.pendingDeals.Delete(abi.CidKey(pcid)); err != nil {
+		return xerrors.Errorf("failed to delete pending proposal %v: %w", pcid, err)
+	}
+	m.pendingCount--
+	return nil
+}
+
+// Removes from the pending set any proposals left there whose expiry is scheduled at an epoch,
+// returning the number removed.
+func (m *marketStateMutation) expirePendingProposals(epoch abi.ChainEpoch) (uint64, error) {
+	key := abi.UIntKey(uint64(epoch))
+	removed := uint64(0)
+	var pcid cbg.CborCid
+	if err := m.pendingExpirations.ForEach(key, &pcid, func(_ int64) error {
+		found, err := m.pendingDeals.TryDelete(abi.CidKey(cid.Cid(pcid)))
+		if err != nil {
+			return err
+		}
+		if found {
+			removed++
+		}
+		return nil
+	}); err != nil {
+		return 0, xerrors.Errorf("failed to expire pending proposals at epoch %d: %w", epoch, err)
+	}
+	if err := m.pendingExpirations.RemoveAll(key); err != nil {
+		return 0, xerrors.Errorf("failed to remove pending proposal expirations at epoch %d: %w", epoch, err)
+	}
+	m.pendingCount -= removed
+	return removed, nil
+}
+
+// Removes a deal that has not yet been activated from the set of upfront payment deals, returning
+// the deal's payment mode.
+func (m *marketStateMutation) popUpfrontPaymentDeal(dealID abi.DealID) (DealPaymentMode, error) {
+	upfront, err := m.upfrontDeals.Has(abi.UIntKey(uint64(dealID)))
+	if err != nil {
+		return 0, xerrors.Errorf("failed to check upfront payment for deal %d: %w", dealID, err)
+	}
+	if !upfront {
+		return DealPaymentPerEpoch, nil
+	}
+	if err := m.upfrontDeals.Delete(abi.UIntKey(uint64(dealID))); err != nil {
+		return 0, xerrors.Errorf("failed to delete upfront payment deal %d: %w", dealID, err)
+	}
+	return DealPaymentUpfront, nil
+}
+
 func (m *marketStateMutation) generateStorageDealID() abi.DealID {
 	ret := m.nextDealId
 	m.nextDealId = m.nextDealId + abi.DealID(1)
 	return ret
 }
 
+// Adds a published deal to the indexes of deals by provider and by piece.
+func (m *marketStateMutation) indexDeal(dealID abi.DealID, proposal *DealProposal) error {
+	if err := m.dealsByProvider.PutKey(abi.AddrKey(proposal.Provider), dealID); err != nil {
+		return xerrors.Errorf("failed to index deal %d by provider: %w", dealID, err)
+	}
+	if err := m.dealsByPiece.PutKey(abi.CidKey(proposal.PieceCID), dealID); err != nil {
+		return xerrors.Errorf("failed to index deal %d by piece: %w", dealID, err)
+	}
+	return nil
+}
+
+// Removes a deal from the indexes of deals by provider and by piece.
+func (m *marketStateMutation) unindexDeal(dealID abi.DealID, proposal *DealProposal) error {
+	if err := m.dealsByProvider.RemoveKey(abi.AddrKey(proposal.Provider), dealID); err != nil {
+		return xerrors.Errorf("failed to remove deal %d from provider index: %w", dealID, err)
+	}
+	if err := m.dealsByPiece.RemoveKey(abi.CidKey(proposal.PieceCID), dealID); err != nil {
+		return xerrors.Errorf("failed to remove deal %d from piece index: %w", dealID, err)
+	}
+	return nil
+}
+
+// Deal IDs derived from proposal CIDs have this bit set, and no higher bit.
+// Sequentially generated deal IDs are far below it, so the two kinds never coincide.
+const DerivedDealIDFlag = abi.DealID(1) << 62
+
+// Derives a deal ID from the CID of a deal proposal, whose client and provider are ID addresses.
+// The ID is taken from the leading bytes of the CID's digest, within [DerivedDealIDFlag, 2*DerivedDealIDFlag).
+func DerivedDealID(proposalCid cid.Cid) (abi.DealID, error) {
+	decoded, err := mh.Decode(proposalCid.Hash())
+	if err != nil {
+		return 0, xerrors.Errorf("failed to decode proposal cid %v: %w", proposalCid, err)
+	}
+	if len(decoded.Digest) < 8 {
+		return 0, xerrors.Errorf("proposal cid %v digest too short", proposalCid)
+	}
+	bits := abi.DealID(binary.BigEndian.Uint64(decoded.Digest[:8]))
+	return DerivedDealIDFlag | (bits & (DerivedDealIDFlag - 1)), nil
+}
+
+// Whether a deal ID was derived from a proposal CID rather than generated sequentially.
+func IsDerivedDealID(id abi.DealID) bool {
+	return id&DerivedDealIDFlag != 0
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // State utility functions
 ////////////////////////////////////////////////////////////////////////////////
@@ -241,9 +680,10 @@ func dealProposalIsInternallyValid(rt Runtime, proposal ClientDealProposal) erro
 	return nil
 }
 
-func dealGetPaymentRemaining(deal *DealProposal, slashEpoch abi.ChainEpoch) (abi.TokenAmount, error) {
-	if slashEpoch > deal.EndEpoch {
-		return big.Zero(), xerrors.Errorf("deal slash epoch %d after end epoch %d", slashEpoch, deal.EndEpoch)
+func dealGetPaymentRemaining(deal *DealProposal, state *DealState, slashEpoch abi.ChainEpoch) (abi.TokenAmount, error) {
+	endEpoch := dealEndEpoch(deal, state)
+	if slashEpoch > endEpoch {
+		return big.Zero(), xerrors.Errorf("deal slash epoch %d after end epoch %d", slashEpoch, endEpoch)
 	}
 
 	// Payments are always for start -> end epoch irrespective of when the deal is slashed.
@@ -251,12 +691,51 @@ func dealGetPaymentRemaining(deal *DealProposal, slashEpoch abi.ChainEpoch) (abi
 		slashEpoch = deal.StartEpoch
 	}
 
-	durationRemaining := deal.EndEpoch - slashEpoch
-	if durationRemaining < 0 {
-		return big.Zero(), xerrors.Errorf("deal remaining duration negative: %d", durationRemaining)
+	return dealPaymentBetween(deal, slashEpoch, endEpoch), nil
+}
+
+// The epoch at which a deal ends: the end of its renewal if it is renewable and the renewal has not been declined,
+// otherwise its end epoch.
+func dealEndEpoch(deal *DealProposal, state *DealState) abi.ChainEpoch {
+	if state.RenewalDeclined {
+		return deal.EndEpoch
+	}
+	return deal.MaxEndEpoch()
+}
+
+// Computes the payment for a deal's storage between two epochs, at the deal's price up to its end epoch
+// and at its renewal price (if renewable) after that.
+func dealPaymentBetween(deal *DealProposal, from, to abi.ChainEpoch) abi.TokenAmount {
+	payment := big.Zero()
+	termEnd := to
+	if termEnd > deal.EndEpoch {
+		termEnd = deal.EndEpoch
+	}
+	if termEnd > from {
+		payment = big.Mul(big.NewInt(int64(termEnd-from)), deal.StoragePricePerEpoch)
+	}
+
+	renewalStart := from
+	if renewalStart < deal.EndEpoch {
+		renewalStart = deal.EndEpoch
+	}
+	if deal.Renewal != nil && to > renewalStart {
+		payment = big.Add(payment, big.Mul(big.NewInt(int64(to-renewalStart)), deal.Renewal.StoragePricePerEpoch))
 	}
+	return payment
+}
 
-	return big.Mul(big.NewInt(int64(durationRemaining)), deal.StoragePricePerEpoch), nil
+// Computes the payment transferred from client to provider for a deal that has not been removed.
+// Per-epoch payments are settled up to the deal's last update, and upfront payments only on removal.
+func dealAmountSettled(deal *DealProposal, state *DealState) abi.TokenAmount {
+	if state.PaymentMode == DealPaymentUpfront || state.LastUpdatedEpoch == epochUndefined {
+		return big.Zero()
+	}
+	settledTo := state.LastUpdatedEpoch
+	if endEpoch := dealEndEpoch(deal, state); settledTo > endEpoch {
+		settledTo = endEpoch
+	}
+	return dealPaymentBetween(deal, deal.StartEpoch, settledTo)
 }
 
 // MarketStateMutationPermission is the mutation permission on a state field
@@ -284,17 +763,44 @@ type marketStateMutation struct {
 	escrowPermit MarketStateMutationPermission
 	escrowTable  *adt.BalanceTable
 
-	pendingPermit MarketStateMutationPermission
-	pendingDeals  *adt.Set
+	pendingPermit      MarketStateMutationPermission
+	pendingDeals       *adt.Set
+	pendingExpirations *adt.Multimap
+	pendingCount       uint64
 
 	dpePermit    MarketStateMutationPermission
 	dealsByEpoch *SetMultimap
 
+	upfrontPermit MarketStateMutationPermission
+	upfrontDeals  *adt.Set
+
+	offerPermit MarketStateMutationPermission
+	dealOffers  *DealArray
+
+	dbpPermit       MarketStateMutationPermission
+	dealsByProvider *SetMultimap
+
+	dbcPermit    MarketStateMutationPermission
+	dealsByPiece *SetMultimap
+
+	authPermit          MarketStateMutationPermission
+	authorizedProposals *adt.Set
+
+	terminationPermit      MarketStateMutationPermission
+	pendingTerminations    *adt.Array
+	nextPendingTermination uint64
+
+	extensionPermit      MarketStateMutationPermission
+	activationExtensions *adt.Map
+
 	lockedPermit                  MarketStateMutationPermission
 	lockedTable                   *adt.BalanceTable
+	lockedCollateralTable         *adt.BalanceTable
 	totalClientLockedCollateral   abi.TokenAmount
 	totalProviderLockedCollateral abi.TokenAmount
 	totalClientStorageFee         abi.TokenAmount
+	totalClientUpfrontPayments    abi.TokenAmount
+	totalProviderStorageFee       abi.TokenAmount
 
 	nextDealId abi.DealID
 }
@@ -326,9 +832,16 @@ func (m *marketStateMutation) build() (*marketStateMutation, error) {
 			return nil, xerrors.Errorf("failed to load locked table: %w", err)
 		}
 		m.lockedTable = lt
+		lct, err := adt.AsBalanceTable(m.store, m.st.LockedCollateralTable)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load locked collateral table: %w", err)
+		}
+		m.lockedCollateralTable = lct
 		m.totalClientLockedCollateral = m.st.TotalClientLockedCollateral.Copy()
 		m.totalClientStorageFee = m.st.TotalClientStorageFee.Copy()
 		m.totalProviderLockedCollateral = m.st.TotalProviderLockedCollateral.Copy()
+		m.totalClientUpfrontPayments = m.st.TotalClientUpfrontPayments.Copy()
+		m.totalProviderStorageFee = m.st.TotalProviderStorageFee.Copy()
 	}
 
 	if m.escrowPermit != Invalid {
@@ -345,6 +858,14 @@ func (m *marketStateMutation) build() (*marketStateMutation, error) {
 			return nil, xerrors.Errorf("failed to load pending proposals: %w", err)
 		}
 		m.pendingDeals = pending
+
+		expirations, err := adt.AsMultimap(m.store, m.st.PendingProposalExpirations, builtin.DefaultHamtBitwidth,
+			PendingProposalExpirationsAmtBitwidth)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load pending proposal expirations: %w", err)
+		}
+		m.pendingExpirations = expirations
+		m.pendingCount = m.st.PendingProposalCount
 	}
 
 	if m.dpePermit != Invalid {
@@ -355,6 +876,63 @@ func (m *marketStateMutation) build() (*marketStateMutation, error) {
 		m.dealsByEpoch = dbe
 	}
 
+	if m.upfrontPermit != Invalid {
+		upfront, err := adt.AsSet(m.store, m.st.UpfrontPaymentDeals, builtin.DefaultHamtBitwidth)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load upfront payment deals: %w", err)
+		}
+		m.upfrontDeals = upfront
+	}
+
+	if m.offerPermit != Invalid {
+		offers, err := AsDealProposalArray(m.store, m.st.DealOffers)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load deal offers: %w", err)
+		}
+		m.dealOffers = offers
+	}
+
+	if m.dbpPermit != Invalid {
+		dbp, err := AsSetMultimap(m.store, m.st.DealsByProvider, builtin.DefaultHamtBitwidth, builtin.DefaultHamtBitwidth)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load deals by provider: %w", err)
+		}
+		m.dealsByProvider = dbp
+	}
+
+	if m.dbcPermit != Invalid {
+		dbc, err := AsSetMultimap(m.store, m.st.DealsByPiece, builtin.DefaultHamtBitwidth, builtin.DefaultHamtBitwidth)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load deals by piece: %w", err)
+		}
+		m.dealsByPiece = dbc
+	}
+
+	if m.authPermit != Invalid {
+		auth, err := adt.AsSet(m.store, m.st.AuthorizedProposals, builtin.DefaultHamtBitwidth)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load authorized proposals: %w", err)
+		}
+		m.authorizedProposals = auth
+	}
+
+	if m.terminationPermit != Invalid {
+		pending, err := adt.AsArray(m.store, m.st.PendingTerminations, PendingTerminationsAmtBitwidth)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load pending terminations: %w", err)
+		}
+		m.pendingTerminations = pending
+		m.nextPendingTermination = m.st.NextPendingTermination
+	}
+
+	if m.extensionPermit != Invalid {
+		extensions, err := adt.AsMap(m.store, m.st.ActivationExtensions, builtin.DefaultHamtBitwidth)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load activation extensions: %w", err)
+		}
+		m.activationExtensions = extensions
+	}
+
 	m.nextDealId = m.st.NextID
 
 	return m, nil
@@ -390,6 +968,41 @@ func (m *marketStateMutation) withDealsByEpoch(permit MarketStateMutationPermiss
 	return m
 }
 
+func (m *marketStateMutation) withUpfrontPaymentDeals(permit MarketStateMutationPermission) *marketStateMutation {
+	m.upfrontPermit = permit
+	return m
+}
+
+func (m *marketStateMutation) withDealOffers(permit MarketStateMutationPermission) *marketStateMutation {
+	m.offerPermit = permit
+	return m
+}
+
+func (m *marketStateMutation) withDealsByProvider(permit MarketStateMutationPermission) *marketStateMutation {
+	m.dbpPermit = permit
+	return m
+}
+
+func (m *marketStateMutation) withDealsByPiece(permit MarketStateMutationPermission) *marketStateMutation {
+	m.dbcPermit = permit
+	return m
+}
+
+func (m *marketStateMutation) withAuthorizedProposals(permit MarketStateMutationPermission) *marketStateMutation {
+	m.authPermit = permit
+	return m
+}
+
+func (m *marketStateMutation) withPendingTerminations(permit MarketStateMutationPermission) *marketStateMutation {
+	m.terminationPermit = permit
+	return m
+}
+
+func (m *marketStateMutation) withActivationExtensions(permit MarketStateMutationPermission) *marketStateMutation {
+	m.extensionPermit = permit
+	return m
+}
+
 func (m *marketStateMutation) commitState() error {
 	var err error
 	if m.proposalPermit == WritePermission {
@@ -408,9 +1021,14 @@ func (m *marketStateMutation) commitState() error {
 		if m.st.LockedTable, err = m.lockedTable.Root(); err != nil {
 			return xerrors.Errorf("failed to flush locked table: %w", err)
 		}
+		if m.st.LockedCollateralTable, err = m.lockedCollateralTable.Root(); err != nil {
+			return xerrors.Errorf("failed to flush locked collateral table: %w", err)
+		}
 		m.st.TotalClientLockedCollateral = m.totalClientLockedCollateral.Copy()
 		m.st.TotalProviderLockedCollateral = m.totalProviderLockedCollateral.Copy()
 		m.st.TotalClientStorageFee = m.totalClientStorageFee.Copy()
+		m.st.TotalClientUpfrontPayments = m.totalClientUpfrontPayments.Copy()
+		m.st.TotalProviderStorageFee = m.totalProviderStorageFee.Copy()
 	}
 
 	if m.escrowPermit == WritePermission {
@@ -423,6 +1041,10 @@ func (m *marketStateMutation) commitState() error {
 		if m.st.PendingProposals, err = m.pendingDeals.Root(); err != nil {
 			return xerrors.Errorf("failed to flush pending deals: %w", err)
 		}
+		if m.st.PendingProposalExpirations, err = m.pendingExpirations.Root(); err != nil {
+			return xerrors.Errorf("failed to flush pending proposal expirations: %w", err)
+		}
+		m.st.PendingProposalCount = m.pendingCount
 	}
 
 	if m.dpePermit == WritePermission {
@@ -431,6 +1053,49 @@ func (m *marketStateMutation) commitState() error {
 		}
 	}
 
+	if m.upfrontPermit == WritePermission {
+		if m.st.UpfrontPaymentDeals, err = m.upfrontDeals.Root(); err != nil {
+			return xerrors.Errorf("failed to flush upfront payment deals: %w", err)
+		}
+	}
+
+	if m.offerPermit == WritePermission {
+		if m.st.DealOffers, err = m.dealOffers.Root(); err != nil {
+			return xerrors.Errorf("failed to flush deal offers: %w", err)
+		}
+	}
+
+	if m.dbpPermit == WritePermission {
+		if m.st.DealsByProvider, err = m.dealsByProvider.Root(); err != nil {
+			return xerrors.Errorf("failed to flush deals by provider: %w", err)
+		}
+	}
+
+	if m.dbcPermit == WritePermission {
+		if m.st.DealsByPiece, err = m.dealsByPiece.Root(); err != nil {
+			return xerrors.Errorf("failed to flush deals by piece: %w", err)
+		}
+	}
+
+	if m.authPermit == WritePermission {
+		if m.st.AuthorizedProposals, err = m.authorizedProposals.Root(); err != nil {
+			return xerrors.Errorf("failed to flush authorized proposals: %w", err)
+		}
+	}
+
+	if m.terminationPermit == WritePermission {
+		if m.st.PendingTerminations, err = m.pendingTerminations.Root(); err != nil {
+			return xerrors.Errorf("failed to flush pending terminations: %w", err)
+		}
+		m.st.NextPendingTermination = m.nextPendingTermination
+	}
+
+	if m.extensionPermit == WritePermission {
+		if m.st.ActivationExtensions, err = m.activationExtensions.Root(); err != nil {
+			return xerrors.Errorf("failed to flush activation extensions: %w", err)
+		}
+	}
+
 	m.st.NextID = m.nextDealId
 	return nil
 }
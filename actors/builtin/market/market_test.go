@@ -2,6 +2,7 @@ package market_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"strings"
 	"testing"
@@ -200,6 +201,56 @@ func TestMarketActor(t *testing.T) {
 		})
 	})
 
+	t.Run("AddBalanceFor", func(t *testing.T) {
+		sponsor := tutil.NewIDAddr(t, 909)
+
+		t.Run("any actor can fund a client's escrow", func(t *testing.T) {
+			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+
+			rt.SetCaller(sponsor, builtin.StorageMinerActorCodeID)
+			rt.SetReceived(abi.NewTokenAmount(10))
+			rt.ExpectValidateCallerAny()
+			rt.Call(actor.AddBalanceFor, &client)
+			rt.Verify()
+			rt.SetBalance(big.Add(rt.Balance(), abi.NewTokenAmount(10)))
+
+			assert.Equal(t, abi.NewTokenAmount(10), actor.getEscrowBalance(rt, client))
+			actor.assertAccountZero(rt, sponsor)
+			actor.checkState(rt)
+
+			// the client can withdraw the sponsored funds
+			actor.withdrawClientBalance(rt, client, abi.NewTokenAmount(10), abi.NewTokenAmount(10))
+			actor.checkState(rt)
+		})
+
+		t.Run("funds a provider's escrow", func(t *testing.T) {
+			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+
+			rt.SetCaller(sponsor, builtin.AccountActorCodeID)
+			rt.SetReceived(abi.NewTokenAmount(10))
+			rt.ExpectValidateCallerAny()
+			expectGetControlAddresses(rt, provider, owner, worker)
+			rt.Call(actor.AddBalanceFor, &provider)
+			rt.Verify()
+			rt.SetBalance(big.Add(rt.Balance(), abi.NewTokenAmount(10)))
+
+			assert.Equal(t, abi.NewTokenAmount(10), actor.getEscrowBalance(rt, provider))
+			actor.checkState(rt)
+		})
+
+		t.Run("fail when balance is zero", func(t *testing.T) {
+			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+
+			rt.SetCaller(sponsor, builtin.AccountActorCodeID)
+			rt.SetReceived(big.Zero())
+			rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+				rt.Call(actor.AddBalanceFor, &client)
+			})
+			rt.Verify()
+			actor.checkState(rt)
+		})
+	})
+
 	t.Run("WithdrawBalance", func(t *testing.T) {
 		startEpoch := abi.ChainEpoch(10)
 		endEpoch := startEpoch + 200*builtin.EpochsInDay
@@ -399,6 +450,101 @@ func TestMarketActor(t *testing.T) {
 			actor.checkState(rt)
 		})
 	})
+
+	t.Run("WithdrawBalanceTo", func(t *testing.T) {
+		coldWallet := tutil.NewIDAddr(t, 910)
+
+		t.Run("owner withdraws provider funds to a designated recipient", func(t *testing.T) {
+			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+			actor.addProviderFunds(rt, abi.NewTokenAmount(20), minerAddrs)
+
+			rt.SetCaller(owner, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(owner)
+			expectGetControlAddresses(rt, provider, owner, worker)
+			rt.ExpectSend(coldWallet, builtin.MethodSend, nil, abi.NewTokenAmount(15), nil, exitcode.Ok)
+			rt.Call(actor.WithdrawBalanceTo, &market.WithdrawBalanceToParams{
+				ProviderOrClientAddress: provider,
+				Amount:                  abi.NewTokenAmount(15),
+				Recipient:               coldWallet,
+			})
+			rt.Verify()
+
+			assert.Equal(t, abi.NewTokenAmount(5), actor.getEscrowBalance(rt, provider))
+			actor.checkState(rt)
+		})
+
+		t.Run("worker may withdraw provider funds to the owner", func(t *testing.T) {
+			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+			actor.addProviderFunds(rt, abi.NewTokenAmount(20), minerAddrs)
+
+			rt.SetCaller(worker, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(owner, worker)
+			expectGetControlAddresses(rt, provider, owner, worker)
+			rt.ExpectSend(owner, builtin.MethodSend, nil, abi.NewTokenAmount(20), nil, exitcode.Ok)
+			rt.Call(actor.WithdrawBalanceTo, &market.WithdrawBalanceToParams{
+				ProviderOrClientAddress: provider,
+				Amount:                  abi.NewTokenAmount(25),
+				Recipient:               owner,
+			})
+			rt.Verify()
+
+			actor.assertAccountZero(rt, provider)
+			actor.checkState(rt)
+		})
+
+		t.Run("fails if worker designates a recipient other than the owner", func(t *testing.T) {
+			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+			actor.addProviderFunds(rt, abi.NewTokenAmount(20), minerAddrs)
+
+			rt.SetCaller(worker, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(owner)
+			expectGetControlAddresses(rt, provider, owner, worker)
+			rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+				rt.Call(actor.WithdrawBalanceTo, &market.WithdrawBalanceToParams{
+					ProviderOrClientAddress: provider,
+					Amount:                  abi.NewTokenAmount(1),
+					Recipient:               coldWallet,
+				})
+			})
+			rt.Verify()
+
+			assert.Equal(t, abi.NewTokenAmount(20), actor.getEscrowBalance(rt, provider))
+			actor.checkState(rt)
+		})
+
+		t.Run("client withdraws to a designated recipient", func(t *testing.T) {
+			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+			actor.addParticipantFunds(rt, client, abi.NewTokenAmount(20))
+
+			rt.SetCaller(client, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(client)
+			rt.ExpectSend(coldWallet, builtin.MethodSend, nil, abi.NewTokenAmount(1), nil, exitcode.Ok)
+			rt.Call(actor.WithdrawBalanceTo, &market.WithdrawBalanceToParams{
+				ProviderOrClientAddress: client,
+				Amount:                  abi.NewTokenAmount(1),
+				Recipient:               coldWallet,
+			})
+			rt.Verify()
+
+			assert.Equal(t, abi.NewTokenAmount(19), actor.getEscrowBalance(rt, client))
+			actor.checkState(rt)
+		})
+
+		t.Run("fails without a recipient", func(t *testing.T) {
+			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+			actor.addParticipantFunds(rt, client, abi.NewTokenAmount(20))
+
+			rt.SetCaller(client, builtin.AccountActorCodeID)
+			rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "recipient address must be specified", func() {
+				rt.Call(actor.WithdrawBalanceTo, &market.WithdrawBalanceToParams{
+					ProviderOrClientAddress: client,
+					Amount:                  abi.NewTokenAmount(1),
+				})
+			})
+			rt.Verify()
+			actor.checkState(rt)
+		})
+	})
 }
 
 func TestPublishStorageDeals(t *testing.T) {
@@ -686,9 +832,21 @@ func TestPublishStorageDealsFailures(t *testing.T) {
 				},
 				exitCode: exitcode.ErrIllegalArgument,
 			},
-			"negative price per epoch": {
+			"price per epoch less than negative total filecoin": {
+				setup: func(_ *mock.Runtime, _ *marketActorTestHarness, d *market.DealProposal) {
+					d.StoragePricePerEpoch = big.Sub(builtin.TotalFilecoin.Neg(), big.NewInt(1))
+				},
+				exitCode: exitcode.ErrIllegalArgument,
+			},
+			"negative slashing grace period": {
+				setup: func(_ *mock.Runtime, _ *marketActorTestHarness, d *market.DealProposal) {
+					d.SlashingGracePeriod = -1
+				},
+				exitCode: exitcode.ErrIllegalArgument,
+			},
+			"slashing grace period longer than deal duration": {
 				setup: func(_ *mock.Runtime, _ *marketActorTestHarness, d *market.DealProposal) {
-					d.StoragePricePerEpoch = abi.NewTokenAmount(-1)
+					d.SlashingGracePeriod = d.Duration() + 1
 				},
 				exitCode: exitcode.ErrIllegalArgument,
 			},
@@ -874,7 +1032,7 @@ func TestPublishStorageDealsFailures(t *testing.T) {
 
 	// fail when deals have different providers
 	{
-		t.Run("fail when deals have different providers", func(t *testing.T) {
+		t.Run("deals with a different provider are not published", func(t *testing.T) {
 			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
 			deal1 := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
 			m2 := &minerAddrs{owner, worker, tutil.NewIDAddr(t, 1000), nil}
@@ -890,11 +1048,61 @@ func TestPublishStorageDealsFailures(t *testing.T) {
 			rt.ExpectVerifySignature(crypto.Signature{}, deal1.Client, mustCbor(&deal1), nil)
 			rt.ExpectVerifySignature(crypto.Signature{}, deal2.Client, mustCbor(&deal2), nil)
 
-			rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
-				rt.Call(actor.PublishStorageDeals, params)
-			})
+			ret := rt.Call(actor.PublishStorageDeals, params).(*market.PublishStorageDealsReturn)
+			rt.Verify()
+
+			require.Len(t, ret.IDs, 1)
+			valid, err := ret.ValidDeals.All(2)
+			require.NoError(t, err)
+			assert.Equal(t, []uint64{0}, valid)
+			assert.Equal(t, []exitcode.ExitCode{exitcode.Ok, exitcode.ErrIllegalArgument}, ret.FailCodes)
+			assert.Equal(t, deal1.PieceCID, actor.getDealProposal(rt, ret.IDs[0]).PieceCID)
+			actor.checkState(rt)
+		})
+
+		t.Run("publishes the valid deals of a batch", func(t *testing.T) {
+			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+			rt.SetEpoch(currentEpoch)
+			deal1 := generateDealProposal(client, provider, startEpoch, endEpoch)
+			deal2 := generateDealProposal(client, provider, startEpoch, endEpoch+1)
+			deal3 := generateDealProposal(client, provider, startEpoch, endEpoch+2)
+			deal4 := generateDealProposal(client, provider, startEpoch, endEpoch+3)
+			deal1.VerifiedDeal = true
+
+			// The client's funds cover deal2 and deal3 but not also deal4.
+			actor.addParticipantFunds(rt, client, big.Add(deal2.ClientBalanceRequirement(), deal3.ClientBalanceRequirement()))
+			actor.addProviderFunds(rt, big.Sum(deal1.ProviderCollateral, deal2.ProviderCollateral, deal3.ProviderCollateral,
+				deal4.ProviderCollateral), mAddrs)
+			params := mkPublishStorageParams(deal1, deal2, deal3, deal4)
 
+			rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
+			rt.ExpectSend(provider, builtin.MethodsMiner.ControlAddresses, nil, abi.NewTokenAmount(0), &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
+			expectQueryNetworkInfo(rt, actor)
+			rt.SetCaller(worker, builtin.AccountActorCodeID)
+			rt.ExpectVerifySignature(crypto.Signature{}, deal1.Client, mustCbor(&deal1), nil)
+			rt.ExpectVerifySignature(crypto.Signature{}, deal2.Client, mustCbor(&deal2), nil)
+			rt.ExpectVerifySignature(crypto.Signature{}, deal3.Client, mustCbor(&deal3), nil)
+			rt.ExpectVerifySignature(crypto.Signature{}, deal4.Client, mustCbor(&deal4), nil)
+			// The verified client has insufficient data cap for deal1.
+			rt.ExpectSend(builtin.VerifiedRegistryActorAddr, builtin.MethodsVerifiedRegistry.UseBytes, &verifreg.UseBytesParams{
+				Address:  client,
+				DealSize: big.NewIntUnsigned(uint64(deal1.PieceSize)),
+			}, abi.NewTokenAmount(0), nil, exitcode.ErrIllegalArgument)
+
+			ret := rt.Call(actor.PublishStorageDeals, params).(*market.PublishStorageDealsReturn)
 			rt.Verify()
+
+			require.Len(t, ret.IDs, 2)
+			valid, err := ret.ValidDeals.All(4)
+			require.NoError(t, err)
+			assert.Equal(t, []uint64{1, 2}, valid)
+			assert.Equal(t, []exitcode.ExitCode{exitcode.ErrIllegalArgument, exitcode.Ok, exitcode.Ok, exitcode.ErrInsufficientFunds}, ret.FailCodes)
+			assert.Equal(t, deal2.EndEpoch, actor.getDealProposal(rt, ret.IDs[0]).EndEpoch)
+			assert.Equal(t, deal3.EndEpoch, actor.getDealProposal(rt, ret.IDs[1]).EndEpoch)
+
+			// Only the published deals' funds are locked.
+			assert.Equal(t, big.Add(deal2.ClientBalanceRequirement(), deal3.ClientBalanceRequirement()), actor.getLockedBalance(rt, client))
+			assert.Equal(t, big.Add(deal2.ProviderCollateral, deal3.ProviderCollateral), actor.getLockedBalance(rt, provider))
 			actor.checkState(rt)
 		})
 
@@ -1407,6 +1615,99 @@ func TestOnMinerSectorsTerminate(t *testing.T) {
 		actor.assertDeaslNotTerminated(rt, dealId1)
 		actor.checkState(rt)
 	})
+
+	t.Run("terminations beyond the cap are queued and continued in order", func(t *testing.T) {
+		prevMax := market.DealTerminationsMax
+		market.DealTerminationsMax = 2
+		defer func() { market.DealTerminationsMax = prevMax }()
+
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		rt.SetEpoch(currentEpoch)
+
+		var dealIds []abi.DealID
+		for i := 0; i < 5; i++ {
+			dealIds = append(dealIds, actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch+abi.ChainEpoch(i)))
+		}
+		actor.activateDeals(rt, sectorExpiry, provider, currentEpoch, dealIds...)
+
+		// the first two deals are terminated immediately, the rest are queued
+		terminationEpoch := rt.SetEpoch(startEpoch + 10)
+		actor.terminateDeals(rt, provider, dealIds...)
+		actor.assertDealsTerminated(rt, terminationEpoch, dealIds[:2]...)
+		actor.assertDeaslNotTerminated(rt, dealIds[2:]...)
+		actor.checkState(rt)
+
+		// anyone may continue the termination, which takes effect at the epoch of the sector's termination
+		rt.SetEpoch(terminationEpoch + 1)
+		assert.Equal(t, uint64(1), actor.continueTermination(rt))
+		actor.assertDealsTerminated(rt, terminationEpoch, dealIds[2:4]...)
+		actor.assertDeaslNotTerminated(rt, dealIds[4])
+
+		assert.Equal(t, uint64(0), actor.continueTermination(rt))
+		actor.assertDealsTerminated(rt, terminationEpoch, dealIds[4])
+		var st market.State
+		rt.GetState(&st)
+		assert.Equal(t, uint64(3), st.NextPendingTermination)
+
+		// continuing with nothing queued does nothing
+		assert.Equal(t, uint64(0), actor.continueTermination(rt))
+		actor.checkState(rt)
+	})
+
+	t.Run("queued termination takes effect no earlier than the deal's last payment", func(t *testing.T) {
+		prevMax := market.DealTerminationsMax
+		market.DealTerminationsMax = 1
+		defer func() { market.DealTerminationsMax = prevMax }()
+
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		rt.SetEpoch(currentEpoch)
+		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch+1)
+		actor.activateDeals(rt, sectorExpiry, provider, currentEpoch, dealId1, dealId2)
+
+		terminationEpoch := rt.SetEpoch(startEpoch + 10)
+		actor.terminateDeals(rt, provider, dealId1, dealId2)
+
+		// the queued deal is paid by cron before its termination is processed
+		rt.SetEpoch(processEpoch(t, dealId2, startEpoch) + market.DealUpdatesInterval)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, actor.getDealProposal(rt, dealId1).ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+		lastUpdated := actor.getDealState(rt, dealId2).LastUpdatedEpoch
+		require.Greater(t, int64(lastUpdated), int64(terminationEpoch))
+
+		assert.Equal(t, uint64(0), actor.continueTermination(rt))
+		actor.assertDealsTerminated(rt, lastUpdated, dealId2)
+
+		// the deal is then settled without paying for the epochs since its termination twice
+		d2 := actor.getDealProposal(rt, dealId2)
+		rt.SetEpoch(lastUpdated + market.DealUpdatesInterval)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d2.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+		actor.assertDealDeleted(rt, dealId2, d2)
+		actor.checkState(rt)
+	})
+
+	t.Run("queued deals are validated when notified", func(t *testing.T) {
+		prevMax := market.DealTerminationsMax
+		market.DealTerminationsMax = 1
+		defer func() { market.DealTerminationsMax = prevMax }()
+
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		rt.SetEpoch(currentEpoch)
+		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+		actor.activateDeals(rt, sectorExpiry, provider, currentEpoch, dealId1)
+		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch+1)
+
+		params := mkTerminateDealParams(currentEpoch, dealId1, dealId2)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "no state for deal", func() {
+			rt.Call(actor.OnMinerSectorsTerminate, params)
+		})
+		rt.Verify()
+		actor.assertDeaslNotTerminated(rt, dealId1)
+		actor.checkState(rt)
+	})
 }
 
 func TestCronTick(t *testing.T) {
@@ -1437,6 +1738,8 @@ func TestCronTick(t *testing.T) {
 		actor.checkState(rt,
 			"no deal proposal for deal state \\d+",
 			"pending proposal with cid \\w+ not found within proposals .*",
+			"locked collateral for \\w+, \\d+, does not match collateral of its deals, 0",
+			"locked collateral for \\w+, \\d+, does not match collateral of its deals, 0",
 			"deal op found for deal id \\d+ with missing proposal at epoch \\d+",
 		)
 	})
@@ -1465,8 +1768,8 @@ func TestCronTick(t *testing.T) {
 	})
 
 	t.Run("crontick for a deal at it's start epoch results in zero payment and no slashing", func(t *testing.T) {
-		// set start epoch to coincide with processing (0 + 0 % 2880 = 0)
-		startEpoch := abi.ChainEpoch(0)
+		// set start epoch to coincide with processing of the first deal
+		startEpoch := processEpoch(t, 0, 0)
 		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
 		dealId := actor.publishAndActivateDeal(rt, client, mAddrs, startEpoch, endEpoch, 0, sectorExpiry)
 
@@ -1490,8 +1793,12 @@ func TestCronTick(t *testing.T) {
 
 		dealId2 := actor.publishAndActivateDeal(rt, client, mAddrs, startEpoch+1, endEpoch+1, 0, sectorExpiry)
 
-		// slash deal1
-		slashEpoch := rt.SetEpoch(processEpoch(t, dealId2, startEpoch) + abi.ChainEpoch(100))
+		// slash deal1 after both deals' first processing epochs
+		firstProcessEpoch := processEpoch(t, dealId1, startEpoch)
+		if e := processEpoch(t, dealId2, startEpoch+1); e > firstProcessEpoch {
+			firstProcessEpoch = e
+		}
+		slashEpoch := rt.SetEpoch(firstProcessEpoch + abi.ChainEpoch(100))
 		actor.terminateDeals(rt, provider, dealId1)
 
 		// cron tick will slash deal1 and make payment for deal2
@@ -1692,24 +1999,35 @@ func TestLockedFundTrackingStates(t *testing.T) {
 	actor.assertLockedFundStates(rt, csf, plc, clc)
 
 	// make payment for p1 and p2, p3 times out as it has not been activated
-	curr = rt.SetEpoch(processEpoch(t, dealId3, startEpoch))
+	firstTick := processEpoch(t, dealId3, startEpoch)
+	for _, id := range []abi.DealID{dealId1, dealId2} {
+		if e := processEpoch(t, id, startEpoch); e > firstTick {
+			firstTick = e
+		}
+	}
+	curr = rt.SetEpoch(firstTick)
 	rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d3.ProviderCollateral, nil, exitcode.Ok)
 	actor.cronTick(rt)
-	payment := big.Product(big.NewInt(4), d1.StoragePricePerEpoch)
+	payment := big.Mul(big.NewInt(int64(firstTick-startEpoch)), big.Add(d1.StoragePricePerEpoch, d2.StoragePricePerEpoch))
 	csf = big.Sub(big.Sub(csf, payment), d3.TotalStorageFee())
 	plc = big.Sub(plc, d3.ProviderCollateral)
 	clc = big.Sub(clc, d3.ClientCollateral)
 	actor.assertLockedFundStates(rt, csf, plc, clc)
 
-	// deal1 and deal2 will now be charged at epoch curr + market.DealUpdatesInterval, so nothing changes before that.
-	curr = rt.SetEpoch(curr + market.DealUpdatesInterval - 1)
+	// deal1 and deal2 will now be charged at their next processing epochs, so nothing changes before that.
+	next1 := processEpoch(t, dealId1, firstTick+1)
+	next2 := processEpoch(t, dealId2, firstTick+1)
+	nextFirst, nextLast := next1, next2
+	if nextLast < nextFirst {
+		nextFirst, nextLast = nextLast, nextFirst
+	}
+	rt.SetEpoch(nextFirst - 1)
 	actor.cronTick(rt)
 	actor.assertLockedFundStates(rt, csf, plc, clc)
 
 	// one more round of payment for deal1 and deal2
-	curr = rt.SetEpoch(curr + 1)
-	duration := big.NewInt(market.DealUpdatesInterval)
-	payment = big.Product(big.NewInt(2), d1.StoragePricePerEpoch, duration)
+	curr = rt.SetEpoch(nextLast)
+	payment = big.Mul(big.NewInt(int64(curr-firstTick)), big.Add(d1.StoragePricePerEpoch, d2.StoragePricePerEpoch))
 	csf = big.Sub(csf, payment)
 	actor.cronTick(rt)
 	actor.assertLockedFundStates(rt, csf, plc, clc)
@@ -1760,8 +2078,8 @@ func TestCronTickTimedoutDeals(t *testing.T) {
 	})
 
 	t.Run("publishing timed out deal again should work after cron tick as it should no longer be pending", func(t *testing.T) {
-		// dealID(0) + 0 % 2880 = 0.  Need processing epoch == start epoch to do hack where we publish deals after cron in same epoch
-		startEpoch := abi.ChainEpoch(0)
+		// Need processing epoch == start epoch to do hack where we publish deals after cron in same epoch
+		startEpoch := processEpoch(t, 0, 0)
 		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
 		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
 		d := actor.getDealProposal(rt, dealId)
@@ -1880,8 +2198,7 @@ func TestCronTickDealExpiry(t *testing.T) {
 
 	t.Run("deal expiry -> regular payments till deal expires and then locked funds are unlocked", func(t *testing.T) {
 		// start epoch should equal first processing epoch for logic to work
-		// 2880 + 0 % 2880 = 2880
-		startEpoch := abi.ChainEpoch(builtin.EpochsInDay)
+		startEpoch := processEpoch(t, 0, builtin.EpochsInDay)
 		t.Parallel()
 		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
 		dealId := actor.publishAndActivateDeal(rt, client, mAddrs, startEpoch, endEpoch, 0, sectorExpiry)
@@ -1894,13 +2211,14 @@ func TestCronTickDealExpiry(t *testing.T) {
 		require.EqualValues(t, pay, big.Mul(big.NewInt(5), d.StoragePricePerEpoch))
 		require.EqualValues(t, big.Zero(), slashed)
 
+		// The deal stays in its update bucket after the late tick, so the next schedule is a full interval after the start.
 		// Setting the current epoch to anything less than next schedule wont make any payment
-		current = rt.SetEpoch(current + market.DealUpdatesInterval - 1)
+		current = rt.SetEpoch(startEpoch + market.DealUpdatesInterval - 1)
 		actor.cronTickNoChange(rt, client, provider)
 
 		// however setting the current epoch to next schedle will make the payment
 		current = rt.SetEpoch(current + 1)
-		duration := big.NewInt(market.DealUpdatesInterval)
+		duration := big.NewInt(market.DealUpdatesInterval - 5)
 		pay, slashed = actor.cronTickAndAssertBalances(rt, client, provider, current, dealId)
 		require.EqualValues(t, big.Mul(duration, d.StoragePricePerEpoch), pay)
 		require.EqualValues(t, big.Zero(), slashed)
@@ -2126,8 +2444,7 @@ func TestCronTickDealSlashing(t *testing.T) {
 	t.Run("deal is correctly processed twice in the same crontick and slashed", func(t *testing.T) {
 		t.Parallel()
 		// start epoch should equal first processing epoch for logic to work
-		// 2880 + 0 % 2880 = 2880
-		startEpoch := abi.ChainEpoch(builtin.EpochsInDay)
+		startEpoch := processEpoch(t, 0, builtin.EpochsInDay)
 		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
 		dealId := actor.publishAndActivateDeal(rt, client, mAddrs, startEpoch, endEpoch, 0, sectorExpiry)
 		d := actor.getDealProposal(rt, dealId)
@@ -2200,8 +2517,8 @@ func TestCronTickDealSlashing(t *testing.T) {
 		require.EqualValues(t, big.Zero(), slashed)
 
 		// Setting the current epoch to before the next schedule will NOT make any changes as the deal
-		// is still not scheduled
-		current = rt.SetEpoch(current + market.DealUpdatesInterval - 1)
+		// is still not scheduled. The late tick doesn't move the deal's schedule.
+		current = rt.SetEpoch(processStart + market.DealUpdatesInterval - 1)
 		actor.cronTickNoChange(rt, client, provider)
 
 		// a second cron tick for the same epoch should not change anything
@@ -2209,7 +2526,7 @@ func TestCronTickDealSlashing(t *testing.T) {
 
 		//  make another payment
 		current = rt.SetEpoch(current + 1)
-		duration := big.NewInt(market.DealUpdatesInterval)
+		duration := big.NewInt(market.DealUpdatesInterval - 5)
 		pay, slashed = actor.cronTickAndAssertBalances(rt, client, provider, current, dealId)
 		require.EqualValues(t, pay, big.Mul(duration, d.StoragePricePerEpoch))
 		require.EqualValues(t, big.Zero(), slashed)
@@ -2279,259 +2596,2184 @@ func TestCronTickDealSlashing(t *testing.T) {
 	})
 }
 
-func TestMarketActorDeals(t *testing.T) {
+func TestUpfrontPaymentDeals(t *testing.T) {
 	owner := tutil.NewIDAddr(t, 101)
 	provider := tutil.NewIDAddr(t, 102)
 	worker := tutil.NewIDAddr(t, 103)
 	client := tutil.NewIDAddr(t, 104)
-	minerAddrs := &minerAddrs{owner, worker, provider, nil}
-
-	var st market.State
-
-	// Test adding provider funds from both worker and owner address
-	rt, actor := basicMarketSetup(t, owner, provider, worker, client)
-	actor.addProviderFunds(rt, abi.NewTokenAmount(20000000), minerAddrs)
-	rt.GetState(&st)
-	assert.Equal(t, abi.NewTokenAmount(20000000), actor.getEscrowBalance(rt, provider))
-
-	actor.addParticipantFunds(rt, client, abi.NewTokenAmount(20000000))
-
-	dealProposal := generateDealProposal(client, provider, abi.ChainEpoch(1), abi.ChainEpoch(200*builtin.EpochsInDay))
-	params := &market.PublishStorageDealsParams{Deals: []market.ClientDealProposal{{Proposal: dealProposal}}}
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
 
-	// First attempt at publishing the deal should work
-	{
-		rt.SetCaller(worker, builtin.AccountActorCodeID)
-		actor.publishDeals(rt, minerAddrs, publishDealReq{deal: dealProposal})
-	}
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 400
 
-	// Second attempt at publishing the same deal should fail
-	{
-		rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
-		rt.ExpectSend(provider, builtin.MethodsMiner.ControlAddresses, nil, abi.NewTokenAmount(0), &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
-		expectQueryNetworkInfo(rt, actor)
-		rt.ExpectVerifySignature(crypto.Signature{}, client, mustCbor(&params.Deals[0].Proposal), nil)
+	publishUpfrontDeal := func(rt *mock.Runtime, actor *marketActorTestHarness) abi.DealID {
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
 		rt.SetCaller(worker, builtin.AccountActorCodeID)
-		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
-			rt.Call(actor.PublishStorageDeals, params)
-		})
+		dealIds := actor.publishDealsWithPaymentMode(rt, mAddrs, market.DealPaymentUpfront, publishDealReq{deal: deal})
 
-		rt.Verify()
+		var st market.State
+		rt.GetState(&st)
+		require.True(t, st.TotalClientStorageFee.IsZero())
+		require.Equal(t, deal.TotalStorageFee(), st.TotalClientUpfrontPayments)
+		return dealIds[0]
 	}
 
-	dealProposal.Label = "foo"
+	t.Run("upfront payment is released to the provider when the deal expires", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := publishUpfrontDeal(rt, actor)
+		actor.activateDeals(rt, sectorExpiry, provider, 0, dealId)
+		d := actor.getDealProposal(rt, dealId)
 
-	// Same deal with a different label should work
-	{
-		rt.SetCaller(worker, builtin.AccountActorCodeID)
-		actor.publishDeals(rt, minerAddrs, publishDealReq{deal: dealProposal})
-	}
-	actor.checkState(rt)
-}
+		// the payment mode moves from the pending upfront deal set to the deal state
+		assert.Equal(t, market.DealPaymentUpfront, actor.getDealState(rt, dealId).PaymentMode)
+		var st market.State
+		rt.GetState(&st)
+		summary, msgs := market.CheckStateInvariants(&st, rt.AdtStore(), rt.Balance(), rt.Epoch())
+		assert.True(t, msgs.IsEmpty(), strings.Join(msgs.Messages(), "\n"))
+		assert.Zero(t, summary.UpfrontPaymentDealCount)
 
-func TestMaxDealLabelSize(t *testing.T) {
-	owner := tutil.NewIDAddr(t, 101)
-	provider := tutil.NewIDAddr(t, 102)
-	worker := tutil.NewIDAddr(t, 103)
-	client := tutil.NewIDAddr(t, 104)
-	minerAddrs := &minerAddrs{owner, worker, provider, nil}
+		cEscrow := actor.getEscrowBalance(rt, client)
+		pEscrow := actor.getEscrowBalance(rt, provider)
 
-	var st market.State
+		// no payment is made while the deal is active
+		rt.SetEpoch(processEpoch(t, dealId, startEpoch) + market.DealUpdatesInterval)
+		actor.cronTick(rt)
+		require.EqualValues(t, cEscrow, actor.getEscrowBalance(rt, client))
+		require.EqualValues(t, d.ClientBalanceRequirement(), actor.getLockedBalance(rt, client))
+		require.EqualValues(t, pEscrow, actor.getEscrowBalance(rt, provider))
+		actor.checkState(rt)
 
-	// Test adding provider funds from both worker and owner address
-	rt, actor := basicMarketSetup(t, owner, provider, worker, client)
-	actor.addProviderFunds(rt, abi.NewTokenAmount(20000000), minerAddrs)
-	rt.GetState(&st)
-	assert.Equal(t, abi.NewTokenAmount(20000000), actor.getEscrowBalance(rt, provider))
+		// the full storage fee is paid once the deal expires
+		rt.SetEpoch(endEpoch + market.DealUpdatesInterval)
+		actor.cronTick(rt)
+		require.EqualValues(t, big.Sub(cEscrow, d.TotalStorageFee()), actor.getEscrowBalance(rt, client))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, client))
+		require.EqualValues(t, big.Add(pEscrow, d.TotalStorageFee()), actor.getEscrowBalance(rt, provider))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, provider))
 
-	actor.addParticipantFunds(rt, client, abi.NewTokenAmount(20000000))
+		rt.GetState(&st)
+		require.True(t, st.TotalClientUpfrontPayments.IsZero())
+		actor.assertDealDeleted(rt, dealId, d)
+		actor.checkState(rt)
+	})
 
-	dealProposal := generateDealProposal(client, provider, abi.ChainEpoch(1), abi.ChainEpoch(200*builtin.EpochsInDay))
-	dealProposal.Label = string(make([]byte, market.DealMaxLabelSize))
-	params := &market.PublishStorageDealsParams{Deals: []market.ClientDealProposal{{Proposal: dealProposal}}}
+	t.Run("upfront payment is pro-rated between provider and client when the deal is slashed", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := publishUpfrontDeal(rt, actor)
+		actor.activateDeals(rt, sectorExpiry, provider, 0, dealId)
+		d := actor.getDealProposal(rt, dealId)
 
-	// Label at max size should work.
-	{
+		cEscrow := actor.getEscrowBalance(rt, client)
+		pEscrow := actor.getEscrowBalance(rt, provider)
+
+		// terminate 100 epochs after the deal start
+		slashEpoch := startEpoch + 100
+		rt.SetEpoch(slashEpoch)
+		actor.terminateDeals(rt, provider, dealId)
+
+		earned := big.Mul(big.NewInt(int64(slashEpoch-startEpoch)), d.StoragePricePerEpoch)
+		rt.SetEpoch(processEpoch(t, dealId, startEpoch) + market.DealUpdatesInterval)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+
+		require.EqualValues(t, big.Sub(cEscrow, earned), actor.getEscrowBalance(rt, client))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, client))
+		require.EqualValues(t, big.Sub(big.Add(pEscrow, earned), d.ProviderCollateral), actor.getEscrowBalance(rt, provider))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, provider))
+
+		var st market.State
+		rt.GetState(&st)
+		require.True(t, st.TotalClientUpfrontPayments.IsZero())
+		actor.assertDealDeleted(rt, dealId, d)
+		actor.checkState(rt)
+	})
+
+	t.Run("timed out upfront payment deal refunds client", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := publishUpfrontDeal(rt, actor)
+		d := actor.getDealProposal(rt, dealId)
+
+		cEscrow := actor.getEscrowBalance(rt, client)
+
+		rt.SetEpoch(processEpoch(t, dealId, startEpoch))
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+
+		require.Equal(t, cEscrow, actor.getEscrowBalance(rt, client))
+		require.Equal(t, big.Zero(), actor.getLockedBalance(rt, client))
+		actor.assertAccountZero(rt, provider)
+		actor.assertDealDeleted(rt, dealId, d)
+		actor.checkState(rt)
+	})
+
+	t.Run("fail with invalid payment mode", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
 		rt.SetCaller(worker, builtin.AccountActorCodeID)
-		actor.publishDeals(rt, minerAddrs, publishDealReq{deal: dealProposal})
+
+		params := &market.PublishStorageDealsWithPaymentModeParams{
+			Deals:       []market.ClientDealProposal{{Proposal: deal}},
+			PaymentMode: market.DealPaymentUpfront + 1,
+		}
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "invalid deal payment mode", func() {
+			rt.Call(actor.PublishStorageDealsWithPaymentMode, params)
+		})
+		rt.Verify()
+		actor.checkState(rt)
+	})
+}
+
+func TestProviderPaidDeals(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 400
+
+	generateProviderPaidDeal := func(rt *mock.Runtime, actor *marketActorTestHarness) market.DealProposal {
+		deal := generateDealProposal(client, provider, startEpoch, endEpoch)
+		deal.StoragePricePerEpoch = big.NewInt(-10)
+		actor.addProviderFunds(rt, deal.ProviderBalanceRequirement(), mAddrs)
+		actor.addParticipantFunds(rt, client, deal.ClientBalanceRequirement())
+		return deal
 	}
 
-	dealProposal.Label = string(make([]byte, market.DealMaxLabelSize+1))
+	publishProviderPaidDeal := func(rt *mock.Runtime, actor *marketActorTestHarness) abi.DealID {
+		deal := generateProviderPaidDeal(rt, actor)
+		require.True(t, deal.ProviderPaysStorageFee())
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		dealIds := actor.publishDeals(rt, mAddrs, publishDealReq{deal: deal})
 
-	// Label greater than max size should fail.
-	{
-		rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
-		rt.ExpectSend(provider, builtin.MethodsMiner.ControlAddresses, nil, abi.NewTokenAmount(0), &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
+		// the provider locks the storage fee, the client only its collateral
+		require.EqualValues(t, deal.ClientCollateral, actor.getLockedBalance(rt, client))
+		require.EqualValues(t, big.Sub(deal.ProviderCollateral, deal.TotalStorageFee()), actor.getLockedBalance(rt, provider))
+		var st market.State
+		rt.GetState(&st)
+		require.True(t, st.TotalClientStorageFee.IsZero())
+		require.Equal(t, deal.TotalStorageFee().Neg(), st.TotalProviderStorageFee)
+		return dealIds[0]
+	}
+
+	t.Run("provider pays the client per epoch until the deal expires", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := publishProviderPaidDeal(rt, actor)
+		actor.activateDeals(rt, sectorExpiry, provider, 0, dealId)
+		d := actor.getDealProposal(rt, dealId)
+
+		cEscrow := actor.getEscrowBalance(rt, client)
+		pEscrow := actor.getEscrowBalance(rt, provider)
+
+		current := rt.SetEpoch(processEpoch(t, dealId, startEpoch))
+		actor.cronTick(rt)
+		paid := big.Mul(big.NewInt(int64(current-startEpoch)), d.StoragePricePerEpoch.Neg())
+		require.EqualValues(t, big.Add(cEscrow, paid), actor.getEscrowBalance(rt, client))
+		require.EqualValues(t, big.Sub(pEscrow, paid), actor.getEscrowBalance(rt, provider))
+		require.EqualValues(t, paid.Neg(), actor.getDealStatus(rt, dealId).AmountPaid)
+		actor.checkState(rt)
+
+		// the full fee is paid once the deal expires, and the collateral unlocked
+		rt.SetEpoch(endEpoch + market.DealUpdatesInterval)
+		actor.cronTick(rt)
+		require.EqualValues(t, big.Sub(cEscrow, d.TotalStorageFee()), actor.getEscrowBalance(rt, client))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, client))
+		require.EqualValues(t, big.Add(pEscrow, d.TotalStorageFee()), actor.getEscrowBalance(rt, provider))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, provider))
+
+		var st market.State
+		rt.GetState(&st)
+		require.True(t, st.TotalProviderStorageFee.IsZero())
+		actor.assertDealDeleted(rt, dealId, d)
+		actor.checkState(rt)
+	})
+
+	t.Run("remaining fee is returned to the provider when the deal is slashed", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := publishProviderPaidDeal(rt, actor)
+		actor.activateDeals(rt, sectorExpiry, provider, 0, dealId)
+		d := actor.getDealProposal(rt, dealId)
+
+		cEscrow := actor.getEscrowBalance(rt, client)
+		pEscrow := actor.getEscrowBalance(rt, provider)
+
+		slashEpoch := rt.SetEpoch(startEpoch + 100)
+		actor.terminateDeals(rt, provider, dealId)
+
+		paid := big.Mul(big.NewInt(int64(slashEpoch-startEpoch)), d.StoragePricePerEpoch.Neg())
+		rt.SetEpoch(processEpoch(t, dealId, startEpoch) + market.DealUpdatesInterval)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+
+		require.EqualValues(t, big.Add(cEscrow, paid), actor.getEscrowBalance(rt, client))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, client))
+		require.EqualValues(t, big.Sub(big.Sub(pEscrow, paid), d.ProviderCollateral), actor.getEscrowBalance(rt, provider))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, provider))
+		actor.assertDealDeleted(rt, dealId, d)
+		actor.checkState(rt)
+	})
+
+	t.Run("timed out deal unlocks the provider's fee", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := publishProviderPaidDeal(rt, actor)
+		d := actor.getDealProposal(rt, dealId)
+		pEscrow := actor.getEscrowBalance(rt, provider)
+
+		rt.SetEpoch(processEpoch(t, dealId, startEpoch))
+		slashed := market.CollateralPenaltyForDealActivationMissed(d.ProviderCollateral)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, slashed, nil, exitcode.Ok)
+		actor.cronTick(rt)
+
+		require.EqualValues(t, big.Sub(pEscrow, slashed), actor.getEscrowBalance(rt, provider))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, provider))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, client))
+		actor.assertDealDeleted(rt, dealId, d)
+		actor.checkState(rt)
+	})
+
+	t.Run("zero price deal pays nothing", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := generateDealProposal(client, provider, startEpoch, endEpoch)
+		deal.StoragePricePerEpoch = big.Zero()
+		actor.addProviderFunds(rt, deal.ProviderBalanceRequirement(), mAddrs)
+		actor.addParticipantFunds(rt, client, deal.ClientBalanceRequirement())
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		dealId := actor.publishDeals(rt, mAddrs, publishDealReq{deal: deal})[0]
+		actor.activateDeals(rt, sectorExpiry, provider, 0, dealId)
+
+		cEscrow := actor.getEscrowBalance(rt, client)
+		pEscrow := actor.getEscrowBalance(rt, provider)
+		rt.SetEpoch(endEpoch + market.DealUpdatesInterval)
+		actor.cronTick(rt)
+		require.EqualValues(t, cEscrow, actor.getEscrowBalance(rt, client))
+		require.EqualValues(t, pEscrow, actor.getEscrowBalance(rt, provider))
+		actor.checkState(rt)
+	})
+
+	t.Run("fails if the provider cannot lock the fee", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := generateDealProposal(client, provider, startEpoch, endEpoch)
+		deal.StoragePricePerEpoch = big.NewInt(-10)
+		actor.addProviderFunds(rt, deal.ProviderCollateral, mAddrs)
+		actor.addParticipantFunds(rt, client, deal.ClientBalanceRequirement())
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+
+		params := mkPublishStorageParams(deal)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectGetControlAddresses(rt, provider, owner, worker)
 		expectQueryNetworkInfo(rt, actor)
 		rt.ExpectVerifySignature(crypto.Signature{}, client, mustCbor(&params.Deals[0].Proposal), nil)
-		rt.SetCaller(worker, builtin.AccountActorCodeID)
-		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+		rt.ExpectAbortContainsMessage(exitcode.ErrInsufficientFunds, "failed to lock provider funds", func() {
 			rt.Call(actor.PublishStorageDeals, params)
 		})
+		actor.checkState(rt)
+	})
+
+	t.Run("provider's fee for earlier deals in a batch is accounted for", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal1 := generateDealProposal(client, provider, startEpoch, endEpoch)
+		deal1.StoragePricePerEpoch = big.NewInt(-10)
+		deal2 := generateDealProposal(client, provider, startEpoch, endEpoch+1)
+		deal2.StoragePricePerEpoch = big.NewInt(-10)
+		// the provider can afford the fee for either deal, but not both
+		actor.addProviderFunds(rt, big.Add(deal2.ProviderBalanceRequirement(), deal1.ProviderCollateral), mAddrs)
+		actor.addParticipantFunds(rt, client, big.Add(deal1.ClientBalanceRequirement(), deal2.ClientBalanceRequirement()))
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
 
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectGetControlAddresses(rt, provider, owner, worker)
+		expectQueryNetworkInfo(rt, actor)
+		rt.ExpectVerifySignature(crypto.Signature{}, client, mustCbor(&deal1), nil)
+		rt.ExpectVerifySignature(crypto.Signature{}, client, mustCbor(&deal2), nil)
+		ret := rt.Call(actor.PublishStorageDeals, mkPublishStorageParams(deal1, deal2)).(*market.PublishStorageDealsReturn)
 		rt.Verify()
-	}
-	actor.checkState(rt)
+
+		assert.Equal(t, []exitcode.ExitCode{exitcode.Ok, exitcode.ErrInsufficientFunds}, ret.FailCodes)
+		assert.EqualValues(t, deal1.ProviderBalanceRequirement(), actor.getLockedBalance(rt, provider))
+		actor.checkState(rt)
+	})
+
+	t.Run("fails to pay a provider paid deal upfront", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := generateProviderPaidDeal(rt, actor)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+
+		params := &market.PublishStorageDealsWithPaymentModeParams{
+			Deals:       []market.ClientDealProposal{{Proposal: deal}},
+			PaymentMode: market.DealPaymentUpfront,
+		}
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectGetControlAddresses(rt, provider, owner, worker)
+		expectQueryNetworkInfo(rt, actor)
+		rt.ExpectVerifySignature(crypto.Signature{}, client, mustCbor(&deal), nil)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "deal paid by the provider cannot be paid upfront", func() {
+			rt.Call(actor.PublishStorageDealsWithPaymentMode, params)
+		})
+		actor.checkState(rt)
+	})
 }
 
-func TestComputeDataCommitment(t *testing.T) {
+func TestDerivedDealIDs(t *testing.T) {
 	owner := tutil.NewIDAddr(t, 101)
 	provider := tutil.NewIDAddr(t, 102)
 	worker := tutil.NewIDAddr(t, 103)
 	client := tutil.NewIDAddr(t, 104)
 	mAddrs := &minerAddrs{owner, worker, provider, nil}
-	start := abi.ChainEpoch(10)
-	end := start + 200*builtin.EpochsInDay
 
-	t.Run("successfully compute cid", func(t *testing.T) {
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 400
+
+	t.Run("deal id is derived from the proposal cid", func(t *testing.T) {
 		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
-		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end)
-		d1 := actor.getDealProposal(rt, dealId1)
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		pcid, err := deal.Cid()
+		require.NoError(t, err)
+		expectedID, err := market.DerivedDealID(pcid)
+		require.NoError(t, err)
+		assert.True(t, market.IsDerivedDealID(expectedID))
 
-		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end+1)
-		d2 := actor.getDealProposal(rt, dealId2)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		dealIDs := actor.publishDealsWithDerivedIDs(rt, mAddrs, deal)
+		assert.Equal(t, []abi.DealID{expectedID}, dealIDs)
 
-		param := &market.ComputeDataCommitmentParams{}
-		param.Inputs = []*market.SectorDataSpec{{DealIDs: []abi.DealID{dealId1, dealId2}, SectorType: 1}}
+		// Sequential ids are unaffected.
+		other := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch+1)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		otherIDs := actor.publishDeals(rt, mAddrs, publishDealReq{deal: other})
+		assert.Equal(t, []abi.DealID{0}, otherIDs)
+		assert.False(t, market.IsDerivedDealID(otherIDs[0]))
 
-		p1 := abi.PieceInfo{Size: d1.PieceSize, PieceCID: d1.PieceCID}
-		p2 := abi.PieceInfo{Size: d2.PieceSize, PieceCID: d2.PieceCID}
+		// The deal proceeds as any other.
+		actor.activateDeals(rt, sectorExpiry, provider, 0, expectedID, otherIDs[0])
+		rt.SetEpoch(processEpoch(t, expectedID, startEpoch) + market.DealUpdatesInterval)
+		actor.cronTick(rt)
+		actor.checkState(rt)
+	})
 
-		c := tutil.MakeCID("100", &market.PieceCIDPrefix)
+	t.Run("republishing a deal is idempotent", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		deal.VerifiedDeal = true
 
-		rt.ExpectComputeUnsealedSectorCID(1, []abi.PieceInfo{p1, p2}, c, nil)
-		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
-		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		dealIDs := actor.publishDealsWithDerivedIDs(rt, mAddrs, deal)
+		clientLocked := actor.getLockedBalance(rt, client)
+		providerLocked := actor.getLockedBalance(rt, provider)
+
+		// The verified client's data cap is not used again.
+		republished := actor.publishDealsWithDerivedIDs(rt, mAddrs, deal)
+		assert.Equal(t, dealIDs, republished)
+		assert.Equal(t, clientLocked, actor.getLockedBalance(rt, client))
+		assert.Equal(t, providerLocked, actor.getLockedBalance(rt, provider))
+		actor.checkState(rt)
+	})
+
+	t.Run("cannot republish a sequentially published deal", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		actor.publishDeals(rt, mAddrs, publishDealReq{deal: deal})
+
+		actor.addProviderFunds(rt, deal.ProviderCollateral, mAddrs)
+		actor.addParticipantFunds(rt, client, deal.ClientBalanceRequirement())
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "cannot publish duplicate deals", func() {
+			actor.publishDealsWithDerivedIDs(rt, mAddrs, deal)
+		})
+		actor.checkState(rt)
+	})
+}
+
+func TestDealOffers(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 400
+
+	t.Run("accepted offer locks provider collateral and can be activated", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		dealId := actor.offerDeals(rt, client, deal)[0]
+
+		// only the client's funds are locked for an offer
+		require.EqualValues(t, deal.ClientBalanceRequirement(), actor.getLockedBalance(rt, client))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, provider))
+		actor.checkState(rt)
+
+		actor.acceptDealOffers(rt, mAddrs, dealId)
+		require.EqualValues(t, deal.ProviderCollateral, actor.getLockedBalance(rt, provider))
+		assert.Equal(t, deal, *actor.getDealProposal(rt, dealId))
+
+		var st market.State
+		rt.GetState(&st)
+		summary, msgs := market.CheckStateInvariants(&st, rt.AdtStore(), rt.Balance(), rt.Epoch())
+		assert.True(t, msgs.IsEmpty(), strings.Join(msgs.Messages(), "\n"))
+		assert.Zero(t, summary.DealOfferCount)
+		assert.Equal(t, uint64(1), summary.PendingProposalCount)
+
+		actor.activateDeals(rt, sectorExpiry, provider, 0, dealId)
+		actor.checkState(rt)
+	})
+
+	t.Run("offer not accepted by its start epoch is refunded", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		dealId := actor.offerDeals(rt, client, deal)[0]
+		cEscrow := actor.getEscrowBalance(rt, client)
+		pEscrow := actor.getEscrowBalance(rt, provider)
+
+		// the provider is not penalized
+		rt.SetEpoch(processEpoch(t, dealId, startEpoch))
+		actor.cronTick(rt)
+
+		require.EqualValues(t, cEscrow, actor.getEscrowBalance(rt, client))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, client))
+		require.EqualValues(t, pEscrow, actor.getEscrowBalance(rt, provider))
+
+		var st market.State
+		rt.GetState(&st)
+		summary, msgs := market.CheckStateInvariants(&st, rt.AdtStore(), rt.Balance(), rt.Epoch())
+		assert.True(t, msgs.IsEmpty(), strings.Join(msgs.Messages(), "\n"))
+		assert.Zero(t, summary.DealOfferCount)
+		assert.Zero(t, summary.PendingProposalCount)
+		assert.Zero(t, summary.DealOpCount)
+	})
+
+	t.Run("unaccepted verified offer restores the client's data cap", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		deal.VerifiedDeal = true
+		dealId := actor.offerDeals(rt, client, deal)[0]
+
+		rt.SetEpoch(processEpoch(t, dealId, startEpoch))
+		param := &verifreg.RestoreBytesParams{
+			Address:  client,
+			DealSize: big.NewIntUnsigned(uint64(deal.PieceSize)),
+		}
+		rt.ExpectSend(builtin.VerifiedRegistryActorAddr, builtin.MethodsVerifiedRegistry.RestoreBytes, param, abi.NewTokenAmount(0), nil, exitcode.Ok)
+		actor.cronTick(rt)
+
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, client))
+		actor.checkState(rt)
+	})
+
+	t.Run("fail when caller is not the client", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectQueryNetworkInfo(rt, actor)
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "is not the client", func() {
+			rt.Call(actor.OfferStorageDeals, &market.OfferStorageDealsParams{Deals: []market.DealProposal{deal}})
+		})
+		rt.Verify()
+		actor.checkState(rt)
+	})
+
+	t.Run("fail when client has insufficient funds", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := generateDealProposal(client, provider, startEpoch, endEpoch)
+		actor.addParticipantFunds(rt, client, big.Sub(deal.ClientBalanceRequirement(), big.NewInt(1)))
+
+		rt.SetCaller(client, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectQueryNetworkInfo(rt, actor)
+		rt.ExpectAbortContainsMessage(exitcode.ErrInsufficientFunds, "insufficient balance", func() {
+			rt.Call(actor.OfferStorageDeals, &market.OfferStorageDealsParams{Deals: []market.DealProposal{deal}})
+		})
+		rt.Verify()
+		actor.checkState(rt)
+	})
+
+	t.Run("fail to accept when caller is not a provider control address", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		dealId := actor.offerDeals(rt, client, deal)[0]
+
+		rt.SetCaller(client, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectGetControlAddresses(rt, provider, owner, worker)
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "is not worker or control address", func() {
+			rt.Call(actor.AcceptStorageDealOffers, &market.AcceptStorageDealOffersParams{DealIDs: []abi.DealID{dealId}})
+		})
+		rt.Verify()
+		actor.checkState(rt)
+	})
+
+	t.Run("fail to accept a deal that was not offered", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrNotFound, "no offer for deal", func() {
+			rt.Call(actor.AcceptStorageDealOffers, &market.AcceptStorageDealOffersParams{DealIDs: []abi.DealID{dealId}})
+		})
+		rt.Verify()
+		actor.checkState(rt)
+	})
+
+	t.Run("fail to accept after the deal start epoch", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		dealId := actor.offerDeals(rt, client, deal)[0]
+
+		rt.SetEpoch(startEpoch + 1)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectGetControlAddresses(rt, provider, owner, worker)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "has already elapsed", func() {
+			rt.Call(actor.AcceptStorageDealOffers, &market.AcceptStorageDealOffersParams{DealIDs: []abi.DealID{dealId}})
+		})
+		rt.Verify()
+	})
+}
+
+func TestAuthorizeDealProposal(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	msig := tutil.NewIDAddr(t, 105)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+
+	setup := func(t *testing.T) (*mock.Runtime, *marketActorTestHarness, market.DealProposal) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		rt.SetAddressActorType(msig, builtin.MultisigActorCodeID)
+		deal := generateDealProposal(msig, provider, startEpoch, endEpoch)
+		actor.addProviderFunds(rt, deal.ProviderBalanceRequirement(), mAddrs)
+		actor.addParticipantFunds(rt, msig, deal.ClientBalanceRequirement())
+		return rt, actor, deal
+	}
+
+	authorizedCount := func(rt *mock.Runtime) uint64 {
+		var st market.State
+		rt.GetState(&st)
+		summary, _ := market.CheckStateInvariants(&st, rt.AdtStore(), rt.Balance(), rt.Epoch())
+		return summary.AuthorizedProposalCount
+	}
+
+	expectPublish := func(rt *mock.Runtime, actor *marketActorTestHarness) {
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectGetControlAddresses(rt, provider, owner, worker)
+		expectQueryNetworkInfo(rt, actor)
+	}
+
+	t.Run("authorized proposal is published without a client signature", func(t *testing.T) {
+		rt, actor, deal := setup(t)
+		actor.authorizeDealProposal(rt, msig, deal)
+		assert.Equal(t, uint64(1), authorizedCount(rt))
+
+		expectPublish(rt, actor)
+		ret := rt.Call(actor.PublishStorageDeals, mkPublishStorageParams(deal)).(*market.PublishStorageDealsReturn)
+		rt.Verify()
+		require.Len(t, ret.IDs, 1)
+		assert.Equal(t, deal, *actor.getDealProposal(rt, ret.IDs[0]))
+
+		// the authorization is consumed
+		assert.Zero(t, authorizedCount(rt))
+		actor.checkState(rt)
+	})
+
+	t.Run("unauthorized proposal requires a client signature", func(t *testing.T) {
+		rt, actor, deal := setup(t)
+
+		expectPublish(rt, actor)
+		rt.ExpectVerifySignature(crypto.Signature{}, msig, mustCbor(&deal), errors.New("no key"))
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "signature proposal invalid", func() {
+			rt.Call(actor.PublishStorageDeals, mkPublishStorageParams(deal))
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("authorization is for the exact proposal", func(t *testing.T) {
+		rt, actor, deal := setup(t)
+		actor.authorizeDealProposal(rt, msig, deal)
+
+		other := deal
+		other.StoragePricePerEpoch = big.Sub(deal.StoragePricePerEpoch, big.NewInt(1))
+		expectPublish(rt, actor)
+		rt.ExpectVerifySignature(crypto.Signature{}, msig, mustCbor(&other), errors.New("no key"))
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "signature proposal invalid", func() {
+			rt.Call(actor.PublishStorageDeals, mkPublishStorageParams(other))
+		})
+		assert.Equal(t, uint64(1), authorizedCount(rt))
+		actor.checkState(rt)
+	})
+
+	t.Run("fails if caller is not the client", func(t *testing.T) {
+		rt, actor, deal := setup(t)
+
+		rt.SetCaller(client, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "is not the client", func() {
+			rt.Call(actor.AuthorizeDealProposal, &market.AuthorizeDealProposalParams{Proposal: deal})
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fails if the proposal start epoch has elapsed", func(t *testing.T) {
+		rt, actor, deal := setup(t)
+		rt.SetEpoch(startEpoch + 1)
+
+		rt.SetCaller(msig, builtin.MultisigActorCodeID)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "has already elapsed", func() {
+			rt.Call(actor.AuthorizeDealProposal, &market.AuthorizeDealProposalParams{Proposal: deal})
+		})
+		actor.checkState(rt)
+	})
+}
+
+func TestMarketActorDeals(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	minerAddrs := &minerAddrs{owner, worker, provider, nil}
+
+	var st market.State
+
+	// Test adding provider funds from both worker and owner address
+	rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+	actor.addProviderFunds(rt, abi.NewTokenAmount(20000000), minerAddrs)
+	rt.GetState(&st)
+	assert.Equal(t, abi.NewTokenAmount(20000000), actor.getEscrowBalance(rt, provider))
+
+	actor.addParticipantFunds(rt, client, abi.NewTokenAmount(20000000))
+
+	dealProposal := generateDealProposal(client, provider, abi.ChainEpoch(1), abi.ChainEpoch(200*builtin.EpochsInDay))
+	params := &market.PublishStorageDealsParams{Deals: []market.ClientDealProposal{{Proposal: dealProposal}}}
+
+	// First attempt at publishing the deal should work
+	{
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		actor.publishDeals(rt, minerAddrs, publishDealReq{deal: dealProposal})
+	}
+
+	// Second attempt at publishing the same deal should fail
+	{
+		rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
+		rt.ExpectSend(provider, builtin.MethodsMiner.ControlAddresses, nil, abi.NewTokenAmount(0), &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
+		expectQueryNetworkInfo(rt, actor)
+		rt.ExpectVerifySignature(crypto.Signature{}, client, mustCbor(&params.Deals[0].Proposal), nil)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(actor.PublishStorageDeals, params)
+		})
+
+		rt.Verify()
+	}
+
+	dealProposal.Label = mustLabel("foo")
+
+	// Same deal with a different label should work
+	{
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		actor.publishDeals(rt, minerAddrs, publishDealReq{deal: dealProposal})
+	}
+	actor.checkState(rt)
+}
+
+func TestMaxDealLabelSize(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	minerAddrs := &minerAddrs{owner, worker, provider, nil}
+
+	var st market.State
+
+	// Test adding provider funds from both worker and owner address
+	rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+	actor.addProviderFunds(rt, abi.NewTokenAmount(20000000), minerAddrs)
+	rt.GetState(&st)
+	assert.Equal(t, abi.NewTokenAmount(20000000), actor.getEscrowBalance(rt, provider))
+
+	actor.addParticipantFunds(rt, client, abi.NewTokenAmount(20000000))
+
+	dealProposal := generateDealProposal(client, provider, abi.ChainEpoch(1), abi.ChainEpoch(200*builtin.EpochsInDay))
+	dealProposal.Label = market.NewLabelFromBytes(make([]byte, market.DealMaxLabelSize))
+	params := &market.PublishStorageDealsParams{Deals: []market.ClientDealProposal{{Proposal: dealProposal}}}
+
+	// Label at max size should work.
+	{
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		actor.publishDeals(rt, minerAddrs, publishDealReq{deal: dealProposal})
+	}
+
+	dealProposal.Label = market.NewLabelFromBytes(make([]byte, market.DealMaxLabelSize+1))
+
+	// Label greater than max size should fail.
+	{
+		rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
+		rt.ExpectSend(provider, builtin.MethodsMiner.ControlAddresses, nil, abi.NewTokenAmount(0), &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
+		expectQueryNetworkInfo(rt, actor)
+		rt.ExpectVerifySignature(crypto.Signature{}, client, mustCbor(&params.Deals[0].Proposal), nil)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(actor.PublishStorageDeals, params)
+		})
+
+		rt.Verify()
+	}
+	actor.checkState(rt)
+}
+
+func TestDealLabel(t *testing.T) {
+	t.Run("string and bytes labels serialize to text and byte strings", func(t *testing.T) {
+		strLabel := mustLabel("foo")
+		assert.Equal(t, []byte{0x63, 'f', 'o', 'o'}, mustCbor(&strLabel))
+		bytesLabel := market.NewLabelFromBytes([]byte("foo"))
+		assert.Equal(t, []byte{0x43, 'f', 'o', 'o'}, mustCbor(&bytesLabel))
+
+		for _, label := range []market.DealLabel{market.EmptyDealLabel, strLabel, bytesLabel, market.NewLabelFromBytes([]byte{0xff})} {
+			var decoded market.DealLabel
+			require.NoError(t, decoded.UnmarshalCBOR(bytes.NewReader(mustCbor(&label))))
+			assert.Equal(t, label, decoded)
+		}
+
+		s, err := strLabel.ToString()
+		require.NoError(t, err)
+		assert.Equal(t, "foo", s)
+		_, err = bytesLabel.ToString()
+		assert.Error(t, err)
+		assert.Equal(t, []byte("foo"), bytesLabel.ToBytes())
+	})
+
+	t.Run("rejects a string that is not valid UTF-8", func(t *testing.T) {
+		_, err := market.NewLabelFromString(string([]byte{0xff}))
+		assert.Error(t, err)
+
+		var decoded market.DealLabel
+		err = decoded.UnmarshalCBOR(bytes.NewReader([]byte{0x61, 0xff}))
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects an oversized or non-string label when decoding", func(t *testing.T) {
+		oversized := market.NewLabelFromBytes(make([]byte, market.DealMaxLabelSize+1))
+		var decoded market.DealLabel
+		assert.Error(t, decoded.UnmarshalCBOR(bytes.NewReader(mustCbor(&oversized))))
+		assert.Error(t, decoded.UnmarshalCBOR(bytes.NewReader([]byte{0x01})))
+	})
+
+	t.Run("JSON distinguishes string and bytes labels", func(t *testing.T) {
+		for _, label := range []market.DealLabel{mustLabel("foo"), market.NewLabelFromBytes([]byte{0xff, 0x00})} {
+			encoded, err := json.Marshal(label)
+			require.NoError(t, err)
+			var decoded market.DealLabel
+			require.NoError(t, json.Unmarshal(encoded, &decoded))
+			assert.Equal(t, label, decoded)
+		}
+		encoded, err := json.Marshal(mustLabel("foo"))
+		require.NoError(t, err)
+		assert.Equal(t, `"foo"`, string(encoded))
+	})
+}
+
+func TestComputeDataCommitment(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+	start := abi.ChainEpoch(10)
+	end := start + 200*builtin.EpochsInDay
+
+	t.Run("successfully compute cid", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end)
+		d1 := actor.getDealProposal(rt, dealId1)
+
+		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end+1)
+		d2 := actor.getDealProposal(rt, dealId2)
+
+		param := &market.ComputeDataCommitmentParams{}
+		param.Inputs = []*market.SectorDataSpec{{DealIDs: []abi.DealID{dealId1, dealId2}, SectorType: 1}}
+
+		p1 := abi.PieceInfo{Size: d1.PieceSize, PieceCID: d1.PieceCID}
+		p2 := abi.PieceInfo{Size: d2.PieceSize, PieceCID: d2.PieceCID}
+
+		c := tutil.MakeCID("100", &market.PieceCIDPrefix)
+
+		rt.ExpectComputeUnsealedSectorCID(1, []abi.PieceInfo{p1, p2}, c, nil)
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+
+		ret := rt.Call(actor.ComputeDataCommitment, param)
+		val, ok := ret.(*market.ComputeDataCommitmentReturn)
+		require.True(t, ok)
+		require.Equal(t, c, (cid.Cid)(val.CommDs[0]))
+		rt.Verify()
+		actor.checkState(rt)
+	})
+
+	t.Run("success on empty piece info", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		param := &market.ComputeDataCommitmentParams{}
+		param.Inputs = []*market.SectorDataSpec{{DealIDs: nil, SectorType: 1}}
+
+		c := tutil.MakeCID("UnsealedEmpty", &market.PieceCIDPrefix)
+		rt.ExpectComputeUnsealedSectorCID(1, []abi.PieceInfo{}, c, nil)
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		ret := rt.Call(actor.ComputeDataCommitment, param)
+		val, ok := ret.(*market.ComputeDataCommitmentReturn)
+		require.True(t, ok)
+		require.Equal(t, c, (cid.Cid)(val.CommDs[0]))
+		rt.Verify()
+		actor.checkState(rt)
+	})
+
+	t.Run("success with multiple sector commitments", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end)
+		d1 := actor.getDealProposal(rt, dealId1)
+
+		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end+1)
+		d2 := actor.getDealProposal(rt, dealId2)
+
+		param := &market.ComputeDataCommitmentParams{}
+		param.Inputs = []*market.SectorDataSpec{
+			{DealIDs: nil, SectorType: 1},
+			{DealIDs: []abi.DealID{dealId1, dealId2}, SectorType: 1},
+		}
+
+		p1 := abi.PieceInfo{Size: d1.PieceSize, PieceCID: d1.PieceCID}
+		p2 := abi.PieceInfo{Size: d2.PieceSize, PieceCID: d2.PieceCID}
+
+		c1 := tutil.MakeCID("UnsealedSector1", &market.PieceCIDPrefix)
+		c2 := tutil.MakeCID("UnsealedSector2", &market.PieceCIDPrefix)
+
+		rt.ExpectComputeUnsealedSectorCID(1, []abi.PieceInfo{}, c1, nil)
+		rt.ExpectComputeUnsealedSectorCID(1, []abi.PieceInfo{p1, p2}, c2, nil)
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+
+		ret := rt.Call(actor.ComputeDataCommitment, param)
+		val, ok := ret.(*market.ComputeDataCommitmentReturn)
+		require.True(t, ok)
+		require.Equal(t, c1, (cid.Cid)(val.CommDs[0]))
+		require.Equal(t, c2, (cid.Cid)(val.CommDs[1]))
+		rt.Verify()
+		actor.checkState(rt)
+	})
+
+	t.Run("batch limit accommodates the largest aggregated prove-commitment", func(t *testing.T) {
+		assert.LessOrEqual(t, miner.MaxAggregatedSectors, market.ComputeDataCommitmentMaxInputs)
+	})
+
+	t.Run("fail when batch is too large", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+
+		param := &market.ComputeDataCommitmentParams{}
+		for i := 0; i <= market.ComputeDataCommitmentMaxInputs; i++ {
+			param.Inputs = append(param.Inputs, &market.SectorDataSpec{DealIDs: nil, SectorType: 1})
+		}
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "too large", func() {
+			rt.Call(actor.ComputeDataCommitment, param)
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fail when deal proposal is absent", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+
+		param := &market.ComputeDataCommitmentParams{}
+		param.Inputs = []*market.SectorDataSpec{{DealIDs: []abi.DealID{1}, SectorType: 1}}
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			rt.Call(actor.ComputeDataCommitment, param)
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fail when syscall returns an error", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, start, end)
+		d := actor.getDealProposal(rt, dealId)
+		param := &market.ComputeDataCommitmentParams{}
+		param.Inputs = []*market.SectorDataSpec{{DealIDs: []abi.DealID{dealId}, SectorType: 1}}
+
+		pi := abi.PieceInfo{Size: d.PieceSize, PieceCID: d.PieceCID}
+
+		rt.ExpectComputeUnsealedSectorCID(1, []abi.PieceInfo{pi}, cid.Cid{}, errors.New("error"))
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(actor.ComputeDataCommitment, param)
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fail whole call when one deal proposal of one sector is absent", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end)
+		dealId2 := abi.DealID(2)
+
+		param := &market.ComputeDataCommitmentParams{}
+		param.Inputs = []*market.SectorDataSpec{
+			{DealIDs: nil, SectorType: 1},
+			{DealIDs: []abi.DealID{dealId1, dealId2}, SectorType: 1},
+		}
+		c1 := tutil.MakeCID("UnsealedSector1", &market.PieceCIDPrefix)
+		rt.ExpectComputeUnsealedSectorCID(1, []abi.PieceInfo{}, c1, nil) // first sector is computed
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			rt.Call(actor.ComputeDataCommitment, param)
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fail whole call when one commitment fails syscall", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end)
+		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end+1)
+
+		param := &market.ComputeDataCommitmentParams{}
+		param.Inputs = []*market.SectorDataSpec{
+			{DealIDs: nil, SectorType: 1},
+			{DealIDs: []abi.DealID{dealId1, dealId2}, SectorType: 1},
+		}
+		rt.ExpectComputeUnsealedSectorCID(1, []abi.PieceInfo{}, cid.Cid{}, errors.New("error"))
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(actor.ComputeDataCommitment, param)
+		})
+		actor.checkState(rt)
+	})
+
+}
+
+func TestDealEndEpochs(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+	start := abi.ChainEpoch(10)
+	end := start + 200*builtin.EpochsInDay
+
+	t.Run("returns end epochs in requested order", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end)
+		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end+1)
+
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerAny()
+		ret := rt.Call(actor.DealEndEpochs, &market.DealEndEpochsParams{DealIDs: []abi.DealID{dealId2, dealId1}}).(*market.DealEndEpochsReturn)
+		rt.Verify()
+		assert.Equal(t, []market.DealEndEpoch{
+			{DealID: dealId2, EndEpoch: end + 1},
+			{DealID: dealId1, EndEpoch: end},
+		}, ret.Deals)
+		actor.checkState(rt)
+	})
+
+	t.Run("fails for unknown deal", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, start, end)
+
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrNotFound, "no such deal", func() {
+			rt.Call(actor.DealEndEpochs, &market.DealEndEpochsParams{DealIDs: []abi.DealID{dealId, dealId + 1}})
+		})
+		actor.checkState(rt)
+	})
+}
+
+func TestDealSettlements(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 1
+
+	t.Run("reports cumulative payments as settled", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+		d := actor.getDealProposal(rt, dealId)
+
+		// nothing is settled before the deal is activated and processed
+		assert.Equal(t, market.DealSettlement{
+			DealID:           dealId,
+			PaymentMode:      market.DealPaymentPerEpoch,
+			LastSettledEpoch: -1,
+			AmountSettled:    big.Zero(),
+		}, actor.dealSettlements(rt, dealId)[0])
+
+		rt.SetEpoch(startEpoch - 1)
+		actor.activateDeals(rt, sectorExpiry, provider, d.StartEpoch-1, dealId)
+
+		processEpoch := processEpoch(t, dealId, startEpoch)
+		rt.SetEpoch(processEpoch)
+		pay, _ := actor.cronTickAndAssertBalances(rt, client, provider, processEpoch, dealId)
+		current := rt.SetEpoch(processEpoch + market.DealUpdatesInterval)
+		pay2, _ := actor.cronTickAndAssertBalances(rt, client, provider, current, dealId)
+
+		settlement := actor.dealSettlements(rt, dealId)[0]
+		assert.Equal(t, current, settlement.LastSettledEpoch)
+		assert.Equal(t, big.Add(pay, pay2), settlement.AmountSettled)
+		actor.checkState(rt)
+	})
+
+	t.Run("fails for unknown deal", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrNotFound, "no such deal", func() {
+			rt.Call(actor.DealSettlements, &market.DealSettlementsParams{DealIDs: []abi.DealID{dealId, dealId + 1}})
+		})
+		actor.checkState(rt)
+	})
+}
+
+func TestGetDealStatus(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 1
+
+	t.Run("reports payments and obligation as the deal progresses", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+		d := actor.getDealProposal(rt, dealId)
+
+		// the client is committed to the whole fee before the deal is activated
+		assert.Equal(t, &market.GetDealStatusReturn{
+			SectorStartEpoch: -1,
+			LastUpdatedEpoch: -1,
+			SlashEpoch:       -1,
+			AmountPaid:       big.Zero(),
+			ClientObligation: d.TotalStorageFee(),
+		}, actor.getDealStatus(rt, dealId))
+
+		rt.SetEpoch(startEpoch - 1)
+		actor.activateDeals(rt, sectorExpiry, provider, d.StartEpoch-1, dealId)
+		status := actor.getDealStatus(rt, dealId)
+		assert.Equal(t, startEpoch-1, status.SectorStartEpoch)
+		assert.Equal(t, d.TotalStorageFee(), status.ClientObligation)
+
+		processEpoch := processEpoch(t, dealId, startEpoch)
+		rt.SetEpoch(processEpoch)
+		pay, _ := actor.cronTickAndAssertBalances(rt, client, provider, processEpoch, dealId)
+
+		status = actor.getDealStatus(rt, dealId)
+		assert.Equal(t, processEpoch, status.LastUpdatedEpoch)
+		assert.Equal(t, abi.ChainEpoch(-1), status.SlashEpoch)
+		assert.Equal(t, pay, status.AmountPaid)
+		assert.Equal(t, big.Sub(d.TotalStorageFee(), pay), status.ClientObligation)
+		assert.Equal(t, big.Mul(big.NewInt(int64(endEpoch-processEpoch)), d.StoragePricePerEpoch), status.ClientObligation)
+		actor.checkState(rt)
+	})
+
+	t.Run("obligation runs only to the slash epoch of a terminated deal", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+		d := actor.getDealProposal(rt, dealId)
+		rt.SetEpoch(startEpoch - 1)
+		actor.activateDeals(rt, sectorExpiry, provider, d.StartEpoch-1, dealId)
+
+		processEpoch := processEpoch(t, dealId, startEpoch)
+		rt.SetEpoch(processEpoch)
+		pay, _ := actor.cronTickAndAssertBalances(rt, client, provider, processEpoch, dealId)
+
+		slashEpoch := rt.SetEpoch(processEpoch + 100)
+		actor.terminateDeals(rt, provider, dealId)
+
+		status := actor.getDealStatus(rt, dealId)
+		assert.Equal(t, slashEpoch, status.SlashEpoch)
+		assert.Equal(t, pay, status.AmountPaid)
+		assert.Equal(t, big.Mul(big.NewInt(100), d.StoragePricePerEpoch), status.ClientObligation)
+		actor.checkState(rt)
+	})
+
+	t.Run("fails for unknown deal", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrNotFound, "no such deal", func() {
+			rt.Call(actor.GetDealStatus, &market.GetDealStatusParams{DealID: dealId + 1})
+		})
+		actor.checkState(rt)
+	})
+}
+
+func TestGetLockedFunds(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 1
+
+	t.Run("tracks collateral and payment obligations across deals", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch+1)
+		d1 := actor.getDealProposal(rt, dealId1)
+		d2 := actor.getDealProposal(rt, dealId2)
+
+		clientFunds := actor.getLockedFunds(rt, client)
+		assert.Equal(t, big.Add(d1.ClientCollateral, d2.ClientCollateral), clientFunds.Collateral)
+		assert.Equal(t, big.Add(d1.TotalStorageFee(), d2.TotalStorageFee()), clientFunds.PaymentObligation)
+		providerFunds := actor.getLockedFunds(rt, provider)
+		assert.Equal(t, big.Add(d1.ProviderCollateral, d2.ProviderCollateral), providerFunds.Collateral)
+		assert.True(t, providerFunds.PaymentObligation.IsZero())
+
+		// payments reduce the client's obligation
+		rt.SetEpoch(startEpoch - 1)
+		actor.activateDeals(rt, sectorExpiry, provider, d1.StartEpoch-1, dealId1, dealId2)
+		rt.SetEpoch(startEpoch + market.DealUpdatesInterval)
+		actor.cronTick(rt)
+		paid := big.Add(actor.getDealStatus(rt, dealId1).AmountPaid, actor.getDealStatus(rt, dealId2).AmountPaid)
+		assert.True(t, paid.GreaterThan(big.Zero()))
+		assert.Equal(t, big.Sub(big.Add(d1.TotalStorageFee(), d2.TotalStorageFee()), paid), actor.getLockedFunds(rt, client).PaymentObligation)
+
+		// nothing remains locked once the deals expire
+		rt.SetEpoch(endEpoch + 1 + market.DealUpdatesInterval)
+		actor.cronTick(rt)
+		for _, party := range []address.Address{client, provider} {
+			funds := actor.getLockedFunds(rt, party)
+			assert.True(t, funds.Collateral.IsZero())
+			assert.True(t, funds.PaymentObligation.IsZero())
+		}
+		actor.checkState(rt)
+	})
+
+	t.Run("slashed collateral is no longer locked", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.publishAndActivateDeal(rt, client, mAddrs, startEpoch, endEpoch, 0, sectorExpiry)
+		d := actor.getDealProposal(rt, dealId)
+
+		rt.SetEpoch(startEpoch + 10)
+		actor.terminateDeals(rt, provider, dealId)
+		rt.SetEpoch(processEpoch(t, dealId, startEpoch+10))
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+
+		assert.True(t, actor.getLockedFunds(rt, provider).Collateral.IsZero())
+		assert.True(t, actor.getLockedFunds(rt, client).Collateral.IsZero())
+		actor.checkState(rt)
+	})
+
+	t.Run("fails for unresolvable address", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		unknown := tutil.NewBLSAddr(t, 1)
+
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "failed to resolve address", func() {
+			rt.Call(actor.GetLockedFunds, &unknown)
+		})
+		actor.checkState(rt)
+	})
+}
+
+func TestPendingProposals(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 1
+
+	t.Run("count tracks published deals until cron processes them", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		assert.Zero(t, actor.getPendingProposalCount(rt))
+
+		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch+1)
+		d2 := actor.getDealProposal(rt, dealId2)
+		assert.Equal(t, uint64(2), actor.getPendingProposalCount(rt))
+
+		// one deal is activated and processed, the other times out
+		actor.activateDeals(rt, sectorExpiry, provider, 0, dealId1)
+		rt.SetEpoch(startEpoch + market.DealUpdatesInterval)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d2.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+		assert.Zero(t, actor.getPendingProposalCount(rt))
+		actor.checkState(rt)
+	})
+
+	t.Run("proposal left pending is removed after its deal's end epoch", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.publishAndActivateDeal(rt, client, mAddrs, startEpoch, endEpoch, 0, sectorExpiry)
+
+		// The deal appears already processed, so cron leaves its proposal pending.
+		actor.updateLastUpdated(rt, dealId, startEpoch)
+		rt.SetEpoch(startEpoch + market.DealUpdatesInterval)
+		actor.cronTick(rt)
+		assert.Equal(t, uint64(1), actor.getPendingProposalCount(rt))
+
+		rt.SetEpoch(endEpoch + market.PendingProposalExpiry - 1)
+		actor.cronTick(rt)
+		assert.Equal(t, uint64(1), actor.getPendingProposalCount(rt))
+
+		rt.SetEpoch(endEpoch + market.PendingProposalExpiry)
+		actor.cronTick(rt)
+		assert.Zero(t, actor.getPendingProposalCount(rt))
+		rt.ExpectLogsContain("removed 1 pending proposals")
+		actor.checkState(rt)
+	})
+}
+
+func TestProviderDeals(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+
+	t.Run("returns deals in pages", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch+1)
+		dealId3 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch+2)
+
+		ret := actor.providerDeals(rt, provider, builtin.PageParams{Limit: 2})
+		assert.Equal(t, []abi.DealID{dealId1, dealId2}, ret.Deals)
+		assert.True(t, ret.Page.HasMore)
+
+		ret = actor.providerDeals(rt, provider, builtin.PageParams{Limit: 2, Cursor: ret.Page.NextCursor})
+		assert.Equal(t, []abi.DealID{dealId3}, ret.Deals)
+		assert.False(t, ret.Page.HasMore)
+
+		// another provider has no deals
+		otherProvider := tutil.NewIDAddr(t, 501)
+		ret = actor.providerDeals(rt, otherProvider, builtin.PageParams{})
+		assert.Empty(t, ret.Deals)
+		actor.checkState(rt)
+	})
+
+	t.Run("timed out deal is removed", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+		d1 := actor.getDealProposal(rt, dealId1)
+		// the second deal starts after the first is processed
+		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch+market.DealUpdatesInterval, endEpoch)
+		assert.Equal(t, []abi.DealID{dealId1, dealId2}, actor.providerDeals(rt, provider, builtin.PageParams{}).Deals)
+
+		// the first deal times out without being activated
+		rt.SetEpoch(processEpoch(t, dealId1, startEpoch))
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d1.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+		actor.assertDealDeleted(rt, dealId1, d1)
+
+		assert.Equal(t, []abi.DealID{dealId2}, actor.providerDeals(rt, provider, builtin.PageParams{}).Deals)
+		actor.checkState(rt)
+	})
+
+	t.Run("fails for unresolvable provider", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrNotFound, "failed to resolve provider address", func() {
+			rt.Call(actor.ProviderDeals, &market.ProviderDealsParams{Provider: tutil.NewBLSAddr(t, 1)})
+		})
+		actor.checkState(rt)
+	})
+}
+
+func TestPieceDeals(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+
+	publishPiece := func(rt *mock.Runtime, actor *marketActorTestHarness, piece cid.Cid, start, end abi.ChainEpoch) abi.DealID {
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, start, end)
+		deal.PieceCID = piece
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		return actor.publishDeals(rt, mAddrs, publishDealReq{deal: deal})[0]
+	}
+	piece1 := tutil.MakeCID("piece-1", &market.PieceCIDPrefix)
+	piece2 := tutil.MakeCID("piece-2", &market.PieceCIDPrefix)
+
+	t.Run("returns deals for a piece in pages", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId1 := publishPiece(rt, actor, piece1, startEpoch, endEpoch)
+		dealId2 := publishPiece(rt, actor, piece2, startEpoch, endEpoch)
+		dealId3 := publishPiece(rt, actor, piece1, startEpoch, endEpoch+1)
+
+		ret := actor.pieceDeals(rt, piece1, builtin.PageParams{Limit: 1})
+		assert.Equal(t, []abi.DealID{dealId1}, ret.Deals)
+		assert.True(t, ret.Page.HasMore)
+
+		ret = actor.pieceDeals(rt, piece1, builtin.PageParams{Cursor: ret.Page.NextCursor})
+		assert.Equal(t, []abi.DealID{dealId3}, ret.Deals)
+		assert.False(t, ret.Page.HasMore)
+
+		assert.Equal(t, []abi.DealID{dealId2}, actor.pieceDeals(rt, piece2, builtin.PageParams{}).Deals)
+		assert.Empty(t, actor.pieceDeals(rt, tutil.MakeCID("piece-3", &market.PieceCIDPrefix), builtin.PageParams{}).Deals)
+		actor.checkState(rt)
+	})
+
+	t.Run("timed out deal is removed", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId1 := publishPiece(rt, actor, piece1, startEpoch, endEpoch)
+		d1 := actor.getDealProposal(rt, dealId1)
+		// the second deal starts after the first is processed
+		dealId2 := publishPiece(rt, actor, piece1, startEpoch+market.DealUpdatesInterval, endEpoch+1)
+
+		rt.SetEpoch(processEpoch(t, dealId1, startEpoch))
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d1.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+		actor.assertDealDeleted(rt, dealId1, d1)
+
+		assert.Equal(t, []abi.DealID{dealId2}, actor.pieceDeals(rt, piece1, builtin.PageParams{}).Deals)
+		actor.checkState(rt)
+	})
+
+	t.Run("fails for invalid piece CID", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "invalid piece CID", func() {
+			rt.Call(actor.PieceDeals, &market.PieceDealsParams{PieceCID: tutil.MakeCID("piece", nil)})
+		})
+		actor.checkState(rt)
+	})
+}
+
+func TestDiffDeals(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 100
+
+	rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+	store := adt.AsStore(rt)
+	diffFrom := func(prev cid.Cid) *market.DealsDiff {
+		diff, err := market.DiffDeals(store, prev, rt.StateRoot())
+		require.NoError(t, err)
+		return diff
+	}
+
+	// publish four deals
+	root := rt.StateRoot()
+	dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+	dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch+1)
+	dealId3 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch+2)
+	dealId4 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch+3)
+	d1 := actor.getDealProposal(rt, dealId1)
+	d3 := actor.getDealProposal(rt, dealId3)
+	d4 := actor.getDealProposal(rt, dealId4)
+	assert.Equal(t, &market.DealsDiff{Added: []abi.DealID{dealId1, dealId2, dealId3, dealId4}}, diffFrom(root))
+
+	// activate all but the third
+	root = rt.StateRoot()
+	actor.activateDeals(rt, sectorExpiry, provider, rt.SetEpoch(startEpoch-1), dealId1, dealId2, dealId4)
+	assert.Equal(t, &market.DealsDiff{Activated: []abi.DealID{dealId1, dealId2, dealId4}}, diffFrom(root))
+
+	// the third deal times out, and the fourth is terminated and removed
+	root = rt.StateRoot()
+	rt.SetEpoch(startEpoch + market.DealUpdatesInterval)
+	rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d3.ProviderCollateral, nil, exitcode.Ok)
+	actor.cronTick(rt)
+	actor.terminateDeals(rt, provider, dealId4)
+	rt.SetEpoch(processEpoch(t, dealId4, rt.Epoch()+1))
+	rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d4.ProviderCollateral, nil, exitcode.Ok)
+	actor.cronTick(rt)
+	// the first deal is terminated but not yet removed
+	actor.terminateDeals(rt, provider, dealId1)
+	assert.Equal(t, &market.DealsDiff{
+		Slashed: []abi.DealID{dealId1, dealId4},
+		Removed: []abi.DealID{dealId3, dealId4},
+	}, diffFrom(root))
+
+	// the first deal is removed, and the second expires
+	root = rt.StateRoot()
+	rt.SetEpoch(endEpoch + market.DealUpdatesInterval)
+	rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d1.ProviderCollateral, nil, exitcode.Ok)
+	actor.cronTick(rt)
+	assert.Equal(t, &market.DealsDiff{
+		Expired: []abi.DealID{dealId2},
+		Removed: []abi.DealID{dealId1, dealId2},
+	}, diffFrom(root))
+	actor.checkState(rt)
+}
+
+func TestCancelStorageDeals(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 400
+
+	t.Run("client cancels deal and both parties' funds are unlocked", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+		d := actor.getDealProposal(rt, dealId)
+		cEscrow := actor.getEscrowBalance(rt, client)
+		pEscrow := actor.getEscrowBalance(rt, provider)
+
+		actor.cancelDeals(rt, client, dealId)
+		actor.assertDealDeleted(rt, dealId, d)
+		require.EqualValues(t, cEscrow, actor.getEscrowBalance(rt, client))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, client))
+		require.EqualValues(t, pEscrow, actor.getEscrowBalance(rt, provider))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, provider))
+		actor.assertLockedFundStates(rt, big.Zero(), big.Zero(), big.Zero())
+		assert.Empty(t, actor.providerDeals(rt, provider, builtin.PageParams{}).Deals)
+		assert.Empty(t, actor.pieceDeals(rt, d.PieceCID, builtin.PageParams{}).Deals)
+
+		var st market.State
+		rt.GetState(&st)
+		summary, msgs := market.CheckStateInvariants(&st, rt.AdtStore(), rt.Balance(), rt.Epoch())
+		assert.True(t, msgs.IsEmpty(), strings.Join(msgs.Messages(), "\n"))
+		assert.Zero(t, summary.PendingProposalCount)
+		assert.Zero(t, summary.DealOpCount)
+
+		// the provider is not penalized when the start epoch passes
+		rt.SetEpoch(processEpoch(t, dealId, startEpoch))
+		actor.cronTickNoChange(rt, client, provider)
+		actor.checkState(rt)
+	})
+
+	t.Run("provider cancels upfront payment deal", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		dealId := actor.publishDealsWithPaymentMode(rt, mAddrs, market.DealPaymentUpfront, publishDealReq{deal: deal})[0]
+
+		expectGetControlAddresses(rt, provider, owner, worker)
+		actor.cancelDeals(rt, worker, dealId)
+		actor.assertDealDeleted(rt, dealId, &deal)
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, client))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, provider))
+
+		var st market.State
+		rt.GetState(&st)
+		assert.True(t, st.TotalClientUpfrontPayments.IsZero())
+		actor.checkState(rt)
+	})
+
+	t.Run("cancelled verified deal restores the client's data cap", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		deal.VerifiedDeal = true
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		dealId := actor.publishDeals(rt, mAddrs, publishDealReq{deal: deal})[0]
+
+		param := &verifreg.RestoreBytesParams{
+			Address:  client,
+			DealSize: big.NewIntUnsigned(uint64(deal.PieceSize)),
+		}
+		rt.ExpectSend(builtin.VerifiedRegistryActorAddr, builtin.MethodsVerifiedRegistry.RestoreBytes, param, abi.NewTokenAmount(0), nil, exitcode.Ok)
+		actor.cancelDeals(rt, client, dealId)
+		actor.assertDealDeleted(rt, dealId, &deal)
+		actor.checkState(rt)
+	})
+
+	t.Run("fail when deal has been activated", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+		actor.activateDeals(rt, sectorExpiry, provider, 0, dealId)
+
+		rt.SetCaller(client, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "has already been activated", func() {
+			rt.Call(actor.CancelStorageDeals, &market.CancelStorageDealsParams{DealIDs: []abi.DealID{dealId}})
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fail when start epoch has been reached", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+
+		rt.SetEpoch(startEpoch)
+		rt.SetCaller(client, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "has already been reached", func() {
+			rt.Call(actor.CancelStorageDeals, &market.CancelStorageDealsParams{DealIDs: []abi.DealID{dealId}})
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fail when caller is neither client nor provider", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+
+		rt.SetCaller(tutil.NewIDAddr(t, 999), builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectGetControlAddresses(rt, provider, owner, worker)
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "is not worker or control address", func() {
+			rt.Call(actor.CancelStorageDeals, &market.CancelStorageDealsParams{DealIDs: []abi.DealID{dealId}})
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fail for unknown or duplicate deal", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+
+		rt.SetCaller(client, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrNotFound, "no such deal", func() {
+			rt.Call(actor.CancelStorageDeals, &market.CancelStorageDealsParams{DealIDs: []abi.DealID{dealId + 1}})
+		})
+
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "duplicate deal", func() {
+			rt.Call(actor.CancelStorageDeals, &market.CancelStorageDealsParams{DealIDs: []abi.DealID{dealId, dealId}})
+		})
+		actor.checkState(rt)
+	})
+}
+
+func TestRenewableDeals(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	renewal := market.DealRenewal{
+		Duration:             100 * builtin.EpochsInDay,
+		StoragePricePerEpoch: big.NewInt(20),
+	}
+	renewalEnd := endEpoch + renewal.Duration
+	sectorExpiry := renewalEnd + 400
+
+	generateRenewableDeal := func(rt *mock.Runtime, actor *marketActorTestHarness) market.DealProposal {
+		deal := generateDealProposal(client, provider, startEpoch, endEpoch)
+		deal.Renewal = &renewal
+		actor.addProviderFunds(rt, deal.ProviderCollateral, mAddrs)
+		actor.addParticipantFunds(rt, client, deal.ClientBalanceRequirement())
+		return deal
+	}
+	publishAndActivateRenewableDeal := func(rt *mock.Runtime, actor *marketActorTestHarness) (abi.DealID, market.DealProposal) {
+		deal := generateRenewableDeal(rt, actor)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		dealId := actor.publishDeals(rt, mAddrs, publishDealReq{deal: deal})[0]
+		actor.activateDeals(rt, sectorExpiry, provider, 0, dealId)
+		return dealId, deal
+	}
+	termFee := big.Mul(big.NewInt(int64(endEpoch-startEpoch)), big.NewInt(10))
+
+	t.Run("deal renews at end epoch and is paid at the renewal price", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId, deal := publishAndActivateRenewableDeal(rt, actor)
+		require.EqualValues(t, deal.ClientBalanceRequirement(), actor.getLockedBalance(rt, client))
+		cEscrow := actor.getEscrowBalance(rt, client)
+		pEscrow := actor.getEscrowBalance(rt, provider)
+
+		// the deal continues past its end epoch
+		rt.SetEpoch(endEpoch + 100)
+		actor.cronTick(rt)
+		paid := big.Add(termFee, big.Mul(big.NewInt(100), renewal.StoragePricePerEpoch))
+		require.EqualValues(t, big.Sub(cEscrow, paid), actor.getEscrowBalance(rt, client))
+		require.EqualValues(t, big.Add(pEscrow, paid), actor.getEscrowBalance(rt, provider))
+		require.EqualValues(t, deal.ProviderCollateral, actor.getLockedBalance(rt, provider))
+		actor.getDealProposal(rt, dealId)
+		actor.checkState(rt)
+
+		// and expires at the end of its renewal
+		rt.SetEpoch(renewalEnd + 5)
+		actor.cronTick(rt)
+		paid = big.Add(termFee, deal.RenewalStorageFee())
+		require.EqualValues(t, big.Sub(cEscrow, paid), actor.getEscrowBalance(rt, client))
+		require.EqualValues(t, big.Add(pEscrow, paid), actor.getEscrowBalance(rt, provider))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, client))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, provider))
+		actor.assertDealDeleted(rt, dealId, &deal)
+		actor.checkState(rt)
+	})
+
+	t.Run("deal ends at end epoch when client declines renewal", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId, deal := publishAndActivateRenewableDeal(rt, actor)
+		cEscrow := actor.getEscrowBalance(rt, client)
+		pEscrow := actor.getEscrowBalance(rt, provider)
+
+		actor.declineRenewal(rt, client, dealId)
+		assert.True(t, actor.getDealState(rt, dealId).RenewalDeclined)
+		require.EqualValues(t, big.Sub(deal.ClientBalanceRequirement(), deal.RenewalStorageFee()), actor.getLockedBalance(rt, client))
+		actor.checkState(rt)
+
+		rt.SetEpoch(endEpoch + 5)
+		actor.cronTick(rt)
+		require.EqualValues(t, big.Sub(cEscrow, termFee), actor.getEscrowBalance(rt, client))
+		require.EqualValues(t, big.Add(pEscrow, termFee), actor.getEscrowBalance(rt, provider))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, client))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, provider))
+		actor.assertDealDeleted(rt, dealId, &deal)
+		actor.checkState(rt)
+	})
+
+	t.Run("provider declines renewal", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId, _ := publishAndActivateRenewableDeal(rt, actor)
+
+		expectGetControlAddresses(rt, provider, owner, worker)
+		actor.declineRenewal(rt, worker, dealId)
+		assert.True(t, actor.getDealState(rt, dealId).RenewalDeclined)
+		actor.checkState(rt)
+	})
+
+	t.Run("termination during renewal refunds the remaining renewal fee", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId, deal := publishAndActivateRenewableDeal(rt, actor)
+		cEscrow := actor.getEscrowBalance(rt, client)
+		pEscrow := actor.getEscrowBalance(rt, provider)
+
+		rt.SetEpoch(endEpoch + 100)
+		actor.terminateDeals(rt, provider, dealId)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, deal.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+
+		paid := big.Add(termFee, big.Mul(big.NewInt(100), renewal.StoragePricePerEpoch))
+		require.EqualValues(t, big.Sub(cEscrow, paid), actor.getEscrowBalance(rt, client))
+		require.EqualValues(t, big.Sub(big.Add(pEscrow, paid), deal.ProviderCollateral), actor.getEscrowBalance(rt, provider))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, client))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, provider))
+		actor.assertDealDeleted(rt, dealId, &deal)
+		actor.checkState(rt)
+	})
+
+	t.Run("end epoch of renewable deal includes renewal", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := generateRenewableDeal(rt, actor)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		dealId := actor.publishDeals(rt, mAddrs, publishDealReq{deal: deal})[0]
+
+		rt.ExpectValidateCallerAny()
+		ret := rt.Call(actor.DealEndEpochs, &market.DealEndEpochsParams{DealIDs: []abi.DealID{dealId}}).(*market.DealEndEpochsReturn)
+		rt.Verify()
+		assert.Equal(t, []market.DealEndEpoch{{DealID: dealId, EndEpoch: renewalEnd}}, ret.Deals)
+
+		// a sector must cover the renewal to activate the deal
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "exceeds sector expiration", func() {
+			rt.Call(actor.ActivateDeals, &market.ActivateDealsParams{DealIDs: []abi.DealID{dealId}, SectorExpiry: renewalEnd - 1})
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fail to publish renewable deal paid upfront", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := generateRenewableDeal(rt, actor)
+
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectGetControlAddresses(rt, provider, owner, worker)
+		expectQueryNetworkInfo(rt, actor)
+		rt.ExpectVerifySignature(crypto.Signature{}, client, mustCbor(&deal), nil)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "cannot be paid upfront", func() {
+			rt.Call(actor.PublishStorageDealsWithPaymentMode, &market.PublishStorageDealsWithPaymentModeParams{
+				Deals:       []market.ClientDealProposal{{Proposal: deal}},
+				PaymentMode: market.DealPaymentUpfront,
+			})
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fail to publish deal with invalid renewal", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := generateDealProposal(client, provider, startEpoch, endEpoch)
+		deal.Renewal = &market.DealRenewal{Duration: 0, StoragePricePerEpoch: big.NewInt(20)}
+
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectGetControlAddresses(rt, provider, owner, worker)
+		expectQueryNetworkInfo(rt, actor)
+		rt.ExpectVerifySignature(crypto.Signature{}, client, mustCbor(&deal), nil)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "Renewal duration 0 not positive", func() {
+			rt.Call(actor.PublishStorageDeals, mkPublishStorageParams(deal))
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fail to publish deal with renewal paid by the other party", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := generateDealProposal(client, provider, startEpoch, endEpoch)
+		deal.Renewal = &market.DealRenewal{Duration: 100, StoragePricePerEpoch: big.NewInt(-20)}
+
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectGetControlAddresses(rt, provider, owner, worker)
+		expectQueryNetworkInfo(rt, actor)
+		rt.ExpectVerifySignature(crypto.Signature{}, client, mustCbor(&deal), nil)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "Renewal storage price paid by a different party", func() {
+			rt.Call(actor.PublishStorageDeals, mkPublishStorageParams(deal))
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fail to decline renewal of deal that is not renewable", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.publishAndActivateDeal(rt, client, mAddrs, startEpoch, endEpoch, 0, sectorExpiry)
+
+		rt.SetCaller(client, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "is not renewable", func() {
+			rt.Call(actor.DeclineRenewal, &market.DeclineRenewalParams{DealIDs: []abi.DealID{dealId}})
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fail to decline renewal of deal that is not activated", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := generateRenewableDeal(rt, actor)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		dealId := actor.publishDeals(rt, mAddrs, publishDealReq{deal: deal})[0]
+
+		rt.SetCaller(client, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "has not been activated", func() {
+			rt.Call(actor.DeclineRenewal, &market.DeclineRenewalParams{DealIDs: []abi.DealID{dealId}})
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fail to decline renewal at or after end epoch", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId, _ := publishAndActivateRenewableDeal(rt, actor)
+
+		rt.SetEpoch(endEpoch)
+		rt.SetCaller(client, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "has already been reached", func() {
+			rt.Call(actor.DeclineRenewal, &market.DeclineRenewalParams{DealIDs: []abi.DealID{dealId}})
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fail to decline renewal twice", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId, _ := publishAndActivateRenewableDeal(rt, actor)
+		actor.declineRenewal(rt, client, dealId)
+
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "already been declined", func() {
+			rt.Call(actor.DeclineRenewal, &market.DeclineRenewalParams{DealIDs: []abi.DealID{dealId}})
+		})
+		actor.checkState(rt)
+	})
+}
+
+func TestSlashingGracePeriod(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 400
+	gracePeriod := abi.ChainEpoch(50 * builtin.EpochsInDay)
+
+	publishAndActivateDealWithGracePeriod := func(rt *mock.Runtime, actor *marketActorTestHarness) (abi.DealID, market.DealProposal) {
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		deal.SlashingGracePeriod = gracePeriod
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		dealId := actor.publishDeals(rt, mAddrs, publishDealReq{deal: deal})[0]
+		actor.activateDeals(rt, sectorExpiry, provider, 0, dealId)
+		return dealId, deal
+	}
+
+	t.Run("deal terminated within grace period is not slashed", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId, deal := publishAndActivateDealWithGracePeriod(rt, actor)
+		cEscrow := actor.getEscrowBalance(rt, client)
+		pEscrow := actor.getEscrowBalance(rt, provider)
+
+		terminateEpoch := rt.SetEpoch(startEpoch + gracePeriod - 1)
+		actor.terminateDeals(rt, provider, dealId)
+
+		// the provider is paid up to the termination and its collateral unlocked, nothing is burnt
+		rt.SetEpoch(terminateEpoch + market.DealUpdatesInterval)
+		actor.cronTick(rt)
+		paid := big.Mul(big.NewInt(int64(terminateEpoch-startEpoch)), deal.StoragePricePerEpoch)
+		require.EqualValues(t, big.Sub(cEscrow, paid), actor.getEscrowBalance(rt, client))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, client))
+		require.EqualValues(t, big.Add(pEscrow, paid), actor.getEscrowBalance(rt, provider))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, provider))
+		actor.assertDealDeleted(rt, dealId, &deal)
+		actor.checkState(rt)
+	})
+
+	t.Run("deal terminated after grace period is slashed", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId, deal := publishAndActivateDealWithGracePeriod(rt, actor)
+		pEscrow := actor.getEscrowBalance(rt, provider)
+
+		terminateEpoch := rt.SetEpoch(startEpoch + gracePeriod)
+		actor.terminateDeals(rt, provider, dealId)
+
+		rt.SetEpoch(terminateEpoch + market.DealUpdatesInterval)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, deal.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+		paid := big.Mul(big.NewInt(int64(terminateEpoch-startEpoch)), deal.StoragePricePerEpoch)
+		require.EqualValues(t, big.Sub(big.Add(pEscrow, paid), deal.ProviderCollateral), actor.getEscrowBalance(rt, provider))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, provider))
+		actor.assertDealDeleted(rt, dealId, &deal)
+		actor.checkState(rt)
+	})
+}
+
+func TestVerifiedDealDataCapRefund(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 400
+
+	publishAndActivateVerifiedDeal := func(rt *mock.Runtime, actor *marketActorTestHarness) (abi.DealID, market.DealProposal) {
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		deal.VerifiedDeal = true
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		dealId := actor.publishDeals(rt, mAddrs, publishDealReq{deal: deal})[0]
+		actor.activateDeals(rt, sectorExpiry, provider, 0, dealId)
+		return dealId, deal
+	}
+
+	t.Run("deal terminated early in its term refunds a pro-rated share of its data cap", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId, deal := publishAndActivateVerifiedDeal(rt, actor)
+
+		terminateEpoch := rt.SetEpoch(startEpoch + deal.Duration()/4)
+		actor.terminateDeals(rt, provider, dealId)
+
+		rt.SetEpoch(terminateEpoch + market.DealUpdatesInterval)
+		refund := big.Div(big.Mul(big.NewIntUnsigned(uint64(deal.PieceSize)), big.NewInt(3)), big.NewInt(4))
+		rt.ExpectSend(builtin.VerifiedRegistryActorAddr, builtin.MethodsVerifiedRegistry.RefundBytes,
+			&verifreg.RefundBytesParams{Address: client, Amount: refund}, big.Zero(), nil, exitcode.Ok)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, deal.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+		actor.assertDealDeleted(rt, dealId, &deal)
+		actor.checkState(rt)
+	})
+
+	t.Run("deal terminated late in its term refunds nothing", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId, deal := publishAndActivateVerifiedDeal(rt, actor)
+
+		terminateEpoch := rt.SetEpoch(startEpoch + deal.Duration()/2)
+		actor.terminateDeals(rt, provider, dealId)
+
+		rt.SetEpoch(terminateEpoch + market.DealUpdatesInterval)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, deal.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+		actor.assertDealDeleted(rt, dealId, &deal)
+		actor.checkState(rt)
+	})
+
+	t.Run("failure to refund does not prevent the deal's removal", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId, deal := publishAndActivateVerifiedDeal(rt, actor)
+
+		terminateEpoch := rt.SetEpoch(startEpoch)
+		actor.terminateDeals(rt, provider, dealId)
+
+		rt.SetEpoch(terminateEpoch + market.DealUpdatesInterval)
+		refund := big.NewIntUnsigned(uint64(deal.PieceSize))
+		rt.ExpectSend(builtin.VerifiedRegistryActorAddr, builtin.MethodsVerifiedRegistry.RefundBytes,
+			&verifreg.RefundBytesParams{Address: client, Amount: refund}, big.Zero(), nil, exitcode.ErrIllegalArgument)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, deal.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+		rt.ExpectLogsContain("failed to send RefundBytes")
+		actor.assertDealDeleted(rt, dealId, &deal)
+		actor.checkState(rt)
+	})
+}
+
+func TestActivationExtensions(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 400
+	deadline := startEpoch + 2*market.DealUpdatesInterval
+
+	extensionCount := func(rt *mock.Runtime) uint64 {
+		var st market.State
+		rt.GetState(&st)
+		summary, _ := market.CheckStateInvariants(&st, rt.AdtStore(), rt.Balance(), rt.Epoch())
+		return summary.ActivationExtensionCount
+	}
+
+	setup := func(t *testing.T) (*mock.Runtime, *marketActorTestHarness, abi.DealID) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+		return rt, actor, dealId
+	}
+
+	t.Run("granted extension allows activation after start epoch", func(t *testing.T) {
+		rt, actor, dealId := setup(t)
+		actor.requestActivationExtension(rt, mAddrs, dealId, deadline)
+		actor.grantActivationExtension(rt, client, dealId, deadline)
+		assert.Equal(t, uint64(1), extensionCount(rt))
+
+		// the deal is not timed out at its start epoch
+		rt.SetEpoch(processEpoch(t, dealId, startEpoch))
+		actor.cronTick(rt)
+		actor.getDealProposal(rt, dealId)
+
+		rt.SetEpoch(deadline)
+		actor.activateDeals(rt, sectorExpiry, provider, deadline, dealId)
+		assert.Zero(t, extensionCount(rt))
+		actor.checkState(rt)
+	})
+
+	t.Run("deal times out after granted deadline", func(t *testing.T) {
+		rt, actor, dealId := setup(t)
+		d := actor.getDealProposal(rt, dealId)
+		actor.requestActivationExtension(rt, mAddrs, dealId, deadline)
+		actor.grantActivationExtension(rt, client, dealId, deadline)
+
+		rt.SetEpoch(processEpoch(t, dealId, startEpoch))
+		actor.cronTick(rt)
+
+		rt.SetEpoch(deadline + 1)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "activation deadline", func() {
+			actor.activateDeals(rt, sectorExpiry, provider, deadline+1, dealId)
+		})
+
+		rt.SetEpoch(processEpoch(t, dealId, deadline))
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+		actor.assertDealDeleted(rt, dealId, d)
+		assert.Zero(t, extensionCount(rt))
+		actor.checkState(rt)
+	})
+
+	t.Run("requested extension has no effect until granted", func(t *testing.T) {
+		rt, actor, dealId := setup(t)
+		d := actor.getDealProposal(rt, dealId)
+		actor.requestActivationExtension(rt, mAddrs, dealId, deadline)
 
-		ret := rt.Call(actor.ComputeDataCommitment, param)
-		val, ok := ret.(*market.ComputeDataCommitmentReturn)
-		require.True(t, ok)
-		require.Equal(t, c, (cid.Cid)(val.CommDs[0]))
-		rt.Verify()
+		rt.SetEpoch(processEpoch(t, dealId, startEpoch))
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+		actor.assertDealDeleted(rt, dealId, d)
+		assert.Zero(t, extensionCount(rt))
 		actor.checkState(rt)
 	})
 
-	t.Run("success on empty piece info", func(t *testing.T) {
-		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
-		param := &market.ComputeDataCommitmentParams{}
-		param.Inputs = []*market.SectorDataSpec{{DealIDs: nil, SectorType: 1}}
+	t.Run("grant must be for the requested deadline", func(t *testing.T) {
+		rt, actor, dealId := setup(t)
+		actor.requestActivationExtension(rt, mAddrs, dealId, deadline)
 
-		c := tutil.MakeCID("UnsealedEmpty", &market.PieceCIDPrefix)
-		rt.ExpectComputeUnsealedSectorCID(1, []abi.PieceInfo{}, c, nil)
-		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
-		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
-		ret := rt.Call(actor.ComputeDataCommitment, param)
-		val, ok := ret.(*market.ComputeDataCommitmentReturn)
-		require.True(t, ok)
-		require.Equal(t, c, (cid.Cid)(val.CommDs[0]))
-		rt.Verify()
+		rt.SetCaller(client, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "was not requested", func() {
+			rt.Call(actor.GrantActivationExtension, &market.ActivationExtensionParams{DealID: dealId, Deadline: deadline + 1})
+		})
 		actor.checkState(rt)
 	})
 
-	t.Run("success with multiple sector commitments", func(t *testing.T) {
-		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
-		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end)
-		d1 := actor.getDealProposal(rt, dealId1)
+	t.Run("fails if grant caller is not the client", func(t *testing.T) {
+		rt, actor, dealId := setup(t)
+		actor.requestActivationExtension(rt, mAddrs, dealId, deadline)
 
-		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end+1)
-		d2 := actor.getDealProposal(rt, dealId2)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "is not the client", func() {
+			rt.Call(actor.GrantActivationExtension, &market.ActivationExtensionParams{DealID: dealId, Deadline: deadline})
+		})
+		actor.checkState(rt)
+	})
 
-		param := &market.ComputeDataCommitmentParams{}
-		param.Inputs = []*market.SectorDataSpec{
-			{DealIDs: nil, SectorType: 1},
-			{DealIDs: []abi.DealID{dealId1, dealId2}, SectorType: 1},
-		}
+	t.Run("fails if request caller is not a provider control address", func(t *testing.T) {
+		rt, actor, dealId := setup(t)
 
-		p1 := abi.PieceInfo{Size: d1.PieceSize, PieceCID: d1.PieceCID}
-		p2 := abi.PieceInfo{Size: d2.PieceSize, PieceCID: d2.PieceCID}
+		rt.SetCaller(client, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectGetControlAddresses(rt, provider, owner, worker)
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "is not worker or control address", func() {
+			rt.Call(actor.RequestActivationExtension, &market.ActivationExtensionParams{DealID: dealId, Deadline: deadline})
+		})
+		actor.checkState(rt)
+	})
 
-		c1 := tutil.MakeCID("UnsealedSector1", &market.PieceCIDPrefix)
-		c2 := tutil.MakeCID("UnsealedSector2", &market.PieceCIDPrefix)
+	t.Run("fails to request a deadline that is not later", func(t *testing.T) {
+		rt, actor, dealId := setup(t)
+		actor.requestActivationExtension(rt, mAddrs, dealId, deadline)
+		actor.grantActivationExtension(rt, client, dealId, deadline)
 
-		rt.ExpectComputeUnsealedSectorCID(1, []abi.PieceInfo{}, c1, nil)
-		rt.ExpectComputeUnsealedSectorCID(1, []abi.PieceInfo{p1, p2}, c2, nil)
-		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
-		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectGetControlAddresses(rt, provider, owner, worker)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "must be after current deadline", func() {
+			rt.Call(actor.RequestActivationExtension, &market.ActivationExtensionParams{DealID: dealId, Deadline: deadline})
+		})
+		actor.checkState(rt)
+	})
 
-		ret := rt.Call(actor.ComputeDataCommitment, param)
-		val, ok := ret.(*market.ComputeDataCommitmentReturn)
-		require.True(t, ok)
-		require.Equal(t, c1, (cid.Cid)(val.CommDs[0]))
-		require.Equal(t, c2, (cid.Cid)(val.CommDs[1]))
-		rt.Verify()
+	t.Run("fails to request once the deadline has elapsed", func(t *testing.T) {
+		rt, actor, dealId := setup(t)
+		rt.SetEpoch(startEpoch + 1)
+
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectGetControlAddresses(rt, provider, owner, worker)
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "has already elapsed", func() {
+			rt.Call(actor.RequestActivationExtension, &market.ActivationExtensionParams{DealID: dealId, Deadline: deadline})
+		})
 		actor.checkState(rt)
 	})
 
-	t.Run("fail when deal proposal is absent", func(t *testing.T) {
-		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+	t.Run("fails to request for an activated deal", func(t *testing.T) {
+		rt, actor, dealId := setup(t)
+		actor.activateDeals(rt, sectorExpiry, provider, 0, dealId)
 
-		param := &market.ComputeDataCommitmentParams{}
-		param.Inputs = []*market.SectorDataSpec{{DealIDs: []abi.DealID{1}, SectorType: 1}}
-		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
-		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
-		rt.ExpectAbort(exitcode.ErrNotFound, func() {
-			rt.Call(actor.ComputeDataCommitment, param)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "already activated", func() {
+			rt.Call(actor.RequestActivationExtension, &market.ActivationExtensionParams{DealID: dealId, Deadline: deadline})
 		})
 		actor.checkState(rt)
 	})
+}
 
-	t.Run("fail when syscall returns an error", func(t *testing.T) {
+func TestDealLifecycleEvents(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 400
+
+	payment := func(dealId abi.DealID, d *market.DealProposal, from, to abi.ChainEpoch) *market.DealPaymentEvent {
+		return &market.DealPaymentEvent{
+			DealID:   dealId,
+			Client:   client,
+			Provider: provider,
+			Amount:   big.Mul(big.NewInt(int64(to-from)), d.StoragePricePerEpoch),
+			Epoch:    to,
+		}
+	}
+
+	t.Run("deal is published, activated, paid and expires", func(t *testing.T) {
 		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
-		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, start, end)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
 		d := actor.getDealProposal(rt, dealId)
-		param := &market.ComputeDataCommitmentParams{}
-		param.Inputs = []*market.SectorDataSpec{{DealIDs: []abi.DealID{dealId}, SectorType: 1}}
+		dealEvent := &market.DealEvent{DealID: dealId, Client: client, Provider: provider}
+		rt.ExpectEmitted(market.EventDealPublished, dealEvent)
 
-		pi := abi.PieceInfo{Size: d.PieceSize, PieceCID: d.PieceCID}
+		actor.activateDeals(rt, sectorExpiry, provider, 0, dealId)
+		rt.ExpectEmitted(market.EventDealActivated, dealEvent)
 
-		rt.ExpectComputeUnsealedSectorCID(1, []abi.PieceInfo{pi}, cid.Cid{}, errors.New("error"))
-		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
-		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
-		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
-			rt.Call(actor.ComputeDataCommitment, param)
-		})
+		current := rt.SetEpoch(processEpoch(t, dealId, startEpoch) + 100)
+		actor.cronTick(rt)
+		rt.ExpectEmitted(market.EventDealPaymentSettled, payment(dealId, d, startEpoch, current))
+		rt.ExpectNotEmitted(market.EventDealExpired)
+
+		rt.SetEpoch(endEpoch + market.DealUpdatesInterval)
+		actor.cronTick(rt)
+		rt.ExpectEmitted(market.EventDealPaymentSettled, payment(dealId, d, current, endEpoch))
+		rt.ExpectEmitted(market.EventDealExpired, dealEvent)
+		rt.ExpectNotEmitted(market.EventDealSlashed)
 		actor.checkState(rt)
 	})
 
-	t.Run("fail whole call when one deal proposal of one sector is absent", func(t *testing.T) {
+	t.Run("terminated deal is paid and slashed", func(t *testing.T) {
 		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
-		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end)
-		dealId2 := abi.DealID(2)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+		d := actor.getDealProposal(rt, dealId)
+		actor.activateDeals(rt, sectorExpiry, provider, 0, dealId)
 
-		param := &market.ComputeDataCommitmentParams{}
-		param.Inputs = []*market.SectorDataSpec{
-			{DealIDs: nil, SectorType: 1},
-			{DealIDs: []abi.DealID{dealId1, dealId2}, SectorType: 1},
-		}
-		c1 := tutil.MakeCID("UnsealedSector1", &market.PieceCIDPrefix)
-		rt.ExpectComputeUnsealedSectorCID(1, []abi.PieceInfo{}, c1, nil) // first sector is computed
-		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
-		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
-		rt.ExpectAbort(exitcode.ErrNotFound, func() {
-			rt.Call(actor.ComputeDataCommitment, param)
+		terminateEpoch := rt.SetEpoch(startEpoch + 100)
+		actor.terminateDeals(rt, provider, dealId)
+		rt.ExpectNotEmitted(market.EventDealSlashed)
+
+		rt.SetEpoch(terminateEpoch + market.DealUpdatesInterval)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+		rt.ExpectEmitted(market.EventDealPaymentSettled, payment(dealId, d, startEpoch, terminateEpoch))
+		rt.ExpectEmitted(market.EventDealSlashed, &market.DealSlashedEvent{
+			DealID:     dealId,
+			Client:     client,
+			Provider:   provider,
+			SlashEpoch: terminateEpoch,
+			Penalty:    d.ProviderCollateral,
 		})
+		rt.ExpectNotEmitted(market.EventDealExpired)
 		actor.checkState(rt)
 	})
 
-	t.Run("fail whole call when one commitment fails syscall", func(t *testing.T) {
+	t.Run("timed out deal is slashed", func(t *testing.T) {
 		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
-		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end)
-		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end+1)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch)
+		d := actor.getDealProposal(rt, dealId)
 
-		param := &market.ComputeDataCommitmentParams{}
-		param.Inputs = []*market.SectorDataSpec{
-			{DealIDs: nil, SectorType: 1},
-			{DealIDs: []abi.DealID{dealId1, dealId2}, SectorType: 1},
-		}
-		rt.ExpectComputeUnsealedSectorCID(1, []abi.PieceInfo{}, cid.Cid{}, errors.New("error"))
-		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
-		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
-		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
-			rt.Call(actor.ComputeDataCommitment, param)
+		current := rt.SetEpoch(processEpoch(t, dealId, startEpoch))
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+		rt.ExpectEmitted(market.EventDealSlashed, &market.DealSlashedEvent{
+			DealID:     dealId,
+			Client:     client,
+			Provider:   provider,
+			SlashEpoch: current,
+			Penalty:    d.ProviderCollateral,
 		})
+		rt.ExpectNotEmitted(market.EventDealActivated)
+		rt.ExpectNotEmitted(market.EventDealPaymentSettled)
 		actor.checkState(rt)
 	})
-
 }
 
 func TestVerifyDealsForActivation(t *testing.T) {
@@ -2882,6 +5124,12 @@ type publishDealReq struct {
 }
 
 func (h *marketActorTestHarness) publishDeals(rt *mock.Runtime, minerAddrs *minerAddrs, publishDealReqs ...publishDealReq) []abi.DealID {
+	return h.publishDealsWithPaymentMode(rt, minerAddrs, market.DealPaymentPerEpoch, publishDealReqs...)
+}
+
+// Publishes per-epoch payment deals through PublishStorageDeals, and other payment modes through PublishStorageDealsWithPaymentMode.
+func (h *marketActorTestHarness) publishDealsWithPaymentMode(rt *mock.Runtime, minerAddrs *minerAddrs, paymentMode market.DealPaymentMode,
+	publishDealReqs ...publishDealReq) []abi.DealID {
 
 	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
 	rt.ExpectSend(
@@ -2916,7 +5164,15 @@ func (h *marketActorTestHarness) publishDeals(rt *mock.Runtime, minerAddrs *mine
 		}
 	}
 
-	ret := rt.Call(h.PublishStorageDeals, &params)
+	var ret interface{}
+	if paymentMode == market.DealPaymentPerEpoch {
+		ret = rt.Call(h.PublishStorageDeals, &params)
+	} else {
+		ret = rt.Call(h.PublishStorageDealsWithPaymentMode, &market.PublishStorageDealsWithPaymentModeParams{
+			Deals:       params.Deals,
+			PaymentMode: paymentMode,
+		})
+	}
 	rt.Verify()
 
 	resp, ok := ret.(*market.PublishStorageDealsReturn)
@@ -2945,6 +5201,95 @@ func (h *marketActorTestHarness) publishDeals(rt *mock.Runtime, minerAddrs *mine
 	return resp.IDs
 }
 
+// Publishes deals through PublishStorageDealsWithDerivedIDs, expecting data cap to be used only for verified deals
+// not already published.
+func (h *marketActorTestHarness) publishDealsWithDerivedIDs(rt *mock.Runtime, minerAddrs *minerAddrs, deals ...market.DealProposal) []abi.DealID {
+	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+	rt.ExpectSend(
+		minerAddrs.provider,
+		builtin.MethodsMiner.ControlAddresses,
+		nil,
+		big.Zero(),
+		&miner.GetControlAddressesReturn{Owner: minerAddrs.owner, Worker: minerAddrs.worker, ControlAddrs: minerAddrs.control},
+		exitcode.Ok,
+	)
+	expectQueryNetworkInfo(rt, h)
+
+	var params market.PublishStorageDealsWithPaymentModeParams
+	for _, deal := range deals {
+		buf := bytes.Buffer{}
+		require.NoError(h.t, deal.MarshalCBOR(&buf), "failed to marshal deal proposal")
+		sig := crypto.Signature{Type: crypto.SigTypeBLS, Data: []byte("does not matter")}
+		params.Deals = append(params.Deals, market.ClientDealProposal{Proposal: deal, ClientSignature: sig})
+		rt.ExpectVerifySignature(sig, deal.Client, buf.Bytes(), nil)
+
+		pcid, err := deal.Cid()
+		require.NoError(h.t, err)
+		id, err := market.DerivedDealID(pcid)
+		require.NoError(h.t, err)
+		if deal.VerifiedDeal && !h.dealProposalExists(rt, id) {
+			param := &verifreg.UseBytesParams{
+				Address:  deal.Client,
+				DealSize: big.NewIntUnsigned(uint64(deal.PieceSize)),
+			}
+			rt.ExpectSend(builtin.VerifiedRegistryActorAddr, builtin.MethodsVerifiedRegistry.UseBytes, param, abi.NewTokenAmount(0), nil, exitcode.Ok)
+		}
+	}
+
+	ret := rt.Call(h.PublishStorageDealsWithDerivedIDs, &params)
+	rt.Verify()
+
+	resp, ok := ret.(*market.PublishStorageDealsReturn)
+	require.True(h.t, ok, "unexpected type returned from call to PublishStorageDealsWithDerivedIDs")
+	require.Len(h.t, resp.IDs, len(deals))
+	for i, id := range resp.IDs {
+		require.Equal(h.t, deals[i], *h.getDealProposal(rt, id))
+	}
+	return resp.IDs
+}
+
+func (h *marketActorTestHarness) dealProposalExists(rt *mock.Runtime, dealID abi.DealID) bool {
+	var st market.State
+	rt.GetState(&st)
+	proposals, err := market.AsDealProposalArray(adt.AsStore(rt), st.Proposals)
+	require.NoError(h.t, err)
+	_, found, err := proposals.Get(dealID)
+	require.NoError(h.t, err)
+	return found
+}
+
+func (h *marketActorTestHarness) offerDeals(rt *mock.Runtime, client address.Address, deals ...market.DealProposal) []abi.DealID {
+	rt.SetCaller(client, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+	expectQueryNetworkInfo(rt, h)
+	for _, deal := range deals {
+		if deal.VerifiedDeal {
+			param := &verifreg.UseBytesParams{
+				Address:  deal.Client,
+				DealSize: big.NewIntUnsigned(uint64(deal.PieceSize)),
+			}
+			rt.ExpectSend(builtin.VerifiedRegistryActorAddr, builtin.MethodsVerifiedRegistry.UseBytes, param, abi.NewTokenAmount(0), nil, exitcode.Ok)
+		}
+	}
+
+	ret := rt.Call(h.OfferStorageDeals, &market.OfferStorageDealsParams{Deals: deals})
+	rt.Verify()
+
+	resp, ok := ret.(*market.PublishStorageDealsReturn)
+	require.True(h.t, ok, "unexpected type returned from call to OfferStorageDeals")
+	require.Len(h.t, resp.IDs, len(deals))
+	return resp.IDs
+}
+
+func (h *marketActorTestHarness) acceptDealOffers(rt *mock.Runtime, minerAddrs *minerAddrs, dealIDs ...abi.DealID) {
+	rt.SetCaller(minerAddrs.worker, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+	expectGetControlAddresses(rt, minerAddrs.provider, minerAddrs.owner, minerAddrs.worker, minerAddrs.control...)
+
+	rt.Call(h.AcceptStorageDealOffers, &market.AcceptStorageDealOffersParams{DealIDs: dealIDs})
+	rt.Verify()
+}
+
 func (h *marketActorTestHarness) assertDealsNotActivated(rt *mock.Runtime, epoch abi.ChainEpoch, dealIDs ...abi.DealID) {
 	var st market.State
 	rt.GetState(&st)
@@ -3032,6 +5377,92 @@ func (h *marketActorTestHarness) getLockedBalance(rt *mock.Runtime, addr address
 	return bal
 }
 
+func (h *marketActorTestHarness) dealSettlements(rt *mock.Runtime, dealIDs ...abi.DealID) []market.DealSettlement {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.DealSettlements, &market.DealSettlementsParams{DealIDs: dealIDs}).(*market.DealSettlementsReturn)
+	rt.Verify()
+	return ret.Deals
+}
+
+func (h *marketActorTestHarness) getDealStatus(rt *mock.Runtime, dealID abi.DealID) *market.GetDealStatusReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.GetDealStatus, &market.GetDealStatusParams{DealID: dealID}).(*market.GetDealStatusReturn)
+	rt.Verify()
+	return ret
+}
+
+func (h *marketActorTestHarness) getLockedFunds(rt *mock.Runtime, a address.Address) *market.GetLockedFundsReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.GetLockedFunds, &a).(*market.GetLockedFundsReturn)
+	rt.Verify()
+	return ret
+}
+
+func (h *marketActorTestHarness) getPendingProposalCount(rt *mock.Runtime) uint64 {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.GetPendingProposalCount, nil).(*market.GetPendingProposalCountReturn)
+	rt.Verify()
+	return ret.Count
+}
+
+func (h *marketActorTestHarness) continueTermination(rt *mock.Runtime) uint64 {
+	rt.SetCaller(tutil.NewIDAddr(h.t, 999), builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.ContinueTermination, nil).(*market.ContinueTerminationReturn)
+	rt.Verify()
+	return ret.Remaining
+}
+
+func (h *marketActorTestHarness) authorizeDealProposal(rt *mock.Runtime, client address.Address, proposal market.DealProposal) {
+	rt.SetCaller(client, builtin.MultisigActorCodeID)
+	rt.ExpectValidateCallerAny()
+	rt.Call(h.AuthorizeDealProposal, &market.AuthorizeDealProposalParams{Proposal: proposal})
+	rt.Verify()
+}
+
+func (h *marketActorTestHarness) requestActivationExtension(rt *mock.Runtime, minerAddrs *minerAddrs, dealID abi.DealID, deadline abi.ChainEpoch) {
+	rt.SetCaller(minerAddrs.worker, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+	expectGetControlAddresses(rt, minerAddrs.provider, minerAddrs.owner, minerAddrs.worker, minerAddrs.control...)
+	rt.Call(h.RequestActivationExtension, &market.ActivationExtensionParams{DealID: dealID, Deadline: deadline})
+	rt.Verify()
+}
+
+func (h *marketActorTestHarness) grantActivationExtension(rt *mock.Runtime, client address.Address, dealID abi.DealID, deadline abi.ChainEpoch) {
+	rt.SetCaller(client, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAny()
+	rt.Call(h.GrantActivationExtension, &market.ActivationExtensionParams{DealID: dealID, Deadline: deadline})
+	rt.Verify()
+}
+
+func (h *marketActorTestHarness) providerDeals(rt *mock.Runtime, provider address.Address, page builtin.PageParams) *market.ProviderDealsReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.ProviderDeals, &market.ProviderDealsParams{Provider: provider, Page: page}).(*market.ProviderDealsReturn)
+	rt.Verify()
+	return ret
+}
+
+func (h *marketActorTestHarness) pieceDeals(rt *mock.Runtime, pieceCID cid.Cid, page builtin.PageParams) *market.PieceDealsReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.PieceDeals, &market.PieceDealsParams{PieceCID: pieceCID, Page: page}).(*market.PieceDealsReturn)
+	rt.Verify()
+	return ret
+}
+
+func (h *marketActorTestHarness) cancelDeals(rt *mock.Runtime, caller address.Address, dealIDs ...abi.DealID) {
+	rt.SetCaller(caller, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+	rt.Call(h.CancelStorageDeals, &market.CancelStorageDealsParams{DealIDs: dealIDs})
+	rt.Verify()
+}
+
+func (h *marketActorTestHarness) declineRenewal(rt *mock.Runtime, caller address.Address, dealIDs ...abi.DealID) {
+	rt.SetCaller(caller, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+	rt.Call(h.DeclineRenewal, &market.DeclineRenewalParams{DealIDs: dealIDs})
+	rt.Verify()
+}
+
 func (h *marketActorTestHarness) getDealState(rt *mock.Runtime, dealID abi.DealID) *market.DealState {
 	var st market.State
 	rt.GetState(&st)
@@ -3078,6 +5509,12 @@ func (h *marketActorTestHarness) assertDealDeleted(rt *mock.Runtime, dealId abi.
 	found, err = pending.Get(abi.CidKey(pcid), nil)
 	require.NoError(h.t, err)
 	require.False(h.t, found)
+
+	upfront, err := adt.AsSet(adt.AsStore(rt), st.UpfrontPaymentDeals, builtin.DefaultHamtBitwidth)
+	require.NoError(h.t, err)
+	found, err = upfront.Has(abi.UIntKey(uint64(dealId)))
+	require.NoError(h.t, err)
+	require.False(h.t, found)
 }
 
 func (h *marketActorTestHarness) assertDealsTerminated(rt *mock.Runtime, epoch abi.ChainEpoch, dealIds ...abi.DealID) {
@@ -3125,7 +5562,7 @@ func (h *marketActorTestHarness) updateLastUpdated(rt *mock.Runtime, dealId abi.
 	require.NoError(h.t, err)
 	require.NotNil(h.t, s)
 
-	require.NoError(h.t, states.Set(dealId, &market.DealState{s.SectorStartEpoch, newLastUpdated, s.SlashEpoch}))
+	require.NoError(h.t, states.Set(dealId, &market.DealState{s.SectorStartEpoch, newLastUpdated, s.SlashEpoch, s.PaymentMode, s.RenewalDeclined}))
 	st.States, err = states.Root()
 	require.NoError(h.t, err)
 	rt.ReplaceState(&st)
@@ -3154,7 +5591,7 @@ func (h *marketActorTestHarness) generateAndPublishDeal(rt *mock.Runtime, client
 func (h *marketActorTestHarness) generateDealAndAddFunds(rt *mock.Runtime, client address.Address, minerAddrs *minerAddrs,
 	startEpoch, endEpoch abi.ChainEpoch) market.DealProposal {
 	deal := generateDealProposal(client, minerAddrs.provider, startEpoch, endEpoch)
-	h.addProviderFunds(rt, deal.ProviderCollateral, minerAddrs)
+	h.addProviderFunds(rt, deal.ProviderBalanceRequirement(), minerAddrs)
 	h.addParticipantFunds(rt, client, deal.ClientBalanceRequirement())
 	return deal
 }
@@ -3163,7 +5600,7 @@ func (h *marketActorTestHarness) generateDealWithCollateralAndAddFunds(rt *mock.
 	minerAddrs *minerAddrs, providerCollateral, clientCollateral abi.TokenAmount, startEpoch, endEpoch abi.ChainEpoch) market.DealProposal {
 	deal := generateDealProposalWithCollateral(client, minerAddrs.provider, providerCollateral, clientCollateral,
 		startEpoch, endEpoch)
-	h.addProviderFunds(rt, deal.ProviderCollateral, minerAddrs)
+	h.addProviderFunds(rt, deal.ProviderBalanceRequirement(), minerAddrs)
 	h.addParticipantFunds(rt, client, deal.ClientBalanceRequirement())
 	return deal
 }
@@ -3185,12 +5622,20 @@ func (h *marketActorTestHarness) checkState(rt *mock.Runtime, expectedMessagePat
 	}
 }
 
+func mustLabel(s string) market.DealLabel {
+	label, err := market.NewLabelFromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return label
+}
+
 func generateDealProposalWithCollateral(client, provider address.Address, providerCollateral, clientCollateral abi.TokenAmount, startEpoch, endEpoch abi.ChainEpoch) market.DealProposal {
 	pieceCid := tutil.MakeCID("1", &market.PieceCIDPrefix)
 	pieceSize := abi.PaddedPieceSize(2048)
 	storagePerEpoch := big.NewInt(10)
 
-	return market.DealProposal{PieceCID: pieceCid, PieceSize: pieceSize, Client: client, Provider: provider, Label: "label", StartEpoch: startEpoch,
+	return market.DealProposal{PieceCID: pieceCid, PieceSize: pieceSize, Client: client, Provider: provider, Label: mustLabel("label"), StartEpoch: startEpoch,
 		EndEpoch: endEpoch, StoragePricePerEpoch: storagePerEpoch, ProviderCollateral: providerCollateral, ClientCollateral: clientCollateral}
 }
 
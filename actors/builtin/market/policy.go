@@ -26,13 +26,54 @@ var DealMaxDuration = abi.ChainEpoch(540 * builtin.EpochsInDay) // PARAM_SPEC
 // DealMaxLabelSize is the maximum size of a deal label.
 const DealMaxLabelSize = 256
 
+// Maximum number of sectors for which a single ComputeDataCommitment call computes unsealed CIDs, bounding its gas.
+// Accommodates the largest aggregated prove-commitment, so that it needs only one call.
+const ComputeDataCommitmentMaxInputs = 819
+
+// Maximum number of deal terminations processed by a single call to OnMinerSectorsTerminate or ContinueTermination.
+// Terminations beyond the cap are queued, to be processed in order of notification by later calls.
+var DealTerminationsMax = 5000 // PARAM_SPEC
+
+// The number of epochs after a deal's maximum end epoch at which its proposal is removed from the set of pending
+// proposals, should it remain there. Cron removes a deal's proposal from the set when it first processes the deal,
+// so this only collects proposals left behind.
+var PendingProposalExpiry = abi.ChainEpoch(builtin.EpochsInDay) // PARAM_SPEC
+
+// The fraction of a verified deal's term before which termination of the deal refunds its client a pro-rated share
+// of the data cap the deal used.
+var VerifiedDealRefundTermFraction = builtin.BigFrac{
+	Numerator:   big.NewInt(1), // PARAM_SPEC
+	Denominator: big.NewInt(2),
+}
+
+// The data cap refunded to the client of a verified deal terminated at an epoch: the share of the deal's size
+// for the remainder of its term, if the deal is terminated before VerifiedDealRefundTermFraction of its term.
+// The renewal term of a renewable deal is not included.
+func VerifiedDealDataCapRefund(deal *DealProposal, terminationEpoch abi.ChainEpoch) abi.StoragePower {
+	if !deal.VerifiedDeal {
+		return big.Zero()
+	}
+	elapsed := terminationEpoch - deal.StartEpoch
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	term := big.NewInt(int64(deal.Duration()))
+	if big.Mul(big.NewInt(int64(elapsed)), VerifiedDealRefundTermFraction.Denominator).GreaterThanEqual(
+		big.Mul(term, VerifiedDealRefundTermFraction.Numerator)) {
+		return big.Zero()
+	}
+	remaining := big.NewInt(int64(deal.Duration() - elapsed))
+	return big.Div(big.Mul(big.NewIntUnsigned(uint64(deal.PieceSize)), remaining), term)
+}
+
 // Bounds (inclusive) on deal duration
 func DealDurationBounds(_ abi.PaddedPieceSize) (min abi.ChainEpoch, max abi.ChainEpoch) {
 	return DealMinDuration, DealMaxDuration
 }
 
+// A negative price is paid by the provider to the client.
 func DealPricePerEpochBounds(_ abi.PaddedPieceSize, _ abi.ChainEpoch) (min abi.TokenAmount, max abi.TokenAmount) {
-	return abi.NewTokenAmount(0), builtin.TotalFilecoin
+	return builtin.TotalFilecoin.Neg(), builtin.TotalFilecoin
 }
 
 func DealProviderCollateralBounds(pieceSize abi.PaddedPieceSize, verified bool, networkRawPower, networkQAPower, baselinePower abi.StoragePower,
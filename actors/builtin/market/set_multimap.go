@@ -144,6 +144,84 @@ func (mm *SetMultimap) ForEach(epoch abi.ChainEpoch, fn func(id abi.DealID) erro
 	return nil
 }
 
+// Adds a value to the set for an arbitrary key, such as an address.
+func (mm *SetMultimap) PutKey(k abi.Keyer, v abi.DealID) error {
+	set, found, err := mm.get(k)
+	if err != nil {
+		return err
+	}
+	if !found {
+		set, err = adt.MakeEmptySet(mm.store, mm.innerBitwidth)
+		if err != nil {
+			return err
+		}
+	}
+	if err = set.Put(dealKey(v)); err != nil {
+		return xerrors.Errorf("failed to add value %d to set %s: %w", v, k.Key(), err)
+	}
+	return mm.putSet(k, set)
+}
+
+// Removes a value from the set for an arbitrary key, removing the key if its set becomes empty.
+func (mm *SetMultimap) RemoveKey(k abi.Keyer, v abi.DealID) error {
+	set, found, err := mm.get(k)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return xerrors.Errorf("no set for key %s", k.Key())
+	}
+	if err = set.Delete(dealKey(v)); err != nil {
+		return xerrors.Errorf("failed to remove value %d from set %s: %w", v, k.Key(), err)
+	}
+
+	empty := true
+	errStop := errors.New("stop")
+	if err = set.ForEach(func(string) error {
+		empty = false
+		return errStop
+	}); err != nil && err != errStop {
+		return err
+	}
+	if empty {
+		if err = mm.mp.Delete(k); err != nil {
+			return xerrors.Errorf("failed to delete set key %s: %w", k.Key(), err)
+		}
+		return nil
+	}
+	return mm.putSet(k, set)
+}
+
+// Iterates all entries for an arbitrary key, iteration halts if the function returns an error.
+func (mm *SetMultimap) ForEachKey(k abi.Keyer, fn func(id abi.DealID) error) error {
+	set, found, err := mm.get(k)
+	if err != nil {
+		return err
+	}
+	if found {
+		return set.ForEach(func(k string) error {
+			v, err := parseDealKey(k)
+			if err != nil {
+				return err
+			}
+			return fn(v)
+		})
+	}
+	return nil
+}
+
+func (mm *SetMultimap) putSet(k abi.Keyer, set *adt.Set) error {
+	src, err := set.Root()
+	if err != nil {
+		return xerrors.Errorf("failed to flush set root: %w", err)
+	}
+	newSetRoot := cbg.CborCid(src)
+	if err = mm.mp.Put(k, &newSetRoot); err != nil {
+		return xerrors.Errorf("failed to store set: %w", err)
+	}
+	return nil
+}
+
 func (mm *SetMultimap) get(key abi.Keyer) (*adt.Set, bool, error) {
 	var setRoot cbg.CborCid
 	found, err := mm.mp.Get(key, &setRoot)
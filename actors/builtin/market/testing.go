@@ -23,15 +23,21 @@ type DealSummary struct {
 	SectorStartEpoch abi.ChainEpoch
 	LastUpdatedEpoch abi.ChainEpoch
 	SlashEpoch       abi.ChainEpoch
+	PaymentMode      DealPaymentMode
 }
 
 type StateSummary struct {
-	Deals                map[abi.DealID]*DealSummary
-	PendingProposalCount uint64
-	DealStateCount       uint64
-	LockTableCount       uint64
-	DealOpEpochCount     uint64
-	DealOpCount          uint64
+	Deals                    map[abi.DealID]*DealSummary
+	PendingProposalCount     uint64
+	DealStateCount           uint64
+	LockTableCount           uint64
+	DealOpEpochCount         uint64
+	DealOpCount              uint64
+	UpfrontPaymentDealCount  uint64
+	DealOfferCount           uint64
+	AuthorizedProposalCount  uint64
+	PendingTerminationCount  uint64
+	ActivationExtensionCount uint64
 }
 
 // Checks internal invariants of market state.
@@ -50,6 +56,14 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 		st.TotalClientStorageFee.GreaterThanEqual(big.Zero()),
 		"negative total client storage fee: %v", st.TotalClientLockedCollateral)
 
+	acc.Require(
+		st.TotalClientUpfrontPayments.GreaterThanEqual(big.Zero()),
+		"negative total client upfront payments: %v", st.TotalClientUpfrontPayments)
+
+	acc.Require(
+		st.TotalProviderStorageFee.GreaterThanEqual(big.Zero()),
+		"negative total provider storage fee: %v", st.TotalProviderStorageFee)
+
 	//
 	// Proposals
 	//
@@ -57,8 +71,17 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 	proposalCids := make(map[cid.Cid]struct{})
 	maxDealID := int64(-1)
 	proposalStats := make(map[abi.DealID]*DealSummary)
+	proposalPieces := make(map[abi.DealID]cid.Cid)
+	renewableDeals := make(map[abi.DealID]bool)
 	expectedDealOps := make(map[abi.DealID]struct{})
 	totalProposalCollateral := abi.NewTokenAmount(0)
+	expectedLockedCollateral := make(map[address.Address]abi.TokenAmount)
+	addLockedCollateral := func(a address.Address, amount abi.TokenAmount) {
+		if prev, ok := expectedLockedCollateral[a]; ok {
+			amount = big.Add(prev, amount)
+		}
+		expectedLockedCollateral[a] = amount
+	}
 
 	if proposals, err := adt.AsArray(store, st.Proposals, ProposalsAmtBitwidth); err != nil {
 		acc.Addf("error loading proposals: %v", err)
@@ -76,7 +99,11 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 
 			// keep some state
 			proposalCids[pcid] = struct{}{}
-			if dealID > maxDealID {
+			if IsDerivedDealID(abi.DealID(dealID)) {
+				derivedID, err := DerivedDealID(pcid)
+				acc.RequireNoError(err, "failed to derive id for deal %d", dealID)
+				acc.Require(derivedID == abi.DealID(dealID), "deal %d does not have the id %d derived from its proposal", dealID, derivedID)
+			} else if dealID > maxDealID {
 				maxDealID = dealID
 			}
 			proposalStats[abi.DealID(dealID)] = &DealSummary{
@@ -88,7 +115,14 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 				SlashEpoch:       abi.ChainEpoch(-1),
 			}
 
+			proposalPieces[abi.DealID(dealID)] = proposal.PieceCID
+			if proposal.Renewal != nil {
+				renewableDeals[abi.DealID(dealID)] = true
+				acc.Require(proposal.Renewal.Duration > 0, "deal %d has non-positive renewal duration %d", dealID, proposal.Renewal.Duration)
+			}
 			totalProposalCollateral = big.Sum(totalProposalCollateral, proposal.ClientCollateral, proposal.ProviderCollateral)
+			addLockedCollateral(proposal.Client, proposal.ClientCollateral)
+			addLockedCollateral(proposal.Provider, proposal.ProviderCollateral)
 
 			acc.Require(proposal.Client.Protocol() == address.ID, "client address for deal %d is not an ID address", dealID)
 			acc.Require(proposal.Provider.Protocol() == address.ID, "provider address for deal %d is not an ID address", dealID)
@@ -97,7 +131,49 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 		acc.RequireNoError(err, "error iterating proposals")
 	}
 
-	// next id should be higher than any existing deal
+	//
+	// Deal Offers
+	//
+
+	dealOfferCount := uint64(0)
+	dealOfferIDs := make(map[abi.DealID]struct{})
+	if offers, err := adt.AsArray(store, st.DealOffers, ProposalsAmtBitwidth); err != nil {
+		acc.Addf("error loading deal offers: %v", err)
+	} else {
+		var offer DealProposal
+		err = offers.ForEach(&offer, func(dealID int64) error {
+			pcid, err := offer.Cid()
+			if err != nil {
+				return err
+			}
+
+			if offer.StartEpoch >= currEpoch {
+				expectedDealOps[abi.DealID(dealID)] = struct{}{}
+			}
+
+			_, found := proposalStats[abi.DealID(dealID)]
+			acc.Require(!found, "deal offer %d is also a deal proposal", dealID)
+
+			proposalCids[pcid] = struct{}{}
+			if dealID > maxDealID {
+				maxDealID = dealID
+			}
+			dealOfferIDs[abi.DealID(dealID)] = struct{}{}
+
+			// Only the client's collateral is locked for an offer.
+			totalProposalCollateral = big.Add(totalProposalCollateral, offer.ClientCollateral)
+			addLockedCollateral(offer.Client, offer.ClientCollateral)
+
+			acc.Require(offer.Client.Protocol() == address.ID, "client address for deal offer %d is not an ID address", dealID)
+			acc.Require(offer.Provider.Protocol() == address.ID, "provider address for deal offer %d is not an ID address", dealID)
+
+			dealOfferCount++
+			return nil
+		})
+		acc.RequireNoError(err, "error iterating deal offers")
+	}
+
+	// next id should be higher than any existing sequentially generated deal
 	acc.Require(int64(st.NextID) > maxDealID, "next id, %d, is not greater than highest id in proposals, %d", st.NextID, maxDealID)
 
 	//
@@ -130,6 +206,14 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 				dealState.SlashEpoch == epochUndefined || dealState.SlashEpoch <= currEpoch,
 				"deal %d state slashed after current epoch %d: %v", dealID, currEpoch, dealState)
 
+			acc.Require(
+				dealState.PaymentMode == DealPaymentPerEpoch || dealState.PaymentMode == DealPaymentUpfront,
+				"deal %d state has invalid payment mode: %v", dealID, dealState)
+
+			acc.Require(
+				!dealState.RenewalDeclined || renewableDeals[abi.DealID(dealID)],
+				"deal %d state has renewal declined but is not renewable: %v", dealID, dealState)
+
 			stats, found := proposalStats[abi.DealID(dealID)]
 			if !found {
 				acc.Addf("no deal proposal for deal state %d", dealID)
@@ -137,6 +221,7 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 				stats.SectorStartEpoch = dealState.SectorStartEpoch
 				stats.LastUpdatedEpoch = dealState.LastUpdatedEpoch
 				stats.SlashEpoch = dealState.SlashEpoch
+				stats.PaymentMode = dealState.PaymentMode
 			}
 
 			dealStateCount++
@@ -149,6 +234,28 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 	// Pending Proposals
 	//
 
+	scheduledProposals := make(map[cid.Cid]struct{})
+	if expirations, err := adt.AsMultimap(store, st.PendingProposalExpirations, builtin.DefaultHamtBitwidth,
+		PendingProposalExpirationsAmtBitwidth); err != nil {
+		acc.Addf("error loading pending proposal expirations: %v", err)
+	} else {
+		err = expirations.ForAll(func(key string, arr *adt.Array) error {
+			epoch, err := abi.ParseUIntKey(key)
+			if err != nil {
+				return err
+			}
+			acc.Require(abi.ChainEpoch(epoch) > st.LastCron, "pending proposal expiry at epoch %d not after last cron %d",
+				epoch, st.LastCron)
+
+			var pcid cbg.CborCid
+			return arr.ForEach(&pcid, func(_ int64) error {
+				scheduledProposals[cid.Cid(pcid)] = struct{}{}
+				return nil
+			})
+		})
+		acc.RequireNoError(err, "error iterating pending proposal expirations")
+	}
+
 	pendingProposalCount := uint64(0)
 	if pendingProposals, err := adt.AsMap(store, st.PendingProposals, builtin.DefaultHamtBitwidth); err != nil {
 		acc.Addf("error loading pending proposals: %v", err)
@@ -160,13 +267,116 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 			}
 
 			_, found := proposalCids[proposalCID]
-			acc.Require(found, "pending proposal with cid %v not found within proposals %v", proposalCID, pendingProposals)
+			acc.Require(found, "pending proposal with cid %v not found within proposals or offers %v", proposalCID, pendingProposals)
+			_, found = scheduledProposals[proposalCID]
+			acc.Require(found, "pending proposal with cid %v has no scheduled expiry", proposalCID)
 
 			pendingProposalCount++
 			return nil
 		})
 		acc.RequireNoError(err, "error iterating pending proposals")
 	}
+	acc.Require(pendingProposalCount == st.PendingProposalCount, "pending proposal count %d, expected %d",
+		st.PendingProposalCount, pendingProposalCount)
+
+	//
+	// Upfront Payment Deals
+	//
+
+	upfrontPaymentDealCount := uint64(0)
+	if upfrontDeals, err := adt.AsSet(store, st.UpfrontPaymentDeals, builtin.DefaultHamtBitwidth); err != nil {
+		acc.Addf("error loading upfront payment deals: %v", err)
+	} else {
+		err = upfrontDeals.ForEach(func(key string) error {
+			dealID, err := abi.ParseUIntKey(key)
+			if err != nil {
+				return err
+			}
+
+			stats, found := proposalStats[abi.DealID(dealID)]
+			acc.Require(found, "upfront payment deal %d not found within proposals", dealID)
+			if found {
+				acc.Require(stats.SectorStartEpoch == epochUndefined, "upfront payment deal %d has been activated", dealID)
+			}
+
+			upfrontPaymentDealCount++
+			return nil
+		})
+		acc.RequireNoError(err, "error iterating upfront payment deals")
+	}
+
+	//
+	// Authorized Proposals
+	//
+
+	authorizedProposalCount := uint64(0)
+	if authorizedProposals, err := adt.AsSet(store, st.AuthorizedProposals, builtin.DefaultHamtBitwidth); err != nil {
+		acc.Addf("error loading authorized proposals: %v", err)
+	} else {
+		err = authorizedProposals.ForEach(func(key string) error {
+			if _, err := cid.Parse([]byte(key)); err != nil {
+				return err
+			}
+			authorizedProposalCount++
+			return nil
+		})
+		acc.RequireNoError(err, "error iterating authorized proposals")
+	}
+
+	//
+	// Pending Terminations
+	//
+
+	pendingTerminationCount := uint64(0)
+	if pendingTerminations, err := adt.AsArray(store, st.PendingTerminations, PendingTerminationsAmtBitwidth); err != nil {
+		acc.Addf("error loading pending terminations: %v", err)
+	} else {
+		var pending PendingDealTermination
+		err = pendingTerminations.ForEach(&pending, func(i int64) error {
+			index := uint64(i)
+			acc.Require(index >= st.NextPendingTermination && index < st.NextPendingTermination+pendingTerminations.Length(),
+				"pending termination %d out of queue [%d, %d)", index, st.NextPendingTermination,
+				st.NextPendingTermination+pendingTerminations.Length())
+
+			// A queued deal may since have been removed.
+			if stats, found := proposalStats[pending.DealID]; found {
+				acc.Require(stats.Provider == pending.Provider, "pending termination of deal %d by %v, not its provider %v",
+					pending.DealID, pending.Provider, stats.Provider)
+			}
+			pendingTerminationCount++
+			return nil
+		})
+		acc.RequireNoError(err, "error iterating pending terminations")
+	}
+
+	//
+	// Activation Extensions
+	//
+
+	activationExtensionCount := uint64(0)
+	if activationExtensions, err := adt.AsMap(store, st.ActivationExtensions, builtin.DefaultHamtBitwidth); err != nil {
+		acc.Addf("error loading activation extensions: %v", err)
+	} else {
+		var ext ActivationExtension
+		err = activationExtensions.ForEach(&ext, func(key string) error {
+			dealID, err := abi.ParseUIntKey(key)
+			if err != nil {
+				return err
+			}
+
+			stats, found := proposalStats[abi.DealID(dealID)]
+			acc.Require(found, "activation extension of deal %d not found within proposals", dealID)
+			if found {
+				acc.Require(stats.SectorStartEpoch == epochUndefined, "activation extension of deal %d that has been activated", dealID)
+			}
+			acc.Require(ext.Granted == epochUndefined || ext.Requested == epochUndefined || ext.Requested > ext.Granted,
+				"activation extension of deal %d requests %d, not after granted %d", dealID, ext.Requested, ext.Granted)
+
+			activationExtensionCount++
+			return nil
+		})
+		acc.RequireNoError(err, "error iterating activation extensions")
+	}
 
 	//
 	// Escrow Table and Locked Table
@@ -200,11 +410,13 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 		})
 		acc.RequireNoError(err, "error iterating locked table")
 
-		// lockTable total should be sum of client and provider locked plus client storage fee
-		expectedLockTotal := big.Sum(st.TotalProviderLockedCollateral, st.TotalClientLockedCollateral, st.TotalClientStorageFee)
+		// lockTable total should be sum of client and provider locked plus storage fees and upfront payments
+		expectedLockTotal := big.Sum(st.TotalProviderLockedCollateral, st.TotalClientLockedCollateral, st.TotalClientStorageFee,
+			st.TotalClientUpfrontPayments, st.TotalProviderStorageFee)
 		acc.Require(lockedTotal.Equals(expectedLockTotal),
-			"locked total, %s, does not sum to provider locked, %s, client locked, %s, and client storage fee, %s",
-			lockedTotal, st.TotalProviderLockedCollateral, st.TotalClientLockedCollateral, st.TotalClientStorageFee)
+			"locked total, %s, does not sum to provider locked, %s, client locked, %s, client storage fee, %s, client upfront payments, %s, and provider storage fee, %s",
+			lockedTotal, st.TotalProviderLockedCollateral, st.TotalClientLockedCollateral, st.TotalClientStorageFee,
+			st.TotalClientUpfrontPayments, st.TotalProviderStorageFee)
 
 		// assert escrow <= actor balance
 		// lockTable item <= escrow item and escrowTotal <= balance implies lockTable total <= balance
@@ -214,6 +426,46 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 		acc.Require(escrowTotal.GreaterThanEqual(totalProposalCollateral), "escrow total, %v, less than sum of proposal collateral, %v", escrowTotal, totalProposalCollateral)
 	}
 
+	//
+	// Locked Collateral Table
+	//
+
+	if collateralTable, err := adt.AsBalanceTable(store, st.LockedCollateralTable); err != nil {
+		acc.Addf("error loading locked collateral table: %v", err)
+	} else {
+		var collateral abi.TokenAmount
+		err = (*adt.Map)(collateralTable).ForEach(&collateral, func(key string) error {
+			addr, err := address.NewFromBytes([]byte(key))
+			if err != nil {
+				return err
+			}
+
+			// every entry should be the sum of the collateral of the address's deals and offers
+			expected, ok := expectedLockedCollateral[addr]
+			if !ok {
+				expected = big.Zero()
+			}
+			delete(expectedLockedCollateral, addr)
+			acc.Require(collateral.Equals(expected),
+				"locked collateral for %s, %s, does not match collateral of its deals, %s", addr, collateral, expected)
+
+			if lockTable != nil {
+				lockedAmount, err := lockTable.Get(addr)
+				if err != nil {
+					return err
+				}
+				acc.Require(lockedAmount.GreaterThanEqual(collateral),
+					"locked collateral for %s, %s, greater than locked amount, %s", addr, collateral, lockedAmount)
+			}
+			return nil
+		})
+		acc.RequireNoError(err, "error iterating locked collateral table")
+
+		for addr, expected := range expectedLockedCollateral {
+			acc.Require(expected.IsZero(), "no locked collateral for %s, expected %s", addr, expected)
+		}
+	}
+
 	//
 	// Deal Ops by Epoch
 	//
@@ -234,7 +486,12 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 			dealOpEpochCount++
 			return dealOps.ForEach(abi.ChainEpoch(epoch), func(id abi.DealID) error {
 				_, found := proposalStats[id]
+				if !found {
+					_, found = dealOfferIDs[id]
+				}
 				acc.Require(found, "deal op found for deal id %d with missing proposal at epoch %d", id, epoch)
+				acc.Require(GenRandNextEpoch(abi.ChainEpoch(epoch), id) == abi.ChainEpoch(epoch),
+					"deal op for deal id %d at epoch %d is outside the deal's update bucket", id, epoch)
 				delete(expectedDealOps, id)
 				dealOpCount++
 				return nil
@@ -245,12 +502,83 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 
 	acc.Require(len(expectedDealOps) == 0, "missing deal ops for proposals: %v", expectedDealOps)
 
+	//
+	// Deals by provider
+	//
+
+	indexedDealCount := 0
+	if dealsByProvider, err := AsSetMultimap(store, st.DealsByProvider, builtin.DefaultHamtBitwidth, builtin.DefaultHamtBitwidth); err != nil {
+		acc.Addf("error loading deals by provider: %v", err)
+	} else {
+		var setRoot cbg.CborCid
+		err = dealsByProvider.mp.ForEach(&setRoot, func(key string) error {
+			provider, err := address.NewFromBytes([]byte(key))
+			if err != nil {
+				return errors.Wrapf(err, "deals by provider has key that is not an address: %s", key)
+			}
+
+			providerDealCount := 0
+			err = dealsByProvider.ForEachKey(abi.AddrKey(provider), func(id abi.DealID) error {
+				stats, found := proposalStats[id]
+				acc.Require(found, "deal %d indexed for provider %v not found within proposals", id, provider)
+				if found {
+					acc.Require(stats.Provider == provider, "deal %d indexed for provider %v has provider %v", id, provider, stats.Provider)
+				}
+				providerDealCount++
+				return nil
+			})
+			acc.Require(providerDealCount > 0, "empty set of deals indexed for provider %v", provider)
+			indexedDealCount += providerDealCount
+			return err
+		})
+		acc.RequireNoError(err, "error iterating deals by provider")
+	}
+	acc.Require(indexedDealCount == len(proposalStats), "%d deals indexed by provider, expected %d", indexedDealCount, len(proposalStats))
+
+	//
+	// Deals by piece
+	//
+
+	indexedDealCount = 0
+	if dealsByPiece, err := AsSetMultimap(store, st.DealsByPiece, builtin.DefaultHamtBitwidth, builtin.DefaultHamtBitwidth); err != nil {
+		acc.Addf("error loading deals by piece: %v", err)
+	} else {
+		var setRoot cbg.CborCid
+		err = dealsByPiece.mp.ForEach(&setRoot, func(key string) error {
+			_, pieceCID, err := cid.CidFromBytes([]byte(key))
+			if err != nil {
+				return errors.Wrapf(err, "deals by piece has key that is not a CID: %s", key)
+			}
+
+			pieceDealCount := 0
+			err = dealsByPiece.ForEachKey(abi.CidKey(pieceCID), func(id abi.DealID) error {
+				piece, found := proposalPieces[id]
+				acc.Require(found, "deal %d indexed for piece %v not found within proposals", id, pieceCID)
+				if found {
+					acc.Require(piece == pieceCID, "deal %d indexed for piece %v has piece %v", id, pieceCID, piece)
+				}
+				pieceDealCount++
+				return nil
+			})
+			acc.Require(pieceDealCount > 0, "empty set of deals indexed for piece %v", pieceCID)
+			indexedDealCount += pieceDealCount
+			return err
+		})
+		acc.RequireNoError(err, "error iterating deals by piece")
+	}
+	acc.Require(indexedDealCount == len(proposalStats), "%d deals indexed by piece, expected %d", indexedDealCount, len(proposalStats))
+
 	return &StateSummary{
-		Deals:                proposalStats,
-		PendingProposalCount: pendingProposalCount,
-		DealStateCount:       dealStateCount,
-		LockTableCount:       lockTableCount,
-		DealOpEpochCount:     dealOpEpochCount,
-		DealOpCount:          dealOpCount,
+		Deals:                    proposalStats,
+		PendingProposalCount:     pendingProposalCount,
+		DealStateCount:           dealStateCount,
+		LockTableCount:           lockTableCount,
+		DealOpEpochCount:         dealOpEpochCount,
+		DealOpCount:              dealOpCount,
+		UpfrontPaymentDealCount:  upfrontPaymentDealCount,
+		AuthorizedProposalCount:  authorizedProposalCount,
+		PendingTerminationCount:  pendingTerminationCount,
+		ActivationExtensionCount: activationExtensionCount,
+		DealOfferCount:           dealOfferCount,
 	}, acc
 }
@@ -52,6 +52,8 @@ type DealState struct {
 	SectorStartEpoch abi.ChainEpoch // -1 if not yet included in proven sector
 	LastUpdatedEpoch abi.ChainEpoch // -1 if deal state never updated
 	SlashEpoch       abi.ChainEpoch // -1 if deal never slashed
+	PaymentMode      DealPaymentMode
+	RenewalDeclined  bool // Whether either party has declined the renewal of a renewable deal
 }
 
 // Interprets a store as balance table with root `r`.
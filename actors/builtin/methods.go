@@ -16,9 +16,10 @@ var MethodsAccount = struct {
 }{MethodConstructor, 2}
 
 var MethodsInit = struct {
-	Constructor abi.MethodNum
-	Exec        abi.MethodNum
-}{MethodConstructor, 2}
+	Constructor           abi.MethodNum
+	Exec                  abi.MethodNum
+	CreatePaymentChannels abi.MethodNum
+}{MethodConstructor, 2, 3}
 
 var MethodsCron = struct {
 	Constructor abi.MethodNum
@@ -26,11 +27,14 @@ var MethodsCron = struct {
 }{MethodConstructor, 2}
 
 var MethodsReward = struct {
-	Constructor      abi.MethodNum
-	AwardBlockReward abi.MethodNum
-	ThisEpochReward  abi.MethodNum
-	UpdateNetworkKPI abi.MethodNum
-}{MethodConstructor, 2, 3, 4}
+	Constructor              abi.MethodNum
+	AwardBlockReward         abi.MethodNum
+	ThisEpochReward          abi.MethodNum
+	UpdateNetworkKPI         abi.MethodNum
+	ThisEpochRewardBreakdown abi.MethodNum
+	AwardBlockRewards        abi.MethodNum
+	ProjectRewards           abi.MethodNum
+}{MethodConstructor, 2, 3, 4, 5, 6, 7}
 
 var MethodsMultisig = struct {
 	Constructor                 abi.MethodNum
@@ -52,16 +56,35 @@ var MethodsPaych = struct {
 }{MethodConstructor, 2, 3, 4}
 
 var MethodsMarket = struct {
-	Constructor              abi.MethodNum
-	AddBalance               abi.MethodNum
-	WithdrawBalance          abi.MethodNum
-	PublishStorageDeals      abi.MethodNum
-	VerifyDealsForActivation abi.MethodNum
-	ActivateDeals            abi.MethodNum
-	OnMinerSectorsTerminate  abi.MethodNum
-	ComputeDataCommitment    abi.MethodNum
-	CronTick                 abi.MethodNum
-}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9}
+	Constructor                        abi.MethodNum
+	AddBalance                         abi.MethodNum
+	WithdrawBalance                    abi.MethodNum
+	PublishStorageDeals                abi.MethodNum
+	VerifyDealsForActivation           abi.MethodNum
+	ActivateDeals                      abi.MethodNum
+	OnMinerSectorsTerminate            abi.MethodNum
+	ComputeDataCommitment              abi.MethodNum
+	CronTick                           abi.MethodNum
+	PublishStorageDealsWithPaymentMode abi.MethodNum
+	OfferStorageDeals                  abi.MethodNum
+	AcceptStorageDealOffers            abi.MethodNum
+	PublishStorageDealsWithDerivedIDs  abi.MethodNum
+	DealEndEpochs                      abi.MethodNum
+	DealSettlements                    abi.MethodNum
+	ProviderDeals                      abi.MethodNum
+	PieceDeals                         abi.MethodNum
+	CancelStorageDeals                 abi.MethodNum
+	DeclineRenewal                     abi.MethodNum
+	WithdrawBalanceTo                  abi.MethodNum
+	AddBalanceFor                      abi.MethodNum
+	GetDealStatus                      abi.MethodNum
+	GetLockedFunds                     abi.MethodNum
+	AuthorizeDealProposal              abi.MethodNum
+	ContinueTermination                abi.MethodNum
+	RequestActivationExtension         abi.MethodNum
+	GrantActivationExtension           abi.MethodNum
+	GetPendingProposalCount            abi.MethodNum
+}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28}
 
 var MethodsPower = struct {
 	Constructor              abi.MethodNum
@@ -73,42 +96,81 @@ var MethodsPower = struct {
 	Deprecated1              abi.MethodNum
 	SubmitPoRepForBulkVerify abi.MethodNum
 	CurrentTotalPower        abi.MethodNum
-}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9}
+	UpdateClaimedProofType   abi.MethodNum
+	TotalPowerAt             abi.MethodNum
+	ListClaims               abi.MethodNum
+	CronEventQueueStats      abi.MethodNum
+	RecordConsensusFaults    abi.MethodNum
+	ConsensusFaultHistory    abi.MethodNum
+	MinerEligibleForElection abi.MethodNum
+	TotalPledge              abi.MethodNum
+	MinerPledge              abi.MethodNum
+}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18}
 
 var MethodsMiner = struct {
-	Constructor              abi.MethodNum
-	ControlAddresses         abi.MethodNum
-	ChangeWorkerAddress      abi.MethodNum
-	ChangePeerID             abi.MethodNum
-	SubmitWindowedPoSt       abi.MethodNum
-	PreCommitSector          abi.MethodNum
-	ProveCommitSector        abi.MethodNum
-	ExtendSectorExpiration   abi.MethodNum
-	TerminateSectors         abi.MethodNum
-	DeclareFaults            abi.MethodNum
-	DeclareFaultsRecovered   abi.MethodNum
-	OnDeferredCronEvent      abi.MethodNum
-	CheckSectorProven        abi.MethodNum
-	ApplyRewards             abi.MethodNum
-	ReportConsensusFault     abi.MethodNum
-	WithdrawBalance          abi.MethodNum
-	ConfirmSectorProofsValid abi.MethodNum
-	ChangeMultiaddrs         abi.MethodNum
-	CompactPartitions        abi.MethodNum
-	CompactSectorNumbers     abi.MethodNum
-	ConfirmUpdateWorkerKey   abi.MethodNum
-	RepayDebt                abi.MethodNum
-	ChangeOwnerAddress       abi.MethodNum
-	DisputeWindowedPoSt      abi.MethodNum
-	PreCommitSectorBatch     abi.MethodNum
-	ProveCommitAggregate     abi.MethodNum
-}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26}
+	Constructor                  abi.MethodNum
+	ControlAddresses             abi.MethodNum
+	ChangeWorkerAddress          abi.MethodNum
+	ChangePeerID                 abi.MethodNum
+	SubmitWindowedPoSt           abi.MethodNum
+	PreCommitSector              abi.MethodNum
+	ProveCommitSector            abi.MethodNum
+	ExtendSectorExpiration       abi.MethodNum
+	TerminateSectors             abi.MethodNum
+	DeclareFaults                abi.MethodNum
+	DeclareFaultsRecovered       abi.MethodNum
+	OnDeferredCronEvent          abi.MethodNum
+	CheckSectorProven            abi.MethodNum
+	ApplyRewards                 abi.MethodNum
+	ReportConsensusFault         abi.MethodNum
+	WithdrawBalance              abi.MethodNum
+	ConfirmSectorProofsValid     abi.MethodNum
+	ChangeMultiaddrs             abi.MethodNum
+	CompactPartitions            abi.MethodNum
+	CompactSectorNumbers         abi.MethodNum
+	ConfirmUpdateWorkerKey       abi.MethodNum
+	RepayDebt                    abi.MethodNum
+	ChangeOwnerAddress           abi.MethodNum
+	DisputeWindowedPoSt          abi.MethodNum
+	PreCommitSectorBatch         abi.MethodNum
+	ProveCommitAggregate         abi.MethodNum
+	GetBalanceBreakdown          abi.MethodNum
+	WithdrawBalanceTo            abi.MethodNum
+	ReportConsensusFaults        abi.MethodNum
+	UpgradeWindowPoStProofType   abi.MethodNum
+	DeadlinePostStatus           abi.MethodNum
+	PruneExpiredProofs           abi.MethodNum
+	WindowPoStChallenge          abi.MethodNum
+	FaultExpirations             abi.MethodNum
+	ChangeAddresses              abi.MethodNum
+	CancelWorkerKeyChange        abi.MethodNum
+	ExpirationSchedule           abi.MethodNum
+	DeclareFaultsWithReasons     abi.MethodNum
+	BatchLimits                  abi.MethodNum
+	ChangeDeadlineAssignment     abi.MethodNum
+	AttestSectorPieces           abi.MethodNum
+	LockFunds                    abi.MethodNum
+	GetReliabilityStats          abi.MethodNum
+	GetSectorRegions             abi.MethodNum
+	ChangeNotificationTarget     abi.MethodNum
+	ProvingPeriodStatus          abi.MethodNum
+	EarlyTerminations            abi.MethodNum
+	ProcessEarlyTerminations     abi.MethodNum
+	ProjectSectorPledge          abi.MethodNum
+	DesignatePreCommitAggregator abi.MethodNum
+}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32, 33, 34, 35, 36, 37, 38, 39, 40, 41, 42, 43, 44, 45, 46, 47, 48, 49, 50}
 
 var MethodsVerifiedRegistry = struct {
-	Constructor       abi.MethodNum
-	AddVerifier       abi.MethodNum
-	RemoveVerifier    abi.MethodNum
-	AddVerifiedClient abi.MethodNum
-	UseBytes          abi.MethodNum
-	RestoreBytes      abi.MethodNum
-}{MethodConstructor, 2, 3, 4, 5, 6}
+	Constructor         abi.MethodNum
+	AddVerifier         abi.MethodNum
+	RemoveVerifier      abi.MethodNum
+	AddVerifiedClient   abi.MethodNum
+	UseBytes            abi.MethodNum
+	RestoreBytes        abi.MethodNum
+	RefundBytes         abi.MethodNum
+	TransferDataCap     abi.MethodNum
+	ApproveDataCap      abi.MethodNum
+	UseDataCapAllowance abi.MethodNum
+	RenewVerifier       abi.MethodNum
+	CronTick            abi.MethodNum
+}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
@@ -42,6 +42,7 @@ func (dai *deadlineAssignmentInfo) maxPartitionsReached(partitionSize, maxPartit
 type deadlineAssignmentHeap struct {
 	maxPartitions uint64
 	partitionSize uint64
+	strategy      deadlineAssignmentStrategy
 	deadlines     []*deadlineAssignmentInfo
 }
 
@@ -64,7 +65,53 @@ func (dah *deadlineAssignmentHeap) Less(i, j int) bool {
 		return !aMaxPartitionsreached
 	}
 
-	// Otherwise:-
+	// Otherwise, defer to the assignment strategy.
+	return dah.strategy.prefer(a, b, dah.partitionSize)
+}
+
+func (dah *deadlineAssignmentHeap) Push(x interface{}) {
+	dah.deadlines = append(dah.deadlines, x.(*deadlineAssignmentInfo))
+}
+
+func (dah *deadlineAssignmentHeap) Pop() interface{} {
+	last := dah.deadlines[len(dah.deadlines)-1]
+	dah.deadlines[len(dah.deadlines)-1] = nil
+	dah.deadlines = dah.deadlines[:len(dah.deadlines)-1]
+	return last
+}
+
+// A heuristic for choosing the deadline to which each new sector is assigned.
+type deadlineAssignmentStrategy interface {
+	// Returns whether deadline a should be assigned the next sector in preference to deadline b.
+	// Neither deadline has reached the maximum number of partitions.
+	prefer(a, b *deadlineAssignmentInfo, partitionSize uint64) bool
+}
+
+// Selects the strategy for assigning new sectors to deadlines.
+type DeadlineAssignmentPolicy uint64
+
+const (
+	// Spreads sectors evenly across deadlines, keeping the maximum number of partitions in any deadline to a minimum.
+	DeadlineAssignmentSpread DeadlineAssignmentPolicy = iota
+	// Packs sectors densely into the fewest deadlines, spilling into another deadline only once
+	// the maximum number of partitions is reached.
+	DeadlineAssignmentPackDensely
+)
+
+func (p DeadlineAssignmentPolicy) strategy() (deadlineAssignmentStrategy, error) {
+	switch p {
+	case DeadlineAssignmentSpread:
+		return spreadAssignment{}, nil
+	case DeadlineAssignmentPackDensely:
+		return packDenselyAssignment{}, nil
+	default:
+		return nil, xerrors.Errorf("unknown deadline assignment policy %d", p)
+	}
+}
+
+type spreadAssignment struct{}
+
+func (spreadAssignment) prefer(a, b *deadlineAssignmentInfo, partitionSize uint64) bool {
 	// When assigning partitions to deadlines, we're trying to optimize the
 	// following:
 	//
@@ -112,8 +159,8 @@ func (dah *deadlineAssignmentHeap) Less(i, j int) bool {
 	// before compaction. However, that can only happen if the deadline in
 	// question could save an entire partition by compacting. At that point,
 	// the miner should compact the deadline.
-	aCompactPartitionsAfterAssignment := a.compactPartitionsAfterAssignment(dah.partitionSize)
-	bCompactPartitionsAfterAssignment := b.compactPartitionsAfterAssignment(dah.partitionSize)
+	aCompactPartitionsAfterAssignment := a.compactPartitionsAfterAssignment(partitionSize)
+	bCompactPartitionsAfterAssignment := b.compactPartitionsAfterAssignment(partitionSize)
 	if aCompactPartitionsAfterAssignment != bCompactPartitionsAfterAssignment {
 		return aCompactPartitionsAfterAssignment < bCompactPartitionsAfterAssignment
 	}
@@ -122,16 +169,16 @@ func (dah *deadlineAssignmentHeap) Less(i, j int) bool {
 	// post-compaction partitions, assign to the deadline with the fewest
 	// pre-compaction partitions (after assignment). This will put off
 	// compaction as long as possible.
-	aPartitionsAfterAssignment := a.partitionsAfterAssignment(dah.partitionSize)
-	bPartitionsAfterAssignment := b.partitionsAfterAssignment(dah.partitionSize)
+	aPartitionsAfterAssignment := a.partitionsAfterAssignment(partitionSize)
+	bPartitionsAfterAssignment := b.partitionsAfterAssignment(partitionSize)
 	if aPartitionsAfterAssignment != bPartitionsAfterAssignment {
 		return aPartitionsAfterAssignment < bPartitionsAfterAssignment
 	}
 
 	// Ok, we'll end up with the same number of partitions any which way we
 	// go. Try to fill up a partition instead of opening a new one.
-	aIsFullNow := a.isFullNow(dah.partitionSize)
-	bIsFullNow := b.isFullNow(dah.partitionSize)
+	aIsFullNow := a.isFullNow(partitionSize)
+	bIsFullNow := b.isFullNow(partitionSize)
 	if aIsFullNow != bIsFullNow {
 		return !aIsFullNow
 	}
@@ -161,29 +208,46 @@ func (dah *deadlineAssignmentHeap) Less(i, j int) bool {
 	return a.index < b.index
 }
 
-func (dah *deadlineAssignmentHeap) Push(x interface{}) {
-	dah.deadlines = append(dah.deadlines, x.(*deadlineAssignmentInfo))
-}
+type packDenselyAssignment struct{}
 
-func (dah *deadlineAssignmentHeap) Pop() interface{} {
-	last := dah.deadlines[len(dah.deadlines)-1]
-	dah.deadlines[len(dah.deadlines)-1] = nil
-	dah.deadlines = dah.deadlines[:len(dah.deadlines)-1]
-	return last
+func (packDenselyAssignment) prefer(a, b *deadlineAssignmentInfo, partitionSize uint64) bool {
+	// Fill an open partition before opening a new one.
+	aIsFullNow := a.isFullNow(partitionSize)
+	bIsFullNow := b.isFullNow(partitionSize)
+	if aIsFullNow != bIsFullNow {
+		return !aIsFullNow
+	}
+
+	// Otherwise assign to the deadline that already holds the most sectors, so that sectors are
+	// proven in as few deadlines as possible.
+	if a.totalSectors != b.totalSectors {
+		return a.totalSectors > b.totalSectors
+	}
+
+	// Finally, fallback on the deadline index.
+	return a.index < b.index
 }
 
-// Assigns partitions to deadlines, first filling partial partitions, then
-// adding new partitions to deadlines with the fewest live sectors.
+// Assigns partitions to deadlines, choosing each sector's deadline according to the policy.
+// The default policy first fills partial partitions, then adds new partitions to deadlines
+// with the fewest live sectors.
 func assignDeadlines(
+	policy DeadlineAssignmentPolicy,
 	maxPartitions uint64,
 	partitionSize uint64,
 	deadlines *[WPoStPeriodDeadlines]*Deadline,
 	sectors []*SectorOnChainInfo,
 ) (changes [WPoStPeriodDeadlines][]*SectorOnChainInfo, err error) {
+	strategy, err := policy.strategy()
+	if err != nil {
+		return changes, err
+	}
+
 	// Build a heap
 	dlHeap := deadlineAssignmentHeap{
 		maxPartitions: maxPartitions,
 		partitionSize: partitionSize,
+		strategy:      strategy,
 		deadlines:     make([]*deadlineAssignmentInfo, 0, len(deadlines)),
 	}
 
@@ -152,7 +152,7 @@ func TestDeadlineAssignment(t *testing.T) {
 		for i := range sectors {
 			sectors[i] = &SectorOnChainInfo{SectorNumber: abi.SectorNumber(i)}
 		}
-		assignment, err := assignDeadlines(maxPartitions, partitionSize, &deadlines, sectors)
+		assignment, err := assignDeadlines(DeadlineAssignmentSpread, maxPartitions, partitionSize, &deadlines, sectors)
 		require.NoError(t, err)
 		for i, sectors := range assignment {
 			dl := tc.deadlines[i]
@@ -170,6 +170,48 @@ func TestDeadlineAssignment(t *testing.T) {
 	}
 }
 
+func TestDeadlineAssignmentPackDensely(t *testing.T) {
+	const partitionSize = 4
+	const maxPartitions = 2
+
+	var deadlines [WPoStPeriodDeadlines]*Deadline
+	deadlines[0] = &Deadline{}
+	deadlines[1] = &Deadline{LiveSectors: 2, TotalSectors: 3}
+	deadlines[2] = &Deadline{LiveSectors: 4, TotalSectors: 4}
+	// The remaining deadlines are blacked out.
+
+	sectors := make([]*SectorOnChainInfo, 10)
+	for i := range sectors {
+		sectors[i] = &SectorOnChainInfo{SectorNumber: abi.SectorNumber(i)}
+	}
+
+	t.Run("fills the densest deadline before spilling", func(t *testing.T) {
+		assignment, err := assignDeadlines(DeadlineAssignmentPackDensely, maxPartitions, partitionSize, &deadlines, sectors)
+		require.NoError(t, err)
+
+		sectorNumbers := func(sectors []*SectorOnChainInfo) []uint64 {
+			var ret []uint64
+			for _, s := range sectors {
+				ret = append(ret, uint64(s.SectorNumber))
+			}
+			return ret
+		}
+		// The open partition is filled first, then the densest deadline until it reaches the
+		// maximum number of partitions, then the next densest.
+		assert.Equal(t, []uint64{0, 1, 2, 3, 4}, sectorNumbers(assignment[1]))
+		assert.Equal(t, []uint64{5, 6, 7, 8}, sectorNumbers(assignment[2]))
+		assert.Equal(t, []uint64{9}, sectorNumbers(assignment[0]))
+		for _, dlSectors := range assignment[3:] {
+			assert.Empty(t, dlSectors)
+		}
+	})
+
+	t.Run("rejects unknown policy", func(t *testing.T) {
+		_, err := assignDeadlines(DeadlineAssignmentPackDensely+1, maxPartitions, partitionSize, &deadlines, sectors)
+		require.Error(t, err)
+	})
+}
+
 func TestMaxPartitionsPerDeadline(t *testing.T) {
 	const maxPartitions = 5
 	const partitionSize = 5
@@ -192,7 +234,7 @@ func TestMaxPartitionsPerDeadline(t *testing.T) {
 			sectors[i] = &SectorOnChainInfo{SectorNumber: abi.SectorNumber(i)}
 		}
 
-		_, err := assignDeadlines(maxPartitions, partitionSize, &deadlines, sectors)
+		_, err := assignDeadlines(DeadlineAssignmentSpread, maxPartitions, partitionSize, &deadlines, sectors)
 		require.Error(t, err)
 	})
 
@@ -212,7 +254,7 @@ func TestMaxPartitionsPerDeadline(t *testing.T) {
 			sectors[i] = &SectorOnChainInfo{SectorNumber: abi.SectorNumber(i)}
 		}
 
-		deadlineToSectors, err := assignDeadlines(maxPartitions, partitionSize, &deadlines, sectors)
+		deadlineToSectors, err := assignDeadlines(DeadlineAssignmentSpread, maxPartitions, partitionSize, &deadlines, sectors)
 		require.NoError(t, err)
 
 		for _, sectors := range deadlineToSectors {
@@ -236,7 +278,7 @@ func TestMaxPartitionsPerDeadline(t *testing.T) {
 			sectors[i] = &SectorOnChainInfo{SectorNumber: abi.SectorNumber(i)}
 		}
 
-		_, err := assignDeadlines(maxPartitions, partitionSize, &deadlines, sectors)
+		_, err := assignDeadlines(DeadlineAssignmentSpread, maxPartitions, partitionSize, &deadlines, sectors)
 		require.Error(t, err)
 	})
 }
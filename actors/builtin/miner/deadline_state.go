@@ -66,7 +66,7 @@ type Deadline struct {
 	// the current challenge window. At the end of the challenge window,
 	// this AMT will be moved to PoStSubmissionsSnapshot. WindowPoSt proofs
 	// verified on-chain do not appear in this AMT.
-	OptimisticPoStSubmissions cid.Cid // AMT[]WindowedPoSt
+	OptimisticPoStSubmissions cid.Cid `adt:"array,PoStSubmissionsArray,uint64,WindowedPoSt,DeadlineOptimisticPoStSubmissionsAmtBitwidth"` // AMT[]WindowedPoSt
 
 	// Snapshot of partition state at the end of the previous challenge
 	// window for this deadline.
@@ -76,7 +76,7 @@ type Deadline struct {
 	//
 	// These proofs may be disputed via DisputeWindowedPoSt. Successfully
 	// disputed window PoSts are removed from the snapshot.
-	OptimisticPoStSubmissionsSnapshot cid.Cid
+	OptimisticPoStSubmissionsSnapshot cid.Cid `adt:"array,PoStSubmissionsArray,uint64,WindowedPoSt,DeadlineOptimisticPoStSubmissionsAmtBitwidth"`
 }
 
 type WindowedPoSt struct {
@@ -194,10 +194,10 @@ func (d *Deadline) PartitionsArray(store adt.Store) (*adt.Array, error) {
 	return arr, nil
 }
 
-func (d *Deadline) OptimisticProofsArray(store adt.Store) (*adt.Array, error) {
-	arr, err := adt.AsArray(store, d.OptimisticPoStSubmissions, DeadlineOptimisticPoStSubmissionsAmtBitwidth)
+func (d *Deadline) OptimisticProofsArray(store adt.Store) (PoStSubmissionsArray, error) {
+	arr, err := LoadPoStSubmissionsArray(store, d.OptimisticPoStSubmissions)
 	if err != nil {
-		return nil, xerrors.Errorf("failed to load proofs: %w", err)
+		return PoStSubmissionsArray{}, xerrors.Errorf("failed to load proofs: %w", err)
 	}
 	return arr, nil
 }
@@ -210,10 +210,10 @@ func (d *Deadline) PartitionsSnapshotArray(store adt.Store) (*adt.Array, error)
 	return arr, nil
 }
 
-func (d *Deadline) OptimisticProofsSnapshotArray(store adt.Store) (*adt.Array, error) {
-	arr, err := adt.AsArray(store, d.OptimisticPoStSubmissionsSnapshot, DeadlineOptimisticPoStSubmissionsAmtBitwidth)
+func (d *Deadline) OptimisticProofsSnapshotArray(store adt.Store) (PoStSubmissionsArray, error) {
+	arr, err := LoadPoStSubmissionsArray(store, d.OptimisticPoStSubmissionsSnapshot)
 	if err != nil {
-		return nil, xerrors.Errorf("failed to load proofs snapshot: %w", err)
+		return PoStSubmissionsArray{}, xerrors.Errorf("failed to load proofs snapshot: %w", err)
 	}
 	return arr, nil
 }
@@ -250,6 +250,33 @@ func (d *Deadline) LoadPartitionSnapshot(store adt.Store, partIdx uint64) (*Part
 	return &partition, nil
 }
 
+// Replaces the partitions and proofs snapshots with empty arrays, once the snapshotted proofs may
+// no longer be disputed.
+// Returns true if either snapshot was non-empty, i.e. the deadline was modified.
+func (d *Deadline) PruneSnapshots(store adt.Store) (bool, error) {
+	partitionsSnapshot, err := d.PartitionsSnapshotArray(store)
+	if err != nil {
+		return false, err
+	}
+	proofsSnapshot, err := d.OptimisticProofsSnapshotArray(store)
+	if err != nil {
+		return false, err
+	}
+	if partitionsSnapshot.Length() == 0 && proofsSnapshot.Length() == 0 {
+		return false, nil
+	}
+
+	d.PartitionsSnapshot, err = adt.StoreEmptyArray(store, DeadlinePartitionsAmtBitwidth)
+	if err != nil {
+		return false, xerrors.Errorf("failed to clear partitions snapshot: %w", err)
+	}
+	d.OptimisticPoStSubmissionsSnapshot, err = adt.StoreEmptyArray(store, DeadlineOptimisticPoStSubmissionsAmtBitwidth)
+	if err != nil {
+		return false, xerrors.Errorf("failed to clear proofs snapshot: %w", err)
+	}
+	return true, nil
+}
+
 // Adds some partition numbers to the set expiring at an epoch.
 func (d *Deadline) AddExpirationPartitions(store adt.Store, expirationEpoch abi.ChainEpoch, partitions []uint64, quant builtin.QuantSpec) error {
 	// Avoid doing any work if there's nothing to reschedule.
@@ -270,19 +297,23 @@ func (d *Deadline) AddExpirationPartitions(store adt.Store, expirationEpoch abi.
 	return nil
 }
 
-// PopExpiredSectors terminates expired sectors from all partitions.
-// Returns the expired sector aggregates.
-func (dl *Deadline) PopExpiredSectors(store adt.Store, until abi.ChainEpoch, quant builtin.QuantSpec) (*ExpirationSet, error) {
-	expiredPartitions, modified, err := dl.popExpiredPartitions(store, until, quant)
+// PopExpiredSectors terminates expired sectors from up to maxPartitions partitions, in index order.
+// Partitions beyond the limit remain in the expiration queue, to be processed by a subsequent call.
+// Returns the expired sector aggregates, and whether any partitions with expired sectors remain.
+func (dl *Deadline) PopExpiredSectors(store adt.Store, until abi.ChainEpoch, quant builtin.QuantSpec, maxPartitions uint64) (*ExpirationSet, bool, error) {
+	expiredPartitions, hasMore, err := dl.popExpiredPartitions(store, until, quant, maxPartitions)
 	if err != nil {
-		return nil, err
-	} else if !modified {
-		return NewExpirationSetEmpty(), nil // nothing to do.
+		return nil, false, err
+	}
+	if empty, err := expiredPartitions.IsEmpty(); err != nil {
+		return nil, false, err
+	} else if empty {
+		return NewExpirationSetEmpty(), hasMore, nil // nothing to do.
 	}
 
 	partitions, err := dl.PartitionsArray(store)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	var onTimeSectors []bitfield.BitField
@@ -320,11 +351,11 @@ func (dl *Deadline) PopExpiredSectors(store adt.Store, until abi.ChainEpoch, qua
 
 		return partitions.Set(partIdx, &partition)
 	}); err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	if dl.Partitions, err = partitions.Root(); err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// Update early expiration bitmap.
@@ -334,27 +365,27 @@ func (dl *Deadline) PopExpiredSectors(store adt.Store, until abi.ChainEpoch, qua
 
 	allOnTimeSectors, err := bitfield.MultiMerge(onTimeSectors...)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	allEarlySectors, err := bitfield.MultiMerge(earlySectors...)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// Update live sector count.
 	onTimeCount, err := allOnTimeSectors.Count()
 	if err != nil {
-		return nil, xerrors.Errorf("failed to count on-time expired sectors: %w", err)
+		return nil, false, xerrors.Errorf("failed to count on-time expired sectors: %w", err)
 	}
 	earlyCount, err := allEarlySectors.Count()
 	if err != nil {
-		return nil, xerrors.Errorf("failed to count early expired sectors: %w", err)
+		return nil, false, xerrors.Errorf("failed to count early expired sectors: %w", err)
 	}
 	dl.LiveSectors -= onTimeCount + earlyCount
 
 	dl.FaultyPower = dl.FaultyPower.Sub(allFaultyPower)
 
-	return NewExpirationSet(allOnTimeSectors, allEarlySectors, allOnTimePledge, allActivePower, allFaultyPower), nil
+	return NewExpirationSet(allOnTimeSectors, allEarlySectors, allOnTimePledge, allActivePower, allFaultyPower), hasMore, nil
 }
 
 // Adds sectors to a deadline. It's the caller's responsibility to make sure
@@ -543,25 +574,70 @@ func (dl *Deadline) PopEarlyTerminations(store adt.Store, maxPartitions, maxSect
 }
 
 // Returns nil if nothing was popped.
-func (dl *Deadline) popExpiredPartitions(store adt.Store, until abi.ChainEpoch, quant builtin.QuantSpec) (bitfield.BitField, bool, error) {
+func (dl *Deadline) popExpiredPartitions(store adt.Store, until abi.ChainEpoch, quant builtin.QuantSpec, maxPartitions uint64) (bitfield.BitField, bool, error) {
 	expirations, err := LoadBitfieldQueue(store, dl.ExpirationsEpochs, quant, DeadlineExpirationAmtBitwidth)
 	if err != nil {
 		return bitfield.BitField{}, false, err
 	}
 
-	popped, modified, err := expirations.PopUntil(until)
+	var epochs []abi.ChainEpoch
+	var expiring []bitfield.BitField
+	stopErr := xerrors.New("stop")
+	if err = expirations.ForEach(func(epoch abi.ChainEpoch, bf bitfield.BitField) error {
+		if epoch > until {
+			return stopErr
+		}
+		epochs = append(epochs, epoch)
+		expiring = append(expiring, bf)
+		return nil
+	}); err != nil && err != stopErr {
+		return bitfield.BitField{}, false, xerrors.Errorf("failed to iterate expiring partitions: %w", err)
+	}
+
+	// Nothing expired.
+	if len(epochs) == 0 {
+		return bitfield.New(), false, nil
+	}
+
+	popped, err := bitfield.MultiMerge(expiring...)
 	if err != nil {
-		return bitfield.BitField{}, false, xerrors.Errorf("failed to pop expiring partitions: %w", err)
+		return bitfield.BitField{}, false, err
+	}
+	count, err := popped.Count()
+	if err != nil {
+		return bitfield.BitField{}, false, xerrors.Errorf("failed to count expiring partitions: %w", err)
+	}
+	hasMore := count > maxPartitions
+	if hasMore {
+		if popped, err = popped.Slice(0, maxPartitions); err != nil {
+			return bitfield.BitField{}, false, xerrors.Errorf("failed to slice expiring partitions: %w", err)
+		}
 	}
 
-	if modified {
-		dl.ExpirationsEpochs, err = expirations.Root()
+	// Remove the popped partitions from the queue, leaving any others at the epochs they were queued.
+	var emptyEpochs []uint64
+	for i, epoch := range epochs {
+		remaining, err := bitfield.SubtractBitField(expiring[i], popped)
 		if err != nil {
 			return bitfield.BitField{}, false, err
 		}
+		if empty, err := remaining.IsEmpty(); err != nil {
+			return bitfield.BitField{}, false, err
+		} else if empty {
+			emptyEpochs = append(emptyEpochs, uint64(epoch))
+		} else if err = expirations.Set(uint64(epoch), remaining); err != nil {
+			return bitfield.BitField{}, false, xerrors.Errorf("failed to update expiring partitions at epoch %d: %w", epoch, err)
+		}
+	}
+	if err = expirations.BatchDelete(emptyEpochs, true); err != nil {
+		return bitfield.BitField{}, false, xerrors.Errorf("failed to pop expiring partitions: %w", err)
 	}
 
-	return popped, modified, nil
+	dl.ExpirationsEpochs, err = expirations.Root()
+	if err != nil {
+		return bitfield.BitField{}, false, err
+	}
+	return popped, hasMore, nil
 }
 
 func (dl *Deadline) TerminateSectors(
@@ -871,6 +947,41 @@ func (dl *Deadline) DeclareFaultsRecovered(
 	return nil
 }
 
+// Returns the sectors in each of the partitions of a PoSt that are faulty, expiring early at the fault expiration epoch,
+// and not skipped in the PoSt. These are the faults that are recovered automatically by the PoSt, when automatic
+// recovery is enabled.
+func (dl *Deadline) AutomaticRecoveries(
+	store adt.Store, quant builtin.QuantSpec, faultExpiration abi.ChainEpoch, postPartitions []PoStPartition,
+) (PartitionSectorMap, error) {
+	partitions, err := dl.PartitionsArray(store)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveries := make(PartitionSectorMap)
+	for _, post := range postPartitions {
+		var partition Partition
+		if found, err := partitions.Get(post.Index, &partition); err != nil {
+			return nil, xc.ErrIllegalState.Wrapf("failed to load partition %d: %w", post.Index, err)
+		} else if !found {
+			return nil, xc.ErrNotFound.Wrapf("no such partition %d", post.Index)
+		}
+
+		faults, err := partition.FaultsExpiringAt(store, quant, faultExpiration)
+		if err != nil {
+			return nil, xc.ErrIllegalState.Wrapf("failed to load faults of partition %d: %w", post.Index, err)
+		}
+		unskipped, err := bitfield.SubtractBitField(faults, post.Skipped)
+		if err != nil {
+			return nil, xc.ErrIllegalState.Wrapf("failed to subtract skipped sectors of partition %d: %w", post.Index, err)
+		}
+		if err = recoveries.Add(post.Index, unskipped); err != nil {
+			return nil, xc.ErrIllegalArgument.Wrapf("failed to record recoveries of partition %d: %w", post.Index, err)
+		}
+	}
+	return recoveries, nil
+}
+
 // ProcessDeadlineEnd processes all PoSt submissions, marking unproven sectors as
 // faulty and clearing failed recoveries. It returns the power delta, and any
 // power that should be penalized (new faults and failed recoveries).
@@ -1006,7 +1117,7 @@ func (dl *Deadline) RecordProvenSectors(
 	} else if empty, err := alreadyProven.IsEmpty(); err != nil {
 		return nil, xerrors.Errorf("failed to check proven intersection is empty: %w", err)
 	} else if !empty {
-		return nil, xc.ErrIllegalArgument.Wrapf("partition already proven: %v", alreadyProven)
+		return nil, ErrPartitionAlreadyProven.Wrapf("partition already proven: %v", alreadyProven)
 	}
 
 	partitions, err := dl.PartitionsArray(store)
@@ -1110,6 +1221,47 @@ func (dl *Deadline) RecordProvenSectors(
 	}, nil
 }
 
+// ProofSectors returns the sectors against which a proof of the given partitions would be verified
+// if submitted now, declaring no new faults, and the subset of those to be ignored in favour of a stand-in
+// sector because they are terminated, or faulty and not recovering.
+func (dl *Deadline) ProofSectors(store adt.Store, partitionIdxs bitfield.BitField) (sectors, ignored bitfield.BitField, err error) {
+	partitions, err := dl.PartitionsArray(store)
+	if err != nil {
+		return bitfield.BitField{}, bitfield.BitField{}, err
+	}
+
+	var allSectors, allIgnored []bitfield.BitField
+	if err = partitionIdxs.ForEach(func(pIdx uint64) error {
+		var partition Partition
+		if found, err := partitions.Get(pIdx, &partition); err != nil {
+			return xc.ErrIllegalState.Wrapf("failed to load partition %d: %w", pIdx, err)
+		} else if !found {
+			return xc.ErrNotFound.Wrapf("no such partition %d", pIdx)
+		}
+
+		// Recovering sectors are proven, so are not ignored.
+		unrecovered, err := bitfield.SubtractBitField(partition.Faults, partition.Recoveries)
+		if err != nil {
+			return xc.ErrIllegalState.Wrapf("failed to subtract recoveries from faults of partition %d: %w", pIdx, err)
+		}
+		allSectors = append(allSectors, partition.Sectors)
+		allIgnored = append(allIgnored, unrecovered, partition.Terminated)
+		return nil
+	}); err != nil {
+		return bitfield.BitField{}, bitfield.BitField{}, err
+	}
+
+	sectors, err = bitfield.MultiMerge(allSectors...)
+	if err != nil {
+		return bitfield.BitField{}, bitfield.BitField{}, xc.ErrIllegalState.Wrapf("failed to merge all sectors bitfields: %w", err)
+	}
+	ignored, err = bitfield.MultiMerge(allIgnored...)
+	if err != nil {
+		return bitfield.BitField{}, bitfield.BitField{}, xc.ErrIllegalState.Wrapf("failed to merge ignored sectors bitfields: %w", err)
+	}
+	return sectors, ignored, nil
+}
+
 // RecordPoStProofs records a set of optimistically accepted PoSt proofs
 // (usually one), associating them with the given partitions.
 func (dl *Deadline) RecordPoStProofs(store adt.Store, partitions bitfield.BitField, proofs []proof.PoStProof) error {
@@ -1144,8 +1296,8 @@ func (dl *Deadline) TakePoStProofs(store adt.Store, idx uint64) (partitions bitf
 
 	// Extract and remove the proof from the proofs array, leaving a hole.
 	// This will not affect concurrent attempts to refute other proofs.
-	var post WindowedPoSt
-	if found, err := proofArr.Pop(idx, &post); err != nil {
+	post, found, err := proofArr.Pop(idx)
+	if err != nil {
 		return bitfield.New(), nil, xerrors.Errorf("failed to retrieve proof %d: %w", idx, err)
 	} else if !found {
 		return bitfield.New(), nil, xc.ErrIllegalArgument.Wrapf("proof %d not found", idx)
@@ -449,8 +449,9 @@ func TestDeadlines(t *testing.T) {
 		addThenMarkFaulty(t, store, dl, true)
 
 		// We expect all sectors but 7 to have expired at this point.
-		exp, err := dl.PopExpiredSectors(store, 9, quantSpec)
+		exp, more, err := dl.PopExpiredSectors(store, 9, quantSpec, miner.ExpiringPartitionsMax)
 		require.NoError(t, err)
+		assert.False(t, more)
 
 		onTimeExpected := bf(1, 2, 3, 4, 5, 8, 9)
 		earlyExpected := bf(6)
@@ -483,6 +484,56 @@ func TestDeadlines(t *testing.T) {
 			).assert(t, store, dl)
 	})
 
+	t.Run("expired partitions beyond the limit remain queued", func(t *testing.T) {
+		store := ipld.NewADTStore(context.Background())
+
+		dl := emptyDeadline(t, store)
+		// Mark sectors 5 & 6 faulty, expiring at epoch 9.
+		addThenMarkFaulty(t, store, dl, true)
+
+		// The first partition's sectors expire, and the others remain.
+		exp, more, err := dl.PopExpiredSectors(store, 9, quantSpec, 1)
+		require.NoError(t, err)
+		assert.True(t, more)
+		assertBitfieldsEqual(t, bf(1, 2, 3, 4), exp.OnTimeSectors)
+		assertBitfieldsEqual(t, bf(), exp.EarlySectors)
+
+		dlState.withTerminations(1, 2, 3, 4).
+			withFaults(5, 6).
+			withPartitions(
+				bf(1, 2, 3, 4),
+				bf(5, 6, 7, 8),
+				bf(9),
+			).assert(t, store, dl)
+
+		// The remaining partitions expire in subsequent calls.
+		exp, more, err = dl.PopExpiredSectors(store, 9, quantSpec, 1)
+		require.NoError(t, err)
+		assert.True(t, more)
+		assertBitfieldsEqual(t, bf(5, 8), exp.OnTimeSectors)
+		assertBitfieldsEqual(t, bf(6), exp.EarlySectors)
+
+		exp, more, err = dl.PopExpiredSectors(store, 9, quantSpec, 1)
+		require.NoError(t, err)
+		assert.False(t, more)
+		assertBitfieldsEqual(t, bf(9), exp.OnTimeSectors)
+
+		dlState.withTerminations(1, 2, 3, 4, 5, 6, 8, 9).
+			withPartitions(
+				bf(1, 2, 3, 4),
+				bf(5, 6, 7, 8),
+				bf(9),
+			).assert(t, store, dl)
+
+		// Nothing more expires.
+		exp, more, err = dl.PopExpiredSectors(store, 9, quantSpec, 1)
+		require.NoError(t, err)
+		assert.False(t, more)
+		empty, err := exp.IsEmpty()
+		require.NoError(t, err)
+		assert.True(t, empty)
+	})
+
 	t.Run("cannot pop expired sectors before proving", func(t *testing.T) {
 		store := ipld.NewADTStore(context.Background())
 
@@ -491,7 +542,7 @@ func TestDeadlines(t *testing.T) {
 		addSectors(t, store, dl, false)
 
 		// Try to pop some expirations.
-		_, err := dl.PopExpiredSectors(store, 9, quantSpec)
+		_, _, err := dl.PopExpiredSectors(store, 9, quantSpec, miner.ExpiringPartitionsMax)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "cannot pop expired sectors from a partition with unproven sectors")
 	})
@@ -843,8 +894,9 @@ func TestDeadlines(t *testing.T) {
 
 		assert.Len(t, replaced, 1)
 
-		exp, err := dl.PopExpiredSectors(store, 1, quantSpec)
+		exp, more, err := dl.PopExpiredSectors(store, 1, quantSpec, miner.ExpiringPartitionsMax)
 		require.NoError(t, err)
+		assert.False(t, more)
 
 		sector7 := selectSectors(t, sectors, bf(7))[0]
 
@@ -84,6 +84,27 @@ func deadlineAvailableForOptimisticPoStDispute(provingPeriodStart abi.ChainEpoch
 	return !dlInfo.IsOpen() && currentEpoch < (dlInfo.Close-WPoStProvingPeriod)+WPoStDisputeWindow
 }
 
+// Returns true if the snapshot taken at the end of the given deadline's most recent challenge window
+// has been retained for WPoStSnapshotRetention epochs as of the end of the current epoch, and so may be pruned.
+// This is evaluated after any deadline cron in the current epoch, which replaces the deadline's snapshot
+// at the end of its challenge window.
+func deadlineSnapshotExpired(provingPeriodStart abi.ChainEpoch, dlIdx uint64, currentEpoch abi.ChainEpoch) bool {
+	nextEpoch := currentEpoch + 1
+	if provingPeriodStart > nextEpoch {
+		// We haven't started proving yet, there's no snapshot.
+		return false
+	}
+	dlInfo := NewDeadlineInfo(provingPeriodStart, dlIdx, nextEpoch).NextNotElapsed()
+	return nextEpoch >= (dlInfo.Close-WPoStProvingPeriod)+WPoStSnapshotRetention
+}
+
+// Returns the index of the deadline whose snapshot expires at the end of the given deadline, i.e. the
+// deadline that closed WPoStSnapshotRetention epochs earlier, rounded up to a whole number of challenge windows.
+func snapshotExpiringDeadline(dlIdx uint64) uint64 {
+	retentionDeadlines := uint64((WPoStSnapshotRetention + WPoStChallengeWindow - 1) / WPoStChallengeWindow)
+	return (dlIdx + WPoStPeriodDeadlines - retentionDeadlines) % WPoStPeriodDeadlines
+}
+
 // Returns true if the given deadline may compacted in the current epoch.
 // Deadlines may not be compacted when:
 //
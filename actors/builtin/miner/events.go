@@ -0,0 +1,31 @@
+package miner
+
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+// Types of the events emitted by the miner actor.
+const (
+	// Pre-commit deposits are released, the pre-commitments having expired without being proven, or their sectors
+	// having been proven too late to be activated. The payload is a PreCommitDepositsReleasedEvent.
+	EventPreCommitDepositsReleased = "precommit-deposits-released"
+)
+
+// The release of a single pre-commitment's deposit, split between the amount burned and the amount refunded
+// to the miner's available balance.
+type PreCommitDepositRelease struct {
+	SectorNumber abi.SectorNumber
+	Burned       abi.TokenAmount
+	Refunded     abi.TokenAmount
+}
+
+type PreCommitDepositsReleasedEvent struct {
+	Releases []PreCommitDepositRelease
+}
+
+func emitPreCommitDepositsReleased(rt Runtime, releases []PreCommitDepositRelease) {
+	if len(releases) == 0 {
+		return
+	}
+	rt.EmitEvent(EventPreCommitDepositsReleased, &PreCommitDepositsReleasedEvent{Releases: releases})
+}
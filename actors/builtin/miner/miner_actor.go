@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"sort"
 
 	addr "github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-bitfield"
@@ -38,7 +39,9 @@ type Runtime = runtime.Runtime
 const (
 	// The first 1000 actor-specific codes are left open for user error, i.e. things that might
 	// actually happen without programming error in the actor code.
-	//ErrToBeDetermined = exitcode.FirstActorSpecificExitCode + iota
+
+	// A Window PoSt included a partition that has already been proven in the current challenge window.
+	ErrPartitionAlreadyProven = exitcode.FirstActorSpecificExitCode + iota
 
 	// The following errors are particular cases of illegal state.
 	// They're not expected to ever happen, but if they do, distinguished codes can help us
@@ -76,6 +79,30 @@ func (a Actor) Exports() []interface{} {
 		24:                        a.DisputeWindowedPoSt,
 		25:                        a.PreCommitSectorBatch,
 		26:                        a.ProveCommitAggregate,
+		27:                        a.GetBalanceBreakdown,
+		28:                        a.WithdrawBalanceTo,
+		29:                        a.ReportConsensusFaults,
+		30:                        a.UpgradeWindowPoStProofType,
+		31:                        a.DeadlinePostStatus,
+		32:                        a.PruneExpiredProofs,
+		33:                        a.WindowPoStChallenge,
+		34:                        a.FaultExpirations,
+		35:                        a.ChangeAddresses,
+		36:                        a.CancelWorkerKeyChange,
+		37:                        a.ExpirationSchedule,
+		38:                        a.DeclareFaultsWithReasons,
+		39:                        a.BatchLimits,
+		40:                        a.ChangeDeadlineAssignment,
+		41:                        a.AttestSectorPieces,
+		42:                        a.LockFunds,
+		43:                        a.GetReliabilityStats,
+		44:                        a.GetSectorRegions,
+		45:                        a.ChangeNotificationTarget,
+		46:                        a.ProvingPeriodStatus,
+		47:                        a.EarlyTerminations,
+		48:                        a.ProcessEarlyTerminations,
+		49:                        a.ProjectSectorPledge,
+		50:                        a.DesignatePreCommitAggregator,
 	}
 }
 
@@ -116,8 +143,16 @@ func (a Actor) Constructor(rt Runtime, params *ConstructorParams) *abi.EmptyValu
 	}
 
 	currEpoch := rt.CurrEpoch()
-	offset, err := assignProvingPeriodOffset(rt.Receiver(), currEpoch, rt.HashBlake2b)
-	builtin.RequireNoErr(rt, err, exitcode.ErrSerialization, "failed to assign proving period offset")
+	offset := params.ProvingPeriodOffset
+	if params.RequestProvingPeriodOffset {
+		if offset < 0 || offset >= WPoStProvingPeriod {
+			rt.Abortf(exitcode.ErrIllegalArgument, "proving period offset %d out of range [0, %d)", offset, WPoStProvingPeriod)
+		}
+	} else {
+		var err error
+		offset, err = assignProvingPeriodOffset(rt.Receiver(), currEpoch, rt.HashBlake2b)
+		builtin.RequireNoErr(rt, err, exitcode.ErrSerialization, "failed to assign proving period offset")
+	}
 	periodStart := currentProvingPeriodStart(currEpoch, offset)
 	builtin.RequireState(rt, periodStart <= currEpoch, "computed proving period start %d after current epoch %d", periodStart, currEpoch)
 	deadlineIndex := currentDeadlineIndex(currEpoch, periodStart)
@@ -218,6 +253,30 @@ func (a Actor) ConfirmUpdateWorkerKey(rt Runtime, params *abi.EmptyValue) *abi.E
 	return nil
 }
 
+// Cancels a pending worker address change before its effective epoch.
+func (a Actor) CancelWorkerKeyChange(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
+	var st State
+	rt.StateTransaction(&st, func() {
+		info := getMinerInfo(rt, &st)
+
+		// Only the Owner is allowed to cancel a worker change.
+		rt.ValidateImmediateCallerIs(info.Owner)
+
+		if info.PendingWorkerKey == nil {
+			rt.Abortf(exitcode.ErrIllegalArgument, "no pending worker key change")
+		}
+		if rt.CurrEpoch() >= info.PendingWorkerKey.EffectiveAt {
+			rt.Abortf(exitcode.ErrIllegalArgument, "worker key change already effective at %d", info.PendingWorkerKey.EffectiveAt)
+		}
+		info.PendingWorkerKey = nil
+
+		err := st.SaveInfo(adt.AsStore(rt), info)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "could not save miner info")
+	})
+
+	return nil
+}
+
 // Proposes or confirms a change of owner address.
 // If invoked by the current owner, proposes a new owner address for confirmation. If the proposed address is the
 // current owner address, revokes any existing proposal.
@@ -259,11 +318,68 @@ func (a Actor) ChangeOwnerAddress(rt Runtime, newAddress *addr.Address) *abi.Emp
 	return nil
 }
 
+type ChangeAddressesParams struct {
+	NewOwner        addr.Address
+	NewWorker       addr.Address
+	NewControlAddrs []addr.Address
+}
+
+// Changes the owner, worker and control addresses in a single message, as when transferring control of the miner.
+// The owner change is proposed as by ChangeOwnerAddress, and takes effect only once the new owner confirms it
+// with ChangeOwnerAddress. The worker change is scheduled as by ChangeWorkerAddress, taking effect after
+// WorkerKeyChangeDelay once confirmed with ConfirmUpdateWorkerKey. The control addresses are overwritten immediately.
+// Only the current owner may invoke this method. Either all changes are made, or none.
+func (a Actor) ChangeAddresses(rt Runtime, params *ChangeAddressesParams) *abi.EmptyValue {
+	if params.NewOwner.Empty() {
+		rt.Abortf(exitcode.ErrIllegalArgument, "empty owner address")
+	}
+	if params.NewOwner.Protocol() != addr.ID {
+		rt.Abortf(exitcode.ErrIllegalArgument, "owner address must be an ID address")
+	}
+	checkControlAddresses(rt, params.NewControlAddrs)
+
+	newWorker := resolveWorkerAddress(rt, params.NewWorker)
+
+	var controlAddrs []addr.Address
+	for _, ca := range params.NewControlAddrs {
+		resolved := resolveControlAddress(rt, ca)
+		controlAddrs = append(controlAddrs, resolved)
+	}
+
+	var st State
+	rt.StateTransaction(&st, func() {
+		info := getMinerInfo(rt, &st)
+
+		// Only the Owner is allowed to change any of the addresses.
+		rt.ValidateImmediateCallerIs(info.Owner)
+
+		// Propose the new owner, revoking any existing proposal if it is the current owner.
+		info.PendingOwnerAddress = &params.NewOwner
+		if *info.PendingOwnerAddress == info.Owner {
+			info.PendingOwnerAddress = nil
+		}
+
+		info.ControlAddresses = controlAddrs
+
+		if newWorker != info.Worker && info.PendingWorkerKey == nil {
+			info.PendingWorkerKey = &WorkerKeyChange{
+				NewWorker:   newWorker,
+				EffectiveAt: rt.CurrEpoch() + WorkerKeyChangeDelay,
+			}
+		}
+
+		err := st.SaveInfo(adt.AsStore(rt), info)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "could not save miner info")
+	})
+	return nil
+}
+
 //type ChangePeerIDParams struct {
 //	NewID abi.PeerID
 //}
 type ChangePeerIDParams = miner0.ChangePeerIDParams
 
+// Changes the libp2p peer ID of the miner, which must be well formed unless empty.
 func (a Actor) ChangePeerID(rt Runtime, params *ChangePeerIDParams) *abi.EmptyValue {
 	checkPeerInfo(rt, params.NewID, nil)
 
@@ -285,6 +401,7 @@ func (a Actor) ChangePeerID(rt Runtime, params *ChangePeerIDParams) *abi.EmptyVa
 //}
 type ChangeMultiaddrsParams = miner0.ChangeMultiaddrsParams
 
+// Changes the multiaddrs at which the miner may be reached, each of which must be a well formed binary multiaddr.
 func (a Actor) ChangeMultiaddrs(rt Runtime, params *ChangeMultiaddrsParams) *abi.EmptyValue {
 	checkPeerInfo(rt, nil, params.NewMultiaddrs)
 
@@ -301,6 +418,122 @@ func (a Actor) ChangeMultiaddrs(rt Runtime, params *ChangeMultiaddrsParams) *abi
 	return nil
 }
 
+type ChangeDeadlineAssignmentParams struct {
+	NewPolicy DeadlineAssignmentPolicy
+}
+
+// Changes the policy by which newly proven sectors are assigned to deadlines.
+// Sectors already assigned to deadlines are not moved.
+func (a Actor) ChangeDeadlineAssignment(rt Runtime, params *ChangeDeadlineAssignmentParams) *abi.EmptyValue {
+	_, err := params.NewPolicy.strategy()
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid deadline assignment policy")
+
+	var st State
+	rt.StateTransaction(&st, func() {
+		info := getMinerInfo(rt, &st)
+
+		rt.ValidateImmediateCallerIs(append(info.ControlAddresses, info.Owner, info.Worker)...)
+
+		info.DeadlineAssignment = params.NewPolicy
+		err := st.SaveInfo(adt.AsStore(rt), info)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "could not save miner info")
+	})
+	return nil
+}
+
+type ChangeNotificationTargetParams struct {
+	NewTarget *addr.Address // Must be an ID address, or nil to remove the target
+}
+
+// Sets the address notified when pre-commits expire or sectors are terminated by cron.
+// The target is sent NotificationMethod with NotificationParams, e.g. so that operator automation can react on-chain.
+func (a Actor) ChangeNotificationTarget(rt Runtime, params *ChangeNotificationTargetParams) *abi.EmptyValue {
+	if params.NewTarget != nil && params.NewTarget.Protocol() != addr.ID {
+		rt.Abortf(exitcode.ErrIllegalArgument, "notification target must be an ID address")
+	}
+
+	var st State
+	rt.StateTransaction(&st, func() {
+		info := getMinerInfo(rt, &st)
+		rt.ValidateImmediateCallerIs(info.Owner)
+
+		info.NotificationTarget = params.NewTarget
+		err := st.SaveInfo(adt.AsStore(rt), info)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "could not save miner info")
+	})
+	return nil
+}
+
+type UpgradeWindowPoStProofTypeParams struct {
+	NewProofType abi.RegisteredPoStProof
+}
+
+// Changes the Window PoSt proof type registered for the miner, allowing it to move to a newer proof version
+// without creating a new actor.
+// A proof type with a different sector size or partition size may only be adopted by a miner with no
+// partitions and no pre-committed sectors. Otherwise, the miner must have no faulty sectors in any deadline,
+// so that all partitions may be proven with the new proof type from the next deadline.
+func (a Actor) UpgradeWindowPoStProofType(rt Runtime, params *UpgradeWindowPoStProofTypeParams) *abi.EmptyValue {
+	if !CanWindowPoStProof(params.NewProofType) {
+		rt.Abortf(exitcode.ErrIllegalArgument, "unsupported window post proof type %d", params.NewProofType)
+	}
+	newSectorSize, err := params.NewProofType.SectorSize()
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid sector size for proof type %d", params.NewProofType)
+	newPartitionSectors, err := builtin.PoStProofWindowPoStPartitionSectors(params.NewProofType)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid partition sectors for proof type %d", params.NewProofType)
+
+	var st State
+	rt.StateTransaction(&st, func() {
+		info := getMinerInfo(rt, &st)
+		rt.ValidateImmediateCallerIs(info.Owner, info.Worker)
+
+		if params.NewProofType == info.WindowPoStProofType {
+			rt.Abortf(exitcode.ErrIllegalArgument, "window post proof type %d already registered", params.NewProofType)
+		}
+
+		store := adt.AsStore(rt)
+		deadlines, err := st.LoadDeadlines(store)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadlines")
+
+		geometryChanged := newSectorSize != info.SectorSize || newPartitionSectors != info.WindowPoStPartitionSectors
+		if geometryChanged && !st.PreCommitDeposits.IsZero() {
+			rt.Abortf(exitcode.ErrForbidden, "cannot change sector size or partition size with pre-committed sectors")
+		}
+		err = deadlines.ForEach(store, func(dlIdx uint64, dl *Deadline) error {
+			if geometryChanged {
+				partitions, err := dl.PartitionsArray(store)
+				if err != nil {
+					return err
+				}
+				if partitions.Length() > 0 {
+					return exitcode.ErrForbidden.Wrapf("cannot change sector size or partition size with partitions in deadline %d", dlIdx)
+				}
+			}
+			if !dl.FaultyPower.IsZero() {
+				return exitcode.ErrForbidden.Wrapf("cannot change proof type with faulty power in deadline %d", dlIdx)
+			}
+			return nil
+		})
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check deadlines")
+
+		info.WindowPoStProofType = params.NewProofType
+		info.SectorSize = newSectorSize
+		info.WindowPoStPartitionSectors = newPartitionSectors
+		err = st.SaveInfo(store, info)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "could not save miner info")
+	})
+
+	code := rt.Send(
+		builtin.StoragePowerActorAddr,
+		builtin.MethodsPower.UpdateClaimedProofType,
+		&power.UpdateClaimedProofTypeParams{WindowPoStProofType: params.NewProofType},
+		abi.NewTokenAmount(0),
+		&builtin.Discard{},
+	)
+	builtin.RequireSuccess(rt, code, "failed to update claimed proof type")
+	return nil
+}
+
 //////////////////
 // WindowedPoSt //
 //////////////////
@@ -337,72 +570,18 @@ func (a Actor) SubmitWindowedPoSt(rt Runtime, params *SubmitWindowedPoStParams)
 	store := adt.AsStore(rt)
 	var st State
 
-	// Verify that the miner has passed exactly 1 proof.
-	if len(params.Proofs) != 1 {
-		rt.Abortf(exitcode.ErrIllegalArgument, "expected exactly one proof, got %d", len(params.Proofs))
-	}
-
-	if !CanWindowPoStProof(params.Proofs[0].PoStProof) {
-		rt.Abortf(exitcode.ErrIllegalArgument, "proof type %d not allowed", params.Proofs[0].PoStProof)
-	}
-
-	if params.Deadline >= WPoStPeriodDeadlines {
-		rt.Abortf(exitcode.ErrIllegalArgument, "invalid deadline %d of %d", params.Deadline, WPoStPeriodDeadlines)
-	}
-	// Technically, ChainCommitRand should be _exactly_ 32 bytes. However:
-	// 1. It's convenient to allow smaller slices when testing.
-	// 2. Nothing bad will happen if the caller provides too little randomness.
-	if len(params.ChainCommitRand) > abi.RandomnessLength {
-		rt.Abortf(exitcode.ErrIllegalArgument, "expected at most %d bytes of randomness, got %d", abi.RandomnessLength, len(params.ChainCommitRand))
-	}
+	err := validateWindowPoStParams(params)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid window post submission")
 
 	var postResult *PoStResult
 	var info *MinerInfo
 	rt.StateTransaction(&st, func() {
 		info = getMinerInfo(rt, &st)
-		maxProofSize, err := info.WindowPoStProofType.ProofSize()
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to determine max window post proof size")
-
 		rt.ValidateImmediateCallerIs(append(info.ControlAddresses, info.Owner, info.Worker)...)
 
-		// Make sure the miner is using the correct proof type.
-		if params.Proofs[0].PoStProof != info.WindowPoStProofType {
-			rt.Abortf(exitcode.ErrIllegalArgument, "expected proof of type %d, got proof of type %d", info.WindowPoStProofType, params.Proofs[0])
-		}
-
-		// Make sure the proof size doesn't exceed the max. We could probably check for an exact match, but this is safer.
-		if maxSize := maxProofSize * uint64(len(params.Partitions)); uint64(len(params.Proofs[0].ProofBytes)) > maxSize {
-			rt.Abortf(exitcode.ErrIllegalArgument, "expected proof to be smaller than %d bytes", maxSize)
-		}
-
-		// Validate that the miner didn't try to prove too many partitions at once.
-		submissionPartitionLimit := loadPartitionsSectorsMax(info.WindowPoStPartitionSectors)
-		if uint64(len(params.Partitions)) > submissionPartitionLimit {
-			rt.Abortf(exitcode.ErrIllegalArgument, "too many partitions %d, limit %d", len(params.Partitions), submissionPartitionLimit)
-		}
-
-		currDeadline := st.DeadlineInfo(currEpoch)
-		// Check that the miner state indicates that the current proving deadline has started.
-		// This should only fail if the cron actor wasn't invoked, and matters only in case that it hasn't been
-		// invoked for a whole proving period, and hence the missed PoSt submissions from the prior occurrence
-		// of this deadline haven't been processed yet.
-		if !currDeadline.IsOpen() {
-			rt.Abortf(exitcode.ErrIllegalState, "proving period %d not yet open at %d", currDeadline.PeriodStart, currEpoch)
-		}
+		currDeadline, err := validateWindowPoStForDeadline(&st, info, params, currEpoch)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid window post submission")
 
-		// The miner may only submit a proof for the current deadline.
-		if params.Deadline != currDeadline.Index {
-			rt.Abortf(exitcode.ErrIllegalArgument, "invalid deadline %d at epoch %d, expected %d",
-				params.Deadline, currEpoch, currDeadline.Index)
-		}
-
-		// Verify that the PoSt was committed to the chain at most WPoStChallengeLookback+WPoStChallengeWindow in the past.
-		if params.ChainCommitEpoch < currDeadline.Challenge {
-			rt.Abortf(exitcode.ErrIllegalArgument, "expected chain commit epoch %d to be after %d", params.ChainCommitEpoch, currDeadline.Challenge)
-		}
-		if params.ChainCommitEpoch >= currEpoch {
-			rt.Abortf(exitcode.ErrIllegalArgument, "chain commit epoch %d must be less than the current epoch %d", params.ChainCommitEpoch, currEpoch)
-		}
 		// Verify the chain commit randomness.
 		commRand := rt.GetRandomnessFromTickets(crypto.DomainSeparationTag_PoStChainCommit, params.ChainCommitEpoch, nil)
 		if !bytes.Equal(commRand, params.ChainCommitRand) {
@@ -428,6 +607,17 @@ func (a Actor) SubmitWindowedPoSt(rt Runtime, params *SubmitWindowedPoStParams)
 		// While we could perform _all_ operations at the end of challenge window, we do as we can here to avoid
 		// overloading cron.
 		faultExpiration := currDeadline.Last() + FaultMaxAge
+
+		// Sectors that became faulty at this deadline's previous occurrence and are not skipped are recovered by
+		// this proof, if automatic recovery is enabled and a recovery declaration would be permitted.
+		if AutomaticFaultRecovery && st.FeeDebt.IsZero() && !ConsensusFaultActive(info, currEpoch) {
+			recoveries, err := deadline.AutomaticRecoveries(store, QuantSpecForDeadline(currDeadline), faultExpiration-WPoStProvingPeriod, params.Partitions)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to determine automatic recoveries for deadline %d", params.Deadline)
+
+			err = deadline.DeclareFaultsRecovered(store, sectors, info.SectorSize, recoveries)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to declare automatic recoveries for deadline %d", params.Deadline)
+		}
+
 		postResult, err = deadline.RecordProvenSectors(store, sectors, info.SectorSize, QuantSpecForDeadline(currDeadline), faultExpiration, params.Partitions)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to process post submission for deadline %d", params.Deadline)
 
@@ -464,6 +654,8 @@ func (a Actor) SubmitWindowedPoSt(rt Runtime, params *SubmitWindowedPoStParams)
 
 		err = st.SaveDeadlines(store, deadlines)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to save deadlines")
+
+		st.RecordPoStSubmitted(currEpoch)
 	})
 
 	// Restore power for recovered sectors. Remove power for new faults.
@@ -473,7 +665,7 @@ func (a Actor) SubmitWindowedPoSt(rt Runtime, params *SubmitWindowedPoStParams)
 	requestUpdatePower(rt, postResult.PowerDelta)
 
 	rt.StateReadonly(&st)
-	err := st.CheckBalanceInvariants(rt.CurrentBalance())
+	err = st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
 
 	return nil
@@ -617,7 +809,7 @@ func (a Actor) DisputeWindowedPoSt(rt Runtime, params *DisputeWindowedPoStParams
 
 	if !toReward.IsZero() {
 		// Try to send the reward to the reporter.
-		code := rt.Send(reporter, builtin.MethodSend, nil, toReward, &builtin.Discard{})
+		code := builtin.TransferFunds(rt, reporter, toReward, builtin.TransferReporterReward)
 
 		// If we fail, log and burn the reward to make sure the balances remain correct.
 		if !code.IsSuccess() {
@@ -625,7 +817,7 @@ func (a Actor) DisputeWindowedPoSt(rt Runtime, params *DisputeWindowedPoStParams
 			toBurn = big.Add(toBurn, toReward)
 		}
 	}
-	burnFunds(rt, toBurn)
+	builtin.BurnPenalty(rt, toBurn)
 	notifyPledgeChanged(rt, pledgeDelta)
 	rt.StateReadonly(&st)
 
@@ -634,6 +826,74 @@ func (a Actor) DisputeWindowedPoSt(rt Runtime, params *DisputeWindowedPoStParams
 	return nil
 }
 
+// Prunes the Window PoSt partitions and proofs snapshots of deadlines whose proofs may no longer be disputed,
+// paying the caller a reward from the miner's available balance for each deadline pruned.
+// Deadline cron prunes each snapshot as it expires, so this is needed only when cron has not kept up.
+// Only snapshots that expired before the current epoch are pruned, so that disputes in this epoch are unaffected.
+func (a Actor) PruneExpiredProofs(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
+	reporter := rt.Caller()
+
+	var st State
+	toReward := big.Zero()
+	rt.StateTransaction(&st, func() {
+		pruned, err := st.PruneDeadlineSnapshots(adt.AsStore(rt), rt.CurrEpoch()-1)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to prune deadline snapshots")
+		if len(pruned) == 0 {
+			rt.Abortf(exitcode.ErrIllegalArgument, "no expired proofs to prune")
+		}
+
+		// The reward is limited to the available balance, so it never draws on locked funds.
+		available, err := st.GetAvailableBalance(rt.CurrentBalance())
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to calculate available balance")
+		toReward = big.Min(RewardForPrunedProofs(len(pruned)), big.Max(available, big.Zero()))
+	})
+
+	if !toReward.IsZero() {
+		code := builtin.TransferFunds(rt, reporter, toReward, builtin.TransferReporterReward)
+		builtin.RequireSuccess(rt, code, "failed to send reward")
+	}
+
+	err := st.CheckBalanceInvariants(rt.CurrentBalance())
+	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
+	return nil
+}
+
+// Processes a batch of the miner's early terminations deferred for cron, paying the caller a reward from the
+// miner's available balance.
+// Terminations beyond those TerminateSectors can process immediately are otherwise settled by cron a batch at a
+// time, so this allows them to be settled sooner when cron capacity is the constraint.
+func (a Actor) ProcessEarlyTerminations(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
+	reporter := rt.Caller()
+
+	var st State
+	rt.StateReadonly(&st)
+	if !havePendingEarlyTerminations(rt, &st) {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no early terminations to process")
+	}
+
+	// This does the work of the scheduled cron event, which remains scheduled to process any that remain.
+	processEarlyTerminations(rt, true)
+
+	toReward := big.Zero()
+	rt.StateTransaction(&st, func() {
+		// The reward is limited to the available balance, so it never draws on locked funds.
+		available, err := st.GetAvailableBalance(rt.CurrentBalance())
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to calculate available balance")
+		toReward = big.Min(RewardForProcessedEarlyTerminations, big.Max(available, big.Zero()))
+	})
+
+	if !toReward.IsZero() {
+		code := builtin.TransferFunds(rt, reporter, toReward, builtin.TransferReporterReward)
+		builtin.RequireSuccess(rt, code, "failed to send reward")
+	}
+
+	err := st.CheckBalanceInvariants(rt.CurrentBalance())
+	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
+	return nil
+}
+
 ///////////////////////
 // Sector Commitment //
 ///////////////////////
@@ -665,6 +925,7 @@ func (a Actor) PreCommitSector(rt Runtime, params *PreCommitSectorParams) *abi.E
 
 type PreCommitSectorBatchParams struct {
 	Sectors []miner0.SectorPreCommitInfo
+	Region  string // Code of the region in which the sectors are stored, or empty if not declared
 }
 
 // Pledges the miner to seal and commit some new sectors.
@@ -675,6 +936,7 @@ type PreCommitSectorBatchParams struct {
 // when proven.
 // This method calculates the sector's power, locks a pre-commit deposit for the sector, stores information about the
 // sector in state and waits for it to be proven or expire.
+// The miner may declare the region in which the sectors are stored, which is recorded for clients to query.
 func (a Actor) PreCommitSectorBatch(rt Runtime, params *PreCommitSectorBatchParams) *abi.EmptyValue {
 	currEpoch := rt.CurrEpoch()
 	if len(params.Sectors) == 0 {
@@ -682,6 +944,9 @@ func (a Actor) PreCommitSectorBatch(rt Runtime, params *PreCommitSectorBatchPara
 	} else if len(params.Sectors) > PreCommitSectorBatchMaxSize {
 		rt.Abortf(exitcode.ErrIllegalArgument, "batch of %d too large, max %d", len(params.Sectors), PreCommitSectorBatchMaxSize)
 	}
+	if len(params.Region) > MaxSectorRegionLength {
+		rt.Abortf(exitcode.ErrIllegalArgument, "region of length %d too long, max %d", len(params.Region), MaxSectorRegionLength)
+	}
 
 	// Check per-sector preconditions before opening state transaction or sending other messages.
 	challengeEarliest := currEpoch - MaxPreCommitRandomnessLookback
@@ -739,6 +1004,7 @@ func (a Actor) PreCommitSectorBatch(rt Runtime, params *PreCommitSectorBatchPara
 	// gather information from other actors
 	rewardStats := requestCurrentEpochBlockReward(rt)
 	pwrTotal := requestCurrentTotalPower(rt)
+	validateDealEndEpochs(rt, params.Sectors)
 	dealWeights := requestDealWeights(rt, sectorsDeals)
 
 	if len(dealWeights.Sectors) != len(params.Sectors) {
@@ -832,6 +1098,15 @@ func (a Actor) PreCommitSectorBatch(rt Runtime, params *PreCommitSectorBatchPara
 		err = st.AllocateSectorNumbers(store, sectorNumbers, DenyCollisions)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to allocate sector ids %v", sectorNumbers)
 
+		if params.Region != "" {
+			err = st.RecordSectorRegion(store, params.Region, sectorNumbers)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to record region of sectors")
+		}
+
+		// Mask unused sector numbers if sparse allocation has made the allocated set large.
+		_, err = st.CompactAllocatedSectorNumbers(store, AllocatedSectorsCompactionThreshold, AllocatedSectorsCompactionMaxGaps)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to compact allocated sector numbers")
+
 		err = st.PutPrecommittedSectors(store, chainInfos...)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to write pre-committed sectors")
 
@@ -843,7 +1118,7 @@ func (a Actor) PreCommitSectorBatch(rt Runtime, params *PreCommitSectorBatchPara
 		st.DeadlineCronActive = true
 	})
 
-	burnFunds(rt, feeToBurn)
+	builtin.BurnPenalty(rt, feeToBurn)
 	rt.StateReadonly(&st)
 	err = st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
@@ -857,6 +1132,105 @@ func (a Actor) PreCommitSectorBatch(rt Runtime, params *PreCommitSectorBatchPara
 	return nil
 }
 
+// A piece of data held by a sector, as attested by the miner.
+type AttestedPiece struct {
+	Size     abi.PaddedPieceSize
+	PieceCID cid.Cid `checked:"true"` // CommP, checked in AttestSectorPieces
+}
+
+type AttestSectorPiecesParams struct {
+	SectorNumber abi.SectorNumber
+	Pieces       []AttestedPiece
+}
+
+// Attests the pieces of data held by a pre-committed sector without deals, so that data onboarded without
+// payments is publicly attested.
+// The sector's unsealed CID (CommD) is computed from the pieces, in place of its deals, when the sector is proven,
+// so the seal proof commits to the pieces. The pieces are recorded in the sector's on-chain info on activation.
+// Pieces must be attested no later than the epoch from which the sector's interactive seal challenge is drawn,
+// before any proof of the sector can be submitted.
+func (a Actor) AttestSectorPieces(rt Runtime, params *AttestSectorPiecesParams) *abi.EmptyValue {
+	if len(params.Pieces) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no pieces to attest for sector %d", params.SectorNumber)
+	}
+	pieces := make([]abi.PieceInfo, len(params.Pieces))
+	for i, piece := range params.Pieces {
+		if !piece.PieceCID.Defined() || piece.PieceCID.Prefix() != market.PieceCIDPrefix {
+			rt.Abortf(exitcode.ErrIllegalArgument, "invalid piece CID %v for sector %d", piece.PieceCID, params.SectorNumber)
+		}
+		pieces[i] = abi.PieceInfo{Size: piece.Size, PieceCID: piece.PieceCID}
+	}
+
+	store := adt.AsStore(rt)
+	var st State
+	rt.StateReadonly(&st)
+	info := getMinerInfo(rt, &st)
+	rt.ValidateImmediateCallerIs(append(info.ControlAddresses, info.Owner, info.Worker)...)
+
+	precommit, found, err := st.GetPrecommittedSector(store, params.SectorNumber)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load pre-committed sector %d", params.SectorNumber)
+	if !found {
+		rt.Abortf(exitcode.ErrNotFound, "no pre-committed sector %d", params.SectorNumber)
+	}
+	if len(precommit.Info.DealIDs) > 0 {
+		rt.Abortf(exitcode.ErrForbidden, "cannot attest pieces of sector %d with deals", params.SectorNumber)
+	}
+	if precommit.Pieces != nil {
+		rt.Abortf(exitcode.ErrForbidden, "pieces of sector %d already attested", params.SectorNumber)
+	}
+	if interactiveEpoch := precommit.PreCommitEpoch + PreCommitChallengeDelay; rt.CurrEpoch() > interactiveEpoch {
+		rt.Abortf(exitcode.ErrForbidden, "too late to attest pieces of sector %d at %d, must be no later than %d",
+			params.SectorNumber, rt.CurrEpoch(), interactiveEpoch)
+	}
+	if uint64(len(params.Pieces)) > SectorDealsMax(info.SectorSize) {
+		rt.Abortf(exitcode.ErrIllegalArgument, "too many pieces for sector %d, %d > %d",
+			params.SectorNumber, len(params.Pieces), SectorDealsMax(info.SectorSize))
+	}
+	// Fails if the pieces are malformed or don't fit in the sector.
+	_, err = rt.ComputeUnsealedSectorCID(precommit.Info.SealProof, pieces)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid pieces for sector %d", params.SectorNumber)
+
+	rt.StateTransaction(&st, func() {
+		err := st.SetPrecommittedSectorPieces(store, params.SectorNumber, pieces)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to attest pieces of sector %d", params.SectorNumber)
+	})
+	return nil
+}
+
+type DesignatePreCommitAggregatorParams struct {
+	SectorNumbers bitfield.BitField
+	Aggregator    addr.Address
+}
+
+// Designates an address, such as a proof aggregation service, to prove some pre-committed sectors with
+// ProveCommitAggregate on the miner's behalf, without holding the worker key.
+// The aggregator may only prove sectors for which all those in its aggregate designate it.
+// A later designation for a sector replaces the earlier one.
+func (a Actor) DesignatePreCommitAggregator(rt Runtime, params *DesignatePreCommitAggregatorParams) *abi.EmptyValue {
+	count, err := params.SectorNumbers.Count()
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to count sectors")
+	if count == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no sectors to designate an aggregator for")
+	} else if count > MaxAggregatedSectors {
+		rt.Abortf(exitcode.ErrIllegalArgument, "too many sectors addressed, addressed %d want <= %d", count, MaxAggregatedSectors)
+	}
+	aggregator, ok := rt.ResolveAddress(params.Aggregator)
+	if !ok {
+		rt.Abortf(exitcode.ErrIllegalArgument, "unable to resolve aggregator address %v", params.Aggregator)
+	}
+
+	store := adt.AsStore(rt)
+	var st State
+	rt.StateTransaction(&st, func() {
+		info := getMinerInfo(rt, &st)
+		rt.ValidateImmediateCallerIs(append(info.ControlAddresses, info.Owner, info.Worker)...)
+
+		err := st.DesignatePreCommitAggregator(store, aggregator, params.SectorNumbers)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to designate aggregator %v", aggregator)
+	})
+	return nil
+}
+
 type ProveCommitAggregateParams struct {
 	SectorNumbers  bitfield.BitField
 	AggregateProof []byte
@@ -865,7 +1239,8 @@ type ProveCommitAggregateParams struct {
 // Checks state of the corresponding sector pre-commitments and verifies aggregate proof of replication
 // of these sectors. If valid, the sectors' deals are activated, sectors are assigned a deadline and charged pledge
 // and precommit state is removed.
-func (a Actor) ProveCommitAggregate(rt Runtime, params *ProveCommitAggregateParams) *abi.EmptyValue {
+// The caller must be the owner, worker or a control address, or the aggregator designated for all the sectors.
+func (a Actor) ProveCommitAggregate(rt Runtime, params *ProveCommitAggregateParams) *ConfirmSectorProofsValidReturn {
 	rt.ValidateImmediateCallerAcceptAny()
 	aggSectorsCount, err := params.SectorNumbers.Count()
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to count aggregated sectors")
@@ -884,20 +1259,33 @@ func (a Actor) ProveCommitAggregate(rt Runtime, params *ProveCommitAggregatePara
 	var st State
 	rt.StateReadonly(&st)
 
+	info := getMinerInfo(rt, &st)
+	if !isControlAddress(info, rt.Caller()) {
+		designated, err := st.IsPreCommitAggregator(store, rt.Caller(), params.SectorNumbers)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load pre-commit aggregators")
+		if !designated {
+			rt.Abortf(exitcode.ErrForbidden, "caller %v is not the designated aggregator of all sectors", rt.Caller())
+		}
+	}
+
 	precommits, err := st.GetAllPrecommittedSectors(store, params.SectorNumbers)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get precommits")
 
 	// compute data commitments and validate each precommit
-	computeDataCommitmentsInputs := make([]*market.SectorDataSpec, len(precommits))
+	computeDataCommitmentsInputs := make([]*market.SectorDataSpec, 0, len(precommits))
 	precommitsToConfirm := []*SectorPreCommitOnChainInfo{}
+	latePrecommits := []*SectorPreCommitOnChainInfo{}
 	for i, precommit := range precommits {
 		msd, ok := MaxProveCommitDuration[precommit.Info.SealProof]
 		if !ok {
 			rt.Abortf(exitcode.ErrIllegalState, "no max seal duration for proof type: %d", precommit.Info.SealProof)
 		}
 		proveCommitDue := precommit.PreCommitEpoch + msd
-		if rt.CurrEpoch() > proveCommitDue {
+		if rt.CurrEpoch() > proveCommitDue+ExpiredPreCommitCleanUpDelay {
 			rt.Log(rtt.WARN, "skipping commitment for sector %d, too late at %d, due %d", precommit.Info.SectorNumber, rt.CurrEpoch(), proveCommitDue)
+		} else if rt.CurrEpoch() > proveCommitDue {
+			rt.Log(rtt.WARN, "refunding deposit for sector %d, too late to activate at %d, due %d", precommit.Info.SectorNumber, rt.CurrEpoch(), proveCommitDue)
+			latePrecommits = append(latePrecommits, precommit)
 		} else {
 			precommitsToConfirm = append(precommitsToConfirm, precommit)
 		}
@@ -907,14 +1295,25 @@ func (a Actor) ProveCommitAggregate(rt Runtime, params *ProveCommitAggregatePara
 			builtin.RequireState(rt, prevSealProof == precommit.Info.SealProof, "aggregate contains mismatched seal proofs %d and %d", prevSealProof, precommit.Info.SealProof)
 		}
 
-		computeDataCommitmentsInputs[i] = &market.SectorDataSpec{
-			SectorType: precommit.Info.SealProof,
-			DealIDs:    precommit.Info.DealIDs,
+		// The data commitments of sectors with attested pieces are computed from the pieces.
+		if precommit.Pieces == nil {
+			computeDataCommitmentsInputs = append(computeDataCommitmentsInputs, &market.SectorDataSpec{
+				SectorType: precommit.Info.SealProof,
+				DealIDs:    precommit.Info.DealIDs,
+			})
 		}
 	}
 
 	// compute shared verification inputs
-	commDs := requestUnsealedSectorCIDs(rt, computeDataCommitmentsInputs...)
+	dealCommDs := requestUnsealedSectorCIDs(rt, computeDataCommitmentsInputs...)
+	commDs := make([]cid.Cid, len(precommits))
+	for i, precommit := range precommits {
+		if precommit.Pieces != nil {
+			commDs[i] = computeAttestedUnsealedSectorCID(rt, precommit.Info.SealProof, precommit.Pieces)
+		} else {
+			commDs[i], dealCommDs = dealCommDs[0], dealCommDs[1:]
+		}
+	}
 	svis := make([]proof.AggregateSealVerifyInfo, 0)
 	receiver := rt.Receiver()
 	minerActorID, err := addr.IDFromAddress(receiver)
@@ -953,14 +1352,14 @@ func (a Actor) ProveCommitAggregate(rt Runtime, params *ProveCommitAggregatePara
 			AggregateProof: abi.RegisteredAggregationProof_SnarkPackV1,
 		})
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "aggregate seal verify failed")
-	confirmSectorProofsValid(rt, precommitsToConfirm)
+	ret := confirmTimelyAndRefundLatePreCommits(rt, precommitsToConfirm, latePrecommits)
 
-	burnFunds(rt, AggregateNetworkFee(len(precommitsToConfirm), rt.BaseFee()))
+	builtin.BurnFunds(rt, AggregateNetworkFee(len(precommitsToConfirm)+len(latePrecommits), rt.BaseFee()), builtin.TransferFee)
 	rt.StateReadonly(&st)
 	err = st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
 
-	return nil
+	return ret
 }
 
 //type ProveCommitSectorParams struct {
@@ -1002,8 +1401,10 @@ func (a Actor) ProveCommitSector(rt Runtime, params *ProveCommitSectorParams) *a
 	if !ok {
 		rt.Abortf(exitcode.ErrIllegalState, "no max seal duration for proof type: %d", precommit.Info.SealProof)
 	}
+	// A proof arriving after the due epoch, but before the expired pre-commit is cleaned up, earns a partial
+	// refund of the deposit when confirmed, but does not activate the sector.
 	proveCommitDue := precommit.PreCommitEpoch + msd
-	if rt.CurrEpoch() > proveCommitDue {
+	if rt.CurrEpoch() > proveCommitDue+ExpiredPreCommitCleanUpDelay {
 		rt.Abortf(exitcode.ErrIllegalArgument, "commitment proof for %d too late at %d, due %d", sectorNo, rt.CurrEpoch(), proveCommitDue)
 	}
 
@@ -1013,6 +1414,7 @@ func (a Actor) ProveCommitSector(rt Runtime, params *ProveCommitSectorParams) *a
 		SealRandEpoch:       precommit.Info.SealRandEpoch,
 		Proof:               params.Proof,
 		DealIDs:             precommit.Info.DealIDs,
+		Pieces:              precommit.Pieces,
 		SectorNumber:        precommit.Info.SectorNumber,
 		RegisteredSealProof: precommit.Info.SealProof,
 	})
@@ -1028,7 +1430,35 @@ func (a Actor) ProveCommitSector(rt Runtime, params *ProveCommitSectorParams) *a
 	return nil
 }
 
-func (a Actor) ConfirmSectorProofsValid(rt Runtime, params *builtin.ConfirmSectorProofsParams) *abi.EmptyValue {
+// SectorActivationFailure is the reason a proven pre-committed sector was not activated.
+type SectorActivationFailure uint64
+
+const (
+	// No pre-commitment was found for the sector.
+	SectorActivationNotPreCommitted SectorActivationFailure = iota
+	// The proof arrived after the pre-commitment's due epoch. Part of the pre-commit deposit is refunded.
+	SectorActivationProvenLate
+	// The market actor failed to activate the sector's deals.
+	SectorActivationDealsInvalid
+	// The sector's remaining lifetime is less than the minimum sector lifetime.
+	SectorActivationLifetimeTooShort
+)
+
+type FailedSectorActivation struct {
+	SectorNumber abi.SectorNumber
+	Reason       SectorActivationFailure
+}
+
+type ConfirmSectorProofsValidReturn struct {
+	Activated     bitfield.BitField        // Sectors activated
+	InitialPledge abi.TokenAmount          // Total initial pledge locked for the activated sectors
+	Failed        []FailedSectorActivation // Sectors proven but not activated, in order of sector number
+}
+
+// Activates sectors whose proofs have been verified in bulk by the power actor, at the end of the epoch in which
+// they were proven with ProveCommitSector.
+// Returns the sectors activated and those that failed to activate, with the reason for failure.
+func (a Actor) ConfirmSectorProofsValid(rt Runtime, params *builtin.ConfirmSectorProofsParams) *ConfirmSectorProofsValidReturn {
 	rt.ValidateImmediateCallerIs(builtin.StoragePowerActorAddr)
 
 	// This should be enforced by the power actor. We log here just in case
@@ -1047,12 +1477,90 @@ func (a Actor) ConfirmSectorProofsValid(rt Runtime, params *builtin.ConfirmSecto
 	precommittedSectors, err := st.FindPrecommittedSectors(store, params.Sectors...)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load pre-committed sectors")
 
-	confirmSectorProofsValid(rt, precommittedSectors)
+	found := make(map[abi.SectorNumber]bool, len(precommittedSectors))
+	for _, precommit := range precommittedSectors {
+		found[precommit.Info.SectorNumber] = true
+	}
+	var missing []FailedSectorActivation
+	for _, sectorNo := range params.Sectors {
+		if !found[sectorNo] {
+			missing = append(missing, FailedSectorActivation{SectorNumber: sectorNo, Reason: SectorActivationNotPreCommitted})
+		}
+	}
 
-	return nil
+	// Pre-commits proven after their due epoch but within the clean up delay are released with a partial refund
+	// rather than activated.
+	var timelyPrecommits, latePrecommits []*SectorPreCommitOnChainInfo
+	for _, precommit := range precommittedSectors {
+		msd, ok := MaxProveCommitDuration[precommit.Info.SealProof]
+		if !ok {
+			rt.Abortf(exitcode.ErrIllegalState, "no max seal duration for proof type: %d", precommit.Info.SealProof)
+		}
+		proveCommitDue := precommit.PreCommitEpoch + msd
+		if rt.CurrEpoch() > proveCommitDue && rt.CurrEpoch() <= proveCommitDue+ExpiredPreCommitCleanUpDelay {
+			latePrecommits = append(latePrecommits, precommit)
+		} else {
+			timelyPrecommits = append(timelyPrecommits, precommit)
+		}
+	}
+
+	ret := confirmTimelyAndRefundLatePreCommits(rt, timelyPrecommits, latePrecommits)
+	ret.Failed = append(ret.Failed, missing...)
+	sortFailedSectorActivations(ret.Failed)
+	return ret
+}
+
+// Refunds the deposits of pre-commits proven too late, and activates the sectors of those proven in time.
+// Activation is skipped only if every proof was late, so that an empty set of proofs still aborts.
+func confirmTimelyAndRefundLatePreCommits(rt Runtime, timelyPrecommits, latePrecommits []*SectorPreCommitOnChainInfo) *ConfirmSectorProofsValidReturn {
+	refundLatePreCommits(rt, latePrecommits)
+
+	ret := &ConfirmSectorProofsValidReturn{
+		Activated:     bitfield.New(),
+		InitialPledge: big.Zero(),
+	}
+	if len(timelyPrecommits) > 0 || len(latePrecommits) == 0 {
+		ret = confirmSectorProofsValid(rt, timelyPrecommits)
+	}
+	for _, precommit := range latePrecommits {
+		ret.Failed = append(ret.Failed, FailedSectorActivation{SectorNumber: precommit.Info.SectorNumber, Reason: SectorActivationProvenLate})
+	}
+	sortFailedSectorActivations(ret.Failed)
+	return ret
+}
+
+func sortFailedSectorActivations(failed []FailedSectorActivation) {
+	sort.Slice(failed, func(i, j int) bool {
+		return failed[i].SectorNumber < failed[j].SectorNumber
+	})
+}
+
+// Releases the deposits of pre-commits proven too late to be activated, refunding a portion to the
+// miner's available balance and burning the remainder. The pre-commits are removed from state.
+func refundLatePreCommits(rt Runtime, precommits []*SectorPreCommitOnChainInfo) {
+	if len(precommits) == 0 {
+		return
+	}
+
+	var releases []PreCommitDepositRelease
+	var st State
+	rt.StateTransaction(&st, func() {
+		var err error
+		releases, err = st.ReleaseLatePreCommits(adt.AsStore(rt), precommits)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to release late pre-commits")
+	})
+
+	toBurn := big.Zero()
+	for _, release := range releases {
+		toBurn = big.Add(toBurn, release.Burned)
+	}
+	builtin.BurnPenalty(rt, toBurn)
+	emitPreCommitDepositsReleased(rt, releases)
+	err := st.CheckBalanceInvariants(rt.CurrentBalance())
+	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
 }
 
-func confirmSectorProofsValid(rt Runtime, preCommits []*SectorPreCommitOnChainInfo) {
+func confirmSectorProofsValid(rt Runtime, preCommits []*SectorPreCommitOnChainInfo) *ConfirmSectorProofsValidReturn {
 	// get network stats from other actors
 	rewardStats := requestCurrentEpochBlockReward(rt)
 	pwrTotal := requestCurrentTotalPower(rt)
@@ -1075,6 +1583,7 @@ func confirmSectorProofsValid(rt Runtime, preCommits []*SectorPreCommitOnChainIn
 	activation := rt.CurrEpoch()
 	// Pre-commits for new sectors.
 	var validPreCommits []*SectorPreCommitOnChainInfo
+	var failed []FailedSectorActivation
 	for _, precommit := range preCommits {
 		if len(precommit.Info.DealIDs) > 0 {
 			// Check (and activate) storage deals associated to sector. Abort if checks failed.
@@ -1093,6 +1602,7 @@ func confirmSectorProofsValid(rt Runtime, preCommits []*SectorPreCommitOnChainIn
 
 			if code != exitcode.Ok {
 				rt.Log(rtt.INFO, "failed to activate deals on sector %d, dropping from prove commit set", precommit.Info.SectorNumber)
+				failed = append(failed, FailedSectorActivation{SectorNumber: precommit.Info.SectorNumber, Reason: SectorActivationDealsInvalid})
 				continue
 			}
 		}
@@ -1135,6 +1645,7 @@ func confirmSectorProofsValid(rt Runtime, preCommits []*SectorPreCommitOnChainIn
 			// This should have been caught in precommit, but don't let other sectors fail because of it.
 			if duration < MinSectorExpiration {
 				rt.Log(rtt.WARN, "precommit %d has lifetime %d less than minimum. ignoring", precommit.Info.SectorNumber, duration, MinSectorExpiration)
+				failed = append(failed, FailedSectorActivation{SectorNumber: precommit.Info.SectorNumber, Reason: SectorActivationLifetimeTooShort})
 				continue
 			}
 			pwr := QAPowerForWeight(info.SectorSize, duration, precommit.DealWeight, precommit.VerifiedDealWeight)
@@ -1165,6 +1676,8 @@ func confirmSectorProofsValid(rt Runtime, preCommits []*SectorPreCommitOnChainIn
 				ExpectedStoragePledge: storagePledge,
 				ReplacedSectorAge:     replacedAge,
 				ReplacedDayReward:     replacedDayReward,
+				SealRandEpoch:         precommit.Info.SealRandEpoch,
+				Pieces:                precommit.Pieces,
 			}
 
 			depositToUnlock = big.Add(depositToUnlock, precommit.PreCommitDeposit)
@@ -1179,7 +1692,7 @@ func confirmSectorProofsValid(rt Runtime, preCommits []*SectorPreCommitOnChainIn
 		err = st.DeletePrecommittedSectors(store, newSectorNos...)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete precommited sectors")
 
-		err = st.AssignSectorsToDeadlines(store, rt.CurrEpoch(), newSectors, info.WindowPoStPartitionSectors, info.SectorSize)
+		err = st.AssignSectorsToDeadlines(store, rt.CurrEpoch(), newSectors, info.WindowPoStPartitionSectors, info.SectorSize, info.DeadlineAssignment)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to assign new sectors to deadlines")
 
 		// Unlock deposit for successful proofs, make it available for lock-up as initial pledge.
@@ -1200,6 +1713,16 @@ func confirmSectorProofsValid(rt Runtime, preCommits []*SectorPreCommitOnChainIn
 
 	// Request pledge update for activated sector.
 	notifyPledgeChanged(rt, big.Sub(totalPledge, newlyVested))
+
+	activated := bitfield.New()
+	for _, sector := range newSectors {
+		activated.Set(uint64(sector.SectorNumber))
+	}
+	return &ConfirmSectorProofsValidReturn{
+		Activated:     activated,
+		InitialPledge: totalPledge,
+		Failed:        failed,
+	}
 }
 
 //type CheckSectorProvenParams struct {
@@ -1255,7 +1778,7 @@ func (a Actor) ExtendSectorExpiration(rt Runtime, params *ExtendSectorExpiration
 	// limit the number of sectors declared at once
 	// https://github.com/filecoin-project/specs-actors/issues/416
 	var sectorCount uint64
-	for _, decl := range params.Extensions {
+	for i, decl := range params.Extensions {
 		if decl.Deadline >= WPoStPeriodDeadlines {
 			rt.Abortf(exitcode.ErrIllegalArgument, "deadline %d not in range 0..%d", decl.Deadline, WPoStPeriodDeadlines)
 		}
@@ -1268,12 +1791,12 @@ func (a Actor) ExtendSectorExpiration(rt Runtime, params *ExtendSectorExpiration
 			rt.Abortf(exitcode.ErrIllegalArgument, "sector bitfield integer overflow")
 		}
 		sectorCount += count
-	}
-	if sectorCount > AddressedSectorsMax {
-		rt.Abortf(exitcode.ErrIllegalArgument,
-			"too many sectors for declaration %d, max %d",
-			sectorCount, AddressedSectorsMax,
-		)
+		if sectorCount > AddressedSectorsMax {
+			rt.Abortf(exitcode.ErrIllegalArgument,
+				"declarations %d to %d of %d exceed batch limits: declarations to %d address %d sectors (max %d)",
+				i, len(params.Extensions)-1, len(params.Extensions), i, sectorCount, AddressedSectorsMax,
+			)
+		}
 	}
 
 	currEpoch := rt.CurrEpoch()
@@ -1422,15 +1945,19 @@ type TerminateSectorsParams = miner0.TerminateSectorsParams
 //}
 type TerminationDeclaration = miner0.TerminationDeclaration
 
-//type TerminateSectorsReturn struct {
-//	// Set to true if all early termination work has been completed. When
-//	// false, the miner may choose to repeatedly invoke TerminateSectors
-//	// with no new sectors to process the remainder of the pending
-//	// terminations. While pending terminations are outstanding, the miner
-//	// will not be able to withdraw funds.
-//	Done bool
-//}
-type TerminateSectorsReturn = miner0.TerminateSectorsReturn
+type TerminateSectorsReturn struct {
+	// Set to true if all early termination work has been completed. When
+	// false, the miner may choose to repeatedly invoke TerminateSectors
+	// with no new sectors to process the remainder of the pending
+	// terminations. While pending terminations are outstanding, the miner
+	// will not be able to withdraw funds.
+	Done bool
+	// Change in claimed power for the sectors terminated.
+	PowerDelta PowerPair
+	// Change in total pledge for the terminations processed, i.e. initial pledge released
+	// and locked funds applied to penalties.
+	PledgeDelta abi.TokenAmount
+}
 
 // Marks some sectors as terminated at the present epoch, earlier than their
 // scheduled termination, and adds these sectors to the early termination queue.
@@ -1461,13 +1988,10 @@ func (a Actor) TerminateSectors(rt Runtime, params *TerminateSectorsParams) *Ter
 	}
 
 	toProcess := make(DeadlineSectorMap)
-	for _, term := range params.Terminations {
-		err := toProcess.Add(term.Deadline, term.Partition, term.Sectors)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument,
-			"failed to process deadline %d, partition %d", term.Deadline, term.Partition,
-		)
-	}
-	err := toProcess.Check(AddressedPartitionsMax, AddressedSectorsMax)
+	err := toProcess.AddBatch(len(params.Terminations), func(i int) (uint64, uint64, bitfield.BitField) {
+		term := params.Terminations[i]
+		return term.Deadline, term.Partition, term.Sectors
+	}, AddressedPartitionsMax, AddressedSectorsMax)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "cannot process requested parameters")
 
 	var hadEarlyTerminations bool
@@ -1520,7 +2044,7 @@ func (a Actor) TerminateSectors(rt Runtime, params *TerminateSectorsParams) *Ter
 	})
 
 	// Now, try to process these sectors.
-	more := processEarlyTerminations(rt)
+	more, pledgeDelta := processEarlyTerminations(rt, false)
 	if more && !hadEarlyTerminations {
 		// We have remaining terminations, and we didn't _previously_
 		// have early terminations to process, schedule a cron job.
@@ -1535,7 +2059,11 @@ func (a Actor) TerminateSectors(rt Runtime, params *TerminateSectorsParams) *Ter
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
 
 	requestUpdatePower(rt, powerDelta)
-	return &TerminateSectorsReturn{Done: !more}
+	return &TerminateSectorsReturn{
+		Done:        !more,
+		PowerDelta:  powerDelta,
+		PledgeDelta: pledgeDelta,
+	}
 }
 
 ////////////
@@ -1557,7 +2085,76 @@ type DeclareFaultsParams = miner0.DeclareFaultsParams
 //}
 type FaultDeclaration = miner0.FaultDeclaration
 
-func (a Actor) DeclareFaults(rt Runtime, params *DeclareFaultsParams) *abi.EmptyValue {
+type DeclareFaultsReturn struct {
+	PowerDelta  PowerPair       // Change in claimed power for the newly faulty sectors
+	PledgeDelta abi.TokenAmount // Change in total pledge, zero as fault penalties are deferred to deadline cron
+}
+
+// Declares sectors faulty without giving a reason, as DeclareFaultsWithReasons with FaultReasonUnspecified.
+func (a Actor) DeclareFaults(rt Runtime, params *DeclareFaultsParams) *DeclareFaultsReturn {
+	withReasons := &DeclareFaultsWithReasonsParams{
+		Faults: make([]FaultDeclarationWithReason, len(params.Faults)),
+	}
+	for i, decl := range params.Faults {
+		withReasons.Faults[i] = FaultDeclarationWithReason{
+			Deadline:  decl.Deadline,
+			Partition: decl.Partition,
+			Sectors:   decl.Sectors,
+			Reason:    FaultReasonUnspecified,
+		}
+	}
+	ret := a.DeclareFaultsWithReasons(rt, withReasons)
+	return &DeclareFaultsReturn{
+		PowerDelta:  ret.PowerDelta,
+		PledgeDelta: ret.PledgeDelta,
+	}
+}
+
+// FaultReason is a miner's explanation of why sectors are faulty.
+type FaultReason uint64
+
+const (
+	// No reason given.
+	FaultReasonUnspecified FaultReason = iota
+	// The sectors are unavailable due to a loss of power.
+	FaultReasonPowerOutage
+	// The sectors are unavailable due to failure of the storage holding them, and their data may be lost.
+	FaultReasonDiskFailure
+	// The sectors are temporarily unavailable due to planned maintenance.
+	FaultReasonMaintenance
+)
+
+type DeclareFaultsWithReasonsParams struct {
+	Faults []FaultDeclarationWithReason
+}
+
+type FaultDeclarationWithReason struct {
+	// The deadline to which the faulty sectors are assigned, in range [0..WPoStPeriodDeadlines)
+	Deadline uint64
+	// Partition index within the deadline containing the faulty sectors.
+	Partition uint64
+	// Sectors in the partition being declared faulty.
+	Sectors bitfield.BitField
+	// Why the sectors are faulty. The reason is not stored, and has no effect on the fault's penalties.
+	Reason FaultReason
+}
+
+type DeclareFaultsWithReasonsReturn struct {
+	PowerDelta  PowerPair       // Change in claimed power for the newly faulty sectors
+	PledgeDelta abi.TokenAmount // Change in total pledge, zero as fault penalties are deferred to deadline cron
+	Reasons     []FaultReasonSectors
+}
+
+// The sectors declared faulty for a single reason, in a DeclareFaultsWithReasons message.
+type FaultReasonSectors struct {
+	Reason  FaultReason
+	Sectors bitfield.BitField
+}
+
+// Declares sectors faulty, as DeclareFaults, with each declaration giving a reason for the fault.
+// The reasons are returned, grouped by reason in ascending order, so that observers may distinguish
+// planned maintenance from data loss.
+func (a Actor) DeclareFaultsWithReasons(rt Runtime, params *DeclareFaultsWithReasonsParams) *DeclareFaultsWithReasonsReturn {
 	if len(params.Faults) > DeclarationsMax {
 		rt.Abortf(exitcode.ErrIllegalArgument,
 			"too many fault declarations for a single message: %d > %d",
@@ -1565,14 +2162,18 @@ func (a Actor) DeclareFaults(rt Runtime, params *DeclareFaultsParams) *abi.Empty
 		)
 	}
 
-	toProcess := make(DeadlineSectorMap)
+	sectorsByReason := make(map[FaultReason][]bitfield.BitField)
 	for _, term := range params.Faults {
-		err := toProcess.Add(term.Deadline, term.Partition, term.Sectors)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument,
-			"failed to process deadline %d, partition %d", term.Deadline, term.Partition,
-		)
+		if term.Reason > FaultReasonMaintenance {
+			rt.Abortf(exitcode.ErrIllegalArgument, "invalid fault reason %d", term.Reason)
+		}
+		sectorsByReason[term.Reason] = append(sectorsByReason[term.Reason], term.Sectors)
 	}
-	err := toProcess.Check(AddressedPartitionsMax, AddressedSectorsMax)
+	toProcess := make(DeadlineSectorMap)
+	err := toProcess.AddBatch(len(params.Faults), func(i int) (uint64, uint64, bitfield.BitField) {
+		term := params.Faults[i]
+		return term.Deadline, term.Partition, term.Sectors
+	}, AddressedPartitionsMax, AddressedSectorsMax)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "cannot process requested parameters")
 
 	store := adt.AsStore(rt)
@@ -1606,6 +2207,10 @@ func (a Actor) DeclareFaults(rt Runtime, params *DeclareFaultsParams) *abi.Empty
 			err = deadlines.UpdateDeadline(store, dlIdx, deadline)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to store deadline %d partitions", dlIdx)
 
+			_, sectorCount, err := pm.Count()
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to count declared faults for deadline %d", dlIdx)
+			st.RecordFaultsDeclared(currEpoch, sectorCount)
+
 			powerDelta = powerDelta.Add(deadlinePowerDelta)
 			return nil
 		})
@@ -1621,8 +2226,22 @@ func (a Actor) DeclareFaults(rt Runtime, params *DeclareFaultsParams) *abi.Empty
 	// https://github.com/filecoin-project/specs-actors/issues/414
 	requestUpdatePower(rt, powerDelta)
 
+	reasons := make([]FaultReasonSectors, 0, len(sectorsByReason))
+	for reason, sectors := range sectorsByReason {
+		merged, err := bitfield.MultiMerge(sectors...)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to merge sectors for fault reason %d", reason)
+		reasons = append(reasons, FaultReasonSectors{Reason: reason, Sectors: merged})
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		return reasons[i].Reason < reasons[j].Reason
+	})
+
 	// Payment of penalty for declared faults is deferred to the deadline cron.
-	return nil
+	return &DeclareFaultsWithReasonsReturn{
+		PowerDelta:  powerDelta,
+		PledgeDelta: big.Zero(),
+		Reasons:     reasons,
+	}
 }
 
 //type DeclareFaultsRecoveredParams struct {
@@ -1640,7 +2259,12 @@ type DeclareFaultsRecoveredParams = miner0.DeclareFaultsRecoveredParams
 //}
 type RecoveryDeclaration = miner0.RecoveryDeclaration
 
-func (a Actor) DeclareFaultsRecovered(rt Runtime, params *DeclareFaultsRecoveredParams) *abi.EmptyValue {
+type DeclareFaultsRecoveredReturn struct {
+	PowerDelta  PowerPair       // Change in claimed power, zero as power is restored only when the sectors are proven
+	PledgeDelta abi.TokenAmount // Change in total pledge, zero as fee debt is repaid from unlocked funds
+}
+
+func (a Actor) DeclareFaultsRecovered(rt Runtime, params *DeclareFaultsRecoveredParams) *DeclareFaultsRecoveredReturn {
 	if len(params.Recoveries) > DeclarationsMax {
 		rt.Abortf(exitcode.ErrIllegalArgument,
 			"too many recovery declarations for a single message: %d > %d",
@@ -1649,13 +2273,10 @@ func (a Actor) DeclareFaultsRecovered(rt Runtime, params *DeclareFaultsRecovered
 	}
 
 	toProcess := make(DeadlineSectorMap)
-	for _, term := range params.Recoveries {
-		err := toProcess.Add(term.Deadline, term.Partition, term.Sectors)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument,
-			"failed to process deadline %d, partition %d", term.Deadline, term.Partition,
-		)
-	}
-	err := toProcess.Check(AddressedPartitionsMax, AddressedSectorsMax)
+	err := toProcess.AddBatch(len(params.Recoveries), func(i int) (uint64, uint64, bitfield.BitField) {
+		term := params.Recoveries[i]
+		return term.Deadline, term.Partition, term.Sectors
+	}, AddressedPartitionsMax, AddressedSectorsMax)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "cannot process requested parameters")
 
 	store := adt.AsStore(rt)
@@ -1701,13 +2322,16 @@ func (a Actor) DeclareFaultsRecovered(rt Runtime, params *DeclareFaultsRecovered
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to save deadlines")
 	})
 
-	burnFunds(rt, feeToBurn)
+	builtin.BurnPenalty(rt, feeToBurn)
 	rt.StateReadonly(&st)
 	err = st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
 
 	// Power is not restored yet, but when the recovered sectors are successfully PoSted.
-	return nil
+	return &DeclareFaultsRecoveredReturn{
+		PowerDelta:  NewPowerPairZero(),
+		PledgeDelta: big.Zero(),
+	}
 }
 
 /////////////////
@@ -1747,7 +2371,8 @@ func (a Actor) CompactPartitions(rt Runtime, params *CompactPartitionsParams) *a
 
 		submissionPartitionLimit := loadPartitionsSectorsMax(info.WindowPoStPartitionSectors)
 		if partitionCount > submissionPartitionLimit {
-			rt.Abortf(exitcode.ErrIllegalArgument, "too many partitions %d, limit %d", partitionCount, submissionPartitionLimit)
+			rt.Abortf(exitcode.ErrIllegalArgument, "too many partitions %d, limit %d for partitions of %d sectors",
+				partitionCount, submissionPartitionLimit, info.WindowPoStPartitionSectors)
 		}
 
 		quant := st.QuantSpecForDeadline(params.Deadline)
@@ -1792,9 +2417,10 @@ type CompactSectorNumbersParams = miner0.CompactSectorNumbersParams
 // number bitfield.
 //
 // When allocating sector numbers sequentially, or in sequential groups, this
-// bitfield should remain fairly small. However, if the bitfield grows large
-// enough such that PreCommitSector fails (or becomes expensive), this method
-// can be called to mask out (throw away) entire ranges of unused sector IDs.
+// bitfield should remain fairly small. If the bitfield grows large, pre-commitment
+// compacts it automatically by masking the lowest unused sector numbers
+// (see AllocatedSectorsCompactionThreshold). This method can be called to mask
+// out (throw away) other ranges of unused sector IDs.
 // For example, if sectors 1-99 and 101-200 have been allocated, sector number
 // 99 can be masked out to collapse these two ranges into one.
 func (a Actor) CompactSectorNumbers(rt Runtime, params *CompactSectorNumbersParams) *abi.EmptyValue {
@@ -1866,7 +2492,7 @@ func (a Actor) ApplyRewards(rt Runtime, params *builtin.ApplyRewardParams) *abi.
 	})
 
 	notifyPledgeChanged(rt, pledgeDeltaTotal)
-	burnFunds(rt, toBurn)
+	builtin.BurnPenalty(rt, toBurn)
 	rt.StateReadonly(&st)
 	err := st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
@@ -1879,28 +2505,100 @@ func (a Actor) ApplyRewards(rt Runtime, params *builtin.ApplyRewardParams) *abi.
 //	BlockHeader2     []byte
 //	BlockHeaderExtra []byte
 //}
+type LockFundsParams struct {
+	VestSpec VestSpec
+}
+
+// Locks the funds received with the message in the miner's vesting table, to vest by the given schedule.
+// Any party may lock funds, e.g. to collateralize a miner. Locked funds count towards the miner's pledge
+// collateral until they vest, when they become available to the miner to withdraw.
+// The schedule must vest within MaxLockFundsVestDuration, at epochs aligned with the vesting of rewards.
+func (a Actor) LockFunds(rt Runtime, params *LockFundsParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerAcceptAny()
+	amount := rt.ValueReceived()
+	if amount.Sign() <= 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no funds to lock")
+	}
+	err := validateLockFundsVestSpec(&params.VestSpec)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid vesting schedule")
+
+	var st State
+	pledgeDelta := big.Zero()
+	rt.StateTransaction(&st, func() {
+		newlyVested, err := st.AddLockedFunds(adt.AsStore(rt), rt.CurrEpoch(), amount, &params.VestSpec)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to lock funds in vesting table")
+		pledgeDelta = big.Sub(amount, newlyVested)
+	})
+
+	notifyPledgeChanged(rt, pledgeDelta)
+	rt.StateReadonly(&st)
+	err = st.CheckBalanceInvariants(rt.CurrentBalance())
+	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
+	return nil
+}
+
 type ReportConsensusFaultParams = miner0.ReportConsensusFaultParams
 
+// Reports a single consensus fault by the miner.
+// See ReportConsensusFaults for details.
 func (a Actor) ReportConsensusFault(rt Runtime, params *ReportConsensusFaultParams) *abi.EmptyValue {
-	// Note: only the first report of any fault is processed because it sets the
+	// This is a direct method call to self, not a message send.
+	faultsParams := &ReportConsensusFaultsParams{Faults: []miner0.ReportConsensusFaultParams{*params}}
+	a.ReportConsensusFaults(rt, faultsParams)
+	return nil
+}
+
+type ReportConsensusFaultsParams struct {
+	Faults []miner0.ReportConsensusFaultParams
+}
+
+// Reports a number of consensus faults by the miner.
+// Every fault must be verified, distinct in epoch and type from the others, and no older than the end of the
+// miner's previous consensus fault ineligibility period, else the whole report fails.
+// The miner is penalized, and the reporter rewarded, once for the whole report, as if the faults had been
+// reported in separate messages.
+func (a Actor) ReportConsensusFaults(rt Runtime, params *ReportConsensusFaultsParams) *abi.EmptyValue {
+	// Note: only the first report of any fault is penalized because it sets the
 	// ConsensusFaultElapsed state variable to an epoch after the fault, and reports prior to
-	// that epoch are no longer valid.
+	// that epoch are no longer valid. The same holds for faults reported together, since the
+	// first of them starts an ineligibility period covering all the others.
 	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
 	reporter := rt.Caller()
 
-	fault, err := rt.VerifyConsensusFault(params.BlockHeader1, params.BlockHeader2, params.BlockHeaderExtra)
-	if err != nil {
-		rt.Abortf(exitcode.ErrIllegalArgument, "fault not verified: %s", err)
-	}
-	if fault.Target != rt.Receiver() {
-		rt.Abortf(exitcode.ErrIllegalArgument, "fault by %v reported to miner %v", fault.Target, rt.Receiver())
+	if len(params.Faults) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "batch empty")
+	} else if len(params.Faults) > ReportConsensusFaultsBatchMaxSize {
+		rt.Abortf(exitcode.ErrIllegalArgument, "batch of %d too large, max %d", len(params.Faults), ReportConsensusFaultsBatchMaxSize)
 	}
 
-	// Elapsed since the fault (i.e. since the higher of the two blocks)
 	currEpoch := rt.CurrEpoch()
-	faultAge := currEpoch - fault.Epoch
-	if faultAge <= 0 {
-		rt.Abortf(exitcode.ErrIllegalArgument, "invalid fault epoch %v ahead of current %v", fault.Epoch, currEpoch)
+	type faultKey struct {
+		epoch     abi.ChainEpoch
+		faultType runtime.ConsensusFaultType
+	}
+	seenFaults := make(map[faultKey]struct{}, len(params.Faults))
+	faults := make([]*runtime.ConsensusFault, len(params.Faults))
+	for i, report := range params.Faults {
+		fault, err := rt.VerifyConsensusFault(report.BlockHeader1, report.BlockHeader2, report.BlockHeaderExtra)
+		if err != nil {
+			rt.Abortf(exitcode.ErrIllegalArgument, "fault %d not verified: %s", i, err)
+		}
+		if fault.Target != rt.Receiver() {
+			rt.Abortf(exitcode.ErrIllegalArgument, "fault by %v reported to miner %v", fault.Target, rt.Receiver())
+		}
+
+		// Elapsed since the fault (i.e. since the higher of the two blocks)
+		faultAge := currEpoch - fault.Epoch
+		if faultAge <= 0 {
+			rt.Abortf(exitcode.ErrIllegalArgument, "invalid fault epoch %v ahead of current %v", fault.Epoch, currEpoch)
+		}
+
+		key := faultKey{fault.Epoch, fault.Type}
+		if _, seen := seenFaults[key]; seen {
+			rt.Abortf(exitcode.ErrIllegalArgument, "duplicate fault of type %d at epoch %d", fault.Type, fault.Epoch)
+		}
+		seenFaults[key] = struct{}{}
+		faults[i] = fault
 	}
 
 	// Penalize miner consensus fault fee
@@ -1921,8 +2619,10 @@ func (a Actor) ReportConsensusFault(rt Runtime, params *ReportConsensusFaultPara
 		info := getMinerInfo(rt, &st)
 
 		// verify miner hasn't already been faulted
-		if fault.Epoch < info.ConsensusFaultElapsed {
-			rt.Abortf(exitcode.ErrForbidden, "fault epoch %d is too old, last exclusion period ended at %d", fault.Epoch, info.ConsensusFaultElapsed)
+		for _, fault := range faults {
+			if fault.Epoch < info.ConsensusFaultElapsed {
+				rt.Abortf(exitcode.ErrForbidden, "fault epoch %d is too old, last exclusion period ended at %d", fault.Epoch, info.ConsensusFaultElapsed)
+			}
 		}
 
 		err := st.ApplyPenalty(faultPenalty)
@@ -1943,15 +2643,17 @@ func (a Actor) ReportConsensusFault(rt Runtime, params *ReportConsensusFaultPara
 		err = st.SaveInfo(adt.AsStore(rt), info)
 		builtin.RequireNoErr(rt, err, exitcode.ErrSerialization, "failed to save miner info")
 	})
-	code := rt.Send(reporter, builtin.MethodSend, nil, rewardAmount, &builtin.Discard{})
+	requestRecordConsensusFaults(rt, faults, currEpoch+ConsensusFaultIneligibilityDuration)
+
+	code := builtin.TransferFunds(rt, reporter, rewardAmount, builtin.TransferReporterReward)
 	if !code.IsSuccess() {
 		rt.Log(rtt.ERROR, "failed to send reward")
 	}
-	burnFunds(rt, burnAmount)
+	builtin.BurnPenalty(rt, burnAmount)
 	notifyPledgeChanged(rt, pledgeDelta)
 
 	rt.StateReadonly(&st)
-	err = st.CheckBalanceInvariants(rt.CurrentBalance())
+	err := st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
 
 	return nil
@@ -1963,6 +2665,20 @@ func (a Actor) ReportConsensusFault(rt Runtime, params *ReportConsensusFaultPara
 type WithdrawBalanceParams = miner0.WithdrawBalanceParams
 
 func (a Actor) WithdrawBalance(rt Runtime, params *WithdrawBalanceParams) *abi.EmptyValue {
+	withdrawParams := &WithdrawBalanceToParams{AmountRequested: params.AmountRequested, Recipient: nil}
+	a.WithdrawBalanceTo(rt, withdrawParams)
+	return nil
+}
+
+type WithdrawBalanceToParams struct {
+	AmountRequested abi.TokenAmount
+	// Address to receive the withdrawn funds. If nil, funds are sent to the owner.
+	Recipient *addr.Address
+}
+
+// Withdraws available balance, as for WithdrawBalance, but sends the funds to a recipient nominated by the owner.
+// This saves a subsequent transfer when funds are destined for some address other than the owner.
+func (a Actor) WithdrawBalanceTo(rt Runtime, params *WithdrawBalanceToParams) *abi.EmptyValue {
 	var st State
 	if params.AmountRequested.LessThan(big.Zero()) {
 		rt.Abortf(exitcode.ErrIllegalArgument, "negative fund requested for withdrawal: %s", params.AmountRequested)
@@ -2008,12 +2724,16 @@ func (a Actor) WithdrawBalance(rt Runtime, params *WithdrawBalanceParams) *abi.E
 	builtin.RequireState(rt, amountWithdrawn.GreaterThanEqual(big.Zero()), "negative amount to withdraw: %v", amountWithdrawn)
 	builtin.RequireState(rt, amountWithdrawn.LessThanEqual(availableBalance), "amount to withdraw %v < available %v", amountWithdrawn, availableBalance)
 
+	recipient := info.Owner
+	if params.Recipient != nil {
+		recipient = *params.Recipient
+	}
 	if amountWithdrawn.GreaterThan(abi.NewTokenAmount(0)) {
-		code := rt.Send(info.Owner, builtin.MethodSend, nil, amountWithdrawn, &builtin.Discard{})
+		code := builtin.TransferFunds(rt, recipient, amountWithdrawn, builtin.TransferWithdrawal)
 		builtin.RequireSuccess(rt, code, "failed to withdraw balance")
 	}
 
-	burnFunds(rt, feeToBurn)
+	builtin.BurnPenalty(rt, feeToBurn)
 
 	pledgeDelta := newlyVested.Neg()
 	notifyPledgeChanged(rt, pledgeDelta)
@@ -2038,13 +2758,429 @@ func (a Actor) RepayDebt(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 	})
 
 	notifyPledgeChanged(rt, fromVesting.Neg())
-	burnFunds(rt, big.Sum(fromVesting, fromBalance))
+	builtin.BurnPenalty(rt, big.Sum(fromVesting, fromBalance))
 	err := st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
 
 	return nil
 }
 
+// Returns an itemized breakdown of the actor's balance: the available balance as used by WithdrawBalance,
+// together with pre-commit deposits, initial pledge, locked vesting funds and fee debt.
+// Vested funds that have not yet been unlocked are reported as locked.
+func (a Actor) GetBalanceBreakdown(rt Runtime, _ *abi.EmptyValue) *BalanceBreakdown {
+	rt.ValidateImmediateCallerAcceptAny()
+	var st State
+	rt.StateReadonly(&st)
+	breakdown, err := st.GetBalanceBreakdown(rt.CurrentBalance())
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to calculate balance breakdown")
+	return breakdown
+}
+
+// Returns the miner's rolling counters of window PoSt submissions and faults as of the current epoch,
+// giving deal clients an on-chain signal of the miner's reliability.
+func (a Actor) GetReliabilityStats(rt Runtime, _ *abi.EmptyValue) *ReliabilityStats {
+	rt.ValidateImmediateCallerAcceptAny()
+	var st State
+	rt.StateReadonly(&st)
+	stats := st.ReliabilityStats.AsOf(rt.CurrEpoch())
+	return &stats
+}
+
+type GetSectorRegionsParams struct {
+	Sectors bitfield.BitField
+}
+
+type GetSectorRegionsReturn struct {
+	Regions []SectorRegion
+}
+
+// Returns the regions in which the miner has declared some sectors to be stored, so that clients with data
+// residency requirements can verify where a miner claims to store their data.
+func (a Actor) GetSectorRegions(rt Runtime, params *GetSectorRegionsParams) *GetSectorRegionsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	count, err := params.Sectors.Count()
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to count sectors")
+	if count > AddressedSectorsMax {
+		rt.Abortf(exitcode.ErrIllegalArgument, "too many sectors %d, max %d", count, AddressedSectorsMax)
+	}
+
+	var st State
+	rt.StateReadonly(&st)
+	regions, err := st.LoadSectorRegions(adt.AsStore(rt), params.Sectors)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load sector regions")
+	return &GetSectorRegionsReturn{Regions: regions}
+}
+
+type DeadlinePostStatusReturn struct {
+	Deadline         uint64            // Index of the current deadline
+	Partitions       uint64            // Number of partitions in the current deadline
+	PartitionsPoSted bitfield.BitField // Partitions already proven in the current challenge window
+}
+
+// Returns the Window PoSt status of the current deadline: the partitions that have already been proven
+// in the current challenge window and which SubmitWindowedPoSt will reject.
+func (a Actor) DeadlinePostStatus(rt Runtime, _ *abi.EmptyValue) *DeadlinePostStatusReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	var st State
+	rt.StateReadonly(&st)
+	store := adt.AsStore(rt)
+	currDeadline := st.DeadlineInfo(rt.CurrEpoch())
+
+	deadlines, err := st.LoadDeadlines(store)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadlines")
+	deadline, err := deadlines.LoadDeadline(store, currDeadline.Index)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadline %d", currDeadline.Index)
+	partitions, err := deadline.PartitionsArray(store)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load partitions for deadline %d", currDeadline.Index)
+
+	return &DeadlinePostStatusReturn{
+		Deadline:         currDeadline.Index,
+		Partitions:       partitions.Length(),
+		PartitionsPoSted: deadline.PartitionsPoSted,
+	}
+}
+
+type ProvingPeriodStatusReturn struct {
+	Sectors           SectorStatusCounts
+	CurrentDeadline   uint64           // Index of the current deadline
+	NextDeadlineOpens []abi.ChainEpoch // Epochs at which the next ProvingPeriodStatusUpcomingDeadlines deadlines open
+}
+
+// Returns a summary of the miner's proving status: counts of its sectors by status, the power of its live sectors,
+// and the current and upcoming deadlines, so that monitoring agents can observe a miner in one call.
+func (a Actor) ProvingPeriodStatus(rt Runtime, _ *abi.EmptyValue) *ProvingPeriodStatusReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	var st State
+	rt.StateReadonly(&st)
+	counts, err := st.GetSectorStatusCounts(adt.AsStore(rt))
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to count sectors")
+
+	currDeadline := st.DeadlineInfo(rt.CurrEpoch())
+	nextOpens := make([]abi.ChainEpoch, ProvingPeriodStatusUpcomingDeadlines)
+	for i := range nextOpens {
+		nextOpens[i] = currDeadline.NextOpen() + abi.ChainEpoch(i)*WPoStChallengeWindow
+	}
+	return &ProvingPeriodStatusReturn{
+		Sectors:           *counts,
+		CurrentDeadline:   currDeadline.Index,
+		NextDeadlineOpens: nextOpens,
+	}
+}
+
+type WindowPoStChallengeParams struct {
+	Partitions bitfield.BitField // Partitions of the current deadline to be proven together
+}
+
+type WindowPoStChallengeReturn struct {
+	Deadline          uint64             // Index of the current deadline
+	ChallengeEpoch    abi.ChainEpoch     // Epoch from which the challenge seed is drawn
+	Randomness        abi.PoStRandomness // The challenge seed
+	ChallengedSectors []proof.SectorInfo // Sectors challenged, with stand-ins for those ignored
+}
+
+// Returns the challenge against which a Window PoSt for the given partitions of the current deadline
+// would be verified if submitted now, declaring no new faults.
+// This is derived exactly as on-chain verification derives it.
+func (a Actor) WindowPoStChallenge(rt Runtime, params *WindowPoStChallengeParams) *WindowPoStChallengeReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	var st State
+	rt.StateReadonly(&st)
+	store := adt.AsStore(rt)
+	currDeadline := st.DeadlineInfo(rt.CurrEpoch())
+	if !currDeadline.IsOpen() {
+		rt.Abortf(exitcode.ErrIllegalState, "proving period %d not yet open at %d", currDeadline.PeriodStart, rt.CurrEpoch())
+	}
+
+	deadlines, err := st.LoadDeadlines(store)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadlines")
+	deadline, err := deadlines.LoadDeadline(store, currDeadline.Index)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadline %d", currDeadline.Index)
+	provenSectors, ignoredSectors, err := deadline.ProofSectors(store, params.Partitions)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to load sectors of deadline %d", currDeadline.Index)
+
+	sectors, err := LoadSectors(store, st.Sectors)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load sectors")
+	sectorInfos, err := sectors.LoadForProof(provenSectors, ignoredSectors)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load proven sector info")
+
+	randomness := windowPoStChallengeSeed(rt, currDeadline.Challenge)
+	minerActorID, err := addr.IDFromAddress(rt.Receiver())
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "runtime provided bad receiver address %v", rt.Receiver())
+	verifyInfo := WindowPoStVerifyInfo(abi.ActorID(minerActorID), randomness, sectorInfos, nil)
+
+	return &WindowPoStChallengeReturn{
+		Deadline:          currDeadline.Index,
+		ChallengeEpoch:    currDeadline.Challenge,
+		Randomness:        verifyInfo.Randomness,
+		ChallengedSectors: verifyInfo.ChallengedSectors,
+	}
+}
+
+type FaultExpirationsParams struct {
+	Until abi.ChainEpoch // Latest termination epoch to include
+	Page  builtin.PageParams
+}
+
+type FaultExpiration struct {
+	Deadline   uint64
+	Partition  uint64
+	FaultEpoch abi.ChainEpoch    // Last epoch of the deadline in which the sectors became faulty
+	Expiration abi.ChainEpoch    // Epoch at which the sectors are terminated unless recovered first
+	Sectors    bitfield.BitField // Faulty sectors, including any declared recovering but not yet proven
+}
+
+type FaultExpirationsReturn struct {
+	Expirations []FaultExpiration // Ordered by deadline, partition, then expiration
+	Page        builtin.PageReturn
+}
+
+// Returns the faulty sectors that will be terminated for having been faulty for FaultMaxAge at or before
+// some epoch, along with when they became faulty, so that their recovery may be prioritised.
+// A sector's fault epoch is that from which its scheduled termination was computed.
+func (a Actor) FaultExpirations(rt Runtime, params *FaultExpirationsParams) *FaultExpirationsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	var st State
+	rt.StateReadonly(&st)
+	store := adt.AsStore(rt)
+
+	deadlines, err := st.LoadDeadlines(store)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadlines")
+
+	expirations := []FaultExpiration{}
+	err = deadlines.ForEach(store, func(dlIdx uint64, deadline *Deadline) error {
+		partitions, err := deadline.PartitionsArray(store)
+		if err != nil {
+			return err
+		}
+		quant := st.QuantSpecForDeadline(dlIdx)
+		var partition Partition
+		return partitions.ForEach(&partition, func(pIdx int64) error {
+			return partition.ForEachFaultExpiration(store, params.Until, quant, func(epoch abi.ChainEpoch, sectors bitfield.BitField) error {
+				expirations = append(expirations, FaultExpiration{
+					Deadline:   dlIdx,
+					Partition:  uint64(pIdx),
+					FaultEpoch: epoch - FaultMaxAge,
+					Expiration: epoch,
+					Sectors:    sectors,
+				})
+				return nil
+			})
+		})
+	})
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load fault expirations")
+
+	start, end, page, err := builtin.Paginate(uint64(len(expirations)), &params.Page)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid page")
+	return &FaultExpirationsReturn{Expirations: expirations[start:end], Page: page}
+}
+
+type EarlyTerminationsParams struct {
+	Page builtin.PageParams
+}
+
+type EarlyTermination struct {
+	Deadline  uint64
+	Partition uint64
+	Epoch     abi.ChainEpoch    // Epoch at which the sectors were terminated
+	Sectors   bitfield.BitField // Terminated sectors whose penalties and deals are yet to be settled
+}
+
+type EarlyTerminationsReturn struct {
+	Terminations []EarlyTermination // Ordered by deadline, partition, then epoch
+	Page         builtin.PageReturn
+}
+
+// Returns the miner's early terminations awaiting processing, either by cron or ProcessEarlyTerminations.
+func (a Actor) EarlyTerminations(rt Runtime, params *EarlyTerminationsParams) *EarlyTerminationsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	var st State
+	rt.StateReadonly(&st)
+	store := adt.AsStore(rt)
+
+	deadlines, err := st.LoadDeadlines(store)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadlines")
+
+	terminations := []EarlyTermination{}
+	err = st.EarlyTerminations.ForEach(func(dlIdx uint64) error {
+		deadline, err := deadlines.LoadDeadline(store, dlIdx)
+		if err != nil {
+			return err
+		}
+		partitions, err := deadline.PartitionsArray(store)
+		if err != nil {
+			return err
+		}
+		return deadline.EarlyTerminations.ForEach(func(pIdx uint64) error {
+			var partition Partition
+			if found, err := partitions.Get(pIdx, &partition); err != nil {
+				return err
+			} else if !found {
+				return xerrors.Errorf("no partition %d of deadline %d", pIdx, dlIdx)
+			}
+			return partition.ForEachEarlyTermination(store, func(epoch abi.ChainEpoch, sectors bitfield.BitField) error {
+				terminations = append(terminations, EarlyTermination{
+					Deadline:  dlIdx,
+					Partition: pIdx,
+					Epoch:     epoch,
+					Sectors:   sectors,
+				})
+				return nil
+			})
+		})
+	})
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load early terminations")
+
+	start, end, page, err := builtin.Paginate(uint64(len(terminations)), &params.Page)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid page")
+	return &EarlyTerminationsReturn{Terminations: terminations[start:end], Page: page}
+}
+
+type ProjectSectorPledgeParams struct {
+	Expiration         abi.ChainEpoch // Expiration epoch of the hypothetical sector
+	DealWeight         abi.DealWeight
+	VerifiedDealWeight abi.DealWeight
+}
+
+// Projects the pre-commit deposit and initial pledge of a hypothetical sector of this miner's sector size,
+// were it pre-committed and activated at the current epoch under current network conditions.
+// The projection doesn't account for a replaced sector's pledge, which lower-bounds the pledge of its replacement.
+func (a Actor) ProjectSectorPledge(rt Runtime, params *ProjectSectorPledgeParams) *SectorPledgeProjection {
+	rt.ValidateImmediateCallerAcceptAny()
+	currEpoch := rt.CurrEpoch()
+	if params.Expiration <= currEpoch {
+		rt.Abortf(exitcode.ErrIllegalArgument, "expiration %d must be after current epoch %d", params.Expiration, currEpoch)
+	}
+	if params.DealWeight.LessThan(big.Zero()) || params.VerifiedDealWeight.LessThan(big.Zero()) {
+		rt.Abortf(exitcode.ErrIllegalArgument, "negative deal weight %v, verified %v", params.DealWeight, params.VerifiedDealWeight)
+	}
+
+	var st State
+	rt.StateReadonly(&st)
+	info := getMinerInfo(rt, &st)
+
+	rewardStats := requestCurrentEpochBlockReward(rt)
+	pwrTotal := requestCurrentTotalPower(rt)
+	projection := ProjectSectorPledgeForWeight(info.SectorSize, params.Expiration-currEpoch, params.DealWeight, params.VerifiedDealWeight,
+		rewardStats.ThisEpochRewardSmoothed, pwrTotal.QualityAdjPowerSmoothed, rewardStats.ThisEpochBaselinePower, rt.TotalFilCircSupply())
+	return &projection
+}
+
+type ExpirationScheduleParams struct {
+	From abi.ChainEpoch // Earliest expiration epoch to include
+	To   abi.ChainEpoch // Latest expiration epoch to include
+	Page builtin.PageParams
+}
+
+type ExpirationScheduleEntry struct {
+	Epoch         abi.ChainEpoch
+	OnTimeSectors uint64          // Number of sectors expiring on time
+	EarlySectors  uint64          // Number of faulty sectors terminated early for having been faulty for FaultMaxAge
+	OnTimePledge  abi.TokenAmount // Initial pledge of the sectors expiring on time
+	ActivePower   PowerPair       // Power of the non-faulty sectors, all of which expire on time
+	FaultyPower   PowerPair       // Power of the faulty sectors, whether expiring on time or early
+}
+
+type ExpirationScheduleReturn struct {
+	Entries []ExpirationScheduleEntry // Ordered by epoch
+	Page    builtin.PageReturn
+}
+
+// Returns the miner's sector expirations, aggregated across all deadlines and partitions, at each epoch in a range.
+// Epochs are quantized to the end of the sectors' deadlines, as in the partitions' expiration queues.
+func (a Actor) ExpirationSchedule(rt Runtime, params *ExpirationScheduleParams) *ExpirationScheduleReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	if params.To < params.From {
+		rt.Abortf(exitcode.ErrIllegalArgument, "invalid epoch range [%d, %d]", params.From, params.To)
+	}
+
+	var st State
+	rt.StateReadonly(&st)
+	store := adt.AsStore(rt)
+
+	deadlines, err := st.LoadDeadlines(store)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadlines")
+
+	entries := map[abi.ChainEpoch]*ExpirationScheduleEntry{}
+	err = deadlines.ForEach(store, func(dlIdx uint64, deadline *Deadline) error {
+		partitions, err := deadline.PartitionsArray(store)
+		if err != nil {
+			return err
+		}
+		quant := st.QuantSpecForDeadline(dlIdx)
+		var partition Partition
+		return partitions.ForEach(&partition, func(pIdx int64) error {
+			return partition.ForEachExpiration(store, params.To, quant, func(epoch abi.ChainEpoch, es *ExpirationSet) error {
+				if epoch < params.From {
+					return nil
+				}
+				onTime, err := es.OnTimeSectors.Count()
+				if err != nil {
+					return err
+				}
+				early, err := es.EarlySectors.Count()
+				if err != nil {
+					return err
+				}
+				entry, ok := entries[epoch]
+				if !ok {
+					entry = &ExpirationScheduleEntry{
+						Epoch:        epoch,
+						OnTimePledge: big.Zero(),
+						ActivePower:  NewPowerPairZero(),
+						FaultyPower:  NewPowerPairZero(),
+					}
+					entries[epoch] = entry
+				}
+				entry.OnTimeSectors += onTime
+				entry.EarlySectors += early
+				entry.OnTimePledge = big.Add(entry.OnTimePledge, es.OnTimePledge)
+				entry.ActivePower = entry.ActivePower.Add(es.ActivePower)
+				entry.FaultyPower = entry.FaultyPower.Add(es.FaultyPower)
+				return nil
+			})
+		})
+	})
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load expiration queues")
+
+	sorted := make([]ExpirationScheduleEntry, 0, len(entries))
+	for _, entry := range entries {
+		sorted = append(sorted, *entry)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Epoch < sorted[j].Epoch
+	})
+
+	start, end, page, err := builtin.Paginate(uint64(len(sorted)), &params.Page)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid page")
+	return &ExpirationScheduleReturn{Entries: sorted[start:end], Page: page}
+}
+
+type BatchLimitsReturn struct {
+	PartitionSectors       uint64 // Number of sectors in each of the miner's partitions
+	DeclarationsMax        uint64 // Maximum number of declarations in a batch message
+	AddressedPartitionsMax uint64 // Maximum number of partitions addressed by a batch of declarations
+	AddressedSectorsMax    uint64 // Maximum number of sectors addressed by a batch of declarations
+	PoStPartitionsMax      uint64 // Maximum number of partitions proven by a Window PoSt, or compacted at once
+}
+
+// Returns the limits on the size of batch messages for the miner's partition size, so that clients may split
+// batches of declarations or partitions to fit within them.
+func (a Actor) BatchLimits(rt Runtime, _ *abi.EmptyValue) *BatchLimitsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	var st State
+	rt.StateReadonly(&st)
+	info := getMinerInfo(rt, &st)
+
+	return &BatchLimitsReturn{
+		PartitionSectors:       info.WindowPoStPartitionSectors,
+		DeclarationsMax:        DeclarationsMax,
+		AddressedPartitionsMax: AddressedPartitionsMax,
+		AddressedSectorsMax:    AddressedSectorsMax,
+		PoStPartitionsMax:      loadPartitionsSectorsMax(info.WindowPoStPartitionSectors),
+	}
+}
+
 //////////
 // Cron //
 //////////
@@ -2059,6 +3195,7 @@ type CronEventType = miner0.CronEventType
 const (
 	CronEventProvingDeadline          = miner0.CronEventProvingDeadline
 	CronEventProcessEarlyTerminations = miner0.CronEventProcessEarlyTerminations
+	CronEventProcessExpirations       = CronEventProcessEarlyTerminations + 1
 )
 
 func (a Actor) OnDeferredCronEvent(rt Runtime, payload *CronEventPayload) *abi.EmptyValue {
@@ -2068,9 +3205,13 @@ func (a Actor) OnDeferredCronEvent(rt Runtime, payload *CronEventPayload) *abi.E
 	case CronEventProvingDeadline:
 		handleProvingDeadline(rt)
 	case CronEventProcessEarlyTerminations:
-		if processEarlyTerminations(rt) {
+		if more, _ := processEarlyTerminations(rt, true); more {
 			scheduleEarlyTerminationWork(rt)
 		}
+	case CronEventProcessExpirations:
+		if more := processPendingExpirations(rt); more {
+			scheduleExpirationWork(rt)
+		}
 	}
 
 	var st State
@@ -2084,7 +3225,8 @@ func (a Actor) OnDeferredCronEvent(rt Runtime, payload *CronEventPayload) *abi.E
 // Utility functions & helpers
 ////////////////////////////////////////////////////////////////////////////////
 
-func processEarlyTerminations(rt Runtime) (more bool) {
+// If fromCron, the miner's notification target is notified of the terminated sectors.
+func processEarlyTerminations(rt Runtime, fromCron bool) (more bool, pledgeDelta abi.TokenAmount) {
 	store := adt.AsStore(rt)
 
 	// TODO: We're using the current power+epoch reward. Technically, we
@@ -2097,8 +3239,10 @@ func processEarlyTerminations(rt Runtime) (more bool) {
 		result           TerminationResult
 		dealsToTerminate []market.OnMinerSectorsTerminateParams
 		penalty          = big.Zero()
-		pledgeDelta      = big.Zero()
+		target           *addr.Address
+		terminated       []bitfield.BitField
 	)
+	pledgeDelta = big.Zero()
 
 	var st State
 	rt.StateTransaction(&st, func() {
@@ -2114,6 +3258,7 @@ func processEarlyTerminations(rt Runtime) (more bool) {
 		}
 
 		info := getMinerInfo(rt, &st)
+		target = info.NotificationTarget
 
 		sectors, err := LoadSectors(store, st.Sectors)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load sectors array")
@@ -2134,6 +3279,7 @@ func processEarlyTerminations(rt Runtime) (more bool) {
 			penalty = big.Add(penalty, terminationPenalty(info.SectorSize, epoch,
 				rewardStats.ThisEpochRewardSmoothed, pwrTotal.QualityAdjPowerSmoothed, sectors))
 			dealsToTerminate = append(dealsToTerminate, params)
+			terminated = append(terminated, sectorNos)
 
 			return nil
 		})
@@ -2157,11 +3303,11 @@ func processEarlyTerminations(rt Runtime) (more bool) {
 
 	// We didn't do anything, abort.
 	if result.IsEmpty() {
-		return more
+		return more, pledgeDelta
 	}
 
 	// Burn penalty.
-	burnFunds(rt, penalty)
+	builtin.BurnPenalty(rt, penalty)
 
 	// Return pledge.
 	notifyPledgeChanged(rt, pledgeDelta)
@@ -2171,8 +3317,14 @@ func processEarlyTerminations(rt Runtime) (more bool) {
 		requestTerminateDeals(rt, params.Epoch, params.DealIDs)
 	}
 
+	if fromCron {
+		allTerminated, err := bitfield.MultiMerge(terminated...)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to merge terminated sectors")
+		notifyTarget(rt, target, NotificationSectorsTerminated, allTerminated)
+	}
+
 	// reschedule cron worker, if necessary.
-	return more
+	return more, pledgeDelta
 }
 
 // Invoked at the end of the last epoch for each proving deadline.
@@ -2184,11 +3336,16 @@ func handleProvingDeadline(rt Runtime) {
 	pwrTotal := requestCurrentTotalPower(rt)
 
 	hadEarlyTerminations := false
+	hadPendingExpirations := false
 
 	powerDeltaTotal := NewPowerPairZero()
 	penaltyTotal := abi.NewTokenAmount(0)
 	pledgeDeltaTotal := abi.NewTokenAmount(0)
 
+	var notificationTarget *addr.Address
+	var expiredPreCommits []uint64
+	var releasedDeposits []PreCommitDepositRelease
+
 	var continueCron bool
 	var st State
 	rt.StateTransaction(&st, func() {
@@ -2205,22 +3362,33 @@ func handleProvingDeadline(rt Runtime) {
 			// Process pending worker change if any
 			info := getMinerInfo(rt, &st)
 			processPendingWorker(info, rt, &st)
+			notificationTarget = info.NotificationTarget
 		}
 
 		{
-			depositToBurn, err := st.CleanUpExpiredPreCommits(store, currEpoch)
+			cleanUp, err := st.CleanUpExpiredPreCommits(store, currEpoch)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to expire pre-committed sectors")
+			for _, expired := range cleanUp.Expired {
+				expiredPreCommits = append(expiredPreCommits, uint64(expired.SectorNumber))
+				releasedDeposits = append(releasedDeposits, PreCommitDepositRelease{
+					SectorNumber: expired.SectorNumber,
+					Burned:       expired.Burned,
+					Refunded:     big.Zero(),
+				})
+			}
 
-			err = st.ApplyPenalty(depositToBurn)
+			err = st.ApplyPenalty(cleanUp.DepositBurned)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to apply penalty")
 		}
 
 		// Record whether or not we _had_ early terminations in the queue before this method.
 		// That way, don't re-schedule a cron callback if one is already scheduled.
 		hadEarlyTerminations = havePendingEarlyTerminations(rt, &st)
+		hadPendingExpirations = havePendingExpirations(rt, &st)
 
 		{
-			result, err := st.AdvanceDeadline(store, currEpoch)
+			// Advance the deadline that has ended, even if this event was deferred into the next one.
+			result, err := st.AdvanceDeadline(store, st.LastDeadlineEnd(currEpoch))
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to advance deadline")
 
 			// Faults detected by this missed PoSt pay no penalty, but sectors that were already faulty
@@ -2251,8 +3419,10 @@ func handleProvingDeadline(rt Runtime) {
 
 	// Remove power for new faults, and burn penalties.
 	requestUpdatePower(rt, powerDeltaTotal)
-	burnFunds(rt, penaltyTotal)
+	builtin.BurnPenalty(rt, penaltyTotal)
 	notifyPledgeChanged(rt, pledgeDeltaTotal)
+	notifyTarget(rt, notificationTarget, NotificationPreCommitsExpired, bitfield.NewFromSet(expiredPreCommits))
+	emitPreCommitDepositsReleased(rt, releasedDeposits)
 
 	// Schedule cron callback for next deadline's last epoch.
 	if continueCron {
@@ -2271,7 +3441,7 @@ func handleProvingDeadline(rt Runtime) {
 	// handle them at the next epoch.
 	if !hadEarlyTerminations && hasEarlyTerminations {
 		// First, try to process some of these terminations.
-		if processEarlyTerminations(rt) {
+		if more, _ := processEarlyTerminations(rt, true); more {
 			// If that doesn't work, just defer till the next epoch.
 			scheduleEarlyTerminationWork(rt)
 		}
@@ -2279,6 +3449,12 @@ func handleProvingDeadline(rt Runtime) {
 		// callback already scheduled. In that case, we'll already have
 		// processed AddressedSectorsMax terminations this epoch.
 	}
+
+	// If more partitions expired than could be processed, continue processing them in the next epoch,
+	// unless a cron callback is already scheduled to do so.
+	if !hadPendingExpirations && havePendingExpirations(rt, &st) {
+		scheduleExpirationWork(rt)
+	}
 }
 
 // Check expiry is exactly *the epoch before* the start of a proving period.
@@ -2374,6 +3550,25 @@ func requestUpdatePower(rt Runtime, delta PowerPair) {
 	builtin.RequireSuccess(rt, code, "failed to update power with %v", delta)
 }
 
+// Records verified consensus faults in the power actor's history of the miner's faults.
+func requestRecordConsensusFaults(rt Runtime, faults []*runtime.ConsensusFault, ineligibleUntil abi.ChainEpoch) {
+	records := make([]power.ConsensusFaultRecord, len(faults))
+	for i, fault := range faults {
+		records[i] = power.ConsensusFaultRecord{Epoch: fault.Epoch, Type: fault.Type}
+	}
+	code := rt.Send(
+		builtin.StoragePowerActorAddr,
+		builtin.MethodsPower.RecordConsensusFaults,
+		&power.RecordConsensusFaultsParams{
+			Faults:          records,
+			IneligibleUntil: ineligibleUntil,
+		},
+		abi.NewTokenAmount(0),
+		&builtin.Discard{},
+	)
+	builtin.RequireSuccess(rt, code, "failed to record consensus faults")
+}
+
 func requestTerminateDeals(rt Runtime, epoch abi.ChainEpoch, dealIDs []abi.DealID) {
 	for len(dealIDs) > 0 {
 		size := min64(cbg.MaxLength, uint64(len(dealIDs)))
@@ -2405,17 +3600,61 @@ func havePendingEarlyTerminations(rt Runtime, st *State) bool {
 	return !noEarlyTerminations
 }
 
-func verifyWindowedPost(rt Runtime, challengeEpoch abi.ChainEpoch, sectors []*SectorOnChainInfo, proofs []proof.PoStProof) error {
-	minerActorID, err := addr.IDFromAddress(rt.Receiver())
-	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "runtime provided bad receiver address %v", rt.Receiver())
+// Processes expired sectors that were pending because more partitions expired than could be processed in
+// the deadline's cron event, returning whether any remain pending.
+func processPendingExpirations(rt Runtime) (more bool) {
+	store := adt.AsStore(rt)
+	pledgeDelta := big.Zero()
+	powerDelta := NewPowerPairZero()
+	var hadEarlyTerminations bool
 
-	// Regenerate challenge randomness, which must match that generated for the proof.
+	var st State
+	rt.StateTransaction(&st, func() {
+		hadEarlyTerminations = havePendingEarlyTerminations(rt, &st)
+
+		var err error
+		pledgeDelta, powerDelta, more, err = st.ProcessPendingExpirations(store, rt.CurrEpoch(), ExpiringPartitionsMax)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to process pending expirations")
+	})
+
+	requestUpdatePower(rt, powerDelta)
+	notifyPledgeChanged(rt, pledgeDelta)
+
+	// Schedule processing of any faulty sectors that expired early, as the deadline's cron event would have.
+	if !hadEarlyTerminations && havePendingEarlyTerminations(rt, &st) {
+		if more, _ := processEarlyTerminations(rt, true); more {
+			scheduleEarlyTerminationWork(rt)
+		}
+	}
+	return more
+}
+
+func scheduleExpirationWork(rt Runtime) {
+	enrollCronEvent(rt, rt.CurrEpoch()+1, &CronEventPayload{
+		EventType: CronEventProcessExpirations,
+	})
+}
+
+func havePendingExpirations(rt Runtime, st *State) bool {
+	noPendingExpirations, err := st.ExpirationsPending.IsEmpty()
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to count pending expirations")
+	return !noPendingExpirations
+}
+
+// Returns the entropy mixed into the randomness beacon draw of a miner's Window PoSt challenge seed.
+// The seed is drawn with domain separation tag WindowedPoStChallengeSeed at the deadline's challenge epoch.
+func WindowPoStChallengeEntropy(minerAddr addr.Address) ([]byte, error) {
 	var addrBuf bytes.Buffer
-	receiver := rt.Receiver()
-	err = receiver.MarshalCBOR(&addrBuf)
-	builtin.RequireNoErr(rt, err, exitcode.ErrSerialization, "failed to marshal address for window post challenge")
-	postRandomness := rt.GetRandomnessFromBeacon(crypto.DomainSeparationTag_WindowedPoStChallengeSeed, challengeEpoch, addrBuf.Bytes())
+	if err := minerAddr.MarshalCBOR(&addrBuf); err != nil {
+		return nil, xerrors.Errorf("failed to marshal address for window post challenge: %w", err)
+	}
+	return addrBuf.Bytes(), nil
+}
 
+// Computes the public inputs against which a miner's Window PoSt is verified, from the challenge seed
+// and the sectors challenged (with stand-ins already substituted for ignored sectors).
+// Proving software should use this to derive challenges exactly as on-chain verification does.
+func WindowPoStVerifyInfo(minerID abi.ActorID, seed abi.PoStRandomness, sectors []*SectorOnChainInfo, proofs []proof.PoStProof) proof.WindowPoStVerifyInfo {
 	sectorProofInfo := make([]proof.SectorInfo, len(sectors))
 	for i, s := range sectors {
 		sectorProofInfo[i] = proof.SectorInfo{
@@ -2424,14 +3663,29 @@ func verifyWindowedPost(rt Runtime, challengeEpoch abi.ChainEpoch, sectors []*Se
 			SealedCID:    s.SealedCID,
 		}
 	}
-
-	// Get public inputs
-	pvInfo := proof.WindowPoStVerifyInfo{
-		Randomness:        abi.PoStRandomness(postRandomness),
+	return proof.WindowPoStVerifyInfo{
+		Randomness:        seed,
 		Proofs:            proofs,
 		ChallengedSectors: sectorProofInfo,
-		Prover:            abi.ActorID(minerActorID),
+		Prover:            minerID,
 	}
+}
+
+func windowPoStChallengeSeed(rt Runtime, challengeEpoch abi.ChainEpoch) abi.PoStRandomness {
+	entropy, err := WindowPoStChallengeEntropy(rt.Receiver())
+	builtin.RequireNoErr(rt, err, exitcode.ErrSerialization, "failed to compute window post challenge entropy")
+	return abi.PoStRandomness(rt.GetRandomnessFromBeacon(crypto.DomainSeparationTag_WindowedPoStChallengeSeed, challengeEpoch, entropy))
+}
+
+func verifyWindowedPost(rt Runtime, challengeEpoch abi.ChainEpoch, sectors []*SectorOnChainInfo, proofs []proof.PoStProof) error {
+	minerActorID, err := addr.IDFromAddress(rt.Receiver())
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "runtime provided bad receiver address %v", rt.Receiver())
+
+	// Regenerate challenge randomness, which must match that generated for the proof.
+	postRandomness := windowPoStChallengeSeed(rt, challengeEpoch)
+
+	// Get public inputs
+	pvInfo := WindowPoStVerifyInfo(abi.ActorID(minerActorID), postRandomness, sectors, proofs)
 
 	// Verify the PoSt Proof
 	err = rt.VerifyPoSt(pvInfo)
@@ -2451,6 +3705,7 @@ type SealVerifyStuff struct {
 	abi.RegisteredSealProof
 	Proof   []byte
 	DealIDs []abi.DealID
+	Pieces  []abi.PieceInfo // Attested pieces of a sector without deals, from which CommD is computed if present
 	abi.SectorNumber
 	SealRandEpoch abi.ChainEpoch // Used to tie the seal to a chain.
 }
@@ -2460,10 +3715,15 @@ func getVerifyInfo(rt Runtime, params *SealVerifyStuff) *proof.SealVerifyInfo {
 		rt.Abortf(exitcode.ErrForbidden, "too early to prove sector")
 	}
 
-	commDs := requestUnsealedSectorCIDs(rt, &market.SectorDataSpec{
-		SectorType: params.RegisteredSealProof,
-		DealIDs:    params.DealIDs,
-	})
+	var commD cid.Cid
+	if params.Pieces != nil {
+		commD = computeAttestedUnsealedSectorCID(rt, params.RegisteredSealProof, params.Pieces)
+	} else {
+		commD = requestUnsealedSectorCIDs(rt, &market.SectorDataSpec{
+			SectorType: params.RegisteredSealProof,
+			DealIDs:    params.DealIDs,
+		})[0]
+	}
 
 	minerActorID, err := addr.IDFromAddress(rt.Receiver())
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "runtime provided non-ID receiver address %v", rt.Receiver())
@@ -2487,10 +3747,17 @@ func getVerifyInfo(rt Runtime, params *SealVerifyStuff) *proof.SealVerifyInfo {
 		Proof:                 params.Proof,
 		Randomness:            abi.SealRandomness(svInfoRandomness),
 		SealedCID:             params.SealedCID,
-		UnsealedCID:           commDs[0],
+		UnsealedCID:           commD,
 	}
 }
 
+// Computes the unsealed sector CID from the attested pieces of a sector.
+func computeAttestedUnsealedSectorCID(rt Runtime, sealProof abi.RegisteredSealProof, pieces []abi.PieceInfo) cid.Cid {
+	commD, err := rt.ComputeUnsealedSectorCID(sealProof, pieces)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to compute unsealed sector CID from attested pieces")
+	return commD
+}
+
 // Requests the storage market actor compute the unsealed sector CID from a sector's deals.
 func requestUnsealedSectorCIDs(rt Runtime, dataCommitmentInputs ...*market.SectorDataSpec) []cid.Cid {
 	if len(dataCommitmentInputs) == 0 {
@@ -2515,6 +3782,46 @@ func requestUnsealedSectorCIDs(rt Runtime, dataCommitmentInputs ...*market.Secto
 	return unsealedCIDs
 }
 
+// Aborts if any pre-committed sector would expire before the end of one of its deals, reporting the offending deals.
+func validateDealEndEpochs(rt Runtime, precommits []miner0.SectorPreCommitInfo) {
+	var dealIDs []abi.DealID
+	for _, precommit := range precommits {
+		dealIDs = append(dealIDs, precommit.DealIDs...)
+	}
+	// Short-circuit if there are no deals in any of the sectors.
+	if len(dealIDs) == 0 {
+		return
+	}
+
+	var ret market.DealEndEpochsReturn
+	code := rt.Send(
+		builtin.StorageMarketActorAddr,
+		builtin.MethodsMarket.DealEndEpochs,
+		&market.DealEndEpochsParams{DealIDs: dealIDs},
+		abi.NewTokenAmount(0),
+		&ret,
+	)
+	builtin.RequireSuccess(rt, code, "failed to get deal end epochs")
+	if len(ret.Deals) != len(dealIDs) {
+		rt.Abortf(exitcode.ErrIllegalState, "deal end epochs request returned %d records, expected %d", len(ret.Deals), len(dealIDs))
+	}
+
+	deals := ret.Deals
+	for _, precommit := range precommits {
+		var endAfterExpiration []abi.DealID
+		for _, deal := range deals[:len(precommit.DealIDs)] {
+			if deal.EndEpoch > precommit.Expiration {
+				endAfterExpiration = append(endAfterExpiration, deal.DealID)
+			}
+		}
+		if len(endAfterExpiration) > 0 {
+			rt.Abortf(exitcode.ErrIllegalArgument, "sector %d expiration %d is before the end of deals %v",
+				precommit.SectorNumber, precommit.Expiration, endAfterExpiration)
+		}
+		deals = deals[len(precommit.DealIDs):]
+	}
+}
+
 func requestDealWeights(rt Runtime, sectors []market.SectorDeals) *market.VerifyDealsForActivationReturn {
 	// Short-circuit if there are no deals in any of the sectors.
 	dealCount := 0
@@ -2608,13 +3915,6 @@ func resolveWorkerAddress(rt Runtime, raw addr.Address) addr.Address {
 	return resolved
 }
 
-func burnFunds(rt Runtime, amt abi.TokenAmount) {
-	if amt.GreaterThan(big.Zero()) {
-		code := rt.Send(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, amt, &builtin.Discard{})
-		builtin.RequireSuccess(rt, code, "failed to burn funds")
-	}
-}
-
 func notifyPledgeChanged(rt Runtime, pledgeDelta abi.TokenAmount) {
 	if !pledgeDelta.IsZero() {
 		code := rt.Send(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdatePledgeTotal, &pledgeDelta, big.Zero(), &builtin.Discard{})
@@ -2817,6 +4117,19 @@ func checkControlAddresses(rt Runtime, controlAddrs []addr.Address) {
 	}
 }
 
+// Checks whether an address is the miner's owner, worker, or one of its control addresses.
+func isControlAddress(info *MinerInfo, a addr.Address) bool {
+	if a == info.Owner || a == info.Worker {
+		return true
+	}
+	for _, ca := range info.ControlAddresses {
+		if a == ca {
+			return true
+		}
+	}
+	return false
+}
+
 func checkPeerInfo(rt Runtime, peerID abi.PeerID, multiaddrs []abi.Multiaddrs) {
 	if len(peerID) > MaxPeerIDLength {
 		rt.Abortf(exitcode.ErrIllegalArgument, "peer ID size of %d exceeds maximum size of %d", peerID, MaxPeerIDLength)
@@ -2832,4 +4145,16 @@ func checkPeerInfo(rt Runtime, peerID abi.PeerID, multiaddrs []abi.Multiaddrs) {
 	if totalSize > MaxMultiaddrData {
 		rt.Abortf(exitcode.ErrIllegalArgument, "multiaddr size of %d exceeds maximum of %d", totalSize, MaxMultiaddrData)
 	}
+
+	// An empty peer ID is permitted, indicating the miner has none.
+	if len(peerID) > 0 {
+		if err := validatePeerID(peerID); err != nil {
+			rt.Abortf(exitcode.ErrIllegalArgument, "invalid peer ID: %s", err)
+		}
+	}
+	for i, ma := range multiaddrs {
+		if err := validateMultiaddr(ma); err != nil {
+			rt.Abortf(exitcode.ErrIllegalArgument, "invalid multiaddr %d: %s", i, err)
+		}
+	}
 }
@@ -1,6 +1,8 @@
 package miner_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	addr "github.com/filecoin-project/go-address"
@@ -18,6 +20,7 @@ import (
 	"github.com/filecoin-project/specs-actors/v5/actors/util/smoothing"
 	"github.com/filecoin-project/specs-actors/v5/support/mock"
 	tutil "github.com/filecoin-project/specs-actors/v5/support/testing"
+	"github.com/ipfs/go-cid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -631,6 +634,87 @@ func TestPreCommitBatch(t *testing.T) {
 			actor.preCommitSectorBatch(rt, &miner.PreCommitSectorBatchParams{Sectors: sectors}, preCommitBatchConf{firstForMiner: true})
 		})
 	})
+
+	t.Run("records declared region", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		rt := builderForHarness(actor).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		precommitEpoch := periodOffset + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt)
+		dlInfo := actor.deadline(rt)
+
+		sectorExpiration := dlInfo.PeriodEnd() + defaultSectorExpiration*miner.WPoStProvingPeriod
+		sectors := []miner0.SectorPreCommitInfo{
+			*actor.makePreCommit(100, precommitEpoch-1, sectorExpiration, nil),
+			*actor.makePreCommit(101, precommitEpoch-1, sectorExpiration, nil),
+		}
+		actor.preCommitSectorBatch(rt, &miner.PreCommitSectorBatchParams{Sectors: sectors, Region: "eu-west"},
+			preCommitBatchConf{firstForMiner: true})
+		sectors = []miner0.SectorPreCommitInfo{
+			*actor.makePreCommit(102, precommitEpoch-1, sectorExpiration, nil),
+		}
+		actor.preCommitSectorBatch(rt, &miner.PreCommitSectorBatchParams{Sectors: sectors}, preCommitBatchConf{})
+
+		regions := actor.getSectorRegions(rt, bitfield.NewFromSet([]uint64{101, 102}))
+		require.Len(t, regions, 1)
+		assert.Equal(t, "eu-west", regions[0].Region)
+		assertBitfieldEquals(t, regions[0].Sectors, 101)
+	})
+
+	t.Run("region too long rejects batch", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		rt := builderForHarness(actor).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		precommitEpoch := periodOffset + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt)
+		dlInfo := actor.deadline(rt)
+
+		sectorExpiration := dlInfo.PeriodEnd() + defaultSectorExpiration*miner.WPoStProvingPeriod
+		sectors := []miner0.SectorPreCommitInfo{
+			*actor.makePreCommit(100, precommitEpoch-1, sectorExpiration, nil),
+		}
+		region := strings.Repeat("a", miner.MaxSectorRegionLength+1)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "region of length", func() {
+			actor.preCommitSectorBatch(rt, &miner.PreCommitSectorBatchParams{Sectors: sectors, Region: region}, preCommitBatchConf{firstForMiner: true})
+		})
+	})
+
+	t.Run("deals ending after sector expiration reject batch", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		rt := builderForHarness(actor).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		precommitEpoch := periodOffset + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt)
+		dlInfo := actor.deadline(rt)
+
+		sectorExpiration := dlInfo.PeriodEnd() + defaultSectorExpiration*miner.WPoStProvingPeriod
+		sectors := []miner0.SectorPreCommitInfo{
+			*actor.makePreCommit(100, precommitEpoch-1, sectorExpiration, []abi.DealID{1}),
+			*actor.makePreCommit(101, precommitEpoch-1, sectorExpiration, []abi.DealID{2, 3, 4}),
+		}
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		expectQueryNetworkInfo(rt, actor)
+		rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.DealEndEpochs,
+			&market.DealEndEpochsParams{DealIDs: []abi.DealID{1, 2, 3, 4}}, big.Zero(),
+			&market.DealEndEpochsReturn{Deals: []market.DealEndEpoch{
+				{DealID: 1, EndEpoch: sectorExpiration},
+				{DealID: 2, EndEpoch: sectorExpiration + 1},
+				{DealID: 3, EndEpoch: sectorExpiration - 1},
+				{DealID: 4, EndEpoch: sectorExpiration + 2},
+			}}, exitcode.Ok)
+		expectedMsg := fmt.Sprintf("sector 101 expiration %d is before the end of deals [2 4]", sectorExpiration)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, expectedMsg, func() {
+			rt.Call(actor.a.PreCommitSectorBatch, &miner.PreCommitSectorBatchParams{Sectors: sectors})
+		})
+		rt.Verify()
+	})
 }
 
 func TestProveCommit(t *testing.T) {
@@ -688,6 +772,8 @@ func TestProveCommit(t *testing.T) {
 		assert.Equal(t, precommit.Info.Expiration, sector.Expiration)
 		assert.Equal(t, precommit.DealWeight, sector.DealWeight)
 		assert.Equal(t, precommit.VerifiedDealWeight, sector.VerifiedDealWeight)
+		assert.Equal(t, precommit.Info.SealRandEpoch, sector.SealRandEpoch)
+		assert.Equal(t, proveCommitEpoch, sector.Activation)
 
 		// expect precommit to have been removed
 		st = getState(rt)
@@ -860,8 +946,8 @@ func TestProveCommit(t *testing.T) {
 		})
 		rt.Reset()
 
-		// Too late.
-		rt.SetEpoch(precommitEpoch + miner.MaxProveCommitDuration[precommit.Info.SealProof] + 1)
+		// Too late, even for a partial refund of the deposit.
+		rt.SetEpoch(precommitEpoch + miner.MaxProveCommitDuration[precommit.Info.SealProof] + miner.ExpiredPreCommitCleanUpDelay + 1)
 		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
 			actor.proveCommitSectorAndConfirm(rt, precommit, makeProveCommit(sectorNo), proveCommitConf{})
 		})
@@ -1044,6 +1130,56 @@ func TestProveCommit(t *testing.T) {
 		actor.checkState(rt)
 	})
 
+	t.Run("late prove commit refunds part of the deposit", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		rt := builderForHarness(actor).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		precommitEpoch := periodOffset + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt)
+		deadline := actor.deadline(rt)
+
+		sectorNo := abi.SectorNumber(100)
+		params := actor.makePreCommit(sectorNo, precommitEpoch-1, deadline.PeriodEnd()+defaultSectorExpiration*miner.WPoStProvingPeriod, nil)
+		precommit := actor.preCommitSector(rt, params, preCommitConf{}, true)
+		availableBefore, err := getState(rt).GetAvailableBalance(rt.Balance())
+		require.NoError(t, err)
+
+		// Prove at the last epoch of the clean up delay.
+		rt.SetEpoch(precommitEpoch + miner.MaxProveCommitDuration[precommit.Info.SealProof] + miner.ExpiredPreCommitCleanUpDelay)
+		actor.proveCommitSector(rt, precommit, makeProveCommit(sectorNo))
+
+		// Confirmation releases the deposit without activating the sector.
+		refund := miner.LatePreCommitDepositRefund(precommit.PreCommitDeposit)
+		assert.True(t, refund.GreaterThan(big.Zero()))
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, big.Sub(precommit.PreCommitDeposit, refund), nil, exitcode.Ok)
+		rt.SetCaller(builtin.StoragePowerActorAddr, builtin.StoragePowerActorCodeID)
+		rt.ExpectValidateCallerAddr(builtin.StoragePowerActorAddr)
+		rt.Call(actor.a.ConfirmSectorProofsValid, &builtin.ConfirmSectorProofsParams{Sectors: []abi.SectorNumber{sectorNo}})
+		rt.Verify()
+		rt.ExpectEmitted(miner.EventPreCommitDepositsReleased, &miner.PreCommitDepositsReleasedEvent{
+			Releases: []miner.PreCommitDepositRelease{{
+				SectorNumber: sectorNo,
+				Burned:       big.Sub(precommit.PreCommitDeposit, refund),
+				Refunded:     refund,
+			}},
+		})
+
+		st := getState(rt)
+		_, found, err := st.GetPrecommittedSector(rt.AdtStore(), sectorNo)
+		require.NoError(t, err)
+		assert.False(t, found)
+		_, found, err = st.GetSector(rt.AdtStore(), sectorNo)
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.Equal(t, big.Zero(), st.PreCommitDeposits)
+		availableAfter, err := st.GetAvailableBalance(rt.Balance())
+		require.NoError(t, err)
+		assert.Equal(t, big.Add(availableBefore, refund), availableAfter)
+		actor.checkState(rt)
+	})
+
 	t.Run("verify proof does not vest funds", func(t *testing.T) {
 		actor := newHarness(t, periodOffset)
 		actor.setProofType(abi.RegisteredSealProof_StackedDrg32GiBV1_1)
@@ -1208,3 +1344,223 @@ func TestAggregateProveCommit(t *testing.T) {
 
 	})
 }
+
+func TestAttestSectorPieces(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+	precommitEpoch := periodOffset + 1
+	proveCommitEpoch := precommitEpoch + miner.PreCommitChallengeDelay + 1
+
+	makePieces := func(prefix string) []abi.PieceInfo {
+		return []abi.PieceInfo{
+			{Size: abi.PaddedPieceSize(actor.sectorSize / 2), PieceCID: tutil.MakeCID(prefix+"-1", &market.PieceCIDPrefix)},
+			{Size: abi.PaddedPieceSize(actor.sectorSize / 2), PieceCID: tutil.MakeCID(prefix+"-2", &market.PieceCIDPrefix)},
+		}
+	}
+
+	setup := func(t *testing.T, sectorNos ...abi.SectorNumber) (*mock.Runtime, []*miner.SectorPreCommitOnChainInfo) {
+		rt := builder.Build(t)
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt)
+		expiration := actor.deadline(rt).PeriodEnd() + defaultSectorExpiration*miner.WPoStProvingPeriod
+
+		var precommits []*miner.SectorPreCommitOnChainInfo
+		for i, sectorNo := range sectorNos {
+			params := actor.makePreCommit(sectorNo, precommitEpoch-1, expiration, nil)
+			precommits = append(precommits, actor.preCommitSector(rt, params, preCommitConf{}, i == 0))
+		}
+		return rt, precommits
+	}
+
+	t.Run("attested pieces are proven and recorded in sector", func(t *testing.T) {
+		rt, precommits := setup(t, 100)
+		pieces := makePieces("piece")
+
+		// Pieces may be attested up to the interactive challenge epoch.
+		rt.SetEpoch(precommitEpoch + miner.PreCommitChallengeDelay)
+		precommit := actor.attestSectorPieces(rt, 100, pieces)
+		assert.Equal(t, precommits[0].Info, precommit.Info)
+
+		// The data commitment is computed from the pieces rather than requested from the market.
+		rt.SetEpoch(proveCommitEpoch)
+		sector := actor.proveCommitSectorAndConfirm(rt, precommit, makeProveCommit(100), proveCommitConf{})
+		assert.Equal(t, pieces, sector.Pieces)
+		assert.Empty(t, sector.DealIDs)
+		actor.checkState(rt)
+	})
+
+	t.Run("aggregate proof with attested and unattested sectors", func(t *testing.T) {
+		rt, precommits := setup(t, 100, 101, 102, 103)
+		precommits[1] = actor.attestSectorPieces(rt, 101, makePieces("piece-101"))
+		precommits[3] = actor.attestSectorPieces(rt, 103, makePieces("piece-103"))
+
+		rt.SetEpoch(proveCommitEpoch)
+		rt.SetBalance(big.Mul(big.NewInt(1000), big.NewInt(1e18)))
+		sectorNos := bitfield.NewFromSet([]uint64{100, 101, 102, 103})
+		actor.proveCommitAggregateSector(rt, proveCommitConf{}, precommits, makeProveCommitAggregate(sectorNos))
+
+		for _, precommit := range precommits {
+			assert.Equal(t, precommit.Pieces, actor.getSector(rt, precommit.Info.SectorNumber).Pieces)
+		}
+		assert.Nil(t, actor.getSector(rt, 100).Pieces)
+		assert.Len(t, actor.getSector(rt, 101).Pieces, 2)
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects invalid attestations", func(t *testing.T) {
+		rt, _ := setup(t, 100)
+		expiration := actor.deadline(rt).PeriodEnd() + defaultSectorExpiration*miner.WPoStProvingPeriod
+		actor.preCommitSector(rt, actor.makePreCommit(101, precommitEpoch-1, expiration, []abi.DealID{1}), preCommitConf{}, false)
+		pieces := makePieces("piece")
+
+		attest := func(sectorNo abi.SectorNumber, pieces []abi.PieceInfo) {
+			rt.Call(actor.a.AttestSectorPieces, makeAttestSectorPieces(sectorNo, pieces))
+		}
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "no pieces to attest", func() {
+			attest(100, nil)
+		})
+
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "invalid piece CID", func() {
+			attest(100, []abi.PieceInfo{{Size: pieces[0].Size, PieceCID: tutil.MakeCID("piece", nil)}})
+		})
+
+		rt.ExpectValidateCallerAddr(append(actor.controlAddrs, actor.owner, actor.worker)...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrNotFound, "no pre-committed sector 102", func() {
+			attest(102, pieces)
+		})
+
+		rt.ExpectValidateCallerAddr(append(actor.controlAddrs, actor.owner, actor.worker)...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "with deals", func() {
+			attest(101, pieces)
+		})
+
+		rt.ExpectValidateCallerAddr(append(actor.controlAddrs, actor.owner, actor.worker)...)
+		rt.ExpectComputeUnsealedSectorCID(actor.sealProofType, pieces, cid.Undef, fmt.Errorf("pieces exceed sector size"))
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "invalid pieces for sector 100", func() {
+			attest(100, pieces)
+		})
+
+		rt.SetCaller(tutil.NewIDAddr(t, 1234), builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(append(actor.controlAddrs, actor.owner, actor.worker)...)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			attest(100, pieces)
+		})
+
+		actor.attestSectorPieces(rt, 100, pieces)
+		rt.ExpectValidateCallerAddr(append(actor.controlAddrs, actor.owner, actor.worker)...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "already attested", func() {
+			attest(100, makePieces("other"))
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects attestation after interactive challenge epoch", func(t *testing.T) {
+		rt, _ := setup(t, 100)
+		rt.SetEpoch(precommitEpoch + miner.PreCommitChallengeDelay + 1)
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(append(actor.controlAddrs, actor.owner, actor.worker)...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "too late to attest pieces of sector 100", func() {
+			rt.Call(actor.a.AttestSectorPieces, makeAttestSectorPieces(100, makePieces("piece")))
+		})
+		actor.checkState(rt)
+	})
+}
+
+func TestDesignatePreCommitAggregator(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+	precommitEpoch := periodOffset + 1
+	proveCommitEpoch := precommitEpoch + miner.PreCommitChallengeDelay + 1
+	aggregator := tutil.NewIDAddr(t, 1000)
+
+	setup := func(t *testing.T, sectorNos ...abi.SectorNumber) (*mock.Runtime, []*miner.SectorPreCommitOnChainInfo) {
+		rt := builder.Build(t)
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt)
+		expiration := actor.deadline(rt).PeriodEnd() + defaultSectorExpiration*miner.WPoStProvingPeriod
+
+		var precommits []*miner.SectorPreCommitOnChainInfo
+		for i, sectorNo := range sectorNos {
+			params := actor.makePreCommit(sectorNo, precommitEpoch-1, expiration, nil)
+			precommits = append(precommits, actor.preCommitSector(rt, params, preCommitConf{}, i == 0))
+		}
+		return rt, precommits
+	}
+
+	t.Run("designated aggregator proves sectors", func(t *testing.T) {
+		rt, precommits := setup(t, 100, 101, 102, 103)
+		sectorNos := bitfield.NewFromSet([]uint64{100, 101, 102, 103})
+		actor.designatePreCommitAggregator(rt, aggregator, sectorNos)
+		designated, err := getState(rt).IsPreCommitAggregator(rt.AdtStore(), aggregator, sectorNos)
+		require.NoError(t, err)
+		assert.True(t, designated)
+
+		rt.SetEpoch(proveCommitEpoch)
+		rt.SetBalance(big.Mul(big.NewInt(1000), big.NewInt(1e18)))
+		actor.proveCommitAggregateSector(rt, proveCommitConf{caller: aggregator}, precommits, makeProveCommitAggregate(sectorNos))
+
+		for _, precommit := range precommits {
+			assert.Equal(t, rt.Epoch(), actor.getSector(rt, precommit.Info.SectorNumber).Activation)
+		}
+		// Designations are removed with the pre-commitments.
+		designated, err = getState(rt).IsPreCommitAggregator(rt.AdtStore(), aggregator, sectorNos)
+		require.NoError(t, err)
+		assert.False(t, designated)
+		actor.checkState(rt)
+	})
+
+	t.Run("aggregator cannot prove sectors not designating it", func(t *testing.T) {
+		rt, _ := setup(t, 100, 101, 102, 103)
+		actor.designatePreCommitAggregator(rt, aggregator, bitfield.NewFromSet([]uint64{100, 101, 102}))
+		other := tutil.NewIDAddr(t, 1001)
+		actor.designatePreCommitAggregator(rt, other, bitfield.NewFromSet([]uint64{103}))
+
+		rt.SetEpoch(proveCommitEpoch)
+		params := makeProveCommitAggregate(bitfield.NewFromSet([]uint64{100, 101, 102, 103}))
+		for _, caller := range []addr.Address{aggregator, other} {
+			rt.SetCaller(caller, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAny()
+			rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "not the designated aggregator", func() {
+				rt.Call(actor.a.ProveCommitAggregate, params)
+			})
+		}
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects invalid designations", func(t *testing.T) {
+		rt, _ := setup(t, 100)
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(append(actor.controlAddrs, actor.owner, actor.worker)...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrNotFound, "no pre-committed sector 101", func() {
+			rt.Call(actor.a.DesignatePreCommitAggregator, &miner.DesignatePreCommitAggregatorParams{
+				SectorNumbers: bitfield.NewFromSet([]uint64{100, 101}),
+				Aggregator:    aggregator,
+			})
+		})
+
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "no sectors", func() {
+			rt.Call(actor.a.DesignatePreCommitAggregator, &miner.DesignatePreCommitAggregatorParams{
+				SectorNumbers: bitfield.New(),
+				Aggregator:    aggregator,
+			})
+		})
+
+		rt.SetCaller(aggregator, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(append(actor.controlAddrs, actor.owner, actor.worker)...)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(actor.a.DesignatePreCommitAggregator, &miner.DesignatePreCommitAggregatorParams{
+				SectorNumbers: bitfield.NewFromSet([]uint64{100}),
+				Aggregator:    aggregator,
+			})
+		})
+		actor.checkState(rt)
+	})
+}
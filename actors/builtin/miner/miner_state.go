@@ -7,6 +7,7 @@ import (
 
 	addr "github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-bitfield"
+	rlepluslazy "github.com/filecoin-project/go-bitfield/rle"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/dline"
@@ -40,7 +41,7 @@ type State struct {
 	InitialPledge abi.TokenAmount // Sum of initial pledge requirements of all active sectors
 
 	// Sectors that have been pre-committed but not yet proven.
-	PreCommittedSectors cid.Cid // Map, HAMT[SectorNumber]SectorPreCommitOnChainInfo
+	PreCommittedSectors cid.Cid `adt:"map,PreCommitMap,abi.SectorNumber,SectorPreCommitOnChainInfo,builtin.DefaultHamtBitwidth"` // Map, HAMT[SectorNumber]SectorPreCommitOnChainInfo
 
 	// PreCommittedSectorsCleanUp maintains the state required to cleanup expired PreCommittedSectors.
 	PreCommittedSectorsCleanUp cid.Cid // BitFieldQueue (AMT[Epoch]*BitField)
@@ -80,8 +81,29 @@ type State struct {
 
 	// True when miner cron is active, false otherwise
 	DeadlineCronActive bool
+
+	// Deadlines with expired sectors yet to be processed, because more partitions expired than can be
+	// processed in a single cron event. Processing continues in subsequent epochs until this is empty.
+	ExpirationsPending bitfield.BitField
+
+	// Rolling counters of window PoSt submissions and faults, as an on-chain signal of the miner's reliability.
+	ReliabilityStats ReliabilityStats
+
+	// Regions in which the miner has declared sectors to be stored, for clients with data residency requirements.
+	SectorRegions cid.Cid // Map, HAMT[region]BitField
+
+	// Version of the schema of this state, so that tooling can decode states without knowing which version of
+	// the actor wrote them. States written before schema versioning have no such field.
+	SchemaVersion uint64
+
+	// Addresses designated by the miner to prove pre-committed sectors in an aggregate on its behalf.
+	// Invariant: keys(PreCommitAggregators) ⊆ keys(PreCommittedSectors).
+	PreCommitAggregators cid.Cid // Map, HAMT[SectorNumber]address
 }
 
+// The schema version of states written by this version of the actor, which is the actors version.
+const StateSchemaVersion = 5
+
 // Bitwidth of AMTs determined empirically from mutation patterns and projections of mainnet data.
 const PrecommitCleanUpAmtBitwidth = 6
 const SectorsAmtBitwidth = 5
@@ -127,6 +149,13 @@ type MinerInfo struct {
 	// A proposed new owner account for this miner.
 	// Must be confirmed by a message from the pending address itself.
 	PendingOwnerAddress *addr.Address
+
+	// The policy by which new sectors are assigned to deadlines.
+	DeadlineAssignment DeadlineAssignmentPolicy
+
+	// An address notified when pre-commits expire or sectors are terminated by cron (optional).
+	// Must be an ID address.
+	NotificationTarget *addr.Address
 }
 
 type WorkerKeyChange struct {
@@ -154,8 +183,9 @@ type SectorPreCommitOnChainInfo struct {
 	Info               SectorPreCommitInfo
 	PreCommitDeposit   abi.TokenAmount
 	PreCommitEpoch     abi.ChainEpoch
-	DealWeight         abi.DealWeight // Integral of active deals over sector lifetime
-	VerifiedDealWeight abi.DealWeight // Integral of active verified deals over sector lifetime
+	DealWeight         abi.DealWeight  // Integral of active deals over sector lifetime
+	VerifiedDealWeight abi.DealWeight  // Integral of active verified deals over sector lifetime
+	Pieces             []abi.PieceInfo // Pieces attested for a sector without deals, or nil
 }
 
 // Information stored on-chain for a proven sector.
@@ -164,7 +194,7 @@ type SectorOnChainInfo struct {
 	SealProof             abi.RegisteredSealProof // The seal proof type implies the PoSt proof/s
 	SealedCID             cid.Cid                 // CommR
 	DealIDs               []abi.DealID
-	Activation            abi.ChainEpoch  // Epoch during which the sector proof was accepted
+	Activation            abi.ChainEpoch  // Epoch during which the sector proof was accepted, i.e. the prove-commit epoch
 	Expiration            abi.ChainEpoch  // Epoch during which the sector expires
 	DealWeight            abi.DealWeight  // Integral of active deals over sector lifetime
 	VerifiedDealWeight    abi.DealWeight  // Integral of active verified deals over sector lifetime
@@ -173,8 +203,13 @@ type SectorOnChainInfo struct {
 	ExpectedStoragePledge abi.TokenAmount // Expected twenty day projection of reward for sector computed at activation time
 	ReplacedSectorAge     abi.ChainEpoch  // Age of sector this sector replaced or zero
 	ReplacedDayReward     abi.TokenAmount // Day reward of sector this sector replace or zero
+	SealRandEpoch         abi.ChainEpoch  // Epoch of the seal randomness, or NoSealRandEpoch if not recorded
+	Pieces                []abi.PieceInfo // Pieces attested for a sector without deals, committed by its CommD, or nil
 }
 
+// Value of SectorOnChainInfo.SealRandEpoch for sectors activated before the seal randomness epoch was recorded.
+const NoSealRandEpoch = abi.ChainEpoch(-1)
+
 func ConstructState(store adt.Store, infoCid cid.Cid, periodStart abi.ChainEpoch, deadlineIndex uint64) (*State, error) {
 	emptyPrecommitMapCid, err := adt.StoreEmptyMap(store, builtin.DefaultHamtBitwidth)
 	if err != nil {
@@ -232,6 +267,10 @@ func ConstructState(store adt.Store, infoCid cid.Cid, periodStart abi.ChainEpoch
 		Deadlines:                  emptyDeadlinesCid,
 		EarlyTerminations:          bitfield.New(),
 		DeadlineCronActive:         false,
+		ExpirationsPending:         bitfield.New(),
+		SectorRegions:              emptyPrecommitMapCid,
+		SchemaVersion:              StateSchemaVersion,
+		PreCommitAggregators:       emptyPrecommitMapCid,
 	}, nil
 }
 
@@ -259,6 +298,8 @@ func ConstructMinerInfo(owner, worker addr.Address, controlAddrs []addr.Address,
 		WindowPoStPartitionSectors: partitionSectors,
 		ConsensusFaultElapsed:      abi.ChainEpoch(-1),
 		PendingOwnerAddress:        nil,
+		DeadlineAssignment:         DeadlineAssignmentSpread,
+		NotificationTarget:         nil,
 	}, nil
 }
 
@@ -284,6 +325,17 @@ func (st *State) DeadlineInfo(currEpoch abi.ChainEpoch) *dline.Info {
 	return NewDeadlineInfoFromOffsetAndEpoch(st.ProvingPeriodStart, currEpoch)
 }
 
+// Returns the last epoch of the deadline that most recently ended at or before an epoch.
+// This is the epoch itself when a deadline ends there, which is when a deadline's cron event is scheduled,
+// but the power actor may deliver the event a few epochs late when many miners' deadlines end together.
+func (st *State) LastDeadlineEnd(currEpoch abi.ChainEpoch) abi.ChainEpoch {
+	dlInfo := st.DeadlineInfo(currEpoch)
+	if !dlInfo.PeriodStarted() || currEpoch == dlInfo.Last() {
+		return currEpoch
+	}
+	return dlInfo.Open - 1
+}
+
 // Returns deadline calculations for the state recorded proving period and deadline. This is out of date if the a
 // miner does not have an active miner cron
 func (st *State) RecordedDeadlineInfo(currEpoch abi.ChainEpoch) *dline.Info {
@@ -343,16 +395,71 @@ func (st *State) AllocateSectorNumbers(store adt.Store, sectorNos bitfield.BitFi
 	return nil
 }
 
+// Compacts the allocated sector numbers if their encoding exceeds maxSize bytes, by masking the unallocated
+// sector numbers in (at most) the lowest maxGaps gaps between allocated ranges.
+// Masked sector numbers can never be allocated to a sector.
+// Returns the number of gaps masked.
+func (st *State) CompactAllocatedSectorNumbers(store adt.Store, maxSize int, maxGaps uint64) (uint64, error) {
+	var allocation bitfield.BitField
+	if err := store.Get(store.Context(), st.AllocatedSectors, &allocation); err != nil {
+		return 0, xc.ErrIllegalState.Wrapf("failed to load allocated sectors bitfield: %w", err)
+	}
+
+	runs, err := allocation.RunIterator()
+	if err != nil {
+		return 0, xerrors.Errorf("failed to iterate allocated sectors: %w", err)
+	}
+	encoded, err := rlepluslazy.EncodeRuns(runs, nil)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to encode allocated sectors: %w", err)
+	}
+	if len(encoded) <= maxSize {
+		return 0, nil
+	}
+
+	// The last run of a bitfield is always set, so every unset run is a gap below an allocated sector number.
+	runs, err = allocation.RunIterator()
+	if err != nil {
+		return 0, xerrors.Errorf("failed to iterate allocated sectors: %w", err)
+	}
+	gaps := uint64(0)
+	maskEnd := uint64(0)
+	position := uint64(0)
+	for runs.HasNext() && gaps < maxGaps {
+		run, err := runs.NextRun()
+		if err != nil {
+			return 0, xerrors.Errorf("failed to read allocated sectors run: %w", err)
+		}
+		position += run.Len
+		if !run.Val {
+			gaps++
+			maskEnd = position
+		}
+	}
+	if gaps == 0 {
+		return 0, nil
+	}
+
+	mask, err := bitfield.NewFromIter(&rlepluslazy.RunSliceIterator{Runs: []rlepluslazy.Run{{Val: true, Len: maskEnd}}})
+	if err != nil {
+		return 0, xerrors.Errorf("failed to construct sector number mask: %w", err)
+	}
+	if err := st.AllocateSectorNumbers(store, mask, AllowCollisions); err != nil {
+		return 0, xerrors.Errorf("failed to mask sector numbers below %d: %w", maskEnd, err)
+	}
+	return gaps, nil
+}
+
 // Stores a pre-committed sector info, failing if the sector number is already present.
 func (st *State) PutPrecommittedSectors(store adt.Store, precommits ...*SectorPreCommitOnChainInfo) error {
-	precommitted, err := adt.AsMap(store, st.PreCommittedSectors, builtin.DefaultHamtBitwidth)
+	precommitted, err := LoadPreCommitMap(store, st.PreCommittedSectors)
 	if err != nil {
 		return err
 	}
 
 	for _, precommit := range precommits {
 		// NOTE: HAMT batch operations could reduce total state read/write cost of this batch.
-		if modified, err := precommitted.PutIfAbsent(SectorKey(precommit.Info.SectorNumber), precommit); err != nil {
+		if modified, err := precommitted.PutIfAbsent(precommit.Info.SectorNumber, precommit); err != nil {
 			return xerrors.Errorf("failed to store pre-commitment for %v: %w", precommit, err)
 		} else if !modified {
 			return xerrors.Errorf("sector %v already pre-committed", precommit.Info.SectorNumber)
@@ -363,37 +470,57 @@ func (st *State) PutPrecommittedSectors(store adt.Store, precommits ...*SectorPr
 }
 
 func (st *State) GetPrecommittedSector(store adt.Store, sectorNo abi.SectorNumber) (*SectorPreCommitOnChainInfo, bool, error) {
-	precommitted, err := adt.AsMap(store, st.PreCommittedSectors, builtin.DefaultHamtBitwidth)
+	precommitted, err := LoadPreCommitMap(store, st.PreCommittedSectors)
 	if err != nil {
 		return nil, false, err
 	}
 
-	var info SectorPreCommitOnChainInfo
-	found, err := precommitted.Get(SectorKey(sectorNo), &info)
+	info, found, err := precommitted.Get(sectorNo)
 	if err != nil {
 		return nil, false, errors.Wrapf(err, "failed to load precommitment for %v", sectorNo)
 	}
-	return &info, found, nil
+	return info, found, nil
+}
+
+// Records the pieces attested for a pre-committed sector.
+func (st *State) SetPrecommittedSectorPieces(store adt.Store, sectorNo abi.SectorNumber, pieces []abi.PieceInfo) error {
+	precommitted, err := LoadPreCommitMap(store, st.PreCommittedSectors)
+	if err != nil {
+		return err
+	}
+
+	info, found, err := precommitted.Get(sectorNo)
+	if err != nil {
+		return xerrors.Errorf("failed to load precommitment for %v: %w", sectorNo, err)
+	}
+	if !found {
+		return xc.ErrNotFound.Wrapf("no pre-committed sector %d", sectorNo)
+	}
+	info.Pieces = pieces
+	if err := precommitted.Put(sectorNo, info); err != nil {
+		return xerrors.Errorf("failed to store precommitment for %v: %w", sectorNo, err)
+	}
+	st.PreCommittedSectors, err = precommitted.Root()
+	return err
 }
 
 // Load all precommits or fail trying
 func (st *State) GetAllPrecommittedSectors(store adt.Store, sectorNos bitfield.BitField) ([]*SectorPreCommitOnChainInfo, error) {
 	precommits := make([]*SectorPreCommitOnChainInfo, 0)
-	precommitted, err := adt.AsMap(store, st.PreCommittedSectors, builtin.DefaultHamtBitwidth)
+	precommitted, err := LoadPreCommitMap(store, st.PreCommittedSectors)
 	if err != nil {
 		return nil, err
 	}
 
 	if err := sectorNos.ForEach(func(sectorNo uint64) error {
-		var info SectorPreCommitOnChainInfo
-		found, err := precommitted.Get(SectorKey(abi.SectorNumber(sectorNo)), &info)
+		info, found, err := precommitted.Get(abi.SectorNumber(sectorNo))
 		if err != nil {
 			return err
 		}
 		if !found {
 			return xc.ErrNotFound.Wrapf("sector %d not found", sectorNo)
 		}
-		precommits = append(precommits, &info)
+		precommits = append(precommits, info)
 		return nil
 	}); err != nil {
 		return nil, err
@@ -404,7 +531,7 @@ func (st *State) GetAllPrecommittedSectors(store adt.Store, sectorNos bitfield.B
 // This method gets and returns the requested pre-committed sectors, skipping
 // missing sectors.
 func (st *State) FindPrecommittedSectors(store adt.Store, sectorNos ...abi.SectorNumber) ([]*SectorPreCommitOnChainInfo, error) {
-	precommitted, err := adt.AsMap(store, st.PreCommittedSectors, builtin.DefaultHamtBitwidth)
+	precommitted, err := LoadPreCommitMap(store, st.PreCommittedSectors)
 	if err != nil {
 		return nil, err
 	}
@@ -412,8 +539,7 @@ func (st *State) FindPrecommittedSectors(store adt.Store, sectorNos ...abi.Secto
 	result := make([]*SectorPreCommitOnChainInfo, 0, len(sectorNos))
 
 	for _, sectorNo := range sectorNos {
-		var info SectorPreCommitOnChainInfo
-		found, err := precommitted.Get(SectorKey(sectorNo), &info)
+		info, found, err := precommitted.Get(sectorNo)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to load precommitment for %v", sectorNo)
 		}
@@ -421,26 +547,28 @@ func (st *State) FindPrecommittedSectors(store adt.Store, sectorNos ...abi.Secto
 			// TODO #564 log: "failed to get precommitted sector on sector %d, dropping from prove commit set"
 			continue
 		}
-		result = append(result, &info)
+		result = append(result, info)
 	}
 
 	return result, nil
 }
 
 func (st *State) DeletePrecommittedSectors(store adt.Store, sectorNos ...abi.SectorNumber) error {
-	precommitted, err := adt.AsMap(store, st.PreCommittedSectors, builtin.DefaultHamtBitwidth)
+	precommitted, err := LoadPreCommitMap(store, st.PreCommittedSectors)
 	if err != nil {
 		return err
 	}
 
 	for _, sectorNo := range sectorNos {
-		err = precommitted.Delete(SectorKey(sectorNo))
+		err = precommitted.Delete(sectorNo)
 		if err != nil {
 			return xerrors.Errorf("failed to delete precommitment for %v: %w", sectorNo, err)
 		}
 	}
-	st.PreCommittedSectors, err = precommitted.Root()
-	return err
+	if st.PreCommittedSectors, err = precommitted.Root(); err != nil {
+		return err
+	}
+	return st.removePreCommitAggregators(store, sectorNos...)
 }
 
 func (st *State) HasSectorNo(store adt.Store, sectorNo abi.SectorNumber) (bool, error) {
@@ -576,6 +704,7 @@ func (st *State) RescheduleSectorExpirations(
 // Assign new sectors to deadlines.
 func (st *State) AssignSectorsToDeadlines(
 	store adt.Store, currentEpoch abi.ChainEpoch, sectors []*SectorOnChainInfo, partitionSize uint64, sectorSize abi.SectorSize,
+	policy DeadlineAssignmentPolicy,
 ) error {
 	deadlines, err := st.LoadDeadlines(store)
 	if err != nil {
@@ -598,7 +727,7 @@ func (st *State) AssignSectorsToDeadlines(
 		return err
 	}
 
-	deadlineToSectors, err := assignDeadlines(MaxPartitionsPerDeadline, partitionSize, &deadlineArr, sectors)
+	deadlineToSectors, err := assignDeadlines(policy, MaxPartitionsPerDeadline, partitionSize, &deadlineArr, sectors)
 	if err != nil {
 		return xerrors.Errorf("failed to assign sectors to deadlines: %w", err)
 	}
@@ -748,6 +877,58 @@ func (st *State) LoadSectorInfos(store adt.Store, sectors bitfield.BitField) ([]
 	return sectorsArr.Load(sectors)
 }
 
+// Counts of a miner's live sectors by status, across all deadlines.
+type SectorStatusCounts struct {
+	Live       uint64    // Sectors not terminated (but possibly faulty)
+	Faulty     uint64    // Live sectors that are faulty, including those recovering
+	Recovering uint64    // Faulty sectors declared recovering
+	Unproven   uint64    // Live sectors not yet proven in a Window PoSt
+	LivePower  PowerPair // Raw and quality-adjusted power of live sectors
+}
+
+// Counts the miner's live sectors by status, visiting every partition.
+func (st *State) GetSectorStatusCounts(store adt.Store) (*SectorStatusCounts, error) {
+	deadlines, err := st.LoadDeadlines(store)
+	if err != nil {
+		return nil, err
+	}
+	counts := SectorStatusCounts{LivePower: NewPowerPairZero()}
+	err = deadlines.ForEach(store, func(dlIdx uint64, dl *Deadline) error {
+		partitions, err := dl.PartitionsArray(store)
+		if err != nil {
+			return err
+		}
+		var partition Partition
+		return partitions.ForEach(&partition, func(pIdx int64) error {
+			live, err := partition.LiveSectors()
+			if err != nil {
+				return err
+			}
+			for _, c := range []struct {
+				sectors bitfield.BitField
+				count   *uint64
+			}{
+				{live, &counts.Live},
+				{partition.Faults, &counts.Faulty},
+				{partition.Recoveries, &counts.Recovering},
+				{partition.Unproven, &counts.Unproven},
+			} {
+				n, err := c.sectors.Count()
+				if err != nil {
+					return xerrors.Errorf("failed to count sectors of deadline %d partition %d: %w", dlIdx, pIdx, err)
+				}
+				*c.count += n
+			}
+			counts.LivePower = counts.LivePower.Add(partition.LivePower)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to count sectors: %w", err)
+	}
+	return &counts, nil
+}
+
 func (st *State) LoadDeadlines(store adt.Store) (*Deadlines, error) {
 	var deadlines Deadlines
 	if err := store.Get(store.Context(), st.Deadlines, &deadlines); err != nil {
@@ -999,6 +1180,32 @@ func (st *State) GetAvailableBalance(actorBalance abi.TokenAmount) (abi.TokenAmo
 	return big.Subtract(unlockedBalance, st.FeeDebt), nil
 }
 
+// Itemized view of an actor balance. Available is computed exactly as by st.GetAvailableBalance,
+// and the remaining fields are the components subtracted from the actor balance to arrive at it.
+type BalanceBreakdown struct {
+	Balance           abi.TokenAmount // Actor balance the breakdown was computed from
+	Available         abi.TokenAmount // Can go negative if the miner is in IP debt
+	PreCommitDeposits abi.TokenAmount
+	InitialPledge     abi.TokenAmount
+	LockedFunds       abi.TokenAmount // Funds locked in the vesting table
+	FeeDebt           abi.TokenAmount
+}
+
+func (st *State) GetBalanceBreakdown(actorBalance abi.TokenAmount) (*BalanceBreakdown, error) {
+	available, err := st.GetAvailableBalance(actorBalance)
+	if err != nil {
+		return nil, err
+	}
+	return &BalanceBreakdown{
+		Balance:           actorBalance,
+		Available:         available,
+		PreCommitDeposits: st.PreCommitDeposits,
+		InitialPledge:     st.InitialPledge,
+		LockedFunds:       st.LockedFunds,
+		FeeDebt:           st.FeeDebt,
+	}, nil
+}
+
 func (st *State) CheckBalanceInvariants(balance abi.TokenAmount) error {
 	if st.PreCommitDeposits.LessThan(big.Zero()) {
 		return xerrors.Errorf("pre-commit deposit is negative: %v", st.PreCommitDeposits)
@@ -1060,24 +1267,40 @@ func (st *State) AddPreCommitCleanUps(store adt.Store, cleanUpEvents map[abi.Cha
 	return nil
 }
 
-func (st *State) CleanUpExpiredPreCommits(store adt.Store, currEpoch abi.ChainEpoch) (depositToBurn abi.TokenAmount, err error) {
-	depositToBurn = abi.NewTokenAmount(0)
+// Record of the deposit burned for a single expired pre-commitment.
+type ExpiredPreCommit struct {
+	SectorNumber abi.SectorNumber
+	Burned       abi.TokenAmount // The full pre-commit deposit
+}
+
+// Attribution of the deposits burned by cleaning up expired pre-commitments.
+type CleanUpExpiredPreCommitsResult struct {
+	Expired       []ExpiredPreCommit // Ordered by sector number
+	DepositBurned abi.TokenAmount    // Sum of Burned over expired pre-commits
+}
+
+// Removes pre-commitments that have expired by currEpoch and releases their deposits.
+// Expired deposits are burned in full; the result attributes the released deposit to each sector.
+func (st *State) CleanUpExpiredPreCommits(store adt.Store, currEpoch abi.ChainEpoch) (*CleanUpExpiredPreCommitsResult, error) {
+	result := &CleanUpExpiredPreCommitsResult{
+		DepositBurned: big.Zero(),
+	}
 
 	// cleanup expired pre-committed sectors
 	cleanUpQ, err := LoadBitfieldQueue(store, st.PreCommittedSectorsCleanUp, st.QuantSpecEveryDeadline(), PrecommitCleanUpAmtBitwidth)
 	if err != nil {
-		return depositToBurn, xerrors.Errorf("failed to load sector expiry queue: %w", err)
+		return nil, xerrors.Errorf("failed to load sector expiry queue: %w", err)
 	}
 
 	sectors, modified, err := cleanUpQ.PopUntil(currEpoch)
 	if err != nil {
-		return depositToBurn, xerrors.Errorf("failed to pop expired sectors: %w", err)
+		return nil, xerrors.Errorf("failed to pop expired sectors: %w", err)
 	}
 
 	if modified {
 		st.PreCommittedSectorsCleanUp, err = cleanUpQ.Root()
 		if err != nil {
-			return depositToBurn, xerrors.Errorf("failed to save pre commit clean up queue: %w", err)
+			return nil, xerrors.Errorf("failed to save pre commit clean up queue: %w", err)
 		}
 	}
 
@@ -1096,27 +1319,58 @@ func (st *State) CleanUpExpiredPreCommits(store adt.Store, currEpoch abi.ChainEp
 		// mark it for deletion
 		precommitsToDelete = append(precommitsToDelete, sectorNo)
 
-		// increment deposit to burn
-		depositToBurn = big.Add(depositToBurn, sector.PreCommitDeposit)
+		// the whole deposit is burned
+		result.Expired = append(result.Expired, ExpiredPreCommit{
+			SectorNumber: sectorNo,
+			Burned:       sector.PreCommitDeposit,
+		})
+		result.DepositBurned = big.Add(result.DepositBurned, sector.PreCommitDeposit)
 		return nil
 	}); err != nil {
-		return big.Zero(), xerrors.Errorf("failed to check pre-commit expiries: %w", err)
+		return nil, xerrors.Errorf("failed to check pre-commit expiries: %w", err)
 	}
 
 	// Actually delete it.
 	if len(precommitsToDelete) > 0 {
 		if err := st.DeletePrecommittedSectors(store, precommitsToDelete...); err != nil {
-			return big.Zero(), fmt.Errorf("failed to delete pre-commits: %w", err)
+			return nil, fmt.Errorf("failed to delete pre-commits: %w", err)
 		}
 	}
 
-	st.PreCommitDeposits = big.Sub(st.PreCommitDeposits, depositToBurn)
+	st.PreCommitDeposits = big.Sub(st.PreCommitDeposits, result.DepositBurned)
 	if st.PreCommitDeposits.LessThan(big.Zero()) {
-		return big.Zero(), xerrors.Errorf("pre-commit clean up caused negative deposits: %v", st.PreCommitDeposits)
+		return nil, xerrors.Errorf("pre-commit clean up caused negative deposits: %v", st.PreCommitDeposits)
 	}
 
 	// This deposit was locked separately to pledge collateral so there's no pledge change here.
-	return depositToBurn, nil
+	return result, nil
+}
+
+// Removes pre-commitments whose sectors were proven after their prove-commit deadline but before clean up,
+// releasing their deposits. A portion of each deposit is refunded to the miner's available balance;
+// the remainder is to be burned. The returned releases attribute the split to each sector.
+func (st *State) ReleaseLatePreCommits(store adt.Store, precommits []*SectorPreCommitOnChainInfo) ([]PreCommitDepositRelease, error) {
+	released := big.Zero()
+	releases := make([]PreCommitDepositRelease, len(precommits))
+	sectorNos := make([]abi.SectorNumber, len(precommits))
+	for i, precommit := range precommits {
+		sectorNos[i] = precommit.Info.SectorNumber
+		released = big.Add(released, precommit.PreCommitDeposit)
+		refund := LatePreCommitDepositRefund(precommit.PreCommitDeposit)
+		releases[i] = PreCommitDepositRelease{
+			SectorNumber: precommit.Info.SectorNumber,
+			Burned:       big.Sub(precommit.PreCommitDeposit, refund),
+			Refunded:     refund,
+		}
+	}
+
+	if err := st.DeletePrecommittedSectors(store, sectorNos...); err != nil {
+		return nil, xerrors.Errorf("failed to delete pre-commits: %w", err)
+	}
+	if err := st.AddPreCommitDeposit(released.Neg()); err != nil {
+		return nil, err
+	}
+	return releases, nil
 }
 
 type AdvanceDeadlineResult struct {
@@ -1176,6 +1430,14 @@ func (st *State) AdvanceDeadline(store adt.Store, currEpoch abi.ChainEpoch) (*Ad
 	if err != nil {
 		return nil, xerrors.Errorf("failed to load deadlines: %w", err)
 	}
+
+	// Prune the snapshot of the earlier deadline whose proofs can no longer be disputed.
+	// This happens before the early return below so that snapshots are pruned even if this deadline is empty.
+	prunedSnapshots, err := st.pruneDeadlineSnapshots(store, deadlines, dlInfo.Last(), []uint64{snapshotExpiringDeadline(dlInfo.Index)})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to prune deadline snapshots: %w", err)
+	}
+
 	deadline, err := deadlines.LoadDeadline(store, dlInfo.Index)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to load deadline %d: %w", dlInfo.Index, err)
@@ -1187,6 +1449,11 @@ func (st *State) AdvanceDeadline(store adt.Store, currEpoch abi.ChainEpoch) (*Ad
 	if live, err := deadline.IsLive(); err != nil {
 		return nil, xerrors.Errorf("failed to determine if miner is live: %w", err)
 	} else if !live {
+		if len(prunedSnapshots) > 0 {
+			if err := st.SaveDeadlines(store, deadlines); err != nil {
+				return nil, xerrors.Errorf("failed to save deadlines: %w", err)
+			}
+		}
 		return &AdvanceDeadlineResult{
 			pledgeDelta,
 			powerDelta,
@@ -1213,33 +1480,12 @@ func (st *State) AdvanceDeadline(store adt.Store, currEpoch abi.ChainEpoch) (*Ad
 	}
 	{
 		// Expire sectors that are due, either for on-time expiration or "early" faulty-for-too-long.
-		expired, err := deadline.PopExpiredSectors(store, dlInfo.Last(), quant)
-		if err != nil {
-			return nil, xerrors.Errorf("failed to load expired sectors: %w", err)
-		}
-
-		// Release pledge requirements for the sectors expiring on-time.
-		// Pledge for the sectors expiring early is retained to support the termination fee that will be assessed
-		// when the early termination is processed.
-		pledgeDelta = big.Sub(pledgeDelta, expired.OnTimePledge)
-		if err = st.AddInitialPledge(expired.OnTimePledge.Neg()); err != nil {
-			return nil, xerrors.Errorf("failed to reduce %v initial pledge for expiring sectors: %w", expired.OnTimePledge, err)
-		}
-
-		// Record reduction in power of the amount of expiring active power.
-		// Faulty power has already been lost, so the amount expiring can be excluded from the delta.
-		powerDelta = powerDelta.Sub(expired.ActivePower)
-
-		// Record deadlines with early terminations. While this
-		// bitfield is non-empty, the miner is locked until they
-		// pay the fee.
-		noEarlyTerminations, err := expired.EarlySectors.IsEmpty()
+		expiredPledgeDelta, expiredPowerDelta, err := st.popExpiredSectors(store, deadline, dlInfo.Index, dlInfo.Last(), quant, ExpiringPartitionsMax)
 		if err != nil {
-			return nil, xerrors.Errorf("failed to count early terminations: %w", err)
-		}
-		if !noEarlyTerminations {
-			st.EarlyTerminations.Set(dlInfo.Index)
+			return nil, err
 		}
+		pledgeDelta = big.Add(pledgeDelta, expiredPledgeDelta)
+		powerDelta = powerDelta.Add(expiredPowerDelta)
 	}
 
 	// Save new deadline state.
@@ -1264,6 +1510,144 @@ func (st *State) AdvanceDeadline(store adt.Store, currEpoch abi.ChainEpoch) (*Ad
 	}, nil
 }
 
+// Continues processing the expired sectors of a deadline for which there were more than could be processed
+// in a single cron event, up to maxPartitions partitions.
+// Only one deadline is processed at a time. Since processing continues in each epoch after a deadline's
+// cron event, and at least one partition is processed each time, no more than one is pending in practice.
+// Returns the changes to pledge and power, and whether any expirations remain pending.
+func (st *State) ProcessPendingExpirations(store adt.Store, currEpoch abi.ChainEpoch, maxPartitions uint64) (pledgeDelta abi.TokenAmount, powerDelta PowerPair, hasMore bool, err error) {
+	pending, err := st.ExpirationsPending.First()
+	if err == bitfield.ErrNoBitsSet {
+		return big.Zero(), NewPowerPairZero(), false, nil
+	} else if err != nil {
+		return big.Zero(), NewPowerPairZero(), false, xerrors.Errorf("failed to find pending expirations: %w", err)
+	}
+
+	deadlines, err := st.LoadDeadlines(store)
+	if err != nil {
+		return big.Zero(), NewPowerPairZero(), false, xerrors.Errorf("failed to load deadlines: %w", err)
+	}
+	deadline, err := deadlines.LoadDeadline(store, pending)
+	if err != nil {
+		return big.Zero(), NewPowerPairZero(), false, xerrors.Errorf("failed to load deadline %d: %w", pending, err)
+	}
+
+	// The pending expirations are those up to the end of the deadline's most recent challenge window.
+	quant := st.QuantSpecForDeadline(pending)
+	pledgeDelta, powerDelta, err = st.popExpiredSectors(store, deadline, pending, quant.QuantizeDown(currEpoch), quant, maxPartitions)
+	if err != nil {
+		return big.Zero(), NewPowerPairZero(), false, err
+	}
+
+	if err = deadlines.UpdateDeadline(store, pending, deadline); err != nil {
+		return big.Zero(), NewPowerPairZero(), false, xerrors.Errorf("failed to update deadline %d: %w", pending, err)
+	}
+	if err = st.SaveDeadlines(store, deadlines); err != nil {
+		return big.Zero(), NewPowerPairZero(), false, xerrors.Errorf("failed to save deadlines: %w", err)
+	}
+
+	noneRemaining, err := st.ExpirationsPending.IsEmpty()
+	if err != nil {
+		return big.Zero(), NewPowerPairZero(), false, xerrors.Errorf("failed to count pending expirations: %w", err)
+	}
+	return pledgeDelta, powerDelta, !noneRemaining, nil
+}
+
+// Expires sectors of up to maxPartitions partitions of a deadline that are due at or before until, either for
+// on-time expiration or "early" faulty-for-too-long, updating but not saving the deadline.
+// Records the deadline as having pending expirations if partitions beyond the limit remain, or clears it otherwise.
+// Returns the changes to pledge and power.
+func (st *State) popExpiredSectors(store adt.Store, deadline *Deadline, dlIdx uint64, until abi.ChainEpoch, quant builtin.QuantSpec, maxPartitions uint64) (abi.TokenAmount, PowerPair, error) {
+	expired, hasMore, err := deadline.PopExpiredSectors(store, until, quant, maxPartitions)
+	if err != nil {
+		return big.Zero(), NewPowerPairZero(), xerrors.Errorf("failed to load expired sectors: %w", err)
+	}
+	earlyCount, err := expired.EarlySectors.Count()
+	if err != nil {
+		return big.Zero(), NewPowerPairZero(), xerrors.Errorf("failed to count early terminations: %w", err)
+	}
+	if earlyCount > 0 {
+		st.RecordSectorsTerminatedForFault(until, earlyCount)
+	}
+	if hasMore {
+		st.ExpirationsPending.Set(dlIdx)
+	} else {
+		st.ExpirationsPending.Unset(dlIdx)
+	}
+
+	// Release pledge requirements for the sectors expiring on-time.
+	// Pledge for the sectors expiring early is retained to support the termination fee that will be assessed
+	// when the early termination is processed.
+	if err = st.AddInitialPledge(expired.OnTimePledge.Neg()); err != nil {
+		return big.Zero(), NewPowerPairZero(), xerrors.Errorf("failed to reduce %v initial pledge for expiring sectors: %w", expired.OnTimePledge, err)
+	}
+
+	// Record deadlines with early terminations. While this
+	// bitfield is non-empty, the miner is locked until they
+	// pay the fee.
+	if earlyCount > 0 {
+		st.EarlyTerminations.Set(dlIdx)
+	}
+
+	// Record reduction in power of the amount of expiring active power.
+	// Faulty power has already been lost, so the amount expiring can be excluded from the delta.
+	return expired.OnTimePledge.Neg(), expired.ActivePower.Neg(), nil
+}
+
+// Prunes the partitions and proofs snapshots of all deadlines for which the snapshot retention period
+// has elapsed as of the end of the current epoch.
+// Cron prunes each deadline's snapshot as it expires, so this is necessary only for state that was not
+// pruned as it advanced.
+// Returns the indices of the deadlines whose snapshots were pruned.
+func (st *State) PruneDeadlineSnapshots(store adt.Store, currEpoch abi.ChainEpoch) ([]uint64, error) {
+	deadlines, err := st.LoadDeadlines(store)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load deadlines: %w", err)
+	}
+
+	dlIdxs := make([]uint64, WPoStPeriodDeadlines)
+	for dlIdx := range dlIdxs {
+		dlIdxs[dlIdx] = uint64(dlIdx)
+	}
+	pruned, err := st.pruneDeadlineSnapshots(store, deadlines, currEpoch, dlIdxs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pruned) > 0 {
+		if err := st.SaveDeadlines(store, deadlines); err != nil {
+			return nil, xerrors.Errorf("failed to save deadlines: %w", err)
+		}
+	}
+	return pruned, nil
+}
+
+// Prunes the expired snapshots of the given deadlines, updating but not saving the deadlines.
+func (st *State) pruneDeadlineSnapshots(store adt.Store, deadlines *Deadlines, currEpoch abi.ChainEpoch, dlIdxs []uint64) ([]uint64, error) {
+	var pruned []uint64
+	for _, dlIdx := range dlIdxs {
+		if !deadlineSnapshotExpired(st.ProvingPeriodStart, dlIdx, currEpoch) {
+			continue
+		}
+
+		deadline, err := deadlines.LoadDeadline(store, dlIdx)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load deadline %d: %w", dlIdx, err)
+		}
+		if modified, err := deadline.PruneSnapshots(store); err != nil {
+			return nil, xerrors.Errorf("failed to prune snapshots of deadline %d: %w", dlIdx, err)
+		} else if !modified {
+			continue
+		}
+
+		if err := deadlines.UpdateDeadline(store, dlIdx, deadline); err != nil {
+			return nil, xerrors.Errorf("failed to update deadline %d: %w", dlIdx, err)
+		}
+		pruned = append(pruned, dlIdx)
+	}
+	return pruned, nil
+}
+
 //
 // Misc helpers
 //
@@ -661,6 +661,52 @@ func TestAddPreCommitExpiry(t *testing.T) {
 	})
 }
 
+func TestCleanUpExpiredPreCommits(t *testing.T) {
+	t.Run("expired deposits are attributed to sectors", func(t *testing.T) {
+		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		pc1 := newPreCommitOnChain(1, tutils.MakeCID("1", &miner.SealedCIDPrefix), abi.NewTokenAmount(10), 1)
+		pc2 := newPreCommitOnChain(2, tutils.MakeCID("2", &miner.SealedCIDPrefix), abi.NewTokenAmount(20), 1)
+		pc3 := newPreCommitOnChain(3, tutils.MakeCID("3", &miner.SealedCIDPrefix), abi.NewTokenAmount(30), 1)
+		require.NoError(t, harness.s.PutPrecommittedSectors(harness.store, pc1, pc2, pc3))
+		harness.s.PreCommitDeposits = abi.NewTokenAmount(60)
+
+		err := harness.s.AddPreCommitCleanUps(harness.store, map[abi.ChainEpoch][]uint64{
+			100: {1, 2},
+			500: {3},
+		})
+		require.NoError(t, err)
+
+		// Sector 2 was proven before expiry, so is no longer pre-committed.
+		harness.deletePreCommit(2)
+		harness.s.PreCommitDeposits = abi.NewTokenAmount(40)
+
+		quant := harness.s.QuantSpecEveryDeadline()
+		result, err := harness.s.CleanUpExpiredPreCommits(harness.store, quant.QuantizeUp(100))
+		require.NoError(t, err)
+
+		assert.Equal(t, []miner.ExpiredPreCommit{{
+			SectorNumber: 1,
+			Burned:       abi.NewTokenAmount(10),
+		}}, result.Expired)
+		assert.Equal(t, abi.NewTokenAmount(10), result.DepositBurned)
+		assert.Equal(t, abi.NewTokenAmount(30), harness.s.PreCommitDeposits)
+		assert.False(t, harness.hasPreCommit(1))
+		assert.True(t, harness.hasPreCommit(3))
+
+		ExpectBQ().
+			Add(quant.QuantizeUp(500), 3).
+			Equals(t, harness.loadPreCommitCleanUps())
+	})
+
+	t.Run("nothing expired", func(t *testing.T) {
+		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		result, err := harness.s.CleanUpExpiredPreCommits(harness.store, 100)
+		require.NoError(t, err)
+		assert.Empty(t, result.Expired)
+		assert.Equal(t, big.Zero(), result.DepositBurned)
+	})
+}
+
 func TestSectorAssignment(t *testing.T) {
 	partitionSectors, err := builtin.SealProofWindowPoStPartitionSectors(abi.RegisteredSealProof_StackedDrg32GiBV1_1)
 	require.NoError(t, err)
@@ -688,7 +734,7 @@ func TestSectorAssignment(t *testing.T) {
 		harness := constructStateHarness(t, abi.ChainEpoch(0))
 
 		err := harness.s.AssignSectorsToDeadlines(harness.store, 0, sectorInfos,
-			partitionSectors, sectorSize)
+			partitionSectors, sectorSize, miner.DeadlineAssignmentSpread)
 		require.NoError(t, err)
 
 		sectorArr := sectorsArr(t, harness.store, sectorInfos)
@@ -745,6 +791,113 @@ func TestSectorAssignment(t *testing.T) {
 	})
 }
 
+func TestProcessPendingExpirations(t *testing.T) {
+	sectorSize, err := abi.RegisteredSealProof_StackedDrg32GiBV1_1.SectorSize()
+	require.NoError(t, err)
+	partitionSize := uint64(2)
+	dlIdx := uint64(2)
+
+	harness := constructStateHarness(t, abi.ChainEpoch(0))
+	quant := harness.s.QuantSpecForDeadline(dlIdx)
+	sectors := make([]*miner.SectorOnChainInfo, 6)
+	for i := range sectors {
+		sectors[i] = newSectorOnChainInfo(abi.SectorNumber(i), tutils.MakeCID(fmt.Sprintf("%d", i), &miner.SealedCIDPrefix), big.NewInt(1), abi.ChainEpoch(0))
+		sectors[i].Expiration = 100
+		sectors[i].InitialPledge = abi.NewTokenAmount(1000)
+		require.NoError(t, harness.s.AddInitialPledge(sectors[i].InitialPledge))
+	}
+
+	// Add three partitions of active sectors to a deadline, all expiring at the end of its first challenge window.
+	dls, err := harness.s.LoadDeadlines(harness.store)
+	require.NoError(t, err)
+	dl, err := dls.LoadDeadline(harness.store, dlIdx)
+	require.NoError(t, err)
+	_, err = dl.AddSectors(harness.store, partitionSize, true, sectors, sectorSize, quant)
+	require.NoError(t, err)
+	require.NoError(t, dls.UpdateDeadline(harness.store, dlIdx, dl))
+	require.NoError(t, harness.s.SaveDeadlines(harness.store, dls))
+	harness.s.ExpirationsPending.Set(dlIdx)
+
+	currEpoch := quant.QuantizeUp(sectors[0].Expiration) + 1
+
+	// The first two partitions expire.
+	pledgeDelta, powerDelta, more, err := harness.s.ProcessPendingExpirations(harness.store, currEpoch, 2)
+	require.NoError(t, err)
+	assert.True(t, more)
+	assert.Equal(t, abi.NewTokenAmount(-4000), pledgeDelta)
+	assert.True(t, powerDelta.Equals(miner.PowerForSectors(sectorSize, sectors[:4]).Neg()))
+	assertBitfieldEquals(t, harness.s.ExpirationsPending, dlIdx)
+
+	// The last partition expires in a later epoch.
+	pledgeDelta, powerDelta, more, err = harness.s.ProcessPendingExpirations(harness.store, currEpoch+1, 2)
+	require.NoError(t, err)
+	assert.False(t, more)
+	assert.Equal(t, abi.NewTokenAmount(-2000), pledgeDelta)
+	assert.True(t, powerDelta.Equals(miner.PowerForSectors(sectorSize, sectors[4:]).Neg()))
+	assertBitfieldEmpty(t, harness.s.ExpirationsPending)
+	assert.True(t, harness.s.InitialPledge.IsZero())
+
+	dls, err = harness.s.LoadDeadlines(harness.store)
+	require.NoError(t, err)
+	dl, err = dls.LoadDeadline(harness.store, dlIdx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), dl.LiveSectors)
+
+	// Nothing remains to process.
+	pledgeDelta, powerDelta, more, err = harness.s.ProcessPendingExpirations(harness.store, currEpoch+2, 2)
+	require.NoError(t, err)
+	assert.False(t, more)
+	assert.True(t, pledgeDelta.IsZero())
+	assert.True(t, powerDelta.IsZero())
+}
+
+func TestReliabilityStats(t *testing.T) {
+	window := miner.ReliabilityStatsWindow
+	harness := constructStateHarness(t, abi.ChainEpoch(0))
+
+	harness.s.RecordPoStSubmitted(window + 1)
+	harness.s.RecordFaultsDeclared(window+2, 3)
+	harness.s.RecordSectorsTerminatedForFault(2*window-1, 2)
+	current := miner.ReliabilityCounters{PoStsSubmitted: 1, FaultsDeclared: 3, SectorsTerminatedForFault: 2}
+	assert.Equal(t, miner.ReliabilityStats{WindowStart: window, Current: current}, harness.s.ReliabilityStats)
+
+	// Stats as of an epoch in the same window, or earlier, are unchanged.
+	assert.Equal(t, harness.s.ReliabilityStats, harness.s.ReliabilityStats.AsOf(window))
+	assert.Equal(t, harness.s.ReliabilityStats, harness.s.ReliabilityStats.AsOf(window-1))
+
+	// Recording in the next window rolls the counters.
+	harness.s.RecordPoStSubmitted(2 * window)
+	assert.Equal(t, miner.ReliabilityStats{
+		WindowStart: 2 * window,
+		Current:     miner.ReliabilityCounters{PoStsSubmitted: 1},
+		Previous:    current,
+	}, harness.s.ReliabilityStats)
+
+	// Skipping a whole window discards the counters.
+	assert.Equal(t, miner.ReliabilityStats{WindowStart: 4 * window}, harness.s.ReliabilityStats.AsOf(4*window+10))
+}
+
+func TestSectorRegions(t *testing.T) {
+	harness := constructStateHarness(t, abi.ChainEpoch(0))
+	require.NoError(t, harness.s.RecordSectorRegion(harness.store, "us-east", bitfield.NewFromSet([]uint64{1, 2})))
+	require.NoError(t, harness.s.RecordSectorRegion(harness.store, "eu-west", bitfield.NewFromSet([]uint64{3})))
+	require.NoError(t, harness.s.RecordSectorRegion(harness.store, "us-east", bitfield.NewFromSet([]uint64{5})))
+
+	regions, err := harness.s.LoadSectorRegions(harness.store, bitfield.NewFromSet([]uint64{2, 4, 5}))
+	require.NoError(t, err)
+	require.Len(t, regions, 1)
+	assert.Equal(t, "us-east", regions[0].Region)
+	assertBitfieldEquals(t, regions[0].Sectors, 2, 5)
+
+	regions, err = harness.s.LoadSectorRegions(harness.store, bitfield.NewFromSet([]uint64{1, 3}))
+	require.NoError(t, err)
+	assert.Len(t, regions, 2)
+
+	regions, err = harness.s.LoadSectorRegions(harness.store, bitfield.NewFromSet([]uint64{4}))
+	require.NoError(t, err)
+	assert.Empty(t, regions)
+}
+
 func TestSectorNumberAllocation(t *testing.T) {
 	allocate := func(h *stateHarness, numbers ...uint64) error {
 		return h.s.AllocateSectorNumbers(h.store, bitfield.NewFromSet(numbers), miner.DenyCollisions)
@@ -780,6 +933,32 @@ func TestSectorNumberAllocation(t *testing.T) {
 		expect(harness, bf(1, 2, 3))
 	})
 
+	t.Run("compaction masks lowest gaps", func(t *testing.T) {
+		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		assert.NoError(t, allocate(harness, 5, 7, 9, 11, 13))
+
+		// Within size bound
+		masked, err := harness.s.CompactAllocatedSectorNumbers(harness.store, 1<<10, 2)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), masked)
+		expect(harness, bf(5, 7, 9, 11, 13))
+
+		masked, err = harness.s.CompactAllocatedSectorNumbers(harness.store, 0, 2)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), masked)
+		expect(harness, bf(0, 1, 2, 3, 4, 5, 6, 7, 9, 11, 13))
+
+		masked, err = harness.s.CompactAllocatedSectorNumbers(harness.store, 0, 5)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), masked)
+		expect(harness, bf(0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13))
+
+		// Nothing left to mask
+		masked, err = harness.s.CompactAllocatedSectorNumbers(harness.store, 0, 5)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), masked)
+	})
+
 	t.Run("batch masking", func(t *testing.T) {
 		harness := constructStateHarness(t, abi.ChainEpoch(0))
 		assert.NoError(t, allocate(harness, 1))
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"sort"
 	"strings"
 	"testing"
 
@@ -37,6 +38,10 @@ import (
 var testPid abi.PeerID
 var testMultiaddrs []abi.Multiaddrs
 
+// Well-formed binary multiaddrs for /ip4/127.0.0.1/tcp/4001 and /dns4/example.com/tcp/4001.
+var testIP4Multiaddr = abi.Multiaddrs{0x04, 127, 0, 0, 1, 0x06, 0x0f, 0xa1}
+var testDNS4Multiaddr = append(append(abi.Multiaddrs{0x36, 11}, "example.com"...), 0x06, 0x0f, 0xa1)
+
 // A balance for use in tests where the miner's low balance is not interesting.
 var bigBalance = big.Mul(big.NewInt(1_000_000), big.NewInt(1e18))
 
@@ -47,11 +52,11 @@ var bigRewards = big.Mul(big.NewInt(1_000), big.NewInt(1e18))
 const defaultSectorExpiration = 220
 
 func init() {
-	testPid = abi.PeerID("peerID")
+	testPid = tutil.MakePID("peerID")
 
 	testMultiaddrs = []abi.Multiaddrs{
-		[]byte("foobar"),
-		[]byte("imafilminer"),
+		testIP4Multiaddr,
+		testDNS4Multiaddr,
 	}
 
 	// permit 2KiB sectors in tests
@@ -195,6 +200,52 @@ func TestConstruction(t *testing.T) {
 		rt.Verify()
 	})
 
+	t.Run("construction with requested proving period offset", func(t *testing.T) {
+		rt := builder.Build(t)
+		offset := abi.ChainEpoch(1000)
+		params := miner.ConstructorParams{
+			OwnerAddr:                  owner,
+			WorkerAddr:                 worker,
+			WindowPoStProofType:        abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
+			RequestProvingPeriodOffset: true,
+			ProvingPeriodOffset:        offset,
+		}
+
+		rt.ExpectValidateCallerAddr(builtin.InitActorAddr)
+		rt.ExpectSend(worker, builtin.MethodsAccount.PubkeyAddress, nil, big.Zero(), &workerKey, exitcode.Ok)
+		rt.Call(actor.Constructor, &params)
+		rt.Verify()
+
+		var st miner.State
+		rt.GetState(&st)
+		assert.Equal(t, offset-miner.WPoStProvingPeriod, st.ProvingPeriodStart)
+		dlIdx := (rt.Epoch() - st.ProvingPeriodStart) / miner.WPoStChallengeWindow
+		assert.Equal(t, uint64(dlIdx), st.CurrentDeadline)
+
+		_, msgs := miner.CheckStateInvariants(&st, rt.AdtStore(), rt.Balance())
+		assert.True(t, msgs.IsEmpty(), strings.Join(msgs.Messages(), "\n"))
+	})
+
+	t.Run("fails if requested proving period offset is out of range", func(t *testing.T) {
+		for _, offset := range []abi.ChainEpoch{-1, miner.WPoStProvingPeriod} {
+			rt := builder.Build(t)
+			params := miner.ConstructorParams{
+				OwnerAddr:                  owner,
+				WorkerAddr:                 worker,
+				WindowPoStProofType:        abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
+				RequestProvingPeriodOffset: true,
+				ProvingPeriodOffset:        offset,
+			}
+
+			rt.ExpectValidateCallerAddr(builtin.InitActorAddr)
+			rt.ExpectSend(worker, builtin.MethodsAccount.PubkeyAddress, nil, big.Zero(), &workerKey, exitcode.Ok)
+			rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "proving period offset", func() {
+				rt.Call(actor.Constructor, &params)
+			})
+			rt.Verify()
+		}
+	})
+
 	t.Run("test construct with invalid peer ID", func(t *testing.T) {
 		rt := builder.Build(t)
 		pid := [miner.MaxPeerIDLength + 1]byte{1, 2, 3, 4}
@@ -213,6 +264,23 @@ func TestConstruction(t *testing.T) {
 		})
 	})
 
+	t.Run("test construct with malformed peer ID", func(t *testing.T) {
+		rt := builder.Build(t)
+		params := miner.ConstructorParams{
+			OwnerAddr:           owner,
+			WorkerAddr:          worker,
+			WindowPoStProofType: abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
+			PeerId:              abi.PeerID("peerID"),
+			Multiaddrs:          testMultiaddrs,
+		}
+
+		rt.ExpectValidateCallerAddr(builtin.InitActorAddr)
+
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "invalid peer ID", func() {
+			rt.Call(actor.Constructor, &params)
+		})
+	})
+
 	t.Run("fails if control addresses exceeds maximum length", func(t *testing.T) {
 		rt := builder.Build(t)
 
@@ -277,6 +345,23 @@ func TestConstruction(t *testing.T) {
 			rt.Call(actor.Constructor, &params)
 		})
 	})
+
+	t.Run("test construct with malformed multiaddr", func(t *testing.T) {
+		rt := builder.Build(t)
+		params := miner.ConstructorParams{
+			OwnerAddr:           owner,
+			WorkerAddr:          worker,
+			WindowPoStProofType: abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
+			PeerId:              testPid,
+			Multiaddrs:          []abi.Multiaddrs{testIP4Multiaddr, abi.Multiaddrs("imafilminer")},
+		}
+
+		rt.ExpectValidateCallerAddr(builtin.InitActorAddr)
+
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "invalid multiaddr 1", func() {
+			rt.Call(actor.Constructor, &params)
+		})
+	})
 }
 
 // Test operations related to peer info (peer ID/multiaddrs)
@@ -288,7 +373,7 @@ func TestPeerInfo(t *testing.T) {
 		rt := builder.Build(t)
 		h.constructAndVerify(rt)
 
-		h.setPeerID(rt, abi.PeerID("new peer id"))
+		h.setPeerID(rt, tutil.MakePID("new peer id"))
 		h.checkState(rt)
 	})
 
@@ -311,11 +396,21 @@ func TestPeerInfo(t *testing.T) {
 		h.checkState(rt)
 	})
 
+	t.Run("can't set malformed peer id", func(t *testing.T) {
+		rt := builder.Build(t)
+		h.constructAndVerify(rt)
+
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "invalid peer ID", func() {
+			h.setPeerID(rt, abi.PeerID("new peer id"))
+		})
+		h.checkState(rt)
+	})
+
 	t.Run("can set multiaddrs", func(t *testing.T) {
 		rt := builder.Build(t)
 		h.constructAndVerify(rt)
 
-		h.setMultiaddrs(rt, abi.Multiaddrs("imanewminer"))
+		h.setMultiaddrs(rt, testIP4Multiaddr)
 		h.checkState(rt)
 	})
 
@@ -323,7 +418,7 @@ func TestPeerInfo(t *testing.T) {
 		rt := builder.Build(t)
 		h.constructAndVerify(rt)
 
-		h.setMultiaddrs(rt, abi.Multiaddrs("imanewminer"), abi.Multiaddrs("ihavemany"))
+		h.setMultiaddrs(rt, testIP4Multiaddr, testDNS4Multiaddr)
 		h.checkState(rt)
 	})
 
@@ -359,6 +454,16 @@ func TestPeerInfo(t *testing.T) {
 		})
 		h.checkState(rt)
 	})
+
+	t.Run("can't set malformed multiaddrs", func(t *testing.T) {
+		rt := builder.Build(t)
+		h.constructAndVerify(rt)
+
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "invalid multiaddr 1", func() {
+			h.setMultiaddrs(rt, testIP4Multiaddr, abi.Multiaddrs("imanewminer"))
+		})
+		h.checkState(rt)
+	})
 }
 
 // Tests for fetching and manipulating miner addresses.
@@ -1306,6 +1411,117 @@ func TestWindowPost(t *testing.T) {
 		actor.disputeWindowPoSt(rt, dlinfo, 0, []*miner.SectorOnChainInfo{sector}, result)
 	})
 
+	t.Run("snapshots pruned after retention period", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		actor.setProofType(abi.RegisteredSealProof_StackedDrg2KiBV1_1)
+		rt := builderForHarness(actor).
+			WithEpoch(precommitEpoch).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		actor.constructAndVerify(rt)
+		store := rt.AdtStore()
+		sector := actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, nil, true)[0]
+		pwr := miner.PowerForSector(actor.sectorSize, sector)
+
+		dlIdx, pIdx, err := getState(rt).FindSector(store, sector.SectorNumber)
+		require.NoError(t, err)
+		dlinfo := advanceToDeadline(rt, actor, dlIdx)
+		partitions := []miner.PoStPartition{
+			{Index: pIdx, Skipped: bitfield.New()},
+		}
+		actor.submitWindowPoSt(rt, dlinfo, partitions, []*miner.SectorOnChainInfo{sector}, &poStConfig{
+			expectedPowerDelta: pwr,
+		})
+
+		snapshotLengths := func() (uint64, uint64) {
+			deadline := actor.getDeadline(rt, dlIdx)
+			partitionsSnapshot, err := deadline.PartitionsSnapshotArray(store)
+			require.NoError(t, err)
+			proofsSnapshot, err := deadline.OptimisticProofsSnapshotArray(store)
+			require.NoError(t, err)
+			return partitionsSnapshot.Length(), proofsSnapshot.Length()
+		}
+
+		// Proofs are snapshotted at the end of the deadline.
+		advanceDeadline(rt, actor, &cronConfig{})
+		partitionCount, proofCount := snapshotLengths()
+		assert.Equal(t, uint64(1), partitionCount)
+		assert.Equal(t, uint64(1), proofCount)
+
+		// The snapshot is retained until the end of the first deadline closing after the retention period.
+		pruneEpoch := dlinfo.Close + miner.WPoStSnapshotRetention
+		for actor.deadline(rt).Close < pruneEpoch {
+			advanceDeadline(rt, actor, &cronConfig{})
+		}
+		partitionCount, proofCount = snapshotLengths()
+		assert.Equal(t, uint64(1), partitionCount)
+		assert.Equal(t, uint64(1), proofCount)
+
+		advanceDeadline(rt, actor, &cronConfig{})
+		partitionCount, proofCount = snapshotLengths()
+		assert.Zero(t, partitionCount)
+		assert.Zero(t, proofCount)
+
+		// The deadline's current partitions are unaffected.
+		deadline := actor.getDeadline(rt, dlIdx)
+		partition, err := deadline.LoadPartition(store, pIdx)
+		require.NoError(t, err)
+		assertBitfieldEquals(t, partition.Sectors, uint64(sector.SectorNumber))
+		actor.checkState(rt)
+	})
+
+	t.Run("expired snapshots pruned explicitly for a reward", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		actor.setProofType(abi.RegisteredSealProof_StackedDrg2KiBV1_1)
+		rt := builderForHarness(actor).
+			WithEpoch(precommitEpoch).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		actor.constructAndVerify(rt)
+		store := rt.AdtStore()
+		sector := actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, nil, true)[0]
+		pwr := miner.PowerForSector(actor.sectorSize, sector)
+
+		dlIdx, pIdx, err := getState(rt).FindSector(store, sector.SectorNumber)
+		require.NoError(t, err)
+		dlinfo := advanceToDeadline(rt, actor, dlIdx)
+		partitions := []miner.PoStPartition{
+			{Index: pIdx, Skipped: bitfield.New()},
+		}
+		actor.submitWindowPoSt(rt, dlinfo, partitions, []*miner.SectorOnChainInfo{sector}, &poStConfig{
+			expectedPowerDelta: pwr,
+		})
+		advanceDeadline(rt, actor, &cronConfig{})
+
+		// Without cron, the snapshot is retained until it can no longer be disputed.
+		pruneEpoch := dlinfo.Close + miner.WPoStSnapshotRetention
+		rt.SetEpoch(pruneEpoch - 1)
+		rt.SetCaller(tutil.NewIDAddr(t, 1000), builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "no expired proofs", func() {
+			rt.Call(actor.a.PruneExpiredProofs, nil)
+		})
+		rt.Reset()
+
+		rt.SetEpoch(pruneEpoch)
+		actor.pruneExpiredProofs(rt, tutil.NewIDAddr(t, 1000), miner.RewardForPrunedProofs(1))
+
+		deadline := actor.getDeadline(rt, dlIdx)
+		partitionsSnapshot, err := deadline.PartitionsSnapshotArray(store)
+		require.NoError(t, err)
+		assert.Zero(t, partitionsSnapshot.Length())
+		proofsSnapshot, err := deadline.OptimisticProofsSnapshotArray(store)
+		require.NoError(t, err)
+		assert.Zero(t, proofsSnapshot.Length())
+		actor.checkState(rt)
+
+		// Once pruned, there is nothing more to prune.
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "no expired proofs", func() {
+			rt.Call(actor.a.PruneExpiredProofs, nil)
+		})
+	})
+
 	t.Run("invalid submissions", func(t *testing.T) {
 		actor := newHarness(t, periodOffset)
 		rt := builderForHarness(actor).
@@ -1544,6 +1760,60 @@ func TestWindowPost(t *testing.T) {
 		rt.Verify()
 	})
 
+	t.Run("validates submissions against state", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		rt := builderForHarness(actor).
+			WithEpoch(precommitEpoch).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		actor.constructAndVerify(rt)
+		store := rt.AdtStore()
+		sector := actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, nil, true)[0]
+		pwr := miner.PowerForSector(actor.sectorSize, sector)
+
+		dlIdx, pIdx, err := getState(rt).FindSector(store, sector.SectorNumber)
+		require.NoError(t, err)
+		dlInfo := advanceToDeadline(rt, actor, dlIdx)
+
+		makeParams := func(partitions ...miner.PoStPartition) *miner.SubmitWindowedPoStParams {
+			return &miner.SubmitWindowedPoStParams{
+				Deadline:         dlIdx,
+				Partitions:       partitions,
+				Proofs:           makePoStProofs(actor.windowPostProofType),
+				ChainCommitEpoch: dlInfo.Challenge,
+				ChainCommitRand:  abi.Randomness("chaincommitment"),
+			}
+		}
+		requireExitCode := func(code exitcode.ExitCode, params *miner.SubmitWindowedPoStParams) {
+			err := miner.ValidateWindowPoStSubmission(store, getState(rt), params, rt.Epoch())
+			require.Error(t, err)
+			assert.Equal(t, code, exitcode.Unwrap(err, exitcode.Ok), err.Error())
+		}
+
+		good := makeParams(miner.PoStPartition{Index: pIdx, Skipped: bf()})
+		require.NoError(t, miner.ValidateWindowPoStSubmission(store, getState(rt), good, rt.Epoch()))
+
+		requireExitCode(exitcode.ErrIllegalArgument, makeParams(
+			miner.PoStPartition{Index: pIdx, Skipped: bf()}, miner.PoStPartition{Index: pIdx, Skipped: bf()}))
+		requireExitCode(exitcode.ErrNotFound, makeParams(miner.PoStPartition{Index: pIdx + 1, Skipped: bf()}))
+		requireExitCode(exitcode.ErrIllegalArgument, makeParams(
+			miner.PoStPartition{Index: pIdx, Skipped: bf(uint64(sector.SectorNumber) + 1)}))
+
+		wrongDeadline := makeParams(miner.PoStPartition{Index: pIdx, Skipped: bf()})
+		wrongDeadline.Deadline = (dlIdx + 1) % miner.WPoStPeriodDeadlines
+		requireExitCode(exitcode.ErrIllegalArgument, wrongDeadline)
+
+		lateCommit := makeParams(miner.PoStPartition{Index: pIdx, Skipped: bf()})
+		lateCommit.ChainCommitEpoch = rt.Epoch()
+		requireExitCode(exitcode.ErrIllegalArgument, lateCommit)
+
+		// Once the partition is proven, another proof for it is rejected.
+		actor.submitWindowPoStRaw(rt, dlInfo, []*miner.SectorOnChainInfo{sector}, good, &poStConfig{
+			expectedPowerDelta: pwr,
+		})
+		requireExitCode(miner.ErrPartitionAlreadyProven, good)
+	})
+
 	t.Run("test duplicate proof rejected", func(t *testing.T) {
 		actor := newHarness(t, periodOffset)
 		actor.setProofType(abi.RegisteredSealProof_StackedDrg2KiBV1_1)
@@ -1594,7 +1864,7 @@ func TestWindowPost(t *testing.T) {
 		// From version 7, a duplicate is explicitly rejected.
 		rt.ExpectValidateCallerAddr(append(actor.controlAddrs, actor.owner, actor.worker)...)
 		rt.ExpectGetRandomnessTickets(crypto.DomainSeparationTag_PoStChainCommit, dlinfo.Challenge, nil, commitRand)
-		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "partition already proven", func() {
+		rt.ExpectAbortContainsMessage(miner.ErrPartitionAlreadyProven, "partition already proven", func() {
 			rt.Call(actor.a.SubmitWindowedPoSt, &params)
 		})
 		rt.Reset()
@@ -1639,6 +1909,11 @@ func TestWindowPost(t *testing.T) {
 			// Verify proof recorded
 			deadline := actor.getDeadline(rt, dlIdx)
 			assertBitfieldEquals(t, deadline.PartitionsPoSted, 0)
+
+			status := actor.deadlinePostStatus(rt)
+			assert.Equal(t, dlIdx, status.Deadline)
+			assert.Equal(t, uint64(2), status.Partitions)
+			assertBitfieldEquals(t, status.PartitionsPoSted, 0)
 		}
 		{
 			// Attempt PoSt for both partitions, thus duplicating proof for partition 0, so rejected
@@ -1650,7 +1925,7 @@ func TestWindowPost(t *testing.T) {
 			pwr := miner.PowerForSectors(actor.sectorSize, sectorsToProve)
 
 			// From network version 7, the miner outright rejects attempts to prove a partition twice.
-			rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "partition already proven", func() {
+			rt.ExpectAbortContainsMessage(miner.ErrPartitionAlreadyProven, "partition already proven", func() {
 				actor.submitWindowPoSt(rt, dlinfo, partitions, sectorsToProve, &poStConfig{
 					expectedPowerDelta: pwr,
 				})
@@ -1670,6 +1945,7 @@ func TestWindowPost(t *testing.T) {
 			// Verify both proofs now recorded
 			deadline := actor.getDeadline(rt, dlIdx)
 			assertBitfieldEquals(t, deadline.PartitionsPoSted, 0, 1)
+			assertBitfieldEquals(t, actor.deadlinePostStatus(rt).PartitionsPoSted, 0, 1)
 		}
 
 		// Advance to end-of-deadline cron to verify no penalties.
@@ -1677,6 +1953,55 @@ func TestWindowPost(t *testing.T) {
 		actor.checkState(rt)
 	})
 
+	t.Run("challenge matches that of on-chain verification", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		actor.setProofType(abi.RegisteredSealProof_StackedDrg2KiBV1_1)
+		rt := builderForHarness(actor).
+			WithEpoch(precommitEpoch).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		actor.constructAndVerify(rt)
+		infos := actor.commitAndProveSectors(rt, 2, defaultSectorExpiration, nil, true)
+		advanceAndSubmitPoSts(rt, actor, infos...)
+
+		// Declare one sector faulty, to be substituted by a stand-in.
+		advanceDeadline(rt, actor, &cronConfig{})
+		actor.declareFaults(rt, infos[1])
+
+		dlIdx, pIdx, err := getState(rt).FindSector(rt.AdtStore(), infos[0].SectorNumber)
+		require.NoError(t, err)
+		dlinfo := actor.deadline(rt)
+		for dlinfo.Index != dlIdx {
+			dlinfo = advanceDeadline(rt, actor, &cronConfig{})
+		}
+
+		var entropy bytes.Buffer
+		receiver := rt.Receiver()
+		require.NoError(t, receiver.MarshalCBOR(&entropy))
+		helperEntropy, err := miner.WindowPoStChallengeEntropy(rt.Receiver())
+		require.NoError(t, err)
+		assert.Equal(t, entropy.Bytes(), helperEntropy)
+
+		challengeRand := abi.Randomness([]byte{10, 11, 12, 13})
+		rt.ExpectValidateCallerAny()
+		rt.ExpectGetRandomnessBeacon(crypto.DomainSeparationTag_WindowedPoStChallengeSeed, dlinfo.Challenge, entropy.Bytes(), challengeRand)
+		ret := rt.Call(actor.a.WindowPoStChallenge, &miner.WindowPoStChallengeParams{Partitions: bf(pIdx)}).(*miner.WindowPoStChallengeReturn)
+		rt.Verify()
+
+		standIn := proof.SectorInfo{SealProof: infos[0].SealProof, SectorNumber: infos[0].SectorNumber, SealedCID: infos[0].SealedCID}
+		assert.Equal(t, dlIdx, ret.Deadline)
+		assert.Equal(t, dlinfo.Challenge, ret.ChallengeEpoch)
+		assert.Equal(t, abi.PoStRandomness(challengeRand), ret.Randomness)
+		assert.Equal(t, []proof.SectorInfo{standIn, standIn}, ret.ChallengedSectors)
+
+		// A proof of a partition that doesn't exist can't be challenged.
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrNotFound, "no such partition", func() {
+			rt.Call(actor.a.WindowPoStChallenge, &miner.WindowPoStChallengeParams{Partitions: bf(pIdx + 1)})
+		})
+		actor.checkState(rt)
+	})
+
 	t.Run("successful recoveries recover power", func(t *testing.T) {
 		actor := newHarness(t, periodOffset)
 		actor.setProofType(abi.RegisteredSealProof_StackedDrg2KiBV1_1)
@@ -1814,7 +2139,11 @@ func TestWindowPost(t *testing.T) {
 		actor.checkState(rt)
 	})
 
-	t.Run("skipping all sectors in a partition rejected", func(t *testing.T) {
+	t.Run("skipped faults are recovered automatically when proven in the next proving period", func(t *testing.T) {
+		prevAutomaticFaultRecovery := miner.AutomaticFaultRecovery
+		miner.AutomaticFaultRecovery = true
+		defer func() { miner.AutomaticFaultRecovery = prevAutomaticFaultRecovery }()
+
 		actor := newHarness(t, periodOffset)
 		actor.setProofType(abi.RegisteredSealProof_StackedDrg2KiBV1_1)
 		rt := builderForHarness(actor).
@@ -1826,31 +2155,50 @@ func TestWindowPost(t *testing.T) {
 
 		actor.applyRewards(rt, bigRewards, big.Zero())
 
-		// Skip to the due deadline.
 		st := getState(rt)
 		dlIdx, pIdx, err := st.FindSector(rt.AdtStore(), infos[0].SectorNumber)
 		require.NoError(t, err)
-		dlIdx2, pIdx2, err := st.FindSector(rt.AdtStore(), infos[1].SectorNumber)
-		require.NoError(t, err)
-		assert.Equal(t, dlIdx, dlIdx2) // this test will need to change when these are not equal
-		assert.Equal(t, pIdx, pIdx2)   // this test will need to change when these are not equal
 		dlinfo := advanceToDeadline(rt, actor, dlIdx)
 
-		// PoSt with all sectors skipped fails to validate, leaving power un-activated.
+		// Submit PoSt with a skipped fault for the first sector.
+		cfg := &poStConfig{
+			expectedPowerDelta: miner.PowerForSectors(actor.sectorSize, infos[1:]),
+		}
 		partitions := []miner.PoStPartition{
-			{Index: pIdx, Skipped: bf(uint64(infos[0].SectorNumber), uint64(infos[1].SectorNumber))},
+			{Index: pIdx, Skipped: bf(uint64(infos[0].SectorNumber))},
 		}
-		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
-			actor.submitWindowPoSt(rt, dlinfo, partitions, infos, nil)
-		})
-		rt.Reset()
+		actor.submitWindowPoSt(rt, dlinfo, partitions, infos, cfg)
 
-		// These sectors are detected faulty and pay no penalty this time.
-		advanceDeadline(rt, actor, &cronConfig{continuedFaultsPenalty: big.Zero()})
+		faultFee := actor.continuedFaultPenalty(infos[:1])
+		dlinfo = advanceDeadline(rt, actor, &cronConfig{continuedFaultsPenalty: faultFee})
+		for dlinfo.Index != dlIdx {
+			dlinfo = advanceDeadline(rt, actor, &cronConfig{})
+		}
+
+		// Proving the faulty sector without a recovery declaration recovers its power.
+		cfg = &poStConfig{
+			expectedPowerDelta: miner.PowerForSectors(actor.sectorSize, infos[:1]),
+		}
+		partitions = []miner.PoStPartition{
+			{Index: pIdx, Skipped: bitfield.New()},
+		}
+		actor.submitWindowPoSt(rt, dlinfo, partitions, infos, cfg)
+
+		deadline, partition := actor.findSector(rt, infos[0].SectorNumber)
+		assert.True(t, deadline.FaultyPower.IsZero())
+		assert.True(t, partition.FaultyPower.IsZero())
+		assertBitfieldEmpty(t, partition.Faults)
+		assertBitfieldEmpty(t, partition.Recoveries)
+
+		advanceDeadline(rt, actor, &cronConfig{})
 		actor.checkState(rt)
 	})
 
-	t.Run("skipped recoveries are penalized and do not recover power", func(t *testing.T) {
+	t.Run("faults are not recovered automatically after more than a proving period", func(t *testing.T) {
+		prevAutomaticFaultRecovery := miner.AutomaticFaultRecovery
+		miner.AutomaticFaultRecovery = true
+		defer func() { miner.AutomaticFaultRecovery = prevAutomaticFaultRecovery }()
+
 		actor := newHarness(t, periodOffset)
 		actor.setProofType(abi.RegisteredSealProof_StackedDrg2KiBV1_1)
 		rt := builderForHarness(actor).
@@ -1862,30 +2210,119 @@ func TestWindowPost(t *testing.T) {
 
 		actor.applyRewards(rt, bigRewards, big.Zero())
 
-		// Submit first PoSt to ensure we are sufficiently early to add a fault
-		// advance to next proving period
-		advanceAndSubmitPoSts(rt, actor, infos...)
-
-		// advance deadline and declare fault on the first sector
-		advanceDeadline(rt, actor, &cronConfig{})
-		actor.declareFaults(rt, infos[0])
-
-		// advance a deadline and declare recovery
-		advanceDeadline(rt, actor, &cronConfig{})
-
-		// declare recovery
 		st := getState(rt)
 		dlIdx, pIdx, err := st.FindSector(rt.AdtStore(), infos[0].SectorNumber)
 		require.NoError(t, err)
-		actor.declareRecoveries(rt, dlIdx, pIdx, bf(uint64(infos[0].SectorNumber)), big.Zero())
-
-		// Skip to the due deadline.
 		dlinfo := advanceToDeadline(rt, actor, dlIdx)
 
-		// Now submit PoSt and skip recovered sector.
-		// No power should be returned
+		// Submit PoSt with a skipped fault for the first sector.
 		cfg := &poStConfig{
-			expectedPowerDelta: miner.NewPowerPairZero(),
+			expectedPowerDelta: miner.PowerForSectors(actor.sectorSize, infos[1:]),
+		}
+		partitions := []miner.PoStPartition{
+			{Index: pIdx, Skipped: bf(uint64(infos[0].SectorNumber))},
+		}
+		actor.submitWindowPoSt(rt, dlinfo, partitions, infos, cfg)
+
+		// Skip the first sector again at the next proving period, so it remains faulty.
+		faultFee := actor.continuedFaultPenalty(infos[:1])
+		dlinfo = advanceDeadline(rt, actor, &cronConfig{continuedFaultsPenalty: faultFee})
+		for dlinfo.Index != dlIdx {
+			dlinfo = advanceDeadline(rt, actor, &cronConfig{})
+		}
+		cfg = &poStConfig{
+			expectedPowerDelta: miner.NewPowerPairZero(),
+		}
+		actor.submitWindowPoSt(rt, dlinfo, partitions, infos, cfg)
+
+		// Two proving periods after the fault, proving the partition without skipping the sector doesn't recover it.
+		dlinfo = advanceDeadline(rt, actor, &cronConfig{continuedFaultsPenalty: faultFee})
+		for dlinfo.Index != dlIdx {
+			dlinfo = advanceDeadline(rt, actor, &cronConfig{})
+		}
+		partitions = []miner.PoStPartition{
+			{Index: pIdx, Skipped: bitfield.New()},
+		}
+		actor.submitWindowPoSt(rt, dlinfo, partitions, infos, cfg)
+
+		_, partition := actor.findSector(rt, infos[0].SectorNumber)
+		assertBitfieldEquals(t, partition.Faults, uint64(infos[0].SectorNumber))
+		assertBitfieldEmpty(t, partition.Recoveries)
+		actor.checkState(rt)
+	})
+
+	t.Run("skipping all sectors in a partition rejected", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		actor.setProofType(abi.RegisteredSealProof_StackedDrg2KiBV1_1)
+		rt := builderForHarness(actor).
+			WithEpoch(precommitEpoch).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		actor.constructAndVerify(rt)
+		infos := actor.commitAndProveSectors(rt, 2, defaultSectorExpiration, nil, true)
+
+		actor.applyRewards(rt, bigRewards, big.Zero())
+
+		// Skip to the due deadline.
+		st := getState(rt)
+		dlIdx, pIdx, err := st.FindSector(rt.AdtStore(), infos[0].SectorNumber)
+		require.NoError(t, err)
+		dlIdx2, pIdx2, err := st.FindSector(rt.AdtStore(), infos[1].SectorNumber)
+		require.NoError(t, err)
+		assert.Equal(t, dlIdx, dlIdx2) // this test will need to change when these are not equal
+		assert.Equal(t, pIdx, pIdx2)   // this test will need to change when these are not equal
+		dlinfo := advanceToDeadline(rt, actor, dlIdx)
+
+		// PoSt with all sectors skipped fails to validate, leaving power un-activated.
+		partitions := []miner.PoStPartition{
+			{Index: pIdx, Skipped: bf(uint64(infos[0].SectorNumber), uint64(infos[1].SectorNumber))},
+		}
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.submitWindowPoSt(rt, dlinfo, partitions, infos, nil)
+		})
+		rt.Reset()
+
+		// These sectors are detected faulty and pay no penalty this time.
+		advanceDeadline(rt, actor, &cronConfig{continuedFaultsPenalty: big.Zero()})
+		actor.checkState(rt)
+	})
+
+	t.Run("skipped recoveries are penalized and do not recover power", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		actor.setProofType(abi.RegisteredSealProof_StackedDrg2KiBV1_1)
+		rt := builderForHarness(actor).
+			WithEpoch(precommitEpoch).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		actor.constructAndVerify(rt)
+		infos := actor.commitAndProveSectors(rt, 2, defaultSectorExpiration, nil, true)
+
+		actor.applyRewards(rt, bigRewards, big.Zero())
+
+		// Submit first PoSt to ensure we are sufficiently early to add a fault
+		// advance to next proving period
+		advanceAndSubmitPoSts(rt, actor, infos...)
+
+		// advance deadline and declare fault on the first sector
+		advanceDeadline(rt, actor, &cronConfig{})
+		actor.declareFaults(rt, infos[0])
+
+		// advance a deadline and declare recovery
+		advanceDeadline(rt, actor, &cronConfig{})
+
+		// declare recovery
+		st := getState(rt)
+		dlIdx, pIdx, err := st.FindSector(rt.AdtStore(), infos[0].SectorNumber)
+		require.NoError(t, err)
+		actor.declareRecoveries(rt, dlIdx, pIdx, bf(uint64(infos[0].SectorNumber)), big.Zero())
+
+		// Skip to the due deadline.
+		dlinfo := advanceToDeadline(rt, actor, dlIdx)
+
+		// Now submit PoSt and skip recovered sector.
+		// No power should be returned
+		cfg := &poStConfig{
+			expectedPowerDelta: miner.NewPowerPairZero(),
 		}
 		partitions := []miner.PoStPartition{
 			{Index: pIdx, Skipped: bf(uint64(infos[0].SectorNumber))},
@@ -2341,6 +2778,75 @@ func TestDeadlineCron(t *testing.T) {
 		})
 		actor.checkState(rt)
 	})
+
+	t.Run("deferred cron processes the deadline that ended", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		allSectors := actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, nil, true)
+		advanceAndSubmitPoSts(rt, actor, allSectors...)
+
+		st := getState(rt)
+		dlIdx, _, err := st.FindSector(rt.AdtStore(), allSectors[0].SectorNumber)
+		require.NoError(t, err)
+		dlinfo := advanceToDeadline(rt, actor, dlIdx)
+
+		// The power actor delivers the event a few epochs into the next deadline.
+		rt.SetEpoch(dlinfo.Last() + 3)
+
+		// The missed PoSt is detected for the deadline that ended, and the next cron
+		// is enrolled for the end of the deadline now open.
+		pwr := miner.PowerForSectors(actor.sectorSize, allSectors)
+		powerDeltaClaim := miner.NewPowerPair(pwr.Raw.Neg(), pwr.QA.Neg())
+		actor.onDeadlineCron(rt, &cronConfig{
+			expectedEnrollment:       dlinfo.Last() + miner.WPoStChallengeWindow,
+			detectedFaultsPowerDelta: &powerDeltaClaim,
+		})
+
+		st = getState(rt)
+		assert.Equal(t, (dlIdx+1)%miner.WPoStPeriodDeadlines, st.CurrentDeadline)
+		actor.checkState(rt)
+	})
+
+	t.Run("reports the deposit burned for each expired pre-commit", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		cronCtrl := newCronControl(rt, actor)
+		cleanUpEpoch := cronCtrl.preCommitToStartCron(t, periodOffset+1)
+
+		// A second pre-commit in the same epoch expires alongside the first.
+		dlinfo := actor.deadline(rt)
+		expiration := dlinfo.PeriodEnd() + defaultSectorExpiration*miner.WPoStProvingPeriod
+		actor.preCommitSector(rt, actor.makePreCommit(1, rt.Epoch()-1, expiration, nil), preCommitConf{}, false)
+
+		st := getState(rt)
+		deposits := make([]abi.TokenAmount, 2)
+		for i := range deposits {
+			precommit, found, err := st.GetPrecommittedSector(rt.AdtStore(), abi.SectorNumber(i))
+			require.NoError(t, err)
+			require.True(t, found)
+			deposits[i] = precommit.PreCommitDeposit
+		}
+
+		dlinfo = miner.NewDeadlineInfoFromOffsetAndEpoch(st.ProvingPeriodStart, rt.Epoch())
+		for dlinfo.Open <= cleanUpEpoch {
+			dlinfo = advanceDeadline(rt, actor, &cronConfig{})
+		}
+		rt.ClearEvents()
+		rt.SetEpoch(dlinfo.Last())
+		actor.onDeadlineCron(rt, &cronConfig{
+			noEnrollment:            true,
+			expiredPrecommitPenalty: big.Add(deposits[0], deposits[1]),
+		})
+
+		rt.ExpectEmitted(miner.EventPreCommitDepositsReleased, &miner.PreCommitDepositsReleasedEvent{
+			Releases: []miner.PreCommitDepositRelease{
+				{SectorNumber: 0, Burned: deposits[0], Refunded: big.Zero()},
+				{SectorNumber: 1, Burned: deposits[1], Refunded: big.Zero()},
+			},
+		})
+		cronCtrl.requireCronInactive(t)
+		actor.checkState(rt)
+	})
 }
 
 // cronControl is a convenience harness on top of the actor harness giving the caller access to common
@@ -2511,29 +3017,379 @@ func TestDeclareFaults(t *testing.T) {
 		dlIdx, _, err := st.FindSector(rt.AdtStore(), allSectors[0].SectorNumber)
 		require.NoError(t, err)
 
-		// advance to first proving period and submit so we'll have time to declare the fault next cycle
+		// advance to first proving period and submit so we'll have time to declare the fault next cycle
+		advanceAndSubmitPoSts(rt, actor, allSectors...)
+
+		// Declare the sector as faulted
+		actor.declareFaults(rt, allSectors...)
+
+		// faults are recorded in state
+		dl := actor.getDeadline(rt, dlIdx)
+		assert.True(t, pwr.Equals(dl.FaultyPower))
+
+		// Skip to end of proving period.
+		dlinfo := actor.deadline(rt)
+		for dlinfo.Index != dlIdx {
+			dlinfo = advanceDeadline(rt, actor, &cronConfig{})
+		}
+
+		// faults are charged at ongoing rate and no additional power is removed
+		ongoingPwr := miner.PowerForSectors(actor.sectorSize, allSectors)
+		ongoingPenalty := miner.PledgePenaltyForContinuedFault(actor.epochRewardSmooth, actor.epochQAPowerSmooth, ongoingPwr.QA)
+
+		advanceDeadline(rt, actor, &cronConfig{
+			continuedFaultsPenalty: ongoingPenalty,
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fault expirations report declared faults", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		allSectors := actor.commitAndProveSectors(rt, 2, defaultSectorExpiration, nil, true)
+		advanceAndSubmitPoSts(rt, actor, allSectors...)
+
+		// No faults yet.
+		assert.Empty(t, actor.faultExpirations(rt, rt.Epoch()+miner.FaultMaxAge+miner.WPoStProvingPeriod).Expirations)
+
+		st := getState(rt)
+		dlIdx, pIdx, err := st.FindSector(rt.AdtStore(), allSectors[0].SectorNumber)
+		require.NoError(t, err)
+		targetDeadline := miner.NewDeadlineInfo(st.ProvingPeriodStart, dlIdx, rt.Epoch()).NextNotElapsed()
+		actor.declareFaults(rt, allSectors[0])
+
+		expiration := targetDeadline.Last() + miner.FaultMaxAge
+		ret := actor.faultExpirations(rt, expiration)
+		require.Len(t, ret.Expirations, 1)
+		assert.Equal(t, dlIdx, ret.Expirations[0].Deadline)
+		assert.Equal(t, pIdx, ret.Expirations[0].Partition)
+		assert.Equal(t, targetDeadline.Last(), ret.Expirations[0].FaultEpoch)
+		assert.Equal(t, expiration, ret.Expirations[0].Expiration)
+		assertBitfieldEquals(t, ret.Expirations[0].Sectors, uint64(allSectors[0].SectorNumber))
+
+		// Expirations after the requested epoch are excluded.
+		assert.Empty(t, actor.faultExpirations(rt, expiration-1).Expirations)
+
+		// A page limited to the only expiration has no more.
+		ret = actor.faultExpirationsPage(rt, expiration, builtin.PageParams{Limit: 1})
+		require.Len(t, ret.Expirations, 1)
+		assert.False(t, ret.Page.HasMore)
+		actor.checkState(rt)
+	})
+
+	t.Run("declared faults return their reasons", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		allSectors := actor.commitAndProveSectors(rt, 3, defaultSectorExpiration, nil, true)
+		advanceAndSubmitPoSts(rt, actor, allSectors...)
+
+		st := getState(rt)
+		dlIdx, pIdx, err := st.FindSector(rt.AdtStore(), allSectors[0].SectorNumber)
+		require.NoError(t, err)
+		declaration := func(sector *miner.SectorOnChainInfo, reason miner.FaultReason) miner.FaultDeclarationWithReason {
+			return miner.FaultDeclarationWithReason{
+				Deadline:  dlIdx,
+				Partition: pIdx,
+				Sectors:   bf(uint64(sector.SectorNumber)),
+				Reason:    reason,
+			}
+		}
+		params := &miner.DeclareFaultsWithReasonsParams{Faults: []miner.FaultDeclarationWithReason{
+			declaration(allSectors[0], miner.FaultReasonMaintenance),
+			declaration(allSectors[1], miner.FaultReasonDiskFailure),
+			declaration(allSectors[2], miner.FaultReasonMaintenance),
+		}}
+		ret := actor.declareFaultsWithReasons(rt, params, allSectors...)
+
+		require.Len(t, ret.Reasons, 2)
+		assert.Equal(t, miner.FaultReasonDiskFailure, ret.Reasons[0].Reason)
+		assertBitfieldEquals(t, ret.Reasons[0].Sectors, uint64(allSectors[1].SectorNumber))
+		assert.Equal(t, miner.FaultReasonMaintenance, ret.Reasons[1].Reason)
+		assertBitfieldEquals(t, ret.Reasons[1].Sectors, uint64(allSectors[0].SectorNumber), uint64(allSectors[2].SectorNumber))
+
+		// The reason has no effect on the faults recorded.
+		dl := actor.getDeadline(rt, dlIdx)
+		pwr := miner.PowerForSectors(actor.sectorSize, allSectors)
+		assert.True(t, pwr.Equals(dl.FaultyPower))
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects invalid fault reason", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		allSectors := actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, nil, true)
+		advanceAndSubmitPoSts(rt, actor, allSectors...)
+
+		st := getState(rt)
+		dlIdx, pIdx, err := st.FindSector(rt.AdtStore(), allSectors[0].SectorNumber)
+		require.NoError(t, err)
+		params := &miner.DeclareFaultsWithReasonsParams{Faults: []miner.FaultDeclarationWithReason{{
+			Deadline:  dlIdx,
+			Partition: pIdx,
+			Sectors:   bf(uint64(allSectors[0].SectorNumber)),
+			Reason:    miner.FaultReasonMaintenance + 1,
+		}}}
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "invalid fault reason", func() {
+			rt.Call(actor.a.DeclareFaultsWithReasons, params)
+		})
+		rt.Reset()
+		actor.checkState(rt)
+	})
+}
+
+func TestProvingPeriodStatus(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+
+	t.Run("empty miner", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		ret := actor.provingPeriodStatus(rt)
+		assert.Equal(t, miner.SectorStatusCounts{LivePower: miner.NewPowerPairZero()}, ret.Sectors)
+		dlInfo := actor.deadline(rt)
+		assert.Equal(t, dlInfo.Index, ret.CurrentDeadline)
+		assert.Equal(t, []abi.ChainEpoch{
+			dlInfo.NextOpen(),
+			dlInfo.NextOpen() + miner.WPoStChallengeWindow,
+			dlInfo.NextOpen() + 2*miner.WPoStChallengeWindow,
+		}, ret.NextDeadlineOpens)
+	})
+
+	t.Run("counts sectors by status", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		sectors := actor.commitAndProveSectors(rt, 3, defaultSectorExpiration, nil, true)
+		livePower := miner.PowerForSectors(actor.sectorSize, sectors)
+
+		ret := actor.provingPeriodStatus(rt)
+		assert.Equal(t, miner.SectorStatusCounts{Live: 3, Unproven: 3, LivePower: livePower}, ret.Sectors)
+
+		advanceAndSubmitPoSts(rt, actor, sectors...)
+		actor.declareFaults(rt, sectors[0], sectors[1])
+		dlIdx, pIdx, err := getState(rt).FindSector(rt.AdtStore(), sectors[0].SectorNumber)
+		require.NoError(t, err)
+		actor.declareRecoveries(rt, dlIdx, pIdx, bf(uint64(sectors[0].SectorNumber)), big.Zero())
+
+		ret = actor.provingPeriodStatus(rt)
+		assert.Equal(t, miner.SectorStatusCounts{Live: 3, Faulty: 2, Recovering: 1, LivePower: livePower}, ret.Sectors)
+		assert.Equal(t, actor.deadline(rt).Index, ret.CurrentDeadline)
+		actor.checkState(rt)
+	})
+}
+
+func TestProjectSectorPledge(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+
+	t.Run("projects the collateral required of a sector", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		rt.SetEpoch(periodOffset + 1)
+		expiration := actor.deadline(rt).PeriodEnd() + abi.ChainEpoch(defaultSectorExpiration)*miner.WPoStProvingPeriod
+
+		projection := actor.projectSectorPledge(rt, &miner.ProjectSectorPledgeParams{
+			Expiration:         expiration,
+			DealWeight:         big.Zero(),
+			VerifiedDealWeight: big.Zero(),
+		})
+		qaPower := miner.QAPowerForWeight(actor.sectorSize, expiration-rt.Epoch(), big.Zero(), big.Zero())
+		assert.Equal(t, qaPower, projection.QAPower)
+		assert.Equal(t, miner.InitialPledgeForPower(qaPower, actor.baselinePower, actor.epochRewardSmooth,
+			actor.epochQAPowerSmooth, rt.TotalFilCircSupply()), projection.InitialPledge)
+
+		// The projected deposit is that required by a pre-commitment at the same epoch.
+		precommit := actor.preCommitSector(rt, actor.makePreCommit(100, rt.Epoch()-1, expiration, nil), preCommitConf{}, true)
+		assert.Equal(t, precommit.PreCommitDeposit, projection.PreCommitDeposit)
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects an expiration that isn't in the future", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		rt.SetEpoch(periodOffset + 1)
+
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "must be after current epoch", func() {
+			rt.Call(actor.a.ProjectSectorPledge, &miner.ProjectSectorPledgeParams{
+				Expiration:         rt.Epoch(),
+				DealWeight:         big.Zero(),
+				VerifiedDealWeight: big.Zero(),
+			})
+		})
+		rt.Reset()
+	})
+}
+
+func TestExpirationSchedule(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+
+	t.Run("aggregates on time and early expirations", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		allSectors := actor.commitAndProveSectors(rt, 2, defaultSectorExpiration, nil, true)
+		advanceAndSubmitPoSts(rt, actor, allSectors...)
+
+		st := getState(rt)
+		dlIdx, _, err := st.FindSector(rt.AdtStore(), allSectors[0].SectorNumber)
+		require.NoError(t, err)
+		quant := st.QuantSpecForDeadline(dlIdx)
+		onTimeEpoch := quant.QuantizeUp(allSectors[0].Expiration)
+
+		ret := actor.expirationSchedule(rt, 0, onTimeEpoch)
+		require.Len(t, ret.Entries, 1)
+		assert.Equal(t, onTimeEpoch, ret.Entries[0].Epoch)
+		assert.Equal(t, uint64(2), ret.Entries[0].OnTimeSectors)
+		assert.Equal(t, uint64(0), ret.Entries[0].EarlySectors)
+		assert.Equal(t, big.Add(allSectors[0].InitialPledge, allSectors[1].InitialPledge), ret.Entries[0].OnTimePledge)
+		assert.True(t, ret.Entries[0].ActivePower.Equals(miner.PowerForSectors(actor.sectorSize, allSectors)))
+		assert.True(t, ret.Entries[0].FaultyPower.IsZero())
+
+		// A faulty sector is scheduled to expire early.
+		targetDeadline := miner.NewDeadlineInfo(st.ProvingPeriodStart, dlIdx, rt.Epoch()).NextNotElapsed()
+		actor.declareFaults(rt, allSectors[0])
+		faultExpiration := targetDeadline.Last() + miner.FaultMaxAge
+
+		ret = actor.expirationSchedule(rt, 0, onTimeEpoch)
+		require.Len(t, ret.Entries, 2)
+		assert.Equal(t, faultExpiration, ret.Entries[0].Epoch)
+		assert.Equal(t, uint64(1), ret.Entries[0].EarlySectors)
+		assert.Equal(t, uint64(0), ret.Entries[0].OnTimeSectors)
+		assert.True(t, ret.Entries[0].FaultyPower.Equals(miner.PowerForSector(actor.sectorSize, allSectors[0])))
+		assert.Equal(t, onTimeEpoch, ret.Entries[1].Epoch)
+		assert.Equal(t, uint64(1), ret.Entries[1].OnTimeSectors)
+		assert.True(t, ret.Entries[1].ActivePower.Equals(miner.PowerForSector(actor.sectorSize, allSectors[1])))
+
+		// Expirations outside the range are excluded.
+		ret = actor.expirationSchedule(rt, faultExpiration+1, onTimeEpoch-1)
+		assert.Empty(t, ret.Entries)
+		assert.False(t, ret.Page.HasMore)
+		actor.checkState(rt)
+	})
+
+	t.Run("pages through entries", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		allSectors := actor.commitAndProveSectors(rt, 2, defaultSectorExpiration, nil, true)
+		advanceAndSubmitPoSts(rt, actor, allSectors...)
+
+		st := getState(rt)
+		dlIdx, _, err := st.FindSector(rt.AdtStore(), allSectors[0].SectorNumber)
+		require.NoError(t, err)
+		onTimeEpoch := st.QuantSpecForDeadline(dlIdx).QuantizeUp(allSectors[0].Expiration)
+		targetDeadline := miner.NewDeadlineInfo(st.ProvingPeriodStart, dlIdx, rt.Epoch()).NextNotElapsed()
+		actor.declareFaults(rt, allSectors[0])
+		faultExpiration := targetDeadline.Last() + miner.FaultMaxAge
+
+		ret := actor.expirationSchedulePage(rt, 0, onTimeEpoch, builtin.PageParams{Limit: 1})
+		require.Len(t, ret.Entries, 1)
+		assert.Equal(t, faultExpiration, ret.Entries[0].Epoch)
+		require.True(t, ret.Page.HasMore)
+
+		ret = actor.expirationSchedulePage(rt, 0, onTimeEpoch, builtin.PageParams{Limit: 1, Cursor: ret.Page.NextCursor})
+		require.Len(t, ret.Entries, 1)
+		assert.Equal(t, onTimeEpoch, ret.Entries[0].Epoch)
+		assert.False(t, ret.Page.HasMore)
+		assert.Empty(t, ret.Page.NextCursor)
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects an invalid range", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.expirationSchedule(rt, 10, 9)
+		})
+	})
+
+	t.Run("rejects a malformed cursor", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "invalid page", func() {
+			actor.expirationSchedulePage(rt, 0, 10, builtin.PageParams{Cursor: []byte{0xff}})
+		})
+	})
+}
+
+func TestGetReliabilityStats(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+
+	t.Run("counts posts and declared faults", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		assert.Equal(t, miner.ReliabilityCounters{}, actor.getReliabilityStats(rt).Current)
+
+		allSectors := actor.commitAndProveSectors(rt, 2, defaultSectorExpiration, nil, true)
 		advanceAndSubmitPoSts(rt, actor, allSectors...)
+		posts := actor.getReliabilityStats(rt).Current.PoStsSubmitted
+		assert.True(t, posts > 0)
+
+		actor.declareFaults(rt, allSectors[0])
+		stats := actor.getReliabilityStats(rt)
+		assert.Equal(t, miner.ReliabilityCounters{PoStsSubmitted: posts, FaultsDeclared: 1}, stats.Current)
+		assert.Equal(t, miner.ReliabilityCounters{}, stats.Previous)
+
+		// The counters roll over into the previous window, and are then discarded.
+		rt.SetEpoch(stats.WindowStart + miner.ReliabilityStatsWindow)
+		rolled := actor.getReliabilityStats(rt)
+		assert.Equal(t, stats.WindowStart+miner.ReliabilityStatsWindow, rolled.WindowStart)
+		assert.Equal(t, miner.ReliabilityCounters{}, rolled.Current)
+		assert.Equal(t, stats.Current, rolled.Previous)
+
+		rt.SetEpoch(stats.WindowStart + 2*miner.ReliabilityStatsWindow)
+		rolled = actor.getReliabilityStats(rt)
+		assert.Equal(t, miner.ReliabilityCounters{}, rolled.Current)
+		assert.Equal(t, miner.ReliabilityCounters{}, rolled.Previous)
+	})
+}
 
-		// Declare the sector as faulted
-		actor.declareFaults(rt, allSectors...)
+func TestBatchLimits(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
 
-		// faults are recorded in state
-		dl := actor.getDeadline(rt, dlIdx)
-		assert.True(t, pwr.Equals(dl.FaultyPower))
+	t.Run("reports limits for the partition size", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
 
-		// Skip to end of proving period.
-		dlinfo := actor.deadline(rt)
-		for dlinfo.Index != dlIdx {
-			dlinfo = advanceDeadline(rt, actor, &cronConfig{})
-		}
+		ret := actor.batchLimits(rt)
+		assert.Equal(t, actor.partitionSize, ret.PartitionSectors)
+		assert.Equal(t, uint64(miner.DeclarationsMax), ret.DeclarationsMax)
+		assert.Equal(t, uint64(miner.AddressedPartitionsMax), ret.AddressedPartitionsMax)
+		assert.Equal(t, uint64(miner.AddressedSectorsMax), ret.AddressedSectorsMax)
+		assert.Equal(t, miner.AddressedSectorsMax/actor.partitionSize, ret.PoStPartitionsMax)
+		actor.checkState(rt)
+	})
 
-		// faults are charged at ongoing rate and no additional power is removed
-		ongoingPwr := miner.PowerForSectors(actor.sectorSize, allSectors)
-		ongoingPenalty := miner.PledgePenaltyForContinuedFault(actor.epochRewardSmooth, actor.epochQAPowerSmooth, ongoingPwr.QA)
+	t.Run("batch exceeding limits reports the offending declarations", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
 
-		advanceDeadline(rt, actor, &cronConfig{
-			continuedFaultsPenalty: ongoingPenalty,
+		params := &miner.TerminateSectorsParams{Terminations: []miner.TerminationDeclaration{
+			{Deadline: 0, Partition: 0, Sectors: seq(t, 0, 10)},
+			{Deadline: 0, Partition: 1, Sectors: seq(t, 10, miner.AddressedSectorsMax-10)},
+			{Deadline: 1, Partition: 0, Sectors: bf(miner.AddressedSectorsMax)},
+			{Deadline: 1, Partition: 1, Sectors: bf(miner.AddressedSectorsMax + 1)},
+		}}
+		rt.SetCaller(actor.owner, builtin.AccountActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "declarations 2 to 3 of 4 exceed batch limits", func() {
+			rt.Call(actor.a.TerminateSectors, params)
 		})
+		rt.Reset()
 		actor.checkState(rt)
 	})
 }
@@ -2875,7 +3731,7 @@ func TestExtendSectorExpiration(t *testing.T) {
 			// Half the sectors should expire on-time.
 			var onTimeTotal uint64
 			require.NoError(t, deadlines.ForEach(rt.AdtStore(), func(dlIdx uint64, dl *miner.Deadline) error {
-				expirationSet, err := dl.PopExpiredSectors(rt.AdtStore(), newExpiration-1, st.QuantSpecForDeadline(dlIdx))
+				expirationSet, _, err := dl.PopExpiredSectors(rt.AdtStore(), newExpiration-1, st.QuantSpecForDeadline(dlIdx), miner.ExpiringPartitionsMax)
 				require.NoError(t, err)
 
 				count, err := expirationSet.Count()
@@ -2888,7 +3744,7 @@ func TestExtendSectorExpiration(t *testing.T) {
 			// Half the sectors should expire late.
 			var extendedTotal uint64
 			require.NoError(t, deadlines.ForEach(rt.AdtStore(), func(dlIdx uint64, dl *miner.Deadline) error {
-				expirationSet, err := dl.PopExpiredSectors(rt.AdtStore(), newExpiration-1, st.QuantSpecForDeadline(dlIdx))
+				expirationSet, _, err := dl.PopExpiredSectors(rt.AdtStore(), newExpiration-1, st.QuantSpecForDeadline(dlIdx), miner.ExpiringPartitionsMax)
 				require.NoError(t, err)
 
 				count, err := expirationSet.Count()
@@ -3130,6 +3986,79 @@ func TestTerminateSectors(t *testing.T) {
 
 }
 
+func TestProcessEarlyTerminations(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+	reporter := tutil.NewIDAddr(t, 1500)
+
+	// Terminates a sector in state, leaving it in the early terminations queue as if too many were terminated
+	// to process immediately.
+	deferTermination := func(rt *mock.Runtime, sector *miner.SectorOnChainInfo) {
+		st := getState(rt)
+		store := rt.AdtStore()
+		dlIdx, pIdx, err := st.FindSector(store, sector.SectorNumber)
+		require.NoError(t, err)
+		deadlines, err := st.LoadDeadlines(store)
+		require.NoError(t, err)
+		deadline, err := deadlines.LoadDeadline(store, dlIdx)
+		require.NoError(t, err)
+		sectors, err := miner.LoadSectors(store, st.Sectors)
+		require.NoError(t, err)
+
+		_, err = deadline.TerminateSectors(store, sectors, rt.Epoch(), miner.PartitionSectorMap{
+			pIdx: bf(uint64(sector.SectorNumber)),
+		}, actor.sectorSize, st.QuantSpecForDeadline(dlIdx))
+		require.NoError(t, err)
+		require.NoError(t, deadlines.UpdateDeadline(store, dlIdx, deadline))
+		require.NoError(t, st.SaveDeadlines(store, deadlines))
+		st.EarlyTerminations.Set(dlIdx)
+		rt.ReplaceState(st)
+	}
+
+	t.Run("processes deferred terminations and rewards caller", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		sector := actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, nil, true)[0]
+		advanceAndSubmitPoSts(rt, actor, sector)
+		deferTermination(rt, sector)
+
+		dlIdx, pIdx, err := getState(rt).FindSector(rt.AdtStore(), sector.SectorNumber)
+		require.NoError(t, err)
+		ret := actor.earlyTerminations(rt, builtin.PageParams{})
+		require.Len(t, ret.Terminations, 1)
+		assert.Equal(t, dlIdx, ret.Terminations[0].Deadline)
+		assert.Equal(t, pIdx, ret.Terminations[0].Partition)
+		assert.Equal(t, rt.Epoch(), ret.Terminations[0].Epoch)
+		assertBitfieldEquals(t, ret.Terminations[0].Sectors, uint64(sector.SectorNumber))
+		assert.False(t, ret.Page.HasMore)
+
+		sectorPower := miner.QAPowerForSector(actor.sectorSize, sector)
+		expectedFee := miner.PledgePenaltyForTermination(sector.ExpectedDayReward, rt.Epoch()-sector.Activation,
+			sector.ExpectedStoragePledge, actor.epochQAPowerSmooth, sectorPower, actor.epochRewardSmooth,
+			sector.ReplacedDayReward, sector.ReplacedSectorAge)
+		actor.processEarlyTerminations(rt, reporter, expectedFee, sector.InitialPledge.Neg(), miner.RewardForProcessedEarlyTerminations)
+
+		assert.Empty(t, actor.earlyTerminations(rt, builtin.PageParams{}).Terminations)
+		assert.True(t, getState(rt).InitialPledge.IsZero())
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects when there are no early terminations", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetCaller(reporter, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "no early terminations", func() {
+			rt.Call(actor.a.ProcessEarlyTerminations, nil)
+		})
+		assert.Empty(t, actor.earlyTerminations(rt, builtin.PageParams{}).Terminations)
+		actor.checkState(rt)
+	})
+}
+
 func TestWithdrawBalance(t *testing.T) {
 	periodOffset := abi.ChainEpoch(100)
 	actor := newHarness(t, periodOffset)
@@ -3160,6 +4089,31 @@ func TestWithdrawBalance(t *testing.T) {
 		actor.checkState(rt)
 	})
 
+	t.Run("withdraws funds to recipient", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		recipient := tutil.NewIDAddr(t, 999)
+		actor.withdrawFundsTo(rt, recipient, onePercentBalance, onePercentBalance)
+		actor.checkState(rt)
+	})
+
+	t.Run("only owner may withdraw to recipient", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		recipient := tutil.NewIDAddr(t, 999)
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(actor.owner)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(actor.a.WithdrawBalanceTo, &miner.WithdrawBalanceToParams{
+				AmountRequested: onePercentBalance,
+				Recipient:       &recipient,
+			})
+		})
+		actor.checkState(rt)
+	})
+
 	t.Run("withdraw only what we can after fee debt", func(t *testing.T) {
 		rt := builder.Build(t)
 		actor.constructAndVerify(rt)
@@ -3176,6 +4130,47 @@ func TestWithdrawBalance(t *testing.T) {
 	})
 }
 
+func TestGetBalanceBreakdown(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+
+	t.Run("breakdown matches available balance", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		// Commit a sector so that the deadline cron is running while funds are locked.
+		rt.SetEpoch(periodOffset + 1)
+		actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, nil, true)
+
+		rewardAmount := big.Mul(big.NewInt(4), big.NewInt(1e18))
+		amountLocked, _ := miner.LockedRewardFromReward(rewardAmount)
+		actor.applyRewards(rt, rewardAmount, big.Zero())
+
+		st := getState(rt)
+		require.True(t, st.DeadlineCronActive)
+		feeDebt := big.NewInt(1e18)
+		st.FeeDebt = feeDebt
+		rt.ReplaceState(st)
+
+		breakdown := actor.getBalanceBreakdown(rt)
+		available, err := st.GetAvailableBalance(rt.Balance())
+		require.NoError(t, err)
+
+		assert.Equal(t, rt.Balance(), breakdown.Balance)
+		assert.Equal(t, available, breakdown.Available)
+		assert.Equal(t, amountLocked, breakdown.LockedFunds)
+		assert.Equal(t, feeDebt, breakdown.FeeDebt)
+		assert.Equal(t, big.Zero(), breakdown.PreCommitDeposits)
+		assert.Equal(t, st.InitialPledge, breakdown.InitialPledge)
+		assert.True(t, breakdown.InitialPledge.GreaterThan(big.Zero()))
+		assert.Equal(t, breakdown.Balance, big.Sum(breakdown.Available, breakdown.LockedFunds, breakdown.FeeDebt,
+			breakdown.PreCommitDeposits, breakdown.InitialPledge))
+		actor.checkState(rt)
+	})
+}
+
 func TestRepayDebts(t *testing.T) {
 	actor := newHarness(t, abi.ChainEpoch(100))
 	builder := builderForHarness(actor).
@@ -3278,6 +4273,236 @@ func TestChangePeerID(t *testing.T) {
 	})
 }
 
+func TestChangeDeadlineAssignment(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+
+	t.Run("successfully change deadline assignment policy", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		assert.Equal(t, miner.DeadlineAssignmentSpread, actor.getInfo(rt).DeadlineAssignment)
+
+		actor.changeDeadlineAssignment(rt, miner.DeadlineAssignmentPackDensely)
+		assert.Equal(t, miner.DeadlineAssignmentPackDensely, actor.getInfo(rt).DeadlineAssignment)
+
+		actor.changeDeadlineAssignment(rt, miner.DeadlineAssignmentSpread)
+		assert.Equal(t, miner.DeadlineAssignmentSpread, actor.getInfo(rt).DeadlineAssignment)
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects unknown policy", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "invalid deadline assignment policy", func() {
+			rt.Call(actor.a.ChangeDeadlineAssignment, &miner.ChangeDeadlineAssignmentParams{NewPolicy: miner.DeadlineAssignmentPackDensely + 1})
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects caller that is not a control address", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetCaller(tutil.NewIDAddr(t, 1234), builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(append(actor.controlAddrs, actor.owner, actor.worker)...)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(actor.a.ChangeDeadlineAssignment, &miner.ChangeDeadlineAssignmentParams{NewPolicy: miner.DeadlineAssignmentPackDensely})
+		})
+		actor.checkState(rt)
+	})
+}
+
+func TestChangeNotificationTarget(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+	target := tutil.NewIDAddr(t, 1500)
+
+	t.Run("successfully set and remove notification target", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		assert.Nil(t, actor.getInfo(rt).NotificationTarget)
+
+		actor.changeNotificationTarget(rt, &target)
+		assert.Equal(t, &target, actor.getInfo(rt).NotificationTarget)
+
+		actor.changeNotificationTarget(rt, nil)
+		assert.Nil(t, actor.getInfo(rt).NotificationTarget)
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects non-ID address", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		nonID := tutil.NewBLSAddr(t, 1)
+
+		rt.SetCaller(actor.owner, builtin.AccountActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "must be an ID address", func() {
+			rt.Call(actor.a.ChangeNotificationTarget, &miner.ChangeNotificationTargetParams{NewTarget: &nonID})
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects caller other than owner", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(actor.owner)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(actor.a.ChangeNotificationTarget, &miner.ChangeNotificationTargetParams{NewTarget: &target})
+		})
+		actor.checkState(rt)
+	})
+
+	for _, targetExit := range []exitcode.ExitCode{exitcode.Ok, exitcode.SysErrInvalidMethod} {
+		t.Run(fmt.Sprintf("notifies target of expired pre-commits when target exits %v", targetExit), func(t *testing.T) {
+			rt := builder.Build(t)
+			actor.constructAndVerify(rt)
+			actor.changeNotificationTarget(rt, &target)
+
+			cronCtrl := newCronControl(rt, actor)
+			cleanUpEpoch := cronCtrl.preCommitToStartCron(t, periodOffset+1)
+			st := getState(rt)
+			dlinfo := miner.NewDeadlineInfoFromOffsetAndEpoch(st.ProvingPeriodStart, rt.Epoch())
+			for dlinfo.Open <= cleanUpEpoch {
+				dlinfo = advanceDeadline(rt, actor, &cronConfig{})
+			}
+
+			// The target's failure doesn't prevent cleaning up the pre-commit.
+			rt.SetEpoch(dlinfo.Last())
+			actor.onDeadlineCron(rt, &cronConfig{
+				noEnrollment:            true,
+				expiredPrecommitPenalty: st.PreCommitDeposits,
+				notification: &miner.NotificationParams{
+					Kind:    miner.NotificationPreCommitsExpired,
+					Epoch:   dlinfo.Last(),
+					Sectors: bitfield.NewFromSet([]uint64{0}),
+				},
+				notificationExit: targetExit,
+			})
+			cronCtrl.requireCronInactive(t)
+			actor.checkState(rt)
+		})
+	}
+}
+
+func TestUpgradeWindowPoStProofType(t *testing.T) {
+	// Remove this nasty static/global access when policy is encapsulated in a structure.
+	// See https://github.com/filecoin-project/specs-actors/issues/353.
+	miner.WindowPoStProofTypes[abi.RegisteredPoStProof_StackedDrgWindow2KiBV1] = struct{}{}
+	defer func() {
+		delete(miner.WindowPoStProofTypes, abi.RegisteredPoStProof_StackedDrgWindow2KiBV1)
+	}()
+
+	periodOffset := abi.ChainEpoch(100)
+
+	t.Run("change sector size of miner with no sectors", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		actor.setProofType(abi.RegisteredSealProof_StackedDrg2KiBV1_1)
+		rt := builderForHarness(actor).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		actor.constructAndVerify(rt)
+
+		actor.upgradeWindowPoStProofType(rt, abi.RegisteredPoStProof_StackedDrgWindow32GiBV1)
+
+		info := actor.getInfo(rt)
+		assert.Equal(t, abi.SectorSize(32<<30), info.SectorSize)
+		partitionSectors, err := builtin.PoStProofWindowPoStPartitionSectors(abi.RegisteredPoStProof_StackedDrgWindow32GiBV1)
+		require.NoError(t, err)
+		assert.Equal(t, partitionSectors, info.WindowPoStPartitionSectors)
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects current proof type", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		rt := builderForHarness(actor).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(actor.owner, actor.worker)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "already registered", func() {
+			rt.Call(actor.a.UpgradeWindowPoStProofType, &miner.UpgradeWindowPoStProofTypeParams{NewProofType: actor.windowPostProofType})
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects unsupported proof type", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		rt := builderForHarness(actor).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "unsupported window post proof type", func() {
+			rt.Call(actor.a.UpgradeWindowPoStProofType, &miner.UpgradeWindowPoStProofTypeParams{NewProofType: abi.RegisteredPoStProof_StackedDrgWinning32GiBV1})
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects caller other than owner or worker", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		actor.setProofType(abi.RegisteredSealProof_StackedDrg2KiBV1_1)
+		rt := builderForHarness(actor).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetCaller(tutil.NewIDAddr(t, 1234), builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(actor.owner, actor.worker)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(actor.a.UpgradeWindowPoStProofType, &miner.UpgradeWindowPoStProofTypeParams{NewProofType: abi.RegisteredPoStProof_StackedDrgWindow32GiBV1})
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects sector size change with pre-committed sectors", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		rt := builderForHarness(actor).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetEpoch(periodOffset + 1)
+		expiration := actor.deadline(rt).PeriodEnd() + defaultSectorExpiration*miner.WPoStProvingPeriod
+		actor.preCommitSector(rt, actor.makePreCommit(100, rt.Epoch()-1, expiration, nil), preCommitConf{}, true)
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(actor.owner, actor.worker)
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "pre-committed sectors", func() {
+			rt.Call(actor.a.UpgradeWindowPoStProofType, &miner.UpgradeWindowPoStProofTypeParams{NewProofType: abi.RegisteredPoStProof_StackedDrgWindow64GiBV1})
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects sector size change with sectors", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		rt := builderForHarness(actor).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetEpoch(periodOffset + 1)
+		actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, nil, true)
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(actor.owner, actor.worker)
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "with partitions in deadline", func() {
+			rt.Call(actor.a.UpgradeWindowPoStProofType, &miner.UpgradeWindowPoStProofTypeParams{NewProofType: abi.RegisteredPoStProof_StackedDrgWindow64GiBV1})
+		})
+		actor.checkState(rt)
+	})
+}
+
 func TestCompactPartitions(t *testing.T) {
 	periodOffset := abi.ChainEpoch(100)
 	actor := newHarness(t, periodOffset)
@@ -3531,10 +4756,7 @@ func TestChangeMultiAddrs(t *testing.T) {
 		rt := builder.Build(t)
 		actor.constructAndVerify(rt)
 
-		addr1 := abi.Multiaddrs([]byte("addr1"))
-		addr2 := abi.Multiaddrs([]byte("addr2"))
-
-		actor.changeMultiAddrs(rt, []abi.Multiaddrs{addr1, addr2})
+		actor.changeMultiAddrs(rt, []abi.Multiaddrs{testIP4Multiaddr, testDNS4Multiaddr})
 		actor.checkState(rt)
 	})
 
@@ -3885,6 +5107,85 @@ func TestConfirmUpdateWorkerKey(t *testing.T) {
 	})
 }
 
+func TestCancelWorkerKeyChange(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	newWorker := tutil.NewIDAddr(t, 999)
+	currentEpoch := abi.ChainEpoch(5)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+
+	t.Run("cancels a pending change before the effective date", func(t *testing.T) {
+		rt := builder.Build(t)
+		rt.SetEpoch(currentEpoch)
+		actor.constructAndVerify(rt)
+
+		effectiveEpoch := currentEpoch + miner.WorkerKeyChangeDelay
+		actor.changeWorkerAddress(rt, newWorker, effectiveEpoch, actor.controlAddrs)
+
+		rt.SetEpoch(effectiveEpoch - 1)
+		actor.cancelWorkerKeyChange(rt)
+
+		info := actor.getInfo(rt)
+		require.Nil(t, info.PendingWorkerKey)
+
+		// Confirming at the effective epoch leaves the worker unchanged.
+		rt.SetEpoch(effectiveEpoch)
+		actor.confirmUpdateWorkerKey(rt)
+		info = actor.getInfo(rt)
+		require.Equal(t, actor.worker, info.Worker)
+
+		// A new change may be proposed.
+		actor.changeWorkerAddress(rt, newWorker, effectiveEpoch+miner.WorkerKeyChangeDelay, actor.controlAddrs)
+		info = actor.getInfo(rt)
+		require.NotNil(t, info.PendingWorkerKey)
+		actor.checkState(rt)
+	})
+
+	t.Run("fails when no change is pending", func(t *testing.T) {
+		rt := builder.Build(t)
+		rt.SetEpoch(currentEpoch)
+		actor.constructAndVerify(rt)
+
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.cancelWorkerKeyChange(rt)
+		})
+	})
+
+	t.Run("fails once the change is effective", func(t *testing.T) {
+		rt := builder.Build(t)
+		rt.SetEpoch(currentEpoch)
+		actor.constructAndVerify(rt)
+
+		effectiveEpoch := currentEpoch + miner.WorkerKeyChangeDelay
+		actor.changeWorkerAddress(rt, newWorker, effectiveEpoch, actor.controlAddrs)
+
+		rt.SetEpoch(effectiveEpoch)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.cancelWorkerKeyChange(rt)
+		})
+		info := actor.getInfo(rt)
+		require.NotNil(t, info.PendingWorkerKey)
+	})
+
+	t.Run("only owner can cancel", func(t *testing.T) {
+		rt := builder.Build(t)
+		rt.SetEpoch(currentEpoch)
+		actor.constructAndVerify(rt)
+
+		effectiveEpoch := currentEpoch + miner.WorkerKeyChangeDelay
+		actor.changeWorkerAddress(rt, newWorker, effectiveEpoch, actor.controlAddrs)
+
+		rt.ExpectValidateCallerAddr(actor.owner)
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(actor.a.CancelWorkerKeyChange, nil)
+		})
+		info := actor.getInfo(rt)
+		require.NotNil(t, info.PendingWorkerKey)
+	})
+}
+
 func TestChangeOwnerAddress(t *testing.T) {
 	actor := newHarness(t, 0)
 	builder := builderForHarness(actor).
@@ -4039,6 +5340,93 @@ func TestChangeOwnerAddress(t *testing.T) {
 	})
 }
 
+func TestChangeAddresses(t *testing.T) {
+	actor := newHarness(t, 0)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+	newOwner := tutil.NewIDAddr(t, 1001)
+	newWorker := tutil.NewIDAddr(t, 1002)
+	newControl := tutil.NewIDAddr(t, 1003)
+
+	t.Run("proposes owner and schedules worker in one message", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		rt.SetEpoch(abi.ChainEpoch(5))
+
+		rt.SetCaller(actor.owner, builtin.MultisigActorCodeID)
+		actor.changeAddresses(rt, newOwner, newWorker, []addr.Address{newControl})
+
+		info := actor.getInfo(rt)
+		assert.Equal(t, actor.owner, info.Owner)
+		assert.Equal(t, newOwner, *info.PendingOwnerAddress)
+		assert.Equal(t, actor.worker, info.Worker)
+		require.NotNil(t, info.PendingWorkerKey)
+		assert.Equal(t, newWorker, info.PendingWorkerKey.NewWorker)
+		assert.Equal(t, rt.Epoch()+miner.WorkerKeyChangeDelay, info.PendingWorkerKey.EffectiveAt)
+		assert.Equal(t, []addr.Address{newControl}, info.ControlAddresses)
+
+		// The new owner confirms, then enacts the worker change once it is effective.
+		rt.SetCaller(newOwner, builtin.MultisigActorCodeID)
+		actor.changeOwnerAddress(rt, newOwner)
+
+		rt.SetEpoch(info.PendingWorkerKey.EffectiveAt)
+		rt.ExpectValidateCallerAddr(newOwner)
+		rt.Call(actor.a.ConfirmUpdateWorkerKey, nil)
+		rt.Verify()
+
+		info = actor.getInfo(rt)
+		assert.Equal(t, newOwner, info.Owner)
+		assert.Nil(t, info.PendingOwnerAddress)
+		assert.Equal(t, newWorker, info.Worker)
+		assert.Nil(t, info.PendingWorkerKey)
+		actor.checkState(rt)
+	})
+
+	t.Run("keeping the current owner and worker changes only control addresses", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetCaller(actor.owner, builtin.MultisigActorCodeID)
+		actor.changeAddresses(rt, actor.owner, actor.worker, nil)
+
+		info := actor.getInfo(rt)
+		assert.Nil(t, info.PendingOwnerAddress)
+		assert.Nil(t, info.PendingWorkerKey)
+		assert.Empty(t, info.ControlAddresses)
+		actor.checkState(rt)
+	})
+
+	t.Run("owner must be an ID address", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetCaller(actor.owner, builtin.MultisigActorCodeID)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(actor.a.ChangeAddresses, &miner.ChangeAddressesParams{
+				NewOwner:  tutil.NewSECP256K1Addr(t, "asd"),
+				NewWorker: actor.worker,
+			})
+		})
+		info := actor.getInfo(rt)
+		assert.Nil(t, info.PendingOwnerAddress)
+	})
+
+	t.Run("only owner can change addresses", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			actor.changeAddresses(rt, newOwner, newWorker, nil)
+		})
+
+		info := actor.getInfo(rt)
+		assert.Nil(t, info.PendingOwnerAddress)
+		assert.Nil(t, info.PendingWorkerKey)
+		assert.Equal(t, actor.controlAddrs, info.ControlAddresses)
+	})
+}
+
 func TestReportConsensusFault(t *testing.T) {
 	periodOffset := abi.ChainEpoch(100)
 	actor := newHarness(t, periodOffset)
@@ -4172,6 +5560,136 @@ func TestReportConsensusFault(t *testing.T) {
 		})
 		actor.checkState(rt)
 	})
+
+	t.Run("Report of multiple consensus faults pays reward and charges fee once", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		reportEpoch := abi.ChainEpoch(333)
+		rt.SetEpoch(reportEpoch)
+
+		actor.reportConsensusFaults(rt, addr.TestAddress, &runtime.ConsensusFault{
+			Target: actor.receiver,
+			Epoch:  rt.Epoch() - 2,
+			Type:   runtime.ConsensusFaultDoubleForkMining,
+		}, &runtime.ConsensusFault{
+			Target: actor.receiver,
+			Epoch:  rt.Epoch() - 1,
+			Type:   runtime.ConsensusFaultDoubleForkMining,
+		}, &runtime.ConsensusFault{
+			Target: actor.receiver,
+			Epoch:  rt.Epoch() - 1,
+			Type:   runtime.ConsensusFaultTimeOffsetMining,
+		})
+		endInfo := actor.getInfo(rt)
+		assert.Equal(t, reportEpoch+miner.ConsensusFaultIneligibilityDuration, endInfo.ConsensusFaultElapsed)
+		actor.checkState(rt)
+	})
+
+	t.Run("report of multiple consensus faults charges the same as reporting them in turn", func(t *testing.T) {
+		reportEpoch := abi.ChainEpoch(333)
+		fault1 := &runtime.ConsensusFault{
+			Target: actor.receiver,
+			Epoch:  reportEpoch - 2,
+			Type:   runtime.ConsensusFaultDoubleForkMining,
+		}
+		fault2 := &runtime.ConsensusFault{
+			Target: actor.receiver,
+			Epoch:  reportEpoch - 1,
+			Type:   runtime.ConsensusFaultDoubleForkMining,
+		}
+
+		// report faults one at a time
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		rt.SetEpoch(reportEpoch)
+		startBalance := rt.Balance()
+
+		actor.reportConsensusFault(rt, addr.TestAddress, fault1)
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "too old", func() {
+			actor.reportConsensusFault(rt, addr.TestAddress, fault2)
+		})
+		rt.Reset()
+		sequentialPenalty := big.Sub(startBalance, rt.Balance())
+		sequentialInfo := actor.getInfo(rt)
+		actor.checkState(rt)
+
+		// report the same faults together
+		rt = builder.Build(t)
+		actor.constructAndVerify(rt)
+		rt.SetEpoch(reportEpoch)
+		startBalance = rt.Balance()
+
+		actor.reportConsensusFaults(rt, addr.TestAddress, fault1, fault2)
+		batchPenalty := big.Sub(startBalance, rt.Balance())
+
+		assert.True(t, sequentialPenalty.GreaterThan(big.Zero()))
+		assert.Equal(t, sequentialPenalty, batchPenalty)
+		assert.Equal(t, sequentialInfo.ConsensusFaultElapsed, actor.getInfo(rt).ConsensusFaultElapsed)
+		actor.checkState(rt)
+	})
+
+	t.Run("empty report of consensus faults rejected", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		rt.SetEpoch(abi.ChainEpoch(1))
+
+		rt.SetCaller(addr.TestAddress, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "batch empty", func() {
+			rt.Call(actor.a.ReportConsensusFaults, &miner.ReportConsensusFaultsParams{})
+		})
+		rt.Reset()
+		actor.checkState(rt)
+	})
+
+	t.Run("duplicate fault in report rejected", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		rt.SetEpoch(abi.ChainEpoch(333))
+
+		fault := &runtime.ConsensusFault{
+			Target: actor.receiver,
+			Epoch:  rt.Epoch() - 1,
+			Type:   runtime.ConsensusFaultDoubleForkMining,
+		}
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "duplicate fault", func() {
+			actor.reportConsensusFaults(rt, addr.TestAddress, fault, fault)
+		})
+		rt.Reset()
+		actor.checkState(rt)
+	})
+
+	t.Run("report fails if any fault is too old", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		reportEpoch := abi.ChainEpoch(333)
+		rt.SetEpoch(reportEpoch)
+
+		fault1 := rt.Epoch() - 1
+		actor.reportConsensusFault(rt, addr.TestAddress, &runtime.ConsensusFault{
+			Target: actor.receiver,
+			Epoch:  fault1,
+			Type:   runtime.ConsensusFaultDoubleForkMining,
+		})
+		endInfo := actor.getInfo(rt)
+
+		// a fault after the exclusion period cannot be reported alongside one within it
+		rt.SetEpoch(endInfo.ConsensusFaultElapsed + 1)
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "too old", func() {
+			actor.reportConsensusFaults(rt, addr.TestAddress, &runtime.ConsensusFault{
+				Target: actor.receiver,
+				Epoch:  endInfo.ConsensusFaultElapsed,
+				Type:   runtime.ConsensusFaultDoubleForkMining,
+			}, &runtime.ConsensusFault{
+				Target: actor.receiver,
+				Epoch:  fault1 + 1,
+				Type:   runtime.ConsensusFaultDoubleForkMining,
+			})
+		})
+		rt.Reset()
+		assert.Equal(t, endInfo.ConsensusFaultElapsed, actor.getInfo(rt).ConsensusFaultElapsed)
+		actor.checkState(rt)
+	})
 }
 
 func TestApplyRewards(t *testing.T) {
@@ -4347,19 +5865,93 @@ func TestApplyRewards(t *testing.T) {
 		// Set balance to deduct fee
 		finalBalance := big.Sub(newBalance, expectBurnt)
 
-		st = getState(rt)
-		// balance funds used to pay off fee debt
-		// available balance should be 2
-		availableBalance, err := st.GetAvailableBalance(finalBalance)
-		require.NoError(t, err)
-		assert.Equal(t, big.Sum(availableBefore, reward, initFeeDebt.Neg(), remainingLocked.Neg()), availableBalance)
-		assert.True(t, st.IsDebtFree())
-		// remaining funds locked in vesting table
-		assert.Equal(t, remainingLocked, st.LockedFunds)
-		// technically applying rewards without first activating cron is an impossible state but convenient for testing
-		_, msgs := miner.CheckStateInvariants(st, rt.AdtStore(), rt.Balance())
-		assert.Equal(t, 1, len(msgs.Messages()))
-		assert.Contains(t, msgs.Messages()[0], "DeadlineCronActive == false")
+		st = getState(rt)
+		// balance funds used to pay off fee debt
+		// available balance should be 2
+		availableBalance, err := st.GetAvailableBalance(finalBalance)
+		require.NoError(t, err)
+		assert.Equal(t, big.Sum(availableBefore, reward, initFeeDebt.Neg(), remainingLocked.Neg()), availableBalance)
+		assert.True(t, st.IsDebtFree())
+		// remaining funds locked in vesting table
+		assert.Equal(t, remainingLocked, st.LockedFunds)
+		// technically applying rewards without first activating cron is an impossible state but convenient for testing
+		_, msgs := miner.CheckStateInvariants(st, rt.AdtStore(), rt.Balance())
+		assert.Equal(t, 1, len(msgs.Messages()))
+		assert.Contains(t, msgs.Messages()[0], "DeadlineCronActive == false")
+	})
+}
+
+func TestLockFunds(t *testing.T) {
+	periodOffset := abi.ChainEpoch(1808)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+	funder := tutil.NewIDAddr(t, 1234)
+	spec := miner.VestSpec{
+		InitialDelay: 10 * builtin.EpochsInDay,
+		VestPeriod:   30 * builtin.EpochsInDay,
+		StepDuration: builtin.EpochsInDay,
+		Quantization: miner.RewardVestingSpec.Quantization,
+	}
+
+	t.Run("funds from any party are locked by the given schedule", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		amount := abi.NewTokenAmount(3_000_000)
+		actor.lockFunds(rt, funder, amount, spec, amount)
+		st := getState(rt)
+		assert.Equal(t, amount, st.LockedFunds)
+
+		vestingFunds, err := st.LoadVestingFunds(adt.AsStore(rt))
+		require.NoError(t, err)
+		require.Len(t, vestingFunds.Funds, 30)
+		quantSpec := builtin.NewQuantSpec(spec.Quantization, periodOffset)
+		assert.Equal(t, quantSpec.QuantizeUp(rt.Epoch()+spec.InitialDelay+spec.StepDuration), vestingFunds.Funds[0].Epoch)
+		total := big.Zero()
+		for _, vf := range vestingFunds.Funds {
+			total = big.Add(total, vf.Amount)
+		}
+		assert.Equal(t, amount, total)
+
+		// Nothing vests before the initial delay has elapsed.
+		vested, err := st.CheckVestedFunds(adt.AsStore(rt), rt.Epoch()+spec.InitialDelay)
+		require.NoError(t, err)
+		assert.Equal(t, big.Zero(), vested)
+		vested, err = st.CheckVestedFunds(adt.AsStore(rt), vestingFunds.Funds[29].Epoch+1)
+		require.NoError(t, err)
+		assert.Equal(t, amount, vested)
+	})
+
+	t.Run("rejects no funds", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetCaller(funder, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "no funds to lock", func() {
+			rt.Call(actor.a.LockFunds, &miner.LockFundsParams{VestSpec: spec})
+		})
+	})
+
+	t.Run("rejects invalid schedules", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		for _, invalid := range []miner.VestSpec{
+			{InitialDelay: -1, VestPeriod: spec.VestPeriod, StepDuration: spec.StepDuration, Quantization: spec.Quantization},
+			{InitialDelay: miner.MaxLockFundsVestDuration, VestPeriod: 1, StepDuration: spec.StepDuration, Quantization: spec.Quantization},
+			{InitialDelay: spec.InitialDelay, VestPeriod: spec.VestPeriod, StepDuration: spec.StepDuration, Quantization: 0},
+			{InitialDelay: spec.InitialDelay, VestPeriod: spec.VestPeriod, StepDuration: spec.StepDuration, Quantization: spec.Quantization + 1},
+			{InitialDelay: spec.InitialDelay, VestPeriod: spec.VestPeriod, StepDuration: spec.Quantization - 1, Quantization: spec.Quantization},
+		} {
+			rt.SetCaller(funder, builtin.AccountActorCodeID)
+			rt.SetReceived(abi.NewTokenAmount(1_000))
+			rt.ExpectValidateCallerAny()
+			rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "invalid vesting schedule", func() {
+				rt.Call(actor.a.LockFunds, &miner.LockFundsParams{VestSpec: invalid})
+			})
+		}
 	})
 }
 
@@ -4452,6 +6044,47 @@ func TestCompactSectorNumbers(t *testing.T) {
 		actor.checkState(rt)
 	})
 
+	t.Run("pre-commit compacts sparse sector number allocation", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		// Allocate every other sector number, so the allocation encoding exceeds the compaction threshold.
+		sparse := make([]uint64, 4*miner.AllocatedSectorsCompactionThreshold+64)
+		for i := range sparse {
+			sparse[i] = uint64(2 * i)
+		}
+		actor.compactSectorNumbers(rt, bitfield.NewFromSet(sparse))
+
+		precommitEpoch := rt.Epoch()
+		deadline := actor.deadline(rt)
+		expiration := deadline.PeriodEnd() + abi.ChainEpoch(defaultSectorExpiration)*miner.WPoStProvingPeriod
+		sectorNo := abi.SectorNumber(2*len(sparse) + 1)
+		actor.preCommitSector(rt, actor.makePreCommit(sectorNo, precommitEpoch-1, expiration, nil), preCommitConf{}, true)
+
+		// The lowest gaps have been masked.
+		st := getState(rt)
+		var allocated bitfield.BitField
+		require.NoError(t, rt.AdtStore().Get(rt.Context(), st.AllocatedSectors, &allocated))
+		maskedEnd := uint64(2 * miner.AllocatedSectorsCompactionMaxGaps)
+		for _, sno := range []uint64{1, maskedEnd - 1} {
+			set, err := allocated.IsSet(sno)
+			require.NoError(t, err)
+			assert.True(t, set, "sector number %d", sno)
+		}
+		set, err := allocated.IsSet(maskedEnd + 1)
+		require.NoError(t, err)
+		assert.False(t, set)
+		count, err := allocated.Count()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(len(sparse)+1+miner.AllocatedSectorsCompactionMaxGaps), count)
+
+		// A masked sector number cannot be pre-committed.
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.preCommitSector(rt, actor.makePreCommit(1, precommitEpoch-1, expiration, nil), preCommitConf{}, false)
+		})
+		actor.checkState(rt)
+	})
+
 	t.Run("sector number range limits", func(t *testing.T) {
 		rt := builder.Build(t)
 		actor.constructAndVerify(rt)
@@ -4765,6 +6398,13 @@ func (h *actorHarness) confirmUpdateWorkerKey(rt *mock.Runtime) {
 	rt.Verify()
 }
 
+func (h *actorHarness) cancelWorkerKeyChange(rt *mock.Runtime) {
+	rt.ExpectValidateCallerAddr(h.owner)
+	rt.SetCaller(h.owner, builtin.AccountActorCodeID)
+	rt.Call(h.a.CancelWorkerKeyChange, nil)
+	rt.Verify()
+}
+
 func (h *actorHarness) changeOwnerAddress(rt *mock.Runtime, newAddr addr.Address) {
 	if rt.Caller() == h.owner {
 		rt.ExpectValidateCallerAddr(h.owner)
@@ -4780,6 +6420,23 @@ func (h *actorHarness) changeOwnerAddress(rt *mock.Runtime, newAddr addr.Address
 	rt.Verify()
 }
 
+func (h *actorHarness) changeAddresses(rt *mock.Runtime, newOwner, newWorker addr.Address, newControlAddrs []addr.Address) {
+	rt.SetAddressActorType(newWorker, builtin.AccountActorCodeID)
+	rt.ExpectSend(newWorker, builtin.MethodsAccount.PubkeyAddress, nil, big.Zero(), &h.key, exitcode.Ok)
+	for _, ca := range newControlAddrs {
+		rt.SetAddressActorType(ca, builtin.AccountActorCodeID)
+	}
+
+	params := &miner.ChangeAddressesParams{
+		NewOwner:        newOwner,
+		NewWorker:       newWorker,
+		NewControlAddrs: newControlAddrs,
+	}
+	rt.ExpectValidateCallerAddr(h.owner)
+	rt.Call(h.a.ChangeAddresses, params)
+	rt.Verify()
+}
+
 func (h *actorHarness) checkSectorProven(rt *mock.Runtime, sectorNum abi.SectorNumber) {
 	param := &miner.CheckSectorProvenParams{SectorNumber: sectorNum}
 
@@ -4817,6 +6474,57 @@ func (h *actorHarness) changePeerID(rt *mock.Runtime, newPID abi.PeerID) {
 	require.EqualValues(h.t, newPID, info.PeerId)
 }
 
+func (h *actorHarness) changeNotificationTarget(rt *mock.Runtime, target *addr.Address) {
+	rt.ExpectValidateCallerAddr(h.owner)
+	rt.SetCaller(h.owner, builtin.AccountActorCodeID)
+
+	rt.Call(h.a.ChangeNotificationTarget, &miner.ChangeNotificationTargetParams{NewTarget: target})
+	rt.Verify()
+}
+
+func (h *actorHarness) changeDeadlineAssignment(rt *mock.Runtime, policy miner.DeadlineAssignmentPolicy) {
+	rt.ExpectValidateCallerAddr(append(h.controlAddrs, h.owner, h.worker)...)
+	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
+
+	rt.Call(h.a.ChangeDeadlineAssignment, &miner.ChangeDeadlineAssignmentParams{NewPolicy: policy})
+	rt.Verify()
+}
+
+// Attests the pieces of a pre-committed sector, returning the updated pre-commitment.
+func (h *actorHarness) attestSectorPieces(rt *mock.Runtime, sectorNo abi.SectorNumber, pieces []abi.PieceInfo) *miner.SectorPreCommitOnChainInfo {
+	rt.ExpectValidateCallerAddr(append(h.controlAddrs, h.owner, h.worker)...)
+	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
+	rt.ExpectComputeUnsealedSectorCID(h.sealProofType, pieces, tutil.MakeCID("commd", &market.PieceCIDPrefix), nil)
+
+	rt.Call(h.a.AttestSectorPieces, makeAttestSectorPieces(sectorNo, pieces))
+	rt.Verify()
+
+	precommit := h.getPreCommit(rt, sectorNo)
+	require.Equal(h.t, pieces, precommit.Pieces)
+	return precommit
+}
+
+func (h *actorHarness) designatePreCommitAggregator(rt *mock.Runtime, aggregator addr.Address, sectorNos bitfield.BitField) {
+	rt.ExpectValidateCallerAddr(append(h.controlAddrs, h.owner, h.worker)...)
+	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
+	rt.Call(h.a.DesignatePreCommitAggregator, &miner.DesignatePreCommitAggregatorParams{
+		SectorNumbers: sectorNos,
+		Aggregator:    aggregator,
+	})
+	rt.Verify()
+}
+
+func (h *actorHarness) upgradeWindowPoStProofType(rt *mock.Runtime, newProofType abi.RegisteredPoStProof) {
+	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(h.owner, h.worker)
+	rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdateClaimedProofType,
+		&power.UpdateClaimedProofTypeParams{WindowPoStProofType: newProofType}, big.Zero(), nil, exitcode.Ok)
+
+	rt.Call(h.a.UpgradeWindowPoStProofType, &miner.UpgradeWindowPoStProofTypeParams{NewProofType: newProofType})
+	rt.Verify()
+	require.Equal(h.t, newProofType, h.getInfo(rt).WindowPoStProofType)
+}
+
 func (h *actorHarness) controlAddresses(rt *mock.Runtime) (owner, worker addr.Address, control []addr.Address) {
 	rt.ExpectValidateCallerAny()
 	ret := rt.Call(h.a.ControlAddresses, nil).(*miner.GetControlAddressesReturn)
@@ -4841,6 +6549,7 @@ func (h *actorHarness) preCommitSector(rt *mock.Runtime, params *miner.PreCommit
 		expectQueryNetworkInfo(rt, h)
 	}
 	if len(params.DealIDs) > 0 {
+		expectDealEndEpochs(rt, *params)
 		vdParams := market.VerifyDealsForActivationParams{
 			Sectors: []market.SectorDeals{{
 				SectorExpiry: params.Expiration,
@@ -4924,6 +6633,7 @@ func (h *actorHarness) preCommitSectorBatch(rt *mock.Runtime, params *miner.PreC
 		anyDeals = anyDeals || sectorHasDeals
 	}
 	if anyDeals {
+		expectDealEndEpochs(rt, params.Sectors...)
 		vdParams := market.VerifyDealsForActivationParams{
 			Sectors: sectorDeals,
 		}
@@ -4952,10 +6662,24 @@ func (h *actorHarness) preCommitSectorBatch(rt *mock.Runtime, params *miner.PreC
 	return precommits
 }
 
+// Expects the deal end epochs query for pre-committed sectors, returning each deal as ending at its sector's expiration.
+func expectDealEndEpochs(rt *mock.Runtime, precommits ...miner0.SectorPreCommitInfo) {
+	var params market.DealEndEpochsParams
+	var ret market.DealEndEpochsReturn
+	for _, precommit := range precommits {
+		for _, dealID := range precommit.DealIDs {
+			params.DealIDs = append(params.DealIDs, dealID)
+			ret.Deals = append(ret.Deals, market.DealEndEpoch{DealID: dealID, EndEpoch: precommit.Expiration})
+		}
+	}
+	rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.DealEndEpochs, &params, big.Zero(), &ret, exitcode.Ok)
+}
+
 // Options for proveCommitSector behaviour.
 // Default zero values should let everything be ok.
 type proveCommitConf struct {
 	verifyDealsExit map[abi.SectorNumber]exitcode.ExitCode
+	caller          addr.Address // Caller of ProveCommitAggregate, if not the worker
 }
 
 func (h *actorHarness) proveCommitSector(rt *mock.Runtime, precommit *miner.SectorPreCommitOnChainInfo, params *miner.ProveCommitSectorParams) {
@@ -4965,7 +6689,9 @@ func (h *actorHarness) proveCommitSector(rt *mock.Runtime, precommit *miner.Sect
 	interactiveEpoch := precommit.PreCommitEpoch + miner.PreCommitChallengeDelay
 
 	// Prepare for and receive call to ProveCommitSector
-	{
+	if precommit.Pieces != nil {
+		rt.ExpectComputeUnsealedSectorCID(precommit.Info.SealProof, precommit.Pieces, cid.Cid(commd), nil)
+	} else {
 		inputs := []*market.SectorDataSpec{
 			{
 				DealIDs:    precommit.Info.DealIDs,
@@ -5011,23 +6737,31 @@ func (h *actorHarness) proveCommitSector(rt *mock.Runtime, precommit *miner.Sect
 }
 
 func (h *actorHarness) proveCommitAggregateSector(rt *mock.Runtime, conf proveCommitConf, precommits []*miner.SectorPreCommitOnChainInfo, params *miner.ProveCommitAggregateParams) {
-	// Receive call to ComputeDataCommittments
+	// Receive call to ComputeDataCommittments, and compute data commitments of sectors with attested pieces
 	commDs := make([]cbg.CborCid, len(precommits))
 	{
-		cdcInputs := make([]*market.SectorDataSpec, len(precommits))
+		var cdcInputs []*market.SectorDataSpec
+		var cdcCommDs []cbg.CborCid
 		for i, precommit := range precommits {
-			cdcInputs[i] = &market.SectorDataSpec{
-				DealIDs:    precommit.Info.DealIDs,
-				SectorType: precommit.Info.SealProof,
-			}
 			commD := cbg.CborCid(tutil.MakeCID(fmt.Sprintf("commd-%d", i), &market.PieceCIDPrefix))
 			commDs[i] = commD
+			if precommit.Pieces != nil {
+				rt.ExpectComputeUnsealedSectorCID(precommit.Info.SealProof, precommit.Pieces, cid.Cid(commD), nil)
+				continue
+			}
+			cdcInputs = append(cdcInputs, &market.SectorDataSpec{
+				DealIDs:    precommit.Info.DealIDs,
+				SectorType: precommit.Info.SealProof,
+			})
+			cdcCommDs = append(cdcCommDs, commD)
 		}
-		cdcParams := market.ComputeDataCommitmentParams{Inputs: cdcInputs}
-		cdcRet := market.ComputeDataCommitmentReturn{
-			CommDs: commDs,
+		if len(cdcInputs) > 0 {
+			cdcParams := market.ComputeDataCommitmentParams{Inputs: cdcInputs}
+			cdcRet := market.ComputeDataCommitmentReturn{
+				CommDs: cdcCommDs,
+			}
+			rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.ComputeDataCommitment, &cdcParams, big.Zero(), &cdcRet, exitcode.Ok)
 		}
-		rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.ComputeDataCommitment, &cdcParams, big.Zero(), &cdcRet, exitcode.Ok)
 	}
 	// Expect randomness queries for provided precommits
 	var sealRands []abi.SealRandomness
@@ -5072,9 +6806,7 @@ func (h *actorHarness) proveCommitAggregateSector(rt *mock.Runtime, conf proveCo
 	}
 
 	// confirmSectorProofsValid
-	{
-		h.confirmSectorProofsValidInternal(rt, conf, precommits...)
-	}
+	expected := h.confirmSectorProofsValidInternal(rt, conf, precommits...)
 
 	// burn networkFee
 	{
@@ -5082,16 +6814,27 @@ func (h *actorHarness) proveCommitAggregateSector(rt *mock.Runtime, conf proveCo
 		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, expectedFee, nil, exitcode.Ok)
 	}
 
-	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
+	caller := h.worker
+	if conf.caller != addr.Undef {
+		caller = conf.caller
+	}
+	rt.SetCaller(caller, builtin.AccountActorCodeID)
 	rt.ExpectValidateCallerAny()
-	rt.Call(h.a.ProveCommitAggregate, params)
+	ret := rt.Call(h.a.ProveCommitAggregate, params).(*miner.ConfirmSectorProofsValidReturn)
 	rt.Verify()
+	assertConfirmSectorProofsValidReturn(h.t, expected, ret)
 }
 
-func (h *actorHarness) confirmSectorProofsValidInternal(rt *mock.Runtime, conf proveCommitConf, precommits ...*miner.SectorPreCommitOnChainInfo) {
+// Sets expectations for the confirmation of proofs for pre-committed sectors, returning the expected result.
+func (h *actorHarness) confirmSectorProofsValidInternal(rt *mock.Runtime, conf proveCommitConf, precommits ...*miner.SectorPreCommitOnChainInfo) *miner.ConfirmSectorProofsValidReturn {
 	// expect calls to get network stats
 	expectQueryNetworkInfo(rt, h)
 
+	expected := &miner.ConfirmSectorProofsValidReturn{
+		Activated:     bitfield.New(),
+		InitialPledge: big.Zero(),
+	}
+
 	// Prepare for and receive call to ConfirmSectorProofsValid.
 	var validPrecommits []*miner.SectorPreCommitOnChainInfo
 	for _, precommit := range precommits {
@@ -5104,6 +6847,10 @@ func (h *actorHarness) confirmSectorProofsValidInternal(rt *mock.Runtime, conf p
 			exit, found := conf.verifyDealsExit[precommit.Info.SectorNumber]
 			if found {
 				validPrecommits = validPrecommits[:len(validPrecommits)-1] // pop
+				expected.Failed = append(expected.Failed, miner.FailedSectorActivation{
+					SectorNumber: precommit.Info.SectorNumber,
+					Reason:       miner.SectorActivationDealsInvalid,
+				})
 			} else {
 				exit = exitcode.Ok
 			}
@@ -5135,25 +6882,47 @@ func (h *actorHarness) confirmSectorProofsValidInternal(rt *mock.Runtime, conf p
 				}
 
 				expectPledge = big.Add(expectPledge, pledge)
+				expected.Activated.Set(uint64(precommit.Info.SectorNumber))
+			} else {
+				expected.Failed = append(expected.Failed, miner.FailedSectorActivation{
+					SectorNumber: precommit.Info.SectorNumber,
+					Reason:       miner.SectorActivationLifetimeTooShort,
+				})
 			}
 		}
 
 		if !expectPledge.IsZero() {
 			rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdatePledgeTotal, &expectPledge, big.Zero(), nil, exitcode.Ok)
 		}
+		expected.InitialPledge = expectPledge
 	}
+	sort.Slice(expected.Failed, func(i, j int) bool {
+		return expected.Failed[i].SectorNumber < expected.Failed[j].SectorNumber
+	})
+	return expected
+}
+
+func assertConfirmSectorProofsValidReturn(t testing.TB, expected, actual *miner.ConfirmSectorProofsValidReturn) {
+	expectedActivated, err := expected.Activated.All(miner.AddressedSectorsMax)
+	require.NoError(t, err)
+	actualActivated, err := actual.Activated.All(miner.AddressedSectorsMax)
+	require.NoError(t, err)
+	assert.Equal(t, expectedActivated, actualActivated)
+	assert.Equal(t, expected.InitialPledge, actual.InitialPledge)
+	assert.Equal(t, expected.Failed, actual.Failed)
 }
 
 func (h *actorHarness) confirmSectorProofsValid(rt *mock.Runtime, conf proveCommitConf, precommits ...*miner.SectorPreCommitOnChainInfo) {
-	h.confirmSectorProofsValidInternal(rt, conf, precommits...)
+	expected := h.confirmSectorProofsValidInternal(rt, conf, precommits...)
 	var allSectorNumbers []abi.SectorNumber
 	for _, precommit := range precommits {
 		allSectorNumbers = append(allSectorNumbers, precommit.Info.SectorNumber)
 	}
 	rt.SetCaller(builtin.StoragePowerActorAddr, builtin.StoragePowerActorCodeID)
 	rt.ExpectValidateCallerAddr(builtin.StoragePowerActorAddr)
-	rt.Call(h.a.ConfirmSectorProofsValid, &builtin.ConfirmSectorProofsParams{Sectors: allSectorNumbers})
+	ret := rt.Call(h.a.ConfirmSectorProofsValid, &builtin.ConfirmSectorProofsParams{Sectors: allSectorNumbers}).(*miner.ConfirmSectorProofsValidReturn)
 	rt.Verify()
+	assertConfirmSectorProofsValidReturn(h.t, expected, ret)
 }
 
 func (h *actorHarness) proveCommitSectorAndConfirm(rt *mock.Runtime, precommit *miner.SectorPreCommitOnChainInfo,
@@ -5442,13 +7211,25 @@ func (h *actorHarness) submitWindowPoStRaw(rt *mock.Runtime, deadline *dline.Inf
 	allIgnored := bf()
 	allRecovered := bf()
 	dln := h.getDeadline(rt, deadline.Index)
+	autoRecoveries := make(miner.PartitionSectorMap)
+	if miner.AutomaticFaultRecovery {
+		faultExpiration := deadline.Last() + miner.FaultMaxAge - miner.WPoStProvingPeriod
+		var err error
+		autoRecoveries, err = dln.AutomaticRecoveries(rt.AdtStore(), miner.QuantSpecForDeadline(deadline), faultExpiration, params.Partitions)
+		require.NoError(h.t, err)
+	}
 	for _, p := range params.Partitions {
 		if partition, err := dln.LoadPartition(rt.AdtStore(), p.Index); err == nil {
-			expectedFaults, err := bitfield.SubtractBitField(partition.Faults, partition.Recoveries)
+			recoveries := partition.Recoveries
+			if auto, ok := autoRecoveries[p.Index]; ok {
+				recoveries, err = bitfield.MergeBitFields(recoveries, auto)
+				require.NoError(h.t, err)
+			}
+			expectedFaults, err := bitfield.SubtractBitField(partition.Faults, recoveries)
 			require.NoError(h.t, err)
 			allIgnored, err = bitfield.MultiMerge(allIgnored, expectedFaults, p.Skipped)
 			require.NoError(h.t, err)
-			recovered, err := bitfield.SubtractBitField(partition.Recoveries, p.Skipped)
+			recovered, err := bitfield.SubtractBitField(recoveries, p.Skipped)
 			require.NoError(h.t, err)
 			allRecovered, err = bitfield.MergeBitFields(allRecovered, recovered)
 			require.NoError(h.t, err)
@@ -5552,10 +7333,37 @@ func (h *actorHarness) declareFaults(rt *mock.Runtime, faultSectorInfos ...*mine
 	// Calculate params from faulted sector infos
 	st := getState(rt)
 	params := makeFaultParamsFromFaultingSectors(h.t, st, rt.AdtStore(), faultSectorInfos)
-	rt.Call(h.a.DeclareFaults, params)
+	ret := rt.Call(h.a.DeclareFaults, params).(*miner.DeclareFaultsReturn)
 	rt.Verify()
+	powerDelta := miner.NewPowerPair(claim.RawByteDelta, claim.QualityAdjustedDelta)
+	assert.True(h.t, powerDelta.Equals(ret.PowerDelta))
+	assert.Equal(h.t, big.Zero(), ret.PledgeDelta)
+
+	return powerDelta
+}
+
+func (h *actorHarness) declareFaultsWithReasons(rt *mock.Runtime, params *miner.DeclareFaultsWithReasonsParams, faultSectorInfos ...*miner.SectorOnChainInfo) *miner.DeclareFaultsWithReasonsReturn {
+	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(append(h.controlAddrs, h.owner, h.worker)...)
+
+	powerDelta := miner.PowerForSectors(h.sectorSize, faultSectorInfos).Neg()
+	rt.ExpectSend(
+		builtin.StoragePowerActorAddr,
+		builtin.MethodsPower.UpdateClaimedPower,
+		&power.UpdateClaimedPowerParams{
+			RawByteDelta:         powerDelta.Raw,
+			QualityAdjustedDelta: powerDelta.QA,
+		},
+		abi.NewTokenAmount(0),
+		nil,
+		exitcode.Ok,
+	)
 
-	return miner.NewPowerPair(claim.RawByteDelta, claim.QualityAdjustedDelta)
+	ret := rt.Call(h.a.DeclareFaultsWithReasons, params).(*miner.DeclareFaultsWithReasonsReturn)
+	rt.Verify()
+	assert.True(h.t, powerDelta.Equals(ret.PowerDelta))
+	assert.True(h.t, ret.PledgeDelta.IsZero())
+	return ret
 }
 
 func (h *actorHarness) declareRecoveries(rt *mock.Runtime, deadlineIdx uint64, partitionIdx uint64, recoverySectors bitfield.BitField, expectedDebtRepaid abi.TokenAmount) {
@@ -5573,8 +7381,10 @@ func (h *actorHarness) declareRecoveries(rt *mock.Runtime, deadlineIdx uint64, p
 		Sectors:   recoverySectors,
 	}}}
 
-	rt.Call(h.a.DeclareFaultsRecovered, params)
+	ret := rt.Call(h.a.DeclareFaultsRecovered, params).(*miner.DeclareFaultsRecoveredReturn)
 	rt.Verify()
+	assert.True(h.t, ret.PowerDelta.IsZero())
+	assert.Equal(h.t, big.Zero(), ret.PledgeDelta)
 }
 
 func (h *actorHarness) extendSectors(rt *mock.Runtime, params *miner.ExtendSectorExpirationParams) {
@@ -5682,10 +7492,14 @@ func (h *actorHarness) terminateSectors(rt *mock.Runtime, sectors bitfield.BitFi
 	require.NoError(h.t, err)
 
 	params := &miner.TerminateSectorsParams{Terminations: declarations}
-	rt.Call(h.a.TerminateSectors, params)
+	ret := rt.Call(h.a.TerminateSectors, params).(*miner.TerminateSectorsReturn)
 	rt.Verify()
+	assert.True(h.t, ret.Done)
+	expectedPowerDelta := sectorPower.Neg()
+	assert.True(h.t, expectedPowerDelta.Equals(ret.PowerDelta))
+	assert.Equal(h.t, pledgeDelta, ret.PledgeDelta)
 
-	return sectorPower.Neg(), pledgeDelta
+	return expectedPowerDelta, pledgeDelta
 }
 
 func (h *actorHarness) reportConsensusFault(rt *mock.Runtime, from addr.Address, fault *runtime.ConsensusFault) {
@@ -5708,6 +7522,9 @@ func (h *actorHarness) reportConsensusFault(rt *mock.Runtime, from addr.Address,
 		ThisEpochRewardSmoothed: h.epochRewardSmooth,
 	}
 	rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.ThisEpochReward, nil, big.Zero(), &currentReward, exitcode.Ok)
+	if fault != nil {
+		h.expectRecordConsensusFaults(rt, fault)
+	}
 
 	thisEpochReward := h.epochRewardSmooth.Estimate()
 	penaltyTotal := miner.ConsensusFaultPenalty(thisEpochReward)
@@ -5722,6 +7539,52 @@ func (h *actorHarness) reportConsensusFault(rt *mock.Runtime, from addr.Address,
 	rt.Verify()
 }
 
+func (h *actorHarness) reportConsensusFaults(rt *mock.Runtime, from addr.Address, faults ...*runtime.ConsensusFault) {
+	rt.SetCaller(from, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+	params := &miner.ReportConsensusFaultsParams{}
+	for i, fault := range faults {
+		// distinct headers for each fault
+		report := miner.ReportConsensusFaultParams{
+			BlockHeader1:     []byte{byte(i)},
+			BlockHeader2:     []byte{byte(i)},
+			BlockHeaderExtra: nil,
+		}
+		params.Faults = append(params.Faults, report)
+		rt.ExpectVerifyConsensusFault(report.BlockHeader1, report.BlockHeader2, report.BlockHeaderExtra, fault, nil)
+	}
+
+	currentReward := reward.ThisEpochRewardReturn{
+		ThisEpochBaselinePower:  h.baselinePower,
+		ThisEpochRewardSmoothed: h.epochRewardSmooth,
+	}
+	rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.ThisEpochReward, nil, big.Zero(), &currentReward, exitcode.Ok)
+	h.expectRecordConsensusFaults(rt, faults...)
+
+	// a report is penalized once, however many faults it holds
+	thisEpochReward := h.epochRewardSmooth.Estimate()
+	penaltyTotal := miner.ConsensusFaultPenalty(thisEpochReward)
+	rewardTotal := miner.RewardForConsensusSlashReport(thisEpochReward)
+	rt.ExpectSend(from, builtin.MethodSend, nil, rewardTotal, nil, exitcode.Ok)
+
+	// pay fault fee
+	toBurn := big.Sub(penaltyTotal, rewardTotal)
+	rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, toBurn, nil, exitcode.Ok)
+
+	rt.Call(h.a.ReportConsensusFaults, params)
+	rt.Verify()
+}
+
+func (h *actorHarness) expectRecordConsensusFaults(rt *mock.Runtime, faults ...*runtime.ConsensusFault) {
+	params := &power.RecordConsensusFaultsParams{
+		IneligibleUntil: rt.Epoch() + miner.ConsensusFaultIneligibilityDuration,
+	}
+	for _, fault := range faults {
+		params.Faults = append(params.Faults, power.ConsensusFaultRecord{Epoch: fault.Epoch, Type: fault.Type})
+	}
+	rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.RecordConsensusFaults, params, big.Zero(), nil, exitcode.Ok)
+}
+
 func (h *actorHarness) applyRewards(rt *mock.Runtime, amt, penalty abi.TokenAmount) {
 	// This harness function does not handle the state where apply rewards is
 	// on a miner with existing fee debt.  This state is not protocol reachable
@@ -5753,16 +7616,33 @@ func (h *actorHarness) applyRewards(rt *mock.Runtime, amt, penalty abi.TokenAmou
 	rt.Verify()
 }
 
+// Locks funds sent by a funder, expecting the given change in the miner's pledge.
+func (h *actorHarness) lockFunds(rt *mock.Runtime, funder addr.Address, amount abi.TokenAmount, spec miner.VestSpec, pledgeDelta abi.TokenAmount) {
+	rt.SetCaller(funder, builtin.AccountActorCodeID)
+	rt.SetReceived(amount)
+	rt.SetBalance(big.Add(rt.Balance(), amount))
+	rt.ExpectValidateCallerAny()
+	if !pledgeDelta.IsZero() {
+		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdatePledgeTotal, &pledgeDelta, big.Zero(), nil, exitcode.Ok)
+	}
+
+	rt.Call(h.a.LockFunds, &miner.LockFundsParams{VestSpec: spec})
+	rt.Verify()
+	rt.SetReceived(big.Zero())
+}
+
 type cronConfig struct {
 	noEnrollment              bool // true if expect not to continue enrollment false otherwise
 	expectedEnrollment        abi.ChainEpoch
 	detectedFaultsPowerDelta  *miner.PowerPair
 	expiredSectorsPowerDelta  *miner.PowerPair
 	expiredSectorsPledgeDelta abi.TokenAmount
-	continuedFaultsPenalty    abi.TokenAmount // Expected amount burnt to pay continued fault penalties.
-	expiredPrecommitPenalty   abi.TokenAmount // Expected amount burnt to pay for expired precommits
-	repaidFeeDebt             abi.TokenAmount // Expected amount burnt to repay fee debt.
-	penaltyFromUnlocked       abi.TokenAmount // Expected reduction in unlocked balance from penalties exceeding vesting funds.
+	continuedFaultsPenalty    abi.TokenAmount           // Expected amount burnt to pay continued fault penalties.
+	expiredPrecommitPenalty   abi.TokenAmount           // Expected amount burnt to pay for expired precommits
+	repaidFeeDebt             abi.TokenAmount           // Expected amount burnt to repay fee debt.
+	penaltyFromUnlocked       abi.TokenAmount           // Expected reduction in unlocked balance from penalties exceeding vesting funds.
+	notification              *miner.NotificationParams // Expected notification to the miner's notification target.
+	notificationExit          exitcode.ExitCode         // Exit code returned by the notification target.
 }
 
 func (h *actorHarness) onDeadlineCron(rt *mock.Runtime, config *cronConfig) {
@@ -5841,6 +7721,12 @@ func (h *actorHarness) onDeadlineCron(rt *mock.Runtime, config *cronConfig) {
 		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdatePledgeTotal, &pledgeDelta, big.Zero(), nil, exitcode.Ok)
 	}
 
+	if config.notification != nil {
+		info, err := st.GetInfo(rt.AdtStore())
+		require.NoError(h.t, err)
+		rt.ExpectSend(*info.NotificationTarget, miner.NotificationMethod, config.notification, big.Zero(), nil, config.notificationExit)
+	}
+
 	// Re-enrollment for next period.
 	if !config.noEnrollment {
 		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.EnrollCronEvent,
@@ -5869,6 +7755,135 @@ func (h *actorHarness) withdrawFunds(rt *mock.Runtime, amountRequested, amountWi
 	rt.Verify()
 }
 
+func (h *actorHarness) getBalanceBreakdown(rt *mock.Runtime) *miner.BalanceBreakdown {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.a.GetBalanceBreakdown, nil).(*miner.BalanceBreakdown)
+	require.NotNil(h.t, ret)
+	rt.Verify()
+	return ret
+}
+
+func (h *actorHarness) deadlinePostStatus(rt *mock.Runtime) *miner.DeadlinePostStatusReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.a.DeadlinePostStatus, nil).(*miner.DeadlinePostStatusReturn)
+	require.NotNil(h.t, ret)
+	rt.Verify()
+	return ret
+}
+
+func (h *actorHarness) provingPeriodStatus(rt *mock.Runtime) *miner.ProvingPeriodStatusReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.a.ProvingPeriodStatus, nil).(*miner.ProvingPeriodStatusReturn)
+	require.NotNil(h.t, ret)
+	rt.Verify()
+	return ret
+}
+
+func (h *actorHarness) projectSectorPledge(rt *mock.Runtime, params *miner.ProjectSectorPledgeParams) *miner.SectorPledgeProjection {
+	rt.ExpectValidateCallerAny()
+	expectQueryNetworkInfo(rt, h)
+	ret := rt.Call(h.a.ProjectSectorPledge, params).(*miner.SectorPledgeProjection)
+	require.NotNil(h.t, ret)
+	rt.Verify()
+	return ret
+}
+
+func (h *actorHarness) earlyTerminations(rt *mock.Runtime, page builtin.PageParams) *miner.EarlyTerminationsReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.a.EarlyTerminations, &miner.EarlyTerminationsParams{Page: page}).(*miner.EarlyTerminationsReturn)
+	require.NotNil(h.t, ret)
+	rt.Verify()
+	return ret
+}
+
+func (h *actorHarness) processEarlyTerminations(rt *mock.Runtime, caller addr.Address, expectedFee, expectedPledgeDelta, expectedReward abi.TokenAmount) {
+	rt.SetCaller(caller, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+	expectQueryNetworkInfo(rt, h)
+	if !expectedFee.IsZero() {
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, expectedFee, nil, exitcode.Ok)
+	}
+	if !expectedPledgeDelta.IsZero() {
+		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdatePledgeTotal, &expectedPledgeDelta, big.Zero(), nil, exitcode.Ok)
+	}
+	if !expectedReward.IsZero() {
+		rt.ExpectSend(caller, builtin.MethodSend, nil, expectedReward, nil, exitcode.Ok)
+	}
+	rt.Call(h.a.ProcessEarlyTerminations, nil)
+	rt.Verify()
+}
+
+func (h *actorHarness) pruneExpiredProofs(rt *mock.Runtime, caller addr.Address, expectedReward abi.TokenAmount) {
+	rt.SetCaller(caller, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+	if !expectedReward.IsZero() {
+		rt.ExpectSend(caller, builtin.MethodSend, nil, expectedReward, nil, exitcode.Ok)
+	}
+	rt.Call(h.a.PruneExpiredProofs, nil)
+	rt.Verify()
+}
+
+func (h *actorHarness) faultExpirations(rt *mock.Runtime, until abi.ChainEpoch) *miner.FaultExpirationsReturn {
+	return h.faultExpirationsPage(rt, until, builtin.PageParams{})
+}
+
+func (h *actorHarness) faultExpirationsPage(rt *mock.Runtime, until abi.ChainEpoch, page builtin.PageParams) *miner.FaultExpirationsReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.a.FaultExpirations, &miner.FaultExpirationsParams{Until: until, Page: page}).(*miner.FaultExpirationsReturn)
+	require.NotNil(h.t, ret)
+	rt.Verify()
+	return ret
+}
+
+func (h *actorHarness) expirationSchedule(rt *mock.Runtime, from, to abi.ChainEpoch) *miner.ExpirationScheduleReturn {
+	return h.expirationSchedulePage(rt, from, to, builtin.PageParams{})
+}
+
+func (h *actorHarness) expirationSchedulePage(rt *mock.Runtime, from, to abi.ChainEpoch, page builtin.PageParams) *miner.ExpirationScheduleReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.a.ExpirationSchedule, &miner.ExpirationScheduleParams{From: from, To: to, Page: page}).(*miner.ExpirationScheduleReturn)
+	require.NotNil(h.t, ret)
+	rt.Verify()
+	return ret
+}
+
+func (h *actorHarness) getReliabilityStats(rt *mock.Runtime) *miner.ReliabilityStats {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.a.GetReliabilityStats, nil).(*miner.ReliabilityStats)
+	require.NotNil(h.t, ret)
+	rt.Verify()
+	return ret
+}
+
+func (h *actorHarness) getSectorRegions(rt *mock.Runtime, sectors bitfield.BitField) []miner.SectorRegion {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.a.GetSectorRegions, &miner.GetSectorRegionsParams{Sectors: sectors}).(*miner.GetSectorRegionsReturn)
+	require.NotNil(h.t, ret)
+	rt.Verify()
+	return ret.Regions
+}
+
+func (h *actorHarness) batchLimits(rt *mock.Runtime) *miner.BatchLimitsReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.a.BatchLimits, nil).(*miner.BatchLimitsReturn)
+	require.NotNil(h.t, ret)
+	rt.Verify()
+	return ret
+}
+
+func (h *actorHarness) withdrawFundsTo(rt *mock.Runtime, recipient addr.Address, amountRequested, amountWithdrawn abi.TokenAmount) {
+	rt.SetCaller(h.owner, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(h.owner)
+
+	rt.ExpectSend(recipient, builtin.MethodSend, nil, amountWithdrawn, nil, exitcode.Ok)
+	rt.Call(h.a.WithdrawBalanceTo, &miner.WithdrawBalanceToParams{
+		AmountRequested: amountRequested,
+		Recipient:       &recipient,
+	})
+
+	rt.Verify()
+}
+
 func (h *actorHarness) repayDebt(rt *mock.Runtime, value, expectedRepayedFromVest, expectedRepaidFromBalance abi.TokenAmount) {
 	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
 	rt.ExpectValidateCallerAddr(append(h.controlAddrs, h.owner, h.worker)...)
@@ -6147,6 +8162,14 @@ func makeProveCommit(sectorNo abi.SectorNumber) *miner.ProveCommitSectorParams {
 	}
 }
 
+func makeAttestSectorPieces(sectorNo abi.SectorNumber, pieces []abi.PieceInfo) *miner.AttestSectorPiecesParams {
+	params := &miner.AttestSectorPiecesParams{SectorNumber: sectorNo}
+	for _, piece := range pieces {
+		params.Pieces = append(params.Pieces, miner.AttestedPiece{Size: piece.Size, PieceCID: piece.PieceCID})
+	}
+	return params
+}
+
 func makeProveCommitAggregate(sectorNos bitfield.BitField) *miner.ProveCommitAggregateParams {
 	return &miner.ProveCommitAggregateParams{
 		SectorNumbers:  sectorNos,
@@ -55,6 +55,13 @@ var TerminationRewardFactor = builtin.BigFrac{ // PARAM_SPEC
 // Maximum number of lifetime days penalized when a sector is terminated.
 const TerminationLifetimeCap = 140 // PARAM_SPEC
 
+// Fraction of a pre-commit deposit refunded when the sector is proven after its prove-commit deadline
+// but before the expired pre-commit is cleaned up. The remainder of the deposit is burned.
+var LatePreCommitDepositRefundFactor = builtin.BigFrac{ // PARAM_SPEC
+	Numerator:   big.NewInt(1),
+	Denominator: big.NewInt(2),
+}
+
 // Multiplier of whole per-winner rewards for a consensus fault penalty.
 const ConsensusFaultFactor = 5
 
@@ -67,6 +74,12 @@ var BaseRewardForDisputedWindowPoSt = big.Mul(big.NewInt(4), builtin.TokenPrecis
 // Base penalty for a successful disputed window post proof.
 var BasePenaltyForDisputedWindowPoSt = big.Mul(big.NewInt(20), builtin.TokenPrecision) // PARAM_SPEC
 
+// Reward for pruning the expired proof snapshots of a deadline.
+var RewardForPrunedProofSnapshot = big.Div(builtin.TokenPrecision, big.NewInt(100)) // PARAM_SPEC
+
+// Reward for processing a batch of a miner's deferred early terminations.
+var RewardForProcessedEarlyTerminations = big.Div(builtin.TokenPrecision, big.NewInt(100)) // PARAM_SPEC
+
 // The projected block reward a sector would earn over some period.
 // Also known as "BR(t)".
 // BR(t) = ProjectedRewardFraction(t) * SectorQualityAdjustedPower
@@ -146,6 +159,32 @@ func PledgePenaltyForInvalidWindowPoSt(rewardEstimate, networkQAPowerEstimate sm
 	)
 }
 
+// The portion of an expired pre-commit's deposit refunded when its sector is proven during the clean up delay.
+func LatePreCommitDepositRefund(deposit abi.TokenAmount) abi.TokenAmount {
+	return big.Div(big.Mul(deposit, LatePreCommitDepositRefundFactor.Numerator), LatePreCommitDepositRefundFactor.Denominator)
+}
+
+// The collateral projected to be required of a sector.
+type SectorPledgeProjection struct {
+	QAPower          abi.StoragePower // Quality-adjusted power of the sector
+	PreCommitDeposit abi.TokenAmount
+	InitialPledge    abi.TokenAmount
+}
+
+// Projects the pre-commit deposit and initial pledge of a sector with the given size, lifetime and deal weights,
+// given estimates of network reward and power, the baseline power and the circulating supply.
+// This computes the collateral as PreCommitSector and ProveCommitSector do, using the same duration for both,
+// so it's exact when a sector is pre-committed and proven under the same conditions.
+func ProjectSectorPledgeForWeight(sectorSize abi.SectorSize, duration abi.ChainEpoch, dealWeight, verifiedDealWeight abi.DealWeight,
+	rewardEstimate, networkQAPowerEstimate smoothing.FilterEstimate, baselinePower abi.StoragePower, circulatingSupply abi.TokenAmount) SectorPledgeProjection {
+	qaPower := QAPowerForWeight(sectorSize, duration, dealWeight, verifiedDealWeight)
+	return SectorPledgeProjection{
+		QAPower:          qaPower,
+		PreCommitDeposit: PreCommitDepositForPower(rewardEstimate, networkQAPowerEstimate, qaPower),
+		InitialPledge:    InitialPledgeForPower(qaPower, baselinePower, rewardEstimate, networkQAPowerEstimate, circulatingSupply),
+	}
+}
+
 // Computes the PreCommit deposit given sector qa weight and current network conditions.
 // PreCommit Deposit = BR(PreCommitDepositProjectionPeriod)
 func PreCommitDepositForPower(rewardEstimate, networkQAPowerEstimate smoothing.FilterEstimate, qaSectorPower abi.StoragePower) abi.TokenAmount {
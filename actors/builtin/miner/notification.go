@@ -0,0 +1,56 @@
+package miner
+
+import (
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	rtt "github.com/filecoin-project/go-state-types/rt"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+)
+
+// Method invoked on a miner's notification target to notify it of sectors affected by cron.
+// It is numbered above the methods of every built-in actor, so that a notification can't invoke one of them.
+const NotificationMethod = abi.MethodNum(1024)
+
+// The kind of event of which a miner's notification target is notified.
+type NotificationKind uint64
+
+const (
+	// Pre-committed sectors expired without being proven, and their deposits were burned.
+	NotificationPreCommitsExpired NotificationKind = iota
+	// Sectors were terminated by cron, e.g. having been faulty for too long.
+	NotificationSectorsTerminated
+)
+
+// Parameters of the notification sent to a miner's notification target.
+type NotificationParams struct {
+	Kind    NotificationKind
+	Epoch   abi.ChainEpoch // Epoch at which the event was processed
+	Sectors bitfield.BitField
+}
+
+// Notifies a miner's notification target, if it has one, of sectors affected by cron.
+// The send carries no value and its exit code is ignored, so a failing target can't hold up cron.
+// The runtime offers no gas limit for an individual send, so the notification is bounded by the cron work
+// that produced it (e.g. AddressedSectorsMax terminations); the target is responsible for the cost of its method.
+func notifyTarget(rt Runtime, target *addr.Address, kind NotificationKind, sectors bitfield.BitField) {
+	if target == nil {
+		return
+	}
+	empty, err := sectors.IsEmpty()
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check notified sectors")
+	if empty {
+		return
+	}
+	code := rt.Send(*target, NotificationMethod, &NotificationParams{
+		Kind:    kind,
+		Epoch:   rt.CurrEpoch(),
+		Sectors: sectors,
+	}, big.Zero(), &builtin.Discard{})
+	if !code.IsSuccess() {
+		rt.Log(rtt.WARN, "failed to notify %v of event %d, exit code %v", *target, kind, code)
+	}
+}
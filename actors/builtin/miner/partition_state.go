@@ -394,6 +394,20 @@ func (p *Partition) DeclareFaultsRecovered(sectors Sectors, ssize abi.SectorSize
 	return nil
 }
 
+// Returns the faulty sectors scheduled to expire early at the fault expiration epoch, i.e. those that became
+// faulty at the same time.
+func (p *Partition) FaultsExpiringAt(store adt.Store, quant builtin.QuantSpec, faultExpiration abi.ChainEpoch) (bitfield.BitField, error) {
+	expirations, err := LoadExpirationQueue(store, p.ExpirationsEpochs, quant, PartitionExpirationAmtBitwidth)
+	if err != nil {
+		return bitfield.BitField{}, xerrors.Errorf("failed to load expiration queue: %w", err)
+	}
+	es, err := expirations.mayGet(quant.QuantizeUp(faultExpiration))
+	if err != nil {
+		return bitfield.BitField{}, err
+	}
+	return es.EarlySectors, nil
+}
+
 // Removes sectors from recoveries and recovering power. Assumes sectors are currently faulty and recovering..
 func (p *Partition) removeRecoveries(sectorNos bitfield.BitField, power PowerPair) (err error) {
 	empty, err := sectorNos.IsEmpty()
@@ -693,6 +707,44 @@ func (p *Partition) PopExpiredSectors(store adt.Store, until abi.ChainEpoch, qua
 	return popped, nil
 }
 
+// Calls cb for each epoch, up to and including until, at which some of the partition's faulty sectors
+// are scheduled to be terminated for having been faulty for FaultMaxAge.
+// Faulty sectors that expire on time before reaching FaultMaxAge are not included.
+func (p *Partition) ForEachFaultExpiration(store adt.Store, until abi.ChainEpoch, quant builtin.QuantSpec,
+	cb func(epoch abi.ChainEpoch, sectors bitfield.BitField) error) error {
+	return p.ForEachExpiration(store, until, quant, func(epoch abi.ChainEpoch, es *ExpirationSet) error {
+		if empty, err := es.EarlySectors.IsEmpty(); err != nil {
+			return err
+		} else if empty {
+			return nil
+		}
+		return cb(epoch, es.EarlySectors)
+	})
+}
+
+// Invokes a callback with each set of sectors in the partition's expiration queue up to and including some epoch,
+// in epoch order. The set passed to the callback is overwritten by the next iteration.
+func (p *Partition) ForEachExpiration(store adt.Store, until abi.ChainEpoch, quant builtin.QuantSpec,
+	cb func(epoch abi.ChainEpoch, es *ExpirationSet) error) error {
+	expirations, err := LoadExpirationQueue(store, p.ExpirationsEpochs, quant, PartitionExpirationAmtBitwidth)
+	if err != nil {
+		return xerrors.Errorf("failed to load expiration queue: %w", err)
+	}
+
+	var es ExpirationSet
+	errStop := errors.New("stop")
+	if err = expirations.ForEach(&es, func(i int64) error {
+		epoch := abi.ChainEpoch(i)
+		if epoch > until {
+			return errStop
+		}
+		return cb(epoch, &es)
+	}); err != nil && err != errStop {
+		return err
+	}
+	return nil
+}
+
 // Marks all non-faulty sectors in the partition as faulty and clears recoveries, updating power memos appropriately.
 // All sectors' expirations are rescheduled to the fault expiration, as "early" (if not expiring earlier)
 // Returns the power delta, power that should be penalized (new faults + failed recoveries), and newly faulty power.
@@ -743,6 +795,16 @@ func (p *Partition) RecordMissedPost(
 	return powerDelta, penalizedPower, newFaultyPower, nil
 }
 
+// Calls cb for each epoch at which some of the partition's sectors were terminated early and await processing,
+// in epoch order.
+func (p *Partition) ForEachEarlyTermination(store adt.Store, cb func(epoch abi.ChainEpoch, sectors bitfield.BitField) error) error {
+	earlyTerminatedQ, err := LoadBitfieldQueue(store, p.EarlyTerminated, builtin.NoQuantization, PartitionEarlyTerminationArrayAmtBitwidth)
+	if err != nil {
+		return xerrors.Errorf("failed to load early terminations: %w", err)
+	}
+	return earlyTerminatedQ.ForEach(cb)
+}
+
 func (p *Partition) PopEarlyTerminations(store adt.Store, maxSectors uint64) (result TerminationResult, hasMore bool, err error) {
 	stopErr := errors.New("stop iter")
 
@@ -0,0 +1,146 @@
+package miner
+
+import (
+	"encoding/binary"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	mh "github.com/multiformats/go-multihash"
+	"golang.org/x/xerrors"
+)
+
+// Multiaddr protocol code for a libp2p peer ID component (formerly "ipfs").
+const multiaddrProtocolP2P = 421
+
+// Size in bytes of the address part of multiaddr protocol components, indexed by protocol code.
+// A size of -1 indicates a variable-length address prefixed by its length as an unsigned varint.
+// The table is used only to find the extent of each component, so that validation doesn't depend on the
+// protocols registered with any multiaddr library. A component with a code not in the table is accepted
+// only where its size is implied, i.e. as the last component with no address.
+var multiaddrProtocolSizes = map[uint64]int{
+	4:                    4,  // ip4
+	6:                    2,  // tcp
+	33:                   2,  // dccp
+	41:                   16, // ip6
+	42:                   -1, // ip6zone
+	43:                   1,  // ipcidr
+	53:                   -1, // dns
+	54:                   -1, // dns4
+	55:                   -1, // dns6
+	56:                   -1, // dnsaddr
+	132:                  2,  // sctp
+	273:                  2,  // udp
+	275:                  0,  // p2p-webrtc-star
+	276:                  0,  // p2p-webrtc-direct
+	277:                  0,  // p2p-stardust
+	280:                  0,  // webrtc-direct
+	281:                  0,  // webrtc
+	290:                  0,  // p2p-circuit
+	301:                  0,  // udt
+	302:                  0,  // utp
+	400:                  -1, // unix
+	multiaddrProtocolP2P: -1, // p2p
+	443:                  0,  // https
+	444:                  12, // onion
+	445:                  37, // onion3
+	446:                  -1, // garlic64
+	447:                  -1, // garlic32
+	448:                  0,  // tls
+	449:                  -1, // sni
+	454:                  0,  // noise
+	460:                  0,  // quic
+	461:                  0,  // quic-v1
+	465:                  0,  // webtransport
+	466:                  -1, // certhash
+	477:                  0,  // ws
+	478:                  0,  // wss
+	479:                  0,  // p2p-websocket-star
+	480:                  0,  // http
+	481:                  -1, // http-path
+	777:                  8,  // memory
+}
+
+// Maximum length of a multiformats unsigned varint.
+const maxUvarintLength = 9
+
+// Checks that a peer ID is a well-formed libp2p peer ID, i.e. a multihash of the peer's public key
+// either inlined with the identity hash function or hashed with sha2-256.
+func validatePeerID(pid []byte) error {
+	decoded, err := mh.Decode(pid)
+	if err != nil {
+		return xerrors.Errorf("invalid peer ID multihash: %w", err)
+	}
+	switch decoded.Code {
+	case mh.IDENTITY:
+		if decoded.Length == 0 {
+			return xerrors.Errorf("empty inline peer ID public key")
+		}
+	case mh.SHA2_256:
+		if decoded.Length != 32 {
+			return xerrors.Errorf("invalid sha2-256 peer ID digest length %d", decoded.Length)
+		}
+	default:
+		return xerrors.Errorf("invalid peer ID multihash code %d", decoded.Code)
+	}
+	return nil
+}
+
+// Checks that a multiaddr is a well-formed sequence of components in the binary multiaddr encoding,
+// each a protocol code followed by an address of the size the protocol requires.
+// Only the structure is checked, not the content of addresses, except for the peer ID of a p2p component.
+func validateMultiaddr(ma abi.Multiaddrs) error {
+	if len(ma) == 0 {
+		return xerrors.Errorf("empty multiaddr")
+	}
+
+	buf := []byte(ma)
+	for len(buf) > 0 {
+		code, n, err := readUvarint(buf)
+		if err != nil {
+			return xerrors.Errorf("invalid multiaddr protocol code: %w", err)
+		}
+		buf = buf[n:]
+
+		size, ok := multiaddrProtocolSizes[code]
+		if !ok {
+			if len(buf) > 0 {
+				return xerrors.Errorf("unknown multiaddr protocol %d with %d bytes remaining", code, len(buf))
+			}
+			size = 0
+		}
+		if size < 0 {
+			length, n, err := readUvarint(buf)
+			if err != nil {
+				return xerrors.Errorf("invalid multiaddr length for protocol %d: %w", code, err)
+			}
+			buf = buf[n:]
+			if length == 0 || length > uint64(len(buf)) {
+				return xerrors.Errorf("invalid multiaddr length %d for protocol %d with %d bytes remaining", length, code, len(buf))
+			}
+			size = int(length)
+		}
+		if size > len(buf) {
+			return xerrors.Errorf("truncated multiaddr address for protocol %d, need %d bytes, have %d", code, size, len(buf))
+		}
+
+		if code == multiaddrProtocolP2P {
+			if err := validatePeerID(buf[:size]); err != nil {
+				return xerrors.Errorf("invalid multiaddr p2p component: %w", err)
+			}
+		}
+		buf = buf[size:]
+	}
+	return nil
+}
+
+// Reads a minimally-encoded unsigned varint, returning the value and number of bytes read.
+func readUvarint(buf []byte) (uint64, int, error) {
+	v, n := binary.Uvarint(buf)
+	if n <= 0 || n > maxUvarintLength {
+		return 0, 0, xerrors.Errorf("malformed varint")
+	}
+	// A trailing zero byte is redundant, and would permit more than one encoding of the same value.
+	if n > 1 && buf[n-1] == 0 {
+		return 0, 0, xerrors.Errorf("varint not minimally encoded")
+	}
+	return v, n, nil
+}
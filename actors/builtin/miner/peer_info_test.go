@@ -0,0 +1,72 @@
+package miner
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePeerID(t *testing.T) {
+	inline, err := mh.Encode([]byte("public key"), mh.IDENTITY)
+	require.NoError(t, err)
+	hashed, err := mh.Encode(make([]byte, 32), mh.SHA2_256)
+	require.NoError(t, err)
+	shortHash, err := mh.Encode(make([]byte, 20), mh.SHA2_256)
+	require.NoError(t, err)
+	otherHash, err := mh.Encode(make([]byte, 32), mh.SHA3_256)
+	require.NoError(t, err)
+
+	assert.NoError(t, validatePeerID(inline))
+	assert.NoError(t, validatePeerID(hashed))
+
+	assert.Error(t, validatePeerID(nil))
+	assert.Error(t, validatePeerID([]byte("not a peer id")))
+	assert.Error(t, validatePeerID([]byte{0x00, 0x00}))
+	assert.Error(t, validatePeerID(shortHash))
+	assert.Error(t, validatePeerID(otherHash))
+	assert.Error(t, validatePeerID(append(hashed, 0x01)))
+}
+
+func TestValidateMultiaddr(t *testing.T) {
+	pid, err := mh.Encode(make([]byte, 32), mh.SHA2_256)
+	require.NoError(t, err)
+	certHash, err := mh.Encode(make([]byte, 32), mh.SHA2_256)
+	require.NoError(t, err)
+
+	valid := map[string]abi.Multiaddrs{
+		"/ip4/127.0.0.1/tcp/4001":         {0x04, 127, 0, 0, 1, 0x06, 0x0f, 0xa1},
+		"/ip6/::1/udp/4001/quic":          append(append(abi.Multiaddrs{0x29}, make([]byte, 16)...), 0x91, 0x02, 0x0f, 0xa1, 0xcc, 0x03),
+		"/dns4/example.com/tcp/443/wss":   append(append(abi.Multiaddrs{0x36, 11}, "example.com"...), 0x06, 0x01, 0xbb, 0xde, 0x03),
+		"/ip4/127.0.0.1/tcp/4001/p2p/Qm":  append(abi.Multiaddrs{0x04, 127, 0, 0, 1, 0x06, 0x0f, 0xa1, 0xa5, 0x03, byte(len(pid))}, pid...),
+		"/ip4/127.0.0.1/udp/4001/quic-v1": {0x04, 127, 0, 0, 1, 0x91, 0x02, 0x0f, 0xa1, 0xcd, 0x03},
+		"/ip4/127.0.0.1/udp/4001/quic-v1/webtransport/certhash/uEi": append(
+			abi.Multiaddrs{0x04, 127, 0, 0, 1, 0x91, 0x02, 0x0f, 0xa1, 0xcd, 0x03, 0xd1, 0x03, 0xd2, 0x03, byte(len(certHash))}, certHash...),
+		"/ip4/127.0.0.1/udp/4001/webrtc-direct/certhash/uEi": append(
+			abi.Multiaddrs{0x04, 127, 0, 0, 1, 0x91, 0x02, 0x0f, 0xa1, 0x98, 0x02, 0xd2, 0x03, byte(len(certHash))}, certHash...),
+		"/ip4/127.0.0.1/tcp/4001/p2p/Qm/p2p-circuit/webrtc": append(append(
+			abi.Multiaddrs{0x04, 127, 0, 0, 1, 0x06, 0x0f, 0xa1, 0xa5, 0x03, byte(len(pid))}, pid...), 0xa2, 0x02, 0x99, 0x02),
+		"unknown protocol without address": {0x04, 127, 0, 0, 1, 0xff, 0x7f},
+	}
+	for name, ma := range valid {
+		assert.NoError(t, validateMultiaddr(ma), name)
+	}
+
+	invalid := map[string]abi.Multiaddrs{
+		"empty":              {},
+		"garbage":            abi.Multiaddrs("imanewminer"),
+		"unknown protocol":   {0x7f, 0x01},
+		"truncated certhash": {0xd2, 0x03, 0x22, 0x12, 0x20},
+		"truncated address":  {0x04, 127, 0, 0},
+		"zero length":        {0x36, 0x00},
+		"length overflow":    {0x36, 0x05, 'a', 'b'},
+		"non-minimal varint": {0x84, 0x00, 127, 0, 0, 1},
+		"malformed varint":   {0x80},
+		"invalid p2p":        {0xa5, 0x03, 0x02, 'h', 'i'},
+	}
+	for name, ma := range invalid {
+		assert.Error(t, validateMultiaddr(ma), name)
+	}
+}
@@ -25,6 +25,11 @@ var WPoStChallengeWindow = abi.ChainEpoch(30 * 60 / builtin.EpochDurationSeconds
 // PoSts submitted during that period may be disputed.
 var WPoStDisputeWindow = 2 * ChainFinality // PARAM_SPEC
 
+// WPoStSnapshotRetention is the period after a challenge window ends for which the snapshot of the deadline's
+// partitions and optimistically accepted PoSts is retained. The snapshot is needed only to dispute PoSts,
+// so is pruned at the end of the first deadline after this period elapses.
+var WPoStSnapshotRetention = WPoStDisputeWindow // PARAM_SPEC
+
 // The number of non-overlapping PoSt deadlines in a proving period.
 // This spreads a miner's Window PoSt work across a proving period.
 const WPoStPeriodDeadlines = uint64(48) // PARAM_SPEC
@@ -50,6 +55,13 @@ func init() {
 		panic(fmt.Sprintf("the proof dispute period %d must exceed finality %d", WPoStDisputeWindow, ChainFinality))
 	}
 
+	// Snapshots must be retained for disputes, and would be replaced at the deadline's next challenge
+	// window anyway if retained for longer than the rest of the proving period.
+	if WPoStSnapshotRetention < WPoStDisputeWindow || WPoStSnapshotRetention > WPoStProvingPeriod-WPoStChallengeWindow {
+		panic(fmt.Sprintf("the snapshot retention period %d must be between the dispute window %d and %d",
+			WPoStSnapshotRetention, WPoStDisputeWindow, WPoStProvingPeriod-WPoStChallengeWindow))
+	}
+
 	// A deadline becomes immutable one challenge window before it's challenge window opens.
 	// The challenge lookback must fall within this immutability period.
 	if WPoStChallengeLookback > WPoStChallengeWindow {
@@ -83,6 +95,20 @@ const DeclarationsMax = AddressedPartitionsMax
 // This limits the amount of state to be read in a single message execution.
 const AddressedSectorsMax = 25_000 // PARAM_SPEC
 
+// The maximum number of partitions with expiring sectors processed by a single cron event.
+// When more partitions of a deadline expire together, the rest are processed by cron in subsequent epochs,
+// bounding the gas used by a deadline's cron event however many sectors expire.
+const ExpiringPartitionsMax = 64
+
+// The number of epochs in each window of a miner's rolling reliability counters.
+const ReliabilityStatsWindow = abi.ChainEpoch(30 * builtin.EpochsInDay)
+
+// The maximum length of the region code a miner may declare for sectors it pre-commits.
+const MaxSectorRegionLength = 32
+
+// The number of upcoming deadlines whose opening epochs are reported by ProvingPeriodStatus.
+const ProvingPeriodStatusUpcomingDeadlines = 3
+
 // Libp2p peer info limits.
 const (
 	// MaxPeerIDLength is the maximum length allowed for any on-chain peer ID.
@@ -141,6 +167,34 @@ func CanPreCommitSealProof(s abi.RegisteredSealProof) bool {
 	return ok
 }
 
+// Configures the proof types supported for new miners and sectors, for testing and development networks
+// (e.g. with 8MiB or 512MiB sectors).
+// Both PreCommitSealProofTypesV8 and WindowPoStProofTypes are replaced, with the Window PoSt proof types those
+// corresponding to the seal proof types, so that the two remain consistent.
+// Each proof type must have a maximum prove-commit duration and a Window PoSt partition size. If any does not,
+// an error is returned and the supported proof types are unchanged.
+func SetSupportedProofTypes(types ...abi.RegisteredSealProof) error {
+	sealProofs := make(map[abi.RegisteredSealProof]struct{}, len(types))
+	postProofs := make(map[abi.RegisteredPoStProof]struct{}, len(types))
+	for _, t := range types {
+		if _, ok := MaxProveCommitDuration[t]; !ok {
+			return fmt.Errorf("no max prove-commit duration for seal proof type %d", t)
+		}
+		postProof, err := t.RegisteredWindowPoStProof()
+		if err != nil {
+			return fmt.Errorf("no Window PoSt proof type for seal proof type %d: %w", t, err)
+		}
+		if _, err := builtin.PoStProofWindowPoStPartitionSectors(postProof); err != nil {
+			return fmt.Errorf("no partition size for Window PoSt proof type %d: %w", postProof, err)
+		}
+		sealProofs[t] = struct{}{}
+		postProofs[postProof] = struct{}{}
+	}
+	PreCommitSealProofTypesV8 = sealProofs
+	WindowPoStProofTypes = postProofs
+	return nil
+}
+
 // Checks whether a seal proof type is supported for new miners and sectors.
 // As of network version 11, all permitted seal proof types may be extended.
 func CanExtendSealProofType(_ abi.RegisteredSealProof) bool {
@@ -167,6 +221,19 @@ var MaxProveCommitDuration = map[abi.RegisteredSealProof]abi.ChainEpoch{
 // 32 sectors per epoch would support a single miner onboarding 1EiB of 32GiB sectors in 1 year.
 const PreCommitSectorBatchMaxSize = 256
 
+// The encoded size, in bytes, of a miner's allocated sector numbers above which pre-committing sectors compacts
+// the allocation. This is well below the maximum encoded size of a bitfield, beyond which the allocation could
+// no longer be stored, and so no more sectors pre-committed.
+const AllocatedSectorsCompactionThreshold = 16 << 10
+
+// The maximum number of gaps between allocated sector numbers masked by each compaction.
+// This exceeds the number of gaps a single pre-commit batch can add, so a miner's allocation shrinks with
+// every pre-commitment until compaction is no longer required.
+const AllocatedSectorsCompactionMaxGaps = 2 * PreCommitSectorBatchMaxSize
+
+// The maximum number of consensus faults that may be reported in a single message.
+const ReportConsensusFaultsBatchMaxSize = 32
+
 // Maximum delay between challenge and pre-commitment.
 // This prevents a miner sealing sectors far in advance of committing them to the chain, thus committing to a
 // particular chain.
@@ -193,6 +260,11 @@ const FaultDeclarationCutoff = WPoStChallengeLookback + 50 // PARAM_SPEC
 // This bounds the time a miner can lose client's data before sacrificing pledge and deal collateral.
 var FaultMaxAge = WPoStProvingPeriod * 14 // PARAM_SPEC
 
+// Whether sectors that became faulty at a deadline's previous occurrence are recovered automatically if
+// included in a Window PoSt, without a recovery declaration. Sectors faulty for longer must still be declared recovered.
+// Recovery is automatic only while the miner has no fee debt and no active consensus fault.
+var AutomaticFaultRecovery = false // PARAM_SPEC
+
 // Staging period for a miner worker key change.
 // This delay prevents a miner choosing a more favorable worker key that wins leader elections.
 const WorkerKeyChangeDelay = ChainFinality // PARAM_SPEC
@@ -282,6 +354,27 @@ var RewardVestingSpec = VestSpec{ // PARAM_SPEC
 	Quantization: 12 * builtin.EpochsInHour,
 }
 
+// Maximum duration, from the current epoch, over which funds locked by a third party with LockFunds may vest.
+const MaxLockFundsVestDuration = abi.ChainEpoch(540 * builtin.EpochsInDay) // PARAM_SPEC
+
+// Checks that a vesting schedule for funds locked by a third party adds a bounded number of entries to the
+// miner's vesting table, aligned with the entries for locked rewards.
+func validateLockFundsVestSpec(spec *VestSpec) error {
+	if spec.InitialDelay < 0 || spec.VestPeriod < 0 {
+		return fmt.Errorf("negative initial delay %d or vest period %d", spec.InitialDelay, spec.VestPeriod)
+	}
+	if spec.InitialDelay+spec.VestPeriod > MaxLockFundsVestDuration {
+		return fmt.Errorf("vesting duration %d exceeds maximum %d", spec.InitialDelay+spec.VestPeriod, MaxLockFundsVestDuration)
+	}
+	if spec.Quantization <= 0 || spec.Quantization%RewardVestingSpec.Quantization != 0 {
+		return fmt.Errorf("quantization %d must be a positive multiple of %d", spec.Quantization, RewardVestingSpec.Quantization)
+	}
+	if spec.StepDuration < spec.Quantization {
+		return fmt.Errorf("step duration %d must be at least quantization %d", spec.StepDuration, spec.Quantization)
+	}
+	return nil
+}
+
 // When an actor reports a consensus fault, they earn a share of the penalty paid by the miner.
 func RewardForConsensusSlashReport(epochReward abi.TokenAmount) abi.TokenAmount {
 	return big.Div(epochReward,
@@ -296,6 +389,11 @@ func RewardForDisputedWindowPoSt(proofType abi.RegisteredPoStProof, disputedPowe
 	return BaseRewardForDisputedWindowPoSt
 }
 
+// The reward given for pruning the expired proof snapshots of some number of deadlines.
+func RewardForPrunedProofs(deadlinesPruned int) abi.TokenAmount {
+	return big.Mul(big.NewInt(int64(deadlinesPruned)), RewardForPrunedProofSnapshot)
+}
+
 const MaxAggregatedSectors = 819
 const MinAggregatedSectors = 4
 const MaxAggregateProofSize = 81960
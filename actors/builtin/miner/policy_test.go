@@ -6,6 +6,7 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
 	"github.com/filecoin-project/specs-actors/v5/actors/builtin/miner"
@@ -144,3 +145,35 @@ func assertEqual(t *testing.T, a, b big.Int) {
 		assert.Equal(t, a, b)
 	}
 }
+
+func TestSetSupportedProofTypes(t *testing.T) {
+	sealProofs, postProofs := miner.PreCommitSealProofTypesV8, miner.WindowPoStProofTypes
+	defer func() {
+		miner.PreCommitSealProofTypesV8, miner.WindowPoStProofTypes = sealProofs, postProofs
+	}()
+
+	t.Run("enables small sectors consistently", func(t *testing.T) {
+		require.NoError(t, miner.SetSupportedProofTypes(
+			abi.RegisteredSealProof_StackedDrg8MiBV1_1,
+			abi.RegisteredSealProof_StackedDrg512MiBV1_1,
+		))
+		assert.True(t, miner.CanPreCommitSealProof(abi.RegisteredSealProof_StackedDrg8MiBV1_1))
+		assert.True(t, miner.CanPreCommitSealProof(abi.RegisteredSealProof_StackedDrg512MiBV1_1))
+		assert.False(t, miner.CanPreCommitSealProof(abi.RegisteredSealProof_StackedDrg32GiBV1_1))
+		assert.True(t, miner.CanWindowPoStProof(abi.RegisteredPoStProof_StackedDrgWindow8MiBV1))
+		assert.True(t, miner.CanWindowPoStProof(abi.RegisteredPoStProof_StackedDrgWindow512MiBV1))
+		assert.False(t, miner.CanWindowPoStProof(abi.RegisteredPoStProof_StackedDrgWindow32GiBV1))
+	})
+
+	t.Run("rejects proof types without policy", func(t *testing.T) {
+		require.NoError(t, miner.SetSupportedProofTypes(abi.RegisteredSealProof_StackedDrg32GiBV1_1))
+		assert.Error(t, miner.SetSupportedProofTypes(
+			abi.RegisteredSealProof_StackedDrg8MiBV1_1,
+			abi.RegisteredSealProof(100),
+		))
+		// Unchanged.
+		assert.True(t, miner.CanPreCommitSealProof(abi.RegisteredSealProof_StackedDrg32GiBV1_1))
+		assert.False(t, miner.CanPreCommitSealProof(abi.RegisteredSealProof_StackedDrg8MiBV1_1))
+		assert.True(t, miner.CanWindowPoStProof(abi.RegisteredPoStProof_StackedDrgWindow32GiBV1))
+	})
+}
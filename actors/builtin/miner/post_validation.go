@@ -0,0 +1,140 @@
+package miner
+
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/dline"
+	xc "github.com/filecoin-project/go-state-types/exitcode"
+	"golang.org/x/xerrors"
+
+	. "github.com/filecoin-project/specs-actors/v5/actors/util"
+	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+)
+
+// Checks a prospective Window PoSt submission against a miner's state at an epoch, so that a prover can catch
+// a submission that would be rejected before sending it.
+// This checks the shape of the submission: the proof type and size, the deadline and its partitions, the sectors
+// skipped from each partition, and the bounds of the chain commit epoch. It doesn't check the chain commit
+// randomness (which must be drawn from the chain) or verify the proof itself.
+// The returned error carries the exit code with which SubmitWindowedPoSt would abort.
+func ValidateWindowPoStSubmission(store adt.Store, st *State, params *SubmitWindowedPoStParams, currEpoch abi.ChainEpoch) error {
+	if err := validateWindowPoStParams(params); err != nil {
+		return err
+	}
+	info, err := st.GetInfo(store)
+	if err != nil {
+		return err
+	}
+	if _, err := validateWindowPoStForDeadline(st, info, params, currEpoch); err != nil {
+		return err
+	}
+
+	deadlines, err := st.LoadDeadlines(store)
+	if err != nil {
+		return err
+	}
+	deadline, err := deadlines.LoadDeadline(store, params.Deadline)
+	if err != nil {
+		return xerrors.Errorf("failed to load deadline %d: %w", params.Deadline, err)
+	}
+	partitions, err := deadline.PartitionsArray(store)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[uint64]struct{}, len(params.Partitions))
+	for _, post := range params.Partitions {
+		if _, ok := seen[post.Index]; ok {
+			return xc.ErrIllegalArgument.Wrapf("duplicate partitions proven")
+		}
+		seen[post.Index] = struct{}{}
+
+		if proven, err := deadline.PartitionsPoSted.IsSet(post.Index); err != nil {
+			return xerrors.Errorf("failed to check proven partitions: %w", err)
+		} else if proven {
+			return ErrPartitionAlreadyProven.Wrapf("partition already proven: %d", post.Index)
+		}
+
+		var partition Partition
+		if found, err := partitions.Get(post.Index, &partition); err != nil {
+			return xerrors.Errorf("failed to load partition %d: %w", post.Index, err)
+		} else if !found {
+			return xc.ErrNotFound.Wrapf("no such partition %d", post.Index)
+		}
+		if contains, err := BitFieldContainsAll(partition.Sectors, post.Skipped); err != nil {
+			return xc.ErrIllegalArgument.Wrapf("failed to check skipped sectors of partition %d: %w", post.Index, err)
+		} else if !contains {
+			return xc.ErrIllegalArgument.Wrapf("skipped faults contains sectors outside partition %d", post.Index)
+		}
+	}
+	return nil
+}
+
+// Checks the parts of a Window PoSt submission that don't depend on the miner's state.
+func validateWindowPoStParams(params *SubmitWindowedPoStParams) error {
+	// Verify that the miner has passed exactly 1 proof.
+	if len(params.Proofs) != 1 {
+		return xc.ErrIllegalArgument.Wrapf("expected exactly one proof, got %d", len(params.Proofs))
+	}
+	if !CanWindowPoStProof(params.Proofs[0].PoStProof) {
+		return xc.ErrIllegalArgument.Wrapf("proof type %d not allowed", params.Proofs[0].PoStProof)
+	}
+	if params.Deadline >= WPoStPeriodDeadlines {
+		return xc.ErrIllegalArgument.Wrapf("invalid deadline %d of %d", params.Deadline, WPoStPeriodDeadlines)
+	}
+	// Technically, ChainCommitRand should be _exactly_ 32 bytes. However:
+	// 1. It's convenient to allow smaller slices when testing.
+	// 2. Nothing bad will happen if the caller provides too little randomness.
+	if len(params.ChainCommitRand) > abi.RandomnessLength {
+		return xc.ErrIllegalArgument.Wrapf("expected at most %d bytes of randomness, got %d", abi.RandomnessLength, len(params.ChainCommitRand))
+	}
+	return nil
+}
+
+// Checks a Window PoSt submission against the miner's info and the deadline current at an epoch,
+// returning that deadline.
+func validateWindowPoStForDeadline(st *State, info *MinerInfo, params *SubmitWindowedPoStParams, currEpoch abi.ChainEpoch) (*dline.Info, error) {
+	// Make sure the miner is using the correct proof type.
+	if params.Proofs[0].PoStProof != info.WindowPoStProofType {
+		return nil, xc.ErrIllegalArgument.Wrapf("expected proof of type %d, got proof of type %d", info.WindowPoStProofType, params.Proofs[0].PoStProof)
+	}
+
+	// Make sure the proof size doesn't exceed the max. We could probably check for an exact match, but this is safer.
+	maxProofSize, err := info.WindowPoStProofType.ProofSize()
+	if err != nil {
+		return nil, xc.ErrIllegalState.Wrapf("failed to determine max window post proof size: %w", err)
+	}
+	if maxSize := maxProofSize * uint64(len(params.Partitions)); uint64(len(params.Proofs[0].ProofBytes)) > maxSize {
+		return nil, xc.ErrIllegalArgument.Wrapf("expected proof to be smaller than %d bytes", maxSize)
+	}
+
+	// Validate that the miner didn't try to prove too many partitions at once.
+	submissionPartitionLimit := loadPartitionsSectorsMax(info.WindowPoStPartitionSectors)
+	if uint64(len(params.Partitions)) > submissionPartitionLimit {
+		return nil, xc.ErrIllegalArgument.Wrapf("too many partitions %d, limit %d for partitions of %d sectors",
+			len(params.Partitions), submissionPartitionLimit, info.WindowPoStPartitionSectors)
+	}
+
+	currDeadline := st.DeadlineInfo(currEpoch)
+	// Check that the miner state indicates that the current proving deadline has started.
+	// This should only fail if the cron actor wasn't invoked, and matters only in case that it hasn't been
+	// invoked for a whole proving period, and hence the missed PoSt submissions from the prior occurrence
+	// of this deadline haven't been processed yet.
+	if !currDeadline.IsOpen() {
+		return nil, xc.ErrIllegalState.Wrapf("proving period %d not yet open at %d", currDeadline.PeriodStart, currEpoch)
+	}
+
+	// The miner may only submit a proof for the current deadline.
+	if params.Deadline != currDeadline.Index {
+		return nil, xc.ErrIllegalArgument.Wrapf("invalid deadline %d at epoch %d, expected %d",
+			params.Deadline, currEpoch, currDeadline.Index)
+	}
+
+	// Verify that the PoSt was committed to the chain at most WPoStChallengeLookback+WPoStChallengeWindow in the past.
+	if params.ChainCommitEpoch < currDeadline.Challenge {
+		return nil, xc.ErrIllegalArgument.Wrapf("expected chain commit epoch %d to be after %d", params.ChainCommitEpoch, currDeadline.Challenge)
+	}
+	if params.ChainCommitEpoch >= currEpoch {
+		return nil, xc.ErrIllegalArgument.Wrapf("chain commit epoch %d must be less than the current epoch %d", params.ChainCommitEpoch, currEpoch)
+	}
+	return currDeadline, nil
+}
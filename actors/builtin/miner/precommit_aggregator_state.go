@@ -0,0 +1,81 @@
+package miner
+
+import (
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	xc "github.com/filecoin-project/go-state-types/exitcode"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+)
+
+// Designates an address to prove some pre-committed sectors in an aggregate on the miner's behalf,
+// replacing any address previously designated for them.
+// The designation is removed along with the pre-commitment when the sector is proven or the pre-commitment expires.
+func (st *State) DesignatePreCommitAggregator(store adt.Store, aggregator addr.Address, sectorNos bitfield.BitField) error {
+	precommitted, err := LoadPreCommitMap(store, st.PreCommittedSectors)
+	if err != nil {
+		return err
+	}
+	aggregators, err := adt.AsMap(store, st.PreCommitAggregators, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return xerrors.Errorf("failed to load pre-commit aggregators: %w", err)
+	}
+	if err = sectorNos.ForEach(func(sectorNo uint64) error {
+		if _, found, err := precommitted.Get(abi.SectorNumber(sectorNo)); err != nil {
+			return xerrors.Errorf("failed to load pre-commitment for %d: %w", sectorNo, err)
+		} else if !found {
+			return xc.ErrNotFound.Wrapf("no pre-committed sector %d", sectorNo)
+		}
+		if err := aggregators.Put(SectorKey(abi.SectorNumber(sectorNo)), &aggregator); err != nil {
+			return xerrors.Errorf("failed to store aggregator for sector %d: %w", sectorNo, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if st.PreCommitAggregators, err = aggregators.Root(); err != nil {
+		return xerrors.Errorf("failed to flush pre-commit aggregators: %w", err)
+	}
+	return nil
+}
+
+// Checks whether an address is designated to aggregate the proofs of all of some pre-committed sectors.
+func (st *State) IsPreCommitAggregator(store adt.Store, aggregator addr.Address, sectorNos bitfield.BitField) (bool, error) {
+	aggregators, err := adt.AsMap(store, st.PreCommitAggregators, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return false, xerrors.Errorf("failed to load pre-commit aggregators: %w", err)
+	}
+	designated := true
+	if err = sectorNos.ForEach(func(sectorNo uint64) error {
+		var designee addr.Address
+		found, err := aggregators.Get(SectorKey(abi.SectorNumber(sectorNo)), &designee)
+		if err != nil {
+			return xerrors.Errorf("failed to load aggregator for sector %d: %w", sectorNo, err)
+		}
+		designated = designated && found && designee == aggregator
+		return nil
+	}); err != nil {
+		return false, err
+	}
+	return designated, nil
+}
+
+// Removes any aggregator designated for some sectors.
+func (st *State) removePreCommitAggregators(store adt.Store, sectorNos ...abi.SectorNumber) error {
+	aggregators, err := adt.AsMap(store, st.PreCommitAggregators, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return xerrors.Errorf("failed to load pre-commit aggregators: %w", err)
+	}
+	for _, sectorNo := range sectorNos {
+		if _, err := aggregators.TryDelete(SectorKey(sectorNo)); err != nil {
+			return xerrors.Errorf("failed to remove aggregator for sector %d: %w", sectorNo, err)
+		}
+	}
+	if st.PreCommitAggregators, err = aggregators.Root(); err != nil {
+		return xerrors.Errorf("failed to flush pre-commit aggregators: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package miner
+
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+// Counts of a miner's window PoSt submissions and faults over some window of epochs.
+type ReliabilityCounters struct {
+	PoStsSubmitted            uint64 // Window PoSt submissions accepted
+	FaultsDeclared            uint64 // Sectors named in accepted fault declarations
+	SectorsTerminatedForFault uint64 // Sectors terminated for having been faulty for FaultMaxAge
+}
+
+// Rolling counters of a miner's reliability, for the current window of ReliabilityStatsWindow epochs and the
+// one before it, so that a complete window of history is always available.
+// Windows are aligned to multiples of ReliabilityStatsWindow epochs.
+type ReliabilityStats struct {
+	WindowStart abi.ChainEpoch // First epoch of the current window
+	Current     ReliabilityCounters
+	Previous    ReliabilityCounters // Counters for the window immediately preceding the current one
+}
+
+// Returns the stats as of an epoch, rolling the windows forward to the one containing it.
+func (s ReliabilityStats) AsOf(epoch abi.ChainEpoch) ReliabilityStats {
+	start := reliabilityWindowStart(epoch)
+	if start <= s.WindowStart {
+		return s
+	}
+	rolled := ReliabilityStats{WindowStart: start}
+	if start == s.WindowStart+ReliabilityStatsWindow {
+		rolled.Previous = s.Current
+	}
+	return rolled
+}
+
+// Records an accepted window PoSt submission.
+func (st *State) RecordPoStSubmitted(epoch abi.ChainEpoch) {
+	st.ReliabilityStats = st.ReliabilityStats.AsOf(epoch)
+	st.ReliabilityStats.Current.PoStsSubmitted++
+}
+
+// Records sectors named in an accepted fault declaration.
+func (st *State) RecordFaultsDeclared(epoch abi.ChainEpoch, sectors uint64) {
+	st.ReliabilityStats = st.ReliabilityStats.AsOf(epoch)
+	st.ReliabilityStats.Current.FaultsDeclared += sectors
+}
+
+// Records sectors terminated for having been faulty for too long.
+func (st *State) RecordSectorsTerminatedForFault(epoch abi.ChainEpoch, sectors uint64) {
+	st.ReliabilityStats = st.ReliabilityStats.AsOf(epoch)
+	st.ReliabilityStats.Current.SectorsTerminatedForFault += sectors
+}
+
+func reliabilityWindowStart(epoch abi.ChainEpoch) abi.ChainEpoch {
+	offset := epoch % ReliabilityStatsWindow
+	if offset < 0 {
+		offset += ReliabilityStatsWindow
+	}
+	return epoch - offset
+}
@@ -1,6 +1,7 @@
 package miner
 
 import (
+	"fmt"
 	"math"
 	"sort"
 
@@ -33,6 +34,73 @@ func (dm DeadlineSectorMap) Check(maxPartitions, maxSectors uint64) error {
 	return nil
 }
 
+// BatchLimitError describes a batch of declarations addressing more partitions or sectors than may be processed
+// in a single message. The declarations preceding FirstExcess are within the limits, so the batch may be split there.
+type BatchLimitError struct {
+	Declarations  int    // Number of declarations in the batch
+	FirstExcess   int    // Index of the first declaration at which a limit is exceeded
+	Partitions    uint64 // Partitions addressed by the declarations up to and including FirstExcess
+	MaxPartitions uint64
+	Sectors       uint64 // Sectors addressed by the declarations up to and including FirstExcess
+	MaxSectors    uint64
+}
+
+func (e *BatchLimitError) Error() string {
+	return fmt.Sprintf("declarations %d to %d of %d exceed batch limits: declarations to %d address %d partitions (max %d) and %d sectors (max %d)",
+		e.FirstExcess, e.Declarations-1, e.Declarations, e.FirstExcess, e.Partitions, e.MaxPartitions, e.Sectors, e.MaxSectors)
+}
+
+// AddBatch records the sectors addressed by each of a batch of count declarations, as given by the declaration
+// function, checking as each is added that the map addresses no more than maxPartitions partitions and
+// maxSectors sectors. If a limit is exceeded, the error is a *BatchLimitError identifying the first declaration
+// to exceed it.
+func (dm DeadlineSectorMap) AddBatch(
+	count int, declaration func(i int) (dlIdx, partIdx uint64, sectorNos bitfield.BitField),
+	maxPartitions, maxSectors uint64,
+) error {
+	partitions, sectors, err := dm.Count()
+	if err != nil {
+		return xerrors.Errorf("failed to count sectors: %w", err)
+	}
+	for i := 0; i < count; i++ {
+		dlIdx, partIdx, sectorNos := declaration(i)
+
+		priorCount := uint64(0)
+		if prior, ok := dm[dlIdx][partIdx]; ok {
+			if priorCount, err = prior.Count(); err != nil {
+				return xerrors.Errorf("failed to count sectors for deadline %d, partition %d: %w", dlIdx, partIdx, err)
+			}
+		} else {
+			partitions++
+		}
+		if err = dm.Add(dlIdx, partIdx, sectorNos); err != nil {
+			return xerrors.Errorf("failed to process deadline %d, partition %d: %w", dlIdx, partIdx, err)
+		}
+		newCount, err := dm[dlIdx][partIdx].Count()
+		if err != nil {
+			return xerrors.Errorf("failed to count sectors for deadline %d, partition %d: %w", dlIdx, partIdx, err)
+		}
+
+		added := newCount - priorCount
+		if partitions > maxPartitions || sectors > maxSectors || added > maxSectors-sectors {
+			total := uint64(math.MaxUint64)
+			if added <= math.MaxUint64-sectors {
+				total = sectors + added
+			}
+			return &BatchLimitError{
+				Declarations:  count,
+				FirstExcess:   i,
+				Partitions:    partitions,
+				MaxPartitions: maxPartitions,
+				Sectors:       total,
+				MaxSectors:    maxSectors,
+			}
+		}
+		sectors += added
+	}
+	return nil
+}
+
 // Count counts the number of partitions & sectors within the map.
 func (dm DeadlineSectorMap) Count() (partitions, sectors uint64, err error) {
 	for dlIdx, pm := range dm { //nolint:nomaprange
@@ -70,6 +70,68 @@ func TestDeadlineSectorMapError(t *testing.T) {
 	require.Equal(t, expErr, err)
 }
 
+func TestDeadlineSectorMapAddBatch(t *testing.T) {
+	type declaration struct {
+		dlIdx, partIdx uint64
+		sectors        bitfield.BitField
+	}
+	addBatch := func(dm miner.DeadlineSectorMap, decls []declaration, maxPartitions, maxSectors uint64) error {
+		return dm.AddBatch(len(decls), func(i int) (uint64, uint64, bitfield.BitField) {
+			return decls[i].dlIdx, decls[i].partIdx, decls[i].sectors
+		}, maxPartitions, maxSectors)
+	}
+	decls := []declaration{
+		{0, 0, bf(0, 1)},
+		{0, 1, bf(2)},
+		{0, 0, bf(1, 3)}, // overlaps the first declaration
+		{1, 0, bf(4, 5)},
+	}
+
+	t.Run("within limits", func(t *testing.T) {
+		dm := make(miner.DeadlineSectorMap)
+		require.NoError(t, addBatch(dm, decls, 3, 6))
+		assertBitfieldEquals(t, dm[0][0], 0, 1, 3)
+		assertBitfieldEquals(t, dm[0][1], 2)
+		assertBitfieldEquals(t, dm[1][0], 4, 5)
+	})
+
+	t.Run("too many partitions", func(t *testing.T) {
+		err := addBatch(make(miner.DeadlineSectorMap), decls, 2, 6)
+		var limitErr *miner.BatchLimitError
+		require.True(t, errors.As(err, &limitErr))
+		assert.Equal(t, miner.BatchLimitError{
+			Declarations:  4,
+			FirstExcess:   3,
+			Partitions:    3,
+			MaxPartitions: 2,
+			Sectors:       6,
+			MaxSectors:    6,
+		}, *limitErr)
+	})
+
+	t.Run("too many sectors", func(t *testing.T) {
+		err := addBatch(make(miner.DeadlineSectorMap), decls, 3, 3)
+		var limitErr *miner.BatchLimitError
+		require.True(t, errors.As(err, &limitErr))
+		assert.Equal(t, miner.BatchLimitError{
+			Declarations:  4,
+			FirstExcess:   2,
+			Partitions:    2,
+			MaxPartitions: 3,
+			Sectors:       4,
+			MaxSectors:    3,
+		}, *limitErr)
+		assert.Contains(t, err.Error(), "declarations 2 to 3 of 4 exceed batch limits")
+	})
+
+	t.Run("invalid deadline", func(t *testing.T) {
+		err := addBatch(make(miner.DeadlineSectorMap), []declaration{{miner.WPoStPeriodDeadlines, 0, bf(0)}}, 3, 6)
+		assert.Error(t, err)
+		var limitErr *miner.BatchLimitError
+		assert.False(t, errors.As(err, &limitErr))
+	})
+}
+
 func TestDeadlineSectorMapValues(t *testing.T) {
 	dm := make(miner.DeadlineSectorMap)
 	assert.NoError(t, dm.AddValues(0, 1, 0, 1, 2, 3))
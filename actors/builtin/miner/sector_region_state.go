@@ -0,0 +1,75 @@
+package miner
+
+import (
+	"github.com/filecoin-project/go-bitfield"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+)
+
+// The sectors a miner has declared to be stored in a region.
+type SectorRegion struct {
+	Region  string // Miner-chosen code of a region or availability zone, at most MaxSectorRegionLength bytes
+	Sectors bitfield.BitField
+}
+
+// Keys the sector regions map by region code.
+type regionKey string
+
+func (k regionKey) Key() string {
+	return string(k)
+}
+
+// Records that sectors are stored in a region.
+// Since sector numbers are never re-used, a declaration is never removed, and remains a record of where a sector
+// was claimed to be stored after the sector has expired or terminated.
+func (st *State) RecordSectorRegion(store adt.Store, region string, sectors bitfield.BitField) error {
+	regions, err := adt.AsMap(store, st.SectorRegions, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return xerrors.Errorf("failed to load sector regions: %w", err)
+	}
+	var existing bitfield.BitField
+	found, err := regions.Get(regionKey(region), &existing)
+	if err != nil {
+		return xerrors.Errorf("failed to load sectors in region %s: %w", region, err)
+	}
+	if found {
+		if sectors, err = bitfield.MergeBitFields(existing, sectors); err != nil {
+			return xerrors.Errorf("failed to merge sectors in region %s: %w", region, err)
+		}
+	}
+	if err := regions.Put(regionKey(region), sectors); err != nil {
+		return xerrors.Errorf("failed to store sectors in region %s: %w", region, err)
+	}
+	if st.SectorRegions, err = regions.Root(); err != nil {
+		return xerrors.Errorf("failed to flush sector regions: %w", err)
+	}
+	return nil
+}
+
+// Returns the declared regions of some sectors, each with those of the sectors declared to be stored in it.
+// Sectors without a declared region are omitted.
+func (st *State) LoadSectorRegions(store adt.Store, sectors bitfield.BitField) ([]SectorRegion, error) {
+	regions, err := adt.AsMap(store, st.SectorRegions, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load sector regions: %w", err)
+	}
+	var result []SectorRegion
+	var inRegion bitfield.BitField
+	if err := regions.ForEach(&inRegion, func(k string) error {
+		matched, err := bitfield.IntersectBitField(inRegion, sectors)
+		if err != nil {
+			return err
+		}
+		if empty, err := matched.IsEmpty(); err != nil {
+			return err
+		} else if !empty {
+			result = append(result, SectorRegion{Region: k, Sectors: matched})
+		}
+		return nil
+	}); err != nil {
+		return nil, xerrors.Errorf("failed to iterate sector regions: %w", err)
+	}
+	return result, nil
+}
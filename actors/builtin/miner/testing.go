@@ -23,6 +23,7 @@ type StateSummary struct {
 	Deals               map[abi.DealID]DealSummary
 	WindowPoStProofType abi.RegisteredPoStProof
 	DeadlineCronActive  bool
+	ProvingPeriodStart  abi.ChainEpoch
 }
 
 // Checks internal invariants of init state.
@@ -35,6 +36,7 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount) (
 		FaultyPower:         NewPowerPairZero(),
 		WindowPoStProofType: 0,
 		DeadlineCronActive:  st.DeadlineCronActive,
+		ProvingPeriodStart:  st.ProvingPeriodStart,
 	}
 
 	// Load data from linked structures.
@@ -76,6 +78,10 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount) (
 			allSectors[abi.SectorNumber(sno)] = &cpy
 			acc.Require(allocatedSectorsMap == nil || allocatedSectorsMap[uint64(sno)],
 				"on chain sector's sector number has not been allocated %d", sno)
+			acc.Require(sector.SealRandEpoch == NoSealRandEpoch || sector.SealRandEpoch < sector.Activation,
+				"sector %d seal randomness epoch %d not before activation %d", sno, sector.SealRandEpoch, sector.Activation)
+			acc.Require(sector.Pieces == nil || len(sector.DealIDs) == 0,
+				"sector %d has both deals and attested pieces", sno)
 
 			for _, dealID := range sector.DealIDs {
 				minerSummary.Deals[dealID] = DealSummary{
@@ -243,8 +249,7 @@ func CheckDeadlineStateInvariants(deadline *Deadline, store adt.Store, quant bui
 	// Check that we don't have any proofs proving partitions that are not in the snapshot.
 	proofsSnapshot, err := deadline.OptimisticProofsSnapshotArray(store)
 	acc.RequireNoError(err, "error loading proofs snapshot")
-	var proof WindowedPoSt
-	err = proofsSnapshot.ForEach(&proof, func(_ int64) error {
+	err = proofsSnapshot.ForEach(func(_ uint64, proof *WindowedPoSt) error {
 		err = proof.Partitions.ForEach(func(i uint64) error {
 			found, err := partitionsSnapshot.Get(i, &partition)
 			acc.RequireNoError(err, "error loading partition snapshot")
@@ -777,21 +782,19 @@ func CheckPreCommits(st *State, store adt.Store, allocatedSectors map[uint64]boo
 	}
 
 	precommitTotal := big.Zero()
-	if precommitted, err := adt.AsMap(store, st.PreCommittedSectors, builtin.DefaultHamtBitwidth); err != nil {
+	precommittedSectors := make(map[uint64]bool)
+	if precommitted, err := LoadPreCommitMap(store, st.PreCommittedSectors); err != nil {
 		acc.Addf("error loading precommitted sectors: %v", err)
 	} else {
-		var precommit SectorPreCommitOnChainInfo
-		err = precommitted.ForEach(&precommit, func(key string) error {
-			secNum, err := abi.ParseUIntKey(key)
-			if err != nil {
-				acc.Addf("error parsing pre-commit key as uint: %v", err)
-				return nil
-			}
-
+		err = precommitted.ForEach(func(sectorNo abi.SectorNumber, precommit *SectorPreCommitOnChainInfo) error {
+			secNum := uint64(sectorNo)
+			precommittedSectors[secNum] = true
 			acc.Require(allocatedSectors[secNum], "pre-committed sector number has not been allocated %d", secNum)
 
 			_, found := cleanUpEpochs[secNum]
 			acc.Require(found, "no clean up epoch for pre-commit at %d", precommit.PreCommitEpoch)
+			acc.Require(precommit.Pieces == nil || len(precommit.Info.DealIDs) == 0,
+				"pre-committed sector %d has both deals and attested pieces", secNum)
 
 			precommitTotal = big.Add(precommitTotal, precommit.PreCommitDeposit)
 			return nil
@@ -801,6 +804,22 @@ func CheckPreCommits(st *State, store adt.Store, allocatedSectors map[uint64]boo
 
 	acc.Require(st.PreCommitDeposits.Equals(precommitTotal),
 		"sum of precommit deposits %v does not equal recorded precommit deposit %v", precommitTotal, st.PreCommitDeposits)
+
+	if aggregators, err := adt.AsMap(store, st.PreCommitAggregators, builtin.DefaultHamtBitwidth); err != nil {
+		acc.Addf("error loading pre-commit aggregators: %v", err)
+	} else {
+		var aggregator addr.Address
+		err = aggregators.ForEach(&aggregator, func(key string) error {
+			secNum, err := abi.ParseUIntKey(key)
+			if err != nil {
+				return err
+			}
+			acc.Require(precommittedSectors[secNum], "aggregator %v designated for sector %d that is not pre-committed", aggregator, secNum)
+			acc.Require(aggregator.Protocol() == addr.ID, "aggregator %v designated for sector %d is not an ID address", aggregator, secNum)
+			return nil
+		})
+		acc.RequireNoError(err, "error iterating pre-commit aggregators")
+	}
 }
 
 // Selects a subset of sectors from a map by sector number.
@@ -61,3 +61,12 @@ const SectorQualityPrecision = 20
 
 // 1 NanoFIL
 var OneNanoFIL = big.NewInt(1_000_000_000)
+
+// PARAM_SPEC
+// Fraction of each penalty paid to the treasury actor rather than burnt.
+// Motivation: It allows networks to fund public goods from penalties. None by default.
+// Usage: It is used to split penalties between the treasury and the burnt funds actor.
+var PenaltyTreasuryShare = BigFrac{
+	Numerator:   big.Zero(),
+	Denominator: big.NewInt(1),
+}
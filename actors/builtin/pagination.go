@@ -0,0 +1,47 @@
+package builtin
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Parameters selecting a page of the results of a query method.
+// Query methods returning a list of results take these so that clients can handle pagination the same way for all.
+type PageParams struct {
+	Limit  uint64 // Maximum number of results to return, or zero for no limit
+	Cursor []byte // Cursor returned with the previous page, or empty for the first page
+}
+
+// Returned by a query method along with a page of results.
+type PageReturn struct {
+	NextCursor []byte // Cursor from which to request the next page, or empty if there are no more results
+	HasMore    bool   // Whether there are results after this page
+}
+
+// Selects the page, identified by page params, of a query's results, of which there are count in total.
+// Returns the index of the page's first result and one past its last, and the page info to return with them.
+// A cursor is an opaque encoding of the index of the first result of a page, so is valid only while the
+// results preceding it are unchanged.
+func Paginate(count uint64, params *PageParams) (start, end uint64, ret PageReturn, err error) {
+	if len(params.Cursor) > 0 {
+		var n int
+		start, n = binary.Uvarint(params.Cursor)
+		if n <= 0 || n != len(params.Cursor) {
+			return 0, 0, PageReturn{}, fmt.Errorf("malformed cursor %x", params.Cursor)
+		}
+		if start > count {
+			return 0, 0, PageReturn{}, fmt.Errorf("cursor %d beyond end of %d results", start, count)
+		}
+	}
+
+	end = count
+	if params.Limit > 0 && params.Limit < count-start {
+		end = start + params.Limit
+	}
+	if end < count {
+		buf := make([]byte, binary.MaxVarintLen64)
+		ret.NextCursor = buf[:binary.PutUvarint(buf, end)]
+		ret.HasMore = true
+	}
+	return start, end, ret, nil
+}
@@ -0,0 +1,65 @@
+package builtin_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+)
+
+func TestPaginate(t *testing.T) {
+	t.Run("no limit returns everything", func(t *testing.T) {
+		start, end, ret, err := builtin.Paginate(5, &builtin.PageParams{})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), start)
+		assert.Equal(t, uint64(5), end)
+		assert.False(t, ret.HasMore)
+		assert.Empty(t, ret.NextCursor)
+	})
+
+	t.Run("pages follow cursors to the end", func(t *testing.T) {
+		params := builtin.PageParams{Limit: 2}
+		var pages [][2]uint64
+		for {
+			start, end, ret, err := builtin.Paginate(5, &params)
+			require.NoError(t, err)
+			pages = append(pages, [2]uint64{start, end})
+			if !ret.HasMore {
+				assert.Empty(t, ret.NextCursor)
+				break
+			}
+			params.Cursor = ret.NextCursor
+		}
+		assert.Equal(t, [][2]uint64{{0, 2}, {2, 4}, {4, 5}}, pages)
+	})
+
+	t.Run("limit of exactly the remainder has no more", func(t *testing.T) {
+		_, end, ret, err := builtin.Paginate(4, &builtin.PageParams{Limit: 4})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), end)
+		assert.False(t, ret.HasMore)
+	})
+
+	t.Run("no results", func(t *testing.T) {
+		start, end, ret, err := builtin.Paginate(0, &builtin.PageParams{Limit: 3})
+		require.NoError(t, err)
+		assert.Equal(t, start, end)
+		assert.False(t, ret.HasMore)
+	})
+
+	t.Run("rejects malformed cursor", func(t *testing.T) {
+		_, _, _, err := builtin.Paginate(5, &builtin.PageParams{Cursor: []byte{0x80}})
+		assert.Error(t, err)
+		_, _, _, err = builtin.Paginate(5, &builtin.PageParams{Cursor: []byte{0x01, 0x02}})
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects cursor beyond the results", func(t *testing.T) {
+		_, _, ret, err := builtin.Paginate(10, &builtin.PageParams{Limit: 8})
+		require.NoError(t, err)
+		_, _, _, err = builtin.Paginate(5, &builtin.PageParams{Cursor: ret.NextCursor})
+		assert.Error(t, err)
+	})
+}
@@ -337,13 +337,7 @@ func (pca Actor) Collect(rt runtime.Runtime, _ *abi.EmptyValue) *abi.EmptyValue
 	}
 
 	// send ToSend to "To"
-	codeTo := rt.Send(
-		st.To,
-		builtin.MethodSend,
-		nil,
-		st.ToSend,
-		&builtin.Discard{},
-	)
+	codeTo := builtin.TransferFunds(rt, st.To, st.ToSend, builtin.TransferPayment)
 	builtin.RequireSuccess(rt, codeTo, "Failed to send funds to `To`")
 
 	// the remaining balance will be returned to "From" upon deletion.
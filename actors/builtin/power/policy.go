@@ -1,5 +1,11 @@
 package power
 
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+)
+
 // The number of miners that must meet the consensus minimum miner power before that minimum power is enforced
 // as a condition of leader election.
 // This ensures a network still functions before any miners reach that threshold.
@@ -10,3 +16,25 @@ const ConsensusMinerMinMiners = 4 // PARAM_SPEC
 // This limits the number of proof partitions we may need to load in the cron call path.
 // Onboarding 1EiB/year requires at least 32 prove-commits per epoch.
 const MaxMinerProveCommitsPerEpoch = 200 // PARAM_SPEC
+
+// The interval at which the total power is checkpointed, one proving period.
+const PowerCheckpointInterval = abi.ChainEpoch(builtin.EpochsInDay) // PARAM_SPEC
+
+// The number of recent checkpoints of total power retained in state.
+const PowerCheckpointHistory = 60 // PARAM_SPEC
+
+// The number of most recently reported consensus faults retained in state for each miner.
+const ConsensusFaultHistoryMax = 16 // PARAM_SPEC
+
+// Maximum number of deferred cron events the power actor dispatches to miners in one epoch.
+//
+// Many miners' deadlines can end at the same epoch. Events beyond this number are dispatched at the following
+// epoch(s), in order of scheduling, which spreads the cost of a busy epoch over the next few.
+var MaxMinerCronEventsPerEpoch = 2000 // PARAM_SPEC
+
+// Maximum number of epochs a cron event may be deferred by MaxMinerCronEventsPerEpoch.
+//
+// A miner's deadline cron tolerates being delivered late, but not by a whole challenge window
+// (miner.WPoStChallengeWindow), after which the next deadline's cron would be due. Events that have waited this
+// long are dispatched regardless of the cap.
+var MaxMinerCronEventDelay = abi.ChainEpoch(30*60/builtin.EpochDurationSeconds) - 1 // PARAM_SPEC
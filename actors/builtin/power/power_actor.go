@@ -41,6 +41,15 @@ func (a Actor) Exports() []interface{} {
 		7:                         nil, // deprecated
 		8:                         a.SubmitPoRepForBulkVerify,
 		9:                         a.CurrentTotalPower,
+		10:                        a.UpdateClaimedProofType,
+		11:                        a.TotalPowerAt,
+		12:                        a.ListClaims,
+		13:                        a.CronEventQueueStats,
+		14:                        a.RecordConsensusFaults,
+		15:                        a.ConsensusFaultHistory,
+		16:                        a.MinerEligibleForElection,
+		17:                        a.TotalPledge,
+		18:                        a.MinerPledge,
 	}
 }
 
@@ -69,6 +78,10 @@ type MinerConstructorParams struct {
 	WindowPoStProofType abi.RegisteredPoStProof
 	PeerId              abi.PeerID
 	Multiaddrs          []abi.Multiaddrs
+	// Whether the miner's proving periods are offset by ProvingPeriodOffset, rather than an offset assigned
+	// pseudo-randomly.
+	RequestProvingPeriodOffset bool
+	ProvingPeriodOffset        abi.ChainEpoch
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -92,6 +105,11 @@ type CreateMinerParams struct {
 	WindowPoStProofType abi.RegisteredPoStProof
 	Peer                abi.PeerID
 	Multiaddrs          []abi.Multiaddrs
+	ControlAddrs        []addr.Address
+	// Whether the miner's proving periods are offset by ProvingPeriodOffset from multiples of the proving period,
+	// rather than an offset assigned pseudo-randomly. The offset must be less than the proving period.
+	RequestProvingPeriodOffset bool
+	ProvingPeriodOffset        abi.ChainEpoch
 }
 
 //type CreateMinerReturn struct {
@@ -100,15 +118,20 @@ type CreateMinerParams struct {
 //}
 type CreateMinerReturn = power0.CreateMinerReturn
 
+// Creates a new miner actor, configured with its owner, worker and control addresses, peer info and, optionally,
+// the offset of its proving periods.
 func (a Actor) CreateMiner(rt Runtime, params *CreateMinerParams) *CreateMinerReturn {
 	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
 
 	ctorParams := MinerConstructorParams{
-		OwnerAddr:           params.Owner,
-		WorkerAddr:          params.Worker,
-		WindowPoStProofType: params.WindowPoStProofType,
-		PeerId:              params.Peer,
-		Multiaddrs:          params.Multiaddrs,
+		OwnerAddr:                  params.Owner,
+		WorkerAddr:                 params.Worker,
+		ControlAddrs:               params.ControlAddrs,
+		WindowPoStProofType:        params.WindowPoStProofType,
+		PeerId:                     params.Peer,
+		Multiaddrs:                 params.Multiaddrs,
+		RequestProvingPeriodOffset: params.RequestProvingPeriodOffset,
+		ProvingPeriodOffset:        params.ProvingPeriodOffset,
 	}
 	ctorParamBuf := new(bytes.Buffer)
 	err := ctorParams.MarshalCBOR(ctorParamBuf)
@@ -199,8 +222,11 @@ func (a Actor) EnrollCronEvent(rt Runtime, params *EnrollCronEventParams) *abi.E
 		events, err := adt.AsMultimap(adt.AsStore(rt), st.CronEventQueue, CronQueueHamtBitwidth, CronQueueAmtBitwidth)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load cron events")
 
-		err = st.appendCronEvent(events, params.EventEpoch, &minerEvent)
+		added, err := st.appendCronEvent(events, params.EventEpoch, &minerEvent)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to enroll cron event")
+		if !added {
+			rt.Log(rtt.INFO, "ignoring duplicate cron event for miner %v at epoch %d", minerAddr, params.EventEpoch)
+		}
 
 		st.CronEventQueue, err = events.Root()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush cron events")
@@ -226,6 +252,9 @@ func (a Actor) OnEpochTickEnd(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 		st.ThisEpochRawBytePower = rawBytePower
 		// we can now assume delta is one since cron is invoked on every epoch.
 		st.updateSmoothedEstimate(abi.ChainEpoch(1))
+
+		err := st.checkpointPower(adt.AsStore(rt), rt.CurrEpoch())
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to checkpoint total power")
 	})
 
 	// update network KPI in RewardActor
@@ -297,17 +326,20 @@ func (a Actor) SubmitPoRepForBulkVerify(rt Runtime, sealInfo *proof.SealVerifyIn
 
 // Changed since v0:
 // - QualityAdjPowerSmoothed is not a pointer
+// - ProofTypePower is added
 type CurrentTotalPowerReturn struct {
 	RawBytePower            abi.StoragePower
 	QualityAdjPower         abi.StoragePower
 	PledgeCollateral        abi.TokenAmount
 	QualityAdjPowerSmoothed smoothing.FilterEstimate
+	// Power committed by miners of each Window PoSt proof type, as of the current state.
+	ProofTypePower []ProofTypePower
 }
 
 // Returns the total power and pledge recorded by the power actor.
 // The returned values are frozen during the cron tick before this epoch
 // so that this method returns consistent values while processing all messages
-// of an epoch.
+// of an epoch. The power committed for each proof type is not frozen.
 func (a Actor) CurrentTotalPower(rt Runtime, _ *abi.EmptyValue) *CurrentTotalPowerReturn {
 	rt.ValidateImmediateCallerAcceptAny()
 	var st State
@@ -318,6 +350,322 @@ func (a Actor) CurrentTotalPower(rt Runtime, _ *abi.EmptyValue) *CurrentTotalPow
 		QualityAdjPower:         st.ThisEpochQualityAdjPower,
 		PledgeCollateral:        st.ThisEpochPledgeCollateral,
 		QualityAdjPowerSmoothed: st.ThisEpochQAPowerSmoothed,
+		ProofTypePower:          st.ProofTypePower,
+	}
+}
+
+type TotalPowerAtParams struct {
+	Epoch abi.ChainEpoch
+}
+
+type TotalPowerAtReturn struct {
+	CheckpointEpoch abi.ChainEpoch // Epoch of the checkpoint, at or before the requested epoch
+	RawBytePower    abi.StoragePower
+	QualityAdjPower abi.StoragePower
+}
+
+// Returns the total power recorded at the latest checkpoint at or before an epoch, i.e. the power in effect from
+// the epoch following the checkpoint.
+// Checkpoints are taken every PowerCheckpointInterval, and only the most recent PowerCheckpointHistory are retained,
+// so historical power may be referenced without access to historical state.
+func (a Actor) TotalPowerAt(rt Runtime, params *TotalPowerAtParams) *TotalPowerAtReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	if params.Epoch > rt.CurrEpoch() {
+		rt.Abortf(exitcode.ErrIllegalArgument, "epoch %d is after the current epoch %d", params.Epoch, rt.CurrEpoch())
+	}
+
+	var st State
+	rt.StateReadonly(&st)
+	checkpoint, found, err := st.PowerCheckpointAt(adt.AsStore(rt), params.Epoch)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load power checkpoints")
+	if !found {
+		rt.Abortf(exitcode.ErrNotFound, "no power checkpoint at or before epoch %d", params.Epoch)
+	}
+	return &TotalPowerAtReturn{
+		CheckpointEpoch: checkpoint.Epoch,
+		RawBytePower:    checkpoint.RawBytePower,
+		QualityAdjPower: checkpoint.QualityAdjPower,
+	}
+}
+
+type UpdateClaimedProofTypeParams struct {
+	WindowPoStProofType abi.RegisteredPoStProof
+}
+
+// Changes the Window PoSt proof type of the calling miner's claim, following an upgrade of the miner's proof type.
+// The claimed power is unchanged, but whether it meets the consensus minimum is re-evaluated for the new proof type.
+func (a Actor) UpdateClaimedProofType(rt Runtime, params *UpdateClaimedProofTypeParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
+	minerAddr := rt.Caller()
+	var st State
+	rt.StateTransaction(&st, func() {
+		claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claims")
+
+		err = st.setClaimProofType(claims, minerAddr, params.WindowPoStProofType)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to update claimed proof type to %d", params.WindowPoStProofType)
+
+		st.Claims, err = claims.Root()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush claims")
+	})
+	return nil
+}
+
+type ListClaimsParams struct {
+	Page builtin.PageParams
+}
+
+type MinerClaim struct {
+	Miner           addr.Address
+	RawBytePower    abi.StoragePower
+	QualityAdjPower abi.StoragePower
+}
+
+type ListClaimsReturn struct {
+	Claims []MinerClaim // Ordered as in the claims HAMT
+	Page   builtin.PageReturn
+}
+
+// Returns a page of the miners' power claims, so that the power of all miners may be enumerated.
+// Claims are listed in the order of the claims HAMT, which changes as miners are created or removed,
+// so a cursor is valid only while the set of miners is unchanged.
+func (a Actor) ListClaims(rt Runtime, params *ListClaimsParams) *ListClaimsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	var st State
+	rt.StateReadonly(&st)
+
+	claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, builtin.DefaultHamtBitwidth)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claims")
+
+	minerClaims := []MinerClaim{}
+	var claim Claim
+	err = claims.ForEach(&claim, func(key string) error {
+		miner, err := addr.NewFromBytes([]byte(key))
+		if err != nil {
+			return err
+		}
+		minerClaims = append(minerClaims, MinerClaim{
+			Miner:           miner,
+			RawBytePower:    claim.RawBytePower,
+			QualityAdjPower: claim.QualityAdjPower,
+		})
+		return nil
+	})
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to iterate claims")
+
+	start, end, page, err := builtin.Paginate(uint64(len(minerClaims)), &params.Page)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid page")
+	return &ListClaimsReturn{Claims: minerClaims[start:end], Page: page}
+}
+
+type CronEventQueueStatsReturn struct {
+	FirstCronEpoch abi.ChainEpoch // First epoch from which cron will look for queued events
+	EpochCount     uint64         // Number of epochs with queued events
+	EventCount     uint64         // Number of queued events
+	OrphanCount    uint64         // Number of queued events for miners without a claim
+}
+
+// Returns statistics of the cron event queue.
+func (a Actor) CronEventQueueStats(rt Runtime, _ *abi.EmptyValue) *CronEventQueueStatsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	var st State
+	rt.StateReadonly(&st)
+	store := adt.AsStore(rt)
+
+	events, err := adt.AsMultimap(store, st.CronEventQueue, CronQueueHamtBitwidth, CronQueueAmtBitwidth)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load cron events")
+	claims, err := adt.AsMap(store, st.Claims, builtin.DefaultHamtBitwidth)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claims")
+
+	ret := &CronEventQueueStatsReturn{FirstCronEpoch: st.FirstCronEpoch}
+	err = events.ForAll(func(_ string, arr *adt.Array) error {
+		ret.EpochCount++
+		var event CronEvent
+		return arr.ForEach(&event, func(_ int64) error {
+			ret.EventCount++
+			found, err := claims.Has(abi.AddrKey(event.MinerAddr))
+			if err != nil {
+				return err
+			}
+			if !found {
+				ret.OrphanCount++
+			}
+			return nil
+		})
+	})
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to iterate cron events")
+	return ret
+}
+
+type RecordConsensusFaultsParams struct {
+	Faults          []ConsensusFaultRecord
+	IneligibleUntil abi.ChainEpoch // Epoch at which the miner's ineligibility following these faults ends
+}
+
+// Records consensus faults reported against the calling miner, which has verified and penalized them.
+func (a Actor) RecordConsensusFaults(rt Runtime, params *RecordConsensusFaultsParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
+	minerAddr := rt.Caller()
+	currEpoch := rt.CurrEpoch()
+
+	if len(params.Faults) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no consensus faults to record")
+	}
+	for _, fault := range params.Faults {
+		if fault.Epoch >= currEpoch {
+			rt.Abortf(exitcode.ErrIllegalArgument, "consensus fault epoch %d not before current epoch %d", fault.Epoch, currEpoch)
+		}
+	}
+	if params.IneligibleUntil < currEpoch {
+		rt.Abortf(exitcode.ErrIllegalArgument, "ineligibility end %d before current epoch %d", params.IneligibleUntil, currEpoch)
+	}
+
+	var st State
+	rt.StateTransaction(&st, func() {
+		err := st.recordConsensusFaults(adt.AsStore(rt), minerAddr, params.Faults, currEpoch, params.IneligibleUntil)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to record consensus faults")
+	})
+	return nil
+}
+
+type ConsensusFaultHistoryParams struct {
+	Miner addr.Address
+}
+
+// Returns the consensus faults reported against a miner, which are empty if none have been.
+func (a Actor) ConsensusFaultHistory(rt Runtime, params *ConsensusFaultHistoryParams) *ConsensusFaultHistory {
+	rt.ValidateImmediateCallerAcceptAny()
+	minerAddr, ok := rt.ResolveAddress(params.Miner)
+	if !ok {
+		return &ConsensusFaultHistory{Faults: []ConsensusFaultRecord{}}
+	}
+
+	var st State
+	rt.StateReadonly(&st)
+	history, found, err := st.GetConsensusFaultHistory(adt.AsStore(rt), minerAddr)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load consensus fault history")
+	if !found {
+		return &ConsensusFaultHistory{Faults: []ConsensusFaultRecord{}}
+	}
+	return history
+}
+
+// Reason for which a miner is ineligible to be elected to produce a block.
+type IneligibilityReason uint64
+
+const (
+	IneligibilityNone           IneligibilityReason = iota // The miner is eligible
+	IneligibilityNoClaim                                   // The miner has no power claim
+	IneligibilityNoPower                                   // The miner has no quality-adjusted power
+	IneligibilityBelowMinPower                             // The miner's raw byte power is below the consensus minimum
+	IneligibilityConsensusFault                            // A consensus fault reported against the miner is active
+	IneligibilityFeeDebt                                   // The miner has unpaid fee debt
+)
+
+type MinerEligibleForElectionParams struct {
+	Miner addr.Address
+}
+
+type MinerEligibleForElectionReturn struct {
+	Eligible bool
+	Reason   IneligibilityReason // IneligibilityNone if eligible, otherwise the first failed check
+}
+
+// Checks whether a miner is eligible to be elected to produce a block at the current epoch.
+// All checks are evaluated against the current state, whereas block validation checks the minimum power
+// against the state at the Winning PoSt lookback epoch.
+// Consensus faults are checked against the history recorded by this actor, so faults reported before
+// the history was recorded are not considered.
+func (a Actor) MinerEligibleForElection(rt Runtime, params *MinerEligibleForElectionParams) *MinerEligibleForElectionReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	ineligible := func(reason IneligibilityReason) *MinerEligibleForElectionReturn {
+		return &MinerEligibleForElectionReturn{Eligible: false, Reason: reason}
+	}
+
+	minerAddr, ok := rt.ResolveAddress(params.Miner)
+	if !ok {
+		return ineligible(IneligibilityNoClaim)
+	}
+
+	var st State
+	rt.StateReadonly(&st)
+	store := adt.AsStore(rt)
+
+	claim, found, err := st.GetClaim(store, minerAddr)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claim for %v", minerAddr)
+	if !found {
+		return ineligible(IneligibilityNoClaim)
+	}
+	if claim.QualityAdjPower.LessThanEqual(big.Zero()) {
+		return ineligible(IneligibilityNoPower)
+	}
+
+	meetsMinimum, err := st.MinerNominalPowerMeetsConsensusMinimum(store, minerAddr)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check consensus minimum power of %v", minerAddr)
+	if !meetsMinimum {
+		return ineligible(IneligibilityBelowMinPower)
+	}
+
+	history, found, err := st.GetConsensusFaultHistory(store, minerAddr)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load consensus fault history")
+	if found && rt.CurrEpoch() <= history.IneligibleUntil {
+		return ineligible(IneligibilityConsensusFault)
+	}
+
+	breakdown := builtin.RequestMinerBalanceBreakdown(rt, minerAddr)
+	if breakdown.FeeDebt.GreaterThan(big.Zero()) {
+		return ineligible(IneligibilityFeeDebt)
+	}
+
+	return &MinerEligibleForElectionReturn{Eligible: true, Reason: IneligibilityNone}
+}
+
+type TotalPledgeReturn struct {
+	TotalPledgeCollateral     abi.TokenAmount // Pledge locked by all miners, as of the current state
+	ThisEpochPledgeCollateral abi.TokenAmount // Pledge locked by all miners, frozen during the cron tick before this epoch
+}
+
+// Returns the total pledge collateral locked by miners.
+func (a Actor) TotalPledge(rt Runtime, _ *abi.EmptyValue) *TotalPledgeReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	var st State
+	rt.StateReadonly(&st)
+	return &TotalPledgeReturn{
+		TotalPledgeCollateral:     st.TotalPledgeCollateral,
+		ThisEpochPledgeCollateral: st.ThisEpochPledgeCollateral,
+	}
+}
+
+type MinerPledgeParams struct {
+	Miner addr.Address
+}
+
+type MinerPledgeReturn struct {
+	InitialPledge     abi.TokenAmount // Initial pledge required by the miner's sectors
+	PreCommitDeposits abi.TokenAmount // Deposits for the miner's sectors pre-committed but not yet proven
+}
+
+// Returns the pledge requirement of a miner, as reported by the miner actor.
+// Aborts if the miner has no power claim.
+func (a Actor) MinerPledge(rt Runtime, params *MinerPledgeParams) *MinerPledgeReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	minerAddr, ok := rt.ResolveAddress(params.Miner)
+	if !ok {
+		rt.Abortf(exitcode.ErrNotFound, "failed to resolve address %v", params.Miner)
+	}
+
+	var st State
+	rt.StateReadonly(&st)
+	_, found, err := st.GetClaim(adt.AsStore(rt), minerAddr)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claim for %v", minerAddr)
+	if !found {
+		rt.Abortf(exitcode.ErrNotFound, "no claim for miner %v", minerAddr)
+	}
+
+	breakdown := builtin.RequestMinerBalanceBreakdown(rt, minerAddr)
+	return &MinerPledgeReturn{
+		InitialPledge:     breakdown.InitialPledge,
+		PreCommitDeposits: breakdown.PreCommitDeposits,
 	}
 }
 
@@ -435,11 +783,22 @@ func (a Actor) processDeferredCronEvents(rt Runtime) {
 		claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, builtin.DefaultHamtBitwidth)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claims")
 
+		// Events are dispatched in order of epoch, then of enrollment, up to a cap on the number of miners
+		// invoked this epoch. Events beyond the cap remain queued, to be dispatched first at the next epoch,
+		// unless they have already been deferred for the maximum delay.
+		remaining := MaxMinerCronEventsPerEpoch
+		nextEpoch := rtEpoch + 1
 		for epoch := st.FirstCronEpoch; epoch <= rtEpoch; epoch++ {
 			epochEvents, err := loadCronEvents(events, epoch)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load cron events at %v", epoch)
 
-			for _, evt := range epochEvents {
+			overdue := rtEpoch-epoch >= MaxMinerCronEventDelay
+			var deferred []CronEvent
+			for i, evt := range epochEvents {
+				if remaining <= 0 && !overdue {
+					deferred = epochEvents[i:]
+					break
+				}
 				// refuse to process proofs for miner with no claim
 				found, err := claims.Has(abi.AddrKey(evt.MinerAddr))
 				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to look up claim")
@@ -448,15 +807,26 @@ func (a Actor) processDeferredCronEvents(rt Runtime) {
 					continue
 				}
 				cronEvents = append(cronEvents, evt)
+				remaining--
 			}
 
 			if len(epochEvents) > 0 {
 				err = events.RemoveAll(epochKey(epoch))
 				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to clear cron events at %v", epoch)
 			}
+
+			if len(deferred) > 0 {
+				for i := range deferred {
+					err = events.Add(epochKey(epoch), &deferred[i])
+					builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to defer cron event at %v", epoch)
+				}
+				rt.Log(rtt.INFO, "deferring %d cron events from epoch %d after dispatching %d", len(deferred), epoch, len(cronEvents))
+				nextEpoch = epoch
+				break
+			}
 		}
 
-		st.FirstCronEpoch = rtEpoch + 1
+		st.FirstCronEpoch = nextEpoch
 
 		st.CronEventQueue, err = events.Root()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush events")
@@ -486,6 +856,7 @@ func (a Actor) processDeferredCronEvents(rt Runtime) {
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claims")
 
 			// Remove miner claim and leave miner frozen
+			deleted := make(map[addr.Address]bool)
 			for _, minerAddr := range failedMinerCrons {
 				found, err := st.deleteClaim(claims, minerAddr)
 				if err != nil {
@@ -498,10 +869,24 @@ func (a Actor) processDeferredCronEvents(rt Runtime) {
 
 				// Decrement miner count to keep stats consistent.
 				st.MinerCount--
+				deleted[minerAddr] = true
 			}
 
 			st.Claims, err = claims.Root()
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush claims")
+
+			// Drop any other events queued for the frozen miners, which would be skipped anyway.
+			if len(deleted) > 0 {
+				events, err := adt.AsMultimap(adt.AsStore(rt), st.CronEventQueue, CronQueueHamtBitwidth, CronQueueAmtBitwidth)
+				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load cron events")
+				removed, err := removeCronEvents(events, deleted)
+				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to remove cron events of deleted miners")
+				if removed > 0 {
+					rt.Log(rtt.INFO, "removed %d cron events of %d deleted miners", removed, len(deleted))
+				}
+				st.CronEventQueue, err = events.Root()
+				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush cron events")
+			}
 		})
 	}
 }
@@ -1,6 +1,7 @@
 package power
 
 import (
+	"bytes"
 	"fmt"
 	"reflect"
 
@@ -13,6 +14,7 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v5/actors/runtime"
 	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
 	"github.com/filecoin-project/specs-actors/v5/actors/util/smoothing"
 )
@@ -66,6 +68,15 @@ type State struct {
 	Claims cid.Cid // Map, HAMT[address]Claim
 
 	ProofValidationBatch *cid.Cid // Multimap, (HAMT[Address]AMT[SealVerifyInfo])
+
+	// Recent checkpoints of total power, taken every PowerCheckpointInterval.
+	PowerCheckpoints cid.Cid // PowerCheckpoints
+
+	// Power committed by miners of each Window PoSt proof type having any, in increasing order of proof type.
+	ProofTypePower []ProofTypePower
+
+	// Consensus faults reported against each miner.
+	ConsensusFaults cid.Cid // Map, HAMT[address]ConsensusFaultHistory
 }
 
 type Claim struct {
@@ -79,11 +90,46 @@ type Claim struct {
 	QualityAdjPower abi.StoragePower
 }
 
+// The total power committed by miners of a Window PoSt proof type, including miners below the consensus minimum.
+type ProofTypePower struct {
+	WindowPoStProofType abi.RegisteredPoStProof
+	RawBytePower        abi.StoragePower
+	QualityAdjPower     abi.StoragePower
+}
+
+// A consensus fault committed by a miner.
+type ConsensusFaultRecord struct {
+	Epoch abi.ChainEpoch // Epoch of the fault, that of the higher of the faulty blocks
+	Type  runtime.ConsensusFaultType
+}
+
+// The consensus faults reported against a miner.
+type ConsensusFaultHistory struct {
+	// The most recently reported ConsensusFaultHistoryMax faults, in order of report.
+	Faults []ConsensusFaultRecord
+	// Epoch at which faults were last reported.
+	LastReportEpoch abi.ChainEpoch
+	// Epoch at which the miner's ineligibility to be elected or faulted again, following the last report, ends.
+	IneligibleUntil abi.ChainEpoch
+}
+
 type CronEvent struct {
 	MinerAddr       addr.Address
 	CallbackPayload []byte
 }
 
+// The total power in effect from the epoch following a checkpoint.
+type PowerCheckpoint struct {
+	Epoch           abi.ChainEpoch
+	RawBytePower    abi.StoragePower
+	QualityAdjPower abi.StoragePower
+}
+
+// Up to PowerCheckpointHistory checkpoints of total power, in increasing order of epoch.
+type PowerCheckpoints struct {
+	Checkpoints []PowerCheckpoint
+}
+
 func ConstructState(store adt.Store) (*State, error) {
 	emptyClaimsMapCid, err := adt.StoreEmptyMap(store, builtin.DefaultHamtBitwidth)
 	if err != nil {
@@ -93,6 +139,14 @@ func ConstructState(store adt.Store) (*State, error) {
 	if err != nil {
 		return nil, xerrors.Errorf("failed to create empty multimap: %w", err)
 	}
+	emptyCheckpointsCid, err := store.Put(store.Context(), &PowerCheckpoints{})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create empty power checkpoints: %w", err)
+	}
+	emptyConsensusFaultsMapCid, err := adt.StoreEmptyMap(store, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create empty map: %w", err)
+	}
 
 	return &State{
 		TotalRawBytePower:         abi.NewStoragePower(0),
@@ -109,6 +163,8 @@ func ConstructState(store adt.Store) (*State, error) {
 		Claims:                    emptyClaimsMapCid,
 		MinerCount:                0,
 		MinerAboveMinPowerCount:   0,
+		PowerCheckpoints:          emptyCheckpointsCid,
+		ConsensusFaults:           emptyConsensusFaultsMapCid,
 	}, nil
 }
 
@@ -177,6 +233,99 @@ func (st *State) GetClaim(s adt.Store, a addr.Address) (*Claim, bool, error) {
 	return getClaim(claims, a)
 }
 
+func (st *State) LoadPowerCheckpoints(s adt.Store) (*PowerCheckpoints, error) {
+	var checkpoints PowerCheckpoints
+	if err := s.Get(s.Context(), st.PowerCheckpoints, &checkpoints); err != nil {
+		return nil, xerrors.Errorf("failed to load power checkpoints: %w", err)
+	}
+	return &checkpoints, nil
+}
+
+// Returns the latest checkpoint of total power taken at or before an epoch, if any.
+func (st *State) PowerCheckpointAt(s adt.Store, epoch abi.ChainEpoch) (*PowerCheckpoint, bool, error) {
+	checkpoints, err := st.LoadPowerCheckpoints(s)
+	if err != nil {
+		return nil, false, err
+	}
+	for i := len(checkpoints.Checkpoints) - 1; i >= 0; i-- {
+		if checkpoints.Checkpoints[i].Epoch <= epoch {
+			return &checkpoints.Checkpoints[i], true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// Returns a miner's consensus fault history, and whether any faults have been reported against the miner.
+func (st *State) GetConsensusFaultHistory(s adt.Store, miner addr.Address) (*ConsensusFaultHistory, bool, error) {
+	histories, err := adt.AsMap(s, st.ConsensusFaults, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, false, xerrors.Errorf("failed to load consensus faults: %w", err)
+	}
+	var history ConsensusFaultHistory
+	found, err := histories.Get(abi.AddrKey(miner), &history)
+	if err != nil {
+		return nil, false, xerrors.Errorf("failed to get consensus fault history for %v: %w", miner, err)
+	}
+	return &history, found, nil
+}
+
+// Records consensus faults reported against a miner at an epoch, retaining only the most recent
+// ConsensusFaultHistoryMax faults.
+func (st *State) recordConsensusFaults(s adt.Store, miner addr.Address, faults []ConsensusFaultRecord, reportEpoch, ineligibleUntil abi.ChainEpoch) error {
+	histories, err := adt.AsMap(s, st.ConsensusFaults, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return xerrors.Errorf("failed to load consensus faults: %w", err)
+	}
+	var history ConsensusFaultHistory
+	if _, err := histories.Get(abi.AddrKey(miner), &history); err != nil {
+		return xerrors.Errorf("failed to get consensus fault history for %v: %w", miner, err)
+	}
+
+	history.Faults = append(history.Faults, faults...)
+	if len(history.Faults) > ConsensusFaultHistoryMax {
+		history.Faults = history.Faults[len(history.Faults)-ConsensusFaultHistoryMax:]
+	}
+	history.LastReportEpoch = reportEpoch
+	history.IneligibleUntil = ineligibleUntil
+	if err := histories.Put(abi.AddrKey(miner), &history); err != nil {
+		return xerrors.Errorf("failed to put consensus fault history for %v: %w", miner, err)
+	}
+
+	if st.ConsensusFaults, err = histories.Root(); err != nil {
+		return xerrors.Errorf("failed to flush consensus faults: %w", err)
+	}
+	return nil
+}
+
+// Records a checkpoint of the total power at an epoch, if none has yet been taken in the epoch's checkpoint
+// interval, discarding the oldest checkpoint beyond PowerCheckpointHistory.
+func (st *State) checkpointPower(s adt.Store, epoch abi.ChainEpoch) error {
+	checkpoints, err := st.LoadPowerCheckpoints(s)
+	if err != nil {
+		return err
+	}
+	if n := len(checkpoints.Checkpoints); n > 0 {
+		intervalStart := epoch - epoch%PowerCheckpointInterval
+		if checkpoints.Checkpoints[n-1].Epoch >= intervalStart {
+			return nil
+		}
+	}
+
+	checkpoints.Checkpoints = append(checkpoints.Checkpoints, PowerCheckpoint{
+		Epoch:           epoch,
+		RawBytePower:    st.ThisEpochRawBytePower,
+		QualityAdjPower: st.ThisEpochQualityAdjPower,
+	})
+	if len(checkpoints.Checkpoints) > PowerCheckpointHistory {
+		checkpoints.Checkpoints = checkpoints.Checkpoints[len(checkpoints.Checkpoints)-PowerCheckpointHistory:]
+	}
+	st.PowerCheckpoints, err = s.Put(s.Context(), checkpoints)
+	if err != nil {
+		return xerrors.Errorf("failed to save power checkpoints: %w", err)
+	}
+	return nil
+}
+
 func (st *State) addToClaim(claims *adt.Map, miner addr.Address, power abi.StoragePower, qapower abi.StoragePower) error {
 	oldClaim, ok, err := getClaim(claims, miner)
 	if err != nil {
@@ -189,6 +338,9 @@ func (st *State) addToClaim(claims *adt.Map, miner addr.Address, power abi.Stora
 	// TotalBytes always update directly
 	st.TotalQABytesCommitted = big.Add(st.TotalQABytesCommitted, qapower)
 	st.TotalBytesCommitted = big.Add(st.TotalBytesCommitted, power)
+	if err := st.addProofTypePower(oldClaim.WindowPoStProofType, power, qapower); err != nil {
+		return err
+	}
 
 	newClaim := Claim{
 		WindowPoStProofType: oldClaim.WindowPoStProofType,
@@ -232,6 +384,85 @@ func (st *State) addToClaim(claims *adt.Map, miner addr.Address, power abi.Stora
 	return setClaim(claims, miner, &newClaim)
 }
 
+// Changes the proof type of a miner's claim, updating the count and total power of miners meeting the
+// consensus minimum if the minimum for the new proof type differs.
+func (st *State) setClaimProofType(claims *adt.Map, miner addr.Address, windowPoStProof abi.RegisteredPoStProof) error {
+	oldClaim, ok, err := getClaim(claims, miner)
+	if err != nil {
+		return fmt.Errorf("failed to get claim: %w", err)
+	}
+	if !ok {
+		return exitcode.ErrNotFound.Wrapf("no claim for actor %v", miner)
+	}
+
+	oldMinPower, err := builtin.ConsensusMinerMinPower(oldClaim.WindowPoStProofType)
+	if err != nil {
+		return fmt.Errorf("could not get consensus miner min power: %w", err)
+	}
+	newMinPower, err := builtin.ConsensusMinerMinPower(windowPoStProof)
+	if err != nil {
+		return exitcode.ErrIllegalArgument.Wrapf("could not get consensus miner min power: %w", err)
+	}
+
+	prevBelow := oldClaim.RawBytePower.LessThan(oldMinPower)
+	nowBelow := oldClaim.RawBytePower.LessThan(newMinPower)
+	if prevBelow && !nowBelow {
+		st.MinerAboveMinPowerCount++
+		st.TotalQualityAdjPower = big.Add(st.TotalQualityAdjPower, oldClaim.QualityAdjPower)
+		st.TotalRawBytePower = big.Add(st.TotalRawBytePower, oldClaim.RawBytePower)
+	} else if !prevBelow && nowBelow {
+		st.MinerAboveMinPowerCount--
+		st.TotalQualityAdjPower = big.Sub(st.TotalQualityAdjPower, oldClaim.QualityAdjPower)
+		st.TotalRawBytePower = big.Sub(st.TotalRawBytePower, oldClaim.RawBytePower)
+	}
+
+	// Move the claimed power to the new proof type's total.
+	if err := st.addProofTypePower(oldClaim.WindowPoStProofType, oldClaim.RawBytePower.Neg(), oldClaim.QualityAdjPower.Neg()); err != nil {
+		return err
+	}
+	if err := st.addProofTypePower(windowPoStProof, oldClaim.RawBytePower, oldClaim.QualityAdjPower); err != nil {
+		return err
+	}
+
+	newClaim := Claim{
+		WindowPoStProofType: windowPoStProof,
+		RawBytePower:        oldClaim.RawBytePower,
+		QualityAdjPower:     oldClaim.QualityAdjPower,
+	}
+	return setClaim(claims, miner, &newClaim)
+}
+
+// Adds power, which may be negative, to the total for a proof type, removing the total once it reaches zero.
+func (st *State) addProofTypePower(proof abi.RegisteredPoStProof, power, qapower abi.StoragePower) error {
+	if power.IsZero() && qapower.IsZero() {
+		return nil
+	}
+	i := 0
+	for i < len(st.ProofTypePower) && st.ProofTypePower[i].WindowPoStProofType < proof {
+		i++
+	}
+	if i == len(st.ProofTypePower) || st.ProofTypePower[i].WindowPoStProofType != proof {
+		st.ProofTypePower = append(st.ProofTypePower, ProofTypePower{})
+		copy(st.ProofTypePower[i+1:], st.ProofTypePower[i:])
+		st.ProofTypePower[i] = ProofTypePower{
+			WindowPoStProofType: proof,
+			RawBytePower:        big.Zero(),
+			QualityAdjPower:     big.Zero(),
+		}
+	}
+
+	total := &st.ProofTypePower[i]
+	total.RawBytePower = big.Add(total.RawBytePower, power)
+	total.QualityAdjPower = big.Add(total.QualityAdjPower, qapower)
+	if total.RawBytePower.LessThan(big.Zero()) || total.QualityAdjPower.LessThan(big.Zero()) {
+		return xerrors.Errorf("negative power %v, %v for proof type %d", total.RawBytePower, total.QualityAdjPower, proof)
+	}
+	if total.RawBytePower.IsZero() && total.QualityAdjPower.IsZero() {
+		st.ProofTypePower = append(st.ProofTypePower[:i], st.ProofTypePower[i+1:]...)
+	}
+	return nil
+}
+
 func (st *State) updateStatsForNewMiner(windowPoStProof abi.RegisteredPoStProof) error {
 	minPower, err := builtin.ConsensusMinerMinPower(windowPoStProof)
 	if err != nil {
@@ -281,17 +512,130 @@ func (st *State) addPledgeTotal(amount abi.TokenAmount) {
 	st.TotalPledgeCollateral = big.Add(st.TotalPledgeCollateral, amount)
 }
 
-func (st *State) appendCronEvent(events *adt.Multimap, epoch abi.ChainEpoch, event *CronEvent) error {
+// Appends an event to the queue at an epoch, unless an identical event is already queued at that epoch.
+// Returns whether the event was appended.
+func (st *State) appendCronEvent(events *adt.Multimap, epoch abi.ChainEpoch, event *CronEvent) (bool, error) {
+	queued, err := loadCronEvents(events, epoch)
+	if err != nil {
+		return false, xerrors.Errorf("failed to load cron events at epoch %v: %w", epoch, err)
+	}
+	if containsCronEvent(queued, event) {
+		return false, nil
+	}
+
 	// if event is in past, alter FirstCronEpoch so it will be found.
 	if epoch < st.FirstCronEpoch {
 		st.FirstCronEpoch = epoch
 	}
 
 	if err := events.Add(epochKey(epoch), event); err != nil {
-		return xerrors.Errorf("failed to store cron event at epoch %v for miner %v: %w", epoch, event, err)
+		return false, xerrors.Errorf("failed to store cron event at epoch %v for miner %v: %w", epoch, event, err)
 	}
 
-	return nil
+	return true, nil
+}
+
+// Removes queued cron events for miners without a claim, and all but the first of identical events queued at
+// the same epoch, such as may have been left by earlier versions of the actor.
+// Returns the number of events removed.
+func (st *State) CompactCronEventQueue(s adt.Store) (uint64, error) {
+	events, err := adt.AsMultimap(s, st.CronEventQueue, CronQueueHamtBitwidth, CronQueueAmtBitwidth)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to load cron events: %w", err)
+	}
+	claims, err := adt.AsMap(s, st.Claims, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to load claims: %w", err)
+	}
+
+	removed, err := filterCronEvents(events, func(queued []CronEvent) ([]CronEvent, error) {
+		var kept []CronEvent
+		for i := range queued {
+			if containsCronEvent(kept, &queued[i]) {
+				continue
+			}
+			found, err := claims.Has(abi.AddrKey(queued[i].MinerAddr))
+			if err != nil {
+				return nil, xerrors.Errorf("failed to look up claim: %w", err)
+			}
+			if found {
+				kept = append(kept, queued[i])
+			}
+		}
+		return kept, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if st.CronEventQueue, err = events.Root(); err != nil {
+		return 0, xerrors.Errorf("failed to flush cron events: %w", err)
+	}
+	return removed, nil
+}
+
+// Removes all queued cron events of some miners, returning the number removed.
+func removeCronEvents(events *adt.Multimap, miners map[addr.Address]bool) (uint64, error) {
+	return filterCronEvents(events, func(queued []CronEvent) ([]CronEvent, error) {
+		var kept []CronEvent
+		for _, event := range queued {
+			if !miners[event.MinerAddr] {
+				kept = append(kept, event)
+			}
+		}
+		return kept, nil
+	})
+}
+
+// Replaces the events queued at each epoch with those kept by a filter, preserving their order.
+// Returns the number of events removed.
+func filterCronEvents(events *adt.Multimap, filter func(queued []CronEvent) ([]CronEvent, error)) (uint64, error) {
+	var epochs []abi.ChainEpoch
+	if err := events.ForAll(func(k string, _ *adt.Array) error {
+		epoch, err := abi.ParseIntKey(k)
+		if err != nil {
+			return xerrors.Errorf("invalid cron epoch key %x: %w", k, err)
+		}
+		epochs = append(epochs, abi.ChainEpoch(epoch))
+		return nil
+	}); err != nil {
+		return 0, xerrors.Errorf("failed to iterate cron events: %w", err)
+	}
+
+	removed := uint64(0)
+	for _, epoch := range epochs {
+		queued, err := loadCronEvents(events, epoch)
+		if err != nil {
+			return 0, xerrors.Errorf("failed to load cron events at epoch %v: %w", epoch, err)
+		}
+		kept, err := filter(queued)
+		if err != nil {
+			return 0, err
+		}
+		if len(kept) == len(queued) {
+			continue
+		}
+
+		removed += uint64(len(queued) - len(kept))
+		if err := events.RemoveAll(epochKey(epoch)); err != nil {
+			return 0, xerrors.Errorf("failed to clear cron events at epoch %v: %w", epoch, err)
+		}
+		for i := range kept {
+			if err := events.Add(epochKey(epoch), &kept[i]); err != nil {
+				return 0, xerrors.Errorf("failed to store cron event at epoch %v: %w", epoch, err)
+			}
+		}
+	}
+	return removed, nil
+}
+
+func containsCronEvent(events []CronEvent, event *CronEvent) bool {
+	for _, e := range events {
+		if e.MinerAddr == event.MinerAddr && bytes.Equal(e.CallbackPayload, event.CallbackPayload) {
+			return true
+		}
+	}
+	return false
 }
 
 func (st *State) updateSmoothedEstimate(delta abi.ChainEpoch) {
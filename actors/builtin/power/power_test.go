@@ -20,6 +20,7 @@ import (
 	"github.com/filecoin-project/specs-actors/v5/actors/builtin/market"
 	mineract "github.com/filecoin-project/specs-actors/v5/actors/builtin/miner"
 	"github.com/filecoin-project/specs-actors/v5/actors/builtin/power"
+	"github.com/filecoin-project/specs-actors/v5/actors/runtime"
 	"github.com/filecoin-project/specs-actors/v5/actors/runtime/proof"
 	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
 	"github.com/filecoin-project/specs-actors/v5/support/mock"
@@ -73,6 +74,51 @@ func TestConstruction(t *testing.T) {
 		verifyEmptyMap(t, rt, st.CronEventQueue)
 		actor.checkState(rt)
 	})
+
+	t.Run("create miner passes full configuration to miner constructor", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		worker := tutil.NewIDAddr(t, 102)
+		controls := []addr.Address{tutil.NewIDAddr(t, 104), tutil.NewIDAddr(t, 105)}
+		mAddrs := []abi.Multiaddrs{{1}, {2}}
+		rt.SetCaller(owner, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
+
+		ctorParams := &power.MinerConstructorParams{
+			OwnerAddr:                  owner,
+			WorkerAddr:                 worker,
+			ControlAddrs:               controls,
+			WindowPoStProofType:        abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
+			PeerId:                     abi.PeerID("miner"),
+			Multiaddrs:                 mAddrs,
+			RequestProvingPeriodOffset: true,
+			ProvingPeriodOffset:        100,
+		}
+		buf := new(bytes.Buffer)
+		require.NoError(t, ctorParams.MarshalCBOR(buf))
+		execParams := &initact.ExecParams{
+			CodeCID:           builtin.StorageMinerActorCodeID,
+			ConstructorParams: buf.Bytes(),
+		}
+		rt.ExpectSend(builtin.InitActorAddr, builtin.MethodsInit.Exec, execParams, big.Zero(),
+			&initact.ExecReturn{IDAddress: miner, RobustAddress: actr}, exitcode.Ok)
+
+		rt.Call(actor.CreateMiner, &power.CreateMinerParams{
+			Owner:                      owner,
+			Worker:                     worker,
+			WindowPoStProofType:        abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
+			Peer:                       abi.PeerID("miner"),
+			Multiaddrs:                 mAddrs,
+			ControlAddrs:               controls,
+			RequestProvingPeriodOffset: true,
+			ProvingPeriodOffset:        100,
+		})
+		rt.Verify()
+
+		assert.Equal(t, int64(1), getState(rt).MinerCount)
+		actor.checkState(rt)
+	})
 }
 
 func TestCreateMinerFailures(t *testing.T) {
@@ -126,6 +172,65 @@ func TestCreateMinerFailures(t *testing.T) {
 	})
 }
 
+func TestListClaims(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	miners := []addr.Address{tutil.NewIDAddr(t, 111), tutil.NewIDAddr(t, 112), tutil.NewIDAddr(t, 113)}
+
+	setup := func(t *testing.T) (*mock.Runtime, *spActorHarness) {
+		rt, ac := basicPowerSetup(t)
+		for i, miner := range miners {
+			ac.createMinerBasic(rt, owner, owner, miner)
+			ac.updateClaimedPower(rt, miner, big.NewInt(int64(100*(i+1))), big.NewInt(int64(200*(i+1))))
+		}
+		return rt, ac
+	}
+
+	t.Run("lists all claims", func(t *testing.T) {
+		rt, ac := setup(t)
+		ret := ac.listClaims(rt, builtin.PageParams{})
+		assert.False(t, ret.Page.HasMore)
+		require.Len(t, ret.Claims, len(miners))
+		for _, c := range ret.Claims {
+			claim := ac.getClaim(rt, c.Miner)
+			assert.Equal(t, claim.RawBytePower, c.RawBytePower)
+			assert.Equal(t, claim.QualityAdjPower, c.QualityAdjPower)
+		}
+		ac.checkState(rt)
+	})
+
+	t.Run("pages through claims", func(t *testing.T) {
+		rt, ac := setup(t)
+		all := ac.listClaims(rt, builtin.PageParams{}).Claims
+
+		var paged []power.MinerClaim
+		params := builtin.PageParams{Limit: 2}
+		for {
+			ret := ac.listClaims(rt, params)
+			paged = append(paged, ret.Claims...)
+			if !ret.Page.HasMore {
+				break
+			}
+			params.Cursor = ret.Page.NextCursor
+		}
+		assert.Equal(t, all, paged)
+	})
+
+	t.Run("lists no claims before any miner is created", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+		ret := ac.listClaims(rt, builtin.PageParams{Limit: 10})
+		assert.Empty(t, ret.Claims)
+		assert.False(t, ret.Page.HasMore)
+	})
+
+	t.Run("rejects malformed cursor", func(t *testing.T) {
+		rt, ac := setup(t)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "invalid page", func() {
+			rt.Call(ac.ListClaims, &power.ListClaimsParams{Page: builtin.PageParams{Cursor: []byte{0x80}}})
+		})
+	})
+}
+
 func TestUpdateClaimedPowerFailures(t *testing.T) {
 	rawDelta := big.NewInt(100)
 	qaDelta := big.NewInt(200)
@@ -164,6 +269,151 @@ func TestUpdateClaimedPowerFailures(t *testing.T) {
 	})
 }
 
+func TestUpdateClaimedProofType(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	miner := tutil.NewIDAddr(t, 111)
+	newProofType := abi.RegisteredPoStProof_StackedDrgWindow64GiBV1
+
+	t.Run("changes proof type and retains power", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+		ac.createMinerBasic(rt, owner, owner, miner)
+		ac.updateClaimedPower(rt, miner, big.NewInt(100), big.NewInt(200))
+
+		ac.updateClaimedProofType(rt, miner, newProofType)
+		claim := ac.getClaim(rt, miner)
+		assert.Equal(t, newProofType, claim.WindowPoStProofType)
+		assert.Equal(t, big.NewInt(100), claim.RawBytePower)
+		assert.Equal(t, big.NewInt(200), claim.QualityAdjPower)
+		ac.checkState(rt)
+	})
+
+	t.Run("fails if claim does not exist for caller", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+		rt.SetCaller(miner, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			rt.Call(ac.UpdateClaimedProofType, &power.UpdateClaimedProofTypeParams{WindowPoStProofType: newProofType})
+		})
+		rt.Verify()
+	})
+}
+
+func TestProofTypePower(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	miner1 := tutil.NewIDAddr(t, 111)
+	miner2 := tutil.NewIDAddr(t, 112)
+	miner3 := tutil.NewIDAddr(t, 113)
+	proof32 := abi.RegisteredPoStProof_StackedDrgWindow32GiBV1
+	proof64 := abi.RegisteredPoStProof_StackedDrgWindow64GiBV1
+
+	setup := func(t *testing.T) (*mock.Runtime, *spActorHarness) {
+		rt, ac := basicPowerSetup(t)
+		ac.createMiner(rt, owner, owner, miner1, tutil.NewActorAddr(t, "m1"), abi.PeerID("m1"), nil, proof64, big.Zero())
+		ac.createMiner(rt, owner, owner, miner2, tutil.NewActorAddr(t, "m2"), abi.PeerID("m2"), nil, proof32, big.Zero())
+		ac.createMiner(rt, owner, owner, miner3, tutil.NewActorAddr(t, "m3"), abi.PeerID("m3"), nil, proof32, big.Zero())
+		return rt, ac
+	}
+
+	t.Run("sums committed power by proof type", func(t *testing.T) {
+		rt, ac := setup(t)
+		assert.Empty(t, getState(rt).ProofTypePower)
+
+		ac.updateClaimedPower(rt, miner1, big.NewInt(100), big.NewInt(1000))
+		ac.updateClaimedPower(rt, miner2, big.NewInt(10), big.NewInt(20))
+		ac.updateClaimedPower(rt, miner3, big.NewInt(30), big.NewInt(40))
+
+		expected := []power.ProofTypePower{
+			{WindowPoStProofType: proof32, RawBytePower: big.NewInt(40), QualityAdjPower: big.NewInt(60)},
+			{WindowPoStProofType: proof64, RawBytePower: big.NewInt(100), QualityAdjPower: big.NewInt(1000)},
+		}
+		assert.Equal(t, expected, getState(rt).ProofTypePower)
+		assert.Equal(t, expected, ac.currentPowerTotal(rt).ProofTypePower)
+		ac.checkState(rt)
+
+		// A proof type's total is removed once none of its miners have power.
+		ac.updateClaimedPower(rt, miner1, big.NewInt(-100), big.NewInt(-1000))
+		assert.Equal(t, expected[:1], getState(rt).ProofTypePower)
+		ac.checkState(rt)
+	})
+
+	t.Run("moves power with a miner's proof type", func(t *testing.T) {
+		rt, ac := setup(t)
+		ac.updateClaimedPower(rt, miner1, big.NewInt(100), big.NewInt(1000))
+		ac.updateClaimedPower(rt, miner2, big.NewInt(10), big.NewInt(20))
+
+		ac.updateClaimedProofType(rt, miner2, proof64)
+		assert.Equal(t, []power.ProofTypePower{
+			{WindowPoStProofType: proof64, RawBytePower: big.NewInt(110), QualityAdjPower: big.NewInt(1020)},
+		}, getState(rt).ProofTypePower)
+		ac.checkState(rt)
+	})
+}
+
+func TestTotalPowerAt(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	miner := tutil.NewIDAddr(t, 111)
+
+	t.Run("checkpoints power once per interval", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+		ac.createMinerBasic(rt, owner, owner, miner)
+		ac.updateClaimedPower(rt, miner, big.NewInt(100), big.NewInt(200))
+		ac.onEpochTickEnd(rt, 1, big.NewInt(100), nil, nil)
+
+		ret := ac.totalPowerAt(rt, 1)
+		assert.Equal(t, abi.ChainEpoch(1), ret.CheckpointEpoch)
+		assert.Equal(t, big.NewInt(100), ret.RawBytePower)
+		assert.Equal(t, big.NewInt(200), ret.QualityAdjPower)
+
+		// No checkpoint is taken later in the same interval.
+		ac.updateClaimedPower(rt, miner, big.NewInt(50), big.NewInt(50))
+		ac.onEpochTickEnd(rt, 2, big.NewInt(150), nil, nil)
+		ret = ac.totalPowerAt(rt, 2)
+		assert.Equal(t, abi.ChainEpoch(1), ret.CheckpointEpoch)
+		assert.Equal(t, big.NewInt(100), ret.RawBytePower)
+
+		// The next interval's first tick takes a checkpoint.
+		nextEpoch := power.PowerCheckpointInterval + 5
+		ac.onEpochTickEnd(rt, nextEpoch, big.NewInt(150), nil, nil)
+		ret = ac.totalPowerAt(rt, nextEpoch)
+		assert.Equal(t, nextEpoch, ret.CheckpointEpoch)
+		assert.Equal(t, big.NewInt(150), ret.RawBytePower)
+		assert.Equal(t, big.NewInt(250), ret.QualityAdjPower)
+
+		ret = ac.totalPowerAt(rt, nextEpoch-1)
+		assert.Equal(t, abi.ChainEpoch(1), ret.CheckpointEpoch)
+		ac.checkState(rt)
+	})
+
+	t.Run("retains limited history", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+		for i := 0; i <= power.PowerCheckpointHistory; i++ {
+			ac.onEpochTickEnd(rt, abi.ChainEpoch(i)*power.PowerCheckpointInterval, big.Zero(), nil, nil)
+		}
+		st := getState(rt)
+		checkpoints, err := st.LoadPowerCheckpoints(rt.AdtStore())
+		require.NoError(t, err)
+		assert.Len(t, checkpoints.Checkpoints, power.PowerCheckpointHistory)
+
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrNotFound, "no power checkpoint", func() {
+			rt.Call(ac.TotalPowerAt, &power.TotalPowerAtParams{Epoch: 0})
+		})
+		assert.Equal(t, power.PowerCheckpointInterval, ac.totalPowerAt(rt, power.PowerCheckpointInterval).CheckpointEpoch)
+		ac.checkState(rt)
+	})
+
+	t.Run("rejects future epoch", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+		ac.onEpochTickEnd(rt, 1, big.Zero(), nil, nil)
+
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "after the current epoch", func() {
+			rt.Call(ac.TotalPowerAt, &power.TotalPowerAtParams{Epoch: 2})
+		})
+	})
+}
+
 func TestEnrollCronEpoch(t *testing.T) {
 	owner := tutil.NewBLSAddr(t, 0)
 	miner := tutil.NewIDAddr(t, 101)
@@ -239,6 +489,21 @@ func TestEnrollCronEpoch(t *testing.T) {
 		ac.checkState(rt)
 	})
 
+	t.Run("ignores duplicate event", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+		ac.createMinerBasic(rt, owner, owner, miner)
+
+		ac.enrollCronEvent(rt, miner, 1, []byte("hello"))
+		ac.enrollCronEvent(rt, miner, 1, []byte("hello"))
+		rt.ExpectLogsContain("ignoring duplicate cron event for miner t0101 at epoch 1")
+		require.Len(t, ac.getEnrolledCronTicks(rt, 1), 1)
+
+		// The same event at another epoch is not a duplicate.
+		ac.enrollCronEvent(rt, miner, 2, []byte("hello"))
+		require.Len(t, ac.getEnrolledCronTicks(rt, 2), 1)
+		ac.checkState(rt)
+	})
+
 	t.Run("fails if epoch is negative", func(t *testing.T) {
 		rt, ac := basicPowerSetup(t)
 
@@ -248,6 +513,276 @@ func TestEnrollCronEpoch(t *testing.T) {
 	})
 }
 
+func TestConsensusFaultHistory(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	miner := tutil.NewIDAddr(t, 111)
+
+	t.Run("records and returns faults", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+		ac.createMinerBasic(rt, owner, owner, miner)
+		rt.SetEpoch(100)
+
+		faults := []power.ConsensusFaultRecord{
+			{Epoch: 90, Type: runtime.ConsensusFaultDoubleForkMining},
+			{Epoch: 95, Type: runtime.ConsensusFaultTimeOffsetMining},
+		}
+		ac.recordConsensusFaults(rt, miner, faults, 1000)
+
+		history := ac.consensusFaultHistory(rt, miner)
+		assert.Equal(t, faults, history.Faults)
+		assert.Equal(t, abi.ChainEpoch(100), history.LastReportEpoch)
+		assert.Equal(t, abi.ChainEpoch(1000), history.IneligibleUntil)
+		ac.checkState(rt)
+	})
+
+	t.Run("retains limited history", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+		ac.createMinerBasic(rt, owner, owner, miner)
+
+		for i := 1; i <= power.ConsensusFaultHistoryMax+2; i++ {
+			rt.SetEpoch(abi.ChainEpoch(i * 10))
+			fault := power.ConsensusFaultRecord{Epoch: abi.ChainEpoch(i*10 - 1), Type: runtime.ConsensusFaultParentGrinding}
+			ac.recordConsensusFaults(rt, miner, []power.ConsensusFaultRecord{fault}, abi.ChainEpoch(i*10+5))
+		}
+
+		history := ac.consensusFaultHistory(rt, miner)
+		require.Len(t, history.Faults, power.ConsensusFaultHistoryMax)
+		assert.Equal(t, abi.ChainEpoch(29), history.Faults[0].Epoch)
+		assert.Equal(t, abi.ChainEpoch((power.ConsensusFaultHistoryMax+2)*10+5), history.IneligibleUntil)
+		ac.checkState(rt)
+	})
+
+	t.Run("returns empty history for miner without faults", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+		ac.createMinerBasic(rt, owner, owner, miner)
+
+		history := ac.consensusFaultHistory(rt, miner)
+		assert.Empty(t, history.Faults)
+		assert.Equal(t, abi.ChainEpoch(0), history.IneligibleUntil)
+
+		history = ac.consensusFaultHistory(rt, tutil.NewActorAddr(t, "unknown"))
+		assert.Empty(t, history.Faults)
+	})
+
+	t.Run("rejects invalid faults", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+		ac.createMinerBasic(rt, owner, owner, miner)
+		rt.SetEpoch(100)
+
+		record := func(faults []power.ConsensusFaultRecord, ineligibleUntil abi.ChainEpoch) {
+			rt.SetCaller(miner, builtin.StorageMinerActorCodeID)
+			rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+			rt.Call(ac.RecordConsensusFaults, &power.RecordConsensusFaultsParams{Faults: faults, IneligibleUntil: ineligibleUntil})
+		}
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "no consensus faults", func() {
+			record(nil, 1000)
+		})
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "not before current epoch", func() {
+			record([]power.ConsensusFaultRecord{{Epoch: 100}}, 1000)
+		})
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "before current epoch", func() {
+			record([]power.ConsensusFaultRecord{{Epoch: 90}}, 99)
+		})
+	})
+
+	t.Run("rejects caller that is not a miner", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+		rt.SetCaller(owner, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(ac.RecordConsensusFaults, &power.RecordConsensusFaultsParams{})
+		})
+	})
+}
+
+func TestMinerEligibleForElection(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	miner := tutil.NewIDAddr(t, 111)
+	noDebt := big.Zero()
+
+	setup := func(t *testing.T) (*mock.Runtime, *spActorHarness) {
+		rt, ac := basicPowerSetup(t)
+		ac.createMinerBasic(rt, owner, owner, miner)
+		minPower, err := builtin.ConsensusMinerMinPower(ac.windowPoStProof)
+		require.NoError(t, err)
+		ac.updateClaimedPower(rt, miner, minPower, minPower)
+		rt.SetEpoch(100)
+		return rt, ac
+	}
+
+	t.Run("miner meeting all conditions is eligible", func(t *testing.T) {
+		rt, ac := setup(t)
+		ret := ac.minerEligibleForElection(rt, miner, &noDebt)
+		assert.True(t, ret.Eligible)
+		assert.Equal(t, power.IneligibilityNone, ret.Reason)
+		ac.checkState(rt)
+	})
+
+	t.Run("miner without claim is ineligible", func(t *testing.T) {
+		rt, ac := setup(t)
+		ret := ac.minerEligibleForElection(rt, tutil.NewIDAddr(t, 112), nil)
+		assert.False(t, ret.Eligible)
+		assert.Equal(t, power.IneligibilityNoClaim, ret.Reason)
+	})
+
+	t.Run("miner without power is ineligible", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+		ac.createMinerBasic(rt, owner, owner, miner)
+		ret := ac.minerEligibleForElection(rt, miner, nil)
+		assert.Equal(t, power.IneligibilityNoPower, ret.Reason)
+	})
+
+	t.Run("miner below min power is ineligible once enough miners meet it", func(t *testing.T) {
+		rt, ac := setup(t)
+		minPower, err := builtin.ConsensusMinerMinPower(ac.windowPoStProof)
+		require.NoError(t, err)
+		for i := 1; i < power.ConsensusMinerMinMiners; i++ {
+			other := tutil.NewIDAddr(t, 111+uint64(i))
+			ac.createMinerBasic(rt, owner, owner, other)
+			ac.updateClaimedPower(rt, other, minPower, minPower)
+		}
+		small := tutil.NewIDAddr(t, 200)
+		ac.createMinerBasic(rt, owner, owner, small)
+		ac.updateClaimedPower(rt, small, big.NewInt(1), big.NewInt(1))
+
+		ret := ac.minerEligibleForElection(rt, small, nil)
+		assert.Equal(t, power.IneligibilityBelowMinPower, ret.Reason)
+		ret = ac.minerEligibleForElection(rt, miner, &noDebt)
+		assert.True(t, ret.Eligible)
+	})
+
+	t.Run("miner with active consensus fault is ineligible", func(t *testing.T) {
+		rt, ac := setup(t)
+		ac.recordConsensusFaults(rt, miner, []power.ConsensusFaultRecord{
+			{Epoch: 90, Type: runtime.ConsensusFaultDoubleForkMining},
+		}, 200)
+
+		ret := ac.minerEligibleForElection(rt, miner, nil)
+		assert.Equal(t, power.IneligibilityConsensusFault, ret.Reason)
+
+		rt.SetEpoch(200)
+		ret = ac.minerEligibleForElection(rt, miner, nil)
+		assert.Equal(t, power.IneligibilityConsensusFault, ret.Reason)
+
+		rt.SetEpoch(201)
+		ret = ac.minerEligibleForElection(rt, miner, &noDebt)
+		assert.True(t, ret.Eligible)
+	})
+
+	t.Run("miner with fee debt is ineligible", func(t *testing.T) {
+		rt, ac := setup(t)
+		debt := abi.NewTokenAmount(1)
+		ret := ac.minerEligibleForElection(rt, miner, &debt)
+		assert.False(t, ret.Eligible)
+		assert.Equal(t, power.IneligibilityFeeDebt, ret.Reason)
+	})
+}
+
+func TestPledgeQueries(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	miner := tutil.NewIDAddr(t, 111)
+
+	t.Run("total pledge", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+		ac.createMinerBasic(rt, owner, owner, miner)
+		ac.updatePledgeTotal(rt, miner, abi.NewTokenAmount(1e6))
+
+		ret := ac.totalPledge(rt)
+		assert.Equal(t, abi.NewTokenAmount(1e6), ret.TotalPledgeCollateral)
+		assert.Equal(t, big.Zero(), ret.ThisEpochPledgeCollateral)
+		ac.checkState(rt)
+	})
+
+	t.Run("miner pledge", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+		ac.createMinerBasic(rt, owner, owner, miner)
+
+		rt.ExpectValidateCallerAny()
+		rt.ExpectSend(miner, builtin.MethodsMiner.GetBalanceBreakdown, nil, big.Zero(), &builtin.MinerBalanceBreakdown{
+			Balance:           abi.NewTokenAmount(1e7),
+			Available:         abi.NewTokenAmount(1e6),
+			PreCommitDeposits: abi.NewTokenAmount(2e6),
+			InitialPledge:     abi.NewTokenAmount(3e6),
+			LockedFunds:       abi.NewTokenAmount(4e6),
+			FeeDebt:           big.Zero(),
+		}, exitcode.Ok)
+		ret := rt.Call(ac.MinerPledge, &power.MinerPledgeParams{Miner: miner}).(*power.MinerPledgeReturn)
+		rt.Verify()
+		assert.Equal(t, abi.NewTokenAmount(3e6), ret.InitialPledge)
+		assert.Equal(t, abi.NewTokenAmount(2e6), ret.PreCommitDeposits)
+	})
+
+	t.Run("miner pledge fails for miner without claim", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			rt.Call(ac.MinerPledge, &power.MinerPledgeParams{Miner: miner})
+		})
+		rt.Verify()
+	})
+}
+
+func TestCronEventQueueCompaction(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	miner1 := tutil.NewIDAddr(t, 111)
+	miner2 := tutil.NewIDAddr(t, 112)
+
+	// Sets up a queue with an orphaned event at epoch 1 and a duplicate at epoch 2,
+	// as may have been left by earlier versions of the actor.
+	setup := func(t *testing.T) (*mock.Runtime, *spActorHarness) {
+		rt, ac := basicPowerSetup(t)
+		ac.createMinerBasic(rt, owner, owner, miner1)
+		ac.createMinerBasic(rt, owner, owner, miner2)
+		ac.enrollCronEvent(rt, miner1, 1, []byte("a"))
+		ac.enrollCronEvent(rt, miner2, 1, []byte("a"))
+		ac.enrollCronEvent(rt, miner2, 2, []byte("b"))
+		ac.deleteClaim(rt, miner1)
+
+		st := getState(rt)
+		events, err := adt.AsMultimap(rt.AdtStore(), st.CronEventQueue, power.CronQueueHamtBitwidth, power.CronQueueAmtBitwidth)
+		require.NoError(t, err)
+		require.NoError(t, events.Add(abi.IntKey(2), &power.CronEvent{MinerAddr: miner2, CallbackPayload: []byte("b")}))
+		st.CronEventQueue, err = events.Root()
+		require.NoError(t, err)
+		rt.ReplaceState(st)
+		return rt, ac
+	}
+
+	t.Run("reports queue statistics", func(t *testing.T) {
+		rt, ac := setup(t)
+		assert.Equal(t, &power.CronEventQueueStatsReturn{
+			FirstCronEpoch: 0,
+			EpochCount:     2,
+			EventCount:     4,
+			OrphanCount:    1,
+		}, ac.cronEventQueueStats(rt))
+	})
+
+	t.Run("removes orphaned and duplicate events", func(t *testing.T) {
+		rt, ac := setup(t)
+		st := getState(rt)
+		removed, err := st.CompactCronEventQueue(rt.AdtStore())
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), removed)
+		rt.ReplaceState(st)
+
+		events := ac.getEnrolledCronTicks(rt, 1)
+		require.Len(t, events, 1)
+		assert.Equal(t, miner2, events[0].MinerAddr)
+		events = ac.getEnrolledCronTicks(rt, 2)
+		require.Len(t, events, 1)
+		assert.Equal(t, []byte("b"), events[0].CallbackPayload)
+
+		assert.Equal(t, &power.CronEventQueueStatsReturn{
+			FirstCronEpoch: 0,
+			EpochCount:     2,
+			EventCount:     2,
+			OrphanCount:    0,
+		}, ac.cronEventQueueStats(rt))
+		ac.checkState(rt)
+	})
+}
+
 func TestPowerAndPledgeAccounting(t *testing.T) {
 	actor := newHarness(t)
 	owner := tutil.NewIDAddr(t, 101)
@@ -622,6 +1157,99 @@ func TestCron(t *testing.T) {
 		actor.checkState(rt)
 	})
 
+	t.Run("events beyond the per-epoch cap are deferred to the next epoch in order", func(t *testing.T) {
+		prevMax := power.MaxMinerCronEventsPerEpoch
+		power.MaxMinerCronEventsPerEpoch = 2
+		defer func() { power.MaxMinerCronEventsPerEpoch = prevMax }()
+
+		miner3 := tutil.NewIDAddr(t, 104)
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		actor.createMinerBasic(rt, owner, owner, miner1)
+		actor.createMinerBasic(rt, owner, owner, miner2)
+		actor.createMinerBasic(rt, owner, owner, miner3)
+
+		rt.SetEpoch(1)
+		actor.enrollCronEvent(rt, miner1, 2, []byte{0x1, 0x2})
+		actor.enrollCronEvent(rt, miner2, 2, []byte{0x2, 0x2})
+		actor.enrollCronEvent(rt, miner3, 2, []byte{0x3, 0x2})
+		actor.enrollCronEvent(rt, miner1, 3, []byte{0x1, 0x3})
+
+		// The first two events due at epoch 2 are dispatched, and the third is deferred.
+		expectedRawBytePower := big.NewInt(0)
+		rt.SetEpoch(2)
+		rt.ExpectValidateCallerAddr(builtin.CronActorAddr)
+		rt.ExpectSend(miner1, builtin.MethodsMiner.OnDeferredCronEvent, builtin.CBORBytes([]byte{0x1, 0x2}), big.Zero(), nil, exitcode.Ok)
+		rt.ExpectSend(miner2, builtin.MethodsMiner.OnDeferredCronEvent, builtin.CBORBytes([]byte{0x2, 0x2}), big.Zero(), nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.UpdateNetworkKPI, &expectedRawBytePower, big.Zero(), nil, exitcode.Ok)
+		rt.SetCaller(builtin.CronActorAddr, builtin.CronActorCodeID)
+		rt.ExpectBatchVerifySeals(nil, nil, nil)
+		rt.Call(actor.Actor.OnEpochTickEnd, nil)
+		rt.Verify()
+		assert.Equal(t, abi.ChainEpoch(2), getState(rt).FirstCronEpoch)
+
+		// The deferred event is dispatched ahead of the event due at epoch 3.
+		rt.SetEpoch(3)
+		rt.ExpectValidateCallerAddr(builtin.CronActorAddr)
+		rt.ExpectSend(miner3, builtin.MethodsMiner.OnDeferredCronEvent, builtin.CBORBytes([]byte{0x3, 0x2}), big.Zero(), nil, exitcode.Ok)
+		rt.ExpectSend(miner1, builtin.MethodsMiner.OnDeferredCronEvent, builtin.CBORBytes([]byte{0x1, 0x3}), big.Zero(), nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.UpdateNetworkKPI, &expectedRawBytePower, big.Zero(), nil, exitcode.Ok)
+		rt.SetCaller(builtin.CronActorAddr, builtin.CronActorCodeID)
+		rt.ExpectBatchVerifySeals(nil, nil, nil)
+		rt.Call(actor.Actor.OnEpochTickEnd, nil)
+		rt.Verify()
+		assert.Equal(t, abi.ChainEpoch(4), getState(rt).FirstCronEpoch)
+		actor.checkState(rt)
+	})
+
+	t.Run("events deferred for the maximum delay are dispatched beyond the cap", func(t *testing.T) {
+		require.Less(t, int64(power.MaxMinerCronEventDelay), int64(mineract.WPoStChallengeWindow))
+
+		prevMax := power.MaxMinerCronEventsPerEpoch
+		power.MaxMinerCronEventsPerEpoch = 2
+		defer func() { power.MaxMinerCronEventsPerEpoch = prevMax }()
+
+		miner3 := tutil.NewIDAddr(t, 104)
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		actor.createMinerBasic(rt, owner, owner, miner1)
+		actor.createMinerBasic(rt, owner, owner, miner2)
+		actor.createMinerBasic(rt, owner, owner, miner3)
+
+		rt.SetEpoch(1)
+		actor.enrollCronEvent(rt, miner1, 2, []byte{0x1, 0x2})
+		actor.enrollCronEvent(rt, miner2, 2, []byte{0x2, 0x2})
+		actor.enrollCronEvent(rt, miner3, 2, []byte{0x3, 0x2})
+		actor.enrollCronEvent(rt, miner1, 3, []byte{0x1, 0x3})
+
+		// Cron next runs a challenge window later. All three events due at epoch 2 have waited the maximum delay,
+		// so are dispatched despite the cap, while the event due at epoch 3 is deferred.
+		expectedRawBytePower := big.NewInt(0)
+		rt.SetEpoch(2 + power.MaxMinerCronEventDelay)
+		rt.ExpectValidateCallerAddr(builtin.CronActorAddr)
+		rt.ExpectSend(miner1, builtin.MethodsMiner.OnDeferredCronEvent, builtin.CBORBytes([]byte{0x1, 0x2}), big.Zero(), nil, exitcode.Ok)
+		rt.ExpectSend(miner2, builtin.MethodsMiner.OnDeferredCronEvent, builtin.CBORBytes([]byte{0x2, 0x2}), big.Zero(), nil, exitcode.Ok)
+		rt.ExpectSend(miner3, builtin.MethodsMiner.OnDeferredCronEvent, builtin.CBORBytes([]byte{0x3, 0x2}), big.Zero(), nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.UpdateNetworkKPI, &expectedRawBytePower, big.Zero(), nil, exitcode.Ok)
+		rt.SetCaller(builtin.CronActorAddr, builtin.CronActorCodeID)
+		rt.ExpectBatchVerifySeals(nil, nil, nil)
+		rt.Call(actor.Actor.OnEpochTickEnd, nil)
+		rt.Verify()
+		assert.Equal(t, abi.ChainEpoch(3), getState(rt).FirstCronEpoch)
+
+		// The deferred event is dispatched at the next epoch, still within a challenge window of being due.
+		rt.SetEpoch(3 + power.MaxMinerCronEventDelay)
+		rt.ExpectValidateCallerAddr(builtin.CronActorAddr)
+		rt.ExpectSend(miner1, builtin.MethodsMiner.OnDeferredCronEvent, builtin.CBORBytes([]byte{0x1, 0x3}), big.Zero(), nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.UpdateNetworkKPI, &expectedRawBytePower, big.Zero(), nil, exitcode.Ok)
+		rt.SetCaller(builtin.CronActorAddr, builtin.CronActorCodeID)
+		rt.ExpectBatchVerifySeals(nil, nil, nil)
+		rt.Call(actor.Actor.OnEpochTickEnd, nil)
+		rt.Verify()
+		assert.Equal(t, abi.ChainEpoch(4+power.MaxMinerCronEventDelay), getState(rt).FirstCronEpoch)
+		actor.checkState(rt)
+	})
+
 	t.Run("event scheduled in past called next round", func(t *testing.T) {
 		rt := builder.Build(t)
 		actor.constructAndVerify(rt)
@@ -723,6 +1351,8 @@ func TestCron(t *testing.T) {
 
 		actor.enrollCronEvent(rt, miner1, 2, []byte{})
 		actor.enrollCronEvent(rt, miner2, 2, []byte{})
+		actor.enrollCronEvent(rt, miner1, 5, []byte{})
+		actor.enrollCronEvent(rt, miner2, 5, []byte{})
 
 		rawPow, err := builtin.ConsensusMinerMinPower(abi.RegisteredPoStProof_StackedDrgWindow32GiBV1)
 		require.NoError(t, err)
@@ -766,6 +1396,12 @@ func TestCron(t *testing.T) {
 		// miner count has been reduced to 1
 		assert.Equal(t, int64(1), st.MinerCount)
 
+		// the failed miner's later events are removed
+		rt.ExpectLogsContain("removed 1 cron events of 1 deleted miners")
+		events := actor.getEnrolledCronTicks(rt, 5)
+		require.Len(t, events, 1)
+		assert.Equal(t, miner2, events[0].MinerAddr)
+
 		// Next epoch, only the reward actor is invoked
 		rt.SetEpoch(3)
 		rt.ExpectValidateCallerAddr(builtin.CronActorAddr)
@@ -1275,6 +1911,13 @@ func (h *spActorHarness) updateClaimedPower(rt *mock.Runtime, miner addr.Address
 	}
 }
 
+func (h *spActorHarness) updateClaimedProofType(rt *mock.Runtime, miner addr.Address, proofType abi.RegisteredPoStProof) {
+	rt.SetCaller(miner, builtin.StorageMinerActorCodeID)
+	rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+	rt.Call(h.UpdateClaimedProofType, &power.UpdateClaimedProofTypeParams{WindowPoStProofType: proofType})
+	rt.Verify()
+}
+
 func (h *spActorHarness) updatePledgeTotal(rt *mock.Runtime, miner addr.Address, delta abi.TokenAmount) {
 	st := getState(rt)
 	prev := st.TotalPledgeCollateral
@@ -1296,6 +1939,67 @@ func (h *spActorHarness) currentPowerTotal(rt *mock.Runtime) *power.CurrentTotal
 	return ret
 }
 
+func (h *spActorHarness) totalPowerAt(rt *mock.Runtime, epoch abi.ChainEpoch) *power.TotalPowerAtReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.TotalPowerAt, &power.TotalPowerAtParams{Epoch: epoch}).(*power.TotalPowerAtReturn)
+	rt.Verify()
+	return ret
+}
+
+func (h *spActorHarness) listClaims(rt *mock.Runtime, page builtin.PageParams) *power.ListClaimsReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.ListClaims, &power.ListClaimsParams{Page: page}).(*power.ListClaimsReturn)
+	rt.Verify()
+	return ret
+}
+
+func (h *spActorHarness) cronEventQueueStats(rt *mock.Runtime) *power.CronEventQueueStatsReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.CronEventQueueStats, nil).(*power.CronEventQueueStatsReturn)
+	rt.Verify()
+	return ret
+}
+
+func (h *spActorHarness) recordConsensusFaults(rt *mock.Runtime, miner addr.Address, faults []power.ConsensusFaultRecord, ineligibleUntil abi.ChainEpoch) {
+	rt.SetCaller(miner, builtin.StorageMinerActorCodeID)
+	rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+	rt.Call(h.RecordConsensusFaults, &power.RecordConsensusFaultsParams{Faults: faults, IneligibleUntil: ineligibleUntil})
+	rt.Verify()
+}
+
+func (h *spActorHarness) consensusFaultHistory(rt *mock.Runtime, miner addr.Address) *power.ConsensusFaultHistory {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.ConsensusFaultHistory, &power.ConsensusFaultHistoryParams{Miner: miner}).(*power.ConsensusFaultHistory)
+	rt.Verify()
+	return ret
+}
+
+func (h *spActorHarness) totalPledge(rt *mock.Runtime) *power.TotalPledgeReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.TotalPledge, nil).(*power.TotalPledgeReturn)
+	rt.Verify()
+	return ret
+}
+
+// Checks the eligibility of a miner. If feeDebt is non-nil, expects the miner's balance breakdown
+// to be requested and to report that fee debt.
+func (h *spActorHarness) minerEligibleForElection(rt *mock.Runtime, miner addr.Address, feeDebt *abi.TokenAmount) *power.MinerEligibleForElectionReturn {
+	if feeDebt != nil {
+		rt.ExpectSend(miner, builtin.MethodsMiner.GetBalanceBreakdown, nil, big.Zero(), &builtin.MinerBalanceBreakdown{
+			Balance:           big.Zero(),
+			Available:         big.Zero(),
+			PreCommitDeposits: big.Zero(),
+			InitialPledge:     big.Zero(),
+			LockedFunds:       big.Zero(),
+			FeeDebt:           *feeDebt,
+		}, exitcode.Ok)
+	}
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.MinerEligibleForElection, &power.MinerEligibleForElectionParams{Miner: miner}).(*power.MinerEligibleForElectionReturn)
+	rt.Verify()
+	return ret
+}
+
 func (h *spActorHarness) enrollCronEvent(rt *mock.Runtime, miner addr.Address, epoch abi.ChainEpoch, payload []byte) {
 	rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
 	rt.SetCaller(miner, builtin.StorageMinerActorCodeID)
@@ -47,6 +47,8 @@ func CheckStateInvariants(st *State, store adt.Store) (*StateSummary, *builtin.M
 	crons := CheckCronInvariants(st, store, acc)
 	claims := CheckClaimInvariants(st, store, acc)
 	proofs := CheckProofValidationInvariants(st, store, claims, acc)
+	CheckPowerCheckpointInvariants(st, store, acc)
+	CheckConsensusFaultInvariants(st, store, acc)
 
 	return &StateSummary{
 		Crons:  crons,
@@ -75,7 +77,10 @@ func CheckCronInvariants(st *State, store adt.Store, acc *builtin.MessageAccumul
 			epoch, st.FirstCronEpoch)
 
 		var event CronEvent
+		var epochEvents []CronEvent
 		return arr.ForEach(&event, func(i int64) error {
+			acc.Require(!containsCronEvent(epochEvents, &event), "duplicate cron event for miner %v at epoch %d", event.MinerAddr, epoch)
+			epochEvents = append(epochEvents, event)
 			byAddress[event.MinerAddr] = append(byAddress[event.MinerAddr], MinerCronEvent{
 				Epoch:   abi.ChainEpoch(epoch),
 				Payload: event.CallbackPayload,
@@ -102,6 +107,7 @@ func CheckClaimInvariants(st *State, store adt.Store, acc *builtin.MessageAccumu
 	rawPower := abi.NewStoragePower(0)
 	qaPower := abi.NewStoragePower(0)
 	claimsWithSufficientPowerCount := int64(0)
+	proofTypePower := map[abi.RegisteredPoStProof]ProofTypePower{}
 	var claim Claim
 	err = claims.ForEach(&claim, func(key string) error {
 		addr, err := address.NewFromBytes([]byte(key))
@@ -111,6 +117,15 @@ func CheckClaimInvariants(st *State, store adt.Store, acc *builtin.MessageAccumu
 		byAddress[addr] = claim
 		committedRawPower = big.Add(committedRawPower, claim.RawBytePower)
 		committedQAPower = big.Add(committedQAPower, claim.QualityAdjPower)
+		if !claim.RawBytePower.IsZero() || !claim.QualityAdjPower.IsZero() {
+			total, ok := proofTypePower[claim.WindowPoStProofType]
+			if !ok {
+				total = ProofTypePower{claim.WindowPoStProofType, big.Zero(), big.Zero()}
+			}
+			total.RawBytePower = big.Add(total.RawBytePower, claim.RawBytePower)
+			total.QualityAdjPower = big.Add(total.QualityAdjPower, claim.QualityAdjPower)
+			proofTypePower[claim.WindowPoStProofType] = total
+		}
 
 		minPower, err := builtin.ConsensusMinerMinPower(claim.WindowPoStProofType)
 		acc.Require(err == nil, "could not get consensus miner min power for miner %v: %v", addr, err)
@@ -134,6 +149,20 @@ func CheckClaimInvariants(st *State, store adt.Store, acc *builtin.MessageAccumu
 		"sum of qa power in claims %v does not match recorded qa power committed %v",
 		committedQAPower, st.TotalQABytesCommitted)
 
+	acc.Require(len(proofTypePower) == len(st.ProofTypePower),
+		"claims have power of %d proof types, but %d are recorded", len(proofTypePower), len(st.ProofTypePower))
+	for i, recorded := range st.ProofTypePower {
+		acc.Require(i == 0 || st.ProofTypePower[i-1].WindowPoStProofType < recorded.WindowPoStProofType,
+			"power of proof type %d out of order", recorded.WindowPoStProofType)
+		expected, ok := proofTypePower[recorded.WindowPoStProofType]
+		acc.Require(ok, "power %v recorded for proof type %d with no claimed power", recorded.RawBytePower, recorded.WindowPoStProofType)
+		if ok {
+			acc.Require(expected.RawBytePower.Equals(recorded.RawBytePower) && expected.QualityAdjPower.Equals(recorded.QualityAdjPower),
+				"power of proof type %d in claims %v, %v does not match recorded %v, %v", recorded.WindowPoStProofType,
+				expected.RawBytePower, expected.QualityAdjPower, recorded.RawBytePower, recorded.QualityAdjPower)
+		}
+	}
+
 	acc.Require(claimsWithSufficientPowerCount == st.MinerAboveMinPowerCount,
 		"claims with sufficient power %d does not match MinerAboveMinPowerCount %d",
 		claimsWithSufficientPowerCount, st.MinerAboveMinPowerCount)
@@ -146,6 +175,26 @@ func CheckClaimInvariants(st *State, store adt.Store, acc *builtin.MessageAccumu
 	return byAddress
 }
 
+func CheckPowerCheckpointInvariants(st *State, store adt.Store, acc *builtin.MessageAccumulator) {
+	checkpoints, err := st.LoadPowerCheckpoints(store)
+	if err != nil {
+		acc.Addf("error loading power checkpoints: %v", err)
+		return
+	}
+	acc.Require(len(checkpoints.Checkpoints) <= PowerCheckpointHistory,
+		"%d power checkpoints exceeds history of %d", len(checkpoints.Checkpoints), PowerCheckpointHistory)
+	for i, checkpoint := range checkpoints.Checkpoints {
+		acc.Require(checkpoint.RawBytePower.LessThanEqual(checkpoint.QualityAdjPower),
+			"power checkpoint at %d raw power %v is greater than quality adjusted power %v",
+			checkpoint.Epoch, checkpoint.RawBytePower, checkpoint.QualityAdjPower)
+		if i > 0 {
+			prev := checkpoints.Checkpoints[i-1].Epoch
+			acc.Require(checkpoint.Epoch-checkpoint.Epoch%PowerCheckpointInterval > prev,
+				"power checkpoint at %d in the same interval as, or before, checkpoint at %d", checkpoint.Epoch, prev)
+		}
+	}
+}
+
 func CheckProofValidationInvariants(st *State, store adt.Store, claims ClaimsByAddress, acc *builtin.MessageAccumulator) ProofsByAddress {
 	if st.ProofValidationBatch == nil {
 		return nil
@@ -187,3 +236,29 @@ func CheckProofValidationInvariants(st *State, store adt.Store, claims ClaimsByA
 	}
 	return proofs
 }
+
+func CheckConsensusFaultInvariants(st *State, store adt.Store, acc *builtin.MessageAccumulator) {
+	histories, err := adt.AsMap(store, st.ConsensusFaults, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		acc.Addf("error loading consensus faults: %v", err)
+		return
+	}
+	var history ConsensusFaultHistory
+	err = histories.ForEach(&history, func(key string) error {
+		miner, err := address.NewFromBytes([]byte(key))
+		if err != nil {
+			return err
+		}
+		acc.Require(len(history.Faults) > 0, "empty consensus fault history for miner %v", miner)
+		acc.Require(len(history.Faults) <= ConsensusFaultHistoryMax,
+			"%d consensus faults for miner %v exceeds history of %d", len(history.Faults), miner, ConsensusFaultHistoryMax)
+		acc.Require(history.IneligibleUntil >= history.LastReportEpoch,
+			"miner %v ineligibility ends at %d before last report at %d", miner, history.IneligibleUntil, history.LastReportEpoch)
+		for _, fault := range history.Faults {
+			acc.Require(fault.Epoch < history.LastReportEpoch,
+				"miner %v consensus fault at %d not before last report at %d", miner, fault.Epoch, history.LastReportEpoch)
+		}
+		return nil
+	})
+	acc.RequireNoError(err, "error iterating consensus faults")
+}
@@ -7,6 +7,8 @@ import (
 	"io"
 
 	abi "github.com/filecoin-project/go-state-types/abi"
+	big "github.com/filecoin-project/go-state-types/big"
+	reward "github.com/filecoin-project/specs-actors/actors/builtin/reward"
 	cbg "github.com/whyrusleeping/cbor-gen"
 	xerrors "golang.org/x/xerrors"
 )
@@ -308,3 +310,453 @@ func (t *ThisEpochRewardReturn) UnmarshalCBOR(r io.Reader) error {
 	}
 	return nil
 }
+
+var lengthBufThisEpochRewardBreakdownReturn = []byte{137}
+
+func (t *ThisEpochRewardBreakdownReturn) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufThisEpochRewardBreakdownReturn); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Epoch (abi.ChainEpoch) (int64)
+	if t.Epoch >= 0 {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.Epoch)); err != nil {
+			return err
+		}
+	} else {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajNegativeInt, uint64(-t.Epoch-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.ThisEpochReward (big.Int) (struct)
+	if err := t.ThisEpochReward.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.SimpleReward (big.Int) (struct)
+	if err := t.SimpleReward.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.BaselineReward (big.Int) (struct)
+	if err := t.BaselineReward.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.ThisEpochBaselinePower (big.Int) (struct)
+	if err := t.ThisEpochBaselinePower.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.CumsumRealized (big.Int) (struct)
+	if err := t.CumsumRealized.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.CumsumBaseline (big.Int) (struct)
+	if err := t.CumsumBaseline.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.EffectiveNetworkTime (abi.ChainEpoch) (int64)
+	if t.EffectiveNetworkTime >= 0 {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.EffectiveNetworkTime)); err != nil {
+			return err
+		}
+	} else {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajNegativeInt, uint64(-t.EffectiveNetworkTime-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.EffectiveBaselinePower (big.Int) (struct)
+	if err := t.EffectiveBaselinePower.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *ThisEpochRewardBreakdownReturn) UnmarshalCBOR(r io.Reader) error {
+	*t = ThisEpochRewardBreakdownReturn{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 9 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Epoch (abi.ChainEpoch) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.Epoch = abi.ChainEpoch(extraI)
+	}
+	// t.ThisEpochReward (big.Int) (struct)
+
+	{
+
+		if err := t.ThisEpochReward.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.ThisEpochReward: %w", err)
+		}
+
+	}
+	// t.SimpleReward (big.Int) (struct)
+
+	{
+
+		if err := t.SimpleReward.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.SimpleReward: %w", err)
+		}
+
+	}
+	// t.BaselineReward (big.Int) (struct)
+
+	{
+
+		if err := t.BaselineReward.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.BaselineReward: %w", err)
+		}
+
+	}
+	// t.ThisEpochBaselinePower (big.Int) (struct)
+
+	{
+
+		if err := t.ThisEpochBaselinePower.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.ThisEpochBaselinePower: %w", err)
+		}
+
+	}
+	// t.CumsumRealized (big.Int) (struct)
+
+	{
+
+		if err := t.CumsumRealized.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.CumsumRealized: %w", err)
+		}
+
+	}
+	// t.CumsumBaseline (big.Int) (struct)
+
+	{
+
+		if err := t.CumsumBaseline.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.CumsumBaseline: %w", err)
+		}
+
+	}
+	// t.EffectiveNetworkTime (abi.ChainEpoch) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.EffectiveNetworkTime = abi.ChainEpoch(extraI)
+	}
+	// t.EffectiveBaselinePower (big.Int) (struct)
+
+	{
+
+		if err := t.EffectiveBaselinePower.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.EffectiveBaselinePower: %w", err)
+		}
+
+	}
+	return nil
+}
+
+var lengthBufAwardBlockRewardsParams = []byte{129}
+
+func (t *AwardBlockRewardsParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufAwardBlockRewardsParams); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Rewards ([]reward.AwardBlockRewardParams) (slice)
+	if len(t.Rewards) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Rewards was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajArray, uint64(len(t.Rewards))); err != nil {
+		return err
+	}
+	for _, v := range t.Rewards {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *AwardBlockRewardsParams) UnmarshalCBOR(r io.Reader) error {
+	*t = AwardBlockRewardsParams{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Rewards ([]reward.AwardBlockRewardParams) (slice)
+
+	maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Rewards: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.Rewards = make([]reward.AwardBlockRewardParams, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		var v reward.AwardBlockRewardParams
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.Rewards[i] = v
+	}
+
+	return nil
+}
+
+var lengthBufProjectRewardsParams = []byte{129}
+
+func (t *ProjectRewardsParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufProjectRewardsParams); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Epochs (abi.ChainEpoch) (int64)
+	if t.Epochs >= 0 {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.Epochs)); err != nil {
+			return err
+		}
+	} else {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajNegativeInt, uint64(-t.Epochs-1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *ProjectRewardsParams) UnmarshalCBOR(r io.Reader) error {
+	*t = ProjectRewardsParams{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Epochs (abi.ChainEpoch) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.Epochs = abi.ChainEpoch(extraI)
+	}
+	return nil
+}
+
+var lengthBufProjectRewardsReturn = []byte{130}
+
+func (t *ProjectRewardsReturn) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufProjectRewardsReturn); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.EpochRewards ([]big.Int) (slice)
+	if len(t.EpochRewards) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.EpochRewards was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajArray, uint64(len(t.EpochRewards))); err != nil {
+		return err
+	}
+	for _, v := range t.EpochRewards {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+
+	// t.TotalReward (big.Int) (struct)
+	if err := t.TotalReward.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *ProjectRewardsReturn) UnmarshalCBOR(r io.Reader) error {
+	*t = ProjectRewardsReturn{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 2 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.EpochRewards ([]big.Int) (slice)
+
+	maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.EpochRewards: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.EpochRewards = make([]big.Int, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		var v big.Int
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.EpochRewards[i] = v
+	}
+
+	// t.TotalReward (big.Int) (struct)
+
+	{
+
+		if err := t.TotalReward.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.TotalReward: %w", err)
+		}
+
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package reward
 
 import (
+	addr "github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/cbor"
@@ -25,6 +26,9 @@ func (a Actor) Exports() []interface{} {
 		2:                         a.AwardBlockReward,
 		3:                         a.ThisEpochReward,
 		4:                         a.UpdateNetworkKPI,
+		5:                         a.ThisEpochRewardBreakdown,
+		6:                         a.AwardBlockRewards,
+		7:                         a.ProjectRewards,
 	}
 }
 
@@ -75,19 +79,11 @@ type AwardBlockRewardParams = reward0.AwardBlockRewardParams
 func (a Actor) AwardBlockReward(rt runtime.Runtime, params *AwardBlockRewardParams) *abi.EmptyValue {
 	rt.ValidateImmediateCallerIs(builtin.SystemActorAddr)
 	priorBalance := rt.CurrentBalance()
-	if params.Penalty.LessThan(big.Zero()) {
-		rt.Abortf(exitcode.ErrIllegalArgument, "negative penalty %v", params.Penalty)
-	}
-	if params.GasReward.LessThan(big.Zero()) {
-		rt.Abortf(exitcode.ErrIllegalArgument, "negative gas reward %v", params.GasReward)
-	}
+	validateAwardBlockRewardParams(rt, params)
 	if priorBalance.LessThan(params.GasReward) {
 		rt.Abortf(exitcode.ErrIllegalState, "actor current balance %v insufficient to pay gas reward %v",
 			priorBalance, params.GasReward)
 	}
-	if params.WinCount <= 0 {
-		rt.Abortf(exitcode.ErrIllegalArgument, "invalid win count %d", params.WinCount)
-	}
 
 	minerAddr, ok := rt.ResolveAddress(params.Miner)
 	if !ok {
@@ -98,8 +94,7 @@ func (a Actor) AwardBlockReward(rt runtime.Runtime, params *AwardBlockRewardPara
 	totalReward := big.Zero()
 	var st State
 	rt.StateTransaction(&st, func() {
-		blockReward := big.Mul(st.ThisEpochReward, big.NewInt(params.WinCount))
-		blockReward = big.Div(blockReward, big.NewInt(builtin.ExpectedLeadersPerEpoch))
+		blockReward := st.blockReward(params.WinCount)
 		totalReward = big.Add(blockReward, params.GasReward)
 		currBalance := rt.CurrentBalance()
 		if totalReward.GreaterThan(currBalance) {
@@ -115,21 +110,97 @@ func (a Actor) AwardBlockReward(rt runtime.Runtime, params *AwardBlockRewardPara
 
 	builtin.RequireState(rt, totalReward.LessThanEqual(priorBalance), "reward %v exceeds balance %v", totalReward, priorBalance)
 
+	applyReward(rt, minerAddr, totalReward, penalty)
+	return nil
+}
+
+type AwardBlockRewardsParams struct {
+	Rewards []AwardBlockRewardParams // One entry per block in the tipset
+}
+
+// Awards rewards to the producers of all blocks in a tipset.
+// This method is called only by the system actor, implicitly, in place of one AwardBlockReward call per block.
+// The gas rewards of all blocks are expected to be transferred to the reward actor with this invocation.
+//
+// All parameters are validated before any reward is paid. Each block producer is always paid its gas reward.
+// If the reward actor's balance is insufficient to also pay every block reward, the remaining balance is
+// paid out as block rewards in the order of the parameters.
+func (a Actor) AwardBlockRewards(rt runtime.Runtime, params *AwardBlockRewardsParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerIs(builtin.SystemActorAddr)
+	priorBalance := rt.CurrentBalance()
+	if len(params.Rewards) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no block rewards to award")
+	}
+
+	minerAddrs := make([]addr.Address, len(params.Rewards))
+	totalGasReward := big.Zero()
+	for i := range params.Rewards {
+		p := &params.Rewards[i]
+		validateAwardBlockRewardParams(rt, p)
+		minerAddr, ok := rt.ResolveAddress(p.Miner)
+		if !ok {
+			rt.Abortf(exitcode.ErrNotFound, "failed to resolve given owner address %v", p.Miner)
+		}
+		minerAddrs[i] = minerAddr
+		totalGasReward = big.Add(totalGasReward, p.GasReward)
+	}
+	if priorBalance.LessThan(totalGasReward) {
+		rt.Abortf(exitcode.ErrIllegalState, "actor current balance %v insufficient to pay gas rewards %v",
+			priorBalance, totalGasReward)
+	}
+
+	totalRewards := make([]abi.TokenAmount, len(params.Rewards))
+	var st State
+	rt.StateTransaction(&st, func() {
+		// Gas rewards are reserved in full, so only the remainder of the balance is available for block rewards.
+		available := big.Sub(rt.CurrentBalance(), totalGasReward)
+		for i, p := range params.Rewards {
+			blockReward := st.blockReward(p.WinCount)
+			if blockReward.GreaterThan(available) {
+				rt.Log(rtt.WARN, "reward actor balance %d below block reward expected %d, paying out rest of balance", available, blockReward)
+				blockReward = available
+			}
+			available = big.Sub(available, blockReward)
+			totalRewards[i] = big.Add(blockReward, p.GasReward)
+			st.TotalStoragePowerReward = big.Add(st.TotalStoragePowerReward, blockReward)
+		}
+	})
+
+	for i, p := range params.Rewards {
+		// The miner penalty is scaled up by a factor of PenaltyMultiplier
+		penalty := big.Mul(big.NewInt(PenaltyMultiplier), p.Penalty)
+		applyReward(rt, minerAddrs[i], totalRewards[i], penalty)
+	}
+	return nil
+}
+
+func validateAwardBlockRewardParams(rt runtime.Runtime, params *AwardBlockRewardParams) {
+	if params.Penalty.LessThan(big.Zero()) {
+		rt.Abortf(exitcode.ErrIllegalArgument, "negative penalty %v", params.Penalty)
+	}
+	if params.GasReward.LessThan(big.Zero()) {
+		rt.Abortf(exitcode.ErrIllegalArgument, "negative gas reward %v", params.GasReward)
+	}
+	if params.WinCount <= 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "invalid win count %d", params.WinCount)
+	}
+}
+
+// Sends a reward to a miner, burning it if the miner fails to accept it.
+func applyReward(rt runtime.Runtime, minerAddr addr.Address, reward, penalty abi.TokenAmount) {
 	// if this fails, we can assume the miner is responsible and avoid failing here.
 	rewardParams := builtin.ApplyRewardParams{
-		Reward:  totalReward,
+		Reward:  reward,
 		Penalty: penalty,
 	}
-	code := rt.Send(minerAddr, builtin.MethodsMiner.ApplyRewards, &rewardParams, totalReward, &builtin.Discard{})
+	code := rt.Send(minerAddr, builtin.MethodsMiner.ApplyRewards, &rewardParams, reward, &builtin.Discard{})
 	if !code.IsSuccess() {
-		rt.Log(rtt.ERROR, "failed to send ApplyRewards call to the miner actor with funds: %v, code: %v", totalReward, code)
-		code := rt.Send(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, totalReward, &builtin.Discard{})
+		rt.Log(rtt.ERROR, "failed to send ApplyRewards call to the miner actor with funds: %v, code: %v", reward, code)
+		code := builtin.TransferFunds(rt, builtin.BurntFundsActorAddr, reward, builtin.TransferUndeliveredReward)
 		if !code.IsSuccess() {
 			rt.Log(rtt.ERROR, "failed to send unsent reward to the burnt funds actor, code: %v", code)
 		}
 	}
-
-	return nil
 }
 
 // Changed since v0:
@@ -153,6 +224,74 @@ func (a Actor) ThisEpochReward(rt runtime.Runtime, _ *abi.EmptyValue) *ThisEpoch
 	}
 }
 
+type ThisEpochRewardBreakdownReturn struct {
+	// Epoch for which the reward was computed
+	Epoch abi.ChainEpoch
+	// Unsmoothed reward per WinCount, the sum of the simple and baseline minting components
+	ThisEpochReward abi.TokenAmount
+	SimpleReward    abi.TokenAmount
+	BaselineReward  abi.TokenAmount
+
+	ThisEpochBaselinePower abi.StoragePower
+	CumsumRealized         Spacetime
+	CumsumBaseline         Spacetime
+	EffectiveNetworkTime   abi.ChainEpoch
+	EffectiveBaselinePower abi.StoragePower
+}
+
+// Returns the unsmoothed award value used for the current epoch, split into its
+// simple and baseline minting components, together with the network KPIs from
+// which the baseline component was computed.
+func (a Actor) ThisEpochRewardBreakdown(rt runtime.Runtime, _ *abi.EmptyValue) *ThisEpochRewardBreakdownReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+
+	var st State
+	rt.StateReadonly(&st)
+	simple, baseline := st.thisEpochRewardComponents()
+	return &ThisEpochRewardBreakdownReturn{
+		Epoch:                  st.Epoch,
+		ThisEpochReward:        st.ThisEpochReward,
+		SimpleReward:           simple,
+		BaselineReward:         baseline,
+		ThisEpochBaselinePower: st.ThisEpochBaselinePower,
+		CumsumRealized:         st.CumsumRealized,
+		CumsumBaseline:         st.CumsumBaseline,
+		EffectiveNetworkTime:   st.EffectiveNetworkTime,
+		EffectiveBaselinePower: st.EffectiveBaselinePower,
+	}
+}
+
+// Maximum number of epochs for which ProjectRewards projects rewards.
+const MaxRewardProjectionEpochs = builtin.EpochsInDay
+
+type ProjectRewardsParams struct {
+	Epochs abi.ChainEpoch // Number of epochs to project, in (0, MaxRewardProjectionEpochs]
+}
+
+type ProjectRewardsReturn struct {
+	// Projected reward for all expected leaders at each epoch following the current one
+	EpochRewards []abi.TokenAmount
+	// Sum of EpochRewards
+	TotalReward abi.TokenAmount
+}
+
+// Projects the reward for all expected leaders at each of some number of epochs
+// following the current one, by extrapolating ThisEpochRewardSmoothed.
+func (a Actor) ProjectRewards(rt runtime.Runtime, params *ProjectRewardsParams) *ProjectRewardsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	if params.Epochs <= 0 || params.Epochs > MaxRewardProjectionEpochs {
+		rt.Abortf(exitcode.ErrIllegalArgument, "projection epochs %d out of range (0, %d]", params.Epochs, MaxRewardProjectionEpochs)
+	}
+
+	var st State
+	rt.StateReadonly(&st)
+	projection := ProjectEpochRewards(st.ThisEpochRewardSmoothed, params.Epochs)
+	return &ProjectRewardsReturn{
+		EpochRewards: projection,
+		TotalReward:  big.Sum(projection...),
+	}
+}
+
 // Called at the end of each epoch by the power actor (in turn by its cron hook).
 // This is only invoked for non-empty tipsets, but catches up any number of null
 // epochs to compute the next epoch reward.
@@ -5,6 +5,7 @@ import (
 	"github.com/filecoin-project/go-state-types/big"
 
 	"github.com/filecoin-project/specs-actors/v5/actors/util/math"
+	"github.com/filecoin-project/specs-actors/v5/actors/util/smoothing"
 )
 
 // Baseline function = BaselineInitialValue * (BaselineExponent) ^(t), t in epochs
@@ -77,11 +78,7 @@ var (
 // Computes a reward for all expected leaders when effective network time changes from prevTheta to currTheta
 // Inputs are in Q.128 format
 func computeReward(epoch abi.ChainEpoch, prevTheta, currTheta, simpleTotal, baselineTotal big.Int) abi.TokenAmount {
-	simpleReward := big.Mul(simpleTotal, ExpLamSubOne)    //Q.0 * Q.128 =>  Q.128
-	epochLam := big.Mul(big.NewInt(int64(epoch)), Lambda) // Q.0 * Q.128 => Q.128
-
-	simpleReward = big.Mul(simpleReward, big.NewFromGo(math.ExpNeg(epochLam.Int))) // Q.128 * Q.128 => Q.256
-	simpleReward = big.Rsh(simpleReward, math.Precision128)                        // Q.256 >> 128 => Q.128
+	simpleReward := computeSimpleReward(epoch, simpleTotal) // Q.128
 
 	baselineReward := big.Sub(computeBaselineSupply(currTheta, baselineTotal), computeBaselineSupply(prevTheta, baselineTotal)) // Q.128
 
@@ -90,6 +87,16 @@ func computeReward(epoch abi.ChainEpoch, prevTheta, currTheta, simpleTotal, base
 	return big.Rsh(reward, math.Precision128) // Q.128 => Q.0
 }
 
+// Computes the simple minting component of the reward for all expected leaders at an epoch.
+// Return is in Q.128 format
+func computeSimpleReward(epoch abi.ChainEpoch, simpleTotal big.Int) big.Int {
+	simpleReward := big.Mul(simpleTotal, ExpLamSubOne)    //Q.0 * Q.128 =>  Q.128
+	epochLam := big.Mul(big.NewInt(int64(epoch)), Lambda) // Q.0 * Q.128 => Q.128
+
+	simpleReward = big.Mul(simpleReward, big.NewFromGo(math.ExpNeg(epochLam.Int))) // Q.128 * Q.128 => Q.256
+	return big.Rsh(simpleReward, math.Precision128)                                // Q.256 >> 128 => Q.128
+}
+
 // Computes baseline supply based on theta in Q.128 format.
 // Return is in Q.128 format
 func computeBaselineSupply(theta, baselineTotal big.Int) big.Int {
@@ -105,6 +112,18 @@ func computeBaselineSupply(theta, baselineTotal big.Int) big.Int {
 	return big.Mul(baselineTotal, oneSub) // Q.0 * Q.128 => Q.128
 }
 
+// ProjectEpochRewards projects the reward for all expected leaders at each of the
+// epochs following that of a smoothed reward estimate, by extrapolating the estimate.
+// Projected rewards are clamped at zero.
+func ProjectEpochRewards(estimate smoothing.FilterEstimate, epochs abi.ChainEpoch) []abi.TokenAmount {
+	projection := []abi.TokenAmount{}
+	for delta := abi.ChainEpoch(1); delta <= epochs; delta++ {
+		reward := big.Rsh(estimate.Extrapolate(delta), 2*math.Precision128) // Q.256 => Q.0
+		projection = append(projection, big.Max(reward, big.Zero()))
+	}
+	return projection
+}
+
 // SlowConvenientBaselineForEpoch computes baseline power for use in epoch t
 // by calculating the value of ThisEpochBaselinePower that shows up in block at t - 1
 // It multiplies ~t times so it should not be used in actor code directly.  It is exported as
@@ -13,6 +13,7 @@ import (
 
 	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
 	"github.com/filecoin-project/specs-actors/v5/actors/util/math"
+	"github.com/filecoin-project/specs-actors/v5/actors/util/smoothing"
 )
 
 func q128ToF(x big.Int) float64 {
@@ -152,3 +153,21 @@ func TestBaselineRewardGrowth(t *testing.T) {
 		assert.Less(t, perr, testCase.ErrBound)
 	}
 }
+
+func TestProjectEpochRewards(t *testing.T) {
+	t.Run("extrapolates estimate", func(t *testing.T) {
+		estimate := smoothing.TestingEstimate(big.NewInt(1000), big.NewInt(-10))
+		projection := ProjectEpochRewards(estimate, 3)
+		assert.Equal(t, []abi.TokenAmount{big.NewInt(990), big.NewInt(980), big.NewInt(970)}, projection)
+	})
+
+	t.Run("clamps at zero", func(t *testing.T) {
+		estimate := smoothing.TestingEstimate(big.NewInt(15), big.NewInt(-10))
+		projection := ProjectEpochRewards(estimate, 3)
+		assert.Equal(t, []abi.TokenAmount{big.NewInt(5), big.Zero(), big.Zero()}, projection)
+	})
+
+	t.Run("projects nothing for no epochs", func(t *testing.T) {
+		assert.Empty(t, ProjectEpochRewards(smoothing.TestingConstantEstimate(big.NewInt(1000)), 0))
+	})
+}
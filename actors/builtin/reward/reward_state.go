@@ -4,6 +4,8 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v5/actors/util/math"
 	"github.com/filecoin-project/specs-actors/v5/actors/util/smoothing"
 )
 
@@ -112,6 +114,19 @@ func (st *State) updateToNextEpochWithReward(currRealizedPower abi.StoragePower)
 	st.ThisEpochReward = computeReward(st.Epoch, prevRewardTheta, currRewardTheta, st.SimpleTotal, st.BaselineTotal)
 }
 
+// Computes the block reward for a block with some number of wins at the current epoch.
+func (st *State) blockReward(winCount int64) abi.TokenAmount {
+	blockReward := big.Mul(st.ThisEpochReward, big.NewInt(winCount))
+	return big.Div(blockReward, big.NewInt(builtin.ExpectedLeadersPerEpoch))
+}
+
+// Splits ThisEpochReward into its simple and baseline minting components.
+// The simple component depends only on the epoch, so the baseline component is the remainder.
+func (st *State) thisEpochRewardComponents() (simple, baseline abi.TokenAmount) {
+	simple = big.Rsh(computeSimpleReward(st.Epoch, st.SimpleTotal), math.Precision128) // Q.128 => Q.0
+	return simple, big.Sub(st.ThisEpochReward, simple)
+}
+
 func (st *State) updateSmoothedEstimates(delta abi.ChainEpoch) {
 	filterReward := smoothing.LoadFilter(st.ThisEpochRewardSmoothed, smoothing.DefaultAlpha, smoothing.DefaultBeta)
 	st.ThisEpochRewardSmoothed = filterReward.NextEstimate(st.ThisEpochReward, delta)
@@ -216,6 +216,98 @@ func TestAwardBlockReward(t *testing.T) {
 	})
 }
 
+func TestAwardBlockRewards(t *testing.T) {
+	actor := rewardHarness{reward.Actor{}, t}
+	miner1 := tutil.NewIDAddr(t, 1000)
+	miner2 := tutil.NewIDAddr(t, 1001)
+	builder := mock.NewBuilder(builtin.RewardActorAddr).
+		WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID)
+
+	setup := func(t *testing.T, balance abi.TokenAmount) *mock.Runtime {
+		rt := builder.Build(t)
+		startRealizedPower := abi.NewStoragePower(1)
+		actor.constructAndVerify(rt, &startRealizedPower)
+		st := getState(rt)
+		st.ThisEpochReward = abi.NewTokenAmount(5000)
+		rt.ReplaceState(st)
+		rt.SetBalance(balance)
+		return rt
+	}
+
+	expectApplyRewards := func(rt *mock.Runtime, miner address.Address, amount, penalty abi.TokenAmount, code exitcode.ExitCode) {
+		minerPenalty := big.Mul(big.NewInt(reward.PenaltyMultiplier), penalty)
+		rt.ExpectSend(miner, builtin.MethodsMiner.ApplyRewards, &builtin.ApplyRewardParams{Reward: amount, Penalty: minerPenalty}, amount, nil, code)
+	}
+
+	t.Run("pays all winners", func(t *testing.T) {
+		rt := setup(t, abi.NewTokenAmount(1e6))
+		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		// award normalized by expected leaders is 1000 per win
+		expectApplyRewards(rt, miner1, big.NewInt(1000+10), big.NewInt(5), exitcode.Ok)
+		expectApplyRewards(rt, miner2, big.NewInt(2000+20), big.Zero(), exitcode.Ok)
+		rt.Call(actor.AwardBlockRewards, &reward.AwardBlockRewardsParams{Rewards: []reward.AwardBlockRewardParams{
+			{Miner: miner1, Penalty: big.NewInt(5), GasReward: big.NewInt(10), WinCount: 1},
+			{Miner: miner2, Penalty: big.Zero(), GasReward: big.NewInt(20), WinCount: 2},
+		}})
+		rt.Verify()
+		assert.Equal(t, big.NewInt(3000), getState(rt).TotalStoragePowerReward)
+	})
+
+	t.Run("reserves gas rewards when balance is insufficient", func(t *testing.T) {
+		// Enough to pay both gas rewards and half of the first block reward.
+		rt := setup(t, abi.NewTokenAmount(500+10+20))
+		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		expectApplyRewards(rt, miner1, big.NewInt(500+10), big.Zero(), exitcode.Ok)
+		expectApplyRewards(rt, miner2, big.NewInt(20), big.Zero(), exitcode.Ok)
+		rt.Call(actor.AwardBlockRewards, &reward.AwardBlockRewardsParams{Rewards: []reward.AwardBlockRewardParams{
+			{Miner: miner1, Penalty: big.Zero(), GasReward: big.NewInt(10), WinCount: 1},
+			{Miner: miner2, Penalty: big.Zero(), GasReward: big.NewInt(20), WinCount: 1},
+		}})
+		rt.Verify()
+		assert.Equal(t, big.NewInt(500), getState(rt).TotalStoragePowerReward)
+	})
+
+	t.Run("burns reward a miner fails to accept", func(t *testing.T) {
+		rt := setup(t, abi.NewTokenAmount(1e6))
+		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		expectApplyRewards(rt, miner1, big.NewInt(1000), big.Zero(), exitcode.ErrForbidden)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, big.NewInt(1000), nil, exitcode.Ok)
+		expectApplyRewards(rt, miner2, big.NewInt(1000), big.Zero(), exitcode.Ok)
+		rt.Call(actor.AwardBlockRewards, &reward.AwardBlockRewardsParams{Rewards: []reward.AwardBlockRewardParams{
+			{Miner: miner1, Penalty: big.Zero(), GasReward: big.Zero(), WinCount: 1},
+			{Miner: miner2, Penalty: big.Zero(), GasReward: big.Zero(), WinCount: 1},
+		}})
+		rt.Verify()
+	})
+
+	t.Run("rejects invalid rewards before paying any", func(t *testing.T) {
+		rt := setup(t, abi.NewTokenAmount(1e6))
+		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(actor.AwardBlockRewards, &reward.AwardBlockRewardsParams{Rewards: []reward.AwardBlockRewardParams{
+				{Miner: miner1, Penalty: big.Zero(), GasReward: big.Zero(), WinCount: 1},
+				{Miner: miner2, Penalty: big.Zero(), GasReward: big.Zero(), WinCount: 0},
+			}})
+		})
+		rt.Reset()
+
+		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		rt.ExpectAbort(exitcode.ErrIllegalState, func() {
+			rt.Call(actor.AwardBlockRewards, &reward.AwardBlockRewardsParams{Rewards: []reward.AwardBlockRewardParams{
+				{Miner: miner1, Penalty: big.Zero(), GasReward: abi.NewTokenAmount(6e5), WinCount: 1},
+				{Miner: miner2, Penalty: big.Zero(), GasReward: abi.NewTokenAmount(6e5), WinCount: 1},
+			}})
+		})
+		rt.Reset()
+
+		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(actor.AwardBlockRewards, &reward.AwardBlockRewardsParams{})
+		})
+		rt.Reset()
+	})
+}
+
 func TestThisEpochReward(t *testing.T) {
 	t.Run("successfully fetch reward for this epoch", func(t *testing.T) {
 		actor := rewardHarness{reward.Actor{}, t}
@@ -233,6 +325,81 @@ func TestThisEpochReward(t *testing.T) {
 	})
 }
 
+func TestThisEpochRewardBreakdown(t *testing.T) {
+	actor := rewardHarness{reward.Actor{}, t}
+	builder := mock.NewBuilder(builtin.RewardActorAddr).
+		WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID)
+
+	t.Run("reward is all simple minting without power", func(t *testing.T) {
+		rt := builder.Build(t)
+		power := abi.NewStoragePower(0)
+		actor.constructAndVerify(rt, &power)
+
+		resp := actor.thisEpochRewardBreakdown(rt)
+		st := getState(rt)
+		assert.Equal(t, st.ThisEpochReward, resp.ThisEpochReward)
+		assert.Equal(t, st.ThisEpochReward, resp.SimpleReward)
+		assert.True(t, resp.BaselineReward.IsZero())
+	})
+
+	t.Run("components sum to reward and KPIs match state", func(t *testing.T) {
+		rt := builder.Build(t)
+		power := abi.NewStoragePower(1 << 50)
+		actor.constructAndVerify(rt, &power)
+		rt.SetEpoch(abi.ChainEpoch(1))
+		actor.updateNetworkKPI(rt, &power)
+
+		resp := actor.thisEpochRewardBreakdown(rt)
+		st := getState(rt)
+		assert.Equal(t, st.Epoch, resp.Epoch)
+		assert.Equal(t, st.ThisEpochReward, resp.ThisEpochReward)
+		assert.True(t, resp.SimpleReward.GreaterThan(big.Zero()))
+		assert.True(t, resp.BaselineReward.GreaterThan(big.Zero()))
+		assert.Equal(t, resp.ThisEpochReward, big.Add(resp.SimpleReward, resp.BaselineReward))
+		assert.Equal(t, st.ThisEpochBaselinePower, resp.ThisEpochBaselinePower)
+		assert.Equal(t, st.CumsumRealized, resp.CumsumRealized)
+		assert.Equal(t, st.CumsumBaseline, resp.CumsumBaseline)
+		assert.Equal(t, st.EffectiveNetworkTime, resp.EffectiveNetworkTime)
+		assert.Equal(t, st.EffectiveBaselinePower, resp.EffectiveBaselinePower)
+	})
+}
+
+func TestProjectRewards(t *testing.T) {
+	actor := rewardHarness{reward.Actor{}, t}
+	builder := mock.NewBuilder(builtin.RewardActorAddr).
+		WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID)
+
+	t.Run("projects rewards from smoothed estimate", func(t *testing.T) {
+		rt := builder.Build(t)
+		power := abi.NewStoragePower(1 << 50)
+		actor.constructAndVerify(rt, &power)
+
+		rt.ExpectValidateCallerAny()
+		ret := rt.Call(actor.ProjectRewards, &reward.ProjectRewardsParams{Epochs: 10}).(*reward.ProjectRewardsReturn)
+		rt.Verify()
+
+		st := getState(rt)
+		assert.Equal(t, reward.ProjectEpochRewards(st.ThisEpochRewardSmoothed, 10), ret.EpochRewards)
+		assert.Equal(t, big.Sum(ret.EpochRewards...), ret.TotalReward)
+		// The smoothed reward is decaying.
+		assert.True(t, ret.EpochRewards[9].LessThan(ret.EpochRewards[0]))
+	})
+
+	t.Run("rejects out of range projection", func(t *testing.T) {
+		rt := builder.Build(t)
+		power := abi.NewStoragePower(1 << 50)
+		actor.constructAndVerify(rt, &power)
+
+		for _, epochs := range []abi.ChainEpoch{0, reward.MaxRewardProjectionEpochs + 1} {
+			rt.ExpectValidateCallerAny()
+			rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+				rt.Call(actor.ProjectRewards, &reward.ProjectRewardsParams{Epochs: epochs})
+			})
+			rt.Verify()
+		}
+	})
+}
+
 func TestSuccessiveKPIUpdates(t *testing.T) {
 	actor := rewardHarness{reward.Actor{}, t}
 	builder := mock.NewBuilder(builtin.RewardActorAddr).
@@ -300,6 +467,13 @@ func (h *rewardHarness) thisEpochReward(rt *mock.Runtime) *reward.ThisEpochRewar
 	return resp
 }
 
+func (h *rewardHarness) thisEpochRewardBreakdown(rt *mock.Runtime) *reward.ThisEpochRewardBreakdownReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.ThisEpochRewardBreakdown, nil).(*reward.ThisEpochRewardBreakdownReturn)
+	rt.Verify()
+	return ret
+}
+
 func getState(rt *mock.Runtime) *reward.State {
 	var st reward.State
 	rt.GetState(&st)
@@ -2,6 +2,7 @@ package builtin
 
 import (
 	stabi "github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
 	"github.com/pkg/errors"
 )
 
@@ -87,6 +88,34 @@ func ConsensusMinerMinPower(p stabi.RegisteredPoStProof) (stabi.StoragePower, er
 	return info.ConsensusMinerMinPower, nil
 }
 
+// Configures the minimum consensus power of miners of some Window PoSt proof types, for testing and development
+// networks (e.g. with small sectors), leaving the minimums of other proof types unchanged.
+// This must be done before any miner is created, since the power actor's count of miners meeting the minimum
+// is not recomputed.
+// Each proof type must have a PoSt proof policy and each minimum must be non-negative, otherwise an error is
+// returned and no minimum is changed.
+func SetConsensusMinerMinPower(minimums map[stabi.RegisteredPoStProof]stabi.StoragePower) error {
+	for p, minPower := range minimums {
+		if _, ok := PoStProofPolicies[p]; !ok {
+			return errors.Errorf("unsupported proof type: %v", p)
+		}
+		if minPower.Nil() || minPower.LessThan(big.Zero()) {
+			return errors.Errorf("invalid consensus miner min power %v for proof type %v", minPower, p)
+		}
+	}
+
+	policies := make(map[stabi.RegisteredPoStProof]*PoStProofPolicy, len(PoStProofPolicies))
+	for p, policy := range PoStProofPolicies {
+		updated := *policy
+		if minPower, ok := minimums[p]; ok {
+			updated.ConsensusMinerMinPower = minPower
+		}
+		policies[p] = &updated
+	}
+	PoStProofPolicies = policies
+	return nil
+}
+
 // Policy values associated with a PoSt proof type.
 type PoStProofPolicy struct {
 	WindowPoStPartitionSectors uint64
@@ -0,0 +1,53 @@
+package builtin_test
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+)
+
+func TestSetConsensusMinerMinPower(t *testing.T) {
+	policies := builtin.PoStProofPolicies
+	defer func() {
+		builtin.PoStProofPolicies = policies
+	}()
+
+	minPower := func(p abi.RegisteredPoStProof) abi.StoragePower {
+		power, err := builtin.ConsensusMinerMinPower(p)
+		require.NoError(t, err)
+		return power
+	}
+
+	t.Run("sets minimums of some proof types", func(t *testing.T) {
+		default32GiB := minPower(abi.RegisteredPoStProof_StackedDrgWindow32GiBV1)
+		require.NoError(t, builtin.SetConsensusMinerMinPower(map[abi.RegisteredPoStProof]abi.StoragePower{
+			abi.RegisteredPoStProof_StackedDrgWindow2KiBV1: big.NewInt(2048),
+			abi.RegisteredPoStProof_StackedDrgWindow8MiBV1: big.NewInt(8 << 20),
+		}))
+		assert.Equal(t, big.NewInt(2048), minPower(abi.RegisteredPoStProof_StackedDrgWindow2KiBV1))
+		assert.Equal(t, big.NewInt(8<<20), minPower(abi.RegisteredPoStProof_StackedDrgWindow8MiBV1))
+		assert.Equal(t, default32GiB, minPower(abi.RegisteredPoStProof_StackedDrgWindow32GiBV1))
+
+		// The default policies are not modified.
+		assert.Equal(t, default32GiB, policies[abi.RegisteredPoStProof_StackedDrgWindow2KiBV1].ConsensusMinerMinPower)
+	})
+
+	t.Run("rejects invalid minimums", func(t *testing.T) {
+		builtin.PoStProofPolicies = policies
+		assert.Error(t, builtin.SetConsensusMinerMinPower(map[abi.RegisteredPoStProof]abi.StoragePower{
+			abi.RegisteredPoStProof_StackedDrgWindow2KiBV1: big.NewInt(2048),
+			abi.RegisteredPoStProof(100):                   big.NewInt(2048),
+		}))
+		assert.Error(t, builtin.SetConsensusMinerMinPower(map[abi.RegisteredPoStProof]abi.StoragePower{
+			abi.RegisteredPoStProof_StackedDrgWindow2KiBV1: big.NewInt(-1),
+		}))
+		// Unchanged.
+		assert.Equal(t, policies[abi.RegisteredPoStProof_StackedDrgWindow2KiBV1].ConsensusMinerMinPower,
+			minPower(abi.RegisteredPoStProof_StackedDrgWindow2KiBV1))
+	})
+}
@@ -76,6 +76,25 @@ func RequireNoErr(rt runtime.Runtime, err error, defaultExitCode exitcode.ExitCo
 	}
 }
 
+// Splits a penalty into the amounts to pay the treasury actor and to burn, according to PenaltyTreasuryShare.
+func SplitPenalty(penalty abi.TokenAmount) (toTreasury, toBurn abi.TokenAmount) {
+	toTreasury = big.Div(big.Mul(penalty, PenaltyTreasuryShare.Numerator), PenaltyTreasuryShare.Denominator)
+	return toTreasury, big.Sub(penalty, toTreasury)
+}
+
+// Pays the treasury actor its share of a penalty and burns the remainder, aborting if either send fails.
+func BurnPenalty(rt runtime.Runtime, penalty abi.TokenAmount) {
+	if !penalty.GreaterThan(big.Zero()) {
+		return
+	}
+	toTreasury, toBurn := SplitPenalty(penalty)
+	if toTreasury.GreaterThan(big.Zero()) {
+		code := TransferFunds(rt, TreasuryActorAddr, toTreasury, TransferPenalty)
+		RequireSuccess(rt, code, "failed to pay penalty to treasury")
+	}
+	BurnFunds(rt, toBurn, TransferPenalty)
+}
+
 func RequestMinerControlAddrs(rt runtime.Runtime, minerAddr addr.Address) (ownerAddr addr.Address, workerAddr addr.Address, controlAddrs []addr.Address) {
 	var addrs MinerAddrs
 	code := rt.Send(minerAddr, MethodsMiner.ControlAddresses, nil, abi.NewTokenAmount(0), &addrs)
@@ -91,6 +110,23 @@ type MinerAddrs struct {
 	ControlAddrs []addr.Address
 }
 
+func RequestMinerBalanceBreakdown(rt runtime.Runtime, minerAddr addr.Address) *MinerBalanceBreakdown {
+	var breakdown MinerBalanceBreakdown
+	code := rt.Send(minerAddr, MethodsMiner.GetBalanceBreakdown, nil, abi.NewTokenAmount(0), &breakdown)
+	RequireSuccess(rt, code, "failed fetching balance breakdown")
+	return &breakdown
+}
+
+// This type duplicates the Miner.GetBalanceBreakdown return type, to work around a circular dependency between actors.
+type MinerBalanceBreakdown struct {
+	Balance           abi.TokenAmount
+	Available         abi.TokenAmount
+	PreCommitDeposits abi.TokenAmount
+	InitialPledge     abi.TokenAmount
+	LockedFunds       abi.TokenAmount
+	FeeDebt           abi.TokenAmount
+}
+
 // Note: we could move this alias back to the mutually-importing packages that use it, now that they
 // can instead both alias the v2 version.
 //type ConfirmSectorProofsParams struct {
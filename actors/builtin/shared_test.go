@@ -0,0 +1,45 @@
+package builtin_test
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+)
+
+func TestSplitPenalty(t *testing.T) {
+	t.Run("burns everything by default", func(t *testing.T) {
+		toTreasury, toBurn := builtin.SplitPenalty(abi.NewTokenAmount(1000))
+		assert.True(t, toTreasury.IsZero())
+		assert.Equal(t, abi.NewTokenAmount(1000), toBurn)
+	})
+
+	t.Run("pays the treasury its share", func(t *testing.T) {
+		setPenaltyTreasuryShare(t, 1, 3)
+
+		toTreasury, toBurn := builtin.SplitPenalty(abi.NewTokenAmount(1000))
+		assert.Equal(t, abi.NewTokenAmount(333), toTreasury)
+		assert.Equal(t, abi.NewTokenAmount(667), toBurn)
+
+		toTreasury, toBurn = builtin.SplitPenalty(big.Zero())
+		assert.True(t, toTreasury.IsZero())
+		assert.True(t, toBurn.IsZero())
+	})
+
+	t.Run("pays the treasury everything", func(t *testing.T) {
+		setPenaltyTreasuryShare(t, 1, 1)
+
+		toTreasury, toBurn := builtin.SplitPenalty(abi.NewTokenAmount(1000))
+		assert.Equal(t, abi.NewTokenAmount(1000), toTreasury)
+		assert.True(t, toBurn.IsZero())
+	})
+}
+
+func setPenaltyTreasuryShare(t *testing.T, numerator, denominator int64) {
+	prev := builtin.PenaltyTreasuryShare
+	builtin.PenaltyTreasuryShare = builtin.BigFrac{Numerator: big.NewInt(numerator), Denominator: big.NewInt(denominator)}
+	t.Cleanup(func() { builtin.PenaltyTreasuryShare = prev })
+}
@@ -14,6 +14,10 @@ var (
 	StoragePowerActorAddr     = mustMakeAddress(4)
 	StorageMarketActorAddr    = mustMakeAddress(5)
 	VerifiedRegistryActorAddr = mustMakeAddress(6)
+	// Distinguished actor that receives the PenaltyTreasuryShare of penalties, to fund public goods.
+	// Networks paying any share of penalties to the treasury must install an actor at this address at genesis,
+	// typically a multisig controlled by the network's governance.
+	TreasuryActorAddr = mustMakeAddress(98)
 	// Distinguished AccountActor that is the destination of all burnt funds.
 	BurntFundsActorAddr = mustMakeAddress(99)
 )
@@ -4,6 +4,8 @@ import (
 	addr "github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
 
 	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
 	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
@@ -58,6 +60,61 @@ func CheckStateInvariants(st *State, store adt.Store) (*StateSummary, *builtin.M
 		acc.RequireNoError(err, "error iterating clients")
 	}
 
+	// Check verifier expirations
+	if expirations, err := adt.AsMap(store, st.VerifierExpirations, builtin.DefaultHamtBitwidth); err != nil {
+		acc.Addf("error loading verifier expirations: %v", err)
+	} else {
+		count := 0
+		var expiration cbg.CborInt
+		err = expirations.ForEach(&expiration, func(key string) error {
+			verifier, err := addr.NewFromBytes([]byte(key))
+			if err != nil {
+				return err
+			}
+			count++
+			_, found := allVerifiers[verifier]
+			acc.Require(found, "expiration %d for unknown verifier %v", expiration, verifier)
+			acc.Require(st.NextVerifierExpiration != NoVerifierExpiration && st.NextVerifierExpiration <= abi.ChainEpoch(expiration),
+				"next verifier expiration %d after expiration %d of verifier %v", st.NextVerifierExpiration, expiration, verifier)
+			return nil
+		})
+		acc.RequireNoError(err, "error iterating verifier expirations")
+		acc.Require(count == len(allVerifiers), "%d verifier expirations for %d verifiers", count, len(allVerifiers))
+	}
+
+	// Check allowances
+	if allowances, err := adt.AsMap(store, st.Allowances, builtin.DefaultHamtBitwidth); err != nil {
+		acc.Addf("error loading allowances: %v", err)
+	} else {
+		var spendersRoot cbg.CborCid
+		err = allowances.ForEach(&spendersRoot, func(key string) error {
+			client, err := addr.NewFromBytes([]byte(key))
+			if err != nil {
+				return err
+			}
+			acc.Require(client.Protocol() == addr.ID, "allowance client %v should have ID protocol", client)
+			spenders, err := adt.AsMap(store, cid.Cid(spendersRoot), builtin.DefaultHamtBitwidth)
+			if err != nil {
+				return err
+			}
+			count := 0
+			var allowance DataCap
+			err = spenders.ForEach(&allowance, func(key string) error {
+				spender, err := addr.NewFromBytes([]byte(key))
+				if err != nil {
+					return err
+				}
+				count++
+				acc.Require(spender.Protocol() == addr.ID, "spender %v should have ID protocol", spender)
+				acc.Require(allowance.GreaterThan(big.Zero()), "allowance %v of %v for %v is not positive", allowance, spender, client)
+				return nil
+			})
+			acc.Require(count > 0, "client %v has empty allowances", client)
+			return err
+		})
+		acc.RequireNoError(err, "error iterating allowances")
+	}
+
 	// Check verifiers and clients are disjoint.
 	for v := range allVerifiers { //nolint:nomaprange
 		_, found := allClients[v]
@@ -8,6 +8,7 @@ import (
 
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/exitcode"
+	rtt "github.com/filecoin-project/go-state-types/rt"
 	verifreg0 "github.com/filecoin-project/specs-actors/actors/builtin/verifreg"
 
 	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
@@ -25,6 +26,12 @@ func (a Actor) Exports() []interface{} {
 		4:                         a.AddVerifiedClient,
 		5:                         a.UseBytes,
 		6:                         a.RestoreBytes,
+		7:                         a.RefundBytes,
+		8:                         a.TransferDataCap,
+		9:                         a.ApproveDataCap,
+		10:                        a.UseDataCapAllowance,
+		11:                        a.RenewVerifier,
+		12:                        a.CronTick,
 	}
 }
 
@@ -99,6 +106,9 @@ func (a Actor) AddVerifier(rt runtime.Runtime, params *AddVerifierParams) *abi.E
 
 		st.Verifiers, err = verifiers.Root()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush verifiers")
+
+		err = st.SetVerifierExpiration(adt.AsStore(rt), verifier, rt.CurrEpoch()+VerifierAllowanceDuration)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set expiration of verifier %v", verifier)
 	})
 
 	return nil
@@ -122,6 +132,54 @@ func (a Actor) RemoveVerifier(rt runtime.Runtime, verifierAddr *addr.Address) *a
 
 		st.Verifiers, err = verifiers.Root()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush verifiers")
+
+		err = st.RemoveVerifierExpiration(adt.AsStore(rt), verifier)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to remove expiration of verifier %v", verifier)
+	})
+
+	return nil
+}
+
+// Extends the expiration of a verifier's remaining DataCap to VerifierAllowanceDuration from the current epoch.
+func (a Actor) RenewVerifier(rt runtime.Runtime, verifierAddr *addr.Address) *abi.EmptyValue {
+	verifier, err := builtin.ResolveToIDAddr(rt, *verifierAddr)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to resolve verifier address %v to ID address", *verifierAddr)
+
+	var st State
+	rt.StateReadonly(&st)
+	rt.ValidateImmediateCallerIs(st.RootKey)
+
+	rt.StateTransaction(&st, func() {
+		verifiers, err := adt.AsMap(adt.AsStore(rt), st.Verifiers, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verifiers")
+
+		found, err := verifiers.Has(abi.AddrKey(verifier))
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get verifier")
+		builtin.RequireParam(rt, found, "no such verifier %v", verifierAddr)
+
+		err = st.SetVerifierExpiration(adt.AsStore(rt), verifier, rt.CurrEpoch()+VerifierAllowanceDuration)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set expiration of verifier %v", verifier)
+	})
+
+	return nil
+}
+
+// Called by the cron actor at the end of each epoch to remove verifiers whose DataCap has lapsed.
+func (a Actor) CronTick(rt runtime.Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
+	rt.ValidateImmediateCallerIs(builtin.CronActorAddr)
+
+	var st State
+	rt.StateReadonly(&st)
+	if st.NextVerifierExpiration == NoVerifierExpiration || rt.CurrEpoch() < st.NextVerifierExpiration {
+		return nil
+	}
+
+	rt.StateTransaction(&st, func() {
+		expired, err := st.ExpireVerifiers(adt.AsStore(rt), rt.CurrEpoch())
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to expire verifiers")
+		if len(expired) > 0 {
+			rt.Log(rtt.INFO, "removed %d expired verifiers", len(expired))
+		}
 	})
 
 	return nil
@@ -278,8 +336,164 @@ func (a Actor) RestoreBytes(rt runtime.Runtime, params *RestoreBytesParams) *abi
 		rt.Abortf(exitcode.ErrIllegalArgument, "Below minimum VerifiedDealSize requested in RestoreBytes: %d", params.DealSize)
 	}
 
-	client, err := builtin.ResolveToIDAddr(rt, params.Address)
-	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to resolve verified client addr %v", params.Address)
+	restoreDataCap(rt, params.Address, params.DealSize)
+	return nil
+}
+
+type RefundBytesParams struct {
+	Address addr.Address
+	Amount  DataCap
+}
+
+// Called by StorageMarketActor when a VerifiedDeal is terminated early in its term.
+// Restore a share of the deal's size to the client's allowable cap, creating new entry if the client has been deleted.
+// Unlike RestoreBytes, the share may be less than MinVerifiedDealSize.
+func (a Actor) RefundBytes(rt runtime.Runtime, params *RefundBytesParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerIs(builtin.StorageMarketActorAddr)
+
+	if params.Amount.LessThanEqual(big.Zero()) {
+		rt.Abortf(exitcode.ErrIllegalArgument, "non-positive amount requested in RefundBytes: %d", params.Amount)
+	}
+
+	restoreDataCap(rt, params.Address, params.Amount)
+	return nil
+}
+
+type TransferDataCapParams struct {
+	To     addr.Address // Client to receive the DataCap, which may not be the root key or a verifier
+	Amount DataCap
+}
+
+// Transfers DataCap from the calling client to another client.
+func (a Actor) TransferDataCap(rt runtime.Runtime, params *TransferDataCapParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerAcceptAny()
+	transferDataCap(rt, rt.Caller(), params.To, params.Amount)
+	return nil
+}
+
+type ApproveDataCapParams struct {
+	Spender   addr.Address
+	Allowance DataCap // Zero revokes any allowance
+}
+
+// Sets the DataCap that a spender may transfer on behalf of the calling client, replacing any prior allowance.
+// This lets a client delegate DataCap to a deal-making agent without sharing its own key.
+func (a Actor) ApproveDataCap(rt runtime.Runtime, params *ApproveDataCapParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerAcceptAny()
+	client := rt.Caller()
+
+	if params.Allowance.LessThan(big.Zero()) {
+		rt.Abortf(exitcode.ErrIllegalArgument, "negative allowance %v", params.Allowance)
+	}
+	spender, err := builtin.ResolveToIDAddr(rt, params.Spender)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to resolve spender address %v", params.Spender)
+	if spender == client {
+		rt.Abortf(exitcode.ErrIllegalArgument, "client %v cannot approve itself", client)
+	}
+
+	var st State
+	rt.StateTransaction(&st, func() {
+		err = st.SetAllowance(adt.AsStore(rt), client, spender, params.Allowance)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set allowance of %v for %v", spender, client)
+	})
+	return nil
+}
+
+type UseDataCapAllowanceParams struct {
+	Client addr.Address // Client that approved the calling spender
+	To     addr.Address // Client to receive the DataCap, which may not be the root key or a verifier
+	Amount DataCap
+}
+
+// Transfers DataCap from a client to another client (possibly the caller), spending the allowance
+// the client approved for the caller.
+func (a Actor) UseDataCapAllowance(rt runtime.Runtime, params *UseDataCapAllowanceParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerAcceptAny()
+	spender := rt.Caller()
+
+	client, err := builtin.ResolveToIDAddr(rt, params.Client)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to resolve client address %v", params.Client)
+
+	var st State
+	rt.StateTransaction(&st, func() {
+		store := adt.AsStore(rt)
+		allowance, err := st.GetAllowance(store, client, spender)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get allowance of %v for %v", spender, client)
+		if params.Amount.GreaterThan(allowance) {
+			rt.Abortf(exitcode.ErrForbidden, "amount %v exceeds allowance %v of %v for %v", params.Amount, allowance, spender, client)
+		}
+		err = st.SetAllowance(store, client, spender, big.Sub(allowance, params.Amount))
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set allowance of %v for %v", spender, client)
+	})
+
+	transferDataCap(rt, client, params.To, params.Amount)
+	return nil
+}
+
+// Moves DataCap between clients, deleting the sender's entry if none remains.
+func transferDataCap(rt runtime.Runtime, from, toAddress addr.Address, amount DataCap) {
+	if amount.LessThanEqual(big.Zero()) {
+		rt.Abortf(exitcode.ErrIllegalArgument, "non-positive amount %v to transfer", amount)
+	}
+	to, err := builtin.ResolveToIDAddr(rt, toAddress)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to resolve recipient address %v", toAddress)
+	if from == to {
+		rt.Abortf(exitcode.ErrIllegalArgument, "client %v cannot transfer DataCap to itself", from)
+	}
+
+	var st State
+	rt.StateTransaction(&st, func() {
+		if st.RootKey == to {
+			rt.Abortf(exitcode.ErrIllegalArgument, "cannot transfer DataCap to the root key")
+		}
+		verifiers, err := adt.AsMap(adt.AsStore(rt), st.Verifiers, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verifiers")
+		found, err := verifiers.Has(abi.AddrKey(to))
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get verifier")
+		if found {
+			rt.Abortf(exitcode.ErrIllegalArgument, "cannot transfer DataCap to verifier %v", to)
+		}
+
+		verifiedClients, err := adt.AsMap(adt.AsStore(rt), st.VerifiedClients, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verified clients")
+
+		var fromCap DataCap
+		found, err = verifiedClients.Get(abi.AddrKey(from), &fromCap)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get verified client %v", from)
+		if !found {
+			rt.Abortf(exitcode.ErrNotFound, "no such verified client %v", from)
+		}
+		if amount.GreaterThan(fromCap) {
+			rt.Abortf(exitcode.ErrIllegalArgument, "amount %v exceeds cap %v of verified client %v", amount, fromCap, from)
+		}
+		newFromCap := big.Sub(fromCap, amount)
+		if newFromCap.IsZero() {
+			err = verifiedClients.Delete(abi.AddrKey(from))
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete verified client %v", from)
+		} else {
+			err = verifiedClients.Put(abi.AddrKey(from), &newFromCap)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to update verified client %v with %v", from, newFromCap)
+		}
+
+		var toCap DataCap
+		found, err = verifiedClients.Get(abi.AddrKey(to), &toCap)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get verified client %v", to)
+		if !found {
+			toCap = big.Zero()
+		}
+		newToCap := big.Add(toCap, amount)
+		err = verifiedClients.Put(abi.AddrKey(to), &newToCap)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to update verified client %v with %v", to, newToCap)
+
+		st.VerifiedClients, err = verifiedClients.Root()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush verified clients")
+	})
+}
+
+// Adds to the allowable cap of a client, which may not be the root key or a verifier.
+func restoreDataCap(rt runtime.Runtime, address addr.Address, amount DataCap) {
+	client, err := builtin.ResolveToIDAddr(rt, address)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to resolve verified client addr %v", address)
 
 	var st State
 	rt.StateReadonly(&st)
@@ -308,13 +522,11 @@ func (a Actor) RestoreBytes(rt runtime.Runtime, params *RestoreBytesParams) *abi
 			vcCap = big.Zero()
 		}
 
-		newVcCap := big.Add(vcCap, params.DealSize)
+		newVcCap := big.Add(vcCap, amount)
 		err = verifiedClients.Put(abi.AddrKey(client), &newVcCap)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to put verified client %v with %v", client, newVcCap)
 
 		st.VerifiedClients, err = verifiedClients.Root()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verifiers")
 	})
-
-	return nil
 }
@@ -3,7 +3,9 @@ package verifreg
 import (
 	addr "github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
 	cid "github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
@@ -24,11 +26,27 @@ type State struct {
 	Verifiers cid.Cid // HAMT[addr.Address]DataCap
 
 	// VerifiedClients can add VerifiedClientData, up to DataCap.
+	// Clients may transfer their DataCap to other clients.
 	VerifiedClients cid.Cid // HAMT[addr.Address]DataCap
+
+	// Allowances of DataCap approved by clients for other parties to transfer on their behalf.
+	Allowances cid.Cid // HAMT[addr.Address]HAMT[addr.Address]DataCap, client -> spender -> allowance
+
+	// Epoch at which each verifier's remaining DataCap lapses, removing the verifier.
+	VerifierExpirations cid.Cid // HAMT[addr.Address]abi.ChainEpoch
+
+	// Earliest epoch at which a verifier may expire, or NoVerifierExpiration if there are no verifiers.
+	// This may be earlier than every epoch in VerifierExpirations, after verifiers are removed or renewed.
+	NextVerifierExpiration abi.ChainEpoch
 }
 
 var MinVerifiedDealSize = abi.NewStoragePower(1 << 20)
 
+// Duration after which the DataCap of a verifier lapses, unless the verifier is renewed.
+var VerifierAllowanceDuration = abi.ChainEpoch(builtin.EpochsInYear)
+
+const NoVerifierExpiration = abi.ChainEpoch(-1)
+
 // rootKeyAddress comes from genesis.
 func ConstructState(store adt.Store, rootKeyAddress addr.Address) (*State, error) {
 	emptyMapCid, err := adt.StoreEmptyMap(store, builtin.DefaultHamtBitwidth)
@@ -40,5 +58,181 @@ func ConstructState(store adt.Store, rootKeyAddress addr.Address) (*State, error
 		RootKey:         rootKeyAddress,
 		Verifiers:       emptyMapCid,
 		VerifiedClients: emptyMapCid,
+		Allowances:      emptyMapCid,
+
+		VerifierExpirations:    emptyMapCid,
+		NextVerifierExpiration: NoVerifierExpiration,
 	}, nil
 }
+
+// Records the epoch at which a verifier expires, replacing any earlier expiration.
+func (st *State) SetVerifierExpiration(store adt.Store, verifier addr.Address, expiration abi.ChainEpoch) error {
+	expirations, err := adt.AsMap(store, st.VerifierExpirations, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return xerrors.Errorf("failed to load verifier expirations: %w", err)
+	}
+	value := cbg.CborInt(expiration)
+	if err = expirations.Put(abi.AddrKey(verifier), &value); err != nil {
+		return xerrors.Errorf("failed to put expiration of verifier %v: %w", verifier, err)
+	}
+	if st.NextVerifierExpiration == NoVerifierExpiration || expiration < st.NextVerifierExpiration {
+		st.NextVerifierExpiration = expiration
+	}
+	if st.VerifierExpirations, err = expirations.Root(); err != nil {
+		return xerrors.Errorf("failed to flush verifier expirations: %w", err)
+	}
+	return nil
+}
+
+// Removes the expiration of a removed verifier.
+func (st *State) RemoveVerifierExpiration(store adt.Store, verifier addr.Address) error {
+	expirations, err := adt.AsMap(store, st.VerifierExpirations, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return xerrors.Errorf("failed to load verifier expirations: %w", err)
+	}
+	if _, err = expirations.TryDelete(abi.AddrKey(verifier)); err != nil {
+		return xerrors.Errorf("failed to delete expiration of verifier %v: %w", verifier, err)
+	}
+	if st.VerifierExpirations, err = expirations.Root(); err != nil {
+		return xerrors.Errorf("failed to flush verifier expirations: %w", err)
+	}
+	return nil
+}
+
+// Removes the verifiers that expire at or before an epoch, together with their remaining DataCap,
+// and updates NextVerifierExpiration to the earliest remaining expiration.
+// Returns the removed verifiers.
+func (st *State) ExpireVerifiers(store adt.Store, currEpoch abi.ChainEpoch) ([]addr.Address, error) {
+	if st.NextVerifierExpiration == NoVerifierExpiration || currEpoch < st.NextVerifierExpiration {
+		return nil, nil
+	}
+	expirations, err := adt.AsMap(store, st.VerifierExpirations, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load verifier expirations: %w", err)
+	}
+	verifiers, err := adt.AsMap(store, st.Verifiers, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load verifiers: %w", err)
+	}
+
+	var expired []addr.Address
+	next := NoVerifierExpiration
+	var expiration cbg.CborInt
+	if err = expirations.ForEach(&expiration, func(key string) error {
+		if abi.ChainEpoch(expiration) > currEpoch {
+			if next == NoVerifierExpiration || abi.ChainEpoch(expiration) < next {
+				next = abi.ChainEpoch(expiration)
+			}
+			return nil
+		}
+		verifier, err := addr.NewFromBytes([]byte(key))
+		if err != nil {
+			return err
+		}
+		expired = append(expired, verifier)
+		return nil
+	}); err != nil {
+		return nil, xerrors.Errorf("failed to iterate verifier expirations: %w", err)
+	}
+
+	for _, verifier := range expired {
+		if err = expirations.Delete(abi.AddrKey(verifier)); err != nil {
+			return nil, xerrors.Errorf("failed to delete expiration of verifier %v: %w", verifier, err)
+		}
+		if _, err = verifiers.TryDelete(abi.AddrKey(verifier)); err != nil {
+			return nil, xerrors.Errorf("failed to delete verifier %v: %w", verifier, err)
+		}
+	}
+
+	if st.VerifierExpirations, err = expirations.Root(); err != nil {
+		return nil, xerrors.Errorf("failed to flush verifier expirations: %w", err)
+	}
+	if st.Verifiers, err = verifiers.Root(); err != nil {
+		return nil, xerrors.Errorf("failed to flush verifiers: %w", err)
+	}
+	st.NextVerifierExpiration = next
+	return expired, nil
+}
+
+// Returns the DataCap a spender may transfer on behalf of a client, which is zero if none has been approved.
+func (st *State) GetAllowance(store adt.Store, client, spender addr.Address) (DataCap, error) {
+	spenders, found, err := st.loadSpenders(store, client)
+	if err != nil || !found {
+		return big.Zero(), err
+	}
+	var allowance DataCap
+	if found, err = spenders.Get(abi.AddrKey(spender), &allowance); err != nil {
+		return big.Zero(), xerrors.Errorf("failed to get allowance of %v for %v: %w", spender, client, err)
+	} else if !found {
+		return big.Zero(), nil
+	}
+	return allowance, nil
+}
+
+// Sets the DataCap a spender may transfer on behalf of a client, removing the allowance if zero.
+func (st *State) SetAllowance(store adt.Store, client, spender addr.Address, allowance DataCap) error {
+	allowances, err := adt.AsMap(store, st.Allowances, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return xerrors.Errorf("failed to load allowances: %w", err)
+	}
+	spenders, found, err := st.loadSpenders(store, client)
+	if err != nil {
+		return err
+	}
+	if !found {
+		if spenders, err = adt.MakeEmptyMap(store, builtin.DefaultHamtBitwidth); err != nil {
+			return xerrors.Errorf("failed to create allowances of %v: %w", client, err)
+		}
+	}
+
+	if allowance.IsZero() {
+		if _, err = spenders.TryDelete(abi.AddrKey(spender)); err != nil {
+			return xerrors.Errorf("failed to delete allowance of %v for %v: %w", spender, client, err)
+		}
+	} else if err = spenders.Put(abi.AddrKey(spender), &allowance); err != nil {
+		return xerrors.Errorf("failed to put allowance of %v for %v: %w", spender, client, err)
+	}
+
+	root, err := spenders.Root()
+	if err != nil {
+		return xerrors.Errorf("failed to flush allowances of %v: %w", client, err)
+	}
+	emptyRoot, err := adt.StoreEmptyMap(store, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return xerrors.Errorf("failed to create empty map: %w", err)
+	}
+	// The allowances of a client are removed entirely once none remain.
+	if root.Equals(emptyRoot) {
+		if _, err = allowances.TryDelete(abi.AddrKey(client)); err != nil {
+			return xerrors.Errorf("failed to delete allowances of %v: %w", client, err)
+		}
+	} else {
+		spendersRoot := cbg.CborCid(root)
+		if err = allowances.Put(abi.AddrKey(client), &spendersRoot); err != nil {
+			return xerrors.Errorf("failed to put allowances of %v: %w", client, err)
+		}
+	}
+
+	if st.Allowances, err = allowances.Root(); err != nil {
+		return xerrors.Errorf("failed to flush allowances: %w", err)
+	}
+	return nil
+}
+
+func (st *State) loadSpenders(store adt.Store, client addr.Address) (*adt.Map, bool, error) {
+	allowances, err := adt.AsMap(store, st.Allowances, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, false, xerrors.Errorf("failed to load allowances: %w", err)
+	}
+	var root cbg.CborCid
+	if found, err := allowances.Get(abi.AddrKey(client), &root); err != nil {
+		return nil, false, xerrors.Errorf("failed to get allowances of %v: %w", client, err)
+	} else if !found {
+		return nil, false, nil
+	}
+	spenders, err := adt.AsMap(store, cid.Cid(root), builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, false, xerrors.Errorf("failed to load allowances of %v: %w", client, err)
+	}
+	return spenders, true, nil
+}
@@ -749,6 +749,306 @@ func TestRestoreBytes(t *testing.T) {
 	})
 }
 
+func TestRefundBytes(t *testing.T) {
+	root := tutil.NewIDAddr(t, 101)
+	clientAddr := tutil.NewIDAddr(t, 201)
+	verifierAddr := tutil.NewIDAddr(t, 301)
+	vallow := big.Add(verifreg.MinVerifiedDealSize, big.NewInt(100))
+
+	t.Run("refunds an amount below the minimum deal size", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.generateAndAddVerifierAndVerifiedClient(rt, verifierAddr, clientAddr, vallow, verifreg.MinVerifiedDealSize)
+
+		amount := big.NewInt(10)
+		ac.refundBytes(rt, clientAddr, amount, &capExpectation{expectedCap: big.Add(verifreg.MinVerifiedDealSize, amount)})
+		ac.checkState(rt)
+	})
+
+	t.Run("refund restores a client removed by using bytes", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.generateAndAddVerifierAndVerifiedClient(rt, verifierAddr, clientAddr, vallow, verifreg.MinVerifiedDealSize)
+		ac.useBytes(rt, clientAddr, verifreg.MinVerifiedDealSize, &capExpectation{removed: true})
+
+		amount := big.NewInt(10)
+		ac.refundBytes(rt, clientAddr, amount, &capExpectation{expectedCap: amount})
+		ac.checkState(rt)
+	})
+
+	t.Run("fails if caller is not the market", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		rt.ExpectValidateCallerAddr(builtin.StorageMarketActorAddr)
+		rt.SetCaller(clientAddr, builtin.AccountActorCodeID)
+		param := &verifreg.RefundBytesParams{Address: clientAddr, Amount: big.NewInt(10)}
+
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(ac.RefundBytes, param)
+		})
+		ac.checkState(rt)
+	})
+
+	t.Run("fails if amount is not positive", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		rt.ExpectValidateCallerAddr(builtin.StorageMarketActorAddr)
+		rt.SetCaller(builtin.StorageMarketActorAddr, builtin.StorageMarketActorCodeID)
+		param := &verifreg.RefundBytesParams{Address: clientAddr, Amount: big.Zero()}
+
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "non-positive amount", func() {
+			rt.Call(ac.RefundBytes, param)
+		})
+		ac.checkState(rt)
+	})
+
+	t.Run("fails if attempt to refund bytes for verifier", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.addNewVerifier(rt, verifierAddr, vallow)
+
+		rt.ExpectValidateCallerAddr(builtin.StorageMarketActorAddr)
+		rt.SetCaller(builtin.StorageMarketActorAddr, builtin.StorageMarketActorCodeID)
+		param := &verifreg.RefundBytesParams{Address: verifierAddr, Amount: big.NewInt(10)}
+
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(ac.RefundBytes, param)
+		})
+		ac.checkState(rt)
+	})
+}
+
+func TestTransferDataCap(t *testing.T) {
+	root := tutil.NewIDAddr(t, 101)
+	clientAddr := tutil.NewIDAddr(t, 201)
+	clientAddr2 := tutil.NewIDAddr(t, 202)
+	verifierAddr := tutil.NewIDAddr(t, 301)
+	vallow := big.Add(verifreg.MinVerifiedDealSize, big.NewInt(100))
+	clientCap := big.Mul(verifreg.MinVerifiedDealSize, big.NewInt(2))
+
+	t.Run("transfers to a new client", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.generateAndAddVerifierAndVerifiedClient(rt, verifierAddr, clientAddr, vallow, clientCap)
+
+		ac.transferDataCap(rt, clientAddr, clientAddr2, verifreg.MinVerifiedDealSize)
+		assert.Equal(t, verifreg.MinVerifiedDealSize, ac.getClientCap(rt, clientAddr))
+		assert.Equal(t, verifreg.MinVerifiedDealSize, ac.getClientCap(rt, clientAddr2))
+		ac.checkState(rt)
+	})
+
+	t.Run("transferring all DataCap removes the client", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.generateAndAddVerifierAndVerifiedClient(rt, verifierAddr, clientAddr, vallow, clientCap)
+
+		ac.transferDataCap(rt, clientAddr, clientAddr2, clientCap)
+		ac.assertClientRemoved(rt, clientAddr)
+		assert.Equal(t, clientCap, ac.getClientCap(rt, clientAddr2))
+		ac.checkState(rt)
+	})
+
+	t.Run("fails to transfer more than the client's cap", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.generateAndAddVerifierAndVerifiedClient(rt, verifierAddr, clientAddr, vallow, clientCap)
+
+		rt.SetCaller(clientAddr, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "exceeds cap", func() {
+			rt.Call(ac.TransferDataCap, &verifreg.TransferDataCapParams{To: clientAddr2, Amount: big.Add(clientCap, big.NewInt(1))})
+		})
+		ac.checkState(rt)
+	})
+
+	t.Run("fails if caller is not a client", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+
+		rt.SetCaller(clientAddr, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			rt.Call(ac.TransferDataCap, &verifreg.TransferDataCapParams{To: clientAddr2, Amount: big.NewInt(1)})
+		})
+	})
+
+	t.Run("fails to transfer to the root key or a verifier", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.generateAndAddVerifierAndVerifiedClient(rt, verifierAddr, clientAddr, vallow, clientCap)
+
+		for _, to := range []address.Address{root, verifierAddr} {
+			rt.SetCaller(clientAddr, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAny()
+			rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+				rt.Call(ac.TransferDataCap, &verifreg.TransferDataCapParams{To: to, Amount: big.NewInt(1)})
+			})
+		}
+		ac.checkState(rt)
+	})
+}
+
+func TestDataCapAllowance(t *testing.T) {
+	root := tutil.NewIDAddr(t, 101)
+	clientAddr := tutil.NewIDAddr(t, 201)
+	agentAddr := tutil.NewIDAddr(t, 202)
+	recipientAddr := tutil.NewIDAddr(t, 203)
+	verifierAddr := tutil.NewIDAddr(t, 301)
+	vallow := big.Add(verifreg.MinVerifiedDealSize, big.NewInt(100))
+	clientCap := big.Mul(verifreg.MinVerifiedDealSize, big.NewInt(4))
+	allowance := big.Mul(verifreg.MinVerifiedDealSize, big.NewInt(2))
+
+	t.Run("agent uses approved allowance", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.generateAndAddVerifierAndVerifiedClient(rt, verifierAddr, clientAddr, vallow, clientCap)
+		ac.approveDataCap(rt, clientAddr, agentAddr, allowance)
+		assert.Equal(t, allowance, ac.getAllowance(rt, clientAddr, agentAddr))
+
+		ac.useDataCapAllowance(rt, agentAddr, clientAddr, recipientAddr, verifreg.MinVerifiedDealSize)
+		assert.Equal(t, verifreg.MinVerifiedDealSize, ac.getAllowance(rt, clientAddr, agentAddr))
+		assert.Equal(t, big.Sub(clientCap, verifreg.MinVerifiedDealSize), ac.getClientCap(rt, clientAddr))
+		assert.Equal(t, verifreg.MinVerifiedDealSize, ac.getClientCap(rt, recipientAddr))
+
+		// The agent may transfer to itself, exhausting the allowance.
+		ac.useDataCapAllowance(rt, agentAddr, clientAddr, agentAddr, verifreg.MinVerifiedDealSize)
+		assert.Equal(t, big.Zero(), ac.getAllowance(rt, clientAddr, agentAddr))
+		assert.Equal(t, verifreg.MinVerifiedDealSize, ac.getClientCap(rt, agentAddr))
+		ac.checkState(rt)
+	})
+
+	t.Run("approval replaces and revokes allowance", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.approveDataCap(rt, clientAddr, agentAddr, allowance)
+		ac.approveDataCap(rt, clientAddr, agentAddr, verifreg.MinVerifiedDealSize)
+		assert.Equal(t, verifreg.MinVerifiedDealSize, ac.getAllowance(rt, clientAddr, agentAddr))
+
+		ac.approveDataCap(rt, clientAddr, agentAddr, big.Zero())
+		assert.Equal(t, big.Zero(), ac.getAllowance(rt, clientAddr, agentAddr))
+		ac.checkState(rt)
+	})
+
+	t.Run("fails to use more than allowance", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.generateAndAddVerifierAndVerifiedClient(rt, verifierAddr, clientAddr, vallow, clientCap)
+		ac.approveDataCap(rt, clientAddr, agentAddr, allowance)
+
+		rt.SetCaller(agentAddr, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			rt.Call(ac.UseDataCapAllowance, &verifreg.UseDataCapAllowanceParams{
+				Client: clientAddr,
+				To:     recipientAddr,
+				Amount: big.Add(allowance, big.NewInt(1)),
+			})
+		})
+		assert.Equal(t, allowance, ac.getAllowance(rt, clientAddr, agentAddr))
+		ac.checkState(rt)
+	})
+
+	t.Run("fails to use allowance beyond the client's cap", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.generateAndAddVerifierAndVerifiedClient(rt, verifierAddr, clientAddr, vallow, verifreg.MinVerifiedDealSize)
+		ac.approveDataCap(rt, clientAddr, agentAddr, allowance)
+
+		rt.SetCaller(agentAddr, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(ac.UseDataCapAllowance, &verifreg.UseDataCapAllowanceParams{Client: clientAddr, To: recipientAddr, Amount: allowance})
+		})
+		assert.Equal(t, allowance, ac.getAllowance(rt, clientAddr, agentAddr))
+		ac.checkState(rt)
+	})
+
+	t.Run("fails to approve invalid allowance", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		rt.SetCaller(clientAddr, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(ac.ApproveDataCap, &verifreg.ApproveDataCapParams{Spender: agentAddr, Allowance: big.NewInt(-1)})
+		})
+
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(ac.ApproveDataCap, &verifreg.ApproveDataCapParams{Spender: clientAddr, Allowance: allowance})
+		})
+		ac.checkState(rt)
+	})
+}
+
+func TestVerifierExpiration(t *testing.T) {
+	root := tutil.NewIDAddr(t, 101)
+	verifierAddr := tutil.NewIDAddr(t, 301)
+	verifierAddr2 := tutil.NewIDAddr(t, 302)
+	clientAddr := tutil.NewIDAddr(t, 201)
+	allowance := big.Mul(verifreg.MinVerifiedDealSize, big.NewInt(2))
+
+	t.Run("verifier expires after allowance duration", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		rt.SetEpoch(100)
+		ac.addVerifier(rt, verifierAddr, allowance)
+		ac.addVerifiedClient(rt, verifierAddr, clientAddr, verifreg.MinVerifiedDealSize, verifreg.MinVerifiedDealSize)
+		expiration := 100 + verifreg.VerifierAllowanceDuration
+		assert.Equal(t, expiration, ac.state(rt).NextVerifierExpiration)
+
+		rt.SetEpoch(expiration - 1)
+		ac.cronTick(rt)
+		assert.Equal(t, verifreg.MinVerifiedDealSize, ac.getVerifierCap(rt, verifierAddr))
+
+		rt.SetEpoch(expiration)
+		ac.cronTick(rt)
+		ac.assertVerifierRemoved(rt, verifierAddr)
+		assert.Equal(t, verifreg.NoVerifierExpiration, ac.state(rt).NextVerifierExpiration)
+		// DataCap already granted to clients is retained.
+		assert.Equal(t, verifreg.MinVerifiedDealSize, ac.getClientCap(rt, clientAddr))
+		ac.checkState(rt)
+	})
+
+	t.Run("renewal extends expiration", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.addVerifier(rt, verifierAddr, allowance)
+		ac.addVerifier(rt, verifierAddr2, allowance)
+
+		rt.SetEpoch(1000)
+		ac.renewVerifier(rt, verifierAddr)
+
+		// Only the verifier not renewed expires.
+		rt.SetEpoch(verifreg.VerifierAllowanceDuration)
+		ac.cronTick(rt)
+		assert.Equal(t, allowance, ac.getVerifierCap(rt, verifierAddr))
+		ac.assertVerifierRemoved(rt, verifierAddr2)
+		assert.Equal(t, 1000+verifreg.VerifierAllowanceDuration, ac.state(rt).NextVerifierExpiration)
+
+		rt.SetEpoch(1000 + verifreg.VerifierAllowanceDuration)
+		ac.cronTick(rt)
+		ac.assertVerifierRemoved(rt, verifierAddr)
+		ac.checkState(rt)
+	})
+
+	t.Run("removed verifier has no expiration", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.addVerifier(rt, verifierAddr, allowance)
+		ac.removeVerifier(rt, verifierAddr)
+		ac.checkState(rt)
+
+		// Re-adding the verifier sets a fresh expiration.
+		rt.SetEpoch(500)
+		ac.addVerifier(rt, verifierAddr, allowance)
+		rt.SetEpoch(verifreg.VerifierAllowanceDuration)
+		ac.cronTick(rt)
+		assert.Equal(t, allowance, ac.getVerifierCap(rt, verifierAddr))
+		ac.checkState(rt)
+	})
+
+	t.Run("fails to renew unknown verifier", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		rt.SetCaller(root, builtin.VerifiedRegistryActorCodeID)
+		rt.ExpectValidateCallerAddr(root)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(ac.RenewVerifier, &verifierAddr)
+		})
+	})
+
+	t.Run("fails to renew if caller is not the root key", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.addVerifier(rt, verifierAddr, allowance)
+		rt.SetCaller(verifierAddr, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(root)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(ac.RenewVerifier, &verifierAddr)
+		})
+	})
+}
+
 type verifRegActorTestHarness struct {
 	rootkey address.Address
 	verifreg.Actor
@@ -846,6 +1146,22 @@ func (h *verifRegActorTestHarness) removeVerifier(rt *mock.Runtime, verifier add
 	h.assertVerifierRemoved(rt, verifier)
 }
 
+func (h *verifRegActorTestHarness) renewVerifier(rt *mock.Runtime, verifier address.Address) {
+	rt.ExpectValidateCallerAddr(h.rootkey)
+	rt.SetCaller(h.rootkey, builtin.VerifiedRegistryActorCodeID)
+	ret := rt.Call(h.RenewVerifier, &verifier)
+	require.Nil(h.t, ret)
+	rt.Verify()
+}
+
+func (h *verifRegActorTestHarness) cronTick(rt *mock.Runtime) {
+	rt.ExpectValidateCallerAddr(builtin.CronActorAddr)
+	rt.SetCaller(builtin.CronActorAddr, builtin.CronActorCodeID)
+	ret := rt.Call(h.CronTick, nil)
+	require.Nil(h.t, ret)
+	rt.Verify()
+}
+
 type capExpectation struct {
 	expectedCap verifreg.DataCap
 	removed     bool
@@ -889,6 +1205,50 @@ func (h *verifRegActorTestHarness) restoreBytes(rt *mock.Runtime, a address.Addr
 	assert.EqualValues(h.t, expectedCap.expectedCap, h.getClientCap(rt, clientIdAddr))
 }
 
+func (h *verifRegActorTestHarness) refundBytes(rt *mock.Runtime, a address.Address, amount verifreg.DataCap, expectedCap *capExpectation) {
+	rt.ExpectValidateCallerAddr(builtin.StorageMarketActorAddr)
+	rt.SetCaller(builtin.StorageMarketActorAddr, builtin.StorageMarketActorCodeID)
+
+	param := &verifreg.RefundBytesParams{Address: a, Amount: amount}
+	ret := rt.Call(h.RefundBytes, param)
+	rt.Verify()
+	assert.Nil(h.t, ret)
+
+	clientIdAddr, found := rt.GetIdAddr(a)
+	require.True(h.t, found)
+	assert.EqualValues(h.t, expectedCap.expectedCap, h.getClientCap(rt, clientIdAddr))
+}
+
+func (h *verifRegActorTestHarness) transferDataCap(rt *mock.Runtime, from, to address.Address, amount verifreg.DataCap) {
+	rt.SetCaller(from, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.TransferDataCap, &verifreg.TransferDataCapParams{To: to, Amount: amount})
+	assert.Nil(h.t, ret)
+	rt.Verify()
+}
+
+func (h *verifRegActorTestHarness) approveDataCap(rt *mock.Runtime, client, spender address.Address, allowance verifreg.DataCap) {
+	rt.SetCaller(client, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.ApproveDataCap, &verifreg.ApproveDataCapParams{Spender: spender, Allowance: allowance})
+	assert.Nil(h.t, ret)
+	rt.Verify()
+}
+
+func (h *verifRegActorTestHarness) useDataCapAllowance(rt *mock.Runtime, spender, client, to address.Address, amount verifreg.DataCap) {
+	rt.SetCaller(spender, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.UseDataCapAllowance, &verifreg.UseDataCapAllowanceParams{Client: client, To: to, Amount: amount})
+	assert.Nil(h.t, ret)
+	rt.Verify()
+}
+
+func (h *verifRegActorTestHarness) getAllowance(rt *mock.Runtime, client, spender address.Address) verifreg.DataCap {
+	allowance, err := h.state(rt).GetAllowance(rt.AdtStore(), client, spender)
+	require.NoError(h.t, err)
+	return allowance
+}
+
 func (h *verifRegActorTestHarness) getVerifierCap(rt *mock.Runtime, a address.Address) verifreg.DataCap {
 	var st verifreg.State
 	rt.GetState(&st)
@@ -0,0 +1,41 @@
+package nv13
+
+import (
+	"context"
+
+	cron4 "github.com/filecoin-project/specs-actors/v4/actors/builtin/cron"
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+
+	builtin5 "github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	cron5 "github.com/filecoin-project/specs-actors/v5/actors/builtin/cron"
+)
+
+// Cron migrator adds an entry for the verified registry's cron tick, which expires verifiers.
+type cronMigrator struct{}
+
+func (m cronMigrator) migrateState(ctx context.Context, store cbor.IpldStore, in actorMigrationInput) (*actorMigrationResult, error) {
+	var inState cron4.State
+	if err := store.Get(ctx, in.head, &inState); err != nil {
+		return nil, err
+	}
+
+	outState := cron5.State{Entries: make([]cron5.Entry, 0, len(inState.Entries)+1)}
+	for _, e := range inState.Entries {
+		outState.Entries = append(outState.Entries, cron5.Entry(e))
+	}
+	outState.Entries = append(outState.Entries, cron5.Entry{
+		Receiver:  builtin5.VerifiedRegistryActorAddr,
+		MethodNum: builtin5.MethodsVerifiedRegistry.CronTick,
+	})
+
+	newHead, err := store.Put(ctx, &outState)
+	return &actorMigrationResult{
+		newCodeCID: m.migratedCodeCID(),
+		newHead:    newHead,
+	}, err
+}
+
+func (m cronMigrator) migratedCodeCID() cid.Cid {
+	return builtin5.CronActorCodeID
+}
@@ -0,0 +1,353 @@
+package nv13
+
+import (
+	"context"
+	"sort"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	market4 "github.com/filecoin-project/specs-actors/v4/actors/builtin/market"
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	builtin5 "github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	market5 "github.com/filecoin-project/specs-actors/v5/actors/builtin/market"
+	adt5 "github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+)
+
+// Market migrator adds the (empty) upfront payment deal set and deal offers to the market state,
+// the indexes of deals by provider and by piece, and the payment mode to each deal state.
+// All deals published before the upgrade are paid per epoch.
+// Deal proposals gain an (absent) renewal term and a typed label, which changes their CIDs, so pending proposals are re-keyed.
+// Pending proposals left behind by deals that cron has already processed are dropped, and the removal of each
+// remaining one is scheduled after its deal's end epoch.
+// Deal ops are moved to the update bucket derived from a hash of each deal's ID.
+// The collateral locked by each party is tabulated from the deal proposals.
+// The set of proposals authorized by their clients in lieu of a signature starts empty.
+type marketMigrator struct{}
+
+func (m marketMigrator) migrateState(ctx context.Context, store cbor.IpldStore, in actorMigrationInput) (*actorMigrationResult, error) {
+	var inState market4.State
+	if err := store.Get(ctx, in.head, &inState); err != nil {
+		return nil, err
+	}
+	adtStore := adt5.WrapStore(ctx, store)
+
+	proposalsOut, pendingOut, pendingExpirations, pendingCount, err := migrateDealProposals(adtStore, inState.Proposals,
+		inState.States, inState.PendingProposals)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to migrate deal proposals: %w", err)
+	}
+
+	statesOut, err := migrateDealStates(adtStore, inState.States)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to migrate deal states: %w", err)
+	}
+
+	emptyUpfrontDeals, err := adt5.StoreEmptyMap(adtStore, builtin5.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create empty upfront payment deals set: %w", err)
+	}
+
+	emptyDealOffers, err := adt5.StoreEmptyArray(adtStore, market5.ProposalsAmtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create empty deal offers array: %w", err)
+	}
+
+	emptyPendingTerminations, err := adt5.StoreEmptyArray(adtStore, market5.PendingTerminationsAmtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create empty pending terminations array: %w", err)
+	}
+
+	dealOpsOut, err := migrateDealOps(adtStore, inState.DealOpsByEpoch)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to migrate deal ops: %w", err)
+	}
+
+	dealsByProvider, dealsByPiece, err := indexDeals(adtStore, inState.Proposals)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to index deals: %w", err)
+	}
+
+	lockedCollateral, err := tabulateLockedCollateral(adtStore, inState.Proposals)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to tabulate locked collateral: %w", err)
+	}
+
+	outState := market5.State{
+		Proposals:                     proposalsOut,
+		States:                        statesOut,
+		PendingProposals:              pendingOut,
+		EscrowTable:                   inState.EscrowTable,
+		LockedTable:                   inState.LockedTable,
+		LockedCollateralTable:         lockedCollateral,
+		NextID:                        inState.NextID,
+		DealOpsByEpoch:                dealOpsOut,
+		LastCron:                      inState.LastCron,
+		TotalClientLockedCollateral:   inState.TotalClientLockedCollateral,
+		TotalProviderLockedCollateral: inState.TotalProviderLockedCollateral,
+		TotalClientStorageFee:         inState.TotalClientStorageFee,
+		UpfrontPaymentDeals:           emptyUpfrontDeals,
+		TotalClientUpfrontPayments:    big.Zero(),
+		TotalProviderStorageFee:       big.Zero(),
+		DealOffers:                    emptyDealOffers,
+		DealsByProvider:               dealsByProvider,
+		DealsByPiece:                  dealsByPiece,
+		AuthorizedProposals:           emptyUpfrontDeals,
+		PendingTerminations:           emptyPendingTerminations,
+		NextPendingTermination:        0,
+		ActivationExtensions:          emptyUpfrontDeals,
+		PendingProposalExpirations:    pendingExpirations,
+		PendingProposalCount:          pendingCount,
+	}
+	newHead, err := store.Put(ctx, &outState)
+	return &actorMigrationResult{
+		newCodeCID: m.migratedCodeCID(),
+		newHead:    newHead,
+	}, err
+}
+
+func (m marketMigrator) migratedCodeCID() cid.Cid {
+	return builtin5.StorageMarketActorCodeID
+}
+
+func migrateDealProposals(store adt5.Store, proposalsRoot, statesRoot, pendingRoot cid.Cid) (proposals, pending, expirations cid.Cid, pendingCount uint64, err error) {
+	inArray, err := adt5.AsArray(store, proposalsRoot, market4.ProposalsAmtBitwidth)
+	if err != nil {
+		return cid.Undef, cid.Undef, cid.Undef, 0, xerrors.Errorf("failed to load deal proposals: %w", err)
+	}
+	inStates, err := adt5.AsArray(store, statesRoot, market4.StatesAmtBitwidth)
+	if err != nil {
+		return cid.Undef, cid.Undef, cid.Undef, 0, xerrors.Errorf("failed to load deal states: %w", err)
+	}
+	inPending, err := adt5.AsSet(store, pendingRoot, builtin5.DefaultHamtBitwidth)
+	if err != nil {
+		return cid.Undef, cid.Undef, cid.Undef, 0, xerrors.Errorf("failed to load pending proposals: %w", err)
+	}
+	outArray, err := adt5.MakeEmptyArray(store, market5.ProposalsAmtBitwidth)
+	if err != nil {
+		return cid.Undef, cid.Undef, cid.Undef, 0, xerrors.Errorf("failed to construct new deal proposals array: %w", err)
+	}
+	outPending, err := adt5.MakeEmptySet(store, builtin5.DefaultHamtBitwidth)
+	if err != nil {
+		return cid.Undef, cid.Undef, cid.Undef, 0, xerrors.Errorf("failed to construct new pending proposals set: %w", err)
+	}
+	outExpirations, err := adt5.MakeEmptyMultimap(store, builtin5.DefaultHamtBitwidth, market5.PendingProposalExpirationsAmtBitwidth)
+	if err != nil {
+		return cid.Undef, cid.Undef, cid.Undef, 0, xerrors.Errorf("failed to construct new pending proposal expirations: %w", err)
+	}
+
+	var inProposal market4.DealProposal
+	if err = inArray.ForEach(&inProposal, func(i int64) error {
+		outProposal := market5.DealProposal{
+			PieceCID:             inProposal.PieceCID,
+			PieceSize:            inProposal.PieceSize,
+			VerifiedDeal:         inProposal.VerifiedDeal,
+			Client:               inProposal.Client,
+			Provider:             inProposal.Provider,
+			Label:                migrateDealLabel(inProposal.Label),
+			StartEpoch:           inProposal.StartEpoch,
+			EndEpoch:             inProposal.EndEpoch,
+			StoragePricePerEpoch: inProposal.StoragePricePerEpoch,
+			ProviderCollateral:   inProposal.ProviderCollateral,
+			ClientCollateral:     inProposal.ClientCollateral,
+		}
+		if err := outArray.Set(uint64(i), &outProposal); err != nil {
+			return err
+		}
+
+		inCid, err := inProposal.Cid()
+		if err != nil {
+			return err
+		}
+		isPending, err := inPending.Has(abi.CidKey(inCid))
+		if err != nil {
+			return err
+		}
+		if !isPending {
+			return nil
+		}
+		// A deal that cron has processed should no longer be pending.
+		var inState market4.DealState
+		found, err := inStates.Get(uint64(i), &inState)
+		if err != nil {
+			return err
+		}
+		if found && inState.LastUpdatedEpoch != -1 {
+			return nil
+		}
+
+		outCid, err := outProposal.Cid()
+		if err != nil {
+			return err
+		}
+		if err := outPending.Put(abi.CidKey(outCid)); err != nil {
+			return err
+		}
+		expiry := outProposal.MaxEndEpoch() + market5.PendingProposalExpiry
+		value := cbg.CborCid(outCid)
+		if err := outExpirations.Add(abi.UIntKey(uint64(expiry)), &value); err != nil {
+			return err
+		}
+		pendingCount++
+		return nil
+	}); err != nil {
+		return cid.Undef, cid.Undef, cid.Undef, 0, err
+	}
+
+	if proposals, err = outArray.Root(); err != nil {
+		return cid.Undef, cid.Undef, cid.Undef, 0, err
+	}
+	if pending, err = outPending.Root(); err != nil {
+		return cid.Undef, cid.Undef, cid.Undef, 0, err
+	}
+	if expirations, err = outExpirations.Root(); err != nil {
+		return cid.Undef, cid.Undef, cid.Undef, 0, err
+	}
+	return proposals, pending, expirations, pendingCount, nil
+}
+
+// Labels that are valid UTF-8 remain strings, and any others become bytes.
+func migrateDealLabel(label string) market5.DealLabel {
+	if out, err := market5.NewLabelFromString(label); err == nil {
+		return out
+	}
+	return market5.NewLabelFromBytes([]byte(label))
+}
+
+func migrateDealStates(store adt5.Store, root cid.Cid) (cid.Cid, error) {
+	inArray, err := adt5.AsArray(store, root, market4.StatesAmtBitwidth)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to load deal states: %w", err)
+	}
+	outArray, err := adt5.MakeEmptyArray(store, market5.StatesAmtBitwidth)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to construct new deal states array: %w", err)
+	}
+
+	var inDealState market4.DealState
+	if err = inArray.ForEach(&inDealState, func(i int64) error {
+		return outArray.Set(uint64(i), &market5.DealState{
+			SectorStartEpoch: inDealState.SectorStartEpoch,
+			LastUpdatedEpoch: inDealState.LastUpdatedEpoch,
+			SlashEpoch:       inDealState.SlashEpoch,
+			PaymentMode:      market5.DealPaymentPerEpoch,
+		})
+	}); err != nil {
+		return cid.Undef, err
+	}
+
+	return outArray.Root()
+}
+
+func indexDeals(store adt5.Store, proposalsRoot cid.Cid) (byProvider, byPiece cid.Cid, err error) {
+	proposals, err := adt5.AsArray(store, proposalsRoot, market4.ProposalsAmtBitwidth)
+	if err != nil {
+		return cid.Undef, cid.Undef, xerrors.Errorf("failed to load deal proposals: %w", err)
+	}
+	providerIndex, err := market5.MakeEmptySetMultimap(store, builtin5.DefaultHamtBitwidth)
+	if err != nil {
+		return cid.Undef, cid.Undef, xerrors.Errorf("failed to construct deals by provider index: %w", err)
+	}
+	pieceIndex, err := market5.MakeEmptySetMultimap(store, builtin5.DefaultHamtBitwidth)
+	if err != nil {
+		return cid.Undef, cid.Undef, xerrors.Errorf("failed to construct deals by piece index: %w", err)
+	}
+
+	var proposal market4.DealProposal
+	if err = proposals.ForEach(&proposal, func(i int64) error {
+		if err := providerIndex.PutKey(abi.AddrKey(proposal.Provider), abi.DealID(i)); err != nil {
+			return err
+		}
+		return pieceIndex.PutKey(abi.CidKey(proposal.PieceCID), abi.DealID(i))
+	}); err != nil {
+		return cid.Undef, cid.Undef, err
+	}
+
+	if byProvider, err = providerIndex.Root(); err != nil {
+		return cid.Undef, cid.Undef, err
+	}
+	if byPiece, err = pieceIndex.Root(); err != nil {
+		return cid.Undef, cid.Undef, err
+	}
+	return byProvider, byPiece, nil
+}
+
+// Sums the client and provider collateral of every deal proposal, which is locked until the deal is removed.
+func tabulateLockedCollateral(store adt5.Store, proposalsRoot cid.Cid) (cid.Cid, error) {
+	proposals, err := adt5.AsArray(store, proposalsRoot, market4.ProposalsAmtBitwidth)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to load deal proposals: %w", err)
+	}
+	emptyTable, err := adt5.StoreEmptyMap(store, adt5.BalanceTableBitwidth)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to create empty locked collateral table: %w", err)
+	}
+	table, err := adt5.AsBalanceTable(store, emptyTable)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to load locked collateral table: %w", err)
+	}
+
+	var proposal market4.DealProposal
+	if err = proposals.ForEach(&proposal, func(i int64) error {
+		if err := table.Add(proposal.Client, proposal.ClientCollateral); err != nil {
+			return err
+		}
+		return table.Add(proposal.Provider, proposal.ProviderCollateral)
+	}); err != nil {
+		return cid.Undef, err
+	}
+	return table.Root()
+}
+
+// Reschedules each deal op to the first epoch in the deal's update bucket at or after its scheduled epoch.
+// Deal ops are never scheduled before a deal's start epoch, so neither are the rescheduled ones.
+func migrateDealOps(store adt5.Store, root cid.Cid) (cid.Cid, error) {
+	inOps, err := adt5.AsMap(store, root, builtin5.DefaultHamtBitwidth)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to load deal ops: %w", err)
+	}
+	outOps, err := market5.MakeEmptySetMultimap(store, builtin5.DefaultHamtBitwidth)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to construct new deal ops: %w", err)
+	}
+
+	rescheduled := make(map[abi.ChainEpoch][]abi.DealID)
+	var setRoot cbg.CborCid
+	if err = inOps.ForEach(&setRoot, func(k string) error {
+		epoch, err := abi.ParseUIntKey(k)
+		if err != nil {
+			return xerrors.Errorf("deal ops key %s is not an epoch: %w", k, err)
+		}
+		set, err := adt5.AsSet(store, cid.Cid(setRoot), builtin5.DefaultHamtBitwidth)
+		if err != nil {
+			return err
+		}
+		return set.ForEach(func(k string) error {
+			id, err := abi.ParseUIntKey(k)
+			if err != nil {
+				return xerrors.Errorf("deal ops key %s is not a deal ID: %w", k, err)
+			}
+			next := market5.GenRandNextEpoch(abi.ChainEpoch(epoch), abi.DealID(id))
+			rescheduled[next] = append(rescheduled[next], abi.DealID(id))
+			return nil
+		})
+	}); err != nil {
+		return cid.Undef, err
+	}
+
+	// Write in epoch order so the resulting state doesn't depend on map iteration.
+	epochs := make([]abi.ChainEpoch, 0, len(rescheduled))
+	for epoch := range rescheduled { //nolint:nomaprange
+		epochs = append(epochs, epoch)
+	}
+	sort.Slice(epochs, func(i, j int) bool { return epochs[i] < epochs[j] })
+	for _, epoch := range epochs {
+		if err := outOps.PutMany(epoch, rescheduled[epoch]); err != nil {
+			return cid.Undef, err
+		}
+	}
+	return outOps.Root()
+}
@@ -0,0 +1,194 @@
+package nv13
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-bitfield"
+	builtin4 "github.com/filecoin-project/specs-actors/v4/actors/builtin"
+	miner4 "github.com/filecoin-project/specs-actors/v4/actors/builtin/miner"
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"golang.org/x/xerrors"
+
+	builtin5 "github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	miner5 "github.com/filecoin-project/specs-actors/v5/actors/builtin/miner"
+	adt5 "github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+)
+
+// Miner migrator adds the seal randomness epoch and attested pieces to sector on-chain info, adds attested
+// pieces to pre-committed sector info, adds the deadline assignment policy to miner info, and prunes deadline
+// snapshots that are no longer needed to dispute window PoSts.
+// The seal randomness epoch of existing sectors is not known, and is recorded as miner5.NoSealRandEpoch.
+// Existing sectors and pre-commitments have no attested pieces.
+// Existing miners assign sectors to deadlines with the default policy, as they did before.
+// Prior to this version, snapshots were retained until replaced at the deadline's next challenge window, or
+// indefinitely for deadlines with no live sectors.
+// No existing miner has expirations pending processing, since prior versions processed all expirations of a
+// deadline in its cron event. Reliability counters start from zero, no sector has a declared region, and
+// no pre-commitment has a designated aggregator.
+// Migrated states record the current schema version.
+type minerMigrator struct{}
+
+func (m minerMigrator) migrateState(ctx context.Context, store cbor.IpldStore, in actorMigrationInput) (*actorMigrationResult, error) {
+	var inState miner4.State
+	if err := store.Get(ctx, in.head, &inState); err != nil {
+		return nil, err
+	}
+	st := miner5.State{
+		Info:                       inState.Info,
+		PreCommitDeposits:          inState.PreCommitDeposits,
+		LockedFunds:                inState.LockedFunds,
+		VestingFunds:               inState.VestingFunds,
+		FeeDebt:                    inState.FeeDebt,
+		InitialPledge:              inState.InitialPledge,
+		PreCommittedSectors:        inState.PreCommittedSectors,
+		PreCommittedSectorsCleanUp: inState.PreCommittedSectorsExpiry,
+		AllocatedSectors:           inState.AllocatedSectors,
+		Sectors:                    inState.Sectors,
+		ProvingPeriodStart:         inState.ProvingPeriodStart,
+		CurrentDeadline:            inState.CurrentDeadline,
+		Deadlines:                  inState.Deadlines,
+		EarlyTerminations:          inState.EarlyTerminations,
+		DeadlineCronActive:         inState.DeadlineCronActive,
+		ExpirationsPending:         bitfield.New(),
+		ReliabilityStats:           miner5.ReliabilityStats{},
+		SchemaVersion:              miner5.StateSchemaVersion,
+	}
+	adtStore := adt5.WrapStore(ctx, store)
+
+	emptySectorRegions, err := adt5.StoreEmptyMap(adtStore, builtin5.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create empty sector regions for miner %s: %w", in.address, err)
+	}
+	st.SectorRegions = emptySectorRegions
+
+	emptyPreCommitAggregators, err := adt5.StoreEmptyMap(adtStore, builtin5.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create empty pre-commit aggregators for miner %s: %w", in.address, err)
+	}
+	st.PreCommitAggregators = emptyPreCommitAggregators
+
+	sectorsOut, err := in.cache.Load(SectorsAmtKey(st.Sectors), func() (cid.Cid, error) {
+		return migrateSectors(adtStore, st.Sectors)
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to migrate sectors for miner %s: %w", in.address, err)
+	}
+	st.Sectors = sectorsOut
+
+	st.PreCommittedSectors, err = migratePreCommittedSectors(adtStore, st.PreCommittedSectors)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to migrate pre-committed sectors for miner %s: %w", in.address, err)
+	}
+
+	st.Info, err = migrateInfo(ctx, store, st.Info)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to migrate info for miner %s: %w", in.address, err)
+	}
+
+	if _, err := st.PruneDeadlineSnapshots(adtStore, in.priorEpoch); err != nil {
+		return nil, xerrors.Errorf("failed to prune deadline snapshots for miner %s: %w", in.address, err)
+	}
+
+	newHead, err := store.Put(ctx, &st)
+	return &actorMigrationResult{
+		newCodeCID: m.migratedCodeCID(),
+		newHead:    newHead,
+	}, err
+}
+
+func (m minerMigrator) migratedCodeCID() cid.Cid {
+	return builtin5.StorageMinerActorCodeID
+}
+
+func migrateInfo(ctx context.Context, store cbor.IpldStore, c cid.Cid) (cid.Cid, error) {
+	var oldInfo miner4.MinerInfo
+	if err := store.Get(ctx, c, &oldInfo); err != nil {
+		return cid.Undef, err
+	}
+
+	var newWorkerKey *miner5.WorkerKeyChange
+	if oldInfo.PendingWorkerKey != nil {
+		newWorkerKey = &miner5.WorkerKeyChange{
+			NewWorker:   oldInfo.PendingWorkerKey.NewWorker,
+			EffectiveAt: oldInfo.PendingWorkerKey.EffectiveAt,
+		}
+	}
+
+	newInfo := miner5.MinerInfo{
+		Owner:                      oldInfo.Owner,
+		Worker:                     oldInfo.Worker,
+		ControlAddresses:           oldInfo.ControlAddresses,
+		PendingWorkerKey:           newWorkerKey,
+		PeerId:                     oldInfo.PeerId,
+		Multiaddrs:                 oldInfo.Multiaddrs,
+		WindowPoStProofType:        oldInfo.WindowPoStProofType,
+		SectorSize:                 oldInfo.SectorSize,
+		WindowPoStPartitionSectors: oldInfo.WindowPoStPartitionSectors,
+		ConsensusFaultElapsed:      oldInfo.ConsensusFaultElapsed,
+		PendingOwnerAddress:        oldInfo.PendingOwnerAddress,
+		DeadlineAssignment:         miner5.DeadlineAssignmentSpread,
+	}
+	return store.Put(ctx, &newInfo)
+}
+
+func migrateSectors(store adt5.Store, root cid.Cid) (cid.Cid, error) {
+	inArray, err := adt5.AsArray(store, root, miner4.SectorsAmtBitwidth)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to load sectors: %w", err)
+	}
+	outArray, err := adt5.MakeEmptyArray(store, miner5.SectorsAmtBitwidth)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to construct new sectors array: %w", err)
+	}
+
+	var inSector miner4.SectorOnChainInfo
+	if err = inArray.ForEach(&inSector, func(i int64) error {
+		outSector := miner5.SectorOnChainInfo{
+			SectorNumber:          inSector.SectorNumber,
+			SealProof:             inSector.SealProof,
+			SealedCID:             inSector.SealedCID,
+			DealIDs:               inSector.DealIDs,
+			Activation:            inSector.Activation,
+			Expiration:            inSector.Expiration,
+			DealWeight:            inSector.DealWeight,
+			VerifiedDealWeight:    inSector.VerifiedDealWeight,
+			InitialPledge:         inSector.InitialPledge,
+			ExpectedDayReward:     inSector.ExpectedDayReward,
+			ExpectedStoragePledge: inSector.ExpectedStoragePledge,
+			ReplacedSectorAge:     inSector.ReplacedSectorAge,
+			ReplacedDayReward:     inSector.ReplacedDayReward,
+			SealRandEpoch:         miner5.NoSealRandEpoch,
+		}
+		return outArray.Set(uint64(i), &outSector)
+	}); err != nil {
+		return cid.Undef, err
+	}
+	return outArray.Root()
+}
+
+func migratePreCommittedSectors(store adt5.Store, root cid.Cid) (cid.Cid, error) {
+	inMap, err := adt5.AsMap(store, root, builtin4.DefaultHamtBitwidth)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to load pre-committed sectors: %w", err)
+	}
+	outMap, err := adt5.MakeEmptyMap(store, builtin5.DefaultHamtBitwidth)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to construct new pre-committed sectors map: %w", err)
+	}
+
+	var inPrecommit miner4.SectorPreCommitOnChainInfo
+	if err = inMap.ForEach(&inPrecommit, func(_ string) error {
+		outPrecommit := miner5.SectorPreCommitOnChainInfo{
+			Info:               miner5.SectorPreCommitInfo(inPrecommit.Info),
+			PreCommitDeposit:   inPrecommit.PreCommitDeposit,
+			PreCommitEpoch:     inPrecommit.PreCommitEpoch,
+			DealWeight:         inPrecommit.DealWeight,
+			VerifiedDealWeight: inPrecommit.VerifiedDealWeight,
+		}
+		return outMap.Put(miner5.SectorKey(inPrecommit.Info.SectorNumber), &outPrecommit)
+	}); err != nil {
+		return cid.Undef, err
+	}
+	return outMap.Root()
+}
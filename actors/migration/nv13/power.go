@@ -0,0 +1,116 @@
+package nv13
+
+import (
+	"context"
+	"sort"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	power4 "github.com/filecoin-project/specs-actors/v4/actors/builtin/power"
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"golang.org/x/xerrors"
+
+	builtin5 "github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	power5 "github.com/filecoin-project/specs-actors/v5/actors/builtin/power"
+	adt5 "github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+	smoothing5 "github.com/filecoin-project/specs-actors/v5/actors/util/smoothing"
+)
+
+// Power migrator adds the (empty) history of total power checkpoints to the power state.
+// The first checkpoint is taken at the first cron tick after the upgrade.
+// The power committed for each proof type is summed from the claims, and the cron event queue is compacted,
+// dropping events for miners without a claim and duplicate events.
+// The history of consensus faults starts empty, as earlier faults were not recorded by the power actor.
+type powerMigrator struct{}
+
+func (m powerMigrator) migrateState(ctx context.Context, store cbor.IpldStore, in actorMigrationInput) (*actorMigrationResult, error) {
+	var inState power4.State
+	if err := store.Get(ctx, in.head, &inState); err != nil {
+		return nil, err
+	}
+
+	emptyCheckpoints, err := store.Put(ctx, &power5.PowerCheckpoints{})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create empty power checkpoints: %w", err)
+	}
+	adtStore := adt5.WrapStore(ctx, store)
+	proofTypePower, err := sumProofTypePower(adtStore, inState.Claims)
+	if err != nil {
+		return nil, err
+	}
+	emptyConsensusFaults, err := adt5.StoreEmptyMap(adtStore, builtin5.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create empty consensus faults map: %w", err)
+	}
+
+	outState := power5.State{
+		TotalRawBytePower:         inState.TotalRawBytePower,
+		TotalBytesCommitted:       inState.TotalBytesCommitted,
+		TotalQualityAdjPower:      inState.TotalQualityAdjPower,
+		TotalQABytesCommitted:     inState.TotalQABytesCommitted,
+		TotalPledgeCollateral:     inState.TotalPledgeCollateral,
+		ThisEpochRawBytePower:     inState.ThisEpochRawBytePower,
+		ThisEpochQualityAdjPower:  inState.ThisEpochQualityAdjPower,
+		ThisEpochPledgeCollateral: inState.ThisEpochPledgeCollateral,
+		ThisEpochQAPowerSmoothed:  smoothing5.FilterEstimate(inState.ThisEpochQAPowerSmoothed),
+		MinerCount:                inState.MinerCount,
+		MinerAboveMinPowerCount:   inState.MinerAboveMinPowerCount,
+		CronEventQueue:            inState.CronEventQueue,
+		FirstCronEpoch:            inState.FirstCronEpoch,
+		Claims:                    inState.Claims,
+		ProofValidationBatch:      inState.ProofValidationBatch,
+		PowerCheckpoints:          emptyCheckpoints,
+		ProofTypePower:            proofTypePower,
+		ConsensusFaults:           emptyConsensusFaults,
+	}
+	if _, err := outState.CompactCronEventQueue(adtStore); err != nil {
+		return nil, xerrors.Errorf("failed to compact cron event queue: %w", err)
+	}
+	newHead, err := store.Put(ctx, &outState)
+	return &actorMigrationResult{
+		newCodeCID: m.migratedCodeCID(),
+		newHead:    newHead,
+	}, err
+}
+
+func (m powerMigrator) migratedCodeCID() cid.Cid {
+	return builtin5.StoragePowerActorCodeID
+}
+
+// Sums the power claimed by miners of each proof type, omitting proof types with no claimed power.
+func sumProofTypePower(store adt5.Store, claimsRoot cid.Cid) ([]power5.ProofTypePower, error) {
+	claims, err := adt5.AsMap(store, claimsRoot, builtin5.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load claims: %w", err)
+	}
+
+	totals := map[abi.RegisteredPoStProof]*power5.ProofTypePower{}
+	var claim power4.Claim
+	if err := claims.ForEach(&claim, func(_ string) error {
+		if claim.RawBytePower.IsZero() && claim.QualityAdjPower.IsZero() {
+			return nil
+		}
+		total, ok := totals[claim.WindowPoStProofType]
+		if !ok {
+			total = &power5.ProofTypePower{
+				WindowPoStProofType: claim.WindowPoStProofType,
+				RawBytePower:        big.Zero(),
+				QualityAdjPower:     big.Zero(),
+			}
+			totals[claim.WindowPoStProofType] = total
+		}
+		total.RawBytePower = big.Add(total.RawBytePower, claim.RawBytePower)
+		total.QualityAdjPower = big.Add(total.QualityAdjPower, claim.QualityAdjPower)
+		return nil
+	}); err != nil {
+		return nil, xerrors.Errorf("failed to iterate claims: %w", err)
+	}
+
+	var out []power5.ProofTypePower
+	for _, total := range totals {
+		out = append(out, *total)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].WindowPoStProofType < out[j].WindowPoStProofType })
+	return out, nil
+}
@@ -0,0 +1,146 @@
+package test_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/filecoin-project/go-state-types/dline"
+	builtin4 "github.com/filecoin-project/specs-actors/v4/actors/builtin"
+	market4 "github.com/filecoin-project/specs-actors/v4/actors/builtin/market"
+	miner4 "github.com/filecoin-project/specs-actors/v4/actors/builtin/miner"
+	power4 "github.com/filecoin-project/specs-actors/v4/actors/builtin/power"
+	proof4 "github.com/filecoin-project/specs-actors/v4/actors/runtime/proof"
+	tutil4 "github.com/filecoin-project/specs-actors/v4/support/testing"
+	vm4 "github.com/filecoin-project/specs-actors/v4/support/vm"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/migration/nv13"
+	"github.com/filecoin-project/specs-actors/v5/actors/states"
+	adt5 "github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+)
+
+const sealProof = abi.RegisteredSealProof_StackedDrg32GiBV1_1
+
+// Creates a miner in a v4 state tree and commits a sector holding a single deal.
+// Returns the VM at the epoch in which the sector's proof was confirmed.
+func setupMinerWithDealSector(ctx context.Context, t *testing.T, v *vm4.VM, sectorNumber abi.SectorNumber) (*vm4.VM, address.Address, *power4.CreateMinerReturn, abi.DealID) {
+	addrs := vm4.CreateAccounts(ctx, t, v, 2, big.Mul(big.NewInt(10_000), vm4.FIL), 93837778)
+	worker, client := addrs[0], addrs[1]
+
+	createMinerParams := power4.CreateMinerParams{
+		Owner:               worker,
+		Worker:              worker,
+		WindowPoStProofType: abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
+		Peer:                abi.PeerID("not really a peer id"),
+	}
+	ret := vm4.ApplyOk(t, v, worker, builtin4.StoragePowerActorAddr, big.Mul(big.NewInt(1_000), vm4.FIL), builtin4.MethodsPower.CreateMiner, &createMinerParams)
+	minerAddrs, ok := ret.(*power4.CreateMinerReturn)
+	require.True(t, ok)
+
+	// advance vm so we can have seal randomness epoch in the past
+	v, err := v.WithEpoch(200)
+	require.NoError(t, err)
+
+	// add market collateral for client and miner, and publish a deal
+	vm4.ApplyOk(t, v, client, builtin4.StorageMarketActorAddr, big.Mul(big.NewInt(3), vm4.FIL), builtin4.MethodsMarket.AddBalance, &client)
+	vm4.ApplyOk(t, v, worker, builtin4.StorageMarketActorAddr, big.Mul(big.NewInt(64), vm4.FIL), builtin4.MethodsMarket.AddBalance, &minerAddrs.IDAddress)
+
+	dealStart := v.GetEpoch() + miner4.MaxProveCommitDuration[sealProof]
+	publishParams := market4.PublishStorageDealsParams{
+		Deals: []market4.ClientDealProposal{{
+			Proposal: market4.DealProposal{
+				PieceCID:             tutil4.MakeCID("deal1", &market4.PieceCIDPrefix),
+				PieceSize:            1 << 30,
+				Client:               client,
+				Provider:             minerAddrs.IDAddress,
+				Label:                "deal1",
+				StartEpoch:           dealStart,
+				EndEpoch:             dealStart + 181*builtin4.EpochsInDay,
+				StoragePricePerEpoch: abi.NewTokenAmount(1 << 20),
+				ProviderCollateral:   big.Mul(big.NewInt(2), vm4.FIL),
+				ClientCollateral:     big.Mul(big.NewInt(1), vm4.FIL),
+			},
+			ClientSignature: crypto.Signature{},
+		}},
+	}
+	ret = vm4.ApplyOk(t, v, worker, builtin4.StorageMarketActorAddr, big.Zero(), builtin4.MethodsMarket.PublishStorageDeals, &publishParams)
+	dealIDs := ret.(*market4.PublishStorageDealsReturn).IDs
+	require.Len(t, dealIDs, 1)
+
+	v = commitSector(t, v, worker, minerAddrs.IDAddress, sectorNumber, dealIDs)
+	return v, worker, minerAddrs, dealIDs[0]
+}
+
+// Pre-commits and proves a sector, returning the VM at the epoch in which the proof was confirmed.
+func commitSector(t *testing.T, v *vm4.VM, worker, minerID address.Address, sectorNumber abi.SectorNumber, dealIDs []abi.DealID) *vm4.VM {
+	preCommitSector(t, v, worker, minerID, sectorNumber, dealIDs)
+
+	proveTime := v.GetEpoch() + miner4.PreCommitChallengeDelay + 1
+	v, _ = vm4.AdvanceByDeadlineTillEpoch(t, v, minerID, proveTime)
+	v, err := v.WithEpoch(proveTime)
+	require.NoError(t, err)
+	return proveCommitSector(t, v, worker, minerID, sectorNumber)
+}
+
+func preCommitSector(t *testing.T, v *vm4.VM, worker, minerID address.Address, sectorNumber abi.SectorNumber, dealIDs []abi.DealID) {
+	preCommitParams := miner4.PreCommitSectorParams{
+		SealProof:     sealProof,
+		SectorNumber:  sectorNumber,
+		SealedCID:     tutil4.MakeCID(sectorNumber.String(), &miner4.SealedCIDPrefix),
+		SealRandEpoch: v.GetEpoch() - 1,
+		DealIDs:       dealIDs,
+		Expiration:    v.GetEpoch() + 220*builtin4.EpochsInDay,
+	}
+	vm4.ApplyOk(t, v, worker, minerID, big.Zero(), builtin4.MethodsMiner.PreCommitSector, &preCommitParams)
+}
+
+// Proves a pre-committed sector and runs cron in the same epoch to confirm the proof.
+func proveCommitSector(t *testing.T, v *vm4.VM, worker, minerID address.Address, sectorNumber abi.SectorNumber) *vm4.VM {
+	proveCommitParams := miner4.ProveCommitSectorParams{
+		SectorNumber: sectorNumber,
+	}
+	vm4.ApplyOk(t, v, worker, minerID, big.Zero(), builtin4.MethodsMiner.ProveCommitSector, &proveCommitParams)
+	vm4.ApplyOk(t, v, builtin4.SystemActorAddr, builtin4.CronActorAddr, big.Zero(), builtin4.MethodsCron.EpochTick, nil)
+	return v
+}
+
+// Submits a window PoSt for a single partition of the given deadline.
+func submitWindowPoSt(t *testing.T, v *vm4.VM, worker, minerID address.Address, dlInfo *dline.Info, pIdx uint64) {
+	submitParams := miner4.SubmitWindowedPoStParams{
+		Deadline: dlInfo.Index,
+		Partitions: []miner4.PoStPartition{{
+			Index:   pIdx,
+			Skipped: bitfield.New(),
+		}},
+		Proofs: []proof4.PoStProof{{
+			PoStProof: abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
+		}},
+		ChainCommitEpoch: dlInfo.Challenge,
+		ChainCommitRand:  []byte("not really random"),
+	}
+	vm4.ApplyOk(t, v, worker, minerID, big.Zero(), builtin4.MethodsMiner.SubmitWindowedPoSt, &submitParams)
+}
+
+// Migrates the VM's state tree at the VM's current epoch, and checks the invariants of the migrated state.
+func migrateAndCheckState(ctx context.Context, t *testing.T, bs cbor.IpldBlockstore, v *vm4.VM) *states.Tree {
+	adtStore := adt5.WrapStore(ctx, cbor.NewCborStore(bs))
+	endRoot, err := nv13.MigrateStateTree(ctx, adtStore, v.StateRoot(), v.GetEpoch(), nv13.Config{MaxWorkers: 1}, nv13.TestLogger{TB: t}, nv13.NewMemMigrationCache())
+	require.NoError(t, err)
+
+	tree, err := states.LoadTree(adtStore, endRoot)
+	require.NoError(t, err)
+	totalBalance, err := v.GetTotalActorBalance()
+	require.NoError(t, err)
+	acc, err := states.CheckStateInvariants(tree, totalBalance, v.GetEpoch())
+	require.NoError(t, err)
+	assert.True(t, acc.IsEmpty(), strings.Join(acc.Messages(), "\n"))
+	return tree
+}
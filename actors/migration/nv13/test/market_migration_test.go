@@ -0,0 +1,86 @@
+package test_test
+
+import (
+	"context"
+	"testing"
+
+	ipld2 "github.com/filecoin-project/specs-actors/v2/support/ipld"
+	builtin4 "github.com/filecoin-project/specs-actors/v4/actors/builtin"
+	market4 "github.com/filecoin-project/specs-actors/v4/actors/builtin/market"
+	vm4 "github.com/filecoin-project/specs-actors/v4/support/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/market"
+)
+
+func TestMarketMigrationWithActiveDeal(t *testing.T) {
+	ctx := context.Background()
+	bs := ipld2.NewSyncBlockStoreInMemory()
+	v := vm4.NewVMWithSingletons(ctx, t, bs)
+
+	v, _, _, dealID := setupMinerWithDealSector(ctx, t, v, 100)
+	deal, found := vm4.GetDealState(t, v, dealID)
+	require.True(t, found)
+	require.Equal(t, v.GetEpoch(), deal.SectorStartEpoch)
+
+	tree := migrateAndCheckState(ctx, t, bs, v)
+
+	marketActor, found, err := tree.GetActor(builtin.StorageMarketActorAddr)
+	require.NoError(t, err)
+	require.True(t, found)
+	var st market.State
+	require.NoError(t, tree.Store.Get(ctx, marketActor.Head, &st))
+
+	summary, msgs := market.CheckStateInvariants(&st, tree.Store, marketActor.Balance, v.GetEpoch())
+	assert.True(t, msgs.IsEmpty(), msgs.Messages())
+
+	// Deals activated before the migration are paid per epoch, and none are awaiting upfront payment.
+	require.Contains(t, summary.Deals, dealID)
+	assert.Equal(t, market.DealPaymentPerEpoch, summary.Deals[dealID].PaymentMode)
+	assert.Equal(t, deal.SectorStartEpoch, summary.Deals[dealID].SectorStartEpoch)
+	assert.Equal(t, uint64(0), summary.UpfrontPaymentDealCount)
+	assert.Equal(t, uint64(1), summary.DealStateCount)
+
+	// The proposal of the deal, not yet processed by cron, remains pending under its new CID.
+	assert.Equal(t, uint64(1), summary.PendingProposalCount)
+	assert.Equal(t, uint64(1), st.PendingProposalCount)
+}
+
+func TestMarketMigrationDropsProcessedPendingProposal(t *testing.T) {
+	ctx := context.Background()
+	bs := ipld2.NewSyncBlockStoreInMemory()
+	v := vm4.NewVMWithSingletons(ctx, t, bs)
+
+	v, _, _, dealID := setupMinerWithDealSector(ctx, t, v, 100)
+
+	// Mark the deal as processed by cron while leaving its proposal pending.
+	var st4 market4.State
+	require.NoError(t, v.GetState(builtin4.StorageMarketActorAddr, &st4))
+	states, err := market4.AsDealStateArray(v.Store(), st4.States)
+	require.NoError(t, err)
+	deal, found, err := states.Get(dealID)
+	require.NoError(t, err)
+	require.True(t, found)
+	deal.LastUpdatedEpoch = v.GetEpoch()
+	require.NoError(t, states.Set(dealID, deal))
+	st4.States, err = states.Root()
+	require.NoError(t, err)
+	require.NoError(t, v.SetActorState(ctx, builtin4.StorageMarketActorAddr, &st4))
+	v, err = v.WithEpoch(v.GetEpoch()) // flush the state tree
+	require.NoError(t, err)
+
+	tree := migrateAndCheckState(ctx, t, bs, v)
+
+	marketActor, found, err := tree.GetActor(builtin.StorageMarketActorAddr)
+	require.NoError(t, err)
+	require.True(t, found)
+	var st market.State
+	require.NoError(t, tree.Store.Get(ctx, marketActor.Head, &st))
+
+	summary, msgs := market.CheckStateInvariants(&st, tree.Store, marketActor.Balance, v.GetEpoch())
+	assert.True(t, msgs.IsEmpty(), msgs.Messages())
+	assert.Equal(t, uint64(0), summary.PendingProposalCount)
+	assert.Equal(t, uint64(0), st.PendingProposalCount)
+}
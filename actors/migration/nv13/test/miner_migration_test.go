@@ -0,0 +1,184 @@
+package test_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/dline"
+	ipld2 "github.com/filecoin-project/specs-actors/v2/support/ipld"
+	builtin4 "github.com/filecoin-project/specs-actors/v4/actors/builtin"
+	miner4 "github.com/filecoin-project/specs-actors/v4/actors/builtin/miner"
+	power4 "github.com/filecoin-project/specs-actors/v4/actors/builtin/power"
+	vm4 "github.com/filecoin-project/specs-actors/v4/support/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/miner"
+)
+
+func TestMinerMigrationWithSectors(t *testing.T) {
+	ctx := context.Background()
+	bs := ipld2.NewSyncBlockStoreInMemory()
+	v := vm4.NewVMWithSingletons(ctx, t, bs)
+
+	sectorNumber := abi.SectorNumber(100)
+	v, worker, minerAddrs, dealID := setupMinerWithDealSector(ctx, t, v, sectorNumber)
+
+	// A second miner with no sectors
+	createMinerParams := power4.CreateMinerParams{
+		Owner:               worker,
+		Worker:              worker,
+		WindowPoStProofType: abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
+		Peer:                abi.PeerID("not really a peer id"),
+	}
+	ret := vm4.ApplyOk(t, v, worker, builtin4.StoragePowerActorAddr, big.Mul(big.NewInt(1_000), vm4.FIL), builtin4.MethodsPower.CreateMiner, &createMinerParams)
+	emptyMinerAddrs, ok := ret.(*power4.CreateMinerReturn)
+	require.True(t, ok)
+
+	var stIn miner4.State
+	require.NoError(t, v.GetState(minerAddrs.IDAddress, &stIn))
+	sectorIn, found, err := stIn.GetSector(v.Store(), sectorNumber)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	tree := migrateAndCheckState(ctx, t, bs, v)
+
+	minerActor, found, err := tree.GetActor(minerAddrs.IDAddress)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, builtin.StorageMinerActorCodeID, minerActor.Code)
+	var st miner.State
+	require.NoError(t, tree.Store.Get(ctx, minerActor.Head, &st))
+
+	summary, msgs := miner.CheckStateInvariants(&st, tree.Store, minerActor.Balance)
+	assert.True(t, msgs.IsEmpty(), msgs.Messages())
+	assert.Contains(t, summary.Deals, dealID)
+
+	// The seal randomness epoch of migrated sectors is unknown, all other fields are carried over.
+	sector, found, err := st.GetSector(tree.Store, sectorNumber)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, miner.NoSealRandEpoch, sector.SealRandEpoch)
+	assert.Equal(t, sectorIn.SealedCID, sector.SealedCID)
+	assert.Equal(t, sectorIn.DealIDs, sector.DealIDs)
+	assert.Equal(t, sectorIn.Activation, sector.Activation)
+	assert.Equal(t, sectorIn.Expiration, sector.Expiration)
+	assert.Equal(t, sectorIn.InitialPledge, sector.InitialPledge)
+
+	// Miner info is carried over, with the default deadline assignment policy.
+	infoIn, err := stIn.GetInfo(v.Store())
+	require.NoError(t, err)
+	info, err := st.GetInfo(tree.Store)
+	require.NoError(t, err)
+	assert.Equal(t, infoIn.Owner, info.Owner)
+	assert.Equal(t, infoIn.Worker, info.Worker)
+	assert.Equal(t, infoIn.PeerId, info.PeerId)
+	assert.Equal(t, infoIn.WindowPoStProofType, info.WindowPoStProofType)
+	assert.Equal(t, infoIn.WindowPoStPartitionSectors, info.WindowPoStPartitionSectors)
+	assert.Equal(t, infoIn.ConsensusFaultElapsed, info.ConsensusFaultElapsed)
+	assert.Equal(t, miner.DeadlineAssignmentSpread, info.DeadlineAssignment)
+
+	// A miner with no sectors retains its state other than its info.
+	emptyMiner, found, err := tree.GetActor(emptyMinerAddrs.IDAddress)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, builtin.StorageMinerActorCodeID, emptyMiner.Code)
+	var emptyStIn miner4.State
+	require.NoError(t, v.GetState(emptyMinerAddrs.IDAddress, &emptyStIn))
+	var emptySt miner.State
+	require.NoError(t, tree.Store.Get(ctx, emptyMiner.Head, &emptySt))
+	assert.NotEqual(t, emptyStIn.Info, emptySt.Info)
+	assert.Equal(t, emptyStIn.Sectors, emptySt.Sectors)
+	assert.Equal(t, emptyStIn.Deadlines, emptySt.Deadlines)
+}
+
+func TestMinerMigrationPrunesExpiredSnapshots(t *testing.T) {
+	ctx := context.Background()
+	bs := ipld2.NewSyncBlockStoreInMemory()
+	v := vm4.NewVMWithSingletons(ctx, t, bs)
+
+	v, worker, minerAddrs, _ := setupMinerWithDealSector(ctx, t, v, 100)
+	minerID := minerAddrs.IDAddress
+
+	// Pre-commit a second sector, to be proven while the first sector's deadline is immutable so that
+	// it is assigned to a different deadline.
+	preCommitSector(t, v, worker, minerID, 101, nil)
+	proveAfter := v.GetEpoch() + miner4.PreCommitChallengeDelay
+
+	var dlInfo1 *dline.Info
+	var pIdx uint64
+	for {
+		dlInfo1, pIdx, v = vm4.AdvanceTillProvingDeadline(t, v, minerID, 100)
+		submitWindowPoSt(t, v, worker, minerID, dlInfo1, pIdx)
+		if v.GetEpoch() > proveAfter {
+			break
+		}
+		v, _ = vm4.AdvanceByDeadlineTillIndex(t, v, minerID, (dlInfo1.Index+1)%miner4.WPoStPeriodDeadlines)
+	}
+	v = proveCommitSector(t, v, worker, minerID, 101)
+
+	// Close the first deadline, taking its snapshot, then prove the second sector in its own deadline.
+	v, _ = vm4.AdvanceByDeadlineTillIndex(t, v, minerID, (dlInfo1.Index+1)%miner4.WPoStPeriodDeadlines)
+	dlInfo2, pIdx, v := vm4.AdvanceTillProvingDeadline(t, v, minerID, 101)
+	require.NotEqual(t, dlInfo1.Index, dlInfo2.Index)
+	submitWindowPoSt(t, v, worker, minerID, dlInfo2, pIdx)
+	v, _ = vm4.AdvanceByDeadlineTillIndex(t, v, minerID, (dlInfo2.Index+1)%miner4.WPoStPeriodDeadlines)
+
+	// Advance to the epoch at the end of which the first deadline's snapshot has been retained for
+	// WPoStSnapshotRetention. The second deadline closed later, so its snapshot is still retained.
+	migrationEpoch := dlInfo1.Close + miner.WPoStSnapshotRetention - 1
+	require.True(t, migrationEpoch < dlInfo2.Close+miner.WPoStSnapshotRetention-1)
+	if v.GetEpoch() < migrationEpoch {
+		v, _ = vm4.AdvanceByDeadlineTillEpoch(t, v, minerID, migrationEpoch)
+		var err error
+		v, err = v.WithEpoch(migrationEpoch)
+		require.NoError(t, err)
+		vm4.ApplyOk(t, v, builtin4.SystemActorAddr, builtin4.CronActorAddr, big.Zero(), builtin4.MethodsCron.EpochTick, nil)
+	}
+
+	// Both deadlines hold snapshots prior to migration.
+	var stIn miner4.State
+	require.NoError(t, v.GetState(minerID, &stIn))
+	deadlinesIn, err := stIn.LoadDeadlines(v.Store())
+	require.NoError(t, err)
+	for _, dlIdx := range []uint64{dlInfo1.Index, dlInfo2.Index} {
+		dl, err := deadlinesIn.LoadDeadline(v.Store(), dlIdx)
+		require.NoError(t, err)
+		snapshot, err := dl.PartitionsSnapshotArray(v.Store())
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), snapshot.Length())
+		proofs, err := dl.OptimisticProofsSnapshotArray(v.Store())
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), proofs.Length())
+	}
+
+	tree := migrateAndCheckState(ctx, t, bs, v)
+
+	minerActor, found, err := tree.GetActor(minerID)
+	require.NoError(t, err)
+	require.True(t, found)
+	var st miner.State
+	require.NoError(t, tree.Store.Get(ctx, minerActor.Head, &st))
+	_, msgs := miner.CheckStateInvariants(&st, tree.Store, minerActor.Balance)
+	assert.True(t, msgs.IsEmpty(), msgs.Messages())
+
+	deadlines, err := st.LoadDeadlines(tree.Store)
+	require.NoError(t, err)
+	expectedSnapshotLen := map[uint64]uint64{
+		dlInfo1.Index: 0, // retained for WPoStSnapshotRetention, pruned
+		dlInfo2.Index: 1, // still disputable, retained
+	}
+	for dlIdx, expectedLen := range expectedSnapshotLen {
+		dl, err := deadlines.LoadDeadline(tree.Store, dlIdx)
+		require.NoError(t, err)
+		snapshot, err := dl.PartitionsSnapshotArray(tree.Store)
+		require.NoError(t, err)
+		assert.Equal(t, expectedLen, snapshot.Length(), "deadline %d", dlIdx)
+		proofs, err := dl.OptimisticProofsSnapshotArray(tree.Store)
+		require.NoError(t, err)
+		assert.Equal(t, expectedLen, proofs.Length(), "deadline %d", dlIdx)
+	}
+}
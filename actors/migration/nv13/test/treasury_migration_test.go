@@ -0,0 +1,37 @@
+package test_test
+
+import (
+	"context"
+	"testing"
+
+	ipld2 "github.com/filecoin-project/specs-actors/v2/support/ipld"
+	vm4 "github.com/filecoin-project/specs-actors/v4/support/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/account"
+)
+
+func TestMigrationCreatesTreasuryActor(t *testing.T) {
+	ctx := context.Background()
+	bs := ipld2.NewSyncBlockStoreInMemory()
+	v := vm4.NewVMWithSingletons(ctx, t, bs)
+	v, _, _, _ = setupMinerWithDealSector(ctx, t, v, 100)
+
+	_, found, err := v.GetActor(builtin.TreasuryActorAddr)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	tree := migrateAndCheckState(ctx, t, bs, v)
+
+	treasury, found, err := tree.GetActor(builtin.TreasuryActorAddr)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, builtin.AccountActorCodeID, treasury.Code)
+	assert.True(t, treasury.Balance.IsZero())
+
+	var st account.State
+	require.NoError(t, tree.Store.Get(ctx, treasury.Head, &st))
+	assert.Equal(t, builtin.TreasuryActorAddr, st.Address)
+}
@@ -47,9 +47,15 @@ func ActorHeadKey(addr address.Address, head cid.Cid) string {
 	return addr.String() + "-h-" + head.String()
 }
 
+func SectorsAmtKey(sectorsAmt cid.Cid) string {
+	return "sectorsAmt-" + sectorsAmt.String()
+}
+
 // Migrates from v12 to v13
 //
-// This migration only updates the actor code CIDs in the state tree.
+// This migration updates the actor code CIDs in the state tree, adds the upfront payment deal set and
+// deal payment modes to the market actor state, adds the seal randomness epoch to miner sector info,
+// and prunes expired deadline snapshots from miner actor state.
 // MigrationCache stores and loads cached data. Its implementation must be threadsafe
 type MigrationCache interface {
 	Write(key string, newCid cid.Cid) error
@@ -67,16 +73,16 @@ func MigrateStateTree(ctx context.Context, store cbor.IpldStore, actorsRootIn ci
 	// Maps prior version code CIDs to migration functions.
 	var migrations = map[cid.Cid]actorMigration{
 		builtin4.AccountActorCodeID:          nilMigrator{builtin5.AccountActorCodeID},
-		builtin4.CronActorCodeID:             nilMigrator{builtin5.CronActorCodeID},
+		builtin4.CronActorCodeID:             cronMigrator{},
 		builtin4.InitActorCodeID:             nilMigrator{builtin5.InitActorCodeID},
 		builtin4.MultisigActorCodeID:         nilMigrator{builtin5.MultisigActorCodeID},
 		builtin4.PaymentChannelActorCodeID:   nilMigrator{builtin5.PaymentChannelActorCodeID},
 		builtin4.RewardActorCodeID:           nilMigrator{builtin5.RewardActorCodeID},
-		builtin4.StorageMarketActorCodeID:    nilMigrator{builtin5.StorageMarketActorCodeID},
-		builtin4.StorageMinerActorCodeID:     nilMigrator{builtin5.StorageMinerActorCodeID},
-		builtin4.StoragePowerActorCodeID:     nilMigrator{builtin5.StoragePowerActorCodeID},
+		builtin4.StorageMarketActorCodeID:    marketMigrator{},
+		builtin4.StorageMinerActorCodeID:     minerMigrator{},
+		builtin4.StoragePowerActorCodeID:     powerMigrator{},
 		builtin4.SystemActorCodeID:           nilMigrator{builtin5.SystemActorCodeID},
-		builtin4.VerifiedRegistryActorCodeID: nilMigrator{builtin5.VerifiedRegistryActorCodeID},
+		builtin4.VerifiedRegistryActorCodeID: verifregMigrator{},
 	}
 
 	// Set of prior version code CIDs for actors to defer during iteration, for explicit migration afterwards.
@@ -214,6 +220,10 @@ func MigrateStateTree(ctx context.Context, store cbor.IpldStore, actorsRootIn ci
 		return cid.Undef, err
 	}
 
+	if err := createTreasuryActor(adtStore, actorsOut); err != nil {
+		return cid.Undef, xerrors.Errorf("failed to create treasury actor: %w", err)
+	}
+
 	elapsed := time.Since(startTime)
 	rate := float64(doneCount) / elapsed.Seconds()
 	log.Log(rt.INFO, "All %d done after %v (%.0f/s). Flushing state tree root.", doneCount, elapsed, rate)
@@ -0,0 +1,33 @@
+package nv13
+
+import (
+	"github.com/filecoin-project/go-state-types/big"
+
+	builtin5 "github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	account5 "github.com/filecoin-project/specs-actors/v5/actors/builtin/account"
+	states5 "github.com/filecoin-project/specs-actors/v5/actors/states"
+	adt5 "github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+)
+
+// Creates the treasury actor, which receives the PenaltyTreasuryShare of penalties, as an account actor
+// with no balance. A network that already has an actor at the treasury address keeps it.
+func createTreasuryActor(store adt5.Store, actors *states5.Tree) error {
+	_, found, err := actors.GetActor(builtin5.TreasuryActorAddr)
+	if err != nil {
+		return err
+	}
+	if found {
+		return nil
+	}
+
+	head, err := store.Put(store.Context(), &account5.State{Address: builtin5.TreasuryActorAddr})
+	if err != nil {
+		return err
+	}
+	return actors.SetActor(builtin5.TreasuryActorAddr, &states5.Actor{
+		Code:       builtin5.AccountActorCodeID,
+		Head:       head,
+		CallSeqNum: 0,
+		Balance:    big.Zero(),
+	})
+}
@@ -0,0 +1,67 @@
+package nv13
+
+import (
+	"context"
+
+	addr "github.com/filecoin-project/go-address"
+	verifreg4 "github.com/filecoin-project/specs-actors/v4/actors/builtin/verifreg"
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"golang.org/x/xerrors"
+
+	builtin5 "github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	verifreg5 "github.com/filecoin-project/specs-actors/v5/actors/builtin/verifreg"
+	adt5 "github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+)
+
+// Verified registry migrator adds the (empty) DataCap allowances to the verified registry state.
+// The verified clients table is unchanged, and now holds transferable DataCap balances.
+// The DataCap of each existing verifier expires VerifierAllowanceDuration after the upgrade.
+type verifregMigrator struct{}
+
+func (m verifregMigrator) migrateState(ctx context.Context, store cbor.IpldStore, in actorMigrationInput) (*actorMigrationResult, error) {
+	var inState verifreg4.State
+	if err := store.Get(ctx, in.head, &inState); err != nil {
+		return nil, err
+	}
+
+	adtStore := adt5.WrapStore(ctx, store)
+	emptyMap, err := adt5.StoreEmptyMap(adtStore, builtin5.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create empty map: %w", err)
+	}
+
+	outState := verifreg5.State{
+		RootKey:                inState.RootKey,
+		Verifiers:              inState.Verifiers,
+		VerifiedClients:        inState.VerifiedClients,
+		Allowances:             emptyMap,
+		VerifierExpirations:    emptyMap,
+		NextVerifierExpiration: verifreg5.NoVerifierExpiration,
+	}
+
+	verifiers, err := adt5.AsMap(adtStore, inState.Verifiers, builtin5.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load verifiers: %w", err)
+	}
+	expiration := in.priorEpoch + verifreg5.VerifierAllowanceDuration
+	if err = verifiers.ForEach(nil, func(key string) error {
+		verifier, err := addr.NewFromBytes([]byte(key))
+		if err != nil {
+			return err
+		}
+		return outState.SetVerifierExpiration(adtStore, verifier, expiration)
+	}); err != nil {
+		return nil, xerrors.Errorf("failed to set verifier expirations: %w", err)
+	}
+
+	newHead, err := store.Put(ctx, &outState)
+	return &actorMigrationResult{
+		newCodeCID: m.migratedCodeCID(),
+		newHead:    newHead,
+	}, err
+}
+
+func (m verifregMigrator) migratedCodeCID() cid.Cid {
+	return builtin5.VerifiedRegistryActorCodeID
+}
@@ -141,6 +141,10 @@ type Runtime interface {
 	// Note events that may make debugging easier
 	Log(level rt.LogLevel, msg string, args ...interface{})
 
+	// Emits an event for observers outside the chain, such as explorers and monitoring services.
+	// Events cannot be read by actors, and are discarded if the invocation exits with an error.
+	EmitEvent(eventType string, payload cbor.Marshaler)
+
 	// BaseFee returns the basefee value in attoFIL per unit gas for the currently exectuting tipset.
 	BaseFee() abi.TokenAmount
 }
@@ -1,6 +1,7 @@
 package runtime
 
 import (
+	"github.com/filecoin-project/go-state-types/cbor"
 	"github.com/filecoin-project/go-state-types/rt"
 	runtime0 "github.com/filecoin-project/specs-actors/actors/runtime"
 )
@@ -28,3 +29,9 @@ const (
 )
 
 type VMActor = rt.VMActor
+
+// An event emitted by an actor, identified by a type name and carrying a serializable payload.
+type ActorEvent struct {
+	Type    string
+	Payload cbor.Marshaler
+}
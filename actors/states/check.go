@@ -171,11 +171,9 @@ func CheckMinersAgainstPower(acc *builtin.MessageAccumulator, minerSummaries map
 		}
 
 		// check crons
-		crons, ok := powerSummary.Crons[addr]
-		if !ok { // with deferred and discontinued crons it is normal for a miner actor to have no cron events
-			continue
-		}
-
+		// With discontinued crons it is normal for a miner actor to have no cron events, but a miner with live
+		// sectors must be enrolled for deadline cron, else its missed proofs are never detected.
+		crons := powerSummary.Crons[addr]
 		var payload miner.CronEventPayload
 		var provingPeriodCron *power.MinerCronEvent
 		for _, event := range crons {
@@ -190,14 +188,28 @@ func CheckMinersAgainstPower(acc *builtin.MessageAccumulator, minerSummaries map
 					acc.Require(false, "miner %v has duplicate proving period crons at epoch %d and %d",
 						addr, provingPeriodCron.Epoch, event.Epoch)
 				}
+				event := event // Intentional shadow
 				provingPeriodCron = &event
+
+				// Deadline cron runs in the last epoch of each of the miner's deadlines.
+				acc.Require((event.Epoch+1-minerSummary.ProvingPeriodStart)%miner.WPoStChallengeWindow == 0,
+					"miner %v proving period cron at epoch %d is not at the end of a deadline for proving period start %d",
+					addr, event.Epoch, minerSummary.ProvingPeriodStart)
 			}
 		}
 		hasProvingPeriodCron := provingPeriodCron != nil
 		acc.Require(hasProvingPeriodCron == minerSummary.DeadlineCronActive, "miner %v has invalid DeadlineCronActive (%t) for hasProvingPeriodCron status (%t)",
 			addr, minerSummary.DeadlineCronActive, hasProvingPeriodCron)
 
-		acc.Require(provingPeriodCron != nil, "miner %v has no proving period cron", addr)
+		acc.Require(len(crons) == 0 || hasProvingPeriodCron, "miner %v has no proving period cron", addr)
+		acc.Require(minerSummary.LivePower.IsZero() || hasProvingPeriodCron,
+			"miner %v has live power %v but no proving period cron", addr, minerSummary.LivePower)
+	}
+
+	// Check that crons are enrolled only for miners.
+	for addr, crons := range powerSummary.Crons { // nolint:nomaprange
+		_, ok := minerSummaries[addr]
+		acc.Require(ok, "%d cron events enrolled for %v which is not a miner", len(crons), addr)
 	}
 }
 
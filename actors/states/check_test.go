@@ -0,0 +1,100 @@
+package states_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/miner"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/power"
+	"github.com/filecoin-project/specs-actors/v5/actors/states"
+	tutil "github.com/filecoin-project/specs-actors/v5/support/testing"
+)
+
+func TestCheckMinersAgainstPower(t *testing.T) {
+	proofType := abi.RegisteredPoStProof_StackedDrgWindow32GiBV1
+	maddr := tutil.NewIDAddr(t, 101)
+	periodStart := abi.ChainEpoch(100)
+	livePower := miner.NewPowerPair(abi.NewStoragePower(1<<35), abi.NewStoragePower(1<<35))
+
+	provingCron := func(epoch abi.ChainEpoch) power.MinerCronEvent {
+		var buf bytes.Buffer
+		require.NoError(t, (&miner.CronEventPayload{EventType: miner.CronEventProvingDeadline}).MarshalCBOR(&buf))
+		return power.MinerCronEvent{Epoch: epoch, Payload: buf.Bytes()}
+	}
+	minerSummary := func(live miner.PowerPair, cronActive bool) map[address.Address]*miner.StateSummary {
+		return map[address.Address]*miner.StateSummary{
+			maddr: {
+				LivePower:           live,
+				ActivePower:         miner.NewPowerPairZero(),
+				FaultyPower:         miner.NewPowerPairZero(),
+				WindowPoStProofType: proofType,
+				DeadlineCronActive:  cronActive,
+				ProvingPeriodStart:  periodStart,
+			},
+		}
+	}
+	powerSummary := func(crons power.CronEventsByAddress) *power.StateSummary {
+		return &power.StateSummary{
+			Crons: crons,
+			Claims: power.ClaimsByAddress{
+				maddr: {WindowPoStProofType: proofType, RawBytePower: big.Zero(), QualityAdjPower: big.Zero()},
+			},
+		}
+	}
+	check := func(minerSummaries map[address.Address]*miner.StateSummary, powerSummary *power.StateSummary) []string {
+		acc := &builtin.MessageAccumulator{}
+		states.CheckMinersAgainstPower(acc, minerSummaries, powerSummary)
+		return acc.Messages()
+	}
+	requireMessage := func(msgs []string, substr string) {
+		for _, msg := range msgs {
+			if strings.Contains(msg, substr) {
+				return
+			}
+		}
+		assert.Failf(t, "missing invariant message", "expected message containing %q in %v", substr, msgs)
+	}
+
+	t.Run("enrolled miner with live power", func(t *testing.T) {
+		crons := power.CronEventsByAddress{maddr: {provingCron(periodStart + miner.WPoStChallengeWindow - 1)}}
+		assert.Empty(t, check(minerSummary(livePower, true), powerSummary(crons)))
+	})
+
+	t.Run("inactive miner without crons", func(t *testing.T) {
+		assert.Empty(t, check(minerSummary(miner.NewPowerPairZero(), false), powerSummary(power.CronEventsByAddress{})))
+	})
+
+	t.Run("miner with live power and no cron", func(t *testing.T) {
+		msgs := check(minerSummary(livePower, false), powerSummary(power.CronEventsByAddress{}))
+		requireMessage(msgs, "miner "+maddr.String()+" has live power")
+	})
+
+	t.Run("active miner with no cron", func(t *testing.T) {
+		msgs := check(minerSummary(miner.NewPowerPairZero(), true), powerSummary(power.CronEventsByAddress{}))
+		requireMessage(msgs, "invalid DeadlineCronActive")
+	})
+
+	t.Run("cron not at end of deadline", func(t *testing.T) {
+		crons := power.CronEventsByAddress{maddr: {provingCron(periodStart + miner.WPoStChallengeWindow)}}
+		msgs := check(minerSummary(livePower, true), powerSummary(crons))
+		requireMessage(msgs, "is not at the end of a deadline")
+	})
+
+	t.Run("cron enrolled for non-miner", func(t *testing.T) {
+		other := tutil.NewIDAddr(t, 102)
+		crons := power.CronEventsByAddress{
+			maddr: {provingCron(periodStart + miner.WPoStChallengeWindow - 1)},
+			other: {provingCron(periodStart + miner.WPoStChallengeWindow - 1)},
+		}
+		msgs := check(minerSummary(livePower, true), powerSummary(crons))
+		requireMessage(msgs, "enrolled for "+other.String()+" which is not a miner")
+	})
+}
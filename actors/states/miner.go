@@ -0,0 +1,43 @@
+package states
+
+import (
+	"bytes"
+
+	miner0 "github.com/filecoin-project/specs-actors/actors/builtin/miner"
+	miner2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/miner"
+	miner3 "github.com/filecoin-project/specs-actors/v3/actors/builtin/miner"
+	miner4 "github.com/filecoin-project/specs-actors/v4/actors/builtin/miner"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/miner"
+)
+
+// Decodes a miner actor state written by the given actors version (0, 2, 3, 4 or 5), so that tooling can
+// decode historical miner states with only this version as a dependency.
+// The result is a pointer to the state type of that version, e.g. *miner4.State for version 4.
+// States of version 5 and later also record their schema version, which must match.
+func DecodeMinerState(version uint64, data []byte) (interface{}, error) {
+	var st cbg.CBORUnmarshaler
+	switch version {
+	case 0:
+		st = new(miner0.State)
+	case 2:
+		st = new(miner2.State)
+	case 3:
+		st = new(miner3.State)
+	case 4:
+		st = new(miner4.State)
+	case miner.StateSchemaVersion:
+		st = new(miner.State)
+	default:
+		return nil, xerrors.Errorf("unknown miner state version %d", version)
+	}
+	if err := st.UnmarshalCBOR(bytes.NewReader(data)); err != nil {
+		return nil, xerrors.Errorf("failed to decode miner state version %d: %w", version, err)
+	}
+	if current, ok := st.(*miner.State); ok && current.SchemaVersion != version {
+		return nil, xerrors.Errorf("miner state has schema version %d, expected %d", current.SchemaVersion, version)
+	}
+	return st, nil
+}
@@ -0,0 +1,58 @@
+package states_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	miner4 "github.com/filecoin-project/specs-actors/v4/actors/builtin/miner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/miner"
+	"github.com/filecoin-project/specs-actors/v5/actors/states"
+	"github.com/filecoin-project/specs-actors/v5/support/ipld"
+	tutil "github.com/filecoin-project/specs-actors/v5/support/testing"
+)
+
+func TestDecodeMinerState(t *testing.T) {
+	store := ipld.NewADTStore(context.Background())
+	infoCid := tutil.MakeCID("info", nil)
+
+	st, err := miner.ConstructState(store, infoCid, 100, 3)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(miner.StateSchemaVersion), st.SchemaVersion)
+	buf := new(bytes.Buffer)
+	require.NoError(t, st.MarshalCBOR(buf))
+	current := buf.Bytes()
+
+	st4, err := miner4.ConstructState(store, infoCid, 100, 3)
+	require.NoError(t, err)
+	buf = new(bytes.Buffer)
+	require.NoError(t, st4.MarshalCBOR(buf))
+	prior := buf.Bytes()
+
+	t.Run("decodes current state", func(t *testing.T) {
+		decoded, err := states.DecodeMinerState(miner.StateSchemaVersion, current)
+		require.NoError(t, err)
+		assert.Equal(t, st, decoded)
+	})
+
+	t.Run("decodes prior version state", func(t *testing.T) {
+		decoded, err := states.DecodeMinerState(4, prior)
+		require.NoError(t, err)
+		assert.Equal(t, st4, decoded)
+	})
+
+	t.Run("rejects mismatched version", func(t *testing.T) {
+		_, err := states.DecodeMinerState(4, current)
+		assert.Error(t, err)
+		_, err = states.DecodeMinerState(miner.StateSchemaVersion, prior)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects unknown version", func(t *testing.T) {
+		_, err := states.DecodeMinerState(1, current)
+		assert.Error(t, err)
+	})
+}
@@ -87,6 +87,7 @@ func TestCommitPoStFlow(t *testing.T) {
 					{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.UpdateNetworkKPI},
 				}},
 				{To: builtin.StorageMarketActorAddr, Method: builtin.MethodsMarket.CronTick},
+				{To: builtin.VerifiedRegistryActorAddr, Method: builtin.MethodsVerifiedRegistry.CronTick},
 			},
 		}.Matches(t, tv.LastInvocation())
 
@@ -143,6 +144,7 @@ func TestCommitPoStFlow(t *testing.T) {
 				{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.UpdateNetworkKPI},
 			}},
 			{To: builtin.StorageMarketActorAddr, Method: builtin.MethodsMarket.CronTick},
+			{To: builtin.VerifiedRegistryActorAddr, Method: builtin.MethodsVerifiedRegistry.CronTick},
 		},
 	}.Matches(t, v.Invocations()[1])
 
@@ -162,13 +164,7 @@ func TestCommitPoStFlow(t *testing.T) {
 
 	// advance to proving period
 	dlInfo, pIdx, v := vm.AdvanceTillProvingDeadline(t, v, minerAddrs.IDAddress, sectorNumber)
-	var minerState miner.State
-	err = v.GetState(minerAddrs.IDAddress, &minerState)
-	require.NoError(t, err)
-
-	sector, found, err := minerState.GetSector(v.Store(), sectorNumber)
-	require.NoError(t, err)
-	require.True(t, found)
+	sector := vm.GetSector(t, v, minerAddrs.IDAddress, sectorNumber)
 
 	t.Run("submit PoSt succeeds", func(t *testing.T) {
 		tv, err := v.WithEpoch(v.GetEpoch())
@@ -261,6 +257,7 @@ func TestCommitPoStFlow(t *testing.T) {
 					{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.UpdateNetworkKPI},
 				}},
 				{To: builtin.StorageMarketActorAddr, Method: builtin.MethodsMarket.CronTick},
+				{To: builtin.VerifiedRegistryActorAddr, Method: builtin.MethodsVerifiedRegistry.CronTick},
 			},
 		}.Matches(t, tv.LastInvocation())
 
@@ -411,6 +408,7 @@ func TestMeasurePoRepGas(t *testing.T) {
 					{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.UpdateNetworkKPI},
 				}},
 				{To: builtin.StorageMarketActorAddr, Method: builtin.MethodsMarket.CronTick},
+				{To: builtin.VerifiedRegistryActorAddr, Method: builtin.MethodsVerifiedRegistry.CronTick},
 			},
 		}.Matches(t, v.Invocations()[sectorsProven+crons-1])
 	}
@@ -522,13 +520,7 @@ func TestBatchOnboarding(t *testing.T) {
 
 	// The sectors are all in the same partition. Advance to it's proving window.
 	dlInfo, pIdx, v := vm.AdvanceTillProvingDeadline(t, v, minerAddrs.IDAddress, abi.SectorNumber(0))
-	var minerState miner.State
-	err = v.GetState(minerAddrs.IDAddress, &minerState)
-	require.NoError(t, err)
-
-	sector, found, err := minerState.GetSector(v.Store(), abi.SectorNumber(0))
-	require.NoError(t, err)
-	require.True(t, found)
+	sector := vm.GetSector(t, v, minerAddrs.IDAddress, abi.SectorNumber(0))
 
 	partitions := []miner.PoStPartition{{
 		Index:   pIdx,
@@ -604,7 +596,9 @@ func TestAggregateOnePreCommitExpires(t *testing.T) {
 	proveCommitAggregateParams := miner.ProveCommitAggregateParams{
 		SectorNumbers: sectorNosBf,
 	}
-	// Aggregate passes, proving the 2 unexpired commitments
+	// Aggregate passes, proving the unexpired commitments and refunding part of the expired commitment's deposit
+	lateDeposit := earlyPrecommits[0].PreCommitDeposit
+	lateRefund := miner.LatePreCommitDepositRefund(lateDeposit)
 	vm.ApplyOk(t, v, addrs[0], minerAddrs.RobustAddress, big.Zero(), builtin.MethodsMiner.ProveCommitAggregate, &proveCommitAggregateParams)
 	vm.ExpectInvocation{
 		To:     minerAddrs.IDAddress,
@@ -612,6 +606,7 @@ func TestAggregateOnePreCommitExpires(t *testing.T) {
 		Params: vm.ExpectObject(&proveCommitAggregateParams),
 		SubInvocations: []vm.ExpectInvocation{
 			{To: builtin.StorageMarketActorAddr, Method: builtin.MethodsMarket.ComputeDataCommitment},
+			{To: builtin.BurntFundsActorAddr, Method: builtin.MethodSend, Value: vm.ExpectAttoFil(big.Sub(lateDeposit, lateRefund))},
 			{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.ThisEpochReward},
 			{To: builtin.StoragePowerActorAddr, Method: builtin.MethodsPower.CurrentTotalPower},
 			{To: builtin.StoragePowerActorAddr, Method: builtin.MethodsPower.UpdatePledgeTotal},
@@ -619,10 +614,16 @@ func TestAggregateOnePreCommitExpires(t *testing.T) {
 		},
 	}.Matches(t, v.LastInvocation())
 
+	// All deposits are released: the expired one partly refunded, the rest unlocked for initial pledge.
 	balances := vm.GetMinerBalances(t, v, minerAddrs.IDAddress)
 	assert.True(t, balances.InitialPledge.GreaterThan(big.Zero()))
-	assert.True(t, balances.PreCommitDeposit.GreaterThan(big.Zero()))
+	assert.Equal(t, big.Zero(), balances.PreCommitDeposit)
+	assert.True(t, lateRefund.GreaterThan(big.Zero()))
 
+	// The expired sector is not activated.
+	_, found, err := vm.GetMinerState(t, v, minerAddrs.IDAddress).GetSector(v.Store(), earlyPrecommits[0].Info.SectorNumber)
+	require.NoError(t, err)
+	assert.False(t, found)
 }
 
 func TestAggregateSizeLimits(t *testing.T) {
@@ -766,6 +767,7 @@ func TestMeasureAggregatePorepGas(t *testing.T) {
 				{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.UpdateNetworkKPI},
 			}},
 			{To: builtin.StorageMarketActorAddr, Method: builtin.MethodsMarket.CronTick},
+			{To: builtin.VerifiedRegistryActorAddr, Method: builtin.MethodsVerifiedRegistry.CronTick},
 		},
 	}.Matches(t, v.Invocations()[1])
 
@@ -824,16 +826,9 @@ func preCommitSectors(t *testing.T, v *vm.VM, count, batchSize int, worker, mAdd
 	}
 
 	// Extract chain state.
-	var minerState miner.State
-	err := v.GetState(mAddr, &minerState)
-	require.NoError(t, err)
-
 	precommits := make([]*miner.SectorPreCommitOnChainInfo, count)
 	for i := 0; i < count; i++ {
-		precommit, found, err := minerState.GetPrecommittedSector(v.Store(), sectorNumberBase+abi.SectorNumber(i))
-		require.NoError(t, err)
-		require.True(t, found)
-		precommits[i] = precommit
+		precommits[i] = vm.GetPrecommittedSector(t, v, mAddr, sectorNumberBase+abi.SectorNumber(i))
 	}
 	return precommits
 }
@@ -37,7 +37,7 @@ func TestReplaceCommittedCapacitySectorWithDealLadenSector(t *testing.T) {
 		Owner:               worker,
 		Worker:              worker,
 		WindowPoStProofType: abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
-		Peer:                abi.PeerID("not really a peer id"),
+		Peer:                tutil.MakePID("not really a peer id"),
 	}
 	ret := vm.ApplyOk(t, v, addrs[0], builtin.StoragePowerActorAddr, minerBalance, builtin.MethodsPower.CreateMiner, &params)
 
@@ -168,7 +168,8 @@ func TestReplaceCommittedCapacitySectorWithDealLadenSector(t *testing.T) {
 		SubInvocations: []vm.ExpectInvocation{
 			{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.ThisEpochReward, SubInvocations: none},
 			{To: builtin.StoragePowerActorAddr, Method: builtin.MethodsPower.CurrentTotalPower, SubInvocations: none},
-			// addtion of deal ids prompts call to verify deals for activation
+			// addtion of deal ids prompts calls to check deal end epochs and verify deals for activation
+			{To: builtin.StorageMarketActorAddr, Method: builtin.MethodsMarket.DealEndEpochs, SubInvocations: none},
 			{To: builtin.StorageMarketActorAddr, Method: builtin.MethodsMarket.VerifyDealsForActivation, SubInvocations: none},
 		},
 	}.Matches(t, v.LastInvocation())
@@ -201,6 +202,7 @@ func TestReplaceCommittedCapacitySectorWithDealLadenSector(t *testing.T) {
 					// slash funds
 					{To: builtin.BurntFundsActorAddr, Method: builtin.MethodSend},
 				}},
+				{To: builtin.VerifiedRegistryActorAddr, Method: builtin.MethodsVerifiedRegistry.CronTick},
 			},
 		}.Matches(t, tv.LastInvocation())
 	})
@@ -245,6 +247,7 @@ func TestReplaceCommittedCapacitySectorWithDealLadenSector(t *testing.T) {
 				{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.UpdateNetworkKPI},
 			}},
 			{To: builtin.StorageMarketActorAddr, Method: builtin.MethodsMarket.CronTick},
+			{To: builtin.VerifiedRegistryActorAddr, Method: builtin.MethodsVerifiedRegistry.CronTick},
 		},
 	}.Matches(t, v.LastInvocation())
 
@@ -289,6 +292,7 @@ func TestReplaceCommittedCapacitySectorWithDealLadenSector(t *testing.T) {
 					{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.UpdateNetworkKPI},
 				}},
 				{To: builtin.StorageMarketActorAddr, Method: builtin.MethodsMarket.CronTick, SubInvocations: []vm.ExpectInvocation{}},
+				{To: builtin.VerifiedRegistryActorAddr, Method: builtin.MethodsVerifiedRegistry.CronTick},
 			},
 		}.Matches(t, tv.LastInvocation())
 
@@ -22,7 +22,7 @@ func createMiner(t *testing.T, v *vm.VM, owner, worker addr.Address, wPoStProof
 		Owner:               owner,
 		Worker:              worker,
 		WindowPoStProofType: wPoStProof,
-		Peer:                abi.PeerID("not really a peer id"),
+		Peer:                tutil.MakePID("not really a peer id"),
 	}
 	ret := vm.ApplyOk(t, v, worker, builtin.StoragePowerActorAddr, balance, builtin.MethodsPower.CreateMiner, &params)
 	minerAddrs, ok := ret.(*power.CreateMinerReturn)
@@ -33,13 +33,16 @@ func createMiner(t *testing.T, v *vm.VM, owner, worker addr.Address, wPoStProof
 func publishDeal(t *testing.T, v *vm.VM, provider, dealClient, minerID addr.Address, dealLabel string,
 	pieceSize abi.PaddedPieceSize, verifiedDeal bool, dealStart abi.ChainEpoch, dealLifetime abi.ChainEpoch,
 ) *market.PublishStorageDealsReturn {
+	label, err := market.NewLabelFromString(dealLabel)
+	require.NoError(t, err)
+
 	deal := market.DealProposal{
 		PieceCID:             tutil.MakeCID(dealLabel, &market.PieceCIDPrefix),
 		PieceSize:            pieceSize,
 		VerifiedDeal:         verifiedDeal,
 		Client:               dealClient,
 		Provider:             minerID,
-		Label:                dealLabel,
+		Label:                label,
 		StartEpoch:           dealStart,
 		EndEpoch:             dealStart + dealLifetime,
 		StoragePricePerEpoch: abi.NewTokenAmount(1 << 20),
@@ -78,5 +81,13 @@ func publishDeal(t *testing.T, v *vm.VM, provider, dealClient, minerID addr.Addr
 		SubInvocations: expectedPublishSubinvocations,
 	}.Matches(t, v.LastInvocation())
 
-	return result.Ret.(*market.PublishStorageDealsReturn)
+	ret := result.Ret.(*market.PublishStorageDealsReturn)
+	require.Len(t, ret.IDs, 1)
+	clientID, found := v.NormalizeAddress(dealClient)
+	require.True(t, found)
+	events := v.LastInvocation().AllEvents()
+	require.Len(t, events, 1)
+	require.Equal(t, market.EventDealPublished, events[0].Type)
+	require.Equal(t, &market.DealEvent{DealID: ret.IDs[0], Client: clientID, Provider: minerID}, events[0].Payload)
+	return ret
 }
@@ -37,7 +37,7 @@ func TestCronCatchedCCExpirationsAtDeadlineBoundary(t *testing.T) {
 		Owner:               worker,
 		Worker:              worker,
 		WindowPoStProofType: abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
-		Peer:                abi.PeerID("not really a peer id"),
+		Peer:                tutil.MakePID("not really a peer id"),
 	}
 	ret := vm.ApplyOk(t, v, worker, builtin.StoragePowerActorAddr, minerBalance, builtin.MethodsPower.CreateMiner, &params)
 
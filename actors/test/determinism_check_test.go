@@ -0,0 +1,86 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/account"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/market"
+	"github.com/filecoin-project/specs-actors/v5/actors/runtime"
+	"github.com/filecoin-project/specs-actors/v5/support/ipld"
+	"github.com/filecoin-project/specs-actors/v5/support/vm"
+)
+
+// An account actor whose PubkeyAddress method returns a different address on every call.
+type nondeterministicAccountActor struct {
+	account.Actor
+	calls *uint64
+}
+
+func (a nondeterministicAccountActor) Exports() []interface{} {
+	exports := append([]interface{}(nil), a.Actor.Exports()...)
+	exports[2] = a.PubkeyAddress
+	return exports
+}
+
+func (a nondeterministicAccountActor) PubkeyAddress(rt runtime.Runtime, _ *abi.EmptyValue) *addr.Address {
+	rt.ValidateImmediateCallerAcceptAny()
+	*a.calls++
+	ret, err := addr.NewIDAddress(*a.calls)
+	if err != nil {
+		panic(err)
+	}
+	return &ret
+}
+
+func TestDeterminismCheck(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deterministic messages pass", func(t *testing.T) {
+		v := vm.NewVMWithSingletons(ctx, t, ipld.NewBlockStoreInMemory())
+		v.SetCheckDeterminism(true)
+		addrs := vm.CreateAccounts(ctx, t, v, 1, big.Mul(big.NewInt(10_000), vm.FIL), 93837778)
+		worker := addrs[0]
+
+		minerAddrs := createMiner(t, v, worker, worker, abi.RegisteredPoStProof_StackedDrgWindow32GiBV1, big.Mul(big.NewInt(1_000), vm.FIL))
+		vm.ApplyOk(t, v, worker, builtin.StorageMarketActorAddr, vm.FIL, builtin.MethodsMarket.AddBalance, &minerAddrs.IDAddress)
+
+		// The check carries over to later epochs.
+		v, err := v.WithEpoch(v.GetEpoch() + 1)
+		require.NoError(t, err)
+		vm.ApplyOk(t, v, worker, builtin.StorageMarketActorAddr, big.Zero(), builtin.MethodsMarket.WithdrawBalance, &market.WithdrawBalanceParams{
+			ProviderOrClientAddress: minerAddrs.IDAddress,
+			Amount:                  vm.FIL,
+		})
+	})
+
+	t.Run("nondeterministic messages panic", func(t *testing.T) {
+		v := vm.NewVMWithSingletons(ctx, t, ipld.NewBlockStoreInMemory())
+		addrs := vm.CreateAccounts(ctx, t, v, 1, big.Mul(big.NewInt(10_000), vm.FIL), 93837778)
+
+		impls := vm.ActorImplLookup{}
+		for code, impl := range v.GetActorImpls() {
+			impls[code] = impl
+		}
+		impls[builtin.AccountActorCodeID] = nondeterministicAccountActor{calls: new(uint64)}
+		v.ActorImpls = impls
+
+		// Without the check, the message succeeds.
+		vm.ApplyOk(t, v, addrs[0], addrs[0], big.Zero(), builtin.MethodsAccount.PubkeyAddress, nil)
+
+		v.SetCheckDeterminism(true)
+		defer func() {
+			err, ok := recover().(error)
+			require.True(t, ok)
+			assert.Contains(t, err.Error(), "nondeterministic execution of method 2")
+		}()
+		v.ApplyMessage(addrs[0], addrs[0], big.Zero(), builtin.MethodsAccount.PubkeyAddress, nil)
+	})
+}
@@ -41,4 +41,17 @@ func TestMarketWithdraw(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, found)
 	assert.Equal(t, initialBalance, a.Balance)
+
+	// the withdrawal is recorded with its purpose
+	callerID, found := v.NormalizeAddress(caller)
+	require.True(t, found)
+	events := v.LastInvocation().AllEvents()
+	require.Len(t, events, 1)
+	assert.Equal(t, builtin.EventFundsTransferred, events[0].Type)
+	assert.Equal(t, &builtin.FundsTransferredEvent{
+		From:    builtin.StorageMarketActorAddr,
+		To:      callerID,
+		Amount:  collateral,
+		Purpose: builtin.TransferWithdrawal,
+	}, events[0].Payload)
 }
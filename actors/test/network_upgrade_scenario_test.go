@@ -0,0 +1,120 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/filecoin-project/go-state-types/network"
+	"github.com/filecoin-project/go-state-types/rt"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/miner"
+	"github.com/filecoin-project/specs-actors/v5/actors/states"
+	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/v5/support/ipld"
+	"github.com/filecoin-project/specs-actors/v5/support/vm"
+)
+
+// An actor exporting only its first few methods, standing in for a prior version of the actor.
+type truncatedActor struct {
+	rt.VMActor
+	methods abi.MethodNum
+}
+
+func (a truncatedActor) Exports() []interface{} {
+	return a.VMActor.Exports()[:a.methods]
+}
+
+func TestNetworkUpgradeWithinScenario(t *testing.T) {
+	ctx := context.Background()
+	v := vm.NewVMWithSingletons(ctx, t, ipld.NewBlockStoreInMemory())
+	addrs := vm.CreateAccounts(ctx, t, v, 1, big.Mul(big.NewInt(10_000), vm.FIL), 93837778)
+	worker := addrs[0]
+	minerAddrs := createMiner(t, v, worker, worker, abi.RegisteredPoStProof_StackedDrgWindow32GiBV1, big.Mul(big.NewInt(1_000), vm.FIL))
+
+	// Before the upgrade, the miner actor doesn't export DeadlinePostStatus.
+	upgradedImpls := vm.ActorImplLookup(v.GetActorImpls())
+	priorImpls := vm.ActorImplLookup{}
+	for code, impl := range upgradedImpls {
+		priorImpls[code] = impl
+	}
+	priorImpls[builtin.StorageMinerActorCodeID] = truncatedActor{
+		VMActor: upgradedImpls[builtin.StorageMinerActorCodeID],
+		methods: builtin.MethodsMiner.DeadlinePostStatus,
+	}
+	v.ActorImpls = priorImpls
+	v, err := v.WithNetworkVersion(network.Version12)
+	require.NoError(t, err)
+
+	// The migration bumps the worker's call sequence number so its effect on the state tree is observable.
+	workerID, found := v.NormalizeAddress(worker)
+	require.True(t, found)
+	upgradeEpoch := abi.ChainEpoch(100)
+	var migrationEpochs []abi.ChainEpoch
+	migration := func(ctx context.Context, store adt.Store, root cid.Cid, epoch abi.ChainEpoch) (cid.Cid, error) {
+		migrationEpochs = append(migrationEpochs, epoch)
+		tree, err := states.LoadTree(store, root)
+		if err != nil {
+			return cid.Undef, err
+		}
+		act, _, err := tree.GetActor(workerID)
+		if err != nil {
+			return cid.Undef, err
+		}
+		act.CallSeqNum += 100
+		if err := tree.SetActor(workerID, act); err != nil {
+			return cid.Undef, err
+		}
+		return tree.Flush()
+	}
+
+	_, err = v.WithNetworkUpgrades(vm.NetworkUpgrade{Epoch: v.GetEpoch(), Version: network.Version13})
+	require.Error(t, err, "upgrades must be scheduled after the current epoch")
+
+	v, err = v.WithNetworkUpgrades(vm.NetworkUpgrade{
+		Epoch:      upgradeEpoch,
+		Version:    network.Version13,
+		ActorImpls: upgradedImpls,
+		Migration:  migration,
+	})
+	require.NoError(t, err)
+
+	workerCallSeqNum := func(v *vm.VM) uint64 {
+		act, found, err := v.GetActor(worker)
+		require.NoError(t, err)
+		require.True(t, found)
+		return act.CallSeqNum
+	}
+	seqNumBeforeUpgrade := workerCallSeqNum(v)
+
+	// Up to the epoch before the upgrade, the prior version is in effect.
+	v, err = v.WithEpoch(upgradeEpoch - 1)
+	require.NoError(t, err)
+	assert.Equal(t, network.Version12, v.GetNetworkVersion())
+	assert.Empty(t, migrationEpochs)
+	result := v.ApplyMessage(worker, minerAddrs.RobustAddress, big.Zero(), builtin.MethodsMiner.DeadlinePostStatus, nil)
+	assert.Equal(t, exitcode.SysErrInvalidMethod, result.Code)
+
+	// At the upgrade epoch the migration has run and the upgraded actors are in effect.
+	v, err = v.WithEpoch(upgradeEpoch)
+	require.NoError(t, err)
+	assert.Equal(t, network.Version13, v.GetNetworkVersion())
+	assert.Equal(t, []abi.ChainEpoch{upgradeEpoch}, migrationEpochs)
+	assert.Equal(t, seqNumBeforeUpgrade+100, workerCallSeqNum(v))
+	ret := vm.ApplyOk(t, v, worker, minerAddrs.RobustAddress, big.Zero(), builtin.MethodsMiner.DeadlinePostStatus, nil)
+	status, ok := ret.(*miner.DeadlinePostStatusReturn)
+	require.True(t, ok)
+	assert.Equal(t, uint64(0), status.Partitions)
+
+	// The migration runs only once.
+	v, err = v.WithEpoch(upgradeEpoch + 1)
+	require.NoError(t, err)
+	assert.Equal(t, network.Version13, v.GetNetworkVersion())
+	assert.Len(t, migrationEpochs, 1)
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	init_ "github.com/filecoin-project/specs-actors/v5/actors/builtin/init"
+	"github.com/filecoin-project/specs-actors/v5/support/ipld"
+	"github.com/filecoin-project/specs-actors/v5/support/vm"
+)
+
+func TestCreatePaymentChannelsToManyRecipients(t *testing.T) {
+	ctx := context.Background()
+	v := vm.NewVMWithSingletons(ctx, t, ipld.NewBlockStoreInMemory())
+	addrs := vm.CreateAccounts(ctx, t, v, 4, big.Mul(big.NewInt(10_000), vm.FIL), 93837778)
+	client, recipients := addrs[0], addrs[1:]
+
+	params := init_.CreatePaymentChannelsParams{}
+	totalValue := big.Zero()
+	for i, to := range recipients {
+		value := big.Mul(big.NewInt(int64(i+1)), vm.FIL)
+		params.Channels = append(params.Channels, init_.PaymentChannelSpec{To: to, Value: value})
+		totalValue = big.Add(totalValue, value)
+	}
+	ret := vm.ApplyOk(t, v, client, builtin.InitActorAddr, totalValue, builtin.MethodsInit.CreatePaymentChannels, &params)
+	created := ret.(*init_.CreatePaymentChannelsReturn).Channels
+	require.Len(t, created, len(recipients))
+
+	clientID, found := v.NormalizeAddress(client)
+	require.True(t, found)
+	for i, channel := range created {
+		// The robust address resolves to the new channel.
+		idAddr, found := v.NormalizeAddress(channel.RobustAddress)
+		require.True(t, found)
+		assert.Equal(t, channel.IDAddress, idAddr)
+
+		act, found, err := v.GetActor(channel.IDAddress)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, builtin.PaymentChannelActorCodeID, act.Code)
+		assert.Equal(t, params.Channels[i].Value, act.Balance)
+
+		st := vm.GetPaychState(t, v, channel.IDAddress)
+		recipientID, found := v.NormalizeAddress(recipients[i])
+		require.True(t, found)
+		assert.Equal(t, clientID, st.From)
+		assert.Equal(t, recipientID, st.To)
+		assert.Equal(t, abi.ChainEpoch(0), st.SettlingAt)
+	}
+}
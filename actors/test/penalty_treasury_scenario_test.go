@@ -0,0 +1,63 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/market"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/miner"
+	"github.com/filecoin-project/specs-actors/v5/support/ipld"
+	"github.com/filecoin-project/specs-actors/v5/support/vm"
+)
+
+func TestPenaltyShareIsPaidToTreasury(t *testing.T) {
+	prevShare := builtin.PenaltyTreasuryShare
+	builtin.PenaltyTreasuryShare = builtin.BigFrac{Numerator: big.NewInt(1), Denominator: big.NewInt(4)}
+	t.Cleanup(func() { builtin.PenaltyTreasuryShare = prevShare })
+
+	ctx := context.Background()
+	v := vm.NewVMWithSingletons(ctx, t, ipld.NewBlockStoreInMemory())
+	addrs := vm.CreateAccounts(ctx, t, v, 2, big.Mul(big.NewInt(10_000), vm.FIL), 93837778)
+	worker, client := addrs[0], addrs[1]
+	sealProof := abi.RegisteredSealProof_StackedDrg32GiBV1_1
+
+	minerAddrs := createMiner(t, v, worker, worker, abi.RegisteredPoStProof_StackedDrgWindow32GiBV1, big.Mul(big.NewInt(1_000), vm.FIL))
+
+	collateral := big.Mul(big.NewInt(3), vm.FIL)
+	vm.ApplyOk(t, v, client, builtin.StorageMarketActorAddr, collateral, builtin.MethodsMarket.AddBalance, &client)
+	vm.ApplyOk(t, v, worker, builtin.StorageMarketActorAddr, collateral, builtin.MethodsMarket.AddBalance, &minerAddrs.IDAddress)
+
+	// Publish a deal that is never activated, so its provider collateral is forfeit at its start epoch.
+	dealStart := v.GetEpoch() + miner.MaxProveCommitDuration[sealProof]
+	dealIDs := publishDeal(t, v, worker, client, minerAddrs.IDAddress, "deal1", 1<<30, false, dealStart, 181*builtin.EpochsInDay).IDs
+	proposal := vm.GetDealProposal(t, v, dealIDs[0])
+
+	burntBefore := actorBalance(t, v, builtin.BurntFundsActorAddr)
+	v, _ = vm.AdvanceByDeadlineTillEpoch(t, v, minerAddrs.IDAddress, dealStart+market.DealUpdatesInterval)
+
+	proposals, err := market.AsDealProposalArray(v.Store(), vm.GetMarketState(t, v).Proposals)
+	require.NoError(t, err)
+	_, found, err := proposals.Get(dealIDs[0])
+	require.NoError(t, err)
+	require.False(t, found, "timed out deal was not removed")
+
+	penalty := market.CollateralPenaltyForDealActivationMissed(proposal.ProviderCollateral)
+	toTreasury, toBurn := builtin.SplitPenalty(penalty)
+	require.True(t, toTreasury.GreaterThan(big.Zero()))
+	assert.Equal(t, toTreasury, actorBalance(t, v, builtin.TreasuryActorAddr))
+	assert.Equal(t, toBurn, big.Sub(actorBalance(t, v, builtin.BurntFundsActorAddr), burntBefore))
+}
+
+func actorBalance(t *testing.T, v *vm.VM, a addr.Address) abi.TokenAmount {
+	act, found, err := v.GetActor(a)
+	require.NoError(t, err)
+	require.True(t, found)
+	return act.Balance
+}
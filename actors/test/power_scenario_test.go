@@ -25,7 +25,7 @@ func TestCreateMiner(t *testing.T) {
 		Owner:               addrs[0],
 		Worker:              addrs[0],
 		WindowPoStProofType: abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
-		Peer:                abi.PeerID("not really a peer id"),
+		Peer:                tutil.MakePID("not really a peer id"),
 	}
 	ret := vm.ApplyOk(t, v, addrs[0], builtin.StoragePowerActorAddr, big.NewInt(1e10), builtin.MethodsPower.CreateMiner, &params)
 
@@ -70,7 +70,7 @@ func TestOnEpochTickEnd(t *testing.T) {
 	minerBalance := big.Mul(big.NewInt(10_000), vm.FIL)
 	params := power.CreateMinerParams{Owner: addrs[0], Worker: addrs[0],
 		WindowPoStProofType: abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
-		Peer:                abi.PeerID("pid")}
+		Peer:                tutil.MakePID("pid")}
 	ret := vm.ApplyOk(t, v, addrs[0], builtin.StoragePowerActorAddr, minerBalance, builtin.MethodsPower.CreateMiner, &params)
 
 	ret, ok := ret.(*power.CreateMinerReturn)
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/miner"
+	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/v5/support/ipld"
+	tutil "github.com/filecoin-project/specs-actors/v5/support/testing"
+	"github.com/filecoin-project/specs-actors/v5/support/vm"
+)
+
+func TestExportMinerStateCAR(t *testing.T) {
+	ctx := context.Background()
+	bs := ipld.NewBlockStoreInMemory()
+	v := vm.NewVMWithSingletons(ctx, t, bs)
+	addrs := vm.CreateAccounts(ctx, t, v, 1, big.Mul(big.NewInt(10_000), vm.FIL), 93837778)
+	worker := addrs[0]
+	minerAddrs := createMiner(t, v, worker, worker, abi.RegisteredPoStProof_StackedDrgWindow32GiBV1, big.Mul(big.NewInt(1_000), vm.FIL))
+
+	var buf bytes.Buffer
+	require.NoError(t, ipld.ExportActorStateCAR(ctx, &buf, bs, v.StateRoot(), minerAddrs.IDAddress))
+
+	// The CAR holds the miner's complete state, rooted at the actor's head.
+	imported := ipld.NewBlockStoreInMemory()
+	roots, err := ipld.ReadCAR(&buf, imported)
+	require.NoError(t, err)
+	act, found, err := v.GetActor(minerAddrs.IDAddress)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, []cid.Cid{act.Head}, roots)
+
+	store := adt.WrapBlockStore(ctx, imported)
+	var st miner.State
+	require.NoError(t, store.Get(ctx, act.Head, &st))
+	info, err := st.GetInfo(store)
+	require.NoError(t, err)
+	assert.Equal(t, vm.GetMinerInfo(t, v, minerAddrs.IDAddress), info)
+	_, err = st.LoadDeadlines(store)
+	require.NoError(t, err)
+
+	// The rest of the state tree is not included.
+	_, err = imported.Get(v.StateRoot())
+	assert.Error(t, err)
+
+	// Exporting an actor that doesn't exist fails.
+	err = ipld.ExportActorStateCAR(ctx, &buf, bs, v.StateRoot(), tutil.NewIDAddr(t, 9999))
+	assert.Error(t, err)
+}
@@ -39,7 +39,7 @@ func TestTerminateSectors(t *testing.T) {
 		Owner:               owner,
 		Worker:              worker,
 		WindowPoStProofType: abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
-		Peer:                abi.PeerID("not really a peer id"),
+		Peer:                tutil.MakePID("not really a peer id"),
 	})
 
 	minerAddrs, ok := ret.(*power.CreateMinerReturn)
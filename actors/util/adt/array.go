@@ -2,6 +2,7 @@ package adt
 
 import (
 	"bytes"
+	"sort"
 
 	amt "github.com/filecoin-project/go-amt-ipld/v3"
 
@@ -150,3 +151,59 @@ func (a *Array) Pop(k uint64, out cbor.Unmarshaler) (bool, error) {
 	}
 	return true, nil
 }
+
+// An entry of an array that differs between two versions of the array.
+// Before is nil if the entry was added, and After is nil if it was removed.
+type ArrayChange struct {
+	Index  uint64
+	Before *cbg.Deferred
+	After  *cbg.Deferred
+}
+
+// Lists the entries that differ between two arrays of the same bitwidth, in index order.
+// Subtrees the arrays share are not traversed.
+func DiffArrays(s Store, prev, cur cid.Cid, bitwidth int) ([]ArrayChange, error) {
+	// The AMT diff doesn't handle an empty array, whose root node has no leaf values, so list its counterpart's
+	// entries instead.
+	if prevArr, err := AsArray(s, prev, bitwidth); err != nil {
+		return nil, err
+	} else if prevArr.Length() == 0 {
+		return listArrayChanges(s, cur, bitwidth, func(i uint64, val *cbg.Deferred) ArrayChange {
+			return ArrayChange{Index: i, After: val}
+		})
+	}
+	if curArr, err := AsArray(s, cur, bitwidth); err != nil {
+		return nil, err
+	} else if curArr.Length() == 0 {
+		return listArrayChanges(s, prev, bitwidth, func(i uint64, val *cbg.Deferred) ArrayChange {
+			return ArrayChange{Index: i, Before: val}
+		})
+	}
+
+	options := append(DefaultAmtOptions, amt.UseTreeBitWidth(uint(bitwidth)))
+	changes, err := amt.Diff(s.Context(), s, s, prev, cur, options...)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to diff arrays %v and %v: %w", prev, cur, err)
+	}
+	out := make([]ArrayChange, len(changes))
+	for i, change := range changes {
+		out[i] = ArrayChange{Index: change.Key, Before: change.Before, After: change.After}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Index < out[j].Index })
+	return out, nil
+}
+
+func listArrayChanges(s Store, root cid.Cid, bitwidth int, change func(i uint64, val *cbg.Deferred) ArrayChange) ([]ArrayChange, error) {
+	arr, err := AsArray(s, root, bitwidth)
+	if err != nil {
+		return nil, err
+	}
+	var out []ArrayChange
+	var val cbg.Deferred
+	err = arr.ForEach(&val, func(i int64) error {
+		valCopy := val
+		out = append(out, change(uint64(i), &valCopy))
+		return nil
+	})
+	return out, err
+}
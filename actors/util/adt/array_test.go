@@ -1,10 +1,13 @@
 package adt_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
 
 	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
 	"github.com/filecoin-project/specs-actors/v5/support/mock"
@@ -20,3 +23,66 @@ func TestArrayNotFound(t *testing.T) {
 	require.NoError(t, err)
 	require.False(t, found)
 }
+
+func TestDiffArrays(t *testing.T) {
+	rt := mock.NewBuilder(address.Undef).Build(t)
+	store := adt.AsStore(rt)
+	arr, err := adt.MakeEmptyArray(store, 3)
+	require.NoError(t, err)
+	for i := uint64(0); i < 20; i++ {
+		require.NoError(t, arr.Set(i, cborInt(int64(i))))
+	}
+	prev, err := arr.Root()
+	require.NoError(t, err)
+
+	require.NoError(t, arr.Delete(2))
+	require.NoError(t, arr.Set(9, cborInt(90)))
+	require.NoError(t, arr.Set(100, cborInt(100)))
+	cur, err := arr.Root()
+	require.NoError(t, err)
+
+	changes, err := adt.DiffArrays(store, prev, cur, 3)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	assert.Equal(t, uint64(2), changes[0].Index)
+	assert.NotNil(t, changes[0].Before)
+	assert.Nil(t, changes[0].After)
+
+	assert.Equal(t, uint64(9), changes[1].Index)
+	var before, after cbg.CborInt
+	require.NoError(t, before.UnmarshalCBOR(bytes.NewReader(changes[1].Before.Raw)))
+	require.NoError(t, after.UnmarshalCBOR(bytes.NewReader(changes[1].After.Raw)))
+	assert.Equal(t, cbg.CborInt(9), before)
+	assert.Equal(t, cbg.CborInt(90), after)
+
+	assert.Equal(t, uint64(100), changes[2].Index)
+	assert.Nil(t, changes[2].Before)
+	assert.NotNil(t, changes[2].After)
+
+	changes, err = adt.DiffArrays(store, cur, cur, 3)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// diffs against an empty array list every entry
+	empty, err := adt.StoreEmptyArray(store, 3)
+	require.NoError(t, err)
+	changes, err = adt.DiffArrays(store, empty, prev, 3)
+	require.NoError(t, err)
+	require.Len(t, changes, 20)
+	assert.Equal(t, uint64(19), changes[19].Index)
+	assert.Nil(t, changes[19].Before)
+	require.NoError(t, after.UnmarshalCBOR(bytes.NewReader(changes[19].After.Raw)))
+	assert.Equal(t, cbg.CborInt(19), after)
+
+	changes, err = adt.DiffArrays(store, prev, empty, 3)
+	require.NoError(t, err)
+	require.Len(t, changes, 20)
+	assert.NotNil(t, changes[0].Before)
+	assert.Nil(t, changes[0].After)
+}
+
+func cborInt(i int64) *cbg.CborInt {
+	v := cbg.CborInt(i)
+	return &v
+}
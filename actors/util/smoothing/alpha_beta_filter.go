@@ -150,7 +150,6 @@ func ExtrapolatedCumSumOfRatio(delta abi.ChainEpoch, relativeStart abi.ChainEpoc
 }
 
 // Extrapolate filter "position" delta epochs in the future.
-// Note this is currently only used in testing.
 // Output is Q.256 format for use in numerator of ratio in test caller
 func (fe *FilterEstimate) Extrapolate(delta abi.ChainEpoch) big.Int {
 	deltaT := big.NewInt(int64(delta))                          // Q.0
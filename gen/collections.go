@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// A typed wrapper for a HAMT or AMT in actor state, declared by an `adt` tag on a state struct's CID field:
+//
+//	Field cid.Cid `adt:"<kind>,<Name>,<KeyType>,<ValueType>,<Bitwidth>"`
+//
+// Kind is "map" (HAMT) or "array" (AMT). Keys must be integers (e.g. abi.SectorNumber), which are keyed as
+// abi.UIntKey in maps. Values are pointers to the value type, which must be a CBOR-marshalable struct.
+// The same name may annotate several fields (e.g. a collection and its snapshot) if they agree.
+type collectionSpec struct {
+	Kind     string
+	Name     string
+	Key      string
+	Value    string
+	Bitwidth string
+	Fields   []string
+}
+
+// Writes typed wrappers for the collections annotated on the fields of some state structs to a file.
+func writeCollectionsToFile(path, pkg string, states ...interface{}) error {
+	byName := map[string]*collectionSpec{}
+	for _, st := range states {
+		t := reflect.TypeOf(st)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag, ok := f.Tag.Lookup("adt")
+			if !ok {
+				continue
+			}
+			parts := strings.Split(tag, ",")
+			if len(parts) != 5 {
+				return fmt.Errorf("%s.%s: malformed adt tag %q", t.Name(), f.Name, tag)
+			}
+			spec := collectionSpec{Kind: parts[0], Name: parts[1], Key: parts[2], Value: parts[3], Bitwidth: parts[4]}
+			if spec.Kind != "map" && spec.Kind != "array" {
+				return fmt.Errorf("%s.%s: unknown collection kind %q", t.Name(), f.Name, spec.Kind)
+			}
+			field := t.Name() + "." + f.Name
+			if existing, ok := byName[spec.Name]; ok {
+				spec.Fields = existing.Fields
+				if !reflect.DeepEqual(*existing, spec) {
+					return fmt.Errorf("%s: collection %s declared inconsistently", field, spec.Name)
+				}
+				existing.Fields = append(existing.Fields, field)
+				continue
+			}
+			spec.Fields = []string{field}
+			byName[spec.Name] = &spec
+		}
+	}
+
+	specs := make([]*collectionSpec, 0, len(byName))
+	for _, spec := range byName {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+
+	// Imports are grouped as external, then of this module.
+	external := map[string]bool{`"github.com/ipfs/go-cid"`: true, `"golang.org/x/xerrors"`: true}
+	internal := map[string]bool{`"github.com/filecoin-project/specs-actors/v5/actors/util/adt"`: true}
+	for _, spec := range specs {
+		if spec.Kind == "map" || strings.HasPrefix(spec.Key, "abi.") {
+			external[`"github.com/filecoin-project/go-state-types/abi"`] = true
+		}
+		if strings.HasPrefix(spec.Bitwidth, "builtin.") {
+			internal[`"github.com/filecoin-project/specs-actors/v5/actors/builtin"`] = true
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := collectionsTemplate.Execute(&buf, map[string]interface{}{
+		"Package":  pkg,
+		"External": sortedKeys(external),
+		"Internal": sortedKeys(internal),
+		"Specs":    specs,
+	}); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format collections for %s: %w", pkg, err)
+	}
+	return ioutil.WriteFile(path, src, 0644)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var collectionsTemplate = template.Must(template.New("collections").Funcs(template.FuncMap{
+	"join": strings.Join,
+}).Parse(`// Code generated by github.com/filecoin-project/specs-actors/v5/gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .External}}
+	{{.}}
+{{- end}}
+{{range .Internal}}
+	{{.}}
+{{- end}}
+)
+{{range .Specs}}{{if eq .Kind "map"}}
+// {{.Name}} is a HAMT of {{.Value}} keyed by {{.Key}}, as stored in {{join .Fields ", "}}.
+type {{.Name}} struct {
+	*adt.Map
+}
+
+// Loads a {{.Name}} from its root.
+func Load{{.Name}}(store adt.Store, root cid.Cid) ({{.Name}}, error) {
+	m, err := adt.AsMap(store, root, {{.Bitwidth}})
+	if err != nil {
+		return {{.Name}}{}, err
+	}
+	return {{.Name}}{m}, nil
+}
+
+// Returns the value for a key, or nil if not found.
+func (m {{.Name}}) Get(k {{.Key}}) (*{{.Value}}, bool, error) {
+	var v {{.Value}}
+	if found, err := m.Map.Get(abi.UIntKey(uint64(k)), &v); err != nil {
+		return nil, false, xerrors.Errorf("failed to get %v: %w", k, err)
+	} else if !found {
+		return nil, false, nil
+	}
+	return &v, true, nil
+}
+
+// Sets the value for a key.
+func (m {{.Name}}) Put(k {{.Key}}, v *{{.Value}}) error {
+	if err := m.Map.Put(abi.UIntKey(uint64(k)), v); err != nil {
+		return xerrors.Errorf("failed to put %v: %w", k, err)
+	}
+	return nil
+}
+
+// Sets the value for a key if it is not already present, returning whether the value was set.
+func (m {{.Name}}) PutIfAbsent(k {{.Key}}, v *{{.Value}}) (bool, error) {
+	modified, err := m.Map.PutIfAbsent(abi.UIntKey(uint64(k)), v)
+	if err != nil {
+		return false, xerrors.Errorf("failed to put %v: %w", k, err)
+	}
+	return modified, nil
+}
+
+// Removes the value for a key, which must be present.
+func (m {{.Name}}) Delete(k {{.Key}}) error {
+	if err := m.Map.Delete(abi.UIntKey(uint64(k))); err != nil {
+		return xerrors.Errorf("failed to delete %v: %w", k, err)
+	}
+	return nil
+}
+
+// Removes and returns the value for a key, or nil if not found.
+func (m {{.Name}}) Pop(k {{.Key}}) (*{{.Value}}, bool, error) {
+	var v {{.Value}}
+	if found, err := m.Map.Pop(abi.UIntKey(uint64(k)), &v); err != nil {
+		return nil, false, xerrors.Errorf("failed to pop %v: %w", k, err)
+	} else if !found {
+		return nil, false, nil
+	}
+	return &v, true, nil
+}
+
+// Iterates the entries of the map.
+func (m {{.Name}}) ForEach(fn func(k {{.Key}}, v *{{.Value}}) error) error {
+	var v {{.Value}}
+	return m.Map.ForEach(&v, func(key string) error {
+		k, err := abi.ParseUIntKey(key)
+		if err != nil {
+			return xerrors.Errorf("invalid key %x: %w", key, err)
+		}
+		value := v
+		return fn({{.Key}}(k), &value)
+	})
+}
+{{else}}
+// {{.Name}} is an AMT of {{.Value}} indexed by {{.Key}}, as stored in {{join .Fields ", "}}.
+type {{.Name}} struct {
+	*adt.Array
+}
+
+// Loads a {{.Name}} from its root.
+func Load{{.Name}}(store adt.Store, root cid.Cid) ({{.Name}}, error) {
+	a, err := adt.AsArray(store, root, {{.Bitwidth}})
+	if err != nil {
+		return {{.Name}}{}, err
+	}
+	return {{.Name}}{a}, nil
+}
+
+// Returns the value at an index, or nil if not found.
+func (a {{.Name}}) Get(i {{.Key}}) (*{{.Value}}, bool, error) {
+	var v {{.Value}}
+	if found, err := a.Array.Get(uint64(i), &v); err != nil {
+		return nil, false, xerrors.Errorf("failed to get %v: %w", i, err)
+	} else if !found {
+		return nil, false, nil
+	}
+	return &v, true, nil
+}
+
+// Sets the value at an index.
+func (a {{.Name}}) Set(i {{.Key}}, v *{{.Value}}) error {
+	if err := a.Array.Set(uint64(i), v); err != nil {
+		return xerrors.Errorf("failed to set %v: %w", i, err)
+	}
+	return nil
+}
+
+// Sets the value at the index one past the last.
+func (a {{.Name}}) AppendContinuous(v *{{.Value}}) error {
+	if err := a.Array.AppendContinuous(v); err != nil {
+		return xerrors.Errorf("failed to append: %w", err)
+	}
+	return nil
+}
+
+// Removes the value at an index, which must be present.
+func (a {{.Name}}) Delete(i {{.Key}}) error {
+	if err := a.Array.Delete(uint64(i)); err != nil {
+		return xerrors.Errorf("failed to delete %v: %w", i, err)
+	}
+	return nil
+}
+
+// Removes and returns the value at an index, or nil if not found.
+func (a {{.Name}}) Pop(i {{.Key}}) (*{{.Value}}, bool, error) {
+	var v {{.Value}}
+	if found, err := a.Array.Pop(uint64(i), &v); err != nil {
+		return nil, false, xerrors.Errorf("failed to pop %v: %w", i, err)
+	} else if !found {
+		return nil, false, nil
+	}
+	return &v, true, nil
+}
+
+// Iterates the values of the array in index order.
+func (a {{.Name}}) ForEach(fn func(i {{.Key}}, v *{{.Value}}) error) error {
+	var v {{.Value}}
+	return a.Array.ForEach(&v, func(i int64) error {
+		value := v
+		return fn({{.Key}}(i), &value)
+	})
+}
+{{end}}{{end}}`))
@@ -16,7 +16,7 @@ import (
 	"github.com/filecoin-project/specs-actors/v5/actors/builtin/system"
 	"github.com/filecoin-project/specs-actors/v5/actors/builtin/verifreg"
 	"github.com/filecoin-project/specs-actors/v5/actors/util/smoothing"
-	"github.com/filecoin-project/specs-actors/v5/support/vm"
+	"github.com/filecoin-project/specs-actors/v5/support/vm/messages"
 )
 
 func main() {
@@ -33,6 +33,10 @@ func main() {
 
 	if err := gen.WriteTupleEncodersToFile("./actors/builtin/cbor_gen.go", "builtin",
 		builtin.MinerAddrs{},
+		builtin.MinerBalanceBreakdown{},
+		builtin.PageParams{},
+		builtin.PageReturn{},
+		builtin.FundsTransferredEvent{},
 		//builtin.ConfirmSectorProofsParams{},  // Aliased from v0
 		// builtin.ApplyRewardParams{}, // Aliased from v2
 	); err != nil {
@@ -67,6 +71,10 @@ func main() {
 		//init_.ConstructorParams{}, // Aliased from v0
 		//init_.ExecParams{}, // Aliased from v0
 		//init_.ExecReturn{}, // Aliased from v0
+		init_.PaymentChannelSpec{},
+		init_.CreatePaymentChannelsParams{},
+		init_.CreatedChannel{},
+		init_.CreatePaymentChannelsReturn{},
 	); err != nil {
 		panic(err)
 	}
@@ -87,6 +95,10 @@ func main() {
 		// method params and returns
 		//reward.AwardBlockRewardParams{}, // Aliased from v0
 		reward.ThisEpochRewardReturn{},
+		reward.ThisEpochRewardBreakdownReturn{},
+		reward.AwardBlockRewardsParams{},
+		reward.ProjectRewardsParams{},
+		reward.ProjectRewardsReturn{},
 	); err != nil {
 		panic(err)
 	}
@@ -131,12 +143,31 @@ func main() {
 		power.State{},
 		power.Claim{},
 		power.CronEvent{},
+		power.PowerCheckpoint{},
+		power.PowerCheckpoints{},
+		power.ProofTypePower{},
+		power.ConsensusFaultRecord{},
+		power.ConsensusFaultHistory{},
 		// method params and returns
 		power.CreateMinerParams{},
 		//power.CreateMinerReturn{}, // Aliased from v0
 		//power.EnrollCronEventParams{}, // Aliased from v0
 		//power.UpdateClaimedPowerParams{}, // Aliased from v0
 		power.CurrentTotalPowerReturn{},
+		power.UpdateClaimedProofTypeParams{},
+		power.TotalPowerAtParams{},
+		power.TotalPowerAtReturn{},
+		power.ListClaimsParams{},
+		power.MinerClaim{},
+		power.ListClaimsReturn{},
+		power.CronEventQueueStatsReturn{},
+		power.RecordConsensusFaultsParams{},
+		power.ConsensusFaultHistoryParams{},
+		power.MinerEligibleForElectionParams{},
+		power.MinerEligibleForElectionReturn{},
+		power.TotalPledgeReturn{},
+		power.MinerPledgeParams{},
+		power.MinerPledgeReturn{},
 		// other types
 		power.MinerConstructorParams{},
 	); err != nil {
@@ -148,8 +179,8 @@ func main() {
 		market.State{},
 		// method params and returns
 		//market.WithdrawBalanceParams{}, // Aliased from v0
-		//market.PublishStorageDealsParams{}, // Aliased from v0
-		//market.PublishStorageDealsReturn{}, // Aliased from v0
+		market.PublishStorageDealsParams{},
+		market.PublishStorageDealsReturn{},
 		//market.ActivateDealsParams{}, // Aliased from v0
 		market.VerifyDealsForActivationParams{},
 		market.VerifyDealsForActivationReturn{},
@@ -158,11 +189,40 @@ func main() {
 		market.ComputeDataCommitmentReturn{},
 		//market.OnMinerSectorsTerminateParams{}, // Aliased from v0
 		// other types
-		//market.DealProposal{}, // Aliased from v0
-		//market.ClientDealProposal{}, // Aliased from v0
+		market.DealProposal{},
+		market.DealRenewal{},
+		market.ClientDealProposal{},
 		market.SectorDeals{},
 		market.SectorWeights{},
 		market.DealState{},
+		market.PublishStorageDealsWithPaymentModeParams{},
+		market.OfferStorageDealsParams{},
+		market.AcceptStorageDealOffersParams{},
+		market.DealEndEpochsParams{},
+		market.DealEndEpoch{},
+		market.DealEndEpochsReturn{},
+		market.DealSettlementsParams{},
+		market.DealSettlement{},
+		market.DealSettlementsReturn{},
+		market.ProviderDealsParams{},
+		market.ProviderDealsReturn{},
+		market.PieceDealsParams{},
+		market.PieceDealsReturn{},
+		market.CancelStorageDealsParams{},
+		market.DeclineRenewalParams{},
+		market.WithdrawBalanceToParams{},
+		market.GetDealStatusParams{},
+		market.GetDealStatusReturn{},
+		market.GetLockedFundsReturn{},
+		market.AuthorizeDealProposalParams{},
+		market.PendingDealTermination{},
+		market.ContinueTerminationReturn{},
+		market.ActivationExtension{},
+		market.ActivationExtensionParams{},
+		market.DealEvent{},
+		market.DealSlashedEvent{},
+		market.DealPaymentEvent{},
+		market.GetPendingProposalCountReturn{},
 	); err != nil {
 		panic(err)
 	}
@@ -187,15 +247,18 @@ func main() {
 		// miner.ConstructorParams{}, // in power actor
 		//miner.SubmitWindowedPoStParams{}, // Aliased from v0
 		//miner.TerminateSectorsParams{}, // Aliased from v0
-		//miner.TerminateSectorsReturn{}, // Aliased from v0
+		miner.TerminateSectorsReturn{},
 		//miner.ChangePeerIDParams{}, // Aliased from v0
 		//miner.ChangeMultiaddrsParams{}, // Aliased from v0
 		//miner.ProveCommitSectorParams{}, // Aliased from v0
 		miner.ProveCommitAggregateParams{},
+		miner.DesignatePreCommitAggregatorParams{},
 		//miner.ChangeWorkerAddressParams{},  // Aliased from v0
 		//miner.ExtendSectorExpirationParams{}, // Aliased from v0
 		//miner.DeclareFaultsParams{}, // Aliased from v0
 		//miner.DeclareFaultsRecoveredParams{}, // Aliased from v0
+		miner.DeclareFaultsReturn{},
+		miner.DeclareFaultsRecoveredReturn{},
 		//miner.ReportConsensusFaultParams{}, // Aliased from v0
 		// miner.GetControlAddressesReturn{}, // Aliased from v2
 		//miner.CheckSectorProvenParams{}, // Aliased from v0
@@ -205,6 +268,48 @@ func main() {
 		//miner.CronEventPayload{}, // Aliased from v0
 		// miner.DisputeWindowedPoStParams{}, // Aliased from v3
 		miner.PreCommitSectorBatchParams{},
+		miner.BalanceBreakdown{},
+		miner.WithdrawBalanceToParams{},
+		miner.ReportConsensusFaultsParams{},
+		miner.UpgradeWindowPoStProofTypeParams{},
+		miner.DeadlinePostStatusReturn{},
+		miner.WindowPoStChallengeParams{},
+		miner.WindowPoStChallengeReturn{},
+		miner.FaultExpirationsParams{},
+		miner.FaultExpiration{},
+		miner.FaultExpirationsReturn{},
+		miner.ChangeAddressesParams{},
+		miner.ExpirationScheduleParams{},
+		miner.ExpirationScheduleEntry{},
+		miner.ExpirationScheduleReturn{},
+		miner.DeclareFaultsWithReasonsParams{},
+		miner.FaultDeclarationWithReason{},
+		miner.DeclareFaultsWithReasonsReturn{},
+		miner.FaultReasonSectors{},
+		miner.BatchLimitsReturn{},
+		miner.FailedSectorActivation{},
+		miner.ConfirmSectorProofsValidReturn{},
+		miner.ChangeDeadlineAssignmentParams{},
+		miner.AttestedPiece{},
+		miner.AttestSectorPiecesParams{},
+		miner.VestSpec{},
+		miner.LockFundsParams{},
+		miner.ReliabilityCounters{},
+		miner.ReliabilityStats{},
+		miner.SectorRegion{},
+		miner.GetSectorRegionsParams{},
+		miner.GetSectorRegionsReturn{},
+		miner.ChangeNotificationTargetParams{},
+		miner.NotificationParams{},
+		miner.PreCommitDepositRelease{},
+		miner.PreCommitDepositsReleasedEvent{},
+		miner.SectorStatusCounts{},
+		miner.ProvingPeriodStatusReturn{},
+		miner.EarlyTerminationsParams{},
+		miner.EarlyTermination{},
+		miner.EarlyTerminationsReturn{},
+		miner.ProjectSectorPledgeParams{},
+		miner.SectorPledgeProjection{},
 		// other types
 		//miner.FaultDeclaration{}, // Aliased from v0
 		//miner.RecoveryDeclaration{}, // Aliased from v0
@@ -223,19 +328,32 @@ func main() {
 		//verifreg.AddVerifiedClientParams{}, // Aliased from v0
 		//verifreg.UseBytesParams{}, // Aliased from v0
 		//verifreg.RestoreBytesParams{}, // Aliased from v0
+		verifreg.RefundBytesParams{},
+		verifreg.TransferDataCapParams{},
+		verifreg.ApproveDataCapParams{},
+		verifreg.UseDataCapAllowanceParams{},
 		// other types
 	); err != nil {
 		panic(err)
 	}
 
+	// Typed wrappers for state collections
+	if err := writeCollectionsToFile("./actors/builtin/miner/collections_gen.go", "miner",
+		miner.State{},
+		miner.Deadline{},
+	); err != nil {
+		panic(err)
+	}
+
 	if err := gen.WriteTupleEncodersToFile("./actors/util/smoothing/cbor_gen.go", "smoothing",
 		smoothing.FilterEstimate{},
 	); err != nil {
 		panic(err)
 	}
 
-	if err := gen.WriteTupleEncodersToFile("./support/vm/cbor_gen.go", "vm",
-		vm.ChainMessage{},
+	if err := gen.WriteTupleEncodersToFile("./support/vm/messages/cbor_gen.go", "messages",
+		messages.ChainMessage{},
+		messages.SignedMessage{},
 	); err != nil {
 		panic(err)
 	}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
 	cbor "github.com/ipfs/go-ipld-cbor"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -16,6 +17,7 @@ import (
 	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
 	"github.com/filecoin-project/specs-actors/v5/actors/builtin/power"
 	"github.com/filecoin-project/specs-actors/v5/actors/states"
+	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
 	"github.com/filecoin-project/specs-actors/v5/support/agent"
 	"github.com/filecoin-project/specs-actors/v5/support/ipld"
 	"github.com/filecoin-project/specs-actors/v5/support/vm"
@@ -64,6 +66,54 @@ func TestCreate20Miners(t *testing.T) {
 	}
 }
 
+func TestUpgradeRehearsal(t *testing.T) {
+	ctx := context.Background()
+	initialBalance := big.Mul(big.NewInt(1000), big.NewInt(1e18))
+	minerCount := 5
+	upgradeEpoch := abi.ChainEpoch(10)
+
+	rnd := rand.New(rand.NewSource(42))
+	sim := agent.NewSim(ctx, t, newBlockStore, agent.SimConfig{Seed: rnd.Int63()})
+	accounts := vm.CreateAccounts(ctx, t, getV5VM(t, sim), minerCount, initialBalance, rnd.Int63())
+	sim.AddAgent(agent.NewMinerGenerator(
+		accounts,
+		agent.MinerAgentConfig{
+			PrecommitRate:    2.5,
+			ProofType:        abi.RegisteredSealProof_StackedDrg32GiBV1_1,
+			StartingBalance:  initialBalance,
+			MinMarketBalance: big.Zero(),
+			MaxMarketBalance: big.Zero(),
+		},
+		1.0, // create miner probability of 1 means a new miner is created every tick
+		rnd.Int63(),
+	))
+
+	// rehearse an upgrade to the same actors, counting migrations
+	var migratedAt []abi.ChainEpoch
+	require.NoError(t, sim.ScheduleUpgrade(agent.SimUpgrade{
+		Epoch: upgradeEpoch,
+		Migration: func(_ context.Context, _ adt.Store, root cid.Cid, epoch abi.ChainEpoch) (cid.Cid, error) {
+			migratedAt = append(migratedAt, epoch)
+			return root, nil
+		},
+		ActorImpls: sim.GetVM().GetActorImpls(),
+	}))
+
+	// upgrades must be scheduled in order
+	assert.Error(t, sim.ScheduleUpgrade(agent.SimUpgrade{Epoch: upgradeEpoch}))
+
+	for i := 0; i < 2*int(upgradeEpoch); i++ {
+		require.NoError(t, sim.Tick())
+	}
+	assert.Equal(t, []abi.ChainEpoch{upgradeEpoch}, migratedAt)
+
+	// agents continue on the upgraded actors
+	assert.Equal(t, minerCount+1, len(sim.Agents))
+	messagesBefore := sim.MessageCount
+	require.NoError(t, sim.Tick())
+	assert.Greater(t, sim.MessageCount, messagesBefore)
+}
+
 // This test covers all the simulation functionality.
 // 500 epochs is long enough for most, not all, important processes to take place, but runs fast enough
 // to keep in CI.
@@ -485,7 +485,13 @@ func (ma *MinerAgent) publishStorageDeals() []message {
 				return errors.Errorf("create miner return has wrong type: %v", ret)
 			}
 
-			for idx, dealId := range publishReturn.IDs {
+			// IDs are returned only for the valid proposals, in order.
+			validIdxs, err := publishReturn.ValidDeals.All(uint64(len(params.Deals)))
+			if err != nil {
+				return err
+			}
+			for i, dealId := range publishReturn.IDs {
+				idx := validIdxs[i]
 				ma.dealsPendingInclusion = append(ma.dealsPendingInclusion, pendingDeal{
 					id:   dealId,
 					size: params.Deals[idx].Proposal.PieceSize,
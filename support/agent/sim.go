@@ -55,10 +55,21 @@ type Sim struct {
 	statsByMethod     map[vm.MethodKey]*vm.CallStats
 	blkStore          ipldcbor.IpldBlockstore
 	blkStoreFactory   func() ipldcbor.IpldBlockstore
+	upgrades          []SimUpgrade // Scheduled upgrades, ordered by epoch
 	ctx               context.Context
 	t                 testing.TB
 }
 
+// SimUpgrade rehearses a network upgrade within a simulation. When the simulation reaches Epoch, its state is
+// migrated and the same agents continue on the upgraded actors.
+type SimUpgrade struct {
+	Epoch             abi.ChainEpoch                                        // First epoch in which the upgraded actors are in effect
+	Migration         vm.StateMigration                                     // Migrates the state tree to the upgraded version
+	ActorImpls        vm2.ActorImplLookup                                   // Upgraded actor implementations
+	VMFactory         VMFactoryFunc                                         // Constructs VMs for the upgraded version, if different
+	MinerStateFactory func(context.Context, cid.Cid) (SimMinerState, error) // Loads upgraded miner state, if different
+}
+
 type VMFactoryFunc func(context.Context, vm2.ActorImplLookup, adt.Store, cid.Cid, abi.ChainEpoch) (SimVM, error)
 
 func NewSim(ctx context.Context, t testing.TB, blockstoreFactory func() ipldcbor.IpldBlockstore, config SimConfig) *Sim {
@@ -119,6 +130,20 @@ func (s *Sim) SwapVM(v SimVM, vmFactory VMFactoryFunc, minerStateFactory func(co
 	s.minerStateFactory = minerStateFactory
 }
 
+// Schedules an upgrade to be applied as the simulation advances to its epoch.
+// Upgrades must be scheduled after the current epoch and after any other scheduled upgrade.
+func (s *Sim) ScheduleUpgrade(upgrade SimUpgrade) error {
+	after := s.v.GetEpoch()
+	if len(s.upgrades) > 0 {
+		after = s.upgrades[len(s.upgrades)-1].Epoch
+	}
+	if upgrade.Epoch <= after {
+		return errors.Errorf("upgrade at epoch %d must be scheduled after epoch %d", upgrade.Epoch, after)
+	}
+	s.upgrades = append(s.upgrades, upgrade)
+	return nil
+}
+
 //////////////////////////////////////////
 //
 //  Sim execution
@@ -200,7 +225,18 @@ func (s *Sim) Tick() error {
 
 	// create next vm
 	nextEpoch := s.v.GetEpoch() + 1
-	if s.Config.CheckpointEpochs > 0 && uint64(nextEpoch)%s.Config.CheckpointEpochs == 0 {
+	checkpoint := s.Config.CheckpointEpochs > 0 && uint64(nextEpoch)%s.Config.CheckpointEpochs == 0
+	if collectable, ok := s.blkStore.(ipld.CollectableBlockStore); checkpoint && ok {
+		// Drop unreachable state in place rather than copying reachable state to a new store.
+		deleted, err := ipld.CollectGarbage(collectable, s.v.StateRoot())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("CHECKPOINT: unreachable state blocks deleted: %d\n", deleted)
+		checkpoint = false
+	}
+
+	if checkpoint {
 		nextStore := s.blkStoreFactory()
 		blks, size, err := BlockstoreCopy(s.blkStore, nextStore, s.v.StateRoot())
 		if err != nil {
@@ -225,9 +261,77 @@ func (s *Sim) Tick() error {
 		s.v.SetStatsSource(statsSource)
 	}
 
+	if len(s.upgrades) > 0 && s.upgrades[0].Epoch == nextEpoch {
+		upgrade := s.upgrades[0]
+		s.upgrades = s.upgrades[1:]
+		return s.upgrade(upgrade)
+	}
 	return err
 }
 
+// Migrates the simulation's state and switches to the upgraded actors, checking that the migrated state
+// meets the state invariants and that balances and power are continuous across the upgrade.
+func (s *Sim) upgrade(upgrade SimUpgrade) error {
+	epoch := s.v.GetEpoch()
+	balanceBefore, err := s.v.GetTotalActorBalance()
+	if err != nil {
+		return err
+	}
+	powerBefore, err := s.computePowerTable(s.v, s.Agents)
+	if err != nil {
+		return err
+	}
+
+	root, err := upgrade.Migration(s.ctx, s.v.Store(), s.v.StateRoot(), epoch)
+	if err != nil {
+		return errors.Wrapf(err, "failed to migrate state for upgrade at epoch %d", epoch)
+	}
+	if upgrade.VMFactory != nil {
+		s.vmFactory = upgrade.VMFactory
+	}
+	if upgrade.MinerStateFactory != nil {
+		s.minerStateFactory = upgrade.MinerStateFactory
+	}
+	statsSource := s.v.GetStatsSource()
+	s.v, err = s.vmFactory(s.ctx, upgrade.ActorImpls, s.v.Store(), root, epoch)
+	if err != nil {
+		return err
+	}
+	s.v.SetStatsSource(statsSource)
+
+	tree, err := states.LoadTree(s.v.Store(), root)
+	if err != nil {
+		return err
+	}
+	acc, err := states.CheckStateInvariants(tree, balanceBefore, epoch-1)
+	if err != nil {
+		return err
+	}
+	if !acc.IsEmpty() {
+		return errors.Errorf("state invariants broken by upgrade at epoch %d:\n%s", epoch, strings.Join(acc.Messages(), "\n"))
+	}
+
+	balanceAfter, err := s.v.GetTotalActorBalance()
+	if err != nil {
+		return err
+	}
+	if !balanceAfter.Equals(balanceBefore) {
+		return errors.Errorf("total balance changed by upgrade at epoch %d from %v to %v", epoch, balanceBefore, balanceAfter)
+	}
+	powerAfter, err := s.computePowerTable(s.v, s.Agents)
+	if err != nil {
+		return err
+	}
+	if !powerAfter.totalQAPower.Equals(powerBefore.totalQAPower) {
+		return errors.Errorf("total power changed by upgrade at epoch %d from %v to %v", epoch, powerBefore.totalQAPower, powerAfter.totalQAPower)
+	}
+	if len(powerAfter.minerPower) != len(powerBefore.minerPower) {
+		return errors.Errorf("miners with consensus power changed by upgrade at epoch %d from %d to %d",
+			epoch, len(powerBefore.minerPower), len(powerAfter.minerPower))
+	}
+	return nil
+}
+
 //////////////////////////////////////////////////
 //
 //  SimState Methods and other accessors
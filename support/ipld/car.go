@@ -0,0 +1,135 @@
+package ipld
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+
+	addr "github.com/filecoin-project/go-address"
+	block "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	ipldcbor "github.com/ipfs/go-ipld-cbor"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/states"
+	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+)
+
+// The header of a CAR (content-addressable archive), version 1.
+type carHeader struct {
+	Roots   []cid.Cid `refmt:"roots"`
+	Version uint64    `refmt:"version"`
+}
+
+func init() {
+	ipldcbor.RegisterCborType(carHeader{})
+}
+
+// Writes a CAR of the state of a single actor, identified by ID address, in the state tree with the given root.
+// The CAR is rooted at the actor's head and holds every block reachable from it, so that one actor's
+// state (e.g. a miner's) can be shared for inspection without the rest of the state tree.
+func ExportActorStateCAR(ctx context.Context, w io.Writer, bs ipldcbor.IpldBlockstore, stateRoot cid.Cid, a addr.Address) error {
+	tree, err := states.LoadTree(adt.WrapBlockStore(ctx, bs), stateRoot)
+	if err != nil {
+		return xerrors.Errorf("failed to load state tree %s: %w", stateRoot, err)
+	}
+	act, found, err := tree.GetActor(a)
+	if err != nil {
+		return xerrors.Errorf("failed to load actor %v: %w", a, err)
+	}
+	if !found {
+		return xerrors.Errorf("no actor %v in state tree %s", a, stateRoot)
+	}
+	return WriteCAR(w, bs, act.Head)
+}
+
+// Writes a CAR with the given roots, holding every stored block reachable from them.
+// As for garbage collection, sector commitments and inlined blocks are not traversed or written.
+func WriteCAR(w io.Writer, bs ipldcbor.IpldBlockstore, roots ...cid.Cid) error {
+	header, err := ipldcbor.DumpObject(&carHeader{Roots: roots, Version: 1})
+	if err != nil {
+		return xerrors.Errorf("failed to encode CAR header: %w", err)
+	}
+	if err := writeCARSection(w, header); err != nil {
+		return xerrors.Errorf("failed to write CAR header: %w", err)
+	}
+
+	written := make(map[cid.Cid]struct{})
+	for _, root := range roots {
+		if err := walkReachable(bs, root, written, func(c cid.Cid, data []byte) error {
+			return writeCARSection(w, c.Bytes(), data)
+		}); err != nil {
+			return xerrors.Errorf("failed to write blocks reachable from %s: %w", root, err)
+		}
+	}
+	return nil
+}
+
+// Reads the blocks of a CAR into a block store, returning the CAR's roots.
+func ReadCAR(r io.Reader, bs ipldcbor.IpldBlockstore) ([]cid.Cid, error) {
+	br := bufio.NewReader(r)
+	headerData, err := readCARSection(br)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read CAR header: %w", err)
+	}
+	var header carHeader
+	if err := ipldcbor.DecodeInto(headerData, &header); err != nil {
+		return nil, xerrors.Errorf("failed to decode CAR header: %w", err)
+	}
+	if header.Version != 1 {
+		return nil, xerrors.Errorf("unsupported CAR version %d", header.Version)
+	}
+
+	for {
+		data, err := readCARSection(br)
+		if err == io.EOF {
+			return header.Roots, nil
+		} else if err != nil {
+			return nil, xerrors.Errorf("failed to read CAR block: %w", err)
+		}
+		n, c, err := cid.CidFromBytes(data)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to decode CAR block CID: %w", err)
+		}
+		blk, err := block.NewBlockWithCid(data[n:], c)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid CAR block %s: %w", c, err)
+		}
+		if err := bs.Put(blk); err != nil {
+			return nil, xerrors.Errorf("failed to put block %s: %w", c, err)
+		}
+	}
+}
+
+// Writes a section of a CAR: the varint length of the concatenated parts, followed by the parts.
+func writeCARSection(w io.Writer, parts ...[]byte) error {
+	length := 0
+	for _, p := range parts {
+		length += len(p)
+	}
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, uint64(length))
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+	for _, p := range parts {
+		if _, err := w.Write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reads a length-prefixed section of a CAR, returning io.EOF if there are no more sections.
+func readCARSection(r *bufio.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, xerrors.Errorf("failed to read section of length %d: %w", length, err)
+	}
+	return data, nil
+}
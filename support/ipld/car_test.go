@@ -0,0 +1,67 @@
+package ipld_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/miner"
+	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/v5/support/ipld"
+	tutil "github.com/filecoin-project/specs-actors/v5/support/testing"
+)
+
+func TestCAR(t *testing.T) {
+	ctx := context.Background()
+	bs := ipld.NewBlockStoreInMemory()
+	store := adt.WrapBlockStore(ctx, bs)
+
+	// An array of sector commitments, which are linked but not stored.
+	arr, err := adt.MakeEmptyArray(store, 2)
+	require.NoError(t, err)
+	for i := uint64(0); i < 16; i++ {
+		commR := cbg.CborCid(tutil.MakeCID(string(rune('a'+i)), &miner.SealedCIDPrefix))
+		require.NoError(t, arr.Set(i, &commR))
+	}
+	root, err := arr.Root()
+	require.NoError(t, err)
+
+	// An unrelated block that is not exported.
+	other := cbg.CborInt(1)
+	otherCid, err := store.Put(ctx, &other)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, ipld.WriteCAR(&buf, bs, root))
+
+	imported := ipld.NewBlockStoreInMemory()
+	roots, err := ipld.ReadCAR(&buf, imported)
+	require.NoError(t, err)
+	assert.Equal(t, []cid.Cid{root}, roots)
+
+	// Every reachable block was exported, and nothing else.
+	_, err = imported.Get(otherCid)
+	assert.Error(t, err)
+	deleted, err := ipld.CollectGarbage(imported, root)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	arr, err = adt.AsArray(adt.WrapBlockStore(ctx, imported), root, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(16), arr.Length())
+	var commR cbg.CborCid
+	require.NoError(t, arr.ForEach(&commR, func(i int64) error {
+		assert.Equal(t, tutil.MakeCID(string(rune('a'+i)), &miner.SealedCIDPrefix), cid.Cid(commR))
+		return nil
+	}))
+
+	// A truncated CAR is rejected.
+	require.NoError(t, ipld.WriteCAR(&buf, bs, root))
+	_, err = ipld.ReadCAR(bytes.NewReader(buf.Bytes()[:buf.Len()-1]), ipld.NewBlockStoreInMemory())
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+package ipld
+
+import (
+	"bytes"
+
+	"github.com/ipfs/go-cid"
+	ipldcbor "github.com/ipfs/go-ipld-cbor"
+	mh "github.com/multiformats/go-multihash"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+)
+
+// A block store whose blocks can be enumerated and deleted, and so garbage collected.
+type CollectableBlockStore interface {
+	ipldcbor.IpldBlockstore
+	// Calls f with the CID of each block in the store. The store must not be modified during iteration.
+	ForEachKey(f func(c cid.Cid) error) error
+	DeleteBlock(c cid.Cid) error
+}
+
+// Deletes every block in a store that is not reachable from one of the given roots, returning the
+// number of blocks deleted. Every reachable block must be present in the store.
+// Only DAG-CBOR blocks are traversed for links; raw blocks and sector commitments have no children.
+func CollectGarbage(bs CollectableBlockStore, roots ...cid.Cid) (uint64, error) {
+	reachable := make(map[cid.Cid]struct{})
+	for _, root := range roots {
+		if err := markReachable(bs, root, reachable); err != nil {
+			return 0, xerrors.Errorf("failed to mark blocks reachable from %s: %w", root, err)
+		}
+	}
+
+	var unreachable []cid.Cid
+	if err := bs.ForEachKey(func(c cid.Cid) error {
+		if _, ok := reachable[c]; !ok {
+			unreachable = append(unreachable, c)
+		}
+		return nil
+	}); err != nil {
+		return 0, xerrors.Errorf("failed to enumerate blocks: %w", err)
+	}
+
+	for _, c := range unreachable {
+		if err := bs.DeleteBlock(c); err != nil {
+			return 0, xerrors.Errorf("failed to delete block %s: %w", c, err)
+		}
+	}
+	return uint64(len(unreachable)), nil
+}
+
+func markReachable(bs ipldcbor.IpldBlockstore, root cid.Cid, reachable map[cid.Cid]struct{}) error {
+	return walkReachable(bs, root, reachable, func(cid.Cid, []byte) error { return nil })
+}
+
+// Calls visit with each stored block reachable from root that is not already in reachable, adding the
+// reachable blocks to it. Sector commitments and inlined blocks are not visited, as they are not stored.
+func walkReachable(bs ipldcbor.IpldBlockstore, root cid.Cid, reachable map[cid.Cid]struct{}, visit func(c cid.Cid, data []byte) error) error {
+	// Iterative depth-first traversal, as state trees can be deep.
+	stack := []cid.Cid{root}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if _, ok := reachable[c]; ok {
+			continue
+		}
+		prefix := c.Prefix()
+		if prefix.Codec == cid.FilCommitmentSealed || prefix.Codec == cid.FilCommitmentUnsealed {
+			continue
+		}
+		// Inlined blocks aren't stored, though they may link to blocks that are.
+		if prefix.MhType == mh.IDENTITY && prefix.Codec == cid.Raw {
+			continue
+		}
+		reachable[c] = struct{}{}
+
+		var data []byte
+		if prefix.MhType == mh.IDENTITY {
+			if prefix.Codec != cid.DagCBOR {
+				continue
+			}
+			decoded, err := mh.Decode(c.Hash())
+			if err != nil {
+				return xerrors.Errorf("failed to decode inlined block %s: %w", c, err)
+			}
+			data = decoded.Digest
+		} else {
+			blk, err := bs.Get(c)
+			if err != nil {
+				return xerrors.Errorf("get %s failed: %w", c, err)
+			}
+			data = blk.RawData()
+			if err := visit(c, data); err != nil {
+				return err
+			}
+		}
+		if prefix.Codec != cid.DagCBOR {
+			continue
+		}
+		if err := cbg.ScanForLinks(bytes.NewReader(data), func(link cid.Cid) {
+			stack = append(stack, link)
+		}); err != nil {
+			return xerrors.Errorf("failed to scan links of %s: %w", c, err)
+		}
+	}
+	return nil
+}
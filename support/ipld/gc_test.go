@@ -0,0 +1,71 @@
+package ipld_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/v5/support/ipld"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	ctx := context.Background()
+	bs := ipld.NewBlockStoreInMemory()
+	store := adt.WrapBlockStore(ctx, bs)
+
+	blockCount := func() int {
+		count := 0
+		require.NoError(t, bs.ForEachKey(func(cid.Cid) error {
+			count++
+			return nil
+		}))
+		return count
+	}
+
+	// Build an array spanning many nodes, then overwrite every entry so that the prior nodes are unreachable.
+	arr, err := adt.MakeEmptyArray(store, 2)
+	require.NoError(t, err)
+	for i := uint64(0); i < 64; i++ {
+		value := cbg.CborInt(i)
+		require.NoError(t, arr.Set(i, &value))
+	}
+	_, err = arr.Root()
+	require.NoError(t, err)
+	for i := uint64(0); i < 64; i++ {
+		value := cbg.CborInt(i * 2)
+		require.NoError(t, arr.Set(i, &value))
+	}
+	root, err := arr.Root()
+	require.NoError(t, err)
+	before := blockCount()
+
+	deleted, err := ipld.CollectGarbage(bs, root)
+	require.NoError(t, err)
+	assert.Greater(t, deleted, uint64(0))
+	assert.Equal(t, before-int(deleted), blockCount())
+
+	// All reachable state remains.
+	arr, err = adt.AsArray(store, root, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(64), arr.Length())
+	var value cbg.CborInt
+	require.NoError(t, arr.ForEach(&value, func(i int64) error {
+		assert.Equal(t, cbg.CborInt(i*2), value)
+		return nil
+	}))
+
+	// Nothing more to collect.
+	deleted, err = ipld.CollectGarbage(bs, root)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	// Collecting with no roots empties the store.
+	_, err = ipld.CollectGarbage(bs)
+	require.NoError(t, err)
+	assert.Zero(t, blockCount())
+}
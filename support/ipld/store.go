@@ -43,6 +43,22 @@ func (mb *BlockStoreInMemory) Put(b block.Block) error {
 	return nil
 }
 
+var _ CollectableBlockStore = (*BlockStoreInMemory)(nil)
+
+func (mb *BlockStoreInMemory) ForEachKey(f func(c cid.Cid) error) error {
+	for c := range mb.data {
+		if err := f(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mb *BlockStoreInMemory) DeleteBlock(c cid.Cid) error {
+	delete(mb.data, c)
+	return nil
+}
+
 //
 // Synchronized block store wrapper.
 //
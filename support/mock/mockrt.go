@@ -75,14 +75,15 @@ type Runtime struct {
 	expectVerifySeal               *expectVerifySeal
 	expectComputeUnsealedSectorCID []*expectComputeUnsealedSectorCID
 	expectVerifyPoSt               *expectVerifyPoSt
-	expectVerifyConsensusFault     *expectVerifyConsensusFault
+	expectVerifyConsensusFaults    []*expectVerifyConsensusFault
 	expectDeleteActor              *addr.Address
 	expectBatchVerifySeals         *expectBatchVerifySeals
 	expectAggregateVerifySeals     *expectAggregateVerifySeals
 	// Gas charged explicitly through rt.ChargeGas. Note: most charges are implicit
 	expectGasCharged []int64
 
-	logs []string
+	logs   []string
+	events []runtime.ActorEvent
 }
 
 type expectBatchVerifySeals struct {
@@ -709,26 +710,27 @@ func (rt *Runtime) VerifyPoSt(vi proof.WindowPoStVerifyInfo) error {
 }
 
 func (rt *Runtime) VerifyConsensusFault(h1, h2, extra []byte) (*runtime.ConsensusFault, error) {
-	if rt.expectVerifyConsensusFault == nil {
+	if len(rt.expectVerifyConsensusFaults) == 0 {
 		rt.failTestNow("Unexpected syscall VerifyConsensusFault")
 		return nil, nil
 	}
 
-	if rt.expectVerifyConsensusFault.requireCorrectInput {
-		if !bytes.Equal(h1, rt.expectVerifyConsensusFault.BlockHeader1) {
-			rt.failTest("block header 1 does not equal expected block header 1 (%v != %v)", h1, rt.expectVerifyConsensusFault.BlockHeader1)
+	exp := rt.expectVerifyConsensusFaults[0]
+	if exp.requireCorrectInput {
+		if !bytes.Equal(h1, exp.BlockHeader1) {
+			rt.failTest("block header 1 does not equal expected block header 1 (%v != %v)", h1, exp.BlockHeader1)
 		}
-		if !bytes.Equal(h2, rt.expectVerifyConsensusFault.BlockHeader2) {
-			rt.failTest("block header 2 does not equal expected block header 2 (%v != %v)", h2, rt.expectVerifyConsensusFault.BlockHeader2)
+		if !bytes.Equal(h2, exp.BlockHeader2) {
+			rt.failTest("block header 2 does not equal expected block header 2 (%v != %v)", h2, exp.BlockHeader2)
 		}
-		if !bytes.Equal(extra, rt.expectVerifyConsensusFault.BlockHeaderExtra) {
-			rt.failTest("block header extra does not equal expected block header extra (%v != %v)", extra, rt.expectVerifyConsensusFault.BlockHeaderExtra)
+		if !bytes.Equal(extra, exp.BlockHeaderExtra) {
+			rt.failTest("block header extra does not equal expected block header extra (%v != %v)", extra, exp.BlockHeaderExtra)
 		}
 	}
 
-	fault := rt.expectVerifyConsensusFault.Fault
-	err := rt.expectVerifyConsensusFault.Err
-	rt.expectVerifyConsensusFault = nil
+	fault := exp.Fault
+	err := exp.Err
+	rt.expectVerifyConsensusFaults = rt.expectVerifyConsensusFaults[1:]
 	return fault, err
 }
 
@@ -736,6 +738,10 @@ func (rt *Runtime) Log(level rt.LogLevel, msg string, args ...interface{}) {
 	rt.logs = append(rt.logs, fmt.Sprintf(msg, args...))
 }
 
+func (rt *Runtime) EmitEvent(eventType string, payload cbor.Marshaler) {
+	rt.events = append(rt.events, runtime.ActorEvent{Type: eventType, Payload: payload})
+}
+
 ///// Trace span implementation /////
 
 type TraceSpan struct {
@@ -938,14 +944,14 @@ func (rt *Runtime) ExpectVerifyPoSt(post proof.WindowPoStVerifyInfo, result erro
 }
 
 func (rt *Runtime) ExpectVerifyConsensusFault(h1, h2, extra []byte, result *runtime.ConsensusFault, resultErr error) {
-	rt.expectVerifyConsensusFault = &expectVerifyConsensusFault{
+	rt.expectVerifyConsensusFaults = append(rt.expectVerifyConsensusFaults, &expectVerifyConsensusFault{
 		requireCorrectInput: true,
 		BlockHeader1:        h1,
 		BlockHeader2:        h2,
 		BlockHeaderExtra:    extra,
 		Fault:               result,
 		Err:                 resultErr,
-	}
+	})
 }
 
 // Verifies that expected calls were received, and resets all expectations.
@@ -1000,7 +1006,7 @@ func (rt *Runtime) Verify() {
 		rt.failTest("missing expected PoSt verification with %v", rt.expectVerifyPoSt)
 	}
 
-	if rt.expectVerifyConsensusFault != nil {
+	if len(rt.expectVerifyConsensusFaults) > 0 {
 		rt.failTest("missing expected verify consensus fault")
 	}
 	if rt.expectDeleteActor != nil {
@@ -1023,6 +1029,7 @@ func (rt *Runtime) Reset() {
 	rt.expectVerifySeal = nil
 	rt.expectBatchVerifySeals = nil
 	rt.expectComputeUnsealedSectorCID = nil
+	rt.expectVerifyConsensusFaults = nil
 }
 
 // Calls f() expecting it to invoke Runtime.Abortf() with a specified exit code.
@@ -1034,6 +1041,7 @@ func (rt *Runtime) ExpectAbort(expected exitcode.ExitCode, f func()) {
 func (rt *Runtime) ExpectAbortContainsMessage(expected exitcode.ExitCode, substr string, f func()) {
 	rt.t.Helper()
 	prevState := rt.state
+	prevEvents := len(rt.events)
 
 	defer func() {
 		rt.t.Helper()
@@ -1054,8 +1062,9 @@ func (rt *Runtime) ExpectAbortContainsMessage(expected exitcode.ExitCode, substr
 				rt.failTest("abort expected message\n'%s'\nto contain\n'%s'\n", a.msg, substr)
 			}
 		}
-		// Roll back state change.
+		// Roll back state change, and discard events.
 		rt.state = prevState
+		rt.events = rt.events[:prevEvents]
 	}()
 	f()
 }
@@ -1073,6 +1082,37 @@ func (rt *Runtime) ClearLogs() {
 	rt.logs = []string{}
 }
 
+// Checks that an event of a type has been emitted with a payload serializing equal to the one given.
+func (rt *Runtime) ExpectEmitted(eventType string, payload cbor.Marshaler) {
+	serialize := func(o cbor.Marshaler) []byte {
+		var buf bytes.Buffer
+		if err := o.MarshalCBOR(&buf); err != nil {
+			rt.failTestNow("failed to serialize event payload: %v", err)
+		}
+		return buf.Bytes()
+	}
+	expected := serialize(payload)
+	for _, event := range rt.events {
+		if event.Type == eventType && bytes.Equal(serialize(event.Payload), expected) {
+			return
+		}
+	}
+	rt.failTest("%d event(s) emitted and none is %s with payload %v", len(rt.events), eventType, payload)
+}
+
+// Checks that no event of a type has been emitted.
+func (rt *Runtime) ExpectNotEmitted(eventType string) {
+	for _, event := range rt.events {
+		if event.Type == eventType {
+			rt.failTest("unexpected %s event emitted with payload %v", eventType, event.Payload)
+		}
+	}
+}
+
+func (rt *Runtime) ClearEvents() {
+	rt.events = []runtime.ActorEvent{}
+}
+
 func (rt *Runtime) ExpectGasCharged(gas int64) {
 	rt.expectGasCharged = append(rt.expectGasCharged, gas)
 }
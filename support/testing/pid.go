@@ -1,7 +1,15 @@
 package testing
 
-import "github.com/filecoin-project/go-state-types/abi"
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+	mh "github.com/multiformats/go-multihash"
+)
 
+// Makes a well-formed peer ID, being the identity multihash of the input.
 func MakePID(input string) abi.PeerID {
-	return abi.PeerID([]byte(input))
+	pid, err := mh.Encode([]byte(input), mh.IDENTITY)
+	if err != nil {
+		panic(err)
+	}
+	return abi.PeerID(pid)
 }
@@ -29,11 +29,12 @@ import (
 	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
 	"github.com/filecoin-project/specs-actors/v5/support/ipld"
 	"github.com/filecoin-project/specs-actors/v5/support/testing"
+	"github.com/filecoin-project/specs-actors/v5/support/vm/messages"
 )
 
 var EmptyObjectCid cid.Cid
 
-const defaultGasLimit = 5_000_000_000
+const defaultGasLimit = messages.DefaultGasLimit
 
 // Context for an individual message invocation, including inter-actor sends.
 type invocationContext struct {
@@ -363,6 +364,8 @@ func (ic *invocationContext) NewActorAddress() address.Address {
 	if err != nil {
 		panic(err)
 	}
+	// Each address created within a message must be distinct.
+	ic.topLevel.newActorAddressCount++
 	return actorAddress
 }
 
@@ -491,6 +494,11 @@ func (ic *invocationContext) Log(level rt.LogLevel, msg string, args ...interfac
 	ic.rt.Log(level, msg, args...)
 }
 
+// Records an event with the current invocation.
+func (ic *invocationContext) EmitEvent(eventType string, payload cbor.Marshaler) {
+	ic.rt.emitEvent(runtime.ActorEvent{Type: eventType, Payload: payload})
+}
+
 type returnWrapper struct {
 	inner cbor.Marshaler
 }
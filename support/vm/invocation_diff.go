@@ -0,0 +1,196 @@
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/cbor"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+)
+
+// Maximum length of a formatted parameter or return value in an invocation diff.
+const maxFormattedObjectLen = 160
+
+// A line of a side-by-side rendering of expected and actual invocation trees.
+// Either side is empty if the invocation is absent from that tree.
+type invocationDiffRow struct {
+	depth    int
+	expected string
+	actual   string
+	differs  bool
+}
+
+// Lists the rows of a side-by-side diff of an expected invocation tree and an actual one, in pre-order.
+// Sub-invocations are paired by position. When an expectation leaves its sub-invocations unspecified
+// (constrained is false for them), the actual sub-invocations are listed without being marked as differing.
+func diffInvocations(rows []invocationDiffRow, depth int, ei *ExpectInvocation, inv *Invocation, constrained bool) []invocationDiffRow {
+	row := invocationDiffRow{depth: depth}
+	if ei != nil {
+		row.expected = ei.describe()
+	}
+	if inv != nil {
+		row.actual = describeInvocation(inv, ei)
+	}
+	switch {
+	case ei != nil && inv != nil:
+		row.differs = !ei.matchesShallow(inv)
+	default:
+		row.differs = constrained
+	}
+	rows = append(rows, row)
+
+	var expectedSubs []ExpectInvocation
+	var actualSubs []*Invocation
+	subsConstrained := constrained
+	if ei != nil {
+		expectedSubs = ei.SubInvocations
+		subsConstrained = ei.SubInvocations != nil
+	}
+	if inv != nil {
+		actualSubs = inv.SubInvocations
+	}
+	for i := 0; i < len(expectedSubs) || i < len(actualSubs); i++ {
+		var subExpected *ExpectInvocation
+		var subActual *Invocation
+		if i < len(expectedSubs) {
+			subExpected = &expectedSubs[i]
+		}
+		if i < len(actualSubs) {
+			subActual = actualSubs[i]
+		}
+		rows = diffInvocations(rows, depth+1, subExpected, subActual, subsConstrained)
+	}
+	return rows
+}
+
+// Renders diff rows as two columns, expected on the left and actual on the right, marking differing rows with "!".
+func renderInvocationDiff(rows []invocationDiffRow) string {
+	const expectedTitle, actualTitle = "expected", "actual"
+	width := len(expectedTitle)
+	for _, row := range rows {
+		if l := len(indentInvocation(row.depth, row.expected)); l > width {
+			width = l
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "invocation trees:\n  %-*s | %s\n", width, expectedTitle, actualTitle)
+	for _, row := range rows {
+		marker := " "
+		if row.differs {
+			marker = "!"
+		}
+		fmt.Fprintf(&b, "%s %-*s | %s\n", marker, width, indentInvocation(row.depth, row.expected), indentInvocation(row.depth, row.actual))
+	}
+	return b.String()
+}
+
+func indentInvocation(depth int, desc string) string {
+	if desc == "" {
+		return ""
+	}
+	return strings.Repeat("  ", depth) + desc
+}
+
+// Describes the fields of an expectation that are specified.
+func (ei ExpectInvocation) describe() string {
+	desc := fmt.Sprintf("[%s:%d] exit=%d", ei.To, ei.Method, ei.Exitcode)
+	if ei.From != address.Undef {
+		desc += fmt.Sprintf(" from=%s", ei.From)
+	}
+	if ei.Value != nil {
+		desc += fmt.Sprintf(" value=%v", *ei.Value)
+	}
+	if ei.Params != nil {
+		desc += fmt.Sprintf(" params=%s", formatObject(ei.Params.val))
+	}
+	if ei.Ret != nil {
+		desc += fmt.Sprintf(" ret=%s", formatObject(ei.Ret.val))
+	}
+	return desc
+}
+
+// Describes an invocation, including those fields specified by the expectation against which it's compared (if any).
+// Parameters and return values are decoded to the types of the expected ones when they are raw bytes.
+func describeInvocation(inv *Invocation, ei *ExpectInvocation) string {
+	desc := fmt.Sprintf("[%s:%d] exit=%d", inv.Msg.to, inv.Msg.method, inv.Exitcode)
+	if ei == nil {
+		return desc
+	}
+	if ei.From != address.Undef {
+		desc += fmt.Sprintf(" from=%s", inv.Msg.from)
+	}
+	if ei.Value != nil {
+		desc += fmt.Sprintf(" value=%v", inv.Msg.value)
+	}
+	if ei.Params != nil {
+		desc += fmt.Sprintf(" params=%s", formatObjectLike(inv.Msg.params, ei.Params.val))
+	}
+	if ei.Ret != nil {
+		desc += fmt.Sprintf(" ret=%s", formatObjectLike(inv.Ret, ei.Ret.val))
+	}
+	return desc
+}
+
+// Checks an invocation against an expectation, ignoring sub-invocations.
+func (ei ExpectInvocation) matchesShallow(inv *Invocation) bool {
+	return ei.To == inv.Msg.to &&
+		ei.Method == inv.Msg.method &&
+		ei.Exitcode == inv.Exitcode &&
+		(ei.From == address.Undef || ei.From == inv.Msg.from) &&
+		(ei.Value == nil || ei.Value.Equals(inv.Msg.value)) &&
+		(ei.Params == nil || ei.Params.matches(inv.Msg.params)) &&
+		(ei.Ret == nil || ei.Ret.matches(inv.Ret))
+}
+
+// Formats a parameter or return value, with raw bytes in hex.
+func formatObject(obj interface{}) string {
+	var s string
+	switch v := obj.(type) {
+	case nil:
+		s = "nil"
+	case builtin.CBORBytes:
+		s = fmt.Sprintf("0x%x", []byte(v))
+	case []byte:
+		s = fmt.Sprintf("0x%x", v)
+	default:
+		if rv := reflect.ValueOf(obj); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			s = "nil"
+		} else {
+			s = fmt.Sprintf("%+v", obj)
+		}
+	}
+	if len(s) > maxFormattedObjectLen {
+		s = s[:maxFormattedObjectLen] + "..."
+	}
+	return s
+}
+
+// Formats a parameter or return value, first decoding raw bytes to the type of another value if possible.
+func formatObjectLike(obj interface{}, like cbor.Marshaler) string {
+	var raw []byte
+	switch v := obj.(type) {
+	case builtin.CBORBytes:
+		raw = v
+	case []byte:
+		raw = v
+	default:
+		return formatObject(obj)
+	}
+	likeType := reflect.TypeOf(like)
+	if likeType == nil || likeType.Kind() != reflect.Ptr {
+		return formatObject(obj)
+	}
+	decoded, ok := reflect.New(likeType.Elem()).Interface().(cbor.Unmarshaler)
+	if !ok {
+		return formatObject(obj)
+	}
+	if err := decoded.UnmarshalCBOR(bytes.NewReader(raw)); err != nil {
+		return formatObject(obj)
+	}
+	return formatObject(decoded)
+}
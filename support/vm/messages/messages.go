@@ -0,0 +1,169 @@
+// Package messages constructs, encodes and signs chain messages in the same form as the test VM.
+// Tools generating test vectors or network traffic can use it to produce messages identical to those
+// applied in scenario tests.
+package messages
+
+import (
+	"bytes"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/cbor"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+)
+
+// Gas limit given to messages constructed by NewChainMessage.
+const DefaultGasLimit = 5_000_000_000
+
+// Version of the chain message encoding.
+const MessageVersion = 0
+
+type ChainMessage struct {
+	Version uint64
+
+	From address.Address
+	To   address.Address
+
+	Nonce uint64
+
+	Value abi.TokenAmount
+
+	GasLimit   int64
+	GasFeeCap  abi.TokenAmount
+	GasPremium abi.TokenAmount
+
+	Method abi.MethodNum
+	Params []byte
+}
+
+type SignedMessage struct {
+	Message   ChainMessage
+	Signature crypto.Signature
+}
+
+// Signer produces a signature over some bytes with the private key for an address.
+// The address is the key address (not ID address) of the message sender.
+type Signer interface {
+	Sign(signer address.Address, plaintext []byte) (*crypto.Signature, error)
+}
+
+// SignatureVerifier checks a signature over some bytes against the key address of the signer.
+// This matches the signature of runtime.Syscalls.VerifySignature.
+type SignatureVerifier func(signature crypto.Signature, signer address.Address, plaintext []byte) error
+
+// NewChainMessage constructs a chain message with the default gas limit and zero gas fee cap and premium.
+// A nil params value is encoded as abi.Empty.
+func NewChainMessage(from, to address.Address, nonce uint64, value abi.TokenAmount, method abi.MethodNum, params cbor.Marshaler) (*ChainMessage, error) {
+	serializedParams, err := SerializeParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChainMessage{
+		Version:    MessageVersion,
+		From:       from,
+		To:         to,
+		Nonce:      nonce,
+		Value:      value,
+		GasLimit:   DefaultGasLimit,
+		GasFeeCap:  big.Zero(),
+		GasPremium: big.Zero(),
+		Method:     method,
+		Params:     serializedParams,
+	}, nil
+}
+
+// SerializeParams encodes method parameters as carried in a chain message.
+// A nil params value is encoded as abi.Empty.
+func SerializeParams(params cbor.Marshaler) ([]byte, error) {
+	if params == nil {
+		params = abi.Empty
+	}
+	var buf bytes.Buffer
+	if err := params.MarshalCBOR(&buf); err != nil {
+		return nil, xerrors.Errorf("failed to serialize params: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Serialize returns the CBOR encoding of the message.
+func (m *ChainMessage) Serialize() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := m.MarshalCBOR(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Cid returns the content identifier of the message's CBOR encoding.
+func (m *ChainMessage) Cid() (cid.Cid, error) {
+	bs, err := m.Serialize()
+	if err != nil {
+		return cid.Undef, err
+	}
+	return abi.CidBuilder.Sum(bs)
+}
+
+// SigningBytes returns the bytes over which a message sender signs, which are the bytes of the message CID.
+func (m *ChainMessage) SigningBytes() ([]byte, error) {
+	c, err := m.Cid()
+	if err != nil {
+		return nil, err
+	}
+	return c.Bytes(), nil
+}
+
+// SignMessage signs a message with the key for its From address.
+// The From address must be a key address, since the signer can't resolve ID addresses.
+func SignMessage(msg *ChainMessage, signer Signer) (*SignedMessage, error) {
+	if msg.From.Protocol() == address.ID {
+		return nil, xerrors.Errorf("cannot sign message from ID address %v", msg.From)
+	}
+	plaintext, err := msg.SigningBytes()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to compute signing bytes: %w", err)
+	}
+	sig, err := signer.Sign(msg.From, plaintext)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to sign message from %v: %w", msg.From, err)
+	}
+	return &SignedMessage{
+		Message:   *msg,
+		Signature: *sig,
+	}, nil
+}
+
+// Verify checks the message signature against the message's From address.
+func (sm *SignedMessage) Verify(verify SignatureVerifier) error {
+	plaintext, err := sm.Message.SigningBytes()
+	if err != nil {
+		return xerrors.Errorf("failed to compute signing bytes: %w", err)
+	}
+	return verify(sm.Signature, sm.Message.From, plaintext)
+}
+
+// Serialize returns the CBOR encoding of the signed message.
+func (sm *SignedMessage) Serialize() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := sm.MarshalCBOR(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Cid returns the content identifier by which the signed message is included in a block.
+// BLS-signed messages are identified by the unsigned message CID, since their signatures are aggregated
+// per block, while other signed messages are identified by the CID of the whole signed message.
+func (sm *SignedMessage) Cid() (cid.Cid, error) {
+	if sm.Signature.Type == crypto.SigTypeBLS {
+		return sm.Message.Cid()
+	}
+	bs, err := sm.Serialize()
+	if err != nil {
+		return cid.Undef, err
+	}
+	return abi.CidBuilder.Sum(bs)
+}
@@ -0,0 +1,98 @@
+package messages_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v5/support/vm/messages"
+)
+
+// Signs with the sender's address bytes prepended to the plaintext, which is sufficient to check plumbing.
+type fakeSigner struct{}
+
+func (fakeSigner) Sign(signer address.Address, plaintext []byte) (*crypto.Signature, error) {
+	return &crypto.Signature{
+		Type: crypto.SigTypeSecp256k1,
+		Data: append(signer.Bytes(), plaintext...),
+	}, nil
+}
+
+func fakeVerify(sig crypto.Signature, signer address.Address, plaintext []byte) error {
+	if !bytes.Equal(sig.Data, append(signer.Bytes(), plaintext...)) {
+		return xerrors.Errorf("bad signature")
+	}
+	return nil
+}
+
+func TestSignMessage(t *testing.T) {
+	from, err := address.NewSecp256k1Address([]byte("sender public key"))
+	require.NoError(t, err)
+	to, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	params := &address.Address{}
+	*params, err = address.NewIDAddress(1001)
+	require.NoError(t, err)
+
+	msg, err := messages.NewChainMessage(from, to, 3, big.NewInt(10), builtin.MethodsMiner.ChangeWorkerAddress, params)
+	require.NoError(t, err)
+	assert.Equal(t, int64(messages.DefaultGasLimit), msg.GasLimit)
+
+	t.Run("params encoded", func(t *testing.T) {
+		encoded, err := messages.SerializeParams(params)
+		require.NoError(t, err)
+		assert.Equal(t, encoded, msg.Params)
+
+		empty, err := messages.SerializeParams(nil)
+		require.NoError(t, err)
+		assert.Empty(t, empty)
+	})
+
+	t.Run("sign and verify", func(t *testing.T) {
+		sm, err := messages.SignMessage(msg, fakeSigner{})
+		require.NoError(t, err)
+		assert.NoError(t, sm.Verify(fakeVerify))
+
+		// Round trip through encoding preserves the signature.
+		bs, err := sm.Serialize()
+		require.NoError(t, err)
+		var decoded messages.SignedMessage
+		require.NoError(t, decoded.UnmarshalCBOR(bytes.NewReader(bs)))
+		assert.Equal(t, *sm, decoded)
+		assert.NoError(t, decoded.Verify(fakeVerify))
+
+		// Altering the message invalidates the signature.
+		decoded.Message.Value = big.NewInt(11)
+		assert.Error(t, decoded.Verify(fakeVerify))
+	})
+
+	t.Run("signed message cid", func(t *testing.T) {
+		sm, err := messages.SignMessage(msg, fakeSigner{})
+		require.NoError(t, err)
+		msgCid, err := msg.Cid()
+		require.NoError(t, err)
+		signedCid, err := sm.Cid()
+		require.NoError(t, err)
+		assert.NotEqual(t, msgCid, signedCid)
+
+		sm.Signature.Type = crypto.SigTypeBLS
+		signedCid, err = sm.Cid()
+		require.NoError(t, err)
+		assert.Equal(t, msgCid, signedCid)
+	})
+
+	t.Run("can't sign from ID address", func(t *testing.T) {
+		idMsg, err := messages.NewChainMessage(to, to, 0, abi.NewTokenAmount(0), builtin.MethodSend, nil)
+		require.NoError(t, err)
+		_, err = messages.SignMessage(idMsg, fakeSigner{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,161 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/cbor"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/account"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/cron"
+	initactor "github.com/filecoin-project/specs-actors/v5/actors/builtin/init"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/market"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/miner"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/multisig"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/paych"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/power"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/reward"
+	"github.com/filecoin-project/specs-actors/v5/actors/builtin/verifreg"
+	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+)
+
+//
+// Decoded actor state.
+// Each getter fails the test if the state cannot be loaded, so that scenario tests can check state inline.
+//
+
+func GetAccountState(t *testing.T, vm *VM, a address.Address) *account.State {
+	var st account.State
+	requireState(t, vm, a, &st)
+	return &st
+}
+
+func GetInitState(t *testing.T, vm *VM) *initactor.State {
+	var st initactor.State
+	requireState(t, vm, builtin.InitActorAddr, &st)
+	return &st
+}
+
+func GetCronState(t *testing.T, vm *VM) *cron.State {
+	var st cron.State
+	requireState(t, vm, builtin.CronActorAddr, &st)
+	return &st
+}
+
+func GetRewardState(t *testing.T, vm *VM) *reward.State {
+	var st reward.State
+	requireState(t, vm, builtin.RewardActorAddr, &st)
+	return &st
+}
+
+func GetPowerState(t *testing.T, vm *VM) *power.State {
+	var st power.State
+	requireState(t, vm, builtin.StoragePowerActorAddr, &st)
+	return &st
+}
+
+// Returns the power actor's claim for a miner, which must exist.
+func GetClaim(t *testing.T, vm *VM, minerAddr address.Address) *power.Claim {
+	claim, found, err := GetPowerState(t, vm).GetClaim(vm.store, requireIDAddress(t, vm, minerAddr))
+	require.NoError(t, err)
+	require.True(t, found, "no claim for miner %v", minerAddr)
+	return claim
+}
+
+func GetMarketState(t *testing.T, vm *VM) *market.State {
+	var st market.State
+	requireState(t, vm, builtin.StorageMarketActorAddr, &st)
+	return &st
+}
+
+// Returns the proposal of a published deal, which must exist.
+func GetDealProposal(t *testing.T, vm *VM, dealID abi.DealID) *market.DealProposal {
+	proposals, err := market.AsDealProposalArray(vm.store, GetMarketState(t, vm).Proposals)
+	require.NoError(t, err)
+
+	proposal, found, err := proposals.Get(dealID)
+	require.NoError(t, err)
+	require.True(t, found, "no proposal for deal %d", dealID)
+	return proposal
+}
+
+// Returns the state of a deal, and whether the deal has been activated.
+func GetDealState(t *testing.T, vm *VM, dealID abi.DealID) (*market.DealState, bool) {
+	states, err := market.AsDealStateArray(vm.store, GetMarketState(t, vm).States)
+	require.NoError(t, err)
+
+	state, found, err := states.Get(dealID)
+	require.NoError(t, err)
+
+	return state, found
+}
+
+func GetMinerState(t *testing.T, vm *VM, minerAddr address.Address) *miner.State {
+	var st miner.State
+	requireState(t, vm, minerAddr, &st)
+	return &st
+}
+
+func GetMinerInfo(t *testing.T, vm *VM, minerAddr address.Address) *miner.MinerInfo {
+	info, err := GetMinerState(t, vm, minerAddr).GetInfo(vm.store)
+	require.NoError(t, err)
+	return info
+}
+
+// Returns a miner's sector, which must exist.
+func GetSector(t *testing.T, vm *VM, minerAddr address.Address, sectorNumber abi.SectorNumber) *miner.SectorOnChainInfo {
+	sector, found, err := GetMinerState(t, vm, minerAddr).GetSector(vm.store, sectorNumber)
+	require.NoError(t, err)
+	require.True(t, found, "no sector %d for miner %v", sectorNumber, minerAddr)
+	return sector
+}
+
+// Returns a miner's pre-committed sector, which must exist.
+func GetPrecommittedSector(t *testing.T, vm *VM, minerAddr address.Address, sectorNumber abi.SectorNumber) *miner.SectorPreCommitOnChainInfo {
+	precommit, found, err := GetMinerState(t, vm, minerAddr).GetPrecommittedSector(vm.store, sectorNumber)
+	require.NoError(t, err)
+	require.True(t, found, "no pre-committed sector %d for miner %v", sectorNumber, minerAddr)
+	return precommit
+}
+
+func GetMultisigState(t *testing.T, vm *VM, msigAddr address.Address) *multisig.State {
+	var st multisig.State
+	requireState(t, vm, msigAddr, &st)
+	return &st
+}
+
+func GetPaychState(t *testing.T, vm *VM, paychAddr address.Address) *paych.State {
+	var st paych.State
+	requireState(t, vm, paychAddr, &st)
+	return &st
+}
+
+func GetVerifregState(t *testing.T, vm *VM) *verifreg.State {
+	var st verifreg.State
+	requireState(t, vm, builtin.VerifiedRegistryActorAddr, &st)
+	return &st
+}
+
+// Returns the data cap of a verified client, and whether the client is verified.
+func GetVerifiedClientDataCap(t *testing.T, vm *VM, clientAddr address.Address) (verifreg.DataCap, bool) {
+	clients, err := adt.AsMap(vm.store, GetVerifregState(t, vm).VerifiedClients, builtin.DefaultHamtBitwidth)
+	require.NoError(t, err)
+
+	var dataCap verifreg.DataCap
+	found, err := clients.Get(abi.AddrKey(requireIDAddress(t, vm, clientAddr)), &dataCap)
+	require.NoError(t, err)
+	return dataCap, found
+}
+
+func requireState(t *testing.T, vm *VM, a address.Address, out cbor.Unmarshaler) {
+	require.NoError(t, vm.GetState(a, out), "failed to load state of actor %v", a)
+}
+
+func requireIDAddress(t *testing.T, vm *VM, a address.Address) address.Address {
+	idAddr, found := vm.NormalizeAddress(a)
+	require.True(t, found, "no ID address for %v", a)
+	return idAddr
+}
@@ -89,6 +89,9 @@ func NewVMWithSingletons(ctx context.Context, t testing.TB, bs ipldcbor.IpldBloc
 	// burnt funds
 	initializeActor(ctx, t, vm, &account.State{Address: builtin.BurntFundsActorAddr}, builtin.AccountActorCodeID, builtin.BurntFundsActorAddr, big.Zero())
 
+	// treasury
+	initializeActor(ctx, t, vm, &account.State{Address: builtin.TreasuryActorAddr}, builtin.AccountActorCodeID, builtin.TreasuryActorAddr, big.Zero())
+
 	_, err = vm.checkpoint()
 	require.NoError(t, err)
 
@@ -146,71 +149,60 @@ type ExpectInvocation struct {
 }
 
 func (ei ExpectInvocation) Matches(t *testing.T, invocations *Invocation) {
-	ei.matches(t, "", invocations)
+	// On failure, the whole expected and actual invocation trees are rendered side by side.
+	diff := func() string {
+		return renderInvocationDiff(diffInvocations(nil, 0, &ei, invocations, true))
+	}
+	ei.matches(t, "", invocations, diff)
 }
 
-func (ei ExpectInvocation) matches(t *testing.T, breadcrumb string, invocation *Invocation) {
+func (ei ExpectInvocation) matches(t *testing.T, breadcrumb string, invocation *Invocation, diff func() string) {
 	identifier := fmt.Sprintf("%s[%s:%d]", breadcrumb, invocation.Msg.to, invocation.Msg.method)
 
 	// mismatch of to or method probably indicates skipped message or messages out of order. halt.
-	require.Equal(t, ei.To, invocation.Msg.to, "%s unexpected 'to' address", identifier)
-	require.Equal(t, ei.Method, invocation.Msg.method, "%s unexpected method", identifier)
+	if ei.To != invocation.Msg.to {
+		require.FailNow(t, fmt.Sprintf("%s unexpected 'to' address %s, expected %s", identifier, invocation.Msg.to, ei.To), diff())
+	}
+	if ei.Method != invocation.Msg.method {
+		require.FailNow(t, fmt.Sprintf("%s unexpected method %d, expected %d", identifier, invocation.Msg.method, ei.Method), diff())
+	}
 
 	// other expectations are optional
-	if address.Undef != ei.From {
-		assert.Equal(t, ei.From, invocation.Msg.from, "%s unexpected from address", identifier)
+	if address.Undef != ei.From && ei.From != invocation.Msg.from {
+		assert.Fail(t, fmt.Sprintf("%s unexpected from address %s, expected %s", identifier, invocation.Msg.from, ei.From), diff())
 	}
-	if ei.Value != nil {
-		assert.Equal(t, *ei.Value, invocation.Msg.value, "%s unexpected value", identifier)
+	if ei.Value != nil && !ei.Value.Equals(invocation.Msg.value) {
+		assert.Fail(t, fmt.Sprintf("%s unexpected value %v, expected %v", identifier, invocation.Msg.value, *ei.Value), diff())
 	}
-	if ei.Params != nil {
-		assert.True(t, ei.Params.matches(invocation.Msg.params), "%s params aren't equal (expected %v, was %v)", identifier, ei.Params.val, invocation.Msg.params)
+	if ei.Params != nil && !ei.Params.matches(invocation.Msg.params) {
+		assert.Fail(t, fmt.Sprintf("%s params aren't equal (expected %s, was %s)", identifier,
+			formatObject(ei.Params.val), formatObjectLike(invocation.Msg.params, ei.Params.val)), diff())
 	}
 	if ei.SubInvocations != nil {
 		for i, invk := range invocation.SubInvocations {
 			subidentifier := fmt.Sprintf("%s%d:", identifier, i)
 			// attempt match only if methods match
-			require.True(t, len(ei.SubInvocations) > i && ei.SubInvocations[i].To == invk.Msg.to && ei.SubInvocations[i].Method == invk.Msg.method,
-				"%s unexpected subinvocation [%s:%d]\nexpected:\n%s\nactual:\n%s",
-				subidentifier, invk.Msg.to, invk.Msg.method, ei.listSubinvocations(), listInvocations(invocation.SubInvocations))
-			ei.SubInvocations[i].matches(t, subidentifier, invk)
+			if len(ei.SubInvocations) <= i || ei.SubInvocations[i].To != invk.Msg.to || ei.SubInvocations[i].Method != invk.Msg.method {
+				require.FailNow(t, fmt.Sprintf("%s unexpected subinvocation [%s:%d]", subidentifier, invk.Msg.to, invk.Msg.method), diff())
+			}
+			ei.SubInvocations[i].matches(t, subidentifier, invk, diff)
 		}
 		missingInvocations := len(ei.SubInvocations) - len(invocation.SubInvocations)
 		if missingInvocations > 0 {
 			missingIndex := len(invocation.SubInvocations)
 			missingExpect := ei.SubInvocations[missingIndex]
-			require.Failf(t, "missing expected invocations", "%s%d: expected invocation [%s:%d]\nexpected:\n%s\nactual:\n%s",
-				identifier, missingIndex, missingExpect.To, missingExpect.Method, ei.listSubinvocations(), listInvocations(invocation.SubInvocations))
+			require.FailNow(t, fmt.Sprintf("%s%d: missing expected invocation [%s:%d]", identifier, missingIndex, missingExpect.To, missingExpect.Method), diff())
 		}
 	}
 
 	// expect results
-	assert.Equal(t, ei.Exitcode, invocation.Exitcode, "%s unexpected exitcode", identifier)
-	if ei.Ret != nil {
-		assert.True(t, ei.Ret.matches(invocation.Ret), "%s unexpected return value (%v != %v)", identifier, ei.Ret, invocation.Ret)
-	}
-}
-
-func (ei ExpectInvocation) listSubinvocations() string {
-	if len(ei.SubInvocations) == 0 {
-		return "[no invocations]\n"
-	}
-	list := ""
-	for i, si := range ei.SubInvocations {
-		list = fmt.Sprintf("%s%2d: [%s:%d]\n", list, i, si.To, si.Method)
+	if ei.Exitcode != invocation.Exitcode {
+		assert.Fail(t, fmt.Sprintf("%s unexpected exitcode %d, expected %d", identifier, invocation.Exitcode, ei.Exitcode), diff())
 	}
-	return list
-}
-
-func listInvocations(invocations []*Invocation) string {
-	if len(invocations) == 0 {
-		return "[no invocations]\n"
+	if ei.Ret != nil && !ei.Ret.matches(invocation.Ret) {
+		assert.Fail(t, fmt.Sprintf("%s unexpected return value (%s != %s)", identifier,
+			formatObject(ei.Ret.val), formatObjectLike(invocation.Ret, ei.Ret.val)), diff())
 	}
-	list := ""
-	for i, si := range invocations {
-		list = fmt.Sprintf("%s%2d: [%s:%d]\n", list, i, si.Msg.to, si.Msg.method)
-	}
-	return list
 }
 
 // helpers to simplify pointer creation
@@ -381,28 +373,14 @@ func GetMinerBalances(t *testing.T, vm *VM, minerIdAddr address.Address) MinerBa
 }
 
 func PowerForMinerSector(t *testing.T, vm *VM, minerIdAddr address.Address, sectorNumber abi.SectorNumber) miner.PowerPair {
-	var state miner.State
-	err := vm.GetState(minerIdAddr, &state)
-	require.NoError(t, err)
-
-	sector, found, err := state.GetSector(vm.store, sectorNumber)
-	require.NoError(t, err)
-	require.True(t, found)
-
+	sector := GetSector(t, vm, minerIdAddr, sectorNumber)
 	sectorSize, err := sector.SealProof.SectorSize()
 	require.NoError(t, err)
 	return miner.PowerForSector(sectorSize, sector)
 }
 
 func MinerPower(t *testing.T, vm *VM, minerIdAddr address.Address) miner.PowerPair {
-	var state power.State
-	err := vm.GetState(builtin.StoragePowerActorAddr, &state)
-	require.NoError(t, err)
-
-	claim, found, err := state.GetClaim(vm.store, minerIdAddr)
-	require.NoError(t, err)
-	require.True(t, found)
-
+	claim := GetClaim(t, vm, minerIdAddr)
 	return miner.NewPowerPair(claim.RawBytePower, claim.QualityAdjPower)
 }
 
@@ -425,6 +403,8 @@ type NetworkStats struct {
 	TotalClientLockedCollateral   abi.TokenAmount
 	TotalProviderLockedCollateral abi.TokenAmount
 	TotalClientStorageFee         abi.TokenAmount
+	TotalClientUpfrontPayments    abi.TokenAmount
+	TotalProviderStorageFee       abi.TokenAmount
 }
 
 func GetNetworkStats(t *testing.T, vm *VM) NetworkStats {
@@ -458,23 +438,11 @@ func GetNetworkStats(t *testing.T, vm *VM) NetworkStats {
 		TotalClientLockedCollateral:   marketState.TotalClientLockedCollateral,
 		TotalProviderLockedCollateral: marketState.TotalProviderLockedCollateral,
 		TotalClientStorageFee:         marketState.TotalClientStorageFee,
+		TotalClientUpfrontPayments:    marketState.TotalClientUpfrontPayments,
+		TotalProviderStorageFee:       marketState.TotalProviderStorageFee,
 	}
 }
 
-func GetDealState(t *testing.T, vm *VM, dealID abi.DealID) (*market.DealState, bool) {
-	var marketState market.State
-	err := vm.GetState(builtin.StorageMarketActorAddr, &marketState)
-	require.NoError(t, err)
-
-	states, err := market.AsDealStateArray(vm.store, marketState.States)
-	require.NoError(t, err)
-
-	state, found, err := states.Get(dealID)
-	require.NoError(t, err)
-
-	return state, found
-}
-
 //
 // Misc. helpers
 //
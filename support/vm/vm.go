@@ -21,6 +21,7 @@ import (
 	"github.com/filecoin-project/specs-actors/v5/actors/runtime"
 	"github.com/filecoin-project/specs-actors/v5/actors/states"
 	"github.com/filecoin-project/specs-actors/v5/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/v5/support/vm/messages"
 )
 
 // VM is a simplified message execution framework for the purposes of testing inter-actor communication.
@@ -51,6 +52,10 @@ type VM struct {
 	circSupply abi.TokenAmount
 
 	gasPrices Pricelist
+
+	upgrades []NetworkUpgrade // Scheduled network upgrades, ordered by epoch
+
+	replayCheck bool // Whether to execute each message twice and compare the results
 }
 
 // VM types
@@ -66,54 +71,34 @@ type InternalMessage struct {
 	params interface{}
 }
 
-type ChainMessage struct {
-	Version uint64
-
-	From address.Address
-	To   address.Address
-
-	Nonce uint64
+// NetworkUpgrade schedules a network version transition at an epoch.
+// From Epoch onwards the VM reports Version to actors and, if ActorImpls is set, dispatches messages to
+// those implementations. If Migration is set, it is run on the state tree when the VM advances to Epoch.
+type NetworkUpgrade struct {
+	Epoch      abi.ChainEpoch
+	Version    network.Version
+	ActorImpls ActorImplLookup
+	Migration  StateMigration
+}
 
-	Value abi.TokenAmount
+// StateMigration transforms the state tree rooted at root into the state tree for a new network version.
+type StateMigration func(ctx context.Context, store adt.Store, root cid.Cid, epoch abi.ChainEpoch) (cid.Cid, error)
 
-	GasLimit   int64
-	GasFeeCap  abi.TokenAmount
-	GasPremium abi.TokenAmount
-
-	Method abi.MethodNum
-	Params []byte
-}
+// ChainMessage is the form of a top-level message as included on chain.
+type ChainMessage = messages.ChainMessage
 
 func makeChainMessage(from, to address.Address, nonce uint64, value abi.TokenAmount, method abi.MethodNum, params interface{}) (*ChainMessage, error) {
-	var buf bytes.Buffer
 	if params == nil {
-		if err := abi.Empty.MarshalCBOR(&buf); err != nil {
-			return nil, err
-		}
-	} else {
-		if err := params.(cbor.Er).MarshalCBOR(&buf); err != nil {
-			return nil, err
-		}
+		return messages.NewChainMessage(from, to, nonce, value, method, nil)
 	}
-
-	return &ChainMessage{
-		Version:    0,
-		From:       from,
-		To:         to,
-		Nonce:      nonce,
-		Value:      value,
-		GasLimit:   defaultGasLimit,
-		GasFeeCap:  big.Zero(),
-		GasPremium: big.Zero(),
-		Method:     method,
-		Params:     buf.Bytes(),
-	}, nil
+	return messages.NewChainMessage(from, to, nonce, value, method, params.(cbor.Marshaler))
 }
 
 type Invocation struct {
 	Msg            *InternalMessage
 	Exitcode       exitcode.ExitCode
 	Ret            cbor.Marshaler
+	Events         []runtime.ActorEvent // Emitted by the invoked actor, excluding sub-invocations
 	SubInvocations []*Invocation
 }
 
@@ -176,31 +161,53 @@ func NewVMAtEpoch(ctx context.Context, actorImpls ActorImplLookup, store adt.Sto
 	}, nil
 }
 
+// WithEpoch returns a VM at the given epoch, applying any network upgrades scheduled after the current
+// epoch and up to and including the new one.
 func (vm *VM) WithEpoch(epoch abi.ChainEpoch) (*VM, error) {
-	_, err := vm.checkpoint()
+	stateRoot, err := vm.checkpoint()
 	if err != nil {
 		return nil, err
 	}
 
-	actors, err := adt.AsMap(vm.store, vm.stateRoot, builtin.DefaultHamtBitwidth)
+	actorImpls := vm.ActorImpls
+	networkVersion := vm.networkVersion
+	for _, upgrade := range vm.upgrades {
+		if upgrade.Epoch <= vm.currentEpoch || upgrade.Epoch > epoch {
+			continue
+		}
+		if upgrade.Migration != nil {
+			stateRoot, err = upgrade.Migration(vm.ctx, vm.store, stateRoot, upgrade.Epoch)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to migrate state for network version %d at epoch %d", upgrade.Version, upgrade.Epoch)
+			}
+		}
+		if upgrade.ActorImpls != nil {
+			actorImpls = upgrade.ActorImpls
+		}
+		networkVersion = upgrade.Version
+	}
+
+	actors, err := adt.AsMap(vm.store, stateRoot, builtin.DefaultHamtBitwidth)
 	if err != nil {
 		return nil, err
 	}
 
 	return &VM{
 		ctx:            vm.ctx,
-		ActorImpls:     vm.ActorImpls,
+		ActorImpls:     actorImpls,
 		store:          vm.store,
 		actors:         actors,
-		stateRoot:      vm.stateRoot,
+		stateRoot:      stateRoot,
 		actorsDirty:    false,
 		emptyObject:    vm.emptyObject,
 		currentEpoch:   epoch,
-		networkVersion: vm.networkVersion,
+		networkVersion: networkVersion,
 		statsSource:    vm.statsSource,
 		statsByMethod:  make(StatsByCall),
 		circSupply:     vm.circSupply,
 		gasPrices:      &v13PriceList,
+		upgrades:       vm.upgrades,
+		replayCheck:    vm.replayCheck,
 	}, nil
 }
 
@@ -229,9 +236,31 @@ func (vm *VM) WithNetworkVersion(nv network.Version) (*VM, error) {
 		statsByMethod:  make(StatsByCall),
 		circSupply:     vm.circSupply,
 		gasPrices:      &v13PriceList,
+		upgrades:       vm.upgrades,
+		replayCheck:    vm.replayCheck,
 	}, nil
 }
 
+// WithNetworkUpgrades returns a VM that applies the given network upgrades as it is advanced through
+// their epochs with WithEpoch. Upgrades must be scheduled after the current epoch, in increasing epoch order.
+// They replace any previously scheduled upgrades.
+func (vm *VM) WithNetworkUpgrades(upgrades ...NetworkUpgrade) (*VM, error) {
+	prevEpoch := vm.currentEpoch
+	for _, upgrade := range upgrades {
+		if upgrade.Epoch <= prevEpoch {
+			return nil, errors.Errorf("network upgrade to version %d at epoch %d must be scheduled after epoch %d", upgrade.Version, upgrade.Epoch, prevEpoch)
+		}
+		prevEpoch = upgrade.Epoch
+	}
+
+	next, err := vm.WithNetworkVersion(vm.networkVersion)
+	if err != nil {
+		return nil, err
+	}
+	next.upgrades = append([]NetworkUpgrade(nil), upgrades...)
+	return next, nil
+}
+
 func (vm *VM) rollback(root cid.Cid) error {
 	var err error
 	vm.actors, err = adt.AsMap(vm.store, root, builtin.DefaultHamtBitwidth)
@@ -340,8 +369,80 @@ type MessageResult struct {
 	GasCharged int64
 }
 
+// SetCheckDeterminism sets whether each message is executed twice, from the same prior state, with the
+// resulting state roots and receipts compared. A difference indicates nondeterminism in actor code,
+// such as dependence on map iteration order, and causes ApplyMessage to panic.
+// The check carries over to VMs derived from this one, but doubles the store reads and writes reported.
+func (vm *VM) SetCheckDeterminism(check bool) {
+	vm.replayCheck = check
+}
+
 // ApplyMessage applies the message to the current state.
 func (vm *VM) ApplyMessage(from, to address.Address, value abi.TokenAmount, method abi.MethodNum, params interface{}) MessageResult {
+	if !vm.replayCheck {
+		return vm.applyMessage(from, to, value, method, params)
+	}
+
+	// Both executions receive serialized parameters, so that the first can't affect the second by mutating them.
+	if params != nil {
+		var paramsBuf bytes.Buffer
+		if err := params.(cbor.Marshaler).MarshalCBOR(&paramsBuf); err != nil {
+			panic(err)
+		}
+		params = builtin.CBORBytes(paramsBuf.Bytes())
+	}
+
+	priorRoot, err := vm.checkpoint()
+	if err != nil {
+		panic(err)
+	}
+	actors, err := adt.AsMap(vm.store, priorRoot, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		panic(err)
+	}
+	shadow := &VM{
+		ctx:            vm.ctx,
+		ActorImpls:     vm.ActorImpls,
+		store:          vm.store,
+		actors:         actors,
+		stateRoot:      priorRoot,
+		emptyObject:    vm.emptyObject,
+		currentEpoch:   vm.currentEpoch,
+		networkVersion: vm.networkVersion,
+		statsByMethod:  make(StatsByCall),
+		circSupply:     vm.circSupply,
+		gasPrices:      vm.gasPrices,
+	}
+	shadowResult := shadow.applyMessage(from, to, value, method, params)
+	result := vm.applyMessage(from, to, value, method, params)
+
+	if shadowResult.Code != result.Code {
+		panic(errors.Errorf("nondeterministic execution of method %d on %v: exit codes %s and %s", method, to, shadowResult.Code, result.Code))
+	}
+	if shadowResult.GasCharged != result.GasCharged {
+		panic(errors.Errorf("nondeterministic execution of method %d on %v: gas charged %d and %d", method, to, shadowResult.GasCharged, result.GasCharged))
+	}
+	if shadowRet, ret := serializeReturn(shadowResult.Ret), serializeReturn(result.Ret); !bytes.Equal(shadowRet, ret) {
+		panic(errors.Errorf("nondeterministic execution of method %d on %v: return values %x and %x", method, to, shadowRet, ret))
+	}
+	if !shadow.stateRoot.Equals(vm.stateRoot) {
+		panic(errors.Errorf("nondeterministic execution of method %d on %v: state roots %s and %s", method, to, shadow.stateRoot, vm.stateRoot))
+	}
+	return result
+}
+
+func serializeReturn(ret cbor.Marshaler) []byte {
+	if ret == nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := ret.MarshalCBOR(&buf); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func (vm *VM) applyMessage(from, to address.Address, value abi.TokenAmount, method abi.MethodNum, params interface{}) MessageResult {
 	// This method does not actually execute the message itself,
 	// but rather deals with the pre/post processing of a message.
 	// (see: `invocationContext.invoke()` for the dispatch and execution)
@@ -451,6 +552,18 @@ func (vm *VM) ApplyMessage(from, to address.Address, value abi.TokenAmount, meth
 	return MessageResult{ret.inner, exitCode, gasCharged}
 }
 
+// ApplySignedMessage checks the signature of a signed message with the verifier, then applies the message to the
+// current state. A message with an invalid signature is not applied and fails with SysErrSenderInvalid.
+// As for ApplyMessage, the message nonce and gas parameters are ignored.
+func (vm *VM) ApplySignedMessage(sm *messages.SignedMessage, verify messages.SignatureVerifier) MessageResult {
+	if err := sm.Verify(verify); err != nil {
+		vm.Log(rt.WARN, "invalid signature for message from %v: %s", sm.Message.From, err)
+		return MessageResult{nil, exitcode.SysErrSenderInvalid, 0}
+	}
+	msg := sm.Message
+	return vm.ApplyMessage(msg.From, msg.To, msg.Value, msg.Method, builtin.CBORBytes(msg.Params))
+}
+
 func (vm *VM) StateRoot() cid.Cid {
 	return vm.stateRoot
 }
@@ -500,6 +613,11 @@ func (vm *VM) GetEpoch() abi.ChainEpoch {
 	return vm.currentEpoch
 }
 
+// Get the network version for this vm
+func (vm *VM) GetNetworkVersion() network.Version {
+	return vm.networkVersion
+}
+
 // Get call stats
 func (vm *VM) GetCallStats() map[MethodKey]*CallStats {
 	return vm.statsByMethod
@@ -638,10 +756,35 @@ func (vm *VM) endInvocation(code exitcode.ExitCode, ret cbor.Marshaler) {
 	current := vm.invocationStack[curIndex]
 	current.Exitcode = code
 	current.Ret = ret
+	// The events of an invocation that fails are discarded along with its state changes.
+	if code != exitcode.Ok {
+		current.discardEvents()
+	}
 
 	vm.invocationStack = vm.invocationStack[:curIndex]
 }
 
+func (vm *VM) emitEvent(event runtime.ActorEvent) {
+	current := vm.invocationStack[len(vm.invocationStack)-1]
+	current.Events = append(current.Events, event)
+}
+
+func (inv *Invocation) discardEvents() {
+	inv.Events = nil
+	for _, sub := range inv.SubInvocations {
+		sub.discardEvents()
+	}
+}
+
+// Returns the events emitted by the invocation and its sub-invocations, in the order they were emitted.
+func (inv *Invocation) AllEvents() []runtime.ActorEvent {
+	events := inv.Events
+	for _, sub := range inv.SubInvocations {
+		events = append(events, sub.AllEvents()...)
+	}
+	return events
+}
+
 func (vm *VM) Invocations() []*Invocation {
 	return vm.invocations
 }